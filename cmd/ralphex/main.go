@@ -6,17 +6,27 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"os/signal"
+	"os/user"
 	"path/filepath"
+	"regexp"
 	"runtime/debug"
-	"syscall"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/fatih/color"
 	"github.com/jessevdk/go-flags"
+	"golang.org/x/term"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/forge"
 	"github.com/umputun/ralphex/pkg/git"
 	"github.com/umputun/ralphex/pkg/input"
 	"github.com/umputun/ralphex/pkg/notify"
@@ -29,27 +39,86 @@ import (
 
 // opts holds all command-line options.
 type opts struct {
-	MaxIterations   int      `short:"m" long:"max-iterations" default:"50" description:"maximum task iterations"`
-	Review          bool     `short:"r" long:"review" description:"skip task execution, run full review pipeline"`
-	ExternalOnly    bool     `short:"e" long:"external-only" description:"skip tasks and first review, run only external review loop"`
-	CodexOnly       bool     `short:"c" long:"codex-only" description:"alias for --external-only (deprecated)"`
-	TasksOnly       bool     `short:"t" long:"tasks-only" description:"run only task phase, skip all reviews"`
-	PlanDescription string   `long:"plan" description:"create plan interactively (enter plan description)"`
-	Debug           bool     `short:"d" long:"debug" description:"enable debug logging"`
-	NoColor         bool     `long:"no-color" description:"disable color output"`
-	Version         bool     `short:"v" long:"version" description:"print version and exit"`
-	Serve           bool     `short:"s" long:"serve" description:"start web dashboard for real-time streaming"`
-	Port            int      `short:"p" long:"port" default:"8080" description:"web dashboard port"`
-	Watch           []string `short:"w" long:"watch" description:"directories to watch for progress files (repeatable)"`
-	Reset           bool     `long:"reset" description:"interactively reset global config to embedded defaults"`
-	DumpDefaults    string   `long:"dump-defaults" description:"extract raw embedded defaults to specified directory"`
-	ConfigDir       string   `long:"config-dir" env:"RALPHEX_CONFIG_DIR" description:"custom config directory"`
-
-	PlanFile string `positional-arg-name:"plan-file" description:"path to plan file (optional, uses fzf if omitted)"`
+	MaxIterations   int           `short:"m" long:"max-iterations" description:"maximum task iterations (default: max_iterations from config, 50)"`
+	Review          bool          `short:"r" long:"review" description:"skip task execution, run full review pipeline"`
+	ExternalOnly    bool          `short:"e" long:"external-only" description:"skip tasks and first review, run only external review loop"`
+	CodexOnly       bool          `short:"c" long:"codex-only" description:"alias for --external-only (deprecated)"`
+	TasksOnly       bool          `short:"t" long:"tasks-only" description:"run only task phase, skip all reviews"`
+	TasksReview     bool          `long:"tasks-review" description:"run tasks and claude reviews, skip external review and finalize"`
+	Agents          []string      `long:"agent" description:"review agent to run, others are left out of review prompts (repeatable)"`
+	NoAgents        []string      `long:"no-agent" description:"review agent to leave out of review prompts (repeatable)"`
+	Base            string        `long:"base" description:"branch reviews compare against (default: repository default branch)"`
+	Diff            string        `long:"diff" description:"revision range to review instead of the branch changes, e.g. v1.2..HEAD"`
+	Since           string        `long:"since" description:"review only the changes after a ref, e.g. main or HEAD~5 (compares against its merge-base with HEAD)"`
+	Findings        []string      `long:"findings" description:"with --external-only, evaluate review findings from a file instead of running the external review (repeatable)"`
+	PlanDescription string        `long:"plan" description:"create plan interactively (enter plan description)"`
+	AnswersFile     string        `long:"answers-file" description:"with --plan, answer questions and drafts from a YAML/JSON file, no terminal needed"`
+	RecordAnswers   string        `long:"record-answers" description:"with --plan, save the answers given to questions and the first draft to a file for --answers-file"`
+	Template        string        `long:"template" description:"with --plan, plan template to fill in (name of a file in the templates dir, without .md)"`
+	Debug           bool          `short:"d" long:"debug" description:"enable debug logging"`
+	Trace           bool          `short:"V" long:"trace" description:"enable debug logging and print raw executor output"`
+	NoColor         bool          `long:"no-color" description:"disable color output"`
+	ShowTheme       bool          `long:"show-theme" description:"print a sample of every output color as configured and exit"`
+	LogFormat       string        `long:"log-format" choice:"text" choice:"json" default:"text" description:"console log format, json prints one object per event"`
+	Version         bool          `short:"v" long:"version" description:"print version and exit"`
+	Serve           bool          `short:"s" long:"serve" description:"start web dashboard for real-time streaming"`
+	Port            int           `short:"p" long:"port" default:"8080" description:"web dashboard port"`
+	PortAuto        bool          `long:"port-auto" description:"use the next free port when the dashboard port is in use"`
+	Open            bool          `long:"open" description:"open the web dashboard in the browser (with --serve)"`
+	Watch           []string      `short:"w" long:"watch" description:"directories to watch for progress files (repeatable)"`
+	DashboardToken  string        `long:"dashboard-token" env:"RALPHEX_DASHBOARD_TOKEN" description:"require this token to access the web dashboard"`
+	Reset           bool          `long:"reset" description:"interactively reset global config to embedded defaults"`
+	Configure       bool          `long:"configure" description:"interactively edit global config values and exit"`
+	Set             []string      `long:"set" value-name:"KEY=VALUE" description:"set a global config value and exit (repeatable)"`
+	DumpDefaults    string        `long:"dump-defaults" description:"extract raw embedded defaults to specified directory"`
+	ConfigDir       string        `long:"config-dir" env:"RALPHEX_CONFIG_DIR" description:"custom config directory"`
+	Config          string        `long:"config" env:"RALPHEX_CONFIG" value-name:"DIR" description:"alias for --config-dir"`
+	PlansDir        string        `long:"plans-dir" description:"plans directory for this run, overrides plans_dir from config"`
+	CheckConfig     bool          `long:"check-config" description:"validate config files, prompts and agents, report problems and exit"`
+	PrintConfig     bool          `long:"print-config" description:"print the effective configuration with the source of each value and exit"`
+	LintPlan        string        `long:"lint-plan" description:"check a plan file for malformed task checkboxes, report problems and exit"`
+	Validate        bool          `long:"validate" description:"check config, prompts, agents and all plans in the plans directory, report problems and exit"`
+	List            bool          `long:"list" description:"print plans in the plans directory with their branch and task progress, and exit"`
+	ListCompleted   bool          `long:"list-completed" description:"with --list, also print plans moved to the completed directory"`
+	DryRun          bool          `long:"dry-run" description:"print planned phases and exit without running claude/codex"`
+	Resume          bool          `long:"resume" description:"resume an interrupted run from its last checkpoint, skipping completed stages"`
+	Yes             bool          `long:"yes" description:"reuse an existing branch of the plan without asking"`
+	ContinueOnError bool          `long:"continue-on-error" description:"with multiple plans, keep running the remaining plans after a failure"`
+	NoMove          bool          `long:"no-move" description:"keep the plan in place after a successful run instead of moving it to the completed directory"`
+	AutoStash       string        `long:"auto-stash" optional:"yes" optional-value:"feature" choice:"feature" choice:"original" value-name:"TARGET" description:"stash uncommitted changes to create the plan branch, restore them on the feature branch (default) or with =original on the starting branch after the run"`
+	Worktree        bool          `long:"worktree" description:"run the plan in a linked git worktree next to the repository, current checkout stays untouched"`
+	WorktreeCleanup bool          `long:"worktree-cleanup" description:"with --worktree, remove the worktree after the plan moves to completed"`
+	CreatePR        bool          `long:"create-pr" description:"push the branch and open a pull request with gh after a successful full run"`
+	Timeout         time.Duration `long:"timeout" description:"abort the run after this duration (e.g. 30m, 2h), 0 means no limit"`
+
+	PlanFile      string   `positional-arg-name:"plan-file" description:"path to plan file, - reads it from stdin (optional, uses fzf if omitted)"`
+	MorePlanFiles []string // additional plan files, executed sequentially after PlanFile
+
+	MaxIterationsSet bool // --max-iterations was given explicitly, it wins over max_iterations of the plan front-matter
 }
 
 var revision = "unknown"
 
+// resolveConfigDir folds the --config alias into ConfigDir. a flag on the command line wins over the
+// RALPHEX_CONFIG_DIR and RALPHEX_CONFIG environment variables, RALPHEX_CONFIG_DIR wins over RALPHEX_CONFIG.
+// --config and --config-dir given together must name the same directory.
+func resolveConfigDir(parser *flags.Parser, o opts) (opts, error) {
+	fromCLI := func(name string) bool {
+		opt := parser.FindOptionByLongName(name)
+		return opt != nil && opt.IsSet() && !opt.IsSetDefault()
+	}
+	aliasCLI, dirCLI := fromCLI("config"), fromCLI("config-dir")
+	switch {
+	case aliasCLI && dirCLI && o.Config != o.ConfigDir:
+		return o, fmt.Errorf("--config %s and --config-dir %s point at different directories", o.Config, o.ConfigDir)
+	case aliasCLI:
+		o.ConfigDir = o.Config
+	case !dirCLI && o.ConfigDir == "":
+		o.ConfigDir = o.Config
+	}
+	return o, nil
+}
+
 // resolveVersion returns the best available version string.
 // priority: ldflags revision → module version from go install → VCS commit hash → "unknown".
 func resolveVersion() string {
@@ -89,6 +158,9 @@ type startupInfo struct {
 	Mode            processor.Mode
 	MaxIterations   int
 	ProgressPath    string
+	QueuePos        int // 1-based position in a multi-plan queue, zero for a single plan
+	QueueLen        int
+	KeepPlan        bool // moving the plan to completed after the run is disabled
 }
 
 // executePlanRequest holds parameters for plan execution.
@@ -101,6 +173,15 @@ type executePlanRequest struct {
 	Selector      *plan.Selector
 	DefaultBranch string
 	NotifySvc     *notify.Service
+	Notifier      notify.Notifier      // run start, failure and completion events, nil if notifier is not configured
+	Deadline      time.Time            // hard deadline for runner execution (from --timeout), zero means none
+	QueuePos      int                  // 1-based position of this plan in a multi-plan queue, zero when running a single plan
+	QueueLen      int                  // number of plans in the queue
+	Stop          *status.StopHolder   // stop after the current iteration requested by the first Ctrl+C, nil if not supported
+	Pause         *status.PauseHolder  // pause before the next iteration requested by SIGUSR1, nil if not supported
+	Agents        []string             // review agents listed in the plan front-matter, nil for all
+	Findings      []processor.Findings // review findings from --findings, nil runs the external review tool
+	Stdin         io.Reader            // input for interactive prompts, os.Stdin unless the plan was read from it
 }
 
 func main() {
@@ -108,7 +189,7 @@ func main() {
 
 	var o opts
 	parser := flags.NewParser(&o, flags.Default)
-	parser.Usage = "[OPTIONS] [plan-file]"
+	parser.Usage = "[OPTIONS] [plan-file...]"
 
 	args, err := parser.Parse()
 	if err != nil {
@@ -123,22 +204,40 @@ func main() {
 		os.Exit(0)
 	}
 
+	if opt := parser.FindOptionByLongName("max-iterations"); opt != nil {
+		o.MaxIterationsSet = opt.IsSet()
+	}
+	if o, err = resolveConfigDir(parser, o); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// handle positional argument
 	if len(args) > 0 {
 		o.PlanFile = args[0]
+		o.MorePlanFiles = args[1:]
 	}
 
-	// setup context with signal handling
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	// setup context with signal handling, the first Ctrl+C during a run stops it after the current iteration,
+	// SIGUSR1 pauses it before the next one and SIGUSR2 resumes it
+	stop, pause := &status.StopHolder{}, &status.PauseHolder{}
+	ctx, cancel := watchSignals(context.Background(), stop, pause)
 	defer cancel()
 
-	if err := run(ctx, o); err != nil {
+	if err := run(ctx, o, stop, pause); err != nil {
+		if errors.Is(err, processor.ErrStopped) {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(130)
+		}
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func run(ctx context.Context, o opts) error {
+// run executes ralphex with parsed options. stop, if not nil, is shared with the signal handler
+// and lets the runner finish its current iteration on the first Ctrl+C.
+// pause, if not nil, is shared with the signal handler and holds the runner before its next iteration.
+func run(ctx context.Context, o opts, stop *status.StopHolder, pause *status.PauseHolder) error {
 	// suppress ^C echo in terminal before setting up interrupt watcher
 	restoreTerminal := disableCtrlCEcho()
 	defer restoreTerminal()
@@ -152,8 +251,14 @@ func run(ctx context.Context, o opts) error {
 		return err
 	}
 
+	// the deadline covers the whole run, including plan creation followed by implementation
+	var deadline time.Time
+	if o.Timeout > 0 {
+		deadline = time.Now().Add(o.Timeout)
+	}
+
 	// handle early-exit flags (before full config load)
-	if done, err := handleEarlyFlags(o); err != nil || done {
+	if done, err := handleEarlyFlags(ctx, o); err != nil || done {
 		return err
 	}
 
@@ -162,15 +267,31 @@ func run(ctx context.Context, o opts) error {
 	if err != nil {
 		return fmt.Errorf("load config: %w", err)
 	}
+	o = applyConfigMaxIterations(o, cfg)
 
 	// create colors from config (all colors guaranteed populated via fallback)
 	colors := progress.NewColors(cfg.Colors)
+	if o.ShowTheme {
+		showTheme(cfg.Colors.Theme, colors, os.Stdout)
+		return nil
+	}
+	if pc := cfg.ProjectContext; pc.Truncated() {
+		colors.Warn().Printf("warning: project context %s is %d bytes, truncated to %d (context_max_bytes)\n",
+			pc.File, pc.Size, len(pc.Content))
+	}
+	if notice := cfg.UpgradeNotice(); notice != "" {
+		colors.Info().Printf("note: %s\n", notice)
+	}
 
 	// create notification service (nil if no channels configured)
 	notifySvc, err := notify.New(cfg.NotifyParams, stderrLog{})
 	if err != nil {
 		return fmt.Errorf("create notification service: %w", err)
 	}
+	notifier, err := notify.NewNotifier(cfg.NotifyParams)
+	if err != nil {
+		return fmt.Errorf("create notifier: %w", err)
+	}
 
 	// watch-only mode: --serve with watch dirs (CLI or config) and no plan file
 	// runs web dashboard without plan execution, can run from any directory
@@ -178,10 +299,16 @@ func run(ctx context.Context, o opts) error {
 		return runWatchOnly(ctx, o, cfg, colors)
 	}
 
-	// check dependencies using configured command (or default "claude")
-	if depErr := checkClaudeDep(cfg); depErr != nil {
+	// check dependencies using configured agent command (or default "claude")
+	if depErr := checkAgentDep(cfg); depErr != nil {
 		return depErr
 	}
+	// pull requests are opened after the run, without gh the page for opening one is printed instead
+	if wantPullRequest(o, cfg) {
+		if depErr := forge.NewGitHub().CheckDep(); depErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v, the pull request will have to be opened manually\n", depErr)
+		}
+	}
 
 	// require running from repo root
 	if _, statErr := os.Stat(".git"); statErr != nil {
@@ -193,22 +320,44 @@ func run(ctx context.Context, o opts) error {
 	if err != nil {
 		return fmt.Errorf("open git repo: %w", err)
 	}
-
-	// ensure repository has commits (prompts to create initial commit if empty)
-	if ensureErr := ensureRepoHasCommits(ctx, gitSvc, os.Stdin, os.Stdout); ensureErr != nil {
-		return ensureErr
+	gitSvc.SetSigning(cfg.GitSign)
+	gitSvc.SetCommitMessages(commitMessages(cfg))
+	gitSvc.SetAutoStash(git.AutoStash(o.AutoStash))
+
+	// ensure repository has commits (prompts to create initial commit if empty).
+	// dry-run leaves an empty repo as is, its summary notes the missing initial commit
+	if !o.DryRun {
+		if ensureErr := ensureRepoHasCommits(ctx, gitSvc, os.Stdin, os.Stdout); ensureErr != nil {
+			return ensureErr
+		}
 	}
 
 	// detect default branch for prompt templates
 	defaultBranch := gitSvc.GetDefaultBranch()
+	if o, err = resolveSince(o, gitSvc, colors); err != nil {
+		return err
+	}
 
 	mode := determineMode(o)
 
+	if err := applyPlansDir(o, mode, cfg); err != nil {
+		return fmt.Errorf("select plan: %w", err)
+	}
+	prompts, closePrompts := promptInput(o, os.Stdin, ttyPath)
+	defer closePrompts()
+	if o, err = saveStdinPlan(o, cfg.PlansDir, os.Stdin, colors); err != nil {
+		return err
+	}
+
 	// create plan selector for use by plan selection and plan mode
 	selector := plan.NewSelector(cfg.PlansDir, colors)
+	selector.Archive = planArchive(cfg)
 
 	// plan mode has different flow - doesn't require plan file selection
 	if mode == processor.ModePlan {
+		if o.DryRun {
+			return runDryRun(o, executePlanRequest{Mode: mode, GitSvc: gitSvc, Config: cfg, Colors: colors, DefaultBranch: defaultBranch})
+		}
 		return runPlanMode(ctx, o, executePlanRequest{
 			Mode:          processor.ModePlan,
 			GitSvc:        gitSvc,
@@ -217,14 +366,22 @@ func run(ctx context.Context, o opts) error {
 			Selector:      selector,
 			DefaultBranch: defaultBranch,
 			NotifySvc:     notifySvc,
+			Notifier:      notifier,
+			Deadline:      deadline,
+			Stop:          stop,
 		})
 	}
 
+	findings, err := readFindings(o.Findings)
+	if err != nil {
+		return err
+	}
+
 	// select and prepare plan file (not needed for plan mode)
 	// plan is optional only for review modes (ModeReview, ModeCodexOnly)
 	planOptional := mode == processor.ModeReview || mode == processor.ModeCodexOnly
-	planFile, err := selector.Select(ctx, o.PlanFile, planOptional)
-	if err != nil {
+	planFiles, err := selectPlans(ctx, selector, o, planOptional)
+	if err != nil && !o.DryRun {
 		// check for auto-plan-mode: no plans found on main/master branch
 		handled, autoPlanErr := tryAutoPlanMode(ctx, err, o, executePlanRequest{
 			GitSvc:        gitSvc,
@@ -233,25 +390,20 @@ func run(ctx context.Context, o opts) error {
 			Selector:      selector,
 			DefaultBranch: defaultBranch,
 			NotifySvc:     notifySvc,
+			Notifier:      notifier,
+			Deadline:      deadline,
+			Stop:          stop,
+			Pause:         pause,
 		})
 		if handled {
 			return autoPlanErr
 		}
-		return fmt.Errorf("select plan: %w", err)
-	}
-
-	// setup git for execution (branch, gitignore)
-	if planFile != "" && modeRequiresBranch(mode) {
-		if err := gitSvc.CreateBranchForPlan(planFile); err != nil {
-			return fmt.Errorf("create branch for plan: %w", err)
-		}
 	}
-	if err := gitSvc.EnsureIgnored(".ralphex/progress/", ".ralphex/progress/progress-test.txt"); err != nil {
-		return fmt.Errorf("ensure gitignore: %w", err)
+	if err != nil {
+		return fmt.Errorf("select plan: %w", err)
 	}
 
-	return executePlan(ctx, o, executePlanRequest{
-		PlanFile:      planFile,
+	req := executePlanRequest{
 		Mode:          mode,
 		GitSvc:        gitSvc,
 		Config:        cfg,
@@ -259,7 +411,243 @@ func run(ctx context.Context, o opts) error {
 		Selector:      selector,
 		DefaultBranch: defaultBranch,
 		NotifySvc:     notifySvc,
-	})
+		Notifier:      notifier,
+		Deadline:      deadline,
+		Stop:          stop,
+		Pause:         pause,
+		Findings:      findings,
+		Stdin:         prompts,
+	}
+
+	// dry-run stops here, before any branch creation or .gitignore changes
+	if o.DryRun {
+		return runDryRunQueue(o, planFiles, req)
+	}
+
+	// lint plans before any branch creation, so a broken plan leaves git state untouched
+	if modeRequiresBranch(mode) {
+		for _, pf := range planFiles {
+			if err := lintPlan(ctx, pf, prompts, os.Stdout); err != nil {
+				return err
+			}
+		}
+	}
+
+	if modeRequiresBranch(mode) {
+		return withAutostash(gitSvc, colors, planFiles, func() error { return runPlans(ctx, o, planFiles, req) })
+	}
+	return runPlans(ctx, o, planFiles, req)
+}
+
+// defaultMaxIterations limits task iterations when neither --max-iterations nor max_iterations is set.
+const defaultMaxIterations = 50
+
+// applyConfigMaxIterations uses max_iterations of the config (env > repo > global > embedded) when
+// --max-iterations isn't given. max_iterations of the plan front-matter is applied later and wins over both.
+func applyConfigMaxIterations(o opts, cfg *config.Config) opts {
+	if o.MaxIterations > 0 {
+		return o
+	}
+	o.MaxIterations = defaultMaxIterations
+	if cfg.MaxIterations > 0 {
+		o.MaxIterations = cfg.MaxIterations
+	}
+	return o
+}
+
+// runPlans sets up the branch for the selected plans and executes them.
+func runPlans(ctx context.Context, o opts, planFiles []string, req executePlanRequest) error {
+	// several plans run sequentially, each on its own branch
+	if len(planFiles) > 1 {
+		return runPlanQueue(ctx, o, planFiles, req)
+	}
+
+	var planFile string
+	if len(planFiles) == 1 {
+		planFile = planFiles[0]
+	}
+
+	req.PlanFile = planFile
+	if o.Worktree {
+		return runInWorktree(ctx, o, req)
+	}
+
+	// setup git for execution (branch, gitignore)
+	if planFile != "" && modeRequiresBranch(req.Mode) {
+		name, err := planBranchName(req.Config, planFile)
+		if err != nil {
+			return err
+		}
+		branch := resolvePlanBranch(ctx, o, req.GitSvc, name, nil, req.Stdin, os.Stdout)
+		if err := req.GitSvc.CreateBranchForPlanAs(planFile, branch); err != nil {
+			return fmt.Errorf("create branch for plan: %w", err)
+		}
+	}
+	if err := ensureProgressIgnored(req.GitSvc, req.Config.ProgressDir); err != nil {
+		return err
+	}
+
+	return executePlan(ctx, o, req)
+}
+
+// withAutostash runs fn with uncommitted changes other than the plan files stashed (--auto-stash=original).
+// the stash is restored onto the starting branch after fn returns, whether it succeeded or not.
+// other auto-stash modes leave the worktree alone and just run fn, see git.Service.StashForRun.
+// a stash that can't be restored stays in the stash list and the error explains how to get it back.
+func withAutostash(gitSvc *git.Service, colors *progress.Colors, planFiles []string, fn func() error) error {
+	st, err := gitSvc.StashForRun(planFiles...)
+	if err != nil {
+		return fmt.Errorf("autostash: %w", err)
+	}
+	if st.Ref == "" {
+		return fn()
+	}
+	colors.Info().Printf("stashed uncommitted changes as %s\n", st.Name)
+
+	runErr := fn()
+	if err := gitSvc.RestoreRunStash(st); err != nil {
+		return errors.Join(runErr, fmt.Errorf("autostash: %w", err))
+	}
+	colors.Info().Printf("restored stashed changes on %s\n", st.Branch)
+	return runErr
+}
+
+// runInWorktree executes the plan in a linked worktree instead of the current checkout.
+// the worktree is created next to the repository on the plan's branch, the plan file is copied
+// and committed there, and the whole run happens with the worktree as working directory.
+// progress logs stay in the original repository, so the dashboard and --resume find them.
+// the worktree is left for inspection, --worktree-cleanup removes it once the plan is completed.
+func runInWorktree(ctx context.Context, o opts, req executePlanRequest) error {
+	if req.PlanFile == "" {
+		return errors.New("--worktree requires a plan file")
+	}
+	origRoot := req.GitSvc.Root()
+	relPlan, err := repoRelativePath(origRoot, req.PlanFile)
+	if err != nil {
+		return err
+	}
+
+	branch, err := planBranchName(req.Config, req.PlanFile)
+	if err != nil {
+		return err
+	}
+	wtPath := req.GitSvc.WorktreePath(branch)
+	if err := req.GitSvc.AddWorktree(wtPath, branch); err != nil {
+		return fmt.Errorf("create worktree: %w", err)
+	}
+	if err := copyFile(req.PlanFile, filepath.Join(wtPath, relPlan)); err != nil {
+		return fmt.Errorf("copy plan to worktree: %w", err)
+	}
+
+	wtSvc, err := git.NewService(wtPath, req.Colors.Info())
+	if err != nil {
+		return fmt.Errorf("open worktree: %w", err)
+	}
+	wtSvc.SetSigning(req.Config.GitSign)
+	wtSvc.SetCommitMessages(commitMessages(req.Config))
+	wtPlan := filepath.Join(wtPath, relPlan)
+	if err := wtSvc.CommitPlanFile(wtPlan, wtSvc.AddPlanMessage(wtPlan, branch)); err != nil {
+		return fmt.Errorf("commit plan in worktree: %w", err)
+	}
+
+	// keep progress logs in the original repository, relative paths would resolve inside the worktree.
+	// they are ignored there too, the worktree stays clean and can be removed after completion
+	if err := ensureProgressIgnored(req.GitSvc, req.Config.ProgressDir); err != nil {
+		return err
+	}
+	progressDir := req.Config.ProgressDir
+	if progressDir == "" {
+		progressDir = progress.DefaultDir
+	}
+	if !filepath.IsAbs(progressDir) {
+		req.Config.ProgressDir = filepath.Join(origRoot, progressDir)
+	}
+
+	// executors, plan and prompt paths are all relative to the working directory
+	origWd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	if err := os.Chdir(wtPath); err != nil {
+		return fmt.Errorf("enter worktree: %w", err)
+	}
+	defer func() { _ = os.Chdir(origWd) }()
+
+	req.Colors.Info().Printf("worktree: %s\n", wtPath)
+	req.GitSvc, req.PlanFile = wtSvc, relPlan
+	if err := executePlan(ctx, o, req); err != nil {
+		req.Colors.Info().Printf("worktree left at %s\n", wtPath)
+		return err
+	}
+
+	if !o.WorktreeCleanup || planArchive(req.Config).FindCompleted(relPlan) == "" {
+		req.Colors.Info().Printf("worktree left at %s\n", wtPath)
+		return nil
+	}
+	if err := os.Chdir(origWd); err != nil {
+		return fmt.Errorf("leave worktree: %w", err)
+	}
+	origSvc, err := git.NewService(origRoot, req.Colors.Info())
+	if err != nil {
+		return fmt.Errorf("open git repo: %w", err)
+	}
+	if err := origSvc.RemoveWorktree(wtPath); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to remove worktree: %v\n", err)
+	}
+	return nil
+}
+
+// repoRelativePath returns path relative to the repository root, or an error if it is outside the repository.
+func repoRelativePath(root, path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", path, err)
+	}
+	// the root has symlinks resolved (macOS /var -> /private/var), so resolve the path's directory too
+	if dir, evalErr := filepath.EvalSymlinks(filepath.Dir(abs)); evalErr == nil {
+		abs = filepath.Join(dir, filepath.Base(abs))
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("plan file %s is outside of the repository %s", path, root)
+	}
+	return rel, nil
+}
+
+// copyFile copies src to dst, creating the parent directory of dst if needed.
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src) //nolint:gosec // plan file path from user selection
+	if err != nil {
+		return fmt.Errorf("read %s: %w", src, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return fmt.Errorf("create dir for %s: %w", dst, err)
+	}
+	if err := os.WriteFile(dst, data, 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", dst, err)
+	}
+	return nil
+}
+
+// ensureProgressIgnored adds the progress directory to .gitignore.
+// directories outside the repository (absolute, or leading out of it with ..) are skipped.
+func ensureProgressIgnored(gitSvc *git.Service, dir string) error {
+	if dir == "" {
+		dir = progress.DefaultDir
+	}
+	rel := filepath.ToSlash(filepath.Clean(dir))
+	if filepath.IsAbs(dir) || rel == ".." || strings.HasPrefix(rel, "../") {
+		return nil
+	}
+
+	pattern, probe := rel+"/", rel+"/progress-test.txt"
+	if rel == "." {
+		pattern, probe = "progress-*.txt", "progress-test.txt" // logs in the project root, don't ignore everything
+	}
+	if err := gitSvc.EnsureIgnored(pattern, probe); err != nil {
+		return fmt.Errorf("ensure gitignore: %w", err)
+	}
+	return nil
 }
 
 // getCurrentBranch returns the current git branch name or "unknown" if unavailable.
@@ -296,6 +684,15 @@ func tryAutoPlanMode(ctx context.Context, err error, o opts, req executePlanRequ
 // executePlan runs the main execution loop for a plan file.
 // handles progress logging, web dashboard, runner execution, and post-execution tasks.
 func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
+	settings, err := readPlanSettings(req.PlanFile, req.Colors)
+	if err != nil {
+		return err
+	}
+	o, req = applyPlanSettings(o, req, settings)
+	if req.Agents, err = selectAgents(o, req.Config, req.Agents); err != nil {
+		return err
+	}
+
 	branch := getCurrentBranch(req.GitSvc)
 
 	// create shared phase holder (single source of truth for current phase)
@@ -303,10 +700,15 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 
 	// create progress logger
 	baseLog, err := progress.NewLogger(progress.Config{
-		PlanFile: req.PlanFile,
-		Mode:     string(req.Mode),
-		Branch:   branch,
-		NoColor:  o.NoColor,
+		PlanFile:     req.PlanFile,
+		Mode:         string(req.Mode),
+		Branch:       branch,
+		NoColor:      o.NoColor,
+		Dir:          req.Config.ProgressDir,
+		Keep:         req.Config.ProgressKeep,
+		Format:       o.LogFormat,
+		MaxSizeBytes: int64(req.Config.ProgressMaxSizeMB) << 20,
+		MaxBackups:   req.Config.ProgressBackups,
 	}, req.Colors, holder)
 	if err != nil {
 		return fmt.Errorf("create progress logger: %w", err)
@@ -321,17 +723,48 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 		}
 	}()
 
-	// wrap logger with broadcast logger if --serve is enabled
+	// structured event log next to the progress file, for post-processing runs
 	var runnerLog processor.Logger = baseLog
+	var eventLog *processor.EventLogger
+	if req.Config.ProgressJSON {
+		eventsFile, createErr := os.Create(progress.EventsPath(baseLog.Path()))
+		if createErr != nil {
+			return fmt.Errorf("create event log: %w", createErr)
+		}
+		defer eventsFile.Close()
+		eventLog = processor.NewEventLogger(baseLog, eventsFile, holder,
+			processor.EventRunInfo{Plan: req.PlanFile, Mode: req.Mode, Branch: branch})
+		runnerLog = eventLog
+	}
+
+	// wrap logger with broadcast logger if --serve is enabled, the dashboard can pause the run
+	// and shows it paused by a signal as well
+	pause := req.Pause
+	var dashboard *web.Dashboard
 	if o.Serve {
-		dashboard := web.NewDashboard(web.DashboardConfig{
-			BaseLog:         baseLog,
+		if pause == nil {
+			pause = &status.PauseHolder{}
+		}
+		dashboard = web.NewDashboard(web.DashboardConfig{
+			BaseLog:         runnerLog,
+			Listen:          req.Config.WebListen,
 			Port:            o.Port,
+			PortAuto:        o.PortAuto,
 			PlanFile:        req.PlanFile,
 			Branch:          branch,
 			WatchDirs:       o.Watch,
 			ConfigWatchDirs: req.Config.WatchDirs,
+			Watch:           watchOptions(req.Config),
 			Colors:          req.Colors,
+			Pause:           pause,
+			Stop:            req.Stop,
+			PruneAfter:      time.Duration(req.Config.WatchPruneHours) * time.Hour,
+			IdleAfter:       time.Duration(req.Config.WatchIdleMinutes) * time.Minute,
+			Metrics:         req.Config.WebMetrics,
+			AuthToken:       dashboardToken(o, req.Config),
+			WebSocket:       req.Config.WebWebSocket,
+			PlanArchive:     planArchive(req.Config),
+			Open:            o.Open,
 		}, holder)
 		var dashErr error
 		runnerLog, dashErr = dashboard.Start(ctx)
@@ -347,22 +780,71 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 		Mode:          req.Mode,
 		MaxIterations: o.MaxIterations,
 		ProgressPath:  baseLog.Path(),
+		QueuePos:      req.QueuePos,
+		QueueLen:      req.QueueLen,
+		KeepPlan:      req.PlanFile != "" && movesPlan(req.Mode) && !moveCompleted(o, req.Config),
 	}, req.Colors)
 
-	// create and run the runner
+	// capture the starting point before the run, the pull request lists commits made during it
+	createPR := req.Mode == processor.ModeFull && (o.CreatePR || req.Config.PREnabled)
+	var draft pullRequestDraft
+	if createPR {
+		draft = newPullRequestDraft(req.GitSvc, req.PlanFile)
+	}
+
+	// create and run the runner. the runner context is bounded by --timeout,
+	// while the dashboard keeps the parent context so it stays up after a timeout.
 	r := createRunner(req, o, runnerLog, holder)
-	if runErr := r.Run(ctx); runErr != nil {
+	if pause != nil {
+		r.SetPauseHolder(pause)
+	}
+	if req.Stop != nil {
+		r.SetStopHolder(req.Stop)
+	}
+	if req.Notifier != nil {
+		r.SetNotifier(req.Notifier)
+	}
+	if eventLog != nil {
+		perMIn, perMOut := req.Config.TokenPrices()
+		eventLog.SetUsage(r, perMIn, perMOut)
+	}
+	if req.Config.PlanChangeAction == "ask" {
+		r.SetInputCollector(input.NewTerminalCollector(o.NoColor))
+	}
+	setRunnerMetrics(r, dashboard)
+	runCtx, cancelRun := runnerContext(ctx, req.Deadline)
+	defer cancelRun()
+	runErr := r.Run(runCtx)
+	timedOut := runErr != nil && ctx.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded)
+	if timedOut {
+		runErr = timeoutError(ctx, runCtx, o.Timeout, runErr)
+		runnerLog.Print("error: %v", runErr) // record the reason in the progress log
+	}
+	if eventLog != nil {
+		eventLog.Finish(runErr)
+	}
+	if runErr != nil {
 		// send failure notification before returning error.
 		// use context.Background() because the parent ctx may be canceled (e.g. SIGINT),
 		// and the notification timeout is applied inside Send() independently.
 		req.NotifySvc.Send(context.Background(), notify.Result{
-			Status:   "failure",
-			Mode:     string(req.Mode),
-			PlanFile: req.PlanFile,
-			Branch:   branch,
-			Duration: baseLog.Elapsed(),
-			Error:    runErr.Error(),
+			Status:      "failure",
+			Mode:        string(req.Mode),
+			PlanFile:    req.PlanFile,
+			Branch:      branch,
+			Duration:    baseLog.Elapsed(),
+			Error:       runErr.Error(),
+			ProgressLog: baseLog.Path(),
 		})
+		if errors.Is(runErr, processor.ErrStalled) {
+			req.Colors.Warn().Printf("the agent repeated the same output without committing. check the current task "+
+				"in %s for ambiguity or a blocker, fix it and re-run; set stall_detection = false to disable this check\n", req.PlanFile)
+		}
+		// keep web dashboard running after timeout so partial progress can be inspected
+		if timedOut && o.Serve {
+			req.Colors.Info().Printf("web dashboard still running at http://localhost:%d (press Ctrl+C to exit)\n", o.Port)
+			<-ctx.Done()
+		}
 		return fmt.Errorf("runner: %w", runErr)
 	}
 
@@ -378,21 +860,24 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 	// use context.Background() because the parent ctx may be canceled (e.g. SIGINT),
 	// and the notification timeout is applied inside Send() independently.
 	req.NotifySvc.Send(context.Background(), notify.Result{
-		Status:    "success",
-		Mode:      string(req.Mode),
-		PlanFile:  req.PlanFile,
-		Branch:    branch,
-		Duration:  elapsed,
-		Files:     stats.Files,
-		Additions: stats.Additions,
-		Deletions: stats.Deletions,
+		Status:      "success",
+		Mode:        string(req.Mode),
+		PlanFile:    req.PlanFile,
+		Branch:      branch,
+		Duration:    elapsed,
+		Files:       stats.Files,
+		Additions:   stats.Additions,
+		Deletions:   stats.Deletions,
+		ProgressLog: baseLog.Path(),
 	})
 
-	// move completed plan to completed/ directory
-	if req.PlanFile != "" && modeRequiresBranch(req.Mode) {
-		if moveErr := req.GitSvc.MovePlanToCompleted(req.PlanFile); moveErr != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to move plan to completed: %v\n", moveErr)
-		}
+	moveCompletedPlan(o, req)
+
+	// push the feature branch and open a pull request for it.
+	// the work is already committed locally, so failures are only warnings
+	if req.Mode == processor.ModeFull && (req.Config.AutoPush || createPR) {
+		draft.footer = pullRequestFooter(elapsed, stats, baseLog.Path())
+		publishBranch(ctx, req, forge.NewGitHub(), branch, createPR, draft)
 	}
 
 	// display completion with stats
@@ -403,6 +888,12 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 	} else {
 		req.Colors.Info().Printf("\ncompleted in %s\n", elapsed)
 	}
+	if phases, total := r.Changes(); len(phases) > 0 {
+		req.Colors.Info().Printf("%s\n", total.Format())
+	}
+	for _, line := range usageSummary(r, req.Config) {
+		req.Colors.Info().Printf("%s\n", line)
+	}
 
 	// keep web dashboard running after execution completes
 	if o.Serve {
@@ -417,6 +908,45 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 	return nil
 }
 
+// moveCompletedPlan moves the plan to the completed directory after a successful run.
+// skipped when moving is disabled by move_completed or --no-move, and in modes that don't finish the plan.
+func moveCompletedPlan(o opts, req executePlanRequest) {
+	if req.PlanFile == "" || !movesPlan(req.Mode) || !moveCompleted(o, req.Config) {
+		return
+	}
+	if err := req.GitSvc.MovePlanToCompleted(req.PlanFile, req.Config.PlansDir, planArchive(req.Config)); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to move plan to completed: %v\n", err)
+	}
+}
+
+// movesPlan reports whether a successful run in the mode finishes the plan.
+// tasks-only doesn't, the plan is not done until its changes are reviewed.
+func movesPlan(mode processor.Mode) bool {
+	return mode == processor.ModeFull || mode == processor.ModeTasksReview
+}
+
+// moveCompleted reports whether finished plans are moved to the completed directory, --no-move overrides the config.
+func moveCompleted(o opts, cfg *config.Config) bool {
+	return cfg.MoveCompleted && !o.NoMove
+}
+
+// runnerContext returns the context for runner execution, bounded by deadline if set.
+func runnerContext(ctx context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	if deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+// timeoutError wraps err with a timeout message if runCtx hit its deadline while the parent
+// context is still alive, distinguishing --timeout expiry from user interruption.
+func timeoutError(parent, runCtx context.Context, timeout time.Duration, err error) error {
+	if parent.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("run exceeded timeout of %s: %w", timeout, err)
+	}
+	return err
+}
+
 // openGitService creates a git.Service for the current directory.
 func openGitService(colors *progress.Colors) (*git.Service, error) {
 	svc, err := git.NewService(".", colors.Info())
@@ -426,14 +956,155 @@ func openGitService(colors *progress.Colors) (*git.Service, error) {
 	return svc, nil
 }
 
-// checkClaudeDep checks that the claude command is available in PATH.
-func checkClaudeDep(cfg *config.Config) error {
-	claudeCmd := cfg.ClaudeCommand
-	if claudeCmd == "" {
-		claudeCmd = "claude"
+// wantPullRequest returns true if a pull request is requested and the mode can end with a full run.
+// plan mode counts, it continues to implementation once the plan is created.
+func wantPullRequest(o opts, cfg *config.Config) bool {
+	mode := determineMode(o)
+	return (o.CreatePR || cfg.PREnabled) && (mode == processor.ModeFull || mode == processor.ModePlan)
+}
+
+// pullRequestCreator opens pull requests on the hosting service.
+type pullRequestCreator interface {
+	CheckDep() error
+	CreatePullRequest(ctx context.Context, pr forge.PullRequest) (string, error)
+	FindPullRequest(ctx context.Context, head string) (string, error)
+}
+
+// pullRequestDraft holds details captured before the run for the pull request opened after it.
+type pullRequestDraft struct {
+	title      string // first heading of the plan, or derived from the plan file name
+	content    string // plan content after the title, read before the plan moves to completed/
+	headBefore string // HEAD before the run, empty if unknown
+	footer     string // run summary, set after the run
+}
+
+// newPullRequestDraft captures the pull request title, plan content and current HEAD.
+// problems only degrade the pull request body, so they are reported as warnings.
+func newPullRequestDraft(gitSvc *git.Service, planFile string) pullRequestDraft {
+	var draft pullRequestDraft
+	if data, err := os.ReadFile(planFile); err == nil { //nolint:gosec // plan file path from user selection
+		draft.title, draft.content = plan.SplitTitle(string(data))
 	}
-	if _, err := exec.LookPath(claudeCmd); err != nil {
-		return fmt.Errorf("%s not found in PATH", claudeCmd)
+	if draft.title == "" {
+		draft.title = forge.Title(plan.ExtractBranchName(planFile))
+	}
+	head, err := gitSvc.HeadHash()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to get HEAD, pull request will not list commits: %v\n", err)
+		return draft
+	}
+	draft.headBefore = head
+	return draft
+}
+
+// openPullRequest opens a pull request for the pushed branch against the default branch.
+// the body has the plan content, subjects of commits made since the draft was captured and the footer.
+// an open pull request of the branch is reused, existing reports it; the push has already updated it.
+func openPullRequest(ctx context.Context, creator pullRequestCreator, gitSvc *git.Service, draft pullRequestDraft,
+	branch, defaultBranch string) (url string, existing bool, err error) {
+	// a failed lookup is not fatal, creating the pull request reports a duplicate anyway
+	if url, findErr := creator.FindPullRequest(ctx, branch); findErr == nil && url != "" {
+		return url, true, nil
+	}
+
+	var commits []string
+	if draft.headBefore != "" {
+		if commits, err = gitSvc.CommitSubjects(draft.headBefore, "HEAD"); err != nil {
+			return "", false, err //nolint:wrapcheck // already wrapped by git service
+		}
+	}
+	url, err = creator.CreatePullRequest(ctx, forge.PullRequest{
+		Title: draft.title,
+		Body:  forge.Body(draft.content, commits, draft.footer),
+		Base:  strings.TrimPrefix(defaultBranch, "origin/"),
+		Head:  branch,
+	})
+	return url, false, err //nolint:wrapcheck // error already has context from forge
+}
+
+// publishBranch pushes the feature branch to origin and, with createPR, opens a pull request for it.
+// without gh the page for opening the pull request is printed. nothing here fails the run,
+// problems are reported as warnings with the steps to finish manually.
+func publishBranch(ctx context.Context, req executePlanRequest, creator pullRequestCreator, branch string, createPR bool,
+	draft pullRequestDraft) {
+	remoteURL, err := req.GitSvc.RemoteURL("origin")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: no origin remote, branch %s is not pushed. to publish it:\n"+
+			"  git remote add origin <url> && git push -u origin %s\n", branch, branch)
+		return
+	}
+	if err := req.GitSvc.Push("origin", branch); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to push branch: %v\nto push it manually:\n  git push -u origin %s\n", err, branch)
+		return
+	}
+	if !createPR {
+		return
+	}
+
+	manual := "  gh pr create --head " + branch
+	if compareURL := forge.CompareURL(remoteURL, strings.TrimPrefix(req.DefaultBranch, "origin/"), branch); compareURL != "" {
+		manual = "  " + compareURL
+	}
+	if err := creator.CheckDep(); err != nil {
+		req.Colors.Info().Printf("%v, open the pull request at:\n%s\n", err, manual)
+		return
+	}
+	url, existing, err := openPullRequest(ctx, creator, req.GitSvc, draft, branch, req.DefaultBranch)
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "warning: failed to create pull request: %v\nto open it manually:\n%s\n", err, manual)
+	case existing:
+		req.Colors.Info().Printf("pull request already open, updated by the push: %s\n", url)
+	default:
+		req.Colors.Info().Printf("pull request: %s\n", url)
+	}
+}
+
+// pullRequestFooter summarizes the run for the pull request body: duration, diff stats and the local progress log.
+func pullRequestFooter(elapsed string, stats git.DiffStats, progressPath string) string {
+	footer := fmt.Sprintf("Implemented by [ralphex](https://github.com/umputun/ralphex) in %s", elapsed)
+	if stats.Files > 0 {
+		footer += fmt.Sprintf(" (%d files, +%d/-%d lines)", stats.Files, stats.Additions, stats.Deletions)
+	}
+	if progressPath != "" {
+		footer += fmt.Sprintf(", progress log: `%s`", progressPath)
+	}
+	return footer
+}
+
+// checkAgentDep checks that the command of the configured agent backend is in PATH.
+// with claude_command_wrapper set, claude is looked up through the wrapper, e.g. "ssh devbox -- which claude".
+// ollama is reached over HTTP, only its model setting is checked.
+func checkAgentDep(cfg *config.Config) error {
+	var agentCmd string
+	switch cfg.AgentBackend {
+	case "", processor.AgentClaude:
+		agentCmd = cfg.ClaudeCommand
+		if agentCmd == "" {
+			agentCmd = "claude"
+		}
+		if cfg.ClaudeCommandWrapper != "" {
+			remote := executor.Remote{Wrapper: cfg.ClaudeCommandWrapper}
+			if err := remote.LookPath(agentCmd); err != nil {
+				return fmt.Errorf("%s not found through wrapper: %w", agentCmd, err)
+			}
+			return nil
+		}
+	case processor.AgentGemini:
+		agentCmd = cfg.GeminiCommand
+		if agentCmd == "" {
+			agentCmd = "gemini"
+		}
+	case processor.AgentOllama:
+		if cfg.OllamaModel == "" {
+			return errors.New("ollama_model is required with agent_backend = ollama")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown agent_backend %q, expected claude, gemini or ollama", cfg.AgentBackend)
+	}
+	if _, err := exec.LookPath(agentCmd); err != nil {
+		return fmt.Errorf("%s not found in PATH", agentCmd)
 	}
 	return nil
 }
@@ -444,12 +1115,35 @@ func isWatchOnlyMode(o opts, configWatchDirs []string) bool {
 	return o.Serve && o.PlanFile == "" && o.PlanDescription == "" && (len(o.Watch) > 0 || len(configWatchDirs) > 0)
 }
 
+// dashboardToken returns the dashboard auth token, --dashboard-token takes precedence over web_auth_token.
+func dashboardToken(o opts, cfg *config.Config) string {
+	if o.DashboardToken != "" {
+		return o.DashboardToken
+	}
+	return cfg.WebAuthToken
+}
+
+// watchOptions returns the search depth and ignored directories of watched directories from config.
+func watchOptions(cfg *config.Config) web.WatchOptions {
+	return web.WatchOptions{Depth: cfg.WatchDepth, Ignore: cfg.WatchIgnore}
+}
+
 // runWatchOnly starts the web dashboard in watch-only mode without plan execution.
 func runWatchOnly(ctx context.Context, o opts, cfg *config.Config, colors *progress.Colors) error {
 	dirs := web.ResolveWatchDirs(o.Watch, cfg.WatchDirs)
 	dashboard := web.NewDashboard(web.DashboardConfig{
-		Port:   o.Port,
-		Colors: colors,
+		Listen:      cfg.WebListen,
+		Port:        o.Port,
+		PortAuto:    o.PortAuto,
+		Colors:      colors,
+		PruneAfter:  time.Duration(cfg.WatchPruneHours) * time.Hour,
+		IdleAfter:   time.Duration(cfg.WatchIdleMinutes) * time.Minute,
+		Metrics:     cfg.WebMetrics,
+		AuthToken:   dashboardToken(o, cfg),
+		WebSocket:   cfg.WebWebSocket,
+		PlanArchive: planArchive(cfg),
+		Watch:       watchOptions(cfg),
+		Open:        o.Open,
 	}, nil)
 	if watchErr := dashboard.RunWatchOnly(ctx, dirs); watchErr != nil {
 		return fmt.Errorf("run watch-only mode: %w", watchErr)
@@ -464,6 +1158,8 @@ func determineMode(o opts) processor.Mode {
 		return processor.ModePlan
 	case o.TasksOnly:
 		return processor.ModeTasksOnly
+	case o.TasksReview:
+		return processor.ModeTasksReview
 	case o.ExternalOnly || o.CodexOnly:
 		return processor.ModeCodexOnly
 	case o.Review:
@@ -474,44 +1170,299 @@ func determineMode(o opts) processor.Mode {
 }
 
 // modeRequiresBranch returns true if the mode requires creating a feature branch.
-// ModeFull and ModeTasksOnly both execute tasks that make commits, requiring a branch.
+// ModeFull, ModeTasksOnly and ModeTasksReview execute tasks that make commits, requiring a branch.
 func modeRequiresBranch(mode processor.Mode) bool {
-	return mode == processor.ModeFull || mode == processor.ModeTasksOnly
+	return mode == processor.ModeFull || mode == processor.ModeTasksOnly || mode == processor.ModeTasksReview
 }
 
-// validateFlags checks for conflicting CLI flags.
-func validateFlags(o opts) error {
-	if o.PlanDescription != "" && o.PlanFile != "" {
-		return errors.New("--plan flag conflicts with plan file argument; use one or the other")
+// applyPlansDir overrides the configured plans directory with --plans-dir, so plan selection,
+// plan mode and the move to completed/ all use it. without an explicit plan file the directory
+// must exist (except for review modes, where the plan is optional); a missing one is plan.ErrNoPlansFound.
+func applyPlansDir(o opts, mode processor.Mode, cfg *config.Config) error {
+	if o.PlansDir == "" {
+		return nil
+	}
+	cfg.PlansDir = o.PlansDir
+	if o.PlanFile != "" || mode == processor.ModePlan || mode == processor.ModeReview || mode == processor.ModeCodexOnly {
+		return nil
+	}
+	if _, err := os.Stat(o.PlansDir); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%w: %s (directory missing)", plan.ErrNoPlansFound, o.PlansDir)
+		}
+		return fmt.Errorf("cannot access plans directory %s: %w", o.PlansDir, err)
 	}
 	return nil
 }
 
-// createRunner creates a processor.Runner with the given configuration.
-func createRunner(req executePlanRequest, o opts, log processor.Logger, holder *status.PhaseHolder) *processor.Runner {
-	// --codex-only mode forces codex enabled regardless of config
-	codexEnabled := req.Config.CodexEnabled
-	if req.Mode == processor.ModeCodexOnly {
-		codexEnabled = true
+// stdinPlanArg is the plan file argument reading the plan from stdin.
+const stdinPlanArg = "-"
+
+// saveStdinPlan saves the plan piped to stdin in plansDir when the plan file argument is "-",
+// and replaces the argument with the saved file. from there the plan is an ordinary plan file:
+// it is committed on the plan's branch and moved to completed when done.
+func saveStdinPlan(o opts, plansDir string, stdin io.Reader, colors *progress.Colors) (opts, error) {
+	args := append([]string{o.PlanFile}, o.MorePlanFiles...)
+	idx := slices.Index(args, stdinPlanArg)
+	if idx < 0 {
+		return o, nil
 	}
-	r := processor.New(processor.Config{
-		PlanFile:         req.PlanFile,
-		ProgressPath:     log.Path(),
-		Mode:             req.Mode,
-		MaxIterations:    o.MaxIterations,
-		Debug:            o.Debug,
-		NoColor:          o.NoColor,
-		IterationDelayMs: req.Config.IterationDelayMs,
-		TaskRetryCount:   req.Config.TaskRetryCount,
-		CodexEnabled:     codexEnabled,
-		FinalizeEnabled:  req.Config.FinalizeEnabled,
-		DefaultBranch:    req.DefaultBranch,
-		AppConfig:        req.Config,
-	}, log, holder)
-	if req.GitSvc != nil {
-		r.SetGitChecker(req.GitSvc)
+	if f, ok := stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		return o, errors.New("plan file argument - reads the plan from stdin, but nothing is piped in")
 	}
-	return r
+	path, err := plan.Save(stdin, plansDir, time.Now())
+	if err != nil {
+		return o, fmt.Errorf("read plan from stdin: %w", err)
+	}
+	colors.Info().Printf("plan from stdin saved to %s\n", path)
+	args[idx] = path
+	o.PlanFile, o.MorePlanFiles = args[0], args[1:]
+	return o, nil
+}
+
+// promptInput returns the input for interactive prompts and a function closing it. when the plan file
+// argument is "-", stdin is read to EOF for the plan, so prompts read from the terminal at tty instead;
+// without a terminal they get no input and take the default answer.
+func promptInput(o opts, stdin io.Reader, tty string) (io.Reader, func()) {
+	if o.PlanFile != stdinPlanArg && !slices.Contains(o.MorePlanFiles, stdinPlanArg) {
+		return stdin, func() {}
+	}
+	f, err := os.Open(tty) //nolint:gosec // fixed terminal device path
+	if err != nil {
+		return strings.NewReader(""), func() {}
+	}
+	return f, func() { _ = f.Close() }
+}
+
+// validateFlags checks for conflicting CLI flags.
+func validateFlags(o opts) error {
+	if o.PlanDescription != "" && o.PlanFile != "" {
+		return errors.New("--plan flag conflicts with plan file argument; use one or the other")
+	}
+	if o.AnswersFile != "" && o.PlanDescription == "" {
+		return errors.New("--answers-file requires --plan")
+	}
+	if o.RecordAnswers != "" && o.PlanDescription == "" {
+		return errors.New("--record-answers requires --plan")
+	}
+	if o.Template != "" && o.PlanDescription == "" {
+		return errors.New("--template requires --plan")
+	}
+	if o.RecordAnswers != "" && o.AnswersFile != "" {
+		return errors.New("--record-answers conflicts with --answers-file")
+	}
+	if o.TasksOnly && (o.Review || o.ExternalOnly || o.CodexOnly) {
+		return errors.New("--tasks-only conflicts with --review, --external-only and --codex-only")
+	}
+	if o.TasksReview && (o.TasksOnly || o.Review || o.ExternalOnly || o.CodexOnly) {
+		return errors.New("--tasks-review conflicts with --tasks-only, --review, --external-only and --codex-only")
+	}
+	if len(o.MorePlanFiles) > 0 {
+		if o.Review || o.ExternalOnly || o.CodexOnly {
+			return errors.New("multiple plan files are only supported in full, tasks-only and tasks-review modes")
+		}
+		if o.Serve {
+			return errors.New("--serve is not supported with multiple plan files")
+		}
+	}
+	stdinPlans := 0
+	for _, arg := range append([]string{o.PlanFile}, o.MorePlanFiles...) {
+		if arg == stdinPlanArg {
+			stdinPlans++
+		}
+	}
+	if stdinPlans > 1 {
+		return errors.New("plan file argument - (stdin) can be given only once")
+	}
+	if stdinPlans > 0 && o.DryRun {
+		return errors.New("--dry-run doesn't support reading the plan from stdin")
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("--timeout must be non-negative, got %s", o.Timeout)
+	}
+	if o.Worktree {
+		if o.Review || o.ExternalOnly || o.CodexOnly || o.PlanDescription != "" {
+			return errors.New("--worktree is only supported in full, tasks-only and tasks-review modes")
+		}
+		if len(o.MorePlanFiles) > 0 {
+			return errors.New("--worktree is not supported with multiple plan files")
+		}
+	}
+	if o.CreatePR && (o.Review || o.ExternalOnly || o.CodexOnly || o.TasksOnly || o.TasksReview) {
+		return errors.New("--create-pr is only supported in full mode")
+	}
+	if len(o.Findings) > 0 && !o.ExternalOnly && !o.CodexOnly {
+		return errors.New("--findings requires --external-only or --codex-only")
+	}
+	if o.AutoStash == string(git.AutoStashOriginal) && o.Worktree {
+		return errors.New("--auto-stash=original can't be used with --worktree")
+	}
+	if o.WorktreeCleanup && !o.Worktree {
+		return errors.New("--worktree-cleanup requires --worktree")
+	}
+	for _, name := range o.NoAgents {
+		if slices.Contains(o.Agents, name) {
+			return fmt.Errorf("agent %q is given with both --agent and --no-agent", name)
+		}
+	}
+	if o.Base != "" && o.Diff != "" {
+		return errors.New("--base and --diff can't be used together, the range sets its own base")
+	}
+	if o.Since != "" && (o.Base != "" || o.Diff != "") {
+		return errors.New("--since can't be used with --base or --diff, it sets the base itself")
+	}
+	if from, _, ok := strings.Cut(o.Diff, ".."); o.Diff != "" && (!ok || from == "") {
+		return fmt.Errorf("--diff expects a revision range like main..HEAD, got %q", o.Diff)
+	}
+	return nil
+}
+
+// resolveSince sets the review base to the merge-base of the --since ref with HEAD, so the review prompts
+// get {{BASE_BRANCH}} and {{DIFF_RANGE}} covering only the commits made after it. no-op without --since.
+func resolveSince(o opts, gitSvc *git.Service, colors *progress.Colors) (opts, error) {
+	if o.Since == "" {
+		return o, nil
+	}
+	base, err := gitSvc.MergeBase(o.Since, "HEAD")
+	if err != nil {
+		return o, fmt.Errorf("resolve --since %s: %w", o.Since, err)
+	}
+	colors.Info().Printf("reviewing changes since %s (merge-base %s)\n", o.Since, base)
+	o.Base = base
+	return o, nil
+}
+
+// reviewBase returns the branch reviews compare against: --base, the start of the --diff range,
+// or empty for the default branch.
+func reviewBase(o opts) string {
+	if o.Diff == "" {
+		return o.Base
+	}
+	from, _, _ := strings.Cut(o.Diff, "..")
+	return from
+}
+
+// stallIterations returns the stall window for the runner, 0 when stall detection is disabled.
+func stallIterations(cfg *config.Config) int {
+	if !cfg.StallDetection {
+		return 0
+	}
+	return cfg.StallIterations
+}
+
+// usageReporter provides token usage collected during a run.
+type usageReporter interface {
+	Usage() (phases []processor.Usage, total processor.Usage)
+}
+
+// usageSummary returns the token usage table printed at completion, one row per phase and the total.
+// the cost column is shown only if token prices are set, the tool calls column only if any were reported.
+// phases whose calls reported no tokens show "n/a".
+func usageSummary(r usageReporter, cfg *config.Config) []string {
+	phases, total := r.Usage()
+	if len(phases) == 0 {
+		return nil
+	}
+	perMIn, perMOut := cfg.TokenPrices()
+	withCost := perMIn > 0 || perMOut > 0
+	withTools := total.ToolCalls > 0
+
+	var buf strings.Builder
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	row := func(name string, u processor.Usage) {
+		in, out, cost := u.Columns(perMIn, perMOut)
+		cols := []string{name, in, out}
+		if withCost {
+			cols = append(cols, cost)
+		}
+		if withTools {
+			cols = append(cols, strconv.Itoa(u.ToolCalls))
+		}
+		fmt.Fprintf(tw, "  %s\n", strings.Join(cols, "\t"))
+	}
+	header := []string{"phase", "input", "output"}
+	if withCost {
+		header = append(header, "cost")
+	}
+	if withTools {
+		header = append(header, "tool calls")
+	}
+	fmt.Fprintf(tw, "  %s\n", strings.Join(header, "\t"))
+	for _, u := range phases {
+		row(string(u.Phase), u)
+	}
+	row("total", total)
+	_ = tw.Flush()
+
+	lines := []string{"token usage:"}
+	for line := range strings.SplitSeq(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return lines
+}
+
+// setRunnerMetrics makes the runner report its counters on the dashboard's /metrics endpoint.
+// a no-op without a dashboard or with web_metrics disabled.
+func setRunnerMetrics(r *processor.Runner, dashboard *web.Dashboard) {
+	if dashboard == nil {
+		return
+	}
+	if m := dashboard.Metrics(); m != nil {
+		r.SetMetrics(m)
+	}
+}
+
+// createRunner creates a processor.Runner with the given configuration.
+func createRunner(req executePlanRequest, o opts, log processor.Logger, holder *status.PhaseHolder) *processor.Runner {
+	// --codex-only mode forces codex enabled regardless of config, --tasks-review never uses it
+	codexEnabled := req.Config.CodexEnabled
+	switch req.Mode {
+	case processor.ModeCodexOnly:
+		codexEnabled = true
+	case processor.ModeTasksReview:
+		codexEnabled = false
+	}
+	branch := ""
+	if req.GitSvc != nil {
+		branch = getCurrentBranch(req.GitSvc)
+	}
+	r := processor.New(processor.Config{
+		PlanFile:            req.PlanFile,
+		ProgressPath:        log.Path(),
+		Mode:                req.Mode,
+		MaxIterations:       o.MaxIterations,
+		Debug:               o.Debug || o.Trace,
+		Trace:               o.Trace,
+		NoColor:             o.NoColor,
+		IterationDelayMs:    req.Config.IterationDelayMs,
+		ExecutorTimeoutMs:   req.Config.ExecutorTimeoutMs,
+		ClaudeTimeoutMin:    req.Config.ClaudeTimeoutMinutes,
+		CodexTimeoutMin:     req.Config.CodexTimeoutMinutes,
+		TaskRetryCount:      req.Config.TaskRetryCount,
+		CodexRetryCount:     req.Config.CodexRetryCount,
+		RetryBaseDelayMs:    req.Config.RetryBaseDelayMs,
+		RetryMaxDelayMs:     req.Config.RetryMaxDelayMs,
+		StallIterations:     stallIterations(req.Config),
+		TaskCommitRatio:     req.Config.TaskCommitRatio,
+		StrictTaskVerify:    req.Config.StrictTaskVerification,
+		MaxReviewIterations: req.Config.ReviewLoopIterations,
+		MaxPlanIterations:   req.Config.PlanLoopIterations,
+		CodexEnabled:        codexEnabled,
+		FinalizeEnabled:     req.Config.FinalizeEnabled,
+		DefaultBranch:       req.DefaultBranch,
+		BaseBranch:          reviewBase(o),
+		DiffRange:           o.Diff,
+		Branch:              branch,
+		Resume:              o.Resume,
+		AppConfig:           req.Config,
+		Agents:              req.Agents,
+		Findings:            req.Findings,
+	}, log, holder)
+	if req.GitSvc != nil {
+		r.SetGitChecker(req.GitSvc)
+		r.SetChangeReporter(req.GitSvc)
+	}
+	return r
 }
 
 func printStartupInfo(info startupInfo, colors *progress.Colors) {
@@ -531,18 +1482,452 @@ func printStartupInfo(info startupInfo, colors *progress.Colors) {
 	if info.Mode != processor.ModeFull {
 		modeStr = fmt.Sprintf(" (%s mode)", info.Mode)
 	}
-	colors.Info().Printf("starting ralphex loop: %s (max %d iterations)%s\n", planStr, info.MaxIterations, modeStr)
+	queueStr := ""
+	if info.QueueLen > 1 {
+		queueStr = fmt.Sprintf(" (plan %d of %d)", info.QueuePos, info.QueueLen)
+	}
+	colors.Info().Printf("starting ralphex loop: %s (max %d iterations)%s%s\n", planStr, info.MaxIterations, modeStr, queueStr)
 	colors.Info().Printf("branch: %s\n", info.Branch)
+	if info.KeepPlan {
+		colors.Info().Printf("plan stays in place after completion (move to completed disabled)\n")
+	}
 	colors.Info().Printf("progress log: %s\n\n", info.ProgressPath)
 }
 
+// selectPlans resolves the plan files to execute.
+// modes that require a plan accept several plan files (or fzf multi-selection) to run in sequence,
+// review modes take at most one optional plan for context.
+func selectPlans(ctx context.Context, selector *plan.Selector, o opts, optional bool) ([]string, error) {
+	if optional {
+		planFile, err := selector.Select(ctx, o.PlanFile, true)
+		if err != nil || planFile == "" {
+			return nil, err //nolint:wrapcheck // error is wrapped by the caller
+		}
+		return []string{planFile}, nil
+	}
+
+	var planArgs []string
+	if o.PlanFile != "" {
+		planArgs = append([]string{o.PlanFile}, o.MorePlanFiles...)
+	}
+	return selector.SelectMultiple(ctx, planArgs) //nolint:wrapcheck // error is wrapped by the caller
+}
+
+// runPlanQueue executes several plans one after another, each on its own branch with its own
+// progress log and move-to-completed step. branches are created from the starting branch, and a
+// plan deriving an already used branch name gets a numeric suffix. stops at the first failure
+// and reports which plans completed and which remain, unless --continue-on-error is set.
+func runPlanQueue(ctx context.Context, o opts, planFiles []string, req executePlanRequest) error {
+	// plans picked in fzf bypass validateFlags, check here as well
+	if o.Serve {
+		return errors.New("--serve is not supported with multiple plan files")
+	}
+
+	startBranch := getCurrentBranch(req.GitSvc)
+
+	// branch names are settled upfront, so reuse prompts don't interrupt the queue between plans
+	usedBranches := make(map[string]bool, len(planFiles))
+	branchNames := make([]string, len(planFiles))
+	for i, planFile := range planFiles {
+		name, err := planBranchName(req.Config, planFile)
+		if err != nil {
+			return err
+		}
+		name = uniqueBranchName(name, usedBranches)
+		branchNames[i] = resolvePlanBranch(ctx, o, req.GitSvc, name, usedBranches, req.Stdin, os.Stdout)
+		usedBranches[branchNames[i]] = true
+	}
+
+	var failed []string
+	for i, planFile := range planFiles {
+		planReq := req
+		planReq.PlanFile = planFile
+		planReq.QueuePos, planReq.QueueLen = i+1, len(planFiles)
+		err := runQueuedPlan(ctx, o, planReq, startBranch, branchNames[i], planFiles[i+1:])
+		if err == nil {
+			continue
+		}
+		// a stop requested by the user ends the whole queue
+		if !o.ContinueOnError || errors.Is(err, processor.ErrStopped) {
+			return planQueueError(planFiles, i, err)
+		}
+		failed = append(failed, planFile)
+		req.Colors.Error().Printf("plan %d of %d %s failed: %v, continuing with next plan\n", i+1, len(planFiles), planFile, err)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d plans failed: %s", len(failed), len(planFiles), strings.Join(failed, ", "))
+	}
+	req.Colors.Info().Printf("\nall %d plans completed\n", len(planFiles))
+	return nil
+}
+
+// runQueuedPlan prepares the branch for a single queued plan and executes it.
+// remaining plans are not committed yet and must not block branch creation.
+func runQueuedPlan(ctx context.Context, o opts, req executePlanRequest, startBranch, branchName string, remaining []string) error {
+	// return to the starting branch so each plan gets its own feature branch
+	if req.QueuePos > 1 && startBranch != "unknown" {
+		if err := req.GitSvc.CheckoutBranch(startBranch); err != nil {
+			return err //nolint:wrapcheck // already wrapped by git service
+		}
+	}
+
+	req.Colors.Info().Printf("\nplan %d of %d: %s\n", req.QueuePos, req.QueueLen, req.PlanFile)
+
+	// the progress .gitignore entry added by a previous plan may be uncommitted too
+	pending := append([]string{".gitignore"}, remaining...)
+	if err := req.GitSvc.CreateBranchForPlanAs(req.PlanFile, branchName, pending...); err != nil {
+		return fmt.Errorf("create branch for plan: %w", err)
+	}
+	if err := ensureProgressIgnored(req.GitSvc, req.Config.ProgressDir); err != nil {
+		return err
+	}
+	return executePlan(ctx, o, req)
+}
+
+// commitMessages returns the plan commit message templates configured by commit_msg_add_plan and commit_msg_move_plan.
+func commitMessages(cfg *config.Config) git.CommitMessages {
+	return git.CommitMessages{AddPlan: cfg.CommitMsgAddPlan, MovePlan: cfg.CommitMsgMovePlan}
+}
+
+// planArchive returns where finished plans are moved, configured by completed_dir and completed_dir_date_layout.
+func planArchive(cfg *config.Config) plan.Archive {
+	return plan.Archive{Dir: cfg.CompletedDir, DateLayout: cfg.CompletedDirDateLayout}
+}
+
+// unsafeUserRe matches runs of characters not kept from the user name in branch names.
+var unsafeUserRe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// planBranchName returns the feature branch name derived from the plan file, rendered from branch_template
+// with the configured branch_prefix, or the branch of the plan front-matter as-is. the name is checked with
+// git's ref name rules, so a template producing an invalid name fails before any branch is created.
+func planBranchName(cfg *config.Config, planFile string) (string, error) {
+	settings, _, err := planSettings(planFile)
+	if err != nil {
+		return "", err
+	}
+	if settings.Branch != "" {
+		if err := git.ValidateBranchName(settings.Branch); err != nil {
+			return "", fmt.Errorf("branch of plan front-matter: %w", err)
+		}
+		return settings.Branch, nil
+	}
+
+	name := cfg.BranchPrefix + renderBranchTemplate(cfg.BranchTemplate, plan.ExtractBranchName(planFile), time.Now(), currentUserName())
+	if err := git.ValidateBranchName(name); err != nil {
+		return "", fmt.Errorf("branch name from branch_template: %w", err)
+	}
+	return name, nil
+}
+
+// planSettings reads the front-matter settings of a plan file, a missing file has none.
+func planSettings(planFile string) (plan.Settings, []string, error) {
+	if planFile == "" {
+		return plan.Settings{}, nil, nil
+	}
+	settings, warnings, err := plan.ReadSettings(planFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return plan.Settings{}, nil, nil
+	}
+	if err != nil {
+		return plan.Settings{}, nil, fmt.Errorf("plan %s: %w", planFile, err)
+	}
+	return settings, warnings, nil
+}
+
+// readFindings reads the review findings files passed with --findings, in the given order.
+// returns nil without files, the external review tool runs then.
+func readFindings(paths []string) ([]processor.Findings, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	res := make([]processor.Findings, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path) //nolint:gosec // findings file path from user
+		if err != nil {
+			return nil, fmt.Errorf("read findings: %w", err)
+		}
+		res = append(res, processor.Findings{File: path, Content: string(data)})
+	}
+	return res, nil
+}
+
+// readPlanSettings reads the front-matter settings of a plan file and prints its warnings.
+func readPlanSettings(planFile string, colors *progress.Colors) (plan.Settings, error) {
+	settings, warnings, err := planSettings(planFile)
+	if err != nil {
+		return plan.Settings{}, err
+	}
+	for _, w := range warnings {
+		colors.Warn().Printf("warning: %s: %s\n", planFile, w)
+	}
+	return settings, nil
+}
+
+// applyPlanSettings applies the plan front-matter settings, they win over config and lose to explicit flags.
+// the config is copied before changes, it is shared by all plans of a queue.
+func applyPlanSettings(o opts, req executePlanRequest, s plan.Settings) (opts, executePlanRequest) {
+	if s.MaxIterations != nil && !o.MaxIterationsSet {
+		o.MaxIterations = *s.MaxIterations
+	}
+	req.Agents = s.Agents
+	if s.Codex == nil && s.Finalize == nil {
+		return o, req
+	}
+	cfg := *req.Config
+	if s.Codex != nil {
+		cfg.CodexEnabled = *s.Codex
+	}
+	if s.Finalize != nil {
+		cfg.FinalizeEnabled = *s.Finalize
+	}
+	req.Config = &cfg
+	return o, req
+}
+
+// selectAgents returns the review agents expanded in review prompts, nil for all agents of the config.
+// --agent replaces the agents of the plan front-matter, --no-agent removes agents from the result.
+// names not found in the agents dir or the [custom_agents] config section fail, listing the available ones.
+func selectAgents(o opts, cfg *config.Config, planAgents []string) ([]string, error) {
+	available := make([]string, 0, len(cfg.CustomAgents)+len(cfg.ConfigAgents))
+	for _, a := range slices.Concat(cfg.CustomAgents, cfg.ConfigAgents) {
+		if !slices.Contains(available, a.Name) {
+			available = append(available, a.Name)
+		}
+	}
+	for _, name := range slices.Concat(o.Agents, o.NoAgents) {
+		if !slices.Contains(available, name) {
+			return nil, fmt.Errorf("unknown agent %q, available: %s", name, strings.Join(available, ", "))
+		}
+	}
+
+	agents := planAgents
+	if len(o.Agents) > 0 {
+		agents = o.Agents
+	}
+	if len(o.NoAgents) == 0 {
+		return agents, nil
+	}
+	if agents == nil {
+		agents = available
+	}
+	return slices.DeleteFunc(slices.Clone(agents), func(name string) bool { return slices.Contains(o.NoAgents, name) }), nil
+}
+
+// renderBranchTemplate replaces the {slug}, {date} and {user} placeholders of the template, empty means "{slug}".
+func renderBranchTemplate(tmpl, slug string, now time.Time, user string) string {
+	if tmpl == "" {
+		tmpl = "{slug}"
+	}
+	return strings.NewReplacer("{slug}", slug, "{date}", now.Format("2006-01-02"), "{user}", user).Replace(tmpl)
+}
+
+// currentUserName returns the OS user name reduced to characters safe in branch names, "user" if unknown.
+func currentUserName() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	// windows user names come as DOMAIN\name
+	if i := strings.LastIndex(name, `\`); i >= 0 {
+		name = name[i+1:]
+	}
+	if name = strings.Trim(unsafeUserRe.ReplaceAllString(name, "-"), "-."); name == "" {
+		return "user"
+	}
+	return name
+}
+
+// resolvePlanBranch returns the branch a plan started on main/master should use.
+// an existing branch of that name may carry unrelated history, e.g. from an older plan with the same
+// file name, so the user is asked whether to reuse it. declining picks the first free name-2, name-3, ...
+// not taken by git or used. --yes and --resume reuse the branch without asking.
+// off main/master no branch is created, and the name is returned as is.
+func resolvePlanBranch(ctx context.Context, o opts, gitSvc *git.Service, name string, used map[string]bool,
+	stdin io.Reader, stdout io.Writer) string {
+	if isMain, err := gitSvc.IsMainBranch(); err != nil || !isMain || !gitSvc.BranchExists(name) {
+		return name
+	}
+	if o.Yes || o.Resume || input.AskYesNo(ctx, fmt.Sprintf("branch %s exists, reuse it?", name), stdin, stdout) {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if !used[candidate] && !gitSvc.BranchExists(candidate) {
+			return candidate
+		}
+	}
+}
+
+// uniqueBranchName returns name, or name with a numeric suffix (name-2, name-3, ...) if already used.
+func uniqueBranchName(name string, used map[string]bool) string {
+	if !used[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
+
+// planQueueError wraps err from the plan at index failed with the list of completed and remaining plans.
+func planQueueError(planFiles []string, failed int, err error) error {
+	listOrNone := func(plans []string) string {
+		if len(plans) == 0 {
+			return "none"
+		}
+		return strings.Join(plans, ", ")
+	}
+	return fmt.Errorf("plan %d of %d %s failed: %w\ncompleted: %s\nnot started: %s", failed+1, len(planFiles),
+		planFiles[failed], err, listOrNone(planFiles[:failed]), listOrNone(planFiles[failed+1:]))
+}
+
+// runDryRunQueue prints the dry-run summary for each selected plan (or once if no plan is selected).
+func runDryRunQueue(o opts, planFiles []string, req executePlanRequest) error {
+	if len(planFiles) == 0 {
+		return runDryRun(o, req)
+	}
+	for _, planFile := range planFiles {
+		planReq := req
+		planReq.PlanFile = planFile
+		if err := runDryRun(o, planReq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runDryRun prints the phases a run would execute for the selected plan and mode, then returns.
+// for each phase it shows which prompt files are used and where they are loaded from,
+// so prompt overrides in .ralphex/ or the global config can be verified without a claude run.
+// it does not invoke claude/codex, create branches, touch .gitignore or create a progress file.
+func runDryRun(o opts, req executePlanRequest) error {
+	settings, err := readPlanSettings(req.PlanFile, req.Colors)
+	if err != nil {
+		return err
+	}
+	o, req = applyPlanSettings(o, req, settings)
+	if req.Agents, err = selectAgents(o, req.Config, req.Agents); err != nil {
+		return err
+	}
+
+	holder := &status.PhaseHolder{}
+	r := createRunner(req, o, progress.NewConsoleLogger(req.Colors, holder), holder)
+
+	planStr := req.PlanFile
+	switch {
+	case req.Mode == processor.ModePlan:
+		planStr = "(interactive plan creation: " + o.PlanDescription + ")"
+	case planStr == "":
+		planStr = "(no plan - review only)"
+	}
+
+	info := req.Colors.Info()
+	info.Printf("dry run, no executors will be invoked\n")
+	if has, hasErr := req.GitSvc.HasCommits(); hasErr == nil && !has {
+		info.Printf("repository has no commits yet, a run would create the initial commit first\n")
+	}
+	info.Printf("plan: %s\n", planStr)
+	info.Printf("branch: %s\n", plannedBranch(req.GitSvc, req.Config, req.PlanFile, req.Mode))
+	info.Printf("mode: %s\n", req.Mode)
+	info.Printf("external review: %s\n", r.ExternalReviewTool())
+	info.Printf("max iterations: %d\n", o.MaxIterations)
+	info.Printf("task retries: %d\n", req.Config.TaskRetryCount)
+	iterDelay := processor.DefaultIterationDelay
+	if req.Config.IterationDelayMs > 0 {
+		iterDelay = time.Duration(req.Config.IterationDelayMs) * time.Millisecond
+	}
+	info.Printf("iteration delay: %s\n", iterDelay)
+	info.Printf("planned phases:\n")
+
+	// codex-only mode skips the first review pass, so its only review phase uses review_second
+	firstReview := req.Mode != processor.ModeCodexOnly
+	for i, phase := range r.PlannedPhases() {
+		info.Printf("  %d. %s\n", i+1, phase)
+		for _, name := range phasePromptFiles(phase, r.ExternalReviewTool(), firstReview) {
+			info.Printf("     prompt %s: %s\n", name, req.Config.PromptSource(name))
+		}
+		if phase == status.PhaseReview {
+			firstReview = false
+		}
+	}
+	return nil
+}
+
+// phasePromptFiles returns the prompt files used by the given phase, for --dry-run output.
+// firstReview selects whether a review phase includes the first (all findings) review pass.
+func phasePromptFiles(phase status.Phase, externalTool string, firstReview bool) []string {
+	switch phase {
+	case status.PhaseTask:
+		return []string{"task.txt"}
+	case status.PhaseReview:
+		if firstReview {
+			return []string{"review_first.txt", "review_second.txt"}
+		}
+		return []string{"review_second.txt"}
+	case status.PhaseCodex:
+		switch externalTool {
+		case "custom":
+			return []string{"custom_review.txt", "custom_eval.txt"}
+		case "gemini":
+			return []string{"gemini.txt"}
+		}
+		return []string{"codex.txt"}
+	case status.PhaseFinalize:
+		return []string{"finalize.txt"}
+	case status.PhasePlan:
+		return []string{"make_plan.txt"}
+	default:
+		return nil
+	}
+}
+
+// plannedBranch returns the branch a run would use, without creating it.
+// mirrors CreateBranchForPlan: a feature branch is derived from the plan name only when on main/master.
+func plannedBranch(gitSvc *git.Service, cfg *config.Config, planFile string, mode processor.Mode) string {
+	current := getCurrentBranch(gitSvc)
+	if planFile == "" || !modeRequiresBranch(mode) {
+		return current
+	}
+	if isMain, err := gitSvc.IsMainBranch(); err != nil || !isMain {
+		return current
+	}
+	name, err := planBranchName(cfg, planFile)
+	if err != nil {
+		return fmt.Sprintf("%s (%v)", current, err)
+	}
+	return fmt.Sprintf("%s (from %s)", name, current)
+}
+
 // runPlanMode executes interactive plan creation mode.
 // creates input collector, progress logger, and runs the plan creation loop.
 // after plan creation, prompts user to continue with implementation or exit.
 func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
+	// resolve the plan template first, an unknown name fails with the list of available templates
+	var planTemplate string
+	if o.Template != "" {
+		tmpl, err := req.Config.PlanTemplate(o.Template)
+		if err != nil {
+			return fmt.Errorf("plan template: %w", err)
+		}
+		planTemplate = tmpl
+	}
+
 	// ensure gitignore has progress files
-	if err := req.GitSvc.EnsureIgnored(".ralphex/progress/", ".ralphex/progress/progress-test.txt"); err != nil {
-		return fmt.Errorf("ensure gitignore: %w", err)
+	if err := ensureProgressIgnored(req.GitSvc, req.Config.ProgressDir); err != nil {
+		return err
+	}
+
+	// create input collector, answers from a file run plan creation without a terminal
+	var collector processor.InputCollector = input.NewTerminalCollector(o.NoColor)
+	if o.AnswersFile != "" {
+		fileCollector, err := input.NewFileCollector(o.AnswersFile)
+		if err != nil {
+			return fmt.Errorf("load answers: %w", err)
+		}
+		collector = fileCollector
 	}
 
 	branch := getCurrentBranch(req.GitSvc)
@@ -556,6 +1941,11 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 		Mode:            string(processor.ModePlan),
 		Branch:          branch,
 		NoColor:         o.NoColor,
+		Dir:             req.Config.ProgressDir,
+		Keep:            req.Config.ProgressKeep,
+		Format:          o.LogFormat,
+		MaxSizeBytes:    int64(req.Config.ProgressMaxSizeMB) << 20,
+		MaxBackups:      req.Config.ProgressBackups,
 	}, req.Colors, holder)
 	if err != nil {
 		return fmt.Errorf("create progress logger: %w", err)
@@ -566,6 +1956,33 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 		}
 	}()
 
+	// with --serve, stream plan creation to the dashboard, questions can be answered there too (web_websocket)
+	var runnerLog processor.Logger = baseLog
+	var dashboard *web.Dashboard
+	if o.Serve {
+		questions := web.NewQuestionRelay(collector)
+		dashboard = web.NewDashboard(web.DashboardConfig{
+			BaseLog:     baseLog,
+			Listen:      req.Config.WebListen,
+			Port:        o.Port,
+			PortAuto:    o.PortAuto,
+			Branch:      branch,
+			Colors:      req.Colors,
+			Metrics:     req.Config.WebMetrics,
+			AuthToken:   dashboardToken(o, req.Config),
+			WebSocket:   req.Config.WebWebSocket,
+			Questions:   questions,
+			Stop:        req.Stop,
+			PlanArchive: planArchive(req.Config),
+			Open:        o.Open,
+		}, holder)
+		broadcastLog, dashErr := dashboard.Start(ctx)
+		if dashErr != nil {
+			return fmt.Errorf("start dashboard: %w", dashErr)
+		}
+		runnerLog, collector = broadcastLog, questions
+	}
+
 	// print startup info for plan mode
 	printStartupInfo(startupInfo{
 		PlanDescription: o.PlanDescription,
@@ -575,29 +1992,44 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 		ProgressPath:    baseLog.Path(),
 	}, req.Colors)
 
-	// create input collector
-	collector := input.NewTerminalCollector(o.NoColor)
-
 	// record start time for finding the created plan
 	startTime := time.Now()
 
 	// create and configure runner
 	r := processor.New(processor.Config{
-		PlanDescription:  o.PlanDescription,
-		ProgressPath:     baseLog.Path(),
-		Mode:             processor.ModePlan,
-		MaxIterations:    o.MaxIterations,
-		Debug:            o.Debug,
-		NoColor:          o.NoColor,
-		IterationDelayMs: req.Config.IterationDelayMs,
-		DefaultBranch:    req.DefaultBranch,
-		AppConfig:        req.Config,
-	}, baseLog, holder)
+		PlanDescription:   o.PlanDescription,
+		PlanTemplate:      planTemplate,
+		ProgressPath:      baseLog.Path(),
+		Mode:              processor.ModePlan,
+		MaxIterations:     o.MaxIterations,
+		Debug:             o.Debug || o.Trace,
+		Trace:             o.Trace,
+		NoColor:           o.NoColor,
+		IterationDelayMs:  req.Config.IterationDelayMs,
+		ExecutorTimeoutMs: req.Config.ExecutorTimeoutMs,
+		ClaudeTimeoutMin:  req.Config.ClaudeTimeoutMinutes,
+		MaxPlanIterations: req.Config.PlanLoopIterations,
+		DefaultBranch:     req.DefaultBranch,
+		AppConfig:         req.Config,
+	}, runnerLog, holder)
+	if o.RecordAnswers != "" {
+		recorder, recErr := input.NewRecordingCollector(collector, o.RecordAnswers)
+		if recErr != nil {
+			return fmt.Errorf("record answers: %w", recErr)
+		}
+		collector = recorder
+	}
 	r.SetInputCollector(collector)
+	if req.Stop != nil {
+		r.SetStopHolder(req.Stop)
+	}
+	setRunnerMetrics(r, dashboard)
 
-	// run the plan creation loop
-	if runErr := r.Run(ctx); runErr != nil {
-		return fmt.Errorf("plan creation: %w", runErr)
+	// run the plan creation loop, bounded by --timeout if set
+	runCtx, cancelRun := runnerContext(ctx, req.Deadline)
+	defer cancelRun()
+	if runErr := r.Run(runCtx); runErr != nil {
+		return fmt.Errorf("plan creation: %w", timeoutError(ctx, runCtx, o.Timeout, runErr))
 	}
 
 	// find the newly created plan file
@@ -614,22 +2046,35 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 	} else {
 		req.Colors.Info().Printf("\nplan creation completed in %s\n", elapsed)
 	}
+	for _, line := range usageSummary(r, req.Config) {
+		req.Colors.Info().Printf("%s\n", line)
+	}
 
 	// if no plan file found, can't continue to implementation
 	if planFile == "" {
 		return nil
 	}
 
-	// ask user if they want to continue with plan implementation
-	if !input.AskYesNo(ctx, "Continue with plan implementation?", os.Stdin, os.Stdout) {
+	// ask user if they want to continue with plan implementation, headless runs stop after plan creation
+	if o.AnswersFile != "" || !input.AskYesNo(ctx, "Continue with plan implementation?", os.Stdin, os.Stdout) {
 		return nil
 	}
 
-	// continue with plan implementation
+	// continue with plan implementation, it starts its own dashboard on the same port
 	req.Colors.Info().Printf("\ncontinuing with plan implementation...\n")
+	if dashboard != nil {
+		if err := dashboard.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to stop plan dashboard: %v\n", err)
+		}
+	}
 
 	// create branch if needed
-	if err := req.GitSvc.CreateBranchForPlan(planFile); err != nil {
+	planBranch, err := planBranchName(req.Config, planFile)
+	if err != nil {
+		return err
+	}
+	planBranch = resolvePlanBranch(ctx, o, req.GitSvc, planBranch, nil, os.Stdin, os.Stdout)
+	if err := req.GitSvc.CreateBranchForPlanAs(planFile, planBranch); err != nil {
 		return fmt.Errorf("create branch for plan: %w", err)
 	}
 
@@ -641,6 +2086,10 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 		Colors:        req.Colors,
 		DefaultBranch: req.DefaultBranch,
 		NotifySvc:     req.NotifySvc,
+		Notifier:      req.Notifier,
+		Deadline:      req.Deadline,
+		Stop:          req.Stop,
+		Pause:         req.Pause,
 	})
 }
 
@@ -653,9 +2102,16 @@ func runReset(configDir string, stdin io.Reader, stdout io.Writer) error {
 	return nil
 }
 
-// handleEarlyFlags processes flags that should run before full config load (--reset, --dump-defaults).
+// handleEarlyFlags processes flags that should run before full config load
+// (--reset, --configure, --set, --dump-defaults, --check-config, --print-config).
 // returns (true, nil) if an early exit occurred, (true, err) on error, or (false, nil) to continue.
-func handleEarlyFlags(o opts) (bool, error) {
+func handleEarlyFlags(ctx context.Context, o opts) (bool, error) {
+	if o.ConfigDir != "" {
+		if err := ensureConfigDir(o.ConfigDir); err != nil {
+			return true, err
+		}
+	}
+
 	if o.Reset {
 		if err := runReset(o.ConfigDir, os.Stdin, os.Stdout); err != nil {
 			return true, err
@@ -665,13 +2121,86 @@ func handleEarlyFlags(o opts) (bool, error) {
 		}
 	}
 
+	if o.Configure {
+		return true, runConfigure(ctx, o.ConfigDir, os.Stdin, os.Stdout)
+	}
+
+	if len(o.Set) > 0 {
+		return true, setConfigValues(o.ConfigDir, o.Set, os.Stdout)
+	}
+
 	if o.DumpDefaults != "" {
 		return true, dumpDefaults(o.DumpDefaults)
 	}
 
+	if o.CheckConfig {
+		return true, checkConfig(o.ConfigDir, os.Stdout)
+	}
+
+	if o.PrintConfig {
+		return true, printConfig(o.ConfigDir, os.Stdout)
+	}
+
+	if o.LintPlan != "" {
+		return true, lintPlanFile(o.LintPlan, os.Stdout)
+	}
+
+	if o.Validate {
+		return true, validateAll(o.ConfigDir, o.PlansDir, os.Stdout)
+	}
+
+	if o.List {
+		return true, listPlans(o.ConfigDir, o.PlansDir, o.ListCompleted, os.Stdout)
+	}
+
 	return false, nil
 }
 
+// ensureConfigDir checks that a custom config directory exists or can be created,
+// so a bad --config-dir fails upfront with a clear message instead of deep inside config loading.
+func ensureConfigDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("config dir %s is not a directory", dir)
+		}
+		return nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("config dir %s is not accessible: %w", dir, err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("config dir %s can't be created: %w", dir, err)
+	}
+	return nil
+}
+
+// showTheme prints a sample line in every output color, so users can preview their color config.
+func showTheme(theme string, colors *progress.Colors, w io.Writer) {
+	if theme == "" {
+		theme = "default"
+	}
+	fmt.Fprintf(w, "theme: %s (%s)\n", theme, colors.Palette())
+	samples := []struct {
+		role string
+		clr  *color.Color
+		text string
+	}{
+		{"task", colors.ForPhase(status.PhaseTask), "task execution output"},
+		{"review", colors.ForPhase(status.PhaseReview), "review phase output"},
+		{"codex", colors.ForPhase(status.PhaseCodex), "external review output"},
+		{"claude_eval", colors.ForPhase(status.PhaseClaudeEval), "claude evaluation of external review"},
+		{"warn", colors.Warn(), "warning message"},
+		{"error", colors.Error(), "error message"},
+		{"signal", colors.Signal(), "<<<RALPHEX:ALL_TASKS_DONE>>>"},
+		{"timestamp", colors.Timestamp(), "[26-01-02 15:04:05]"},
+		{"info", colors.Info(), "informational message"},
+	}
+	for _, s := range samples {
+		s.clr.Fprintf(w, "  %-12s %s\n", s.role, s.text)
+	}
+}
+
 // dumpDefaults extracts raw embedded defaults to the specified directory.
 func dumpDefaults(dir string) error {
 	if err := config.DumpDefaults(dir); err != nil {
@@ -681,11 +2210,289 @@ func dumpDefaults(dir string) error {
 	return nil
 }
 
+// runConfigure runs the interactive config editor, an aborted edit is reported but is not an error.
+func runConfigure(ctx context.Context, configDir string, stdin io.Reader, stdout io.Writer) error {
+	_, err := config.Configure(ctx, configDir, stdin, stdout)
+	if errors.Is(err, config.ErrConfigureAborted) {
+		fmt.Fprintln(stdout, err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("configure: %w", err)
+	}
+	return nil
+}
+
+// setConfigValues sets global config values from --set key=value assignments and prints the keys set.
+func setConfigValues(configDir string, assignments []string, w io.Writer) error {
+	res, err := config.SetValues(configDir, assignments)
+	if err != nil {
+		return fmt.Errorf("set config: %w", err)
+	}
+	for _, key := range config.KnownKeys() {
+		if _, ok := res.Changed[key]; ok {
+			fmt.Fprintf(w, "set %s in %s\n", key, res.Path)
+		}
+	}
+	return nil
+}
+
+// printConfig prints every config key with its effective value and source, merged the same way a run loads it.
+func printConfig(configDir string, w io.Writer) error {
+	cfg, err := config.LoadReadOnly(configDir)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if err := cfg.WriteEffective(w); err != nil {
+		return fmt.Errorf("print config: %w", err)
+	}
+	return nil
+}
+
+// checkConfig validates global, repo-root and repo-local config and prints every problem found,
+// followed by the file each set value is taken from when the config loads.
+// prints "config ok" only when there are no problems and the config also loads.
+func checkConfig(configDir string, w io.Writer) error {
+	issues, err := config.Validate(configDir)
+	if err != nil {
+		return fmt.Errorf("check config: %w", err)
+	}
+	for _, issue := range issues {
+		fmt.Fprintln(w, issue)
+	}
+
+	cfg, loadErr := config.LoadReadOnly(configDir)
+	if loadErr == nil {
+		if dir := cfg.LocalDir(); dir != "" {
+			fmt.Fprintf(w, "repo config: %s\n", dir)
+		}
+		if file := cfg.RepoFile(); file != "" {
+			fmt.Fprintf(w, "repo root config: %s\n", file)
+		}
+		fmt.Fprintln(w, "value sources (env > repo > global > embedded):")
+		for _, key := range config.KnownKeys() {
+			if src := cfg.ValueSource(key); src != "" {
+				fmt.Fprintf(w, "  %s: %s\n", key, src)
+			}
+		}
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("config has %d problem(s)", len(issues))
+	}
+	if loadErr != nil {
+		return fmt.Errorf("check config: %w", loadErr)
+	}
+	fmt.Fprintln(w, "config ok")
+	return nil
+}
+
+// lintPlanFile reports problems of a plan file for --lint-plan, the returned error carries the problem count.
+func lintPlanFile(path string, w io.Writer) error {
+	issues, err := plan.LintFile(path, true)
+	if err != nil {
+		return fmt.Errorf("lint plan: %w", err)
+	}
+	for _, issue := range issues {
+		fmt.Fprintln(w, issue)
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("plan has %d problem(s)", len(issues))
+	}
+	fmt.Fprintln(w, "plan ok")
+	return nil
+}
+
+// validateAll checks the config like --check-config and lints every pending plan of the plans directory
+// like --lint-plan, printing a report grouped by config and plans. plansDir overrides plans_dir if set.
+// a config that fails to load counts as a problem and skips the plans, the returned error carries the total of both groups.
+func validateAll(configDir, plansDir string, w io.Writer) error {
+	issues, err := config.Validate(configDir)
+	if err != nil {
+		return fmt.Errorf("validate config: %w", err)
+	}
+	cfg, loadErr := config.LoadReadOnly(configDir)
+	if loadErr != nil && len(issues) == 0 {
+		issues = append(issues, config.Issue{File: "config", Message: loadErr.Error()})
+	}
+	problems := len(issues)
+	if len(issues) == 0 {
+		fmt.Fprintln(w, "config: ok")
+	} else {
+		fmt.Fprintf(w, "config: %d problem(s)\n", len(issues))
+		for _, issue := range issues {
+			fmt.Fprintf(w, "  %s\n", issue)
+		}
+	}
+	if loadErr != nil {
+		fmt.Fprintln(w, "plans: skipped, config can't be loaded")
+		return fmt.Errorf("validation found %d problem(s)", problems)
+	}
+
+	if plansDir != "" {
+		cfg.PlansDir = plansDir
+	}
+	selector := plan.NewSelector(cfg.PlansDir, nil)
+	selector.Archive = planArchive(cfg)
+	planFiles, err := selector.Pending()
+	if err != nil {
+		return fmt.Errorf("validate plans: %w", err)
+	}
+	fmt.Fprintf(w, "plans in %s: %d\n", cfg.PlansDir, len(planFiles))
+	for _, planFile := range planFiles {
+		planIssues, lintErr := plan.LintFile(planFile, true)
+		if lintErr != nil {
+			planIssues = []plan.LintIssue{{Text: lintErr.Error(), Fatal: true}}
+		}
+		if len(planIssues) == 0 {
+			continue
+		}
+		problems += len(planIssues)
+		fmt.Fprintf(w, "  %s:\n", planFile)
+		for _, issue := range planIssues {
+			fmt.Fprintf(w, "    %s\n", issue)
+		}
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("validation found %d problem(s)", problems)
+	}
+	fmt.Fprintln(w, "all ok")
+	return nil
+}
+
+// listPlans prints the pending plans of the plans directory for --list, with the branch each one runs on
+// and its completed/total task count, and with completed set also the plans in the completed directory.
+// plansDir overrides plans_dir if set.
+func listPlans(configDir, plansDir string, completed bool, w io.Writer) error {
+	cfg, err := config.LoadReadOnly(configDir)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if plansDir != "" {
+		cfg.PlansDir = plansDir
+	}
+	selector := plan.NewSelector(cfg.PlansDir, nil)
+	selector.Archive = planArchive(cfg)
+
+	pending, err := selector.Pending()
+	if err != nil {
+		return fmt.Errorf("list plans: %w", err)
+	}
+	fmt.Fprintf(w, "plans in %s: %d\n", cfg.PlansDir, len(pending))
+	writePlanList(w, cfg.PlansDir, pending)
+
+	if !completed {
+		return nil
+	}
+	done, err := selector.Completed()
+	if err != nil {
+		return fmt.Errorf("list completed plans: %w", err)
+	}
+	fmt.Fprintf(w, "completed plans: %d\n", len(done))
+	writePlanList(w, cfg.PlansDir, done)
+	return nil
+}
+
+// writePlanList prints plan files relative to plansDir as aligned columns: name, branch and tasks done/total.
+func writePlanList(w io.Writer, plansDir string, planFiles []string) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, planFile := range planFiles {
+		name := planFile
+		if rel, err := filepath.Rel(plansDir, planFile); err == nil {
+			name = rel
+		}
+		tasks := "unreadable"
+		if content, err := os.ReadFile(planFile); err == nil { //nolint:gosec // plan file found in the plans directory
+			done, total := plan.CountTasks(string(content))
+			tasks = fmt.Sprintf("%d/%d", done, total)
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", name, plan.ExtractBranchName(planFile), tasks)
+	}
+	_ = tw.Flush()
+}
+
+// lintPlan checks a plan file before execution. errors abort the run,
+// warnings are printed and the user is asked whether to continue anyway.
+func lintPlan(ctx context.Context, planFile string, stdin io.Reader, stdout io.Writer) error {
+	issues, err := plan.LintFile(planFile, true)
+	if err != nil {
+		return fmt.Errorf("lint plan: %w", err)
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+
+	fatal := 0
+	fmt.Fprintf(stdout, "plan %s:\n", planFile)
+	for _, issue := range issues {
+		fmt.Fprintf(stdout, "  %s\n", issue)
+		if issue.Fatal {
+			fatal++
+		}
+	}
+	if fatal > 0 {
+		return fmt.Errorf("plan %s has %d error(s)", planFile, fatal)
+	}
+	if !input.AskYesNo(ctx, "continue despite plan warnings?", stdin, stdout) {
+		return fmt.Errorf("plan %s has warnings, aborted", planFile)
+	}
+	return nil
+}
+
 // isResetOnly returns true if --reset was the only meaningful flag/arg specified.
 // this allows reset to work standalone (exit after reset) while also supporting
 // combined usage like "ralphex --reset docs/plans/feature.md".
 func isResetOnly(o opts) bool {
-	return o.PlanFile == "" && !o.Review && !o.ExternalOnly && !o.CodexOnly && !o.TasksOnly && !o.Serve && o.PlanDescription == "" && len(o.Watch) == 0 && o.DumpDefaults == ""
+	return o.PlanFile == "" && !o.Review && !o.ExternalOnly && !o.CodexOnly && !o.TasksOnly && !o.TasksReview && !o.Serve && o.PlanDescription == "" && len(o.Watch) == 0 && o.DumpDefaults == "" && !o.CheckConfig && !o.PrintConfig && !o.Configure && len(o.Set) == 0 && o.LintPlan == "" && !o.Validate && !o.List && !o.Worktree && !o.ShowTheme
+}
+
+// watchSignals returns a context canceled on SIGTERM and on Ctrl+C outside of a run.
+// the first Ctrl+C during a run asks it to stop after the current iteration instead,
+// a second one while it is stopping cancels the context. the returned cancel also stops the watching.
+// pauseSignal pauses the run before its next iteration and resumeSignal resumes it, where supported.
+func watchSignals(parent context.Context, stop *status.StopHolder, pause *status.PauseHolder) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	sigCh := make(chan os.Signal, 1)
+	signals := slices.Clone(stopSignals)
+	if pauseSignal != nil {
+		signals = append(signals, pauseSignal, resumeSignal)
+	}
+	signal.Notify(sigCh, signals...)
+	go handleSignals(ctx, sigCh, stop, pause, cancel, os.Stderr)
+	return ctx, func() {
+		signal.Stop(sigCh)
+		cancel()
+	}
+}
+
+// handleSignals cancels on the first signal that isn't turned into a stop, pause or resume request, see watchSignals.
+func handleSignals(ctx context.Context, sigCh <-chan os.Signal, stop *status.StopHolder, pause *status.PauseHolder,
+	cancel context.CancelFunc, w io.Writer) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-sigCh:
+			switch {
+			case sig == os.Interrupt && stop.Request():
+				fmt.Fprintf(w, "\nstopping after the current iteration, press Ctrl+C again to abort now\n")
+				continue
+			case sig == pauseSignal:
+				if pause.Pause() {
+					fmt.Fprintf(w, "\npausing before the next iteration, send SIGUSR2 to resume\n")
+				}
+				continue
+			case sig == resumeSignal:
+				if pause.Resume() {
+					fmt.Fprintf(w, "\nresuming\n")
+				}
+				continue
+			}
+			cancel()
+			return
+		}
+	}
 }
 
 // startInterruptWatcher prints immediate feedback when context is canceled.
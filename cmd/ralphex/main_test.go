@@ -3,17 +3,21 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
+	"github.com/jessevdk/go-flags"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/forge"
 	"github.com/umputun/ralphex/pkg/git"
 	gitmocks "github.com/umputun/ralphex/pkg/git/mocks"
 	"github.com/umputun/ralphex/pkg/notify"
@@ -112,6 +116,7 @@ func TestDetermineMode(t *testing.T) {
 		{name: "tasks_only_takes_precedence_over_codex", opts: opts{TasksOnly: true, CodexOnly: true}, expected: processor.ModeTasksOnly},
 		{name: "tasks_only_takes_precedence_over_external", opts: opts{TasksOnly: true, ExternalOnly: true}, expected: processor.ModeTasksOnly},
 		{name: "tasks_only_takes_precedence_over_review", opts: opts{TasksOnly: true, Review: true}, expected: processor.ModeTasksOnly},
+		{name: "tasks_review_flag", opts: opts{TasksReview: true}, expected: processor.ModeTasksReview},
 		{name: "plan_flag", opts: opts{PlanDescription: "add caching"}, expected: processor.ModePlan},
 		{name: "plan_takes_precedence_over_review", opts: opts{PlanDescription: "add caching", Review: true}, expected: processor.ModePlan},
 		{name: "plan_takes_precedence_over_codex", opts: opts{PlanDescription: "add caching", CodexOnly: true}, expected: processor.ModePlan},
@@ -150,13 +155,34 @@ func TestIsWatchOnlyMode(t *testing.T) {
 	}
 }
 
+func TestDashboardToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     opts
+		cfg      config.Config
+		expected string
+	}{
+		{name: "none", expected: ""},
+		{name: "config only", cfg: config.Config{WebAuthToken: "from-config"}, expected: "from-config"},
+		{name: "option only", opts: opts{DashboardToken: "from-option"}, expected: "from-option"},
+		{name: "option overrides config", opts: opts{DashboardToken: "from-option"}, cfg: config.Config{WebAuthToken: "from-config"},
+			expected: "from-option"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, dashboardToken(tc.opts, &tc.cfg))
+		})
+	}
+}
+
 func TestPlanFlagConflict(t *testing.T) {
 	t.Run("returns_error_when_plan_and_planfile_both_set", func(t *testing.T) {
 		o := opts{
 			PlanDescription: "add caching",
 			PlanFile:        "docs/plans/some-plan.md",
 		}
-		err := run(context.Background(), o)
+		err := run(context.Background(), o, nil, nil)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "--plan flag conflicts")
 	})
@@ -164,7 +190,7 @@ func TestPlanFlagConflict(t *testing.T) {
 	t.Run("no_error_when_only_plan_flag_set", func(t *testing.T) {
 		// this test will fail at a later point (missing git repo etc), but not at validation
 		o := opts{PlanDescription: "add caching"}
-		err := run(context.Background(), o)
+		err := run(context.Background(), o, nil, nil)
 		// should fail at git repo check, not at validation
 		require.Error(t, err)
 		assert.NotContains(t, err.Error(), "--plan flag conflicts")
@@ -173,7 +199,7 @@ func TestPlanFlagConflict(t *testing.T) {
 	t.Run("no_error_when_only_planfile_set", func(t *testing.T) {
 		// this test will fail at a later point (file not found etc), but not at validation
 		o := opts{PlanFile: "nonexistent-plan.md"}
-		err := run(context.Background(), o)
+		err := run(context.Background(), o, nil, nil)
 		// should fail at git repo check, not at validation
 		require.Error(t, err)
 		assert.NotContains(t, err.Error(), "--plan flag conflicts")
@@ -194,7 +220,7 @@ func TestPlanModeIntegration(t *testing.T) {
 		t.Cleanup(func() { _ = os.Chdir(origDir) })
 
 		o := opts{PlanDescription: "add caching feature"}
-		err = run(context.Background(), o)
+		err = run(context.Background(), o, nil, nil)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "no .git directory")
 	})
@@ -213,7 +239,7 @@ func TestPlanModeIntegration(t *testing.T) {
 		cancel() // cancel immediately to stop execution
 
 		o := opts{PlanDescription: "add caching feature", MaxIterations: 1}
-		err = run(ctx, o)
+		err = run(ctx, o, nil, nil)
 
 		// should fail with context canceled, not validation errors
 		require.Error(t, err)
@@ -247,7 +273,7 @@ func TestPlanModeIntegration(t *testing.T) {
 		cancel()
 
 		o := opts{PlanDescription: "test plan description", MaxIterations: 1}
-		err = run(ctx, o)
+		err = run(ctx, o, nil, nil)
 
 		// error should be from plan creation (context canceled), not from config or validation
 		require.Error(t, err)
@@ -277,7 +303,7 @@ func TestAutoPlanModeDetection(t *testing.T) {
 
 		// run without arguments - should error because we're on feature branch
 		o := opts{MaxIterations: 1}
-		err = run(context.Background(), o)
+		err = run(context.Background(), o, nil, nil)
 		require.Error(t, err)
 		// should still get the no plans found error, not auto-plan-mode
 		assert.ErrorIs(t, err, plan.ErrNoPlansFound, "should return ErrNoPlansFound on feature branch")
@@ -298,15 +324,15 @@ func TestAutoPlanModeDetection(t *testing.T) {
 		require.NoError(t, os.MkdirAll("docs/plans", 0o750))
 
 		// run in review mode with canceled context - should not trigger auto-plan-mode
-		// plan is optional in review mode, so it proceeds (then fails on canceled context)
+		// plan is optional in review mode, so it proceeds
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel() // cancel immediately to avoid actual execution
 
 		o := opts{Review: true, MaxIterations: 1}
-		err = run(ctx, o)
-		// error should be from context cancellation or runner, not "no plans found"
+		err = run(ctx, o, nil, nil)
+		// the branch has no commits ahead of master, the runner skips reviews and returns nil;
+		// any error must come from the runner, not "no plans found"
 		// this verifies auto-plan-mode is skipped for --review flag
-		require.Error(t, err)
 		assert.NotErrorIs(t, err, plan.ErrNoPlansFound, "review mode should skip auto-plan-mode")
 	})
 
@@ -325,15 +351,15 @@ func TestAutoPlanModeDetection(t *testing.T) {
 		require.NoError(t, os.MkdirAll("docs/plans", 0o750))
 
 		// run in codex-only mode with canceled context - should not trigger auto-plan-mode
-		// plan is optional in codex-only mode, so it proceeds (then fails on canceled context)
+		// plan is optional in codex-only mode, so it proceeds
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel() // cancel immediately to avoid actual execution
 
 		o := opts{CodexOnly: true, MaxIterations: 1}
-		err = run(ctx, o)
-		// error should be from context cancellation or runner, not "no plans found"
+		err = run(ctx, o, nil, nil)
+		// the branch has no commits ahead of master, the runner skips reviews and returns nil;
+		// any error must come from the runner, not "no plans found"
 		// this verifies auto-plan-mode is skipped for --codex-only flag
-		require.Error(t, err)
 		assert.NotErrorIs(t, err, plan.ErrNoPlansFound, "codex-only mode should skip auto-plan-mode")
 	})
 
@@ -352,36 +378,321 @@ func TestAutoPlanModeDetection(t *testing.T) {
 		require.NoError(t, os.MkdirAll("docs/plans", 0o750))
 
 		// run in external-only mode with canceled context - should not trigger auto-plan-mode
-		// plan is optional in external-only mode, so it proceeds (then fails on canceled context)
+		// plan is optional in external-only mode, so it proceeds
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel() // cancel immediately to avoid actual execution
 
 		o := opts{ExternalOnly: true, MaxIterations: 1}
-		err = run(ctx, o)
-		// error should be from context cancellation or runner, not "no plans found"
+		err = run(ctx, o, nil, nil)
+		// the branch has no commits ahead of master, the runner skips reviews and returns nil;
+		// any error must come from the runner, not "no plans found"
 		// this verifies auto-plan-mode is skipped for --external-only flag
-		require.Error(t, err)
 		assert.NotErrorIs(t, err, plan.ErrNoPlansFound, "external-only mode should skip auto-plan-mode")
 	})
 }
 
-func TestCheckClaudeDep(t *testing.T) {
+func TestCheckAgentDep(t *testing.T) {
 	t.Run("uses_configured_command", func(t *testing.T) {
 		cfg := &config.Config{ClaudeCommand: "nonexistent-command-12345"}
-		err := checkClaudeDep(cfg)
+		err := checkAgentDep(cfg)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "nonexistent-command-12345")
 	})
 
 	t.Run("falls_back_to_claude_when_empty", func(t *testing.T) {
 		cfg := &config.Config{ClaudeCommand: ""}
-		err := checkClaudeDep(cfg)
+		err := checkAgentDep(cfg)
 		// may pass or fail depending on whether claude is installed
 		// but error message should reference "claude" not empty string
 		if err != nil {
 			assert.Contains(t, err.Error(), "claude")
 		}
 	})
+
+	t.Run("uses_gemini_command_for_gemini_backend", func(t *testing.T) {
+		cfg := &config.Config{AgentBackend: "gemini", ClaudeCommand: "sh", GeminiCommand: "nonexistent-gemini-12345"}
+		err := checkAgentDep(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "nonexistent-gemini-12345")
+	})
+
+	t.Run("gemini_backend_ignores_claude_command", func(t *testing.T) {
+		cfg := &config.Config{AgentBackend: "gemini", ClaudeCommand: "nonexistent-command-12345", GeminiCommand: "sh"}
+		require.NoError(t, checkAgentDep(cfg))
+	})
+
+	t.Run("claude_through_wrapper", func(t *testing.T) {
+		wrapper := filepath.Join(t.TempDir(), "fake-ssh")
+		require.NoError(t, os.WriteFile(wrapper, []byte("#!/bin/sh\nexec sh -c \"$*\"\n"), 0o700)) //nolint:gosec // test script
+		require.NoError(t, checkAgentDep(&config.Config{ClaudeCommand: "sh", ClaudeCommandWrapper: wrapper}))
+
+		err := checkAgentDep(&config.Config{ClaudeCommand: "nonexistent-command-12345", ClaudeCommandWrapper: wrapper})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "nonexistent-command-12345 not found through wrapper")
+	})
+
+	t.Run("unknown_backend", func(t *testing.T) {
+		err := checkAgentDep(&config.Config{AgentBackend: "qwen"})
+		require.EqualError(t, err, `unknown agent_backend "qwen", expected claude, gemini or ollama`)
+	})
+
+	t.Run("ollama_backend_requires_model", func(t *testing.T) {
+		cfg := &config.Config{AgentBackend: "ollama", ClaudeCommand: "nonexistent-command-12345"}
+		require.EqualError(t, checkAgentDep(cfg), "ollama_model is required with agent_backend = ollama")
+		cfg.OllamaModel = "qwen2.5-coder"
+		require.NoError(t, checkAgentDep(cfg))
+	})
+}
+
+func TestStallIterations(t *testing.T) {
+	assert.Equal(t, 3, stallIterations(&config.Config{StallDetection: true, StallIterations: 3}))
+	assert.Equal(t, 0, stallIterations(&config.Config{StallDetection: false, StallIterations: 3}))
+}
+
+// fakeUsage returns fixed token usage.
+type fakeUsage struct {
+	phases []processor.Usage
+	total  processor.Usage
+}
+
+func (f fakeUsage) Usage() (phases []processor.Usage, total processor.Usage) {
+	return f.phases, f.total
+}
+
+func TestUsageSummary(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage fakeUsage
+		cfg   config.Config
+		want  []string
+	}{
+		{name: "no calls", usage: fakeUsage{}},
+		{name: "tokens without prices",
+			usage: fakeUsage{
+				phases: []processor.Usage{
+					{Phase: status.PhaseTask, InputTokens: 45_100, OutputTokens: 7_800, Calls: 3, Reported: 3},
+					{Phase: status.PhaseCodex, Calls: 2},
+				},
+				total: processor.Usage{InputTokens: 45_100, OutputTokens: 7_800, Calls: 5, Reported: 3},
+			},
+			want: []string{"token usage:",
+				"  phase  input  output",
+				"  task   45.1k  7.8k",
+				"  codex  n/a    n/a",
+				"  total  45.1k  7.8k"}},
+		{name: "tokens with prices",
+			usage: fakeUsage{
+				phases: []processor.Usage{{Phase: status.PhaseTask, InputTokens: 100_000, OutputTokens: 10_000, Calls: 1, Reported: 1}},
+				total:  processor.Usage{InputTokens: 100_000, OutputTokens: 10_000, Calls: 1, Reported: 1},
+			},
+			cfg: config.Config{PriceInput: 3, PriceOutput: 15},
+			want: []string{"token usage:",
+				"  phase  input   output  cost",
+				"  task   100.0k  10.0k   $0.45",
+				"  total  100.0k  10.0k   $0.45"}},
+		{name: "legacy per 1k prices",
+			usage: fakeUsage{
+				phases: []processor.Usage{{Phase: status.PhaseTask, InputTokens: 100_000, OutputTokens: 10_000, Calls: 1, Reported: 1}},
+				total:  processor.Usage{InputTokens: 100_000, OutputTokens: 10_000, Calls: 1, Reported: 1},
+			},
+			cfg: config.Config{CostPer1kInput: 0.003, CostPer1kOutput: 0.015},
+			want: []string{"token usage:",
+				"  phase  input   output  cost",
+				"  task   100.0k  10.0k   $0.45",
+				"  total  100.0k  10.0k   $0.45"}},
+		{name: "tool calls",
+			usage: fakeUsage{
+				phases: []processor.Usage{{Phase: status.PhaseTask, InputTokens: 950, OutputTokens: 40, Calls: 1, Reported: 1, ToolCalls: 7}},
+				total:  processor.Usage{InputTokens: 950, OutputTokens: 40, Calls: 1, Reported: 1, ToolCalls: 7},
+			},
+			want: []string{"token usage:",
+				"  phase  input  output  tool calls",
+				"  task   950    40      7",
+				"  total  950    40      7"}},
+		{name: "backend without token reports",
+			usage: fakeUsage{phases: []processor.Usage{{Phase: status.PhaseTask, Calls: 4}}, total: processor.Usage{Calls: 4}},
+			cfg:   config.Config{PriceInput: 3},
+			want: []string{"token usage:",
+				"  phase  input  output  cost",
+				"  task   n/a    n/a     n/a",
+				"  total  n/a    n/a     n/a"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, usageSummary(tc.usage, &tc.cfg))
+		})
+	}
+}
+
+func TestWantPullRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		opts opts
+		cfg  config.Config
+		want bool
+	}{
+		{name: "not_requested", opts: opts{}, want: false},
+		{name: "flag_in_full_mode", opts: opts{CreatePR: true}, want: true},
+		{name: "config_in_full_mode", cfg: config.Config{PREnabled: true}, want: true},
+		{name: "config_in_plan_mode", opts: opts{PlanDescription: "add feature"}, cfg: config.Config{PREnabled: true}, want: true},
+		{name: "config_in_review_mode", opts: opts{Review: true}, cfg: config.Config{PREnabled: true}, want: false},
+		{name: "config_in_tasks_only_mode", opts: opts{TasksOnly: true}, cfg: config.Config{PREnabled: true}, want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, wantPullRequest(tc.opts, &tc.cfg))
+		})
+	}
+}
+
+// fakePRCreator records pull requests instead of opening them.
+type fakePRCreator struct {
+	prs      []forge.PullRequest
+	err      error
+	depErr   error
+	existing string // url of an open pull request of the branch
+}
+
+func (f *fakePRCreator) CheckDep() error { return f.depErr }
+
+func (f *fakePRCreator) FindPullRequest(context.Context, string) (string, error) {
+	return f.existing, nil
+}
+
+func (f *fakePRCreator) CreatePullRequest(_ context.Context, pr forge.PullRequest) (string, error) {
+	f.prs = append(f.prs, pr)
+	if f.err != nil {
+		return "", f.err
+	}
+	return "https://github.com/owner/repo/pull/1", nil
+}
+
+func TestOpenPullRequest(t *testing.T) {
+	dir := setupTestRepo(t)
+	gitSvc, err := git.NewService(dir, noopLogger())
+	require.NoError(t, err)
+
+	planFile := filepath.Join(dir, "2024-01-15-add-auth.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Add auth\n\n## Overview\nAdd login.\n\n## Tasks\n"), 0o600))
+	draft := newPullRequestDraft(gitSvc, planFile)
+	assert.Equal(t, "Add auth", draft.title)
+	assert.Equal(t, "## Overview\nAdd login.\n\n## Tasks", draft.content)
+	assert.NotEmpty(t, draft.headBefore)
+	draft.footer = "done"
+
+	untitled := filepath.Join(dir, "2024-01-15-fix-login.md")
+	require.NoError(t, os.WriteFile(untitled, []byte("## Overview\nFix it.\n"), 0o600))
+	assert.Equal(t, "Fix login", newPullRequestDraft(gitSvc, untitled).title)
+
+	runGit(t, dir, "checkout", "-b", "add-auth")
+	for _, msg := range []string{"add handler", "add tests"} {
+		runGit(t, dir, "commit", "--allow-empty", "-m", msg)
+	}
+
+	t.Run("creates_pr_with_plan_commits_and_footer", func(t *testing.T) {
+		creator := &fakePRCreator{}
+		url, existing, err := openPullRequest(context.Background(), creator, gitSvc, draft, "add-auth", "origin/master")
+		require.NoError(t, err)
+		assert.False(t, existing)
+		assert.Equal(t, "https://github.com/owner/repo/pull/1", url)
+		require.Len(t, creator.prs, 1)
+		assert.Equal(t, forge.PullRequest{
+			Title: "Add auth",
+			Body:  "## Overview\nAdd login.\n\n## Tasks\n\n## Commits\n\n- add handler\n- add tests\n\n---\n\ndone\n",
+			Base:  "master",
+			Head:  "add-auth",
+		}, creator.prs[0])
+	})
+
+	t.Run("unknown_head_skips_commits", func(t *testing.T) {
+		creator := &fakePRCreator{}
+		_, _, err := openPullRequest(context.Background(), creator, gitSvc, pullRequestDraft{title: "Add auth"}, "add-auth", "master")
+		require.NoError(t, err)
+		require.Len(t, creator.prs, 1)
+		assert.Empty(t, creator.prs[0].Body)
+	})
+
+	t.Run("reuses_open_pr", func(t *testing.T) {
+		creator := &fakePRCreator{existing: "https://github.com/owner/repo/pull/7"}
+		url, existing, err := openPullRequest(context.Background(), creator, gitSvc, draft, "add-auth", "master")
+		require.NoError(t, err)
+		assert.True(t, existing)
+		assert.Equal(t, "https://github.com/owner/repo/pull/7", url)
+		assert.Empty(t, creator.prs)
+	})
+
+	t.Run("creator_error", func(t *testing.T) {
+		creator := &fakePRCreator{err: errors.New("create pull request: gh: exit status 1")}
+		_, _, err := openPullRequest(context.Background(), creator, gitSvc, draft, "add-auth", "master")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "create pull request")
+	})
+
+	t.Run("bad_head_fails", func(t *testing.T) {
+		creator := &fakePRCreator{}
+		_, _, err := openPullRequest(context.Background(), creator, gitSvc, pullRequestDraft{headBefore: "deadbeef"}, "add-auth", "master")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "list commits deadbeef..HEAD")
+		assert.Empty(t, creator.prs)
+	})
+}
+
+func TestPublishBranch(t *testing.T) {
+	setup := func(t *testing.T, withRemote bool) (executePlanRequest, string) {
+		t.Helper()
+		dir := setupTestRepo(t)
+		remote := ""
+		if withRemote {
+			remote = t.TempDir()
+			runGit(t, remote, "init", "--bare")
+			runGit(t, dir, "remote", "add", "origin", remote)
+		}
+		runGit(t, dir, "checkout", "-b", "add-auth")
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+		return executePlanRequest{GitSvc: gitSvc, Colors: testColors(), DefaultBranch: "master"}, remote
+	}
+	draft := pullRequestDraft{title: "Add auth"}
+
+	t.Run("no_origin_skips_push_and_pr", func(t *testing.T) {
+		req, _ := setup(t, false)
+		creator := &fakePRCreator{}
+		publishBranch(context.Background(), req, creator, "add-auth", true, draft)
+		assert.Empty(t, creator.prs)
+	})
+
+	t.Run("pushes_and_opens_pr", func(t *testing.T) {
+		req, remote := setup(t, true)
+		creator := &fakePRCreator{}
+		publishBranch(context.Background(), req, creator, "add-auth", true, draft)
+		assert.FileExists(t, filepath.Join(remote, "refs", "heads", "add-auth"))
+		require.Len(t, creator.prs, 1)
+		assert.Equal(t, "Add auth", creator.prs[0].Title)
+	})
+
+	t.Run("push_only", func(t *testing.T) {
+		req, remote := setup(t, true)
+		creator := &fakePRCreator{}
+		publishBranch(context.Background(), req, creator, "add-auth", false, draft)
+		assert.FileExists(t, filepath.Join(remote, "refs", "heads", "add-auth"))
+		assert.Empty(t, creator.prs)
+	})
+
+	t.Run("without_gh_pushes_only", func(t *testing.T) {
+		req, remote := setup(t, true)
+		creator := &fakePRCreator{depErr: errors.New("gh not found in PATH")}
+		publishBranch(context.Background(), req, creator, "add-auth", true, draft)
+		assert.FileExists(t, filepath.Join(remote, "refs", "heads", "add-auth"))
+		assert.Empty(t, creator.prs)
+	})
+}
+
+func TestPullRequestFooter(t *testing.T) {
+	assert.Equal(t, "Implemented by [ralphex](https://github.com/umputun/ralphex) in 12m5s (3 files, +40/-2 lines), "+
+		"progress log: `.ralphex/progress/progress-add-auth.txt`",
+		pullRequestFooter("12m5s", git.DiffStats{Files: 3, Additions: 40, Deletions: 2}, ".ralphex/progress/progress-add-auth.txt"))
+	assert.Equal(t, "Implemented by [ralphex](https://github.com/umputun/ralphex) in 1m",
+		pullRequestFooter("1m", git.DiffStats{}, ""))
 }
 
 func TestCreateRunner(t *testing.T) {
@@ -454,6 +765,574 @@ func TestGetCurrentBranch(t *testing.T) {
 	})
 }
 
+func TestPlannedBranch(t *testing.T) {
+	dir := setupTestRepo(t)
+	gitSvc, err := git.NewService(dir, noopLogger())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		planFile string
+		mode     processor.Mode
+		want     string
+	}{
+		{name: "full_mode_on_master_derives_branch", planFile: "docs/plans/add-feature.md", mode: processor.ModeFull, want: "add-feature (from master)"},
+		{name: "tasks_only_on_master_derives_branch", planFile: "docs/plans/add-feature.md", mode: processor.ModeTasksOnly, want: "add-feature (from master)"},
+		{name: "review_mode_keeps_current", planFile: "docs/plans/add-feature.md", mode: processor.ModeReview, want: "master"},
+		{name: "no_plan_keeps_current", planFile: "", mode: processor.ModeFull, want: "master"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, plannedBranch(gitSvc, &config.Config{}, tc.planFile, tc.mode))
+		})
+	}
+
+	t.Run("branch_prefix_applied", func(t *testing.T) {
+		cfg := &config.Config{BranchPrefix: "ralphex/"}
+		assert.Equal(t, "ralphex/add-feature (from master)", plannedBranch(gitSvc, cfg, "docs/plans/add-feature.md", processor.ModeFull))
+	})
+
+	t.Run("feature_branch_keeps_current", func(t *testing.T) {
+		runGit(t, dir, "checkout", "-b", "existing")
+		t.Cleanup(func() { runGit(t, dir, "checkout", "master") })
+		assert.Equal(t, "existing", plannedBranch(gitSvc, &config.Config{}, "docs/plans/add-feature.md", processor.ModeFull))
+	})
+}
+
+func TestPlanBranchName(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		want    string
+		wantErr string
+	}{
+		{name: "default", cfg: &config.Config{}, want: "fix-tests"},
+		{name: "prefix", cfg: &config.Config{BranchPrefix: "ralphex/"}, want: "ralphex/fix-tests"},
+		{name: "template with prefix", cfg: &config.Config{BranchPrefix: "ralphex/", BranchTemplate: "{slug}-wip"},
+			want: "ralphex/fix-tests-wip"},
+		{name: "user", cfg: &config.Config{BranchTemplate: "{user}/{slug}"}, want: currentUserName() + "/fix-tests"},
+		{name: "invalid name", cfg: &config.Config{BranchTemplate: "{slug}..old"}, wantErr: "branch name from branch_template"},
+		{name: "trailing slash", cfg: &config.Config{BranchTemplate: "{slug}/"}, wantErr: "branch name from branch_template"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			name, err := planBranchName(tc.cfg, "docs/plans/2024-01-15-fix-tests.md")
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, name)
+		})
+	}
+}
+
+func TestPlanBranchName_FrontMatter(t *testing.T) {
+	dir := t.TempDir()
+	planFile := filepath.Join(dir, "2024-01-15-fix-tests.md")
+	cfg := &config.Config{BranchPrefix: "ralphex/", BranchTemplate: "{slug}-wip"}
+
+	require.NoError(t, os.WriteFile(planFile, []byte("---\nbranch: team/custom-name\n---\n# Plan\n"), 0o600))
+	name, err := planBranchName(cfg, planFile)
+	require.NoError(t, err)
+	assert.Equal(t, "team/custom-name", name, "used as-is, without prefix and template")
+
+	require.NoError(t, os.WriteFile(planFile, []byte("---\nbranch: bad..name\n---\n# Plan\n"), 0o600))
+	_, err = planBranchName(cfg, planFile)
+	require.ErrorContains(t, err, "branch of plan front-matter")
+
+	require.NoError(t, os.WriteFile(planFile, []byte("---\nbranch: [\n---\n# Plan\n"), 0o600))
+	_, err = planBranchName(cfg, planFile)
+	require.ErrorContains(t, err, "parse plan front-matter")
+}
+
+func TestApplyPlanSettings(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	boolPtr := func(v bool) *bool { return &v }
+	cfg := &config.Config{CodexEnabled: true, FinalizeEnabled: false}
+
+	t.Run("front-matter wins over config and default flags", func(t *testing.T) {
+		s := plan.Settings{MaxIterations: intPtr(20), Codex: boolPtr(false), Finalize: boolPtr(true), Agents: []string{"quality"}}
+		o, req := applyPlanSettings(opts{MaxIterations: 50}, executePlanRequest{Config: cfg}, s)
+		assert.Equal(t, 20, o.MaxIterations)
+		assert.False(t, req.Config.CodexEnabled)
+		assert.True(t, req.Config.FinalizeEnabled)
+		assert.Equal(t, []string{"quality"}, req.Agents)
+		assert.True(t, cfg.CodexEnabled, "shared config is not changed")
+		assert.False(t, cfg.FinalizeEnabled, "shared config is not changed")
+	})
+
+	t.Run("explicit flag wins over front-matter", func(t *testing.T) {
+		o, _ := applyPlanSettings(opts{MaxIterations: 5, MaxIterationsSet: true}, executePlanRequest{Config: cfg},
+			plan.Settings{MaxIterations: intPtr(20)})
+		assert.Equal(t, 5, o.MaxIterations)
+	})
+
+	t.Run("no settings", func(t *testing.T) {
+		o, req := applyPlanSettings(opts{MaxIterations: 50}, executePlanRequest{Config: cfg}, plan.Settings{})
+		assert.Equal(t, 50, o.MaxIterations)
+		assert.Same(t, cfg, req.Config)
+		assert.Nil(t, req.Agents)
+	})
+}
+
+func TestReadPlanSettings(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("---\ncodex: false\nmodel: opus\n---\n# Plan\n"), 0o600))
+	s, err := readPlanSettings(planFile, testColors())
+	require.NoError(t, err, "unknown keys only warn")
+	require.NotNil(t, s.Codex)
+	assert.False(t, *s.Codex)
+
+	s, err = readPlanSettings(filepath.Join(t.TempDir(), "missing.md"), testColors())
+	require.NoError(t, err)
+	assert.Equal(t, plan.Settings{}, s)
+}
+
+func TestReviewBase(t *testing.T) {
+	tests := []struct {
+		name string
+		opts opts
+		want string
+	}{
+		{name: "default branch", opts: opts{}, want: ""},
+		{name: "base", opts: opts{Base: "release"}, want: "release"},
+		{name: "two-dot range", opts: opts{Diff: "v1.2..HEAD"}, want: "v1.2"},
+		{name: "three-dot range", opts: opts{Diff: "main...feature"}, want: "main"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, reviewBase(tc.opts))
+		})
+	}
+}
+
+func TestResolveSince(t *testing.T) {
+	dir := setupTestRepo(t)
+	runGit(t, dir, "checkout", "-b", "feature")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "first feature change")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "second feature change")
+	runGit(t, dir, "checkout", "master")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "master change")
+	runGit(t, dir, "checkout", "feature")
+	gitSvc, err := git.NewService(dir, noopLogger())
+	require.NoError(t, err)
+	revParse := func(ref string) string {
+		out, revErr := exec.Command("git", "-C", dir, "rev-parse", ref).Output()
+		require.NoError(t, revErr)
+		return strings.TrimSpace(string(out))
+	}
+
+	t.Run("no since", func(t *testing.T) {
+		o, err := resolveSince(opts{Review: true}, gitSvc, testColors())
+		require.NoError(t, err)
+		assert.Empty(t, o.Base)
+		assert.Empty(t, reviewBase(o))
+	})
+
+	t.Run("branch resolves to the fork point", func(t *testing.T) {
+		o, err := resolveSince(opts{Review: true, Since: "master"}, gitSvc, testColors())
+		require.NoError(t, err)
+		assert.Equal(t, revParse("HEAD~2"), o.Base, "merge-base, not the tip of master")
+		assert.Equal(t, o.Base, reviewBase(o))
+	})
+
+	t.Run("recent commits", func(t *testing.T) {
+		o, err := resolveSince(opts{Review: true, Since: "HEAD~1"}, gitSvc, testColors())
+		require.NoError(t, err)
+		assert.Equal(t, revParse("HEAD~1"), reviewBase(o))
+	})
+
+	t.Run("unknown ref", func(t *testing.T) {
+		_, err := resolveSince(opts{Review: true, Since: "nonexistent"}, gitSvc, testColors())
+		require.ErrorContains(t, err, "resolve --since nonexistent")
+	})
+}
+
+func TestSelectAgents(t *testing.T) {
+	cfg := &config.Config{CustomAgents: []config.CustomAgent{{Name: "quality"}, {Name: "testing"}, {Name: "documentation"}},
+		ConfigAgents: []config.CustomAgent{{Name: "api"}, {Name: "testing"}}}
+	tests := []struct {
+		name       string
+		opts       opts
+		planAgents []string
+		want       []string
+		wantErr    string
+	}{
+		{name: "default is all", want: nil},
+		{name: "plan agents", planAgents: []string{"testing"}, want: []string{"testing"}},
+		{name: "include", opts: opts{Agents: []string{"quality", "testing"}}, want: []string{"quality", "testing"}},
+		{name: "include wins over plan", opts: opts{Agents: []string{"quality"}}, planAgents: []string{"testing"},
+			want: []string{"quality"}},
+		{name: "exclude from all", opts: opts{NoAgents: []string{"testing"}}, want: []string{"quality", "documentation", "api"}},
+		{name: "include config agent", opts: opts{Agents: []string{"api"}}, want: []string{"api"}},
+		{name: "exclude from plan agents", opts: opts{NoAgents: []string{"testing"}}, planAgents: []string{"testing", "quality"},
+			want: []string{"quality"}},
+		{name: "exclude everything", opts: opts{NoAgents: []string{"quality", "testing", "documentation", "api"}}, want: []string{}},
+		{name: "unknown include", opts: opts{Agents: []string{"security"}},
+			wantErr: `unknown agent "security", available: quality, testing, documentation, api`},
+		{name: "unknown exclude", opts: opts{NoAgents: []string{"qa"}}, wantErr: `unknown agent "qa"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := selectAgents(tc.opts, cfg, tc.planAgents)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRenderBranchTemplate(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{tmpl: "", want: "fix-tests"},
+		{tmpl: "{slug}", want: "fix-tests"},
+		{tmpl: "{date}-{slug}", want: "2026-01-02-fix-tests"},
+		{tmpl: "{user}/{slug}", want: "alice/fix-tests"},
+		{tmpl: "feature/{user}/{date}/{slug}", want: "feature/alice/2026-01-02/fix-tests"},
+		{tmpl: "static", want: "static"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.tmpl, func(t *testing.T) {
+			assert.Equal(t, tc.want, renderBranchTemplate(tc.tmpl, "fix-tests", now, "alice"))
+		})
+	}
+}
+
+func TestCurrentUserName(t *testing.T) {
+	name := currentUserName()
+	assert.NotEmpty(t, name)
+	assert.NoError(t, git.ValidateBranchName(name))
+}
+
+func TestResolvePlanBranch(t *testing.T) {
+	dir := setupTestRepo(t)
+	gitSvc, err := git.NewService(dir, noopLogger())
+	require.NoError(t, err)
+	runGit(t, dir, "branch", "fix-tests")
+	runGit(t, dir, "branch", "fix-tests-2")
+
+	tests := []struct {
+		name    string
+		opts    opts
+		branch  string
+		used    map[string]bool
+		stdin   string
+		want    string
+		wantOut string
+	}{
+		{name: "new branch without asking", branch: "add-feature", want: "add-feature"},
+		{name: "reuse accepted", branch: "fix-tests", stdin: "y\n", want: "fix-tests", wantOut: "branch fix-tests exists, reuse it?"},
+		{name: "declined gets unique name", branch: "fix-tests", stdin: "n\n", want: "fix-tests-3"},
+		{name: "no input gets unique name", branch: "fix-tests", want: "fix-tests-3"},
+		{name: "declined skips used names", branch: "fix-tests", used: map[string]bool{"fix-tests-3": true}, stdin: "n\n",
+			want: "fix-tests-4"},
+		{name: "yes reuses without asking", opts: opts{Yes: true}, branch: "fix-tests", want: "fix-tests"},
+		{name: "resume reuses without asking", opts: opts{Resume: true}, branch: "fix-tests", want: "fix-tests"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got := resolvePlanBranch(t.Context(), tc.opts, gitSvc, tc.branch, tc.used, strings.NewReader(tc.stdin), &out)
+			assert.Equal(t, tc.want, got)
+			assert.Contains(t, out.String(), tc.wantOut)
+			if tc.opts.Yes || tc.opts.Resume || tc.branch == "add-feature" {
+				assert.Empty(t, out.String(), "no prompt expected")
+			}
+		})
+	}
+
+	t.Run("feature branch keeps name", func(t *testing.T) {
+		runGit(t, dir, "checkout", "-b", "existing")
+		t.Cleanup(func() { runGit(t, dir, "checkout", "master") })
+		var out bytes.Buffer
+		assert.Equal(t, "fix-tests", resolvePlanBranch(t.Context(), opts{}, gitSvc, "fix-tests", nil, strings.NewReader("n\n"), &out))
+		assert.Empty(t, out.String())
+	})
+}
+
+func TestEnsureProgressIgnored(t *testing.T) {
+	tests := []struct {
+		name string
+		dir  string
+		want string // expected .gitignore entry, empty if .gitignore should not be created
+	}{
+		{name: "default", dir: "", want: ".ralphex/progress/"},
+		{name: "custom relative", dir: "logs/ralphex/", want: "logs/ralphex/"},
+		{name: "project root", dir: ".", want: "progress-*.txt"},
+		{name: "absolute", dir: "/var/log/ralphex", want: ""},
+		{name: "outside repo", dir: "../logs", want: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := setupTestRepo(t)
+			gitSvc, err := git.NewService(dir, noopLogger())
+			require.NoError(t, err)
+
+			require.NoError(t, ensureProgressIgnored(gitSvc, tc.dir))
+
+			data, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+			if tc.want == "" {
+				assert.True(t, os.IsNotExist(err), ".gitignore should not be created")
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, string(data), "\n"+tc.want+"\n")
+		})
+	}
+}
+
+func TestWithAutostash(t *testing.T) {
+	setup := func(t *testing.T) (gitSvc *git.Service, dir, planFile string) {
+		t.Helper()
+		dir = setupTestRepo(t)
+		gitSvc, err := git.NewService(dir, noopLogger())
+		require.NoError(t, err)
+		gitSvc.SetAutoStash(git.AutoStashOriginal)
+		planFile = filepath.Join(dir, "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes\n"), 0o600))
+		return gitSvc, dir, planFile
+	}
+
+	t.Run("run fails, changes restored on starting branch", func(t *testing.T) {
+		gitSvc, dir, planFile := setup(t)
+
+		err := withAutostash(gitSvc, testColors(), []string{planFile}, func() error {
+			assert.NoFileExists(t, filepath.Join(dir, "notes.txt"), "changes stashed during the run")
+			assert.FileExists(t, planFile)
+			require.NoError(t, gitSvc.CreateBranchForPlanAs(planFile, "plan"))
+			return errors.New("task failed")
+		})
+		require.EqualError(t, err, "task failed")
+
+		branch, err := gitSvc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "master", branch)
+		assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+	})
+
+	t.Run("nothing to stash", func(t *testing.T) {
+		gitSvc, dir, planFile := setup(t)
+		require.NoError(t, os.Remove(filepath.Join(dir, "notes.txt")))
+
+		called := false
+		err := withAutostash(gitSvc, testColors(), []string{planFile}, func() error {
+			called = true
+			return nil
+		})
+		require.NoError(t, err)
+		assert.True(t, called)
+	})
+}
+
+func TestRunDryRun(t *testing.T) {
+	dir := setupTestRepo(t)
+	oldWd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(oldWd) })
+
+	gitSvc, err := git.NewService(".", noopLogger())
+	require.NoError(t, err)
+
+	req := executePlanRequest{
+		PlanFile:      "docs/plans/add-feature.md",
+		Mode:          processor.ModeFull,
+		GitSvc:        gitSvc,
+		Config:        &config.Config{CodexEnabled: false},
+		Colors:        testColors(),
+		DefaultBranch: "master",
+	}
+	require.NoError(t, runDryRun(opts{MaxIterations: 10, NoColor: true}, req))
+
+	// dry run must not touch git state or create progress files
+	branch, err := gitSvc.CurrentBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "master", branch)
+	_, statErr := os.Stat(filepath.Join(dir, ".ralphex"))
+	assert.True(t, os.IsNotExist(statErr), "progress dir should not be created")
+	_, statErr = os.Stat(filepath.Join(dir, ".gitignore"))
+	assert.True(t, os.IsNotExist(statErr), ".gitignore should not be created")
+}
+
+func TestRunDryRunEmptyRepo(t *testing.T) {
+	// skip if configured claude command is not installed
+	skipIfClaudeNotAvailable(t)
+
+	dir := initEmptyRepo(t)
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+
+	require.NoError(t, os.MkdirAll("docs/plans", 0o750))
+	planFile := filepath.Join("docs", "plans", "add-feature.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Add Feature\n\n### Task 1: add\n- [ ] add it\n"), 0o600))
+
+	o := opts{PlanFile: planFile, DryRun: true, MaxIterations: 1, NoColor: true, ConfigDir: t.TempDir()}
+	require.NoError(t, run(context.Background(), o, nil, nil))
+
+	// dry run must not create the initial commit, HEAD stays unborn
+	gitSvc, err := git.NewService(".", noopLogger())
+	require.NoError(t, err)
+	hasCommits, err := gitSvc.HasCommits()
+	require.NoError(t, err)
+	assert.False(t, hasCommits, "dry run should not create the initial commit")
+	branch, err := gitSvc.CurrentBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "master", branch)
+}
+
+func TestPhasePromptFiles(t *testing.T) {
+	tests := []struct {
+		name        string
+		phase       status.Phase
+		tool        string
+		firstReview bool
+		want        []string
+	}{
+		{name: "task", phase: status.PhaseTask, want: []string{"task.txt"}},
+		{name: "first_review", phase: status.PhaseReview, firstReview: true, want: []string{"review_first.txt", "review_second.txt"}},
+		{name: "post_codex_review", phase: status.PhaseReview, want: []string{"review_second.txt"}},
+		{name: "codex", phase: status.PhaseCodex, tool: "codex", want: []string{"codex.txt"}},
+		{name: "custom", phase: status.PhaseCodex, tool: "custom", want: []string{"custom_review.txt", "custom_eval.txt"}},
+		{name: "gemini", phase: status.PhaseCodex, tool: "gemini", want: []string{"gemini.txt"}},
+		{name: "finalize", phase: status.PhaseFinalize, want: []string{"finalize.txt"}},
+		{name: "plan", phase: status.PhasePlan, want: []string{"make_plan.txt"}},
+		{name: "unknown", phase: status.PhaseClaudeEval, want: nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, phasePromptFiles(tc.phase, tc.tool, tc.firstReview))
+		})
+	}
+}
+
+func TestRunnerContext(t *testing.T) {
+	t.Run("no_deadline", func(t *testing.T) {
+		ctx, cancel := runnerContext(context.Background(), time.Time{})
+		defer cancel()
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+	})
+
+	t.Run("with_deadline", func(t *testing.T) {
+		deadline := time.Now().Add(time.Hour)
+		ctx, cancel := runnerContext(context.Background(), deadline)
+		defer cancel()
+		got, ok := ctx.Deadline()
+		require.True(t, ok)
+		assert.Equal(t, deadline, got)
+	})
+}
+
+func TestTimeoutError(t *testing.T) {
+	runErr := errors.New("task phase: context deadline exceeded")
+
+	t.Run("deadline_exceeded", func(t *testing.T) {
+		runCtx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		err := timeoutError(context.Background(), runCtx, 30*time.Minute, runErr)
+		require.ErrorIs(t, err, runErr)
+		assert.Contains(t, err.Error(), "run exceeded timeout of 30m0s")
+	})
+
+	t.Run("parent_canceled_is_not_timeout", func(t *testing.T) {
+		parent, cancelParent := context.WithCancel(context.Background())
+		runCtx, cancel := context.WithDeadline(parent, time.Now().Add(-time.Second))
+		defer cancel()
+		cancelParent()
+		assert.Equal(t, runErr, timeoutError(parent, runCtx, time.Minute, runErr))
+	})
+
+	t.Run("no_deadline_hit", func(t *testing.T) {
+		runCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		assert.Equal(t, runErr, timeoutError(context.Background(), runCtx, time.Minute, runErr))
+	})
+}
+
+func TestSaveStdinPlan(t *testing.T) {
+	colors := testColors()
+
+	t.Run("plan from stdin", func(t *testing.T) {
+		plansDir := filepath.Join(t.TempDir(), "plans")
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		defer r.Close()
+		_, err = w.WriteString("# Fix Flaky Tests\n\n### Task 1: fix\n- [ ] fix it\n")
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		o, err := saveStdinPlan(opts{PlanFile: "-"}, plansDir, r, colors)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(plansDir, time.Now().Format("2006-01-02")+"-fix-flaky-tests.md"), o.PlanFile)
+		data, err := os.ReadFile(o.PlanFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "- [ ] fix it")
+		name, err := planBranchName(&config.Config{}, o.PlanFile)
+		require.NoError(t, err)
+		assert.Equal(t, "fix-flaky-tests", name)
+	})
+
+	t.Run("stdin among several plans", func(t *testing.T) {
+		plansDir := t.TempDir()
+		o, err := saveStdinPlan(opts{PlanFile: "a.md", MorePlanFiles: []string{"-", "c.md"}}, plansDir,
+			strings.NewReader("# Second\n"), colors)
+		require.NoError(t, err)
+		assert.Equal(t, "a.md", o.PlanFile)
+		assert.Equal(t, []string{filepath.Join(plansDir, time.Now().Format("2006-01-02")+"-second.md"), "c.md"}, o.MorePlanFiles)
+	})
+
+	t.Run("no stdin argument", func(t *testing.T) {
+		o, err := saveStdinPlan(opts{PlanFile: "a.md"}, t.TempDir(), strings.NewReader("# ignored\n"), colors)
+		require.NoError(t, err)
+		assert.Equal(t, opts{PlanFile: "a.md"}, o)
+	})
+
+	t.Run("empty stdin", func(t *testing.T) {
+		_, err := saveStdinPlan(opts{PlanFile: "-"}, t.TempDir(), strings.NewReader(""), colors)
+		require.ErrorContains(t, err, "read plan from stdin: plan is empty")
+	})
+}
+
+func TestPromptInput(t *testing.T) {
+	warnPlan := filepath.Join(t.TempDir(), "warn.md")
+	require.NoError(t, os.WriteFile(warnPlan, []byte("# Plan\n- [ ] task\n-[ ] other\n"), 0o600))
+
+	t.Run("plan file argument keeps stdin", func(t *testing.T) {
+		stdin := strings.NewReader("y\n")
+		r, closeFn := promptInput(opts{PlanFile: "a.md"}, stdin, filepath.Join(t.TempDir(), "tty"))
+		defer closeFn()
+		assert.Same(t, stdin, r)
+	})
+
+	t.Run("stdin plan reads prompts from tty", func(t *testing.T) {
+		tty := filepath.Join(t.TempDir(), "tty")
+		require.NoError(t, os.WriteFile(tty, []byte("y\n"), 0o600))
+		r, closeFn := promptInput(opts{PlanFile: "a.md", MorePlanFiles: []string{"-"}}, strings.NewReader(""), tty)
+		defer closeFn()
+		var out bytes.Buffer
+		require.NoError(t, lintPlan(t.Context(), warnPlan, r, &out))
+		assert.Contains(t, out.String(), "continue despite plan warnings?")
+	})
+
+	t.Run("stdin plan without tty takes default", func(t *testing.T) {
+		r, closeFn := promptInput(opts{PlanFile: "-"}, strings.NewReader("y\n"), filepath.Join(t.TempDir(), "missing"))
+		defer closeFn()
+		var out bytes.Buffer
+		require.ErrorContains(t, lintPlan(t.Context(), warnPlan, r, &out), "has warnings, aborted")
+	})
+}
+
 func TestValidateFlags(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -462,24 +1341,166 @@ func TestValidateFlags(t *testing.T) {
 		errMsg  string
 	}{
 		{name: "no_flags_is_valid", opts: opts{}, wantErr: false},
+		{name: "agent_and_no_agent_is_valid", opts: opts{Agents: []string{"quality"}, NoAgents: []string{"testing"}}},
+		{name: "same_agent_included_and_excluded", opts: opts{Agents: []string{"quality"}, NoAgents: []string{"quality"}},
+			wantErr: true, errMsg: `agent "quality" is given with both --agent and --no-agent`},
+		{name: "review_with_base_is_valid", opts: opts{Review: true, Base: "release"}},
+		{name: "review_with_diff_is_valid", opts: opts{Review: true, Diff: "v1.2...HEAD"}},
+		{name: "base_and_diff_conflict", opts: opts{Base: "main", Diff: "v1.2..HEAD"}, wantErr: true,
+			errMsg: "--base and --diff can't be used together, the range sets its own base"},
+		{name: "review_with_since_is_valid", opts: opts{Review: true, Since: "HEAD~5"}},
+		{name: "since_and_base_conflict", opts: opts{Since: "main", Base: "release"}, wantErr: true,
+			errMsg: "--since can't be used with --base or --diff, it sets the base itself"},
+		{name: "since_and_diff_conflict", opts: opts{Since: "main", Diff: "v1.2..HEAD"}, wantErr: true,
+			errMsg: "--since can't be used with --base or --diff, it sets the base itself"},
+		{name: "diff_without_range", opts: opts{Diff: "v1.2"}, wantErr: true,
+			errMsg: `--diff expects a revision range like main..HEAD, got "v1.2"`},
+		{name: "diff_without_start", opts: opts{Diff: "..HEAD"}, wantErr: true,
+			errMsg: `--diff expects a revision range like main..HEAD, got "..HEAD"`},
 		{name: "plan_flag_only_is_valid", opts: opts{PlanDescription: "add feature"}, wantErr: false},
 		{name: "plan_file_only_is_valid", opts: opts{PlanFile: "docs/plans/test.md"}, wantErr: false},
 		{name: "both_plan_and_planfile_conflicts", opts: opts{PlanDescription: "add feature", PlanFile: "docs/plans/test.md"}, wantErr: true, errMsg: "conflicts"},
+		{name: "answers_file_with_plan_is_valid", opts: opts{PlanDescription: "add feature", AnswersFile: "answers.yml"}, wantErr: false},
+		{name: "answers_file_without_plan_is_invalid", opts: opts{PlanFile: "a.md", AnswersFile: "answers.yml"}, wantErr: true, errMsg: "--answers-file requires --plan"},
+		{name: "record_answers_with_plan_is_valid", opts: opts{PlanDescription: "add feature", RecordAnswers: "answers.yml"}, wantErr: false},
+		{name: "record_answers_without_plan_is_invalid", opts: opts{RecordAnswers: "answers.yml"}, wantErr: true,
+			errMsg: "--record-answers requires --plan"},
+		{name: "template_with_plan_is_valid", opts: opts{PlanDescription: "add feature", Template: "feature"}, wantErr: false},
+		{name: "template_without_plan_is_invalid", opts: opts{PlanFile: "a.md", Template: "feature"}, wantErr: true,
+			errMsg: "--template requires --plan"},
+		{name: "record_answers_with_answers_file_is_invalid",
+			opts:    opts{PlanDescription: "add feature", AnswersFile: "in.yml", RecordAnswers: "out.yml"},
+			wantErr: true, errMsg: "--record-answers conflicts with --answers-file"},
+		{name: "stdin_plan_is_valid", opts: opts{PlanFile: "-"}, wantErr: false},
+		{name: "stdin_plan_with_plan_flag_conflicts", opts: opts{PlanDescription: "add feature", PlanFile: "-"}, wantErr: true,
+			errMsg: "conflicts"},
+		{name: "stdin_plan_twice_is_invalid", opts: opts{PlanFile: "a.md", MorePlanFiles: []string{"-", "-"}}, wantErr: true,
+			errMsg: "can be given only once"},
+		{name: "stdin_plan_with_dry_run_is_invalid", opts: opts{PlanFile: "-", DryRun: true}, wantErr: true, errMsg: "--dry-run"},
+		{name: "positive_timeout_is_valid", opts: opts{Timeout: 30 * time.Minute}, wantErr: false},
+		{name: "negative_timeout_is_invalid", opts: opts{Timeout: -time.Second}, wantErr: true, errMsg: "--timeout must be non-negative"},
+		{name: "tasks_only_is_valid", opts: opts{TasksOnly: true, PlanFile: "a.md"}, wantErr: false},
+		{name: "tasks_only_conflicts_with_review", opts: opts{TasksOnly: true, Review: true}, wantErr: true, errMsg: "--tasks-only conflicts"},
+		{name: "tasks_only_conflicts_with_codex_only", opts: opts{TasksOnly: true, CodexOnly: true}, wantErr: true, errMsg: "--tasks-only conflicts"},
+		{name: "tasks_only_conflicts_with_external_only", opts: opts{TasksOnly: true, ExternalOnly: true}, wantErr: true, errMsg: "--tasks-only conflicts"},
+		{name: "tasks_review_alone_is_valid", opts: opts{TasksReview: true}, wantErr: false},
+		{name: "tasks_review_conflicts_with_tasks_only", opts: opts{TasksReview: true, TasksOnly: true}, wantErr: true, errMsg: "--tasks-review conflicts"},
+		{name: "tasks_review_conflicts_with_review", opts: opts{TasksReview: true, Review: true}, wantErr: true, errMsg: "--tasks-review conflicts"},
+		{name: "multiple_plans_tasks_review_is_valid", opts: opts{PlanFile: "a.md", MorePlanFiles: []string{"b.md"}, TasksReview: true}, wantErr: false},
+		{name: "multiple_plans_is_valid", opts: opts{PlanFile: "a.md", MorePlanFiles: []string{"b.md"}}, wantErr: false},
+		{name: "multiple_plans_tasks_only_is_valid", opts: opts{PlanFile: "a.md", MorePlanFiles: []string{"b.md"}, TasksOnly: true}, wantErr: false},
+		{name: "multiple_plans_review_is_invalid", opts: opts{PlanFile: "a.md", MorePlanFiles: []string{"b.md"}, Review: true}, wantErr: true, errMsg: "only supported in full, tasks-only and tasks-review modes"},
+		{name: "multiple_plans_codex_only_is_invalid", opts: opts{PlanFile: "a.md", MorePlanFiles: []string{"b.md"}, CodexOnly: true}, wantErr: true, errMsg: "only supported in full, tasks-only and tasks-review modes"},
+		{name: "worktree_is_valid", opts: opts{PlanFile: "a.md", Worktree: true, WorktreeCleanup: true}, wantErr: false},
+		{name: "worktree_tasks_only_is_valid", opts: opts{PlanFile: "a.md", Worktree: true, TasksOnly: true}, wantErr: false},
+		{name: "worktree_review_is_invalid", opts: opts{Worktree: true, Review: true}, wantErr: true, errMsg: "--worktree is only supported"},
+		{name: "worktree_plan_mode_is_invalid", opts: opts{Worktree: true, PlanDescription: "add feature"}, wantErr: true, errMsg: "--worktree is only supported"},
+		{name: "worktree_multiple_plans_is_invalid", opts: opts{PlanFile: "a.md", MorePlanFiles: []string{"b.md"}, Worktree: true}, wantErr: true, errMsg: "--worktree is not supported with multiple plan files"},
+		{name: "create_pr_is_valid", opts: opts{PlanFile: "a.md", CreatePR: true}, wantErr: false},
+		{name: "create_pr_tasks_only_is_invalid", opts: opts{CreatePR: true, TasksOnly: true}, wantErr: true, errMsg: "--create-pr is only supported in full mode"},
+		{name: "create_pr_review_is_invalid", opts: opts{CreatePR: true, Review: true}, wantErr: true, errMsg: "--create-pr is only supported in full mode"},
+		{name: "findings_with_codex_only_is_valid", opts: opts{CodexOnly: true, Findings: []string{"review.md"}}},
+		{name: "findings_with_external_only_is_valid", opts: opts{ExternalOnly: true, Findings: []string{"a.md", "b.md"}}},
+		{name: "findings_without_codex_only_is_invalid", opts: opts{Review: true, Findings: []string{"review.md"}}, wantErr: true,
+			errMsg: "--findings requires --external-only or --codex-only"},
+		{name: "auto_stash_original_with_worktree_is_invalid", opts: opts{PlanFile: "a.md", AutoStash: "original", Worktree: true}, wantErr: true, errMsg: "--auto-stash=original can't be used with --worktree"},
+		{name: "auto_stash_feature_with_worktree_is_valid", opts: opts{PlanFile: "a.md", AutoStash: "feature", Worktree: true}, wantErr: false},
+		{name: "worktree_cleanup_requires_worktree", opts: opts{WorktreeCleanup: true}, wantErr: true, errMsg: "--worktree-cleanup requires --worktree"},
+		{name: "multiple_plans_serve_is_invalid", opts: opts{PlanFile: "a.md", MorePlanFiles: []string{"b.md"}, Serve: true}, wantErr: true, errMsg: "--serve is not supported"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateFlags(tc.opts)
+			if tc.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errMsg)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestApplyPlansDir(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		name    string
+		opts    opts
+		mode    processor.Mode
+		wantDir string
+		wantErr error
+	}{
+		{name: "no flag keeps config", opts: opts{}, mode: processor.ModeFull, wantDir: "docs/plans"},
+		{name: "existing dir overrides config", opts: opts{PlansDir: dir}, mode: processor.ModeFull, wantDir: dir},
+		{name: "missing dir without plan file", opts: opts{PlansDir: missing}, mode: processor.ModeFull, wantErr: plan.ErrNoPlansFound},
+		{name: "missing dir with plan file", opts: opts{PlansDir: missing, PlanFile: "a.md"}, mode: processor.ModeFull, wantDir: missing},
+		{name: "missing dir in plan mode", opts: opts{PlansDir: missing, PlanDescription: "add feature"}, mode: processor.ModePlan, wantDir: missing},
+		{name: "missing dir in review mode", opts: opts{PlansDir: missing, Review: true}, mode: processor.ModeReview, wantDir: missing},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{PlansDir: "docs/plans"}
+			err := applyPlansDir(tc.opts, tc.mode, cfg)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantDir, cfg.PlansDir)
+		})
+	}
+}
+
+func TestUniqueBranchName(t *testing.T) {
+	tests := []struct {
+		name string
+		used map[string]bool
+		want string
+	}{
+		{name: "unused", used: map[string]bool{}, want: "feature"},
+		{name: "used_once", used: map[string]bool{"feature": true}, want: "feature-2"},
+		{name: "used_twice", used: map[string]bool{"feature": true, "feature-2": true}, want: "feature-3"},
+		{name: "other_used", used: map[string]bool{"other": true}, want: "feature"},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			err := validateFlags(tc.opts)
-			if tc.wantErr {
-				require.Error(t, err)
-				assert.Contains(t, err.Error(), tc.errMsg)
-			} else {
-				require.NoError(t, err)
-			}
+			assert.Equal(t, tc.want, uniqueBranchName("feature", tc.used))
 		})
 	}
 }
 
+func TestRunPlanQueue_RejectsServe(t *testing.T) {
+	err := runPlanQueue(context.Background(), opts{Serve: true}, []string{"a.md", "b.md"}, executePlanRequest{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--serve is not supported")
+}
+
+func TestPlanQueueError(t *testing.T) {
+	plans := []string{"a.md", "b.md", "c.md"}
+	errFailed := errors.New("boom")
+
+	t.Run("first_plan_fails", func(t *testing.T) {
+		err := planQueueError(plans, 0, errFailed)
+		require.ErrorIs(t, err, errFailed)
+		assert.Contains(t, err.Error(), "plan 1 of 3 a.md failed: boom")
+		assert.Contains(t, err.Error(), "completed: none")
+		assert.Contains(t, err.Error(), "not started: b.md, c.md")
+	})
+
+	t.Run("last_plan_fails", func(t *testing.T) {
+		err := planQueueError(plans, 2, errFailed)
+		require.ErrorIs(t, err, errFailed)
+		assert.Contains(t, err.Error(), "plan 3 of 3 c.md failed")
+		assert.Contains(t, err.Error(), "completed: a.md, b.md")
+		assert.Contains(t, err.Error(), "not started: none")
+	})
+}
+
 func TestPrintStartupInfo(t *testing.T) {
 	colors := testColors()
 
@@ -506,6 +1527,78 @@ func TestPrintStartupInfo(t *testing.T) {
 		// verify it doesn't panic with empty plan
 		printStartupInfo(info, colors)
 	})
+
+	t.Run("prints_queue_position", func(t *testing.T) {
+		info := startupInfo{
+			PlanFile:      "/path/to/second.md",
+			Branch:        "second",
+			Mode:          processor.ModeFull,
+			MaxIterations: 50,
+			ProgressPath:  "progress-second.txt",
+			QueuePos:      2,
+			QueueLen:      4,
+		}
+		printStartupInfo(info, colors)
+	})
+
+	t.Run("prints_kept_plan", func(t *testing.T) {
+		info := startupInfo{
+			PlanFile:      "/path/to/plan.md",
+			Branch:        "feature-branch",
+			Mode:          processor.ModeFull,
+			MaxIterations: 50,
+			ProgressPath:  "progress.txt",
+			KeepPlan:      true,
+		}
+		printStartupInfo(info, colors)
+	})
+}
+
+func TestMoveCompletedPlan(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      processor.Mode
+		cfgMove   bool
+		noMove    bool
+		wantMoved bool
+	}{
+		{name: "enabled", mode: processor.ModeFull, cfgMove: true, wantMoved: true},
+		{name: "tasks_review_moves", mode: processor.ModeTasksReview, cfgMove: true, wantMoved: true},
+		{name: "disabled_in_config", mode: processor.ModeFull, cfgMove: false},
+		{name: "disabled_by_flag", mode: processor.ModeFull, cfgMove: true, noMove: true},
+		{name: "tasks_only_never_moves", mode: processor.ModeTasksOnly, cfgMove: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := setupTestRepo(t)
+			plansDir := filepath.Join(dir, "docs", "plans")
+			require.NoError(t, os.MkdirAll(plansDir, 0o750))
+			planPath := filepath.Join(plansDir, "feature.md")
+			require.NoError(t, os.WriteFile(planPath, []byte("# Plan\n- [x] done\n"), 0o600))
+			runGit(t, dir, "add", ".")
+			runGit(t, dir, "commit", "-m", "add plan")
+
+			gitSvc, err := git.NewService(dir, noopLogger())
+			require.NoError(t, err)
+			req := executePlanRequest{PlanFile: planPath, Mode: tc.mode, GitSvc: gitSvc,
+				Config: &config.Config{PlansDir: plansDir, CompletedDir: "completed", MoveCompleted: tc.cfgMove}}
+			moveCompletedPlan(opts{NoMove: tc.noMove}, req)
+
+			if tc.wantMoved {
+				assert.NoFileExists(t, planPath)
+				assert.FileExists(t, filepath.Join(plansDir, "completed", "feature.md"))
+				return
+			}
+			assert.FileExists(t, planPath)
+			assert.NoDirExists(t, filepath.Join(plansDir, "completed"))
+		})
+	}
+}
+
+func TestMoveCompleted(t *testing.T) {
+	assert.True(t, moveCompleted(opts{}, &config.Config{MoveCompleted: true}))
+	assert.False(t, moveCompleted(opts{NoMove: true}, &config.Config{MoveCompleted: true}))
+	assert.False(t, moveCompleted(opts{}, &config.Config{MoveCompleted: false}))
 }
 
 // noopLogger returns a no-op git.Logger for tests using moq-generated mock.
@@ -637,7 +1730,7 @@ func TestTasksOnlyModeBranchCreation(t *testing.T) {
 		go func() {
 			defer close(done)
 			o := opts{TasksOnly: true, PlanFile: planPath, MaxIterations: 1}
-			_ = run(ctx, o)
+			_ = run(ctx, o, nil, nil)
 		}()
 
 		// verify branch was created (branch name derived from plan filename)
@@ -680,7 +1773,7 @@ func TestTasksOnlyModeBranchCreation(t *testing.T) {
 		go func() {
 			defer close(done)
 			o := opts{Review: true, PlanFile: planPath, MaxIterations: 1}
-			_ = run(ctx, o)
+			_ = run(ctx, o, nil, nil)
 		}()
 
 		// verify branch was NOT created (still on master) - wait briefly then check
@@ -721,7 +1814,7 @@ func TestTasksOnlyModeBranchCreation(t *testing.T) {
 		go func() {
 			defer close(done)
 			o := opts{CodexOnly: true, PlanFile: planPath, MaxIterations: 1}
-			_ = run(ctx, o)
+			_ = run(ctx, o, nil, nil)
 		}()
 
 		// verify branch was NOT created (still on master) - wait briefly then check
@@ -762,7 +1855,7 @@ func TestTasksOnlyModeBranchCreation(t *testing.T) {
 		go func() {
 			defer close(done)
 			o := opts{ExternalOnly: true, PlanFile: planPath, MaxIterations: 1}
-			_ = run(ctx, o)
+			_ = run(ctx, o, nil, nil)
 		}()
 
 		// verify branch was NOT created (still on master) - wait briefly then check
@@ -786,6 +1879,7 @@ func TestModeRequiresBranch(t *testing.T) {
 	}{
 		{processor.ModeFull, true},
 		{processor.ModeTasksOnly, true},
+		{processor.ModeTasksReview, true},
 		{processor.ModeReview, false},
 		{processor.ModeCodexOnly, false},
 		{processor.ModePlan, false},
@@ -940,6 +2034,162 @@ func TestConfigDirCustomPath(t *testing.T) {
 		assert.DirExists(t, filepath.Join(cfgDir, "prompts"))
 		assert.DirExists(t, filepath.Join(cfgDir, "agents"))
 	})
+
+	t.Run("reset_with_custom_dir_leaves_default_alone", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+		cfgDir := filepath.Join(t.TempDir(), "profile")
+
+		require.NoError(t, runReset(cfgDir, strings.NewReader("y\ny\ny\n"), &bytes.Buffer{}))
+		assert.FileExists(t, filepath.Join(cfgDir, "config"))
+		assert.NoDirExists(t, filepath.Join(home, ".config", "ralphex"))
+	})
+
+	t.Run("flag_wins_over_env", func(t *testing.T) {
+		t.Setenv("RALPHEX_CONFIG_DIR", "/from/env")
+
+		var o opts
+		_, err := flags.NewParser(&o, flags.Default).ParseArgs([]string{"--config-dir", "/from/flag"})
+		require.NoError(t, err)
+		assert.Equal(t, "/from/flag", o.ConfigDir)
+
+		o = opts{}
+		_, err = flags.NewParser(&o, flags.Default).ParseArgs(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "/from/env", o.ConfigDir)
+	})
+}
+
+func TestResolveConfigDir(t *testing.T) {
+	parse := func(t *testing.T, args ...string) (opts, error) {
+		t.Helper()
+		var o opts
+		parser := flags.NewParser(&o, flags.None)
+		_, err := parser.ParseArgs(args)
+		require.NoError(t, err)
+		return resolveConfigDir(parser, o)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{name: "none", want: ""},
+		{name: "config flag", args: []string{"--config", "/from/flag"}, want: "/from/flag"},
+		{name: "config env", env: map[string]string{"RALPHEX_CONFIG": "/from/env"}, want: "/from/env"},
+		{name: "config flag wins over config env", env: map[string]string{"RALPHEX_CONFIG": "/from/env"},
+			args: []string{"--config", "/from/flag"}, want: "/from/flag"},
+		{name: "config flag wins over config-dir env", env: map[string]string{"RALPHEX_CONFIG_DIR": "/from/env"},
+			args: []string{"--config", "/from/flag"}, want: "/from/flag"},
+		{name: "config-dir flag wins over config env", env: map[string]string{"RALPHEX_CONFIG": "/from/env"},
+			args: []string{"--config-dir", "/from/flag"}, want: "/from/flag"},
+		{name: "config-dir env wins over config env",
+			env: map[string]string{"RALPHEX_CONFIG": "/from/alias", "RALPHEX_CONFIG_DIR": "/from/dir"}, want: "/from/dir"},
+		{name: "both flags agree", args: []string{"--config", "/same", "--config-dir", "/same"}, want: "/same"},
+		{name: "both flags differ", args: []string{"--config", "/one", "--config-dir", "/two"},
+			wantErr: "point at different directories"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("RALPHEX_CONFIG", "")
+			t.Setenv("RALPHEX_CONFIG_DIR", "")
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			o, err := parse(t, tc.args...)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, o.ConfigDir)
+		})
+	}
+
+	t.Run("reset with config resets only that directory", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+		t.Setenv("RALPHEX_CONFIG", "")
+		t.Setenv("RALPHEX_CONFIG_DIR", "")
+		cfgDir := filepath.Join(t.TempDir(), "x")
+
+		o, err := parse(t, "--reset", "--config", cfgDir)
+		require.NoError(t, err)
+		require.True(t, o.Reset)
+		require.Equal(t, cfgDir, o.ConfigDir)
+
+		require.NoError(t, runReset(o.ConfigDir, strings.NewReader("y\ny\ny\n"), &bytes.Buffer{}))
+		assert.FileExists(t, filepath.Join(cfgDir, "config"))
+		assert.NoDirExists(t, filepath.Join(home, ".config", "ralphex"))
+	})
+}
+
+func TestApplyConfigMaxIterations(t *testing.T) {
+	tests := []struct {
+		name string
+		o    opts
+		cfg  config.Config
+		want int
+	}{
+		{name: "flag wins over config", o: opts{MaxIterations: 3, MaxIterationsSet: true}, cfg: config.Config{MaxIterations: 7}, want: 3},
+		{name: "config without flag", cfg: config.Config{MaxIterations: 7}, want: 7},
+		{name: "neither", want: defaultMaxIterations},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, applyConfigMaxIterations(tc.o, &tc.cfg).MaxIterations)
+		})
+	}
+
+	t.Run("env through config load", func(t *testing.T) {
+		t.Setenv("RALPHEX_MAX_ITERATIONS", "7")
+		cfg, err := config.Load(t.TempDir())
+		require.NoError(t, err)
+
+		var o opts
+		parser := flags.NewParser(&o, flags.None)
+		_, err = parser.ParseArgs(nil)
+		require.NoError(t, err)
+		assert.Equal(t, 7, applyConfigMaxIterations(o, cfg).MaxIterations)
+
+		o = opts{}
+		_, err = parser.ParseArgs([]string{"-m", "3"})
+		require.NoError(t, err)
+		assert.Equal(t, 3, applyConfigMaxIterations(o, cfg).MaxIterations)
+	})
+}
+
+func TestOpts_AutoStash(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    git.AutoStash
+		wantErr bool
+	}{
+		{name: "not given", want: git.AutoStashOff},
+		{name: "bare flag restores on the feature branch", args: []string{"--auto-stash"}, want: git.AutoStashFeature},
+		{name: "feature", args: []string{"--auto-stash=feature"}, want: git.AutoStashFeature},
+		{name: "original", args: []string{"--auto-stash=original"}, want: git.AutoStashOriginal},
+		{name: "unknown target", args: []string{"--auto-stash=elsewhere"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var o opts
+			_, err := flags.NewParser(&o, flags.None).ParseArgs(tc.args)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, git.AutoStash(o.AutoStash))
+		})
+	}
 }
 
 func TestDumpDefaults(t *testing.T) {
@@ -989,14 +2239,14 @@ func TestDumpDefaults(t *testing.T) {
 
 func TestHandleEarlyFlags(t *testing.T) {
 	t.Run("no_flags_continues", func(t *testing.T) {
-		done, err := handleEarlyFlags(opts{})
+		done, err := handleEarlyFlags(t.Context(), opts{})
 		require.NoError(t, err)
 		assert.False(t, done)
 	})
 
 	t.Run("dump_defaults_exits", func(t *testing.T) {
 		tmpDir := filepath.Join(t.TempDir(), "defaults")
-		done, err := handleEarlyFlags(opts{DumpDefaults: tmpDir})
+		done, err := handleEarlyFlags(t.Context(), opts{DumpDefaults: tmpDir})
 		require.NoError(t, err)
 		assert.True(t, done)
 		assert.FileExists(t, filepath.Join(tmpDir, "config"))
@@ -1007,12 +2257,259 @@ func TestHandleEarlyFlags(t *testing.T) {
 		blocker := filepath.Join(tmpDir, "blocker")
 		require.NoError(t, os.WriteFile(blocker, []byte("x"), 0o600))
 
-		done, err := handleEarlyFlags(opts{DumpDefaults: filepath.Join(blocker, "sub")})
+		done, err := handleEarlyFlags(t.Context(), opts{DumpDefaults: filepath.Join(blocker, "sub")})
+		require.Error(t, err)
+		assert.True(t, done)
+	})
+
+	t.Run("invalid_config_dir_exits", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		blocker := filepath.Join(tmpDir, "blocker")
+		require.NoError(t, os.WriteFile(blocker, []byte("x"), 0o600))
+
+		done, err := handleEarlyFlags(t.Context(), opts{ConfigDir: blocker})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not a directory")
+		assert.True(t, done)
+	})
+}
+
+func TestEnsureConfigDir(t *testing.T) {
+	t.Run("existing_dir", func(t *testing.T) {
+		require.NoError(t, ensureConfigDir(t.TempDir()))
+	})
+
+	t.Run("creates_missing_dir", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "nested", "config")
+		require.NoError(t, ensureConfigDir(dir))
+		assert.DirExists(t, dir)
+	})
+
+	t.Run("file_instead_of_dir", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "config")
+		require.NoError(t, os.WriteFile(file, []byte("x"), 0o600))
+
+		err := ensureConfigDir(file)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not a directory")
+	})
+
+	t.Run("parent_is_file", func(t *testing.T) {
+		blocker := filepath.Join(t.TempDir(), "blocker")
+		require.NoError(t, os.WriteFile(blocker, []byte("x"), 0o600))
+
+		err := ensureConfigDir(filepath.Join(blocker, "sub"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "config dir")
+	})
+}
+
+func TestLintPlanFile(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.md")
+	require.NoError(t, os.WriteFile(good, []byte("# Plan\n- [ ] task\n"), 0o600))
+	bad := filepath.Join(dir, "bad.md")
+	require.NoError(t, os.WriteFile(bad, []byte("# Plan\n* [ ] task\n"), 0o600))
+
+	var out bytes.Buffer
+	require.NoError(t, lintPlanFile(good, &out))
+	assert.Equal(t, "plan ok\n", out.String())
+
+	out.Reset()
+	require.EqualError(t, lintPlanFile(bad, &out), "plan has 2 problem(s)")
+	assert.Contains(t, out.String(), `warning: line 2: malformed checkbox "* [ ] task"`)
+
+	done, err := handleEarlyFlags(t.Context(), opts{LintPlan: bad})
+	require.Error(t, err)
+	assert.True(t, done)
+}
+
+func TestLintPlan(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	clean := write("clean.md", "# Plan\n- [ ] task\n")
+	warn := write("warn.md", "# Plan\n- [ ] task\n-[ ] other\n")
+	empty := write("empty.md", "\n")
+
+	tests := []struct {
+		name    string
+		plan    string
+		stdin   string
+		wantErr string
+		wantOut string
+	}{
+		{name: "clean plan", plan: clean},
+		{name: "warnings accepted", plan: warn, stdin: "y\n", wantOut: "continue despite plan warnings?"},
+		{name: "warnings rejected", plan: warn, stdin: "n\n", wantErr: "has warnings, aborted", wantOut: "malformed checkbox"},
+		{name: "warnings without input", plan: warn, wantErr: "has warnings, aborted"},
+		{name: "error aborts without asking", plan: empty, stdin: "y\n", wantErr: "has 1 error(s)", wantOut: "error: plan file is empty"},
+		{name: "missing file", plan: filepath.Join(dir, "missing.md"), wantErr: "lint plan"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := lintPlan(context.Background(), tc.plan, strings.NewReader(tc.stdin), &out)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Contains(t, out.String(), tc.wantOut)
+			if tc.plan == empty {
+				assert.NotContains(t, out.String(), "continue despite")
+			}
+		})
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	t.Run("clean_config", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, checkConfig(t.TempDir(), &out))
+		assert.Contains(t, out.String(), "  plans_dir: embedded\n")
+		assert.True(t, strings.HasSuffix(out.String(), "config ok\n"))
+	})
+
+	t.Run("shows_value_sources", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config"), []byte("plans_dir = specs\ncodex_enabled = false\n"), 0o600))
+
+		var out bytes.Buffer
+		require.NoError(t, checkConfig(dir, &out))
+		assert.Contains(t, out.String(), "  plans_dir: "+filepath.Join(dir, "config")+"\n")
+		assert.Contains(t, out.String(), "  codex_enabled: "+filepath.Join(dir, "config")+"\n")
+		assert.Contains(t, out.String(), "  iteration_delay_ms: embedded\n")
+		assert.NotContains(t, out.String(), "notify_smtp_password")
+	})
+
+	t.Run("reports_problems", func(t *testing.T) {
+		dir := t.TempDir()
+		content := "claude_command = claude\niteration_delay_ms = fast\ncodex_enabeld = true\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config"), []byte(content), 0o600))
+
+		var out bytes.Buffer
+		err := checkConfig(dir, &out)
+		require.EqualError(t, err, "config has 2 problem(s)")
+		assert.Contains(t, out.String(), filepath.Join(dir, "config")+":2: invalid iteration_delay_ms")
+		assert.Contains(t, out.String(), filepath.Join(dir, "config")+`:3: unknown key "codex_enabeld", did you mean "codex_enabled"?`)
+	})
+
+	t.Run("handled_as_early_flag", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config"), []byte("color_task = red\n"), 0o600))
+
+		done, err := handleEarlyFlags(t.Context(), opts{ConfigDir: dir, CheckConfig: true})
 		require.Error(t, err)
 		assert.True(t, done)
 	})
 }
 
+func TestValidateAll(t *testing.T) {
+	t.Run("all_ok", func(t *testing.T) {
+		plansDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(plansDir, "a.md"), []byte("# Plan\n- [ ] task\n"), 0o600))
+
+		var out bytes.Buffer
+		require.NoError(t, validateAll(t.TempDir(), plansDir, &out))
+		assert.Equal(t, "config: ok\nplans in "+plansDir+": 1\nall ok\n", out.String())
+	})
+
+	t.Run("reports_config_and_plan_problems", func(t *testing.T) {
+		configDir, plansDir := t.TempDir(), t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("codex_enabeld = true\n"), 0o600))
+		for name, content := range map[string]string{
+			"good.md":           "# Plan\n- [x] task\n",
+			"no-tasks.md":       "# Plan\nsome notes\n",
+			"completed/done.md": "# Plan\n",
+		} {
+			path := filepath.Join(plansDir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		}
+
+		var out bytes.Buffer
+		err := validateAll(configDir, plansDir, &out)
+		require.EqualError(t, err, "validation found 2 problem(s)")
+		assert.Contains(t, out.String(), "config: 1 problem(s)\n  "+filepath.Join(configDir, "config")+`:1: unknown key "codex_enabeld"`)
+		assert.Contains(t, out.String(), "plans in "+plansDir+": 2\n  "+filepath.Join(plansDir, "no-tasks.md")+
+			":\n    warning: plan has no tasks")
+		assert.NotContains(t, out.String(), "good.md")
+		assert.NotContains(t, out.String(), "done.md", "finished plans are skipped")
+	})
+
+	t.Run("handled_as_early_flag", func(t *testing.T) {
+		done, err := handleEarlyFlags(t.Context(), opts{ConfigDir: t.TempDir(), PlansDir: t.TempDir(), Validate: true})
+		require.NoError(t, err)
+		assert.True(t, done)
+	})
+}
+
+func TestListPlans(t *testing.T) {
+	plansDir := t.TempDir()
+	for name, content := range map[string]string{
+		"2026-01-10-add-auth.md":   "# Plan\n- [x] one\n- [ ] two\n- [ ] three\n",
+		"backend/fix-api.md":       "# Plan\n- [x] one\n",
+		"notes.md":                 "# Notes\n",
+		"completed/old-feature.md": "# Plan\n- [x] one\n- [x] two\n",
+	} {
+		path := filepath.Join(plansDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	t.Run("pending_plans", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, listPlans(t.TempDir(), plansDir, false, &out))
+		assert.Equal(t, "plans in "+plansDir+": 3\n"+
+			"  2026-01-10-add-auth.md  add-auth  1/3\n"+
+			"  "+filepath.Join("backend", "fix-api.md")+"      fix-api   1/1\n"+
+			"  notes.md                notes     0/0\n", out.String())
+	})
+
+	t.Run("with_completed", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, listPlans(t.TempDir(), plansDir, true, &out))
+		assert.Contains(t, out.String(), "completed plans: 1\n  "+filepath.Join("completed", "old-feature.md")+"  old-feature  2/2\n")
+	})
+
+	t.Run("missing_plans_dir", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, listPlans(t.TempDir(), filepath.Join(plansDir, "missing"), false, &out))
+		assert.Equal(t, "plans in "+filepath.Join(plansDir, "missing")+": 0\n", out.String())
+	})
+
+	t.Run("handled_as_early_flag", func(t *testing.T) {
+		done, err := handleEarlyFlags(t.Context(), opts{ConfigDir: t.TempDir(), PlansDir: plansDir, List: true})
+		require.NoError(t, err)
+		assert.True(t, done)
+	})
+
+	t.Run("runs_after_reset", func(t *testing.T) {
+		// reset reads its answers from stdin and both print to stdout, so swap them for files
+		stdin, err := os.Open(os.DevNull)
+		require.NoError(t, err)
+		defer stdin.Close()
+		stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+		require.NoError(t, err)
+		defer stdout.Close()
+		origStdin, origStdout := os.Stdin, os.Stdout
+		os.Stdin, os.Stdout = stdin, stdout
+		done, err := handleEarlyFlags(t.Context(), opts{ConfigDir: t.TempDir(), PlansDir: plansDir, Reset: true, List: true})
+		os.Stdin, os.Stdout = origStdin, origStdout
+		require.NoError(t, err)
+		assert.True(t, done)
+
+		out, err := os.ReadFile(stdout.Name())
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "plans in "+plansDir+": ")
+	})
+}
+
 func TestIsResetOnly(t *testing.T) {
 	t.Run("reset_only", func(t *testing.T) {
 		assert.True(t, isResetOnly(opts{Reset: true}))
@@ -1029,6 +2526,66 @@ func TestIsResetOnly(t *testing.T) {
 	t.Run("reset_with_review", func(t *testing.T) {
 		assert.False(t, isResetOnly(opts{Reset: true, Review: true}))
 	})
+
+	t.Run("reset_with_worktree", func(t *testing.T) {
+		assert.False(t, isResetOnly(opts{Reset: true, Worktree: true}))
+	})
+
+	t.Run("reset_with_show_theme", func(t *testing.T) {
+		assert.False(t, isResetOnly(opts{Reset: true, ShowTheme: true}))
+	})
+
+	t.Run("reset_with_list", func(t *testing.T) {
+		assert.False(t, isResetOnly(opts{Reset: true, List: true}))
+	})
+}
+
+func TestShowTheme(t *testing.T) {
+	t.Run("default colors", func(t *testing.T) {
+		var buf bytes.Buffer
+		showTheme("", testColors(), &buf)
+		out := buf.String()
+		assert.Contains(t, out, "theme: default (")
+		for _, role := range []string{"task", "review", "codex", "claude_eval", "warn", "error", "signal", "timestamp", "info"} {
+			assert.Regexp(t, `(?m)^.*  `+role+` +\S`, out, "role %s", role)
+		}
+	})
+
+	t.Run("named theme", func(t *testing.T) {
+		var buf bytes.Buffer
+		showTheme("solarized", progress.NewColors(config.ColorConfig{Theme: "solarized"}), &buf)
+		assert.Contains(t, buf.String(), "theme: solarized (")
+		assert.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 10)
+	})
+}
+
+func TestRepoRelativePath(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	rel, err := repoRelativePath(root, filepath.Join(root, "docs", "plans", "a.md"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("docs", "plans", "a.md"), rel)
+
+	_, err = repoRelativePath(root, filepath.Join(filepath.Dir(root), "other", "a.md"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "outside of the repository")
+}
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "plan.md")
+	require.NoError(t, os.WriteFile(src, []byte("# Plan"), 0o600))
+
+	dst := filepath.Join(dir, "wt", "docs", "plans", "plan.md")
+	require.NoError(t, copyFile(src, dst))
+	data, err := os.ReadFile(dst) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Equal(t, "# Plan", string(data))
+
+	err = copyFile(filepath.Join(dir, "missing.md"), dst)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "read ")
 }
 
 func TestResolveVersion(t *testing.T) {
@@ -1049,3 +2606,148 @@ func TestResolveVersion(t *testing.T) {
 		assert.NotEmpty(t, v)
 	})
 }
+
+func TestHandleSignals(t *testing.T) {
+	// waitDone reports whether handleSignals returned within a short time
+	waitDone := func(t *testing.T, done <-chan struct{}) bool {
+		t.Helper()
+		select {
+		case <-done:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}
+	start := func(stop *status.StopHolder, pause *status.PauseHolder) (sigCh chan os.Signal, ctx context.Context,
+		out *bytes.Buffer, done chan struct{}) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		sigCh, out, done = make(chan os.Signal, 1), &bytes.Buffer{}, make(chan struct{})
+		go func() {
+			handleSignals(ctx, sigCh, stop, pause, cancel, out)
+			close(done)
+		}()
+		return sigCh, ctx, out, done
+	}
+
+	t.Run("first interrupt during a run requests a stop, second cancels", func(t *testing.T) {
+		stop := &status.StopHolder{}
+		stop.Begin()
+		defer stop.End()
+		sigCh, ctx, out, done := start(stop, &status.PauseHolder{})
+
+		sigCh <- os.Interrupt
+		require.Eventually(t, stop.Requested, time.Second, 5*time.Millisecond)
+		assert.False(t, waitDone(t, done))
+		require.NoError(t, ctx.Err())
+
+		sigCh <- os.Interrupt
+		require.True(t, waitDone(t, done))
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+		assert.Equal(t, "\nstopping after the current iteration, press Ctrl+C again to abort now\n", out.String())
+	})
+
+	t.Run("interrupt outside of a run cancels", func(t *testing.T) {
+		stop := &status.StopHolder{}
+		sigCh, ctx, out, done := start(stop, &status.PauseHolder{})
+		sigCh <- os.Interrupt
+		require.True(t, waitDone(t, done))
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+		assert.False(t, stop.Requested())
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("sigterm cancels during a run", func(t *testing.T) {
+		stop := &status.StopHolder{}
+		stop.Begin()
+		defer stop.End()
+		sigCh, ctx, _, done := start(stop, &status.PauseHolder{})
+		sigCh <- syscall.SIGTERM
+		require.True(t, waitDone(t, done))
+		require.ErrorIs(t, ctx.Err(), context.Canceled)
+		assert.False(t, stop.Requested())
+	})
+
+	t.Run("pause and resume signals toggle the pause", func(t *testing.T) {
+		if pauseSignal == nil {
+			t.Skip("pause signals are not supported on this platform")
+		}
+		pause := &status.PauseHolder{}
+		sigCh, ctx, out, done := start(&status.StopHolder{}, pause)
+
+		sigCh <- pauseSignal
+		require.Eventually(t, pause.Paused, time.Second, 5*time.Millisecond)
+		sigCh <- pauseSignal // already paused, no-op
+		sigCh <- resumeSignal
+		require.Eventually(t, func() bool { return !pause.Paused() }, time.Second, 5*time.Millisecond)
+		sigCh <- resumeSignal // not paused, no-op
+
+		assert.False(t, waitDone(t, done))
+		require.NoError(t, ctx.Err())
+
+		sigCh <- syscall.SIGTERM // end the handler so its output can be read
+		require.True(t, waitDone(t, done))
+		assert.Equal(t, "\npausing before the next iteration, send SIGUSR2 to resume\n\nresuming\n", out.String())
+	})
+}
+
+func TestReadFindings(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "pr.md")
+	second := filepath.Join(dir, "lint.txt")
+	require.NoError(t, os.WriteFile(first, []byte("- a.go:1 - typo\n"), 0o600))
+	require.NoError(t, os.WriteFile(second, []byte(""), 0o600))
+
+	findings, err := readFindings(nil)
+	require.NoError(t, err)
+	assert.Nil(t, findings, "no files runs the external review")
+
+	findings, err = readFindings([]string{first, second})
+	require.NoError(t, err)
+	assert.Equal(t, []processor.Findings{{File: first, Content: "- a.go:1 - typo\n"}, {File: second, Content: ""}}, findings)
+
+	_, err = readFindings([]string{filepath.Join(dir, "missing.md")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "read findings")
+}
+
+func TestPrintConfig(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config"), []byte("plans_dir = specs\n"), 0o600))
+	t.Setenv("RALPHEX_CODEX_ENABLED", "false")
+
+	var out bytes.Buffer
+	require.NoError(t, printConfig(dir, &out))
+	assert.Contains(t, out.String(), "# codex_enabled: env RALPHEX_CODEX_ENABLED\n# codex_enabled = false\n")
+	assert.Contains(t, out.String(), "# plans_dir: global "+filepath.Join(dir, "config")+"\n# plans_dir = specs\n")
+	assert.Contains(t, out.String(), "# agent_backend: default\n# agent_backend = claude\n")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config"), []byte("task_retry_count = many\n"), 0o600))
+	err := printConfig(dir, &out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load config")
+}
+
+func TestSetConfigValues(t *testing.T) {
+	dir := t.TempDir()
+	var out bytes.Buffer
+	require.NoError(t, setConfigValues(dir, []string{"plans_dir=specs", "codex_enabled=false"}, &out))
+	path := filepath.Join(dir, "config")
+	assert.Equal(t, "set codex_enabled in "+path+"\nset plans_dir in "+path+"\n", out.String())
+
+	cfg, err := config.LoadReadOnly(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "specs", cfg.PlansDir)
+	assert.False(t, cfg.CodexEnabled)
+
+	err = setConfigValues(dir, []string{"codex_enabled=maybe"}, &out)
+	require.ErrorContains(t, err, "set config")
+}
+
+func TestRunConfigure(t *testing.T) {
+	dir := t.TempDir()
+	var out bytes.Buffer
+	require.NoError(t, runConfigure(t.Context(), dir, strings.NewReader("y\n"), &out), "aborted edit is not an error")
+	assert.Contains(t, out.String(), "aborted, config not changed")
+	assert.NoFileExists(t, filepath.Join(dir, "config"))
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// pauseSignal and resumeSignal pause a run before its next iteration and resume it.
+var (
+	pauseSignal  os.Signal = syscall.SIGUSR1
+	resumeSignal os.Signal = syscall.SIGUSR2
+)
+
+// stopSignals end a run, Ctrl+C asks it to stop first, see handleSignals.
+var stopSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// pauseSignal and resumeSignal are not available on windows, a run can be paused from the dashboard only.
+var pauseSignal, resumeSignal os.Signal
+
+// stopSignals end a run, Ctrl+C asks it to stop first, see handleSignals.
+// windows delivers only Ctrl+C and Ctrl+Break, both as os.Interrupt.
+var stopSignals = []os.Signal{os.Interrupt}
@@ -9,6 +9,9 @@ import (
 	"golang.org/x/term"
 )
 
+// ttyPath is the controlling terminal, read by prompts when stdin carries the plan.
+const ttyPath = "/dev/tty"
+
 // disableCtrlCEcho disables the ECHOCTL terminal flag so that pressing Ctrl+C
 // does not echo "^C" to the terminal. returns a function that restores the original state.
 func disableCtrlCEcho() func() {
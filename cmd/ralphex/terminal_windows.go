@@ -2,6 +2,9 @@
 
 package main
 
+// ttyPath is the console input, read by prompts when stdin carries the plan.
+const ttyPath = "CONIN$"
+
 // disableCtrlCEcho is a no-op on windows.
 func disableCtrlCEcho() func() {
 	return func() {}
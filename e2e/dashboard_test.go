@@ -373,6 +373,29 @@ func TestKeyboardShortcutViewModes(t *testing.T) {
 	waitForClass(t, viewToggle, "grouped")
 }
 
+func TestHistoryReplay(t *testing.T) {
+	page := newPage(t)
+	navigateToDashboard(t, page)
+
+	// press h to open the run history
+	err := page.Keyboard().Press("h")
+	require.NoError(t, err)
+	waitVisible(t, page, "#history-overlay", float64(pollTimeout/time.Millisecond))
+	waitVisible(t, page, ".history-item")
+
+	// replay the first run, its log is rendered with the summary header shown
+	err = page.Locator(".history-item").First().Click()
+	require.NoError(t, err)
+	waitHidden(t, page, "#history-overlay", float64(pollTimeout/time.Millisecond))
+	waitVisible(t, page, "#replay-summary")
+	waitForMinCount(t, page.Locator("#output .output-line"), 1)
+
+	// back to the live stream
+	err = page.Locator("#replay-close").Click()
+	require.NoError(t, err)
+	waitHidden(t, page, "#replay-summary", float64(pollTimeout/time.Millisecond))
+}
+
 func TestKeyboardShortcutSectionNavigation(t *testing.T) {
 	page := newPage(t)
 	navigateToDashboard(t, page)
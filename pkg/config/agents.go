@@ -8,6 +8,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -190,3 +191,41 @@ func (al *agentLoader) buildAgent(name, prompt string) CustomAgent {
 	}
 	return CustomAgent{Name: name, Prompt: body, Options: opts}
 }
+
+// default agents of the review prompts' {{agents}} placeholder, used when review_first_agents
+// and review_second_agents are not set
+var (
+	defaultReviewFirstAgents  = []string{"quality", "implementation", "testing", "simplification", "documentation"}
+	defaultReviewSecondAgents = []string{"quality", "implementation"}
+)
+
+// setReviewAgents checks that every agent of review_first_agents and review_second_agents exists,
+// as an agent file or in the [custom_agents] section, and fills in the defaults of unset lists.
+// unknown names fail, listing the available agents.
+func (c *Config) setReviewAgents() error {
+	var available []string
+	for _, a := range slices.Concat(c.CustomAgents, c.ConfigAgents) {
+		if !slices.Contains(available, a.Name) {
+			available = append(available, a.Name)
+		}
+	}
+	lists := []struct {
+		key    string
+		agents []string
+	}{{"review_first_agents", c.ReviewFirstAgents}, {"review_second_agents", c.ReviewSecondAgents}}
+	for _, l := range lists {
+		for _, name := range l.agents {
+			if !slices.Contains(available, name) {
+				return fmt.Errorf("invalid %s: unknown agent %q, available: %s", l.key, name, strings.Join(available, ", "))
+			}
+		}
+	}
+
+	if len(c.ReviewFirstAgents) == 0 {
+		c.ReviewFirstAgents = slices.Clone(defaultReviewFirstAgents)
+	}
+	if len(c.ReviewSecondAgents) == 0 {
+		c.ReviewSecondAgents = slices.Clone(defaultReviewSecondAgents)
+	}
+	return nil
+}
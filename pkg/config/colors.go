@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -21,11 +22,12 @@ func newColorLoader(embedFS embed.FS) *colorLoader {
 	return &colorLoader{embedFS: embedFS}
 }
 
-// Load loads colors from config files with fallback chain: local → global → embedded.
-// localConfigPath and globalConfigPath are full paths to config files (not directories).
+// Load loads colors from config files with fallback chain: local → repo-root file → global → embedded.
+// localConfigPath, repoConfigPath and globalConfigPath are full paths to config files (not directories),
+// repoConfigPath may be a .ralphex.yml (see readConfigData).
 //
 //nolint:dupl // intentional structural similarity with valuesLoader.Load
-func (cl *colorLoader) Load(localConfigPath, globalConfigPath string) (ColorConfig, error) {
+func (cl *colorLoader) Load(localConfigPath, repoConfigPath, globalConfigPath string) (ColorConfig, error) {
 	// start with embedded defaults
 	embedded, err := cl.parseColorsFromEmbedded()
 	if err != nil {
@@ -38,16 +40,35 @@ func (cl *colorLoader) Load(localConfigPath, globalConfigPath string) (ColorConf
 		return ColorConfig{}, fmt.Errorf("parse global config: %w", err)
 	}
 
+	// parse repo-root config file if exists
+	repo, err := cl.parseColorsFromRepoFile(repoConfigPath)
+	if err != nil {
+		return ColorConfig{}, fmt.Errorf("parse repo config: %w", err)
+	}
+
 	// parse local config if exists
 	local, err := cl.parseColorsFromFile(localConfigPath)
 	if err != nil {
 		return ColorConfig{}, fmt.Errorf("parse local config: %w", err)
 	}
 
-	// merge: embedded → global → local (local wins)
+	// parse RALPHEX_* environment overrides
+	env, err := cl.parseColorsFromEnv()
+	if err != nil {
+		return ColorConfig{}, fmt.Errorf("parse environment: %w", err)
+	}
+
+	// merge: embedded → global → repo → local → environment (environment wins).
+	// a theme replaces the embedded colors, it is expanded later by ApplyTheme
+	// so explicit color keys from config files still override it
 	result := embedded
+	if global.Theme != "" || repo.Theme != "" || local.Theme != "" || env.Theme != "" {
+		result = ColorConfig{}
+	}
 	result.mergeFrom(&global)
+	result.mergeFrom(&repo)
 	result.mergeFrom(&local)
+	result.mergeFrom(&env)
 
 	return result, nil
 }
@@ -88,6 +109,13 @@ func (cl *colorLoader) parseColorsFromBytes(data []byte) (ColorConfig, error) {
 
 	var colors ColorConfig
 	section := cfg.Section("")
+	if key, err := section.GetKey("theme"); err == nil {
+		theme := strings.TrimSpace(key.String())
+		if _, ok := colorThemes[theme]; theme != "" && !ok {
+			return ColorConfig{}, fmt.Errorf("invalid theme: %q, expected one of %s", theme, strings.Join(ThemeNames(), ", "))
+		}
+		colors.Theme = theme
+	}
 	colorKeys := []struct {
 		key   string
 		field *string
@@ -147,6 +175,9 @@ func parseHexColor(hex string) (r, g, b int, err error) {
 
 // mergeFrom merges non-empty color values from src into dst.
 func (dst *ColorConfig) mergeFrom(src *ColorConfig) {
+	if src.Theme != "" {
+		dst.Theme = src.Theme
+	}
 	if src.Task != "" {
 		dst.Task = src.Task
 	}
@@ -175,3 +206,41 @@ func (dst *ColorConfig) mergeFrom(src *ColorConfig) {
 		dst.Info = src.Info
 	}
 }
+
+// colorThemes maps built-in theme names to their full palettes.
+var colorThemes = map[string]ColorConfig{
+	// same colors as the embedded defaults, for dark terminal backgrounds
+	"dark": {Task: "0,255,0", Review: "0,255,255", Codex: "208,150,217", ClaudeEval: "189,214,255",
+		Warn: "255,197,109", Error: "255,0,0", Signal: "210,82,82", Timestamp: "138,138,138", Info: "180,180,180"},
+	// darker tones readable on light backgrounds
+	"light": {Task: "0,135,0", Review: "0,118,122", Codex: "135,0,175", ClaudeEval: "0,95,215",
+		Warn: "175,95,0", Error: "215,0,0", Signal: "175,0,0", Timestamp: "108,108,108", Info: "68,68,68"},
+	// solarized accent colors, base01 and base0 for timestamps and info
+	"solarized": {Task: "133,153,0", Review: "42,161,152", Codex: "211,54,130", ClaudeEval: "38,139,210",
+		Warn: "181,137,0", Error: "220,50,47", Signal: "203,75,22", Timestamp: "88,110,117", Info: "131,148,150"},
+	// mid grays readable on both dark and light backgrounds, errors and signals stand out by brightness
+	"mono": {Task: "168,168,168", Review: "168,168,168", Codex: "168,168,168", ClaudeEval: "168,168,168",
+		Warn: "188,188,188", Error: "208,208,208", Signal: "208,208,208", Timestamp: "128,128,128", Info: "148,148,148"},
+}
+
+// ThemeNames returns the names of built-in color themes, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(colorThemes))
+	for name := range colorThemes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ApplyTheme returns the colors with the theme palette filled in for every field not set explicitly.
+// returns the colors unchanged if no theme or an unknown theme is set.
+func (c ColorConfig) ApplyTheme() ColorConfig {
+	theme, ok := colorThemes[c.Theme]
+	if !ok {
+		return c
+	}
+	theme.Theme = c.Theme
+	theme.mergeFrom(&c)
+	return theme
+}
@@ -16,7 +16,7 @@ func Test_newColorLoader(t *testing.T) {
 
 func TestColorLoader_Load_EmbeddedOnly(t *testing.T) {
 	loader := newColorLoader(defaultsFS)
-	colors, err := loader.Load("", "")
+	colors, err := loader.Load("", "", "")
 	require.NoError(t, err)
 
 	// all colors should have expected default values
@@ -42,7 +42,7 @@ color_error = #00ff00
 	require.NoError(t, os.WriteFile(globalConfig, []byte(configContent), 0o600))
 
 	loader := newColorLoader(defaultsFS)
-	colors, err := loader.Load("", globalConfig)
+	colors, err := loader.Load("", "", globalConfig)
 	require.NoError(t, err)
 
 	// custom colors from global config
@@ -71,7 +71,7 @@ color_task = #0000ff
 	require.NoError(t, os.WriteFile(localConfig, []byte(localContent), 0o600))
 
 	loader := newColorLoader(defaultsFS)
-	colors, err := loader.Load(localConfig, globalConfig)
+	colors, err := loader.Load(localConfig, "", globalConfig)
 	require.NoError(t, err)
 
 	// local overrides global
@@ -86,7 +86,7 @@ color_task = #0000ff
 
 func TestColorLoader_Load_NonExistentFiles(t *testing.T) {
 	loader := newColorLoader(defaultsFS)
-	colors, err := loader.Load("/nonexistent/local", "/nonexistent/global")
+	colors, err := loader.Load("/nonexistent/local", "", "/nonexistent/global")
 	require.NoError(t, err)
 
 	// should fall back to embedded defaults
@@ -112,7 +112,7 @@ func TestColorLoader_Load_InvalidColor(t *testing.T) {
 			require.NoError(t, os.WriteFile(configPath, []byte(tc.config), 0o600))
 
 			loader := newColorLoader(defaultsFS)
-			_, err := loader.Load("", configPath)
+			_, err := loader.Load("", "", configPath)
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), tc.errPart)
 		})
@@ -137,7 +137,7 @@ color_info = #191a1b
 	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
 
 	loader := newColorLoader(defaultsFS)
-	colors, err := loader.Load("", configPath)
+	colors, err := loader.Load("", "", configPath)
 	require.NoError(t, err)
 
 	assert.Equal(t, "1,2,3", colors.Task)
@@ -220,6 +220,83 @@ color_review =
 		assert.Equal(t, "255,0,0", colors.Task)
 		assert.Empty(t, colors.Review)
 	})
+
+	t.Run("theme", func(t *testing.T) {
+		colors, err := cl.parseColorsFromBytes([]byte("theme = solarized\ncolor_task = #ff0000\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "solarized", colors.Theme)
+		assert.Equal(t, "255,0,0", colors.Task)
+		assert.Empty(t, colors.Review, "theme is not expanded while parsing")
+	})
+
+	t.Run("unknown theme", func(t *testing.T) {
+		_, err := cl.parseColorsFromBytes([]byte("theme = neon\n"))
+		require.EqualError(t, err, `invalid theme: "neon", expected one of dark, light, mono, solarized`)
+	})
+}
+
+func TestColorLoader_Load_Theme(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+	loader := newColorLoader(defaultsFS)
+
+	t.Run("theme drops embedded colors", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(globalConfig, []byte("theme = light\n"), 0o600))
+		colors, err := loader.Load("", "", globalConfig)
+		require.NoError(t, err)
+		assert.Equal(t, ColorConfig{Theme: "light"}, colors)
+	})
+
+	t.Run("explicit colors override theme from any level", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(globalConfig, []byte("color_warn = #010203\n"), 0o600))
+		require.NoError(t, os.WriteFile(localConfig, []byte("theme = mono\ncolor_error = #040506\n"), 0o600))
+		colors, err := loader.Load(localConfig, "", globalConfig)
+		require.NoError(t, err)
+		assert.Equal(t, ColorConfig{Theme: "mono", Warn: "1,2,3", Error: "4,5,6"}, colors)
+
+		full := colors.ApplyTheme()
+		assert.Equal(t, "1,2,3", full.Warn)
+		assert.Equal(t, "4,5,6", full.Error)
+		assert.Equal(t, colorThemes["mono"].Task, full.Task)
+	})
+
+	t.Run("local theme replaces global theme", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(globalConfig, []byte("theme = light\n"), 0o600))
+		require.NoError(t, os.WriteFile(localConfig, []byte("theme = solarized\n"), 0o600))
+		colors, err := loader.Load(localConfig, "", globalConfig)
+		require.NoError(t, err)
+		assert.Equal(t, "solarized", colors.Theme)
+	})
+}
+
+func TestColorConfig_ApplyTheme(t *testing.T) {
+	t.Run("no theme unchanged", func(t *testing.T) {
+		c := ColorConfig{Task: "1,1,1"}
+		assert.Equal(t, c, c.ApplyTheme())
+	})
+
+	t.Run("unknown theme unchanged", func(t *testing.T) {
+		c := ColorConfig{Theme: "neon", Task: "1,1,1"}
+		assert.Equal(t, c, c.ApplyTheme())
+	})
+
+	t.Run("dark theme matches embedded defaults", func(t *testing.T) {
+		embedded, err := newColorLoader(defaultsFS).Load("", "", "")
+		require.NoError(t, err)
+		dark := ColorConfig{Theme: "dark"}.ApplyTheme()
+		dark.Theme = ""
+		assert.Equal(t, embedded, dark)
+	})
+
+	t.Run("every theme sets every role", func(t *testing.T) {
+		for _, name := range ThemeNames() {
+			c := ColorConfig{Theme: name}.ApplyTheme()
+			for _, v := range []string{c.Task, c.Review, c.Codex, c.ClaudeEval, c.Warn, c.Error, c.Signal, c.Timestamp, c.Info} {
+				assert.NotEmpty(t, v, name)
+			}
+		}
+	})
 }
 
 func TestParseHexColor(t *testing.T) {
@@ -323,6 +400,14 @@ func TestColorConfig_mergeFrom(t *testing.T) {
 		assert.Equal(t, "22,23,24", dst.Timestamp)
 		assert.Equal(t, "25,26,27", dst.Info)
 	})
+
+	t.Run("theme", func(t *testing.T) {
+		dst := &ColorConfig{Theme: "dark"}
+		dst.mergeFrom(&ColorConfig{})
+		assert.Equal(t, "dark", dst.Theme, "empty theme doesn't overwrite")
+		dst.mergeFrom(&ColorConfig{Theme: "light"})
+		assert.Equal(t, "light", dst.Theme)
+	})
 }
 
 func TestColorLoader_parseColorsFromFile_PermissionDenied(t *testing.T) {
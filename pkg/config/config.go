@@ -2,15 +2,17 @@
 package config
 
 import (
+	"cmp"
 	"embed"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/umputun/ralphex/pkg/notify"
 )
 
-//go:embed defaults/config defaults/prompts/* defaults/agents/*
+//go:embed defaults/config defaults/prompts/* defaults/agents/* defaults/templates/*
 var defaultsFS embed.FS
 
 // prompt file names
@@ -21,8 +23,10 @@ const (
 	codexPromptFile        = "codex.txt"
 	makePlanPromptFile     = "make_plan.txt"
 	finalizePromptFile     = "finalize.txt"
+	finalizeFixPromptFile  = "finalize_fix.txt"
 	customReviewPromptFile = "custom_review.txt"
 	customEvalPromptFile   = "custom_eval.txt"
+	geminiPromptFile       = "gemini.txt"
 )
 
 // Config holds all configuration settings for ralphex.
@@ -33,12 +37,33 @@ const (
 // *Set fields:
 //   - CodexEnabledSet: tracks if codex_enabled was explicitly set
 //   - CodexTimeoutMsSet: tracks if codex_timeout_ms was explicitly set
+//   - MaxIterationsSet: tracks if max_iterations was explicitly set
 //   - IterationDelayMsSet: tracks if iteration_delay_ms was explicitly set
+//   - ExecutorTimeoutMsSet: tracks if executor_timeout_ms was explicitly set
+//   - ReviewLoopIterationsSet: tracks if review_loop_iterations was explicitly set
+//   - PlanLoopIterationsSet: tracks if plan_loop_iterations was explicitly set
 //   - TaskRetryCountSet: tracks if task_retry_count was explicitly set
+//   - CodexRetryCountSet: tracks if codex_retry_count was explicitly set
+//   - RetryBaseDelayMsSet: tracks if retry_base_delay_ms was explicitly set
+//   - RetryMaxDelayMsSet: tracks if retry_max_delay_ms was explicitly set
+//   - StallDetectionSet: tracks if stall_detection was explicitly set
+//   - StallIterationsSet: tracks if stall_iterations was explicitly set
 //   - FinalizeEnabledSet: tracks if finalize_enabled was explicitly set
 type Config struct {
-	ClaudeCommand string `json:"claude_command"`
-	ClaudeArgs    string `json:"claude_args"`
+	AgentBackend       string `json:"agent_backend"` // "claude", "gemini" or "ollama", the primary agent
+	ClaudeCommand      string `json:"claude_command"`
+	ClaudeArgs         string `json:"claude_args"`
+	ClaudeOutputFormat string `json:"claude_output_format"` // "stream-json" or "text", empty keeps claude_args as is
+	GeminiCommand      string `json:"gemini_command"`
+	GeminiArgs         string `json:"gemini_args"`
+
+	ClaudeCommandWrapper string            `json:"claude_command_wrapper"` // runs claude and codex through it, e.g. "ssh devbox --"
+	RemotePathMap        map[string]string `json:"remote_path_map"`        // local path prefix -> remote path prefix
+
+	OllamaURL             string `json:"ollama_url"`
+	OllamaModel           string `json:"ollama_model"`
+	OllamaSignalPrompt    bool   `json:"ollama_signal_prompt"` // send the signal protocol as the system prompt
+	OllamaSignalPromptSet bool   `json:"-"`                    // tracks if ollama_signal_prompt was explicitly set in config
 
 	CodexEnabled         bool   `json:"codex_enabled"`
 	CodexEnabledSet      bool   `json:"-"` // tracks if codex_enabled was explicitly set in config
@@ -49,24 +74,136 @@ type Config struct {
 	CodexTimeoutMsSet    bool   `json:"-"` // tracks if codex_timeout_ms was explicitly set in config
 	CodexSandbox         string `json:"codex_sandbox"`
 
-	ExternalReviewTool string `json:"external_review_tool"` // "codex", "custom", or "none"
+	ExternalReviewTool string `json:"external_review_tool"` // "codex", "gemini", "custom", or "none"
 	CustomReviewScript string `json:"custom_review_script"` // path to custom review script
 
-	IterationDelayMs    int  `json:"iteration_delay_ms"`
-	IterationDelayMsSet bool `json:"-"` // tracks if iteration_delay_ms was explicitly set in config
-	TaskRetryCount      int  `json:"task_retry_count"`
-	TaskRetryCountSet   bool `json:"-"` // tracks if task_retry_count was explicitly set in config
+	// agents the {{agents}} placeholder of the review prompts expands to, in order
+	ReviewFirstAgents  []string `json:"review_first_agents"`
+	ReviewSecondAgents []string `json:"review_second_agents"`
+
+	MaxIterations           int  `json:"max_iterations"`
+	MaxIterationsSet        bool `json:"-"` // tracks if max_iterations was explicitly set in config
+	IterationDelayMs        int  `json:"iteration_delay_ms"`
+	IterationDelayMsSet     bool `json:"-"` // tracks if iteration_delay_ms was explicitly set in config
+	ExecutorTimeoutMs       int  `json:"executor_timeout_ms"`
+	ExecutorTimeoutMsSet    bool `json:"-"` // tracks if executor_timeout_ms was explicitly set in config
+	ClaudeTimeoutMinutes    int  `json:"claude_timeout_minutes"`
+	CodexTimeoutMinutes     int  `json:"codex_timeout_minutes"`
+	ReviewLoopIterations    int  `json:"review_loop_iterations"`
+	ReviewLoopIterationsSet bool `json:"-"` // tracks if review_loop_iterations was explicitly set in config
+	PlanLoopIterations      int  `json:"plan_loop_iterations"`
+	PlanLoopIterationsSet   bool `json:"-"` // tracks if plan_loop_iterations was explicitly set in config
+	TaskRetryCount          int  `json:"task_retry_count"`
+	TaskRetryCountSet       bool `json:"-"`                 // tracks if task_retry_count was explicitly set in config
+	CodexRetryCount         int  `json:"codex_retry_count"` // retries of a failed external review call
+	CodexRetryCountSet      bool `json:"-"`                 // tracks if codex_retry_count was explicitly set in config
+	StallDetection          bool `json:"stall_detection"`   // abort the task phase when iterations repeat without commits
+	StallDetectionSet       bool `json:"-"`                 // tracks if stall_detection was explicitly set in config
+	StallIterations         int  `json:"stall_iterations"`  // identical iterations without commits that count as a stall
+	StallIterationsSet      bool `json:"-"`                 // tracks if stall_iterations was explicitly set in config
+
+	TaskCommitRatio        float64 `json:"task_commit_ratio"`        // commits per checked-off task below which the task phase is suspicious
+	StrictTaskVerification bool    `json:"strict_task_verification"` // reopen tasks checked off without a commit instead of finishing the phase
+
+	RetryBaseDelayMs    int  `json:"retry_base_delay_ms"` // delay before the first retry, doubling for each next one
+	RetryBaseDelayMsSet bool `json:"-"`                   // tracks if retry_base_delay_ms was explicitly set in config
+	RetryMaxDelayMs     int  `json:"retry_max_delay_ms"`  // upper bound of the retry delay, 0 means no limit
+	RetryMaxDelayMsSet  bool `json:"-"`                   // tracks if retry_max_delay_ms was explicitly set in config
+
+	PlanChangeAction string `json:"plan_change_action"` // "reload" or "ask", what to do when the plan is edited mid-run
+
+	CostPer1kInput  float64 `json:"cost_per_1k_input"`  // estimated price of 1000 input tokens, 0 disables the estimate
+	CostPer1kOutput float64 `json:"cost_per_1k_output"` // estimated price of 1000 output tokens, 0 disables the estimate
+
+	PriceInput  float64 `json:"price_input"`  // estimated price of a million input tokens, takes precedence over cost_per_1k_input
+	PriceOutput float64 `json:"price_output"` // estimated price of a million output tokens, takes precedence over cost_per_1k_output
 
 	FinalizeEnabled    bool `json:"finalize_enabled"`
 	FinalizeEnabledSet bool `json:"-"` // tracks if finalize_enabled was explicitly set in config
 
-	PlansDir  string   `json:"plans_dir"`
-	WatchDirs []string `json:"watch_dirs"` // directories to watch for progress files
+	FinalizeCommands      []string `json:"finalize_commands"`       // checks run by the finalize step instead of the finalize prompt
+	FinalizeIterations    int      `json:"finalize_max_iterations"` // claude fix attempts while finalize commands fail
+	FinalizeIterationsSet bool     `json:"-"`                       // tracks if finalize_max_iterations was explicitly set in config
+	FinalizeStrict        bool     `json:"finalize_strict"`         // fail the run if finalize commands still fail
+	FinalizeStrictSet     bool     `json:"-"`                       // tracks if finalize_strict was explicitly set in config
+
+	AutoPush    bool `json:"auto_push"` // push the feature branch to origin after a successful full run
+	AutoPushSet bool `json:"-"`         // tracks if auto_push was explicitly set in config
+	GitSign     bool `json:"git_sign"`  // let git sign commits per its config, false forces unsigned commits
+	GitSignSet  bool `json:"-"`         // tracks if git_sign was explicitly set in config
+
+	BranchPrefix string `json:"branch_prefix"` // prepended to branch names derived from plan files, e.g. "ralphex/"
+	// branch name of a plan before branch_prefix, {slug} (the plan name), {date} and {user} are replaced, "{slug}" if empty
+	BranchTemplate string `json:"branch_template"`
+
+	PREnabled    bool `json:"pr_enabled"` // open a pull request with gh after the branch is pushed
+	PREnabledSet bool `json:"-"`          // tracks if pr_enabled was explicitly set in config
+
+	// hook scripts run around phases, empty to skip
+	PreTaskHook      string `json:"pre_task_hook"`
+	PostReviewHook   string `json:"post_review_hook"`
+	PostFinalizeHook string `json:"post_finalize_hook"`
+
+	// project context file injected into prompts as {{PROJECT_CONTEXT}}, see ProjectContext
+	ContextFile        string `json:"context_file"`      // path relative to the project root, .ralphex/context.md if empty
+	ContextMaxBytes    int    `json:"context_max_bytes"` // longer context is truncated, defaultContextMaxBytes if not set
+	ContextMaxBytesSet bool   `json:"-"`                 // tracks if context_max_bytes was explicitly set in config
+
+	// project context read from the context file at load time
+	ProjectContext ProjectContext `json:"-"`
+
+	PlansDir            string   `json:"plans_dir"`
+	WatchDirs           []string `json:"watch_dirs"`         // directories or glob patterns to watch for progress files
+	WatchPruneHours     int      `json:"watch_prune_hours"`  // drop stopped watched sessions older than this, 0 keeps them
+	WatchPruneHoursSet  bool     `json:"-"`                  // tracks if watch_prune_hours was explicitly set in config
+	WatchIdleMinutes    int      `json:"watch_idle_minutes"` // mark watched sessions without writes this long completed, 0 disables
+	WatchIdleMinutesSet bool     `json:"-"`                  // tracks if watch_idle_minutes was explicitly set in config
+	WebMetrics          bool     `json:"web_metrics"`        // serve prometheus metrics on the dashboard /metrics endpoint
+	WebMetricsSet       bool     `json:"-"`                  // tracks if web_metrics was explicitly set in config
+	WebWebSocket        bool     `json:"web_websocket"`      // dashboard streams over a websocket that accepts control commands
+	WebWebSocketSet     bool     `json:"-"`                  // tracks if web_websocket was explicitly set in config
+	WebListen           string   `json:"web_listen"`         // address the dashboard listens on, empty for 127.0.0.1
+	WebAuthToken        string   `json:"-"`                  // token required to access the dashboard, kept out of JSON output
+
+	// where finished plans are moved, see plan.Archive
+	CompletedDir           string `json:"completed_dir"`             // directory in plans_dir, "completed" by default
+	CompletedDirDateLayout string `json:"completed_dir_date_layout"` // time layout of a dated subdirectory, none if empty
+
+	// plan commit messages with {branch}, {plan} and {date} placeholders, git.CommitMessages defaults if empty
+	CommitMsgAddPlan  string `json:"commit_msg_add_plan"`
+	CommitMsgMovePlan string `json:"commit_msg_move_plan"`
+
+	// search of watched directories, see web.WatchOptions
+	WatchDepth    int      `json:"watch_depth"`  // directory levels searched below a watched directory, 0 is unlimited
+	WatchDepthSet bool     `json:"-"`            // tracks if watch_depth was explicitly set in config
+	WatchIgnore   []string `json:"watch_ignore"` // directory names or name patterns skipped while searching
+
+	MoveCompleted    bool `json:"move_completed"` // move the plan to completed_dir after a successful run
+	MoveCompletedSet bool `json:"-"`              // tracks if move_completed was explicitly set in config
+
+	ProgressDir     string `json:"progress_dir"`  // directory for progress files, empty for the default .ralphex/progress
+	ProgressKeep    int    `json:"progress_keep"` // per-run progress files to keep for the same plan and mode, 0 keeps all
+	ProgressKeepSet bool   `json:"-"`             // tracks if progress_keep was explicitly set in config
+	ProgressJSON    bool   `json:"progress_json"` // also write structured events to <progress>.jsonl
+	ProgressJSONSet bool   `json:"-"`             // tracks if progress_json was explicitly set in config
+
+	ProgressMaxSizeMB    int  `json:"progress_max_size_mb"` // rotate a progress file larger than this, 0 disables rotation
+	ProgressMaxSizeMBSet bool `json:"-"`                    // tracks if progress_max_size_mb was explicitly set in config
+	ProgressBackups      int  `json:"progress_backups"`     // rotated backups kept per progress file
+	ProgressBackupsSet   bool `json:"-"`                    // tracks if progress_backups was explicitly set in config
 
 	// error patterns to detect in executor output (e.g., rate limit messages)
 	ClaudeErrorPatterns []string `json:"claude_error_patterns"`
+	GeminiErrorPatterns []string `json:"gemini_error_patterns"`
 	CodexErrorPatterns  []string `json:"codex_error_patterns"`
 
+	// user-defined regex error patterns from the [error_patterns] section, checked for every executor
+	ErrorPatterns []ErrorPattern `json:"error_patterns"`
+
+	// codex findings filter, applied before claude evaluates codex output
+	CodexIgnorePatterns []string `json:"codex_ignore_patterns"` // regexes, matching findings are dropped
+	CodexMinSeverity    string   `json:"codex_min_severity"`    // low, medium, high or critical, empty keeps all
+
 	// notification parameters
 	NotifyParams notify.Params `json:"-"`
 
@@ -80,14 +217,21 @@ type Config struct {
 	CodexPrompt        string `json:"-"`
 	MakePlanPrompt     string `json:"-"`
 	FinalizePrompt     string `json:"-"`
+	FinalizeFixPrompt  string `json:"-"`
 	CustomReviewPrompt string `json:"-"`
 	CustomEvalPrompt   string `json:"-"`
+	GeminiPrompt       string `json:"-"`
 
 	// custom agents (loaded separately from files)
 	CustomAgents []CustomAgent `json:"-"`
 
-	configDir string // private, global config directory set by Load()
-	localDir  string // private, local project config directory (.ralphex/) if found
+	// agents defined inline in the [custom_agents] section, they win over agent files of the same name
+	ConfigAgents []CustomAgent `json:"-"`
+
+	configDir string        // private, global config directory set by Load()
+	localDir  string        // private, repo-local config directory (.ralphex/) if found
+	repoFile  string        // private, repo-root config file (.ralphex.conf or .ralphex.yml) if found
+	upgrade   configUpgrade // private, global config file older than the embedded defaults, see UpgradeNotice
 }
 
 // CustomAgent represents a user-defined review agent.
@@ -97,9 +241,18 @@ type CustomAgent struct {
 	Options        // embedded: model and agent type parsed from frontmatter
 }
 
+// ErrorPattern is a user-defined regular expression detected in executor output.
+type ErrorPattern struct {
+	Pattern string `json:"pattern"`  // regular expression, compiled at config load
+	HelpCmd string `json:"help_cmd"` // command to run for more information, empty uses the tool's default
+}
+
 // ColorConfig holds RGB values for output colors.
 // each field stores comma-separated RGB values (e.g., "255,0,0" for red).
+// Theme names a built-in palette, fields set explicitly override it (see ApplyTheme).
 type ColorConfig struct {
+	Theme string // built-in theme name, empty means no theme
+
 	Task       string // task execution phase
 	Review     string // review phase
 	Codex      string // codex external review
@@ -113,7 +266,9 @@ type ColorConfig struct {
 
 // Load loads all configuration from the specified directory.
 // If configDir is empty, uses the default location (~/.config/ralphex/).
-// It also auto-detects .ralphex/ in the current working directory for local overrides.
+// It also auto-detects a repo-local .ralphex/ (see detectLocalDir) and a repo-root .ralphex.conf
+// or .ralphex.yml (see detectRepoFile) for overrides.
+// Precedence is per-field: RALPHEX_* environment > repo-local > repo-root file > global > embedded defaults.
 // It installs defaults if needed, parses config file, loads prompts and agents.
 func Load(configDir string) (*Config, error) {
 	globalDir := configDir
@@ -121,31 +276,68 @@ func Load(configDir string) (*Config, error) {
 		globalDir = DefaultConfigDir()
 	}
 
-	// auto-detect local config directory in cwd.
-	// os.Getwd() failure is silently ignored - local config is optional,
-	// and the global config will still work correctly.
-	var localDir string
-	if cwd, err := os.Getwd(); err == nil {
-		candidate := filepath.Join(cwd, ".ralphex")
+	repoFile, err := detectRepoFile()
+	if err != nil {
+		return nil, err
+	}
+	return loadWithLocal(globalDir, detectLocalDir(), repoFile)
+}
+
+// detectLocalDir returns the repo-local config directory (.ralphex/), or empty string if there is none.
+// os.Getwd() failure is silently ignored - local config is optional,
+// and the global config will still work correctly.
+func detectLocalDir() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return findLocalDir(cwd)
+}
+
+// findLocalDir looks for .ralphex/ in start and its parents up to the repository root (directory with .git),
+// so running from a subdirectory still picks up repo settings. outside a repository (e.g. watch-only mode)
+// only start itself is checked. a plain .ralphex file is not supported, .ralphex/ also holds progress logs.
+func findLocalDir(start string) string {
+	root := findRepoRoot(start)
+	if root == "" {
+		root = start
+	}
+
+	for dir := start; ; dir = filepath.Dir(dir) {
+		candidate := filepath.Join(dir, ".ralphex")
 		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
-			localDir = candidate
+			return candidate
+		}
+		if dir == root || filepath.Dir(dir) == dir {
+			return ""
 		}
 	}
+}
 
-	return loadWithLocal(globalDir, localDir)
+// findRepoRoot returns the repository root (directory with .git) containing start,
+// or empty string if start is not in a repository.
+func findRepoRoot(start string) string {
+	for dir := start; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		if filepath.Dir(dir) == dir {
+			return "" // filesystem root reached, not in a repository
+		}
+	}
 }
 
 // loadWithLocal loads configuration with explicit global and local directories.
-// local config (.ralphex/) overrides global config (~/.config/ralphex/) per-field.
-// if localDir is empty, only global config is used.
-func loadWithLocal(globalDir, localDir string) (*Config, error) {
+// local config (.ralphex/) overrides the repo-root file, which overrides global config (~/.config/ralphex/) per-field.
+// if localDir and repoFile are empty, only global config is used.
+func loadWithLocal(globalDir, localDir, repoFile string) (*Config, error) {
 	// install defaults
 	installer := newDefaultsInstaller(defaultsFS)
 	if err := installer.Install(globalDir); err != nil {
 		return nil, fmt.Errorf("install defaults: %w", err)
 	}
 
-	return loadConfigFromDirs(globalDir, localDir)
+	return loadConfigFromDirs(globalDir, localDir, repoFile)
 }
 
 // LoadReadOnly loads configuration without installing defaults.
@@ -157,21 +349,16 @@ func LoadReadOnly(configDir string) (*Config, error) {
 		globalDir = DefaultConfigDir()
 	}
 
-	// auto-detect local config directory in cwd
-	var localDir string
-	if cwd, err := os.Getwd(); err == nil {
-		candidate := filepath.Join(cwd, ".ralphex")
-		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
-			localDir = candidate
-		}
+	repoFile, err := detectRepoFile()
+	if err != nil {
+		return nil, err
 	}
-
-	return loadConfigFromDirs(globalDir, localDir)
+	return loadConfigFromDirs(globalDir, detectLocalDir(), repoFile)
 }
 
-// loadConfigFromDirs loads configuration from specified directories without installing defaults.
+// loadConfigFromDirs loads configuration from specified directories and repo-root file without installing defaults.
 // shared by loadWithLocal (after installing) and LoadReadOnly (without installing).
-func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
+func loadConfigFromDirs(globalDir, localDir, repoFile string) (*Config, error) {
 	embedFS := defaultsFS
 
 	// build config file paths
@@ -183,14 +370,14 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 
 	// load values (scalars) - falls back to embedded if files don't exist
 	vl := newValuesLoader(embedFS)
-	values, err := vl.Load(localConfigPath, globalConfigPath)
+	values, err := vl.Load(localConfigPath, repoFile, globalConfigPath)
 	if err != nil {
 		return nil, fmt.Errorf("load values: %w", err)
 	}
 
 	// load colors
 	cl := newColorLoader(embedFS)
-	colors, err := cl.Load(localConfigPath, globalConfigPath)
+	colors, err := cl.Load(localConfigPath, repoFile, globalConfigPath)
 	if err != nil {
 		return nil, fmt.Errorf("load colors: %w", err)
 	}
@@ -221,28 +408,119 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 
 	// assemble config
 	c := &Config{
-		ClaudeCommand:        values.ClaudeCommand,
-		ClaudeArgs:           values.ClaudeArgs,
-		CodexEnabled:         values.CodexEnabled,
-		CodexEnabledSet:      values.CodexEnabledSet,
-		CodexCommand:         values.CodexCommand,
-		CodexModel:           values.CodexModel,
-		CodexReasoningEffort: values.CodexReasoningEffort,
-		CodexTimeoutMs:       values.CodexTimeoutMs,
-		CodexTimeoutMsSet:    values.CodexTimeoutMsSet,
-		CodexSandbox:         values.CodexSandbox,
-		ExternalReviewTool:   values.ExternalReviewTool,
-		CustomReviewScript:   values.CustomReviewScript,
-		IterationDelayMs:     values.IterationDelayMs,
-		IterationDelayMsSet:  values.IterationDelayMsSet,
-		TaskRetryCount:       values.TaskRetryCount,
-		TaskRetryCountSet:    values.TaskRetryCountSet,
-		FinalizeEnabled:      values.FinalizeEnabled,
-		FinalizeEnabledSet:   values.FinalizeEnabledSet,
-		PlansDir:             values.PlansDir,
-		WatchDirs:            values.WatchDirs,
-		ClaudeErrorPatterns:  values.ClaudeErrorPatterns,
-		CodexErrorPatterns:   values.CodexErrorPatterns,
+		AgentBackend:            values.AgentBackend,
+		ClaudeCommand:           values.ClaudeCommand,
+		ClaudeArgs:              values.ClaudeArgs,
+		ClaudeOutputFormat:      values.ClaudeOutputFormat,
+		ClaudeCommandWrapper:    values.ClaudeCommandWrapper,
+		RemotePathMap:           values.RemotePathMap,
+		GeminiCommand:           values.GeminiCommand,
+		GeminiArgs:              values.GeminiArgs,
+		OllamaURL:               values.OllamaURL,
+		OllamaModel:             values.OllamaModel,
+		OllamaSignalPrompt:      values.OllamaSignalPrompt,
+		OllamaSignalPromptSet:   values.OllamaSignalPromptSet,
+		CodexEnabled:            values.CodexEnabled,
+		CodexEnabledSet:         values.CodexEnabledSet,
+		CodexCommand:            values.CodexCommand,
+		CodexModel:              values.CodexModel,
+		CodexReasoningEffort:    values.CodexReasoningEffort,
+		CodexTimeoutMs:          values.CodexTimeoutMs,
+		CodexTimeoutMsSet:       values.CodexTimeoutMsSet,
+		CodexSandbox:            values.CodexSandbox,
+		ExternalReviewTool:      values.ExternalReviewTool,
+		CustomReviewScript:      values.CustomReviewScript,
+		ReviewFirstAgents:       values.ReviewFirstAgents,
+		ReviewSecondAgents:      values.ReviewSecondAgents,
+		MaxIterations:           values.MaxIterations,
+		MaxIterationsSet:        values.MaxIterationsSet,
+		IterationDelayMs:        values.IterationDelayMs,
+		IterationDelayMsSet:     values.IterationDelayMsSet,
+		ExecutorTimeoutMs:       values.ExecutorTimeoutMs,
+		ExecutorTimeoutMsSet:    values.ExecutorTimeoutMsSet,
+		ClaudeTimeoutMinutes:    values.ClaudeTimeoutMinutes,
+		CodexTimeoutMinutes:     values.CodexTimeoutMinutes,
+		ReviewLoopIterations:    values.ReviewLoopIterations,
+		ReviewLoopIterationsSet: values.ReviewLoopIterationsSet,
+		PlanLoopIterations:      values.PlanLoopIterations,
+		PlanLoopIterationsSet:   values.PlanLoopIterationsSet,
+		TaskRetryCount:          values.TaskRetryCount,
+		TaskRetryCountSet:       values.TaskRetryCountSet,
+		CodexRetryCount:         values.CodexRetryCount,
+		CodexRetryCountSet:      values.CodexRetryCountSet,
+		RetryBaseDelayMs:        values.RetryBaseDelayMs,
+		RetryBaseDelayMsSet:     values.RetryBaseDelayMsSet,
+		RetryMaxDelayMs:         values.RetryMaxDelayMs,
+		RetryMaxDelayMsSet:      values.RetryMaxDelayMsSet,
+		StallDetection:          values.StallDetection,
+		StallDetectionSet:       values.StallDetectionSet,
+		StallIterations:         values.StallIterations,
+		StallIterationsSet:      values.StallIterationsSet,
+		TaskCommitRatio:         values.TaskCommitRatio,
+		StrictTaskVerification:  values.StrictTaskVerification,
+		PlanChangeAction:        values.PlanChangeAction,
+		CostPer1kInput:          values.CostPer1kInput,
+		CostPer1kOutput:         values.CostPer1kOutput,
+		PriceInput:              values.PriceInput,
+		PriceOutput:             values.PriceOutput,
+		FinalizeEnabled:         values.FinalizeEnabled,
+		FinalizeEnabledSet:      values.FinalizeEnabledSet,
+		FinalizeCommands:        values.FinalizeCommands,
+		FinalizeIterations:      values.FinalizeIterations,
+		FinalizeIterationsSet:   values.FinalizeIterationsSet,
+		FinalizeStrict:          values.FinalizeStrict,
+		FinalizeStrictSet:       values.FinalizeStrictSet,
+		AutoPush:                values.AutoPush,
+		AutoPushSet:             values.AutoPushSet,
+		GitSign:                 values.GitSign,
+		GitSignSet:              values.GitSignSet,
+		BranchPrefix:            values.BranchPrefix,
+		BranchTemplate:          values.BranchTemplate,
+		PREnabled:               values.PREnabled,
+		PREnabledSet:            values.PREnabledSet,
+		CommitMsgAddPlan:        values.CommitMsgAddPlan,
+		CommitMsgMovePlan:       values.CommitMsgMovePlan,
+		PreTaskHook:             values.PreTaskHook,
+		PostReviewHook:          values.PostReviewHook,
+		PostFinalizeHook:        values.PostFinalizeHook,
+		ContextFile:             values.ContextFile,
+		ContextMaxBytes:         values.ContextMaxBytes,
+		ContextMaxBytesSet:      values.ContextMaxBytesSet,
+		PlansDir:                values.PlansDir,
+		CompletedDir:            values.CompletedDir,
+		CompletedDirDateLayout:  values.CompletedDirDateLayout,
+		MoveCompleted:           values.MoveCompleted,
+		MoveCompletedSet:        values.MoveCompletedSet,
+		ProgressDir:             values.ProgressDir,
+		ProgressKeep:            values.ProgressKeep,
+		ProgressKeepSet:         values.ProgressKeepSet,
+		ProgressJSON:            values.ProgressJSON,
+		ProgressJSONSet:         values.ProgressJSONSet,
+		ProgressMaxSizeMB:       values.ProgressMaxSizeMB,
+		ProgressMaxSizeMBSet:    values.ProgressMaxSizeMBSet,
+		ProgressBackups:         values.ProgressBackups,
+		ProgressBackupsSet:      values.ProgressBackupsSet,
+		WatchDirs:               values.WatchDirs,
+		WatchDepth:              values.WatchDepth,
+		WatchDepthSet:           values.WatchDepthSet,
+		WatchIgnore:             values.WatchIgnore,
+		WatchPruneHours:         values.WatchPruneHours,
+		WatchPruneHoursSet:      values.WatchPruneHoursSet,
+		WatchIdleMinutes:        values.WatchIdleMinutes,
+		WatchIdleMinutesSet:     values.WatchIdleMinutesSet,
+		WebMetrics:              values.WebMetrics,
+		WebMetricsSet:           values.WebMetricsSet,
+		WebWebSocket:            values.WebWebSocket,
+		WebWebSocketSet:         values.WebWebSocketSet,
+		WebListen:               values.WebListen,
+		WebAuthToken:            values.WebAuthToken,
+		ClaudeErrorPatterns:     values.ClaudeErrorPatterns,
+		GeminiErrorPatterns:     values.GeminiErrorPatterns,
+		CodexErrorPatterns:      values.CodexErrorPatterns,
+		CodexIgnorePatterns:     values.CodexIgnorePatterns,
+		ErrorPatterns:           values.ErrorPatterns,
+		ConfigAgents:            values.ConfigAgents,
+		CodexMinSeverity:        values.CodexMinSeverity,
 		NotifyParams: notify.Params{
 			Channels:      values.NotifyChannels,
 			OnError:       values.NotifyOnError,
@@ -260,7 +538,9 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 			EmailFrom:     values.NotifyEmailFrom,
 			EmailTo:       values.NotifyEmailTo,
 			WebhookURLs:   values.NotifyWebhookURLs,
+			WebhookFormat: values.NotifyWebhookFormat,
 			CustomScript:  values.NotifyCustomScript,
+			Notifier:      values.Notifier,
 		},
 		Colors:             colors,
 		TaskPrompt:         prompts.Task,
@@ -269,11 +549,31 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 		CodexPrompt:        prompts.Codex,
 		MakePlanPrompt:     prompts.MakePlan,
 		FinalizePrompt:     prompts.Finalize,
+		FinalizeFixPrompt:  prompts.FinalizeFix,
 		CustomReviewPrompt: prompts.CustomReview,
 		CustomEvalPrompt:   prompts.CustomEval,
+		GeminiPrompt:       prompts.Gemini,
 		CustomAgents:       agents,
 		configDir:          globalDir,
 		localDir:           localDir,
+		repoFile:           repoFile,
+		upgrade:            checkConfigUpgrade(globalConfigPath),
+	}
+
+	if err = c.setReviewAgents(); err != nil {
+		return nil, err
+	}
+
+	// project context, checked against every prompt that can reference it
+	contextPrompts := []string{c.TaskPrompt, c.ReviewFirstPrompt, c.ReviewSecondPrompt, c.CodexPrompt, c.MakePlanPrompt,
+		c.FinalizePrompt, c.FinalizeFixPrompt, c.CustomReviewPrompt, c.CustomEvalPrompt, c.GeminiPrompt}
+	for _, agent := range slices.Concat(c.CustomAgents, c.ConfigAgents) {
+		contextPrompts = append(contextPrompts, agent.Prompt)
+	}
+	c.ProjectContext, err = loadProjectContext(values.ContextFile, localDir, cmp.Or(values.ContextMaxBytes, defaultContextMaxBytes),
+		contextPrompts...)
+	if err != nil {
+		return nil, fmt.Errorf("load project context: %w", err)
 	}
 
 	// notify_on_error and notify_on_complete default to true when not explicitly set
@@ -283,10 +583,29 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 	if !values.NotifyOnCompleteSet {
 		c.NotifyParams.OnComplete = true
 	}
+	c.NotifyParams = withWebhookURL(c.NotifyParams, values.NotifyWebhookURL)
 
 	return c, nil
 }
 
+// withWebhookURL applies the notify_webhook_url shortcut: the url is added to the webhook urls,
+// the webhook channel is enabled and the payload defaults to json unless notify_webhook_format is set.
+func withWebhookURL(p notify.Params, url string) notify.Params {
+	if url == "" {
+		return p
+	}
+	if !slices.Contains(p.WebhookURLs, url) {
+		p.WebhookURLs = append(slices.Clone(p.WebhookURLs), url)
+	}
+	if !slices.Contains(p.Channels, "webhook") {
+		p.Channels = append(slices.Clone(p.Channels), "webhook")
+	}
+	if p.WebhookFormat == "" {
+		p.WebhookFormat = "json"
+	}
+	return p
+}
+
 // DefaultConfigDir returns the default configuration directory path.
 // returns ~/.config/ralphex/ on all platforms.
 // if os.UserHomeDir() fails, falls back to ./.config/ralphex/ silently -
@@ -304,3 +623,22 @@ func DefaultConfigDir() string {
 func (c *Config) LocalDir() string {
 	return c.localDir
 }
+
+// RepoFile returns the repo-root config file (.ralphex.conf or .ralphex.yml) if one was detected.
+// returns empty string if no repo-root file was used.
+func (c *Config) RepoFile() string {
+	return c.repoFile
+}
+
+// TokenPrices returns the estimated prices of a million input and output tokens, zero if not set.
+// price_input and price_output take precedence, cost_per_1k_input and cost_per_1k_output are converted otherwise.
+func (c *Config) TokenPrices() (perMInput, perMOutput float64) {
+	perMInput, perMOutput = c.PriceInput, c.PriceOutput
+	if perMInput == 0 {
+		perMInput = c.CostPer1kInput * 1000
+	}
+	if perMOutput == 0 {
+		perMOutput = c.CostPer1kOutput * 1000
+	}
+	return perMInput, perMOutput
+}
@@ -29,8 +29,8 @@ func Test_defaultsFS_PromptFiles(t *testing.T) {
 		contains []string
 	}{
 		{file: "defaults/prompts/task.txt", contains: []string{"{{PLAN_FILE}}", "{{PROGRESS_FILE}}", "RALPHEX:ALL_TASKS_DONE", "RALPHEX:TASK_FAILED"}},
-		{file: "defaults/prompts/review_first.txt", contains: []string{"{{GOAL}}", "{{PROGRESS_FILE}}", "RALPHEX:REVIEW_DONE", "{{agent:quality}}", "{{agent:testing}}"}},
-		{file: "defaults/prompts/review_second.txt", contains: []string{"{{GOAL}}", "{{PROGRESS_FILE}}", "RALPHEX:REVIEW_DONE", "{{agent:quality}}", "{{agent:implementation}}"}},
+		{file: "defaults/prompts/review_first.txt", contains: []string{"{{GOAL}}", "{{PROGRESS_FILE}}", "RALPHEX:REVIEW_DONE", "{{agents}}"}},
+		{file: "defaults/prompts/review_second.txt", contains: []string{"{{GOAL}}", "{{PROGRESS_FILE}}", "RALPHEX:REVIEW_DONE", "{{agents}}"}},
 		{file: "defaults/prompts/codex.txt", contains: []string{"{{CODEX_OUTPUT}}", "RALPHEX:CODEX_REVIEW_DONE", "Codex reviewed"}},
 	}
 
@@ -316,6 +316,9 @@ func TestLoad_AllUserValues(t *testing.T) {
 	configContent := `
 claude_command = /custom/claude
 claude_args = --custom
+claude_output_format = text
+claude_command_wrapper = ssh devbox --
+remote_path_map = /home/me/src=/srv/src, /tmp=/var/tmp
 codex_enabled = false
 codex_command = /custom/codex
 codex_model = custom-model
@@ -324,6 +327,10 @@ codex_timeout_ms = 1000
 codex_sandbox = none
 iteration_delay_ms = 500
 task_retry_count = 5
+plan_change_action = ask
+finalize_commands = go test ./..., golangci-lint run
+finalize_max_iterations = 5
+finalize_strict = true
 plans_dir = my/plans
 `
 	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(configContent), 0o600))
@@ -334,6 +341,13 @@ plans_dir = my/plans
 	// all values should be user-specified, not defaults
 	assert.Equal(t, "/custom/claude", cfg.ClaudeCommand)
 	assert.Equal(t, "--custom", cfg.ClaudeArgs)
+	assert.Equal(t, "text", cfg.ClaudeOutputFormat)
+	assert.Equal(t, "ssh devbox --", cfg.ClaudeCommandWrapper)
+	assert.Equal(t, map[string]string{"/home/me/src": "/srv/src", "/tmp": "/var/tmp"}, cfg.RemotePathMap)
+	assert.Equal(t, "ask", cfg.PlanChangeAction)
+	assert.Equal(t, []string{"go test ./...", "golangci-lint run"}, cfg.FinalizeCommands)
+	assert.Equal(t, 5, cfg.FinalizeIterations)
+	assert.True(t, cfg.FinalizeStrict)
 	assert.False(t, cfg.CodexEnabled)
 	assert.Equal(t, "/custom/codex", cfg.CodexCommand)
 	assert.Equal(t, "custom-model", cfg.CodexModel)
@@ -351,7 +365,7 @@ func TestLocalConfig_NoLocalDir(t *testing.T) {
 	tmpDir := t.TempDir()
 	globalDir := filepath.Join(tmpDir, "global")
 
-	cfg, err := loadWithLocal(globalDir, "")
+	cfg, err := loadWithLocal(globalDir, "", "")
 	require.NoError(t, err)
 
 	assert.Equal(t, globalDir, cfg.configDir)
@@ -364,13 +378,91 @@ func TestLocalConfig_WithLocalDir(t *testing.T) {
 	localDir := filepath.Join(tmpDir, ".ralphex")
 	require.NoError(t, os.MkdirAll(localDir, 0o700))
 
-	cfg, err := loadWithLocal(globalDir, localDir)
+	cfg, err := loadWithLocal(globalDir, localDir, "")
 	require.NoError(t, err)
 
 	assert.Equal(t, globalDir, cfg.configDir)
 	assert.Equal(t, localDir, cfg.LocalDir())
 }
 
+func TestFindLocalDir(t *testing.T) {
+	mkdirs := func(t *testing.T, base string, dirs ...string) {
+		t.Helper()
+		for _, d := range dirs {
+			require.NoError(t, os.MkdirAll(filepath.Join(base, d), 0o700))
+		}
+	}
+
+	t.Run("repo root from subdirectory", func(t *testing.T) {
+		base := t.TempDir()
+		mkdirs(t, base, "repo/.git", "repo/.ralphex", "repo/pkg/sub")
+		assert.Equal(t, filepath.Join(base, "repo", ".ralphex"), findLocalDir(filepath.Join(base, "repo", "pkg", "sub")))
+	})
+
+	t.Run("nearest directory wins", func(t *testing.T) {
+		base := t.TempDir()
+		mkdirs(t, base, "repo/.git", "repo/.ralphex", "repo/pkg/.ralphex", "repo/pkg/sub")
+		assert.Equal(t, filepath.Join(base, "repo", "pkg", ".ralphex"), findLocalDir(filepath.Join(base, "repo", "pkg", "sub")))
+	})
+
+	t.Run("stops at repo root", func(t *testing.T) {
+		base := t.TempDir()
+		mkdirs(t, base, ".ralphex", "repo/.git", "repo/pkg")
+		assert.Empty(t, findLocalDir(filepath.Join(base, "repo", "pkg")))
+	})
+
+	t.Run("outside repo checks only start dir", func(t *testing.T) {
+		base := t.TempDir()
+		mkdirs(t, base, ".ralphex", "watch")
+		assert.Empty(t, findLocalDir(filepath.Join(base, "watch")))
+		assert.Equal(t, filepath.Join(base, ".ralphex"), findLocalDir(base))
+	})
+
+	t.Run("git worktree file marks repo root", func(t *testing.T) {
+		base := t.TempDir()
+		mkdirs(t, base, "wt/.ralphex", "wt/cmd")
+		require.NoError(t, os.WriteFile(filepath.Join(base, "wt", ".git"), []byte("gitdir: /elsewhere"), 0o600))
+		assert.Equal(t, filepath.Join(base, "wt", ".ralphex"), findLocalDir(filepath.Join(base, "wt", "cmd")))
+	})
+
+	t.Run("plain file is ignored", func(t *testing.T) {
+		base := t.TempDir()
+		mkdirs(t, base, ".git")
+		require.NoError(t, os.WriteFile(filepath.Join(base, ".ralphex"), []byte("plans_dir = x"), 0o600))
+		assert.Empty(t, findLocalDir(base))
+	})
+}
+
+func TestConfig_ValueSource(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalDir := filepath.Join(tmpDir, "global")
+	localDir := filepath.Join(tmpDir, ".ralphex")
+	require.NoError(t, os.MkdirAll(globalDir, 0o700))
+	require.NoError(t, os.MkdirAll(localDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"),
+		[]byte("plans_dir = global-plans\ncodex_enabled = false\nclaude_args =\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte("plans_dir = repo-plans\n"), 0o600))
+
+	cfg, err := loadConfigFromDirs(globalDir, localDir, "")
+	require.NoError(t, err)
+	assert.Equal(t, "repo-plans", cfg.PlansDir)
+
+	tests := []struct {
+		key, want string
+	}{
+		{key: "plans_dir", want: filepath.Join(localDir, "config")},
+		{key: "codex_enabled", want: filepath.Join(globalDir, "config")},
+		{key: "claude_args", want: "embedded"}, // empty value doesn't override
+		{key: "task_retry_count", want: "embedded"},
+		{key: "notify_telegram_token", want: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.key, func(t *testing.T) {
+			assert.Equal(t, tc.want, cfg.ValueSource(tc.key))
+		})
+	}
+}
+
 func TestLocalConfig_LocalOverridesGlobal(t *testing.T) {
 	tmpDir := t.TempDir()
 	globalDir := filepath.Join(tmpDir, "global")
@@ -397,7 +489,7 @@ plans_dir = local/plans
 `
 	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte(localConfig), 0o600))
 
-	cfg, err := loadWithLocal(globalDir, localDir)
+	cfg, err := loadWithLocal(globalDir, localDir, "")
 	require.NoError(t, err)
 
 	// local values override global
@@ -432,7 +524,7 @@ color_task = #0000ff
 `
 	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte(localConfig), 0o600))
 
-	cfg, err := loadWithLocal(globalDir, localDir)
+	cfg, err := loadWithLocal(globalDir, localDir, "")
 	require.NoError(t, err)
 
 	// local color overrides global
@@ -460,7 +552,7 @@ func TestLocalConfig_LocalOverridesCodexEnabled(t *testing.T) {
 	localConfig := `codex_enabled = false`
 	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte(localConfig), 0o600))
 
-	cfg, err := loadWithLocal(globalDir, localDir)
+	cfg, err := loadWithLocal(globalDir, localDir, "")
 	require.NoError(t, err)
 
 	assert.False(t, cfg.CodexEnabled)
@@ -485,7 +577,7 @@ func TestLocalConfig_LocalOverridesTaskRetryCount(t *testing.T) {
 	localConfig := `task_retry_count = 0`
 	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte(localConfig), 0o600))
 
-	cfg, err := loadWithLocal(globalDir, localDir)
+	cfg, err := loadWithLocal(globalDir, localDir, "")
 	require.NoError(t, err)
 
 	assert.Equal(t, 0, cfg.TaskRetryCount)
@@ -505,7 +597,7 @@ func TestLocalConfig_NoLocalConfigFile(t *testing.T) {
 	globalConfig := `claude_command = global-claude`
 	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"), []byte(globalConfig), 0o600))
 
-	cfg, err := loadWithLocal(globalDir, localDir)
+	cfg, err := loadWithLocal(globalDir, localDir, "")
 	require.NoError(t, err)
 
 	// global values used since no local config file
@@ -539,7 +631,7 @@ func TestLocalConfig_LocalPromptsOverrideGlobal(t *testing.T) {
 	// local prompt overrides task.txt only
 	require.NoError(t, os.WriteFile(filepath.Join(localDir, "prompts", "task.txt"), []byte("local task prompt"), 0o600))
 
-	cfg, err := loadWithLocal(globalDir, localDir)
+	cfg, err := loadWithLocal(globalDir, localDir, "")
 	require.NoError(t, err)
 
 	// local prompt used
@@ -565,7 +657,7 @@ func TestLocalConfig_LocalAgentsReplaceGlobal(t *testing.T) {
 	// local agents (completely different set)
 	require.NoError(t, os.WriteFile(filepath.Join(localDir, "agents", "custom.txt"), []byte("local custom agent"), 0o600))
 
-	cfg, err := loadWithLocal(globalDir, localDir)
+	cfg, err := loadWithLocal(globalDir, localDir, "")
 	require.NoError(t, err)
 
 	// only local agents should be used (replace behavior)
@@ -635,7 +727,7 @@ color_task = #0000ff
 	// local agents: completely different set (replaces global)
 	require.NoError(t, os.WriteFile(filepath.Join(localDir, "agents", "custom.txt"), []byte("local custom agent"), 0o600))
 
-	cfg, err := loadWithLocal(globalDir, localDir)
+	cfg, err := loadWithLocal(globalDir, localDir, "")
 	require.NoError(t, err)
 
 	// --- verify values merge chain: embedded → global → local ---
@@ -699,7 +791,7 @@ color_task = #123456
 	require.NoError(t, os.Symlink(realDir, symlinkDir))
 
 	// load config through symlink
-	cfg, err := loadWithLocal(symlinkDir, "")
+	cfg, err := loadWithLocal(symlinkDir, "", "")
 	require.NoError(t, err)
 
 	// verify values loaded correctly through symlink
@@ -740,6 +832,62 @@ custom_review_script = /path/to/my-review.sh
 	assert.Equal(t, "/path/to/my-review.sh", cfg.CustomReviewScript)
 }
 
+func TestLoad_ConfigAgents(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "ralphex")
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "agents"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "agents", "api.txt"), []byte("file api agent"), 0o600))
+
+	configContent := "claude_command = claude\n[custom_agents]\napi = check handlers follow docs/api.md, report mismatches\n"
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(configContent), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []CustomAgent{{Name: "api", Prompt: "check handlers follow docs/api.md, report mismatches"}}, cfg.ConfigAgents)
+	require.Len(t, cfg.CustomAgents, 1, "config agents are kept apart from agent files")
+	assert.Equal(t, "file api agent", cfg.CustomAgents[0].Prompt)
+}
+
+func TestLoad_ReviewAgents(t *testing.T) {
+	newConfigDir := func(t *testing.T, config string) string {
+		t.Helper()
+		configDir := filepath.Join(t.TempDir(), "ralphex")
+		require.NoError(t, os.MkdirAll(filepath.Join(configDir, "agents"), 0o700))
+		for _, name := range []string{"quality", "testing", "perf"} {
+			require.NoError(t, os.WriteFile(filepath.Join(configDir, "agents", name+".txt"), []byte("check "+name), 0o600))
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(config), 0o600))
+		return configDir
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		cfg, err := Load(newConfigDir(t, ""))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"quality", "implementation", "testing", "simplification", "documentation"}, cfg.ReviewFirstAgents)
+		assert.Equal(t, []string{"quality", "implementation"}, cfg.ReviewSecondAgents)
+	})
+
+	t.Run("custom agent file and config agent", func(t *testing.T) {
+		cfg, err := Load(newConfigDir(t, "review_first_agents = perf, quality,api\nreview_second_agents = testing\n"+
+			"[custom_agents]\napi = check the api\n"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"perf", "quality", "api"}, cfg.ReviewFirstAgents)
+		assert.Equal(t, []string{"testing"}, cfg.ReviewSecondAgents)
+	})
+
+	t.Run("unknown agent lists available ones", func(t *testing.T) {
+		_, err := Load(newConfigDir(t, "review_second_agents = quality, implementation\n[custom_agents]\napi = check the api\n"))
+		require.EqualError(t, err,
+			`invalid review_second_agents: unknown agent "implementation", available: perf, quality, testing, api`)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		_, err := Load(newConfigDir(t, "review_first_agents = quality, {{agent:testing}}\n"))
+		require.ErrorContains(t, err, `invalid review_first_agents: "{{agent:testing}}" is not an agent name`)
+	})
+}
+
 func TestLoad_ExternalReviewToolDefaults(t *testing.T) {
 	tmpDir := t.TempDir()
 	configDir := filepath.Join(tmpDir, "ralphex")
@@ -776,7 +924,7 @@ func TestLocalConfig_LocalOverridesExternalReviewTool(t *testing.T) {
 	localConfig := `external_review_tool = none`
 	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte(localConfig), 0o600))
 
-	cfg, err := loadWithLocal(globalDir, localDir)
+	cfg, err := loadWithLocal(globalDir, localDir, "")
 	require.NoError(t, err)
 
 	assert.Equal(t, "none", cfg.ExternalReviewTool)
@@ -797,6 +945,8 @@ notify_timeout_ms = 15000
 notify_telegram_token = bot123:ABC
 notify_telegram_chat = -100123
 notify_webhook_urls = https://hook.example.com
+notify_webhook_format = json
+notifier = desktop
 `
 	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(configContent), 0o600))
 
@@ -804,12 +954,50 @@ notify_webhook_urls = https://hook.example.com
 	require.NoError(t, err)
 
 	assert.Equal(t, []string{"telegram", "webhook"}, cfg.NotifyParams.Channels)
+	assert.Equal(t, "desktop", cfg.NotifyParams.Notifier)
 	assert.True(t, cfg.NotifyParams.OnError)
 	assert.False(t, cfg.NotifyParams.OnComplete)
 	assert.Equal(t, 15000, cfg.NotifyParams.TimeoutMs)
 	assert.Equal(t, "bot123:ABC", cfg.NotifyParams.TelegramToken)
 	assert.Equal(t, "-100123", cfg.NotifyParams.TelegramChat)
 	assert.Equal(t, []string{"https://hook.example.com"}, cfg.NotifyParams.WebhookURLs)
+	assert.Equal(t, "json", cfg.NotifyParams.WebhookFormat)
+}
+
+func TestLoad_NotifyWebhookURL(t *testing.T) {
+	writeConfig := func(t *testing.T, content string) string {
+		t.Helper()
+		configDir := filepath.Join(t.TempDir(), "ralphex")
+		require.NoError(t, os.MkdirAll(filepath.Join(configDir, "prompts"), 0o700))
+		require.NoError(t, os.MkdirAll(filepath.Join(configDir, "agents"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(content), 0o600))
+		return configDir
+	}
+
+	t.Run("enables json webhook channel", func(t *testing.T) {
+		cfg, err := Load(writeConfig(t, "notify_webhook_url = https://hook.example.com/a\nnotify_on = failure\n"))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"webhook"}, cfg.NotifyParams.Channels)
+		assert.Equal(t, []string{"https://hook.example.com/a"}, cfg.NotifyParams.WebhookURLs)
+		assert.Equal(t, "json", cfg.NotifyParams.WebhookFormat)
+		assert.True(t, cfg.NotifyParams.OnError)
+		assert.False(t, cfg.NotifyParams.OnComplete)
+	})
+
+	t.Run("joins existing channels and keeps explicit format", func(t *testing.T) {
+		cfg, err := Load(writeConfig(t, `
+notify_channels = telegram, webhook
+notify_webhook_urls = https://hook.example.com/a
+notify_webhook_url = https://hook.example.com/b
+notify_webhook_format = text
+`))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"telegram", "webhook"}, cfg.NotifyParams.Channels)
+		assert.Equal(t, []string{"https://hook.example.com/a", "https://hook.example.com/b"}, cfg.NotifyParams.WebhookURLs)
+		assert.Equal(t, "text", cfg.NotifyParams.WebhookFormat)
+	})
 }
 
 func TestLoad_NotifyParamsDefaults(t *testing.T) {
@@ -855,7 +1043,7 @@ notify_timeout_ms = 5000
 `
 	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte(localConfig), 0o600))
 
-	cfg, err := loadWithLocal(globalDir, localDir)
+	cfg, err := loadWithLocal(globalDir, localDir, "")
 	require.NoError(t, err)
 
 	// local overrides channels and timeout
@@ -893,7 +1081,7 @@ claude_command = local-symlinked-claude
 	require.NoError(t, os.Symlink(realLocalDir, symlinkLocalDir))
 
 	// load with symlinked local dir
-	cfg, err := loadWithLocal(globalDir, symlinkLocalDir)
+	cfg, err := loadWithLocal(globalDir, symlinkLocalDir, "")
 	require.NoError(t, err)
 
 	// verify local override works through symlink
@@ -905,3 +1093,24 @@ claude_command = local-symlinked-claude
 	// verify localDir is the symlink path
 	assert.Equal(t, symlinkLocalDir, cfg.LocalDir())
 }
+
+func TestConfig_TokenPrices(t *testing.T) {
+	tests := []struct {
+		name            string
+		cfg             Config
+		wantIn, wantOut float64
+	}{
+		{name: "not set", cfg: Config{}},
+		{name: "per million", cfg: Config{PriceInput: 3, PriceOutput: 15}, wantIn: 3, wantOut: 15},
+		{name: "per 1k converted", cfg: Config{CostPer1kInput: 0.003, CostPer1kOutput: 0.015}, wantIn: 3, wantOut: 15},
+		{name: "per million wins", cfg: Config{PriceInput: 5, CostPer1kInput: 0.003, CostPer1kOutput: 0.015},
+			wantIn: 5, wantOut: 15},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			in, out := tc.cfg.TokenPrices()
+			assert.InDelta(t, tc.wantIn, in, 1e-9)
+			assert.InDelta(t, tc.wantOut, out, 1e-9)
+		})
+	}
+}
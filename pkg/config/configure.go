@@ -0,0 +1,406 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/input"
+)
+
+// ErrConfigureAborted is returned by Configure when input ends or is interrupted before the changes are written.
+var ErrConfigureAborted = errors.New("aborted, config not changed")
+
+// sectionRuleRe matches the dashed rule around section titles of the embedded defaults
+var sectionRuleRe = regexp.MustCompile(`^#\s*-{10,}\s*$`)
+
+// keyDescRe matches the "# key: description" comment before a key in the embedded defaults
+var keyDescRe = regexp.MustCompile(`^#\s*([a-z][a-z0-9_]*):\s*(.*)$`)
+
+// keyOptions lists the accepted values of keys with a fixed set of values, shown by the editor
+var keyOptions = map[string][]string{
+	"agent_backend":         agentBackends,
+	"claude_output_format":  claudeOutputFormats,
+	"external_review_tool":  externalReviewTools,
+	"plan_change_action":    planChangeActions,
+	"codex_min_severity":    codexSeverities,
+	"notify_webhook_format": webhookFormats,
+	"notifier":              notifiers,
+}
+
+// keySection is a group of config keys under one section title of the embedded defaults.
+type keySection struct {
+	title string
+	keys  []string
+}
+
+// keyInfo holds what the editor shows about a key: its embedded default and the first line of its description.
+type keyInfo struct {
+	def  string
+	desc string
+}
+
+// ConfigureResult holds the keys changed by Configure or SetValues, with their new values.
+type ConfigureResult struct {
+	Path    string            // path of the written config file
+	Changed map[string]string // new values of changed keys, empty if nothing was written
+}
+
+// Configure interactively edits the global config file one key at a time.
+// keys are grouped by the sections of the embedded defaults, each section is entered on confirmation.
+// a key keeps its value on empty input, "-" restores the default, other input is validated like --check-config does.
+// the file is written only after confirmation, keeping untouched lines as they are; keys set to their default
+// are commented out. EOF or context cancellation at any prompt returns ErrConfigureAborted without writing.
+// if configDir is empty, uses DefaultConfigDir().
+func Configure(ctx context.Context, configDir string, stdin io.Reader, stdout io.Writer) (ConfigureResult, error) {
+	path := globalConfigFile(configDir)
+	content, err := readConfigForEdit(path)
+	if err != nil {
+		return ConfigureResult{Path: path}, err
+	}
+	sections, infos, err := defaultKeySections()
+	if err != nil {
+		return ConfigureResult{Path: path}, err
+	}
+
+	fmt.Fprintf(stdout, "Configure %s\n", path)
+	fmt.Fprintf(stdout, "empty input keeps the value, - restores the default\n")
+	reader := bufio.NewReader(stdin)
+	ask := func(prompt string) (string, error) {
+		fmt.Fprint(stdout, prompt)
+		line, err := input.ReadLineWithContext(ctx, reader)
+		if err != nil && (line == "" || !errors.Is(err, io.EOF)) {
+			fmt.Fprintln(stdout)
+			return "", ErrConfigureAborted
+		}
+		return strings.TrimSpace(line), nil
+	}
+
+	vl, cl := newValuesLoader(defaultsFS), newColorLoader(defaultsFS)
+	changed := map[string]string{}
+	for _, sec := range sections {
+		answer, err := ask(fmt.Sprintf("\n%s (%d keys), edit? [y/N]: ", sec.title, len(sec.keys)))
+		if err != nil {
+			return ConfigureResult{Path: path}, err
+		}
+		if answer = strings.ToLower(answer); answer != "y" && answer != "yes" {
+			continue
+		}
+		for _, key := range sec.keys {
+			info := infos[key]
+			current, set := fileValue(content, key)
+			if !set {
+				current = info.def
+			}
+			if info.desc != "" {
+				fmt.Fprintf(stdout, "  # %s\n", info.desc)
+			}
+			for {
+				value, err := ask("  " + keyPrompt(key, current, info.def))
+				if err != nil {
+					return ConfigureResult{Path: path}, err
+				}
+				switch value {
+				case "":
+					value = current
+				case "-":
+					value = info.def
+				}
+				if msg := validateValue(vl, cl, key, value); msg != "" {
+					fmt.Fprintf(stdout, "  %s\n", msg)
+					continue
+				}
+				if value != current {
+					changed[key] = value
+				}
+				break
+			}
+		}
+	}
+
+	if len(changed) == 0 {
+		fmt.Fprintf(stdout, "\nNo changes.\n")
+		return ConfigureResult{Path: path}, nil
+	}
+	fmt.Fprintf(stdout, "\nChanges:\n")
+	for _, key := range knownKeys {
+		if value, ok := changed[key]; ok {
+			fmt.Fprintf(stdout, "  %s = %s\n", key, displayValue(key, value))
+		}
+	}
+	answer, err := ask(fmt.Sprintf("Write %d change(s) to %s? [y/N]: ", len(changed), path))
+	if err != nil {
+		return ConfigureResult{Path: path}, err
+	}
+	if answer = strings.ToLower(answer); answer != "y" && answer != "yes" {
+		return ConfigureResult{Path: path}, ErrConfigureAborted
+	}
+	if err := writeConfigEdit(path, content, changed, infos); err != nil {
+		return ConfigureResult{Path: path}, err
+	}
+	fmt.Fprintf(stdout, "Done.\n")
+	return ConfigureResult{Path: path, Changed: changed}, nil
+}
+
+// SetValues sets config keys of the global config file from key=value assignments, for scripting.
+// every assignment is validated before anything is written, unknown keys get a suggestion.
+// keys set to their default are commented out, other lines of the file are kept as they are.
+// if configDir is empty, uses DefaultConfigDir().
+func SetValues(configDir string, assignments []string) (ConfigureResult, error) {
+	path := globalConfigFile(configDir)
+	_, infos, err := defaultKeySections()
+	if err != nil {
+		return ConfigureResult{Path: path}, err
+	}
+
+	vl, cl := newValuesLoader(defaultsFS), newColorLoader(defaultsFS)
+	changed := map[string]string{}
+	for _, a := range assignments {
+		key, value, ok := strings.Cut(a, "=")
+		if !ok {
+			return ConfigureResult{Path: path}, fmt.Errorf("invalid assignment %q, expected key=value", a)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !slices.Contains(knownKeys, key) {
+			msg := fmt.Sprintf("unknown key %q", key)
+			if s := suggest(key, knownKeys); s != "" {
+				msg += fmt.Sprintf(", did you mean %q?", s)
+			}
+			return ConfigureResult{Path: path}, errors.New(msg)
+		}
+		if msg := validateValue(vl, cl, key, value); msg != "" {
+			return ConfigureResult{Path: path}, errors.New(msg)
+		}
+		changed[key] = value
+	}
+
+	content, err := readConfigForEdit(path)
+	if err != nil {
+		return ConfigureResult{Path: path}, err
+	}
+	if err := writeConfigEdit(path, content, changed, infos); err != nil {
+		return ConfigureResult{Path: path}, err
+	}
+	return ConfigureResult{Path: path, Changed: changed}, nil
+}
+
+// globalConfigFile returns the path of the config file in configDir, DefaultConfigDir() if empty.
+func globalConfigFile(configDir string) string {
+	if configDir == "" {
+		configDir = DefaultConfigDir()
+	}
+	return filepath.Join(configDir, "config")
+}
+
+// readConfigForEdit returns the content of the config file to edit.
+// a missing file starts from the commented-out embedded defaults, like the installed config.
+func readConfigForEdit(path string) (string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // user's config file
+	if err == nil {
+		return strings.ReplaceAll(string(data), "\r\n", "\n"), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("read config %s: %w", path, err)
+	}
+	data, err = defaultsFS.ReadFile("defaults/config")
+	if err != nil {
+		return "", fmt.Errorf("read embedded config: %w", err)
+	}
+	return commentOutContent(string(data)), nil
+}
+
+// writeConfigEdit applies the changed values to the config content and writes it to path.
+func writeConfigEdit(path, content string, changed map[string]string, infos map[string]keyInfo) error {
+	defaults := make(map[string]string, len(changed))
+	for key := range changed {
+		defaults[key] = infos[key].def
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create config dir: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(applyConfigValues(content, changed, defaults)), 0o600); err != nil {
+		return fmt.Errorf("write config %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyConfigValues returns the config content with the given key values, other lines unchanged.
+// a value equal to the key's default is written commented out, so the file keeps only keys that differ.
+// each key replaces its last active line, or its first commented-out line if it isn't set; other active
+// lines of the key are commented out. keys not mentioned at all are added before the first section.
+func applyConfigValues(content string, values, defaults map[string]string) string {
+	lines := strings.Split(content, "\n")
+	end := len(lines) // top-level keys end at the first section header
+	for i, line := range lines {
+		if sectionHeaderRe.MatchString(strings.TrimSpace(line)) {
+			end = i
+			break
+		}
+	}
+
+	active, commented := map[string][]int{}, map[string]int{}
+	for i, line := range lines[:end] {
+		m := configKeyRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if _, ok := values[m[1]]; !ok {
+			continue
+		}
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			active[m[1]] = append(active[m[1]], i)
+			continue
+		}
+		if _, ok := commented[m[1]]; !ok {
+			commented[m[1]] = i
+		}
+	}
+
+	render := func(key string) string {
+		if values[key] == defaults[key] {
+			return fmt.Sprintf("# %s = %s", key, values[key])
+		}
+		return fmt.Sprintf("%s = %s", key, values[key])
+	}
+	var added []string
+	for _, key := range knownKeys {
+		if _, ok := values[key]; !ok {
+			continue
+		}
+		if idx := active[key]; len(idx) > 0 {
+			for _, i := range idx[:len(idx)-1] {
+				lines[i] = "# " + lines[i]
+			}
+			lines[idx[len(idx)-1]] = render(key)
+			continue
+		}
+		if i, ok := commented[key]; ok {
+			lines[i] = render(key)
+			continue
+		}
+		if values[key] != defaults[key] {
+			added = append(added, render(key))
+		}
+	}
+	if len(added) == 0 {
+		return strings.Join(lines, "\n")
+	}
+
+	if end == len(lines) {
+		// keep the trailing newline of the file after the added keys
+		for end > 0 && lines[end-1] == "" {
+			end--
+		}
+		added = append([]string{""}, added...)
+	} else {
+		added = append(added, "")
+	}
+	res := slices.Concat(lines[:end], added, lines[end:])
+	return strings.Join(res, "\n")
+}
+
+// fileValue returns the value of a top-level key set in the config content, false if the key isn't set.
+func fileValue(content, key string) (string, bool) {
+	for _, line := range slices.Backward(strings.Split(content, "\n")) {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+			continue
+		}
+		if m := configKeyRe.FindStringSubmatch(trimmed); m != nil && m[1] == key {
+			return strings.TrimSpace(m[2]), true
+		}
+	}
+	return "", false
+}
+
+// defaultKeySections groups known keys by the section titles of the embedded defaults, with their default
+// values and descriptions. keys the defaults don't mention go to a trailing "other" section.
+func defaultKeySections() ([]keySection, map[string]keyInfo, error) {
+	data, err := defaultsFS.ReadFile("defaults/config")
+	if err != nil {
+		return nil, nil, fmt.Errorf("read embedded config: %w", err)
+	}
+
+	var sections []keySection
+	infos := map[string]keyInfo{}
+	descs := map[string]string{}
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if sectionRuleRe.MatchString(line) && i+2 < len(lines) && sectionRuleRe.MatchString(strings.TrimSpace(lines[i+2])) {
+			sections = append(sections, keySection{title: strings.TrimSpace(strings.TrimPrefix(lines[i+1], "#"))})
+			i += 2
+			continue
+		}
+		if m := keyDescRe.FindStringSubmatch(line); m != nil {
+			if _, ok := descs[m[1]]; !ok {
+				descs[m[1]] = m[2]
+			}
+			continue
+		}
+		m := configKeyRe.FindStringSubmatch(line)
+		if m == nil || !slices.Contains(knownKeys, m[1]) || m[1] == configVersionKey {
+			continue
+		}
+		if _, seen := infos[m[1]]; seen {
+			continue
+		}
+		if len(sections) == 0 {
+			sections = append(sections, keySection{title: "general"})
+		}
+		sections[len(sections)-1].keys = append(sections[len(sections)-1].keys, m[1])
+		infos[m[1]] = keyInfo{def: iniValue(data, m[1])}
+	}
+
+	other := keySection{title: "other"}
+	for _, key := range knownKeys {
+		if _, ok := infos[key]; !ok && key != configVersionKey {
+			other.keys = append(other.keys, key)
+			infos[key] = keyInfo{}
+		}
+	}
+	if len(other.keys) > 0 {
+		sections = append(sections, other)
+	}
+	sections = slices.DeleteFunc(sections, func(s keySection) bool { return len(s.keys) == 0 })
+	for key, info := range infos {
+		info.desc = descs[key]
+		infos[key] = info
+	}
+	infos[configVersionKey] = keyInfo{def: iniValue(data, configVersionKey)}
+	return sections, infos, nil
+}
+
+// keyPrompt renders the editor prompt of a key with its current value, the default and accepted values.
+func keyPrompt(key, current, def string) string {
+	var hints []string
+	if def != current {
+		hints = append(hints, "default "+displayValue(key, def))
+	}
+	if opts, ok := keyOptions[key]; ok {
+		hints = append(hints, "options: "+strings.Join(opts, ", "))
+	}
+	if key == "theme" {
+		hints = append(hints, "options: "+strings.Join(ThemeNames(), ", "))
+	}
+	prompt := fmt.Sprintf("%s [%s]", key, displayValue(key, current))
+	if len(hints) > 0 {
+		prompt += " (" + strings.Join(hints, "; ") + ")"
+	}
+	return prompt + ": "
+}
+
+// displayValue masks the values of secret keys.
+func displayValue(key, value string) string {
+	if value != "" && isSecretKey(key) {
+		return "*****"
+	}
+	return value
+}
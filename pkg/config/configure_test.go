@@ -0,0 +1,208 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyConfigValues(t *testing.T) {
+	defaults := map[string]string{"codex_enabled": "true", "plans_dir": "docs/plans", "iteration_delay_ms": "2000"}
+
+	tests := []struct {
+		name    string
+		content string
+		values  map[string]string
+		want    string
+	}{
+		{name: "replaces commented-out default",
+			content: "# header\n\n# codex: enable codex\n# codex_enabled = true\n",
+			values:  map[string]string{"codex_enabled": "false"},
+			want:    "# header\n\n# codex: enable codex\ncodex_enabled = false\n"},
+		{name: "replaces active value",
+			content: "plans_dir = old\n# keep me\n",
+			values:  map[string]string{"plans_dir": "new"},
+			want:    "plans_dir = new\n# keep me\n"},
+		{name: "default value is commented out",
+			content: "codex_enabled = false\n",
+			values:  map[string]string{"codex_enabled": "true"},
+			want:    "# codex_enabled = true\n"},
+		{name: "duplicate active lines",
+			content: "plans_dir = a\nplans_dir = b\n",
+			values:  map[string]string{"plans_dir": "c"},
+			want:    "# plans_dir = a\nplans_dir = c\n"},
+		{name: "missing key is appended",
+			content: "plans_dir = a\n",
+			values:  map[string]string{"iteration_delay_ms": "100"},
+			want:    "plans_dir = a\n\niteration_delay_ms = 100\n"},
+		{name: "missing key at default is not added",
+			content: "plans_dir = a\n",
+			values:  map[string]string{"iteration_delay_ms": "2000"},
+			want:    "plans_dir = a\n"},
+		{name: "missing key goes before sections",
+			content: "plans_dir = a\n\n[custom_agents]\nsecurity = check\n",
+			values:  map[string]string{"codex_enabled": "false"},
+			want:    "plans_dir = a\n\ncodex_enabled = false\n\n[custom_agents]\nsecurity = check\n"},
+		{name: "keys in sections are not touched",
+			content: "[custom_agents]\n# plans_dir = x\n",
+			values:  map[string]string{"plans_dir": "y"},
+			want:    "plans_dir = y\n\n[custom_agents]\n# plans_dir = x\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, applyConfigValues(tc.content, tc.values, defaults))
+		})
+	}
+}
+
+func TestDefaultKeySections(t *testing.T) {
+	sections, infos, err := defaultKeySections()
+	require.NoError(t, err)
+	require.NotEmpty(t, sections)
+	assert.Equal(t, "claude executor", sections[0].title)
+	assert.Equal(t, "agent_backend", sections[0].keys[0])
+
+	var keys []string
+	for _, s := range sections {
+		assert.NotEmpty(t, s.keys, "section %s", s.title)
+		keys = append(keys, s.keys...)
+	}
+	assert.ElementsMatch(t, knownKeys[1:], keys, "every known key but config_version is editable once")
+
+	assert.Equal(t, "claude", infos["agent_backend"].def)
+	assert.Equal(t, "the primary agent running tasks, reviews and plan creation", infos["agent_backend"].desc)
+	assert.Empty(t, infos["claude_command_wrapper"].def, "commented-out examples are not defaults")
+	assert.Equal(t, "4", infos["config_version"].def)
+}
+
+func TestSetValues(t *testing.T) {
+	t.Run("creates config from defaults", func(t *testing.T) {
+		dir := t.TempDir()
+		res, err := SetValues(dir, []string{"codex_enabled=false", "iteration_delay_ms = 100"})
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "config"), res.Path)
+		assert.Equal(t, map[string]string{"codex_enabled": "false", "iteration_delay_ms": "100"}, res.Changed)
+
+		data, err := os.ReadFile(res.Path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "\ncodex_enabled = false\n")
+		assert.Contains(t, string(data), "\niteration_delay_ms = 100\n")
+		assert.Contains(t, string(data), "# claude_command = claude\n", "other defaults stay commented out")
+
+		cfg, err := loadConfigFromDirs(dir, "", "")
+		require.NoError(t, err)
+		assert.False(t, cfg.CodexEnabled)
+		assert.Equal(t, 100, cfg.IterationDelayMs)
+		assert.Empty(t, cfg.UpgradeNotice())
+	})
+
+	t.Run("keeps other lines", func(t *testing.T) {
+		dir := t.TempDir()
+		content := "# my notes\nplans_dir = my-plans\ncodex_enabled = false\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config"), []byte(content), 0o600))
+
+		_, err := SetValues(dir, []string{"codex_enabled=true"})
+		require.NoError(t, err)
+		data, err := os.ReadFile(filepath.Join(dir, "config"))
+		require.NoError(t, err)
+		assert.Equal(t, "# my notes\nplans_dir = my-plans\n# codex_enabled = true\n", string(data))
+	})
+
+	t.Run("invalid input writes nothing", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			assignments []string
+			wantErr     string
+		}{
+			{name: "unknown key", assignments: []string{"codex_enabld=false"}, wantErr: `unknown key "codex_enabld", did you mean "codex_enabled"?`},
+			{name: "bad enum", assignments: []string{"codex_enabled=false", "external_review_tool=foo"},
+				wantErr: "invalid external_review_tool"},
+			{name: "bad number", assignments: []string{"iteration_delay_ms=soon"}, wantErr: "iteration_delay_ms"},
+			{name: "no value", assignments: []string{"codex_enabled"}, wantErr: "expected key=value"},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				dir := t.TempDir()
+				_, err := SetValues(dir, tc.assignments)
+				require.ErrorContains(t, err, tc.wantErr)
+				assert.NoFileExists(t, filepath.Join(dir, "config"))
+			})
+		}
+	})
+}
+
+func TestConfigure(t *testing.T) {
+	sections, _, err := defaultKeySections()
+	require.NoError(t, err)
+
+	// answers skipping every section but the first one (claude executor)
+	answers := func(first ...string) string {
+		lines := append([]string{"y"}, first...)
+		for range sections[1:] {
+			lines = append(lines, "")
+		}
+		return strings.Join(lines, "\n") + "\n"
+	}
+	keep := func(n int) []string { return make([]string, n) }
+
+	t.Run("edits and writes changed keys", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config"), []byte("# notes\nclaude_command = my-claude\n"), 0o600))
+
+		// agent_backend invalid then gemini, claude_command back to default, the rest kept
+		first := append([]string{"robot", "gemini", "-"}, keep(len(sections[0].keys)-2)...)
+		in := answers(first...) + "y\n"
+		var out bytes.Buffer
+		res, err := Configure(context.Background(), dir, strings.NewReader(in), &out)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"agent_backend": "gemini", "claude_command": "claude"}, res.Changed)
+		assert.Contains(t, out.String(), `invalid agent_backend: "robot"`)
+		assert.Contains(t, out.String(), "agent_backend [claude] (options: claude, gemini, ollama): ")
+		assert.Contains(t, out.String(), "claude_command [my-claude] (default claude): ")
+		assert.Contains(t, out.String(), "# the primary agent running tasks, reviews and plan creation")
+
+		data, err := os.ReadFile(filepath.Join(dir, "config"))
+		require.NoError(t, err)
+		assert.Equal(t, "# notes\n# claude_command = claude\n\nagent_backend = gemini\n", string(data))
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		dir := t.TempDir()
+		var out bytes.Buffer
+		res, err := Configure(context.Background(), dir, strings.NewReader(answers(keep(len(sections[0].keys))...)), &out)
+		require.NoError(t, err)
+		assert.Empty(t, res.Changed)
+		assert.Contains(t, out.String(), "No changes.")
+		assert.NoFileExists(t, filepath.Join(dir, "config"))
+	})
+
+	t.Run("declined write", func(t *testing.T) {
+		dir := t.TempDir()
+		first := append([]string{"gemini"}, keep(len(sections[0].keys)-1)...)
+		_, err := Configure(context.Background(), dir, strings.NewReader(answers(first...)+"n\n"), &bytes.Buffer{})
+		require.ErrorIs(t, err, ErrConfigureAborted)
+		assert.NoFileExists(t, filepath.Join(dir, "config"))
+	})
+
+	t.Run("eof aborts", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := Configure(context.Background(), dir, strings.NewReader("y\ngemini\n"), &bytes.Buffer{})
+		require.ErrorIs(t, err, ErrConfigureAborted)
+		assert.NoFileExists(t, filepath.Join(dir, "config"))
+	})
+
+	t.Run("canceled context aborts", func(t *testing.T) {
+		dir := t.TempDir()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := Configure(ctx, dir, strings.NewReader("y\n"), &bytes.Buffer{})
+		require.ErrorIs(t, err, ErrConfigureAborted)
+		assert.NoFileExists(t, filepath.Join(dir, "config"))
+	})
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// ProjectContextVar is the prompt variable replaced with the content of the project context file.
+const ProjectContextVar = "{{PROJECT_CONTEXT}}"
+
+// defaultContextMaxBytes caps the project context when context_max_bytes is not set.
+const defaultContextMaxBytes = 16 * 1024
+
+// contextFileName is the project context file in the repo-local .ralphex/, used when context_file is not set.
+const contextFileName = "context.md"
+
+// ProjectContext is the project context file injected into prompts as {{PROJECT_CONTEXT}}.
+type ProjectContext struct {
+	File    string // path of the context file, empty if there is none
+	Content string // file content, cut to context_max_bytes
+	Size    int    // size of the whole file in bytes, larger than len(Content) when truncated
+}
+
+// Truncated reports whether the content was cut to context_max_bytes.
+func (p ProjectContext) Truncated() bool {
+	return p.Size > len(p.Content)
+}
+
+// loadProjectContext reads the project context referenced by prompts as {{PROJECT_CONTEXT}}.
+// file is context_file, relative paths are resolved from the project root (current directory).
+// without it, context.md of the repo-local localDir is used if it exists, no context otherwise.
+// an explicitly set file has no fallback: if any of the prompts references {{PROJECT_CONTEXT}}
+// and the file doesn't exist, loading fails. content over maxBytes is truncated.
+func loadProjectContext(file, localDir string, maxBytes int, prompts ...string) (ProjectContext, error) {
+	if file == "" {
+		if localDir == "" {
+			return ProjectContext{}, nil
+		}
+		fallback := filepath.Join(localDir, contextFileName)
+		if _, err := os.Stat(fallback); err != nil {
+			return ProjectContext{}, nil //nolint:nilerr // the fallback file is optional
+		}
+		file = fallback
+	}
+
+	data, err := os.ReadFile(file) //nolint:gosec // path from user config
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !referencesProjectContext(prompts) {
+			return ProjectContext{}, nil // nothing uses the context, a missing file doesn't matter
+		}
+		return ProjectContext{}, fmt.Errorf("read context_file %s, prompts use %s: %w", file, ProjectContextVar, err)
+	}
+
+	content := strings.TrimSpace(string(data))
+	return ProjectContext{File: file, Content: truncateContext(content, maxBytes), Size: len(content)}, nil
+}
+
+// referencesProjectContext reports whether any of the prompts uses {{PROJECT_CONTEXT}}.
+func referencesProjectContext(prompts []string) bool {
+	for _, p := range prompts {
+		if strings.Contains(p, ProjectContextVar) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateContext cuts content to at most maxBytes, without splitting a UTF-8 character.
+func truncateContext(content string, maxBytes int) string {
+	if len(content) <= maxBytes {
+		return content
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return content[:cut]
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadProjectContext(t *testing.T) {
+	usesContext := "conventions: " + ProjectContextVar
+
+	t.Run("configured file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "CLAUDE.md")
+		require.NoError(t, os.WriteFile(file, []byte("never touch migrations\n"), 0o600))
+
+		pc, err := loadProjectContext(file, "", 100, usesContext)
+		require.NoError(t, err)
+		assert.Equal(t, ProjectContext{File: file, Content: "never touch migrations", Size: 22}, pc)
+		assert.False(t, pc.Truncated())
+	})
+
+	t.Run("repo-local context.md fallback", func(t *testing.T) {
+		localDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(localDir, "context.md"), []byte("use make test"), 0o600))
+
+		pc, err := loadProjectContext("", localDir, 100, usesContext)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(localDir, "context.md"), pc.File)
+		assert.Equal(t, "use make test", pc.Content)
+	})
+
+	t.Run("no file and no fallback", func(t *testing.T) {
+		pc, err := loadProjectContext("", t.TempDir(), 100, usesContext)
+		require.NoError(t, err)
+		assert.Equal(t, ProjectContext{}, pc)
+	})
+
+	t.Run("missing configured file referenced by a prompt", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "CLAUDE.md")
+		_, err := loadProjectContext(file, "", 100, "plain prompt", usesContext)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "read context_file "+file+", prompts use {{PROJECT_CONTEXT}}")
+	})
+
+	t.Run("missing configured file not referenced", func(t *testing.T) {
+		pc, err := loadProjectContext(filepath.Join(t.TempDir(), "CLAUDE.md"), "", 100, "plain prompt")
+		require.NoError(t, err)
+		assert.Equal(t, ProjectContext{}, pc)
+	})
+}
+
+func TestLoadProjectContext_Truncation(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+		maxBytes            int
+	}{
+		{name: "at the limit", content: "abcde", maxBytes: 5, want: "abcde"},
+		{name: "one byte over", content: "abcdef", maxBytes: 5, want: "abcde"},
+		{name: "multi-byte character kept whole", content: "abcdé", maxBytes: 5, want: "abcd"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "context.md")
+			require.NoError(t, os.WriteFile(file, []byte(tc.content), 0o600))
+
+			pc, err := loadProjectContext(file, "", tc.maxBytes)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, pc.Content)
+			assert.Equal(t, len(tc.content), pc.Size)
+			assert.Equal(t, tc.want != tc.content, pc.Truncated())
+		})
+	}
+}
+
+func TestLoad_ProjectContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalDir := filepath.Join(tmpDir, "global")
+	require.NoError(t, os.MkdirAll(globalDir, 0o700))
+	contextFile := filepath.Join(tmpDir, "CLAUDE.md")
+	require.NoError(t, os.WriteFile(contextFile, []byte(strings.Repeat("x", 20)), 0o600))
+
+	t.Run("loaded and capped", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"),
+			[]byte("context_file = "+contextFile+"\ncontext_max_bytes = 10\n"), 0o600))
+
+		cfg, err := loadConfigFromDirs(globalDir, "", "")
+		require.NoError(t, err)
+		assert.Equal(t, contextFile, cfg.ContextFile)
+		assert.Equal(t, strings.Repeat("x", 10), cfg.ProjectContext.Content)
+		assert.True(t, cfg.ProjectContext.Truncated())
+	})
+
+	t.Run("missing file fails, embedded prompts use the context", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"),
+			[]byte("context_file = "+filepath.Join(tmpDir, "missing.md")+"\n"), 0o600))
+
+		_, err := loadConfigFromDirs(globalDir, "", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "load project context")
+	})
+
+	t.Run("invalid max bytes", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"), []byte("context_max_bytes = 0\n"), 0o600))
+
+		_, err := loadConfigFromDirs(globalDir, "", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid context_max_bytes: must be at least 1")
+	})
+}
@@ -30,6 +30,36 @@ func commentOutContent(content string) string {
 	return strings.Join(lines, "\n")
 }
 
+// templatesEmbedPath is the embedded directory of plan templates.
+const templatesEmbedPath = "defaults/templates"
+
+// defaultFileExt returns the extension of the default files in an embedded directory.
+// plan templates are markdown, prompts and agents are .txt.
+func defaultFileExt(embedPath string) string {
+	if embedPath == templatesEmbedPath {
+		return ".md"
+	}
+	return ".txt"
+}
+
+// defaultFileContent returns the content installed for an embedded default file.
+// plan templates are installed as-is because "#" starts a markdown heading, other files are commented out.
+func defaultFileContent(embedPath string, data []byte) []byte {
+	if embedPath == templatesEmbedPath {
+		return data
+	}
+	return []byte(commentOutContent(string(data)))
+}
+
+// isBlankDefault reports whether a file from an embedded directory has no actual content.
+// for prompts and agents comment lines don't count, for plan templates only whitespace does.
+func isBlankDefault(embedPath string, data []byte) bool {
+	if embedPath == templatesEmbedPath {
+		return strings.TrimSpace(string(data)) == ""
+	}
+	return strings.TrimSpace(stripComments(string(data))) == ""
+}
+
 // shouldOverwrite checks if a file is safe to overwrite with new defaults.
 // returns true if file doesn't exist, is empty, or contains only comments/whitespace.
 // returns false if file exists but can't be read (preserve unknown content).
@@ -47,6 +77,18 @@ func shouldOverwrite(filePath string) bool {
 	return strings.TrimSpace(stripped) == ""
 }
 
+// shouldOverwriteDefault is shouldOverwrite for a file installed from the embedded directory embedPath.
+func shouldOverwriteDefault(filePath, embedPath string) bool {
+	if embedPath != templatesEmbedPath {
+		return shouldOverwrite(filePath)
+	}
+	data, err := os.ReadFile(filePath) //nolint:gosec // user's template file
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	return isBlankDefault(embedPath, data)
+}
+
 // defaultsInstaller implements DefaultsInstaller with embedded filesystem.
 type defaultsInstaller struct {
 	embedFS embed.FS
@@ -60,8 +102,8 @@ func newDefaultsInstaller(embedFS embed.FS) *defaultsInstaller {
 // Install creates the config directory and installs default config files if they don't exist.
 // this is called on first run to set up the configuration.
 // the config file is always created if missing.
-// prompts and agents are only installed when their respective directories have no .txt files -
-// this allows users to manage the full set of prompts/agents without interference.
+// prompts, agents and plan templates are only installed when their respective directories have no files
+// with content - this allows users to manage the full set of prompts/agents/templates without interference.
 func (d *defaultsInstaller) Install(configDir string) error {
 	// create config directory (0700 - user only)
 	if err := os.MkdirAll(configDir, 0o700); err != nil {
@@ -80,6 +122,12 @@ func (d *defaultsInstaller) Install(configDir string) error {
 		return fmt.Errorf("create agents dir: %w", err)
 	}
 
+	// create plan templates subdirectory
+	templatesDir := filepath.Join(configDir, "templates")
+	if err := os.MkdirAll(templatesDir, 0o700); err != nil {
+		return fmt.Errorf("create templates dir: %w", err)
+	}
+
 	// install default config file if not exists or is safe to overwrite (all-commented/empty)
 	configPath := filepath.Join(configDir, "config")
 	if shouldOverwrite(configPath) {
@@ -104,25 +152,31 @@ func (d *defaultsInstaller) Install(configDir string) error {
 		return fmt.Errorf("install default agents: %w", err)
 	}
 
+	// install default plan templates if directory is empty
+	if err := d.installDefaultFiles(templatesDir, templatesEmbedPath, "template"); err != nil {
+		return fmt.Errorf("install default templates: %w", err)
+	}
+
 	return nil
 }
 
-// installDefaultFiles copies embedded .txt files to the destination directory.
-// files are only installed if the directory has no .txt files with actual content.
+// installDefaultFiles copies embedded .txt files (.md for plan templates) to the destination directory.
+// files are only installed if the directory has no such files with actual content.
 // files with only comments/whitespace are considered safe to overwrite.
 // note: this is directory-level logic - if ANY file has content, no defaults are added.
 // this allows users to manage their own set of prompts/agents without interference.
 func (d *defaultsInstaller) installDefaultFiles(destDir, embedPath, fileType string) error {
-	// check if directory has any .txt files with actual content - if so, skip installation entirely
+	ext := defaultFileExt(embedPath)
+	// check if directory has any default-type files with actual content - if so, skip installation entirely
 	existingEntries, err := os.ReadDir(destDir)
 	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("read %s dir: %w", fileType, err)
 	}
 	for _, entry := range existingEntries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".txt") {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ext) {
 			// check if this file has actual content (not just comments/empty)
 			filePath := filepath.Join(destDir, entry.Name())
-			if !shouldOverwrite(filePath) {
+			if !shouldOverwriteDefault(filePath, embedPath) {
 				return nil // directory has file with content, don't install defaults
 			}
 		}
@@ -134,13 +188,13 @@ func (d *defaultsInstaller) installDefaultFiles(destDir, embedPath, fileType str
 	}
 
 	for _, entry := range defaultEntries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ext) {
 			continue
 		}
 
 		destPath := filepath.Join(destDir, entry.Name())
 		// only write if file doesn't exist or is safe to overwrite (all-commented/empty)
-		if !shouldOverwrite(destPath) {
+		if !shouldOverwriteDefault(destPath, embedPath) {
 			continue
 		}
 
@@ -149,9 +203,8 @@ func (d *defaultsInstaller) installDefaultFiles(destDir, embedPath, fileType str
 			return fmt.Errorf("read embedded %s %s: %w", fileType, entry.Name(), err)
 		}
 
-		// write with content commented out - users uncomment what they customize
-		commented := commentOutContent(string(data))
-		if err := os.WriteFile(destPath, []byte(commented), 0o600); err != nil {
+		// write with content commented out (templates as-is) - users uncomment what they customize
+		if err := os.WriteFile(destPath, defaultFileContent(embedPath, data), 0o600); err != nil {
 			return fmt.Errorf("write %s file %s: %w", fileType, entry.Name(), err)
 		}
 	}
@@ -161,13 +214,14 @@ func (d *defaultsInstaller) installDefaultFiles(destDir, embedPath, fileType str
 
 // ResetResult holds the result of the reset operation.
 type ResetResult struct {
-	ConfigReset  bool
-	PromptsReset bool
-	AgentsReset  bool
+	ConfigReset    bool
+	PromptsReset   bool
+	AgentsReset    bool
+	TemplatesReset bool
 }
 
 // Reset interactively resets global configuration to embedded defaults.
-// prompts user for each component (config, prompts, agents) before resetting.
+// prompts user for each component (config, prompts, agents, templates) before resetting.
 // local .ralphex/ is not affected.
 func (d *defaultsInstaller) Reset(configDir string, stdin io.Reader, stdout io.Writer) (ResetResult, error) {
 	result := ResetResult{}
@@ -199,6 +253,14 @@ func (d *defaultsInstaller) Reset(configDir string, stdin io.Reader, stdout io.W
 	}
 	result.AgentsReset = agentsReset
 
+	// reset plan templates directory
+	templatesDir := filepath.Join(configDir, "templates")
+	templatesReset, err := d.resetTemplatesDir(templatesDir, scanner, stdout)
+	if err != nil {
+		return result, fmt.Errorf("reset templates: %w", err)
+	}
+	result.TemplatesReset = templatesReset
+
 	// print summary
 	d.printResetSummary(result, stdout)
 
@@ -380,6 +442,65 @@ func (d *defaultsInstaller) resetAgentsDir(agentsDir string, scanner *bufio.Scan
 	return true, nil
 }
 
+// resetTemplatesDir handles interactive reset of the plan templates directory.
+// like agents, only the default templates are overwritten, custom templates are preserved.
+func (d *defaultsInstaller) resetTemplatesDir(templatesDir string, scanner *bufio.Scanner, stdout io.Writer) (bool, error) {
+	fmt.Fprintf(stdout, "\nTemplates directory?\n")
+
+	// find files that differ from embedded defaults
+	differentFiles, err := d.findDifferentFiles(templatesDir, templatesEmbedPath)
+	if err != nil {
+		return false, fmt.Errorf("compare templates: %w", err)
+	}
+
+	// find custom files (not in embedded defaults)
+	customFiles, err := d.findCustomFiles(templatesDir, templatesEmbedPath)
+	if err != nil {
+		return false, fmt.Errorf("find custom templates: %w", err)
+	}
+
+	if len(differentFiles) == 0 {
+		fmt.Fprintf(stdout, "  skipped (all files match defaults)\n")
+		return false, nil
+	}
+
+	// display different files with dates
+	fmt.Fprintf(stdout, "  Different from current defaults:\n")
+	for _, f := range differentFiles {
+		if f.missing {
+			fmt.Fprintf(stdout, "    %s (missing)\n", f.name)
+		} else {
+			fmt.Fprintf(stdout, "    %s (%s)\n", f.name, f.modTime.Format("2006-01-02"))
+		}
+	}
+
+	// display custom files (informational)
+	if len(customFiles) > 0 {
+		fmt.Fprintf(stdout, "  Custom templates (untouched):\n")
+		for _, f := range customFiles {
+			fmt.Fprintf(stdout, "    %s\n", f)
+		}
+	}
+
+	embeddedCount, err := d.countEmbeddedFiles(templatesEmbedPath)
+	if err != nil {
+		return false, fmt.Errorf("count embedded templates: %w", err)
+	}
+	fmt.Fprintf(stdout, "  Note: differences may be your customizations or outdated defaults\n")
+	fmt.Fprintf(stdout, "  Reset will overwrite %d default templates, custom templates preserved\n", embeddedCount)
+
+	if !d.askYesNo(scanner, stdout) {
+		return false, nil
+	}
+
+	// overwrite only files that exist in embedded defaults
+	if err := d.overwriteEmbeddedFiles(templatesDir, templatesEmbedPath); err != nil {
+		return false, fmt.Errorf("overwrite templates: %w", err)
+	}
+
+	return true, nil
+}
+
 // fileInfo holds information about a file for display.
 type fileInfo struct {
 	name    string
@@ -391,6 +512,7 @@ type fileInfo struct {
 // files with only comments/empty lines are considered matching (unmodified from commented templates).
 func (d *defaultsInstaller) findDifferentFiles(destDir, embedPath string) ([]fileInfo, error) {
 	var different []fileInfo
+	ext := defaultFileExt(embedPath)
 
 	embeddedEntries, err := d.embedFS.ReadDir(embedPath)
 	if err != nil {
@@ -398,7 +520,7 @@ func (d *defaultsInstaller) findDifferentFiles(destDir, embedPath string) ([]fil
 	}
 
 	for _, entry := range embeddedEntries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ext) {
 			continue
 		}
 
@@ -430,7 +552,7 @@ func (d *defaultsInstaller) findDifferentFiles(destDir, embedPath string) ([]fil
 		if bytes.Equal(embeddedData, localData) {
 			continue // exact match
 		}
-		if strings.TrimSpace(stripComments(string(localData))) == "" {
+		if isBlankDefault(embedPath, localData) {
 			continue // only comments/whitespace - considered unmodified
 		}
 
@@ -443,6 +565,7 @@ func (d *defaultsInstaller) findDifferentFiles(destDir, embedPath string) ([]fil
 // findCustomFiles returns files in destDir that don't exist in embedded defaults.
 func (d *defaultsInstaller) findCustomFiles(destDir, embedPath string) ([]string, error) {
 	var custom []string
+	ext := defaultFileExt(embedPath)
 
 	// build set of embedded file names
 	embeddedNames := make(map[string]bool)
@@ -451,7 +574,7 @@ func (d *defaultsInstaller) findCustomFiles(destDir, embedPath string) ([]string
 		return nil, fmt.Errorf("read embedded dir: %w", err)
 	}
 	for _, entry := range embeddedEntries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".txt") {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ext) {
 			embeddedNames[entry.Name()] = true
 		}
 	}
@@ -466,7 +589,7 @@ func (d *defaultsInstaller) findCustomFiles(destDir, embedPath string) ([]string
 	}
 
 	for _, entry := range localEntries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ext) {
 			continue
 		}
 		if !embeddedNames[entry.Name()] {
@@ -477,7 +600,7 @@ func (d *defaultsInstaller) findCustomFiles(destDir, embedPath string) ([]string
 	return custom, nil
 }
 
-// countEmbeddedFiles returns the number of .txt files in an embedded directory.
+// countEmbeddedFiles returns the number of default files (.txt, .md for templates) in an embedded directory.
 func (d *defaultsInstaller) countEmbeddedFiles(embedPath string) (int, error) {
 	entries, err := d.embedFS.ReadDir(embedPath)
 	if err != nil {
@@ -486,14 +609,14 @@ func (d *defaultsInstaller) countEmbeddedFiles(embedPath string) (int, error) {
 
 	count := 0
 	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".txt") {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), defaultFileExt(embedPath)) {
 			count++
 		}
 	}
 	return count, nil
 }
 
-// overwriteEmbeddedFiles overwrites files in destDir with embedded defaults (commented).
+// overwriteEmbeddedFiles overwrites files in destDir with embedded defaults (commented, templates as-is).
 // only overwrites files that exist in embedded defaults - preserves custom files.
 func (d *defaultsInstaller) overwriteEmbeddedFiles(destDir, embedPath string) error {
 	// ensure directory exists
@@ -507,7 +630,7 @@ func (d *defaultsInstaller) overwriteEmbeddedFiles(destDir, embedPath string) er
 	}
 
 	for _, entry := range embeddedEntries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), defaultFileExt(embedPath)) {
 			continue
 		}
 
@@ -517,9 +640,8 @@ func (d *defaultsInstaller) overwriteEmbeddedFiles(destDir, embedPath string) er
 		}
 
 		// write with content commented out
-		commented := commentOutContent(string(embeddedData))
 		destPath := filepath.Join(destDir, entry.Name())
-		if err := os.WriteFile(destPath, []byte(commented), 0o600); err != nil {
+		if err := os.WriteFile(destPath, defaultFileContent(embedPath, embeddedData), 0o600); err != nil {
 			return fmt.Errorf("write %s: %w", entry.Name(), err)
 		}
 	}
@@ -556,7 +678,7 @@ func (d *defaultsInstaller) dumpEmbeddedDir(destDir, embedPath string) error {
 }
 
 // DumpDefaults extracts all embedded defaults (raw, uncommented) to the specified directory.
-// creates config, prompts/, agents/ structure under dir.
+// creates config, prompts/, agents/, templates/ structure under dir.
 func DumpDefaults(dir string) error {
 	installer := newDefaultsInstaller(defaultsFS)
 
@@ -582,6 +704,11 @@ func DumpDefaults(dir string) error {
 		return fmt.Errorf("dump agents: %w", err)
 	}
 
+	// dump plan templates
+	if err := installer.dumpEmbeddedDir(filepath.Join(dir, "templates"), templatesEmbedPath); err != nil {
+		return fmt.Errorf("dump templates: %w", err)
+	}
+
 	return nil
 }
 
@@ -634,6 +761,12 @@ func (d *defaultsInstaller) printResetSummary(result ResetResult, stdout io.Writ
 		skipped = append(skipped, "agents")
 	}
 
+	if result.TemplatesReset {
+		reset = append(reset, "templates")
+	} else {
+		skipped = append(skipped, "templates")
+	}
+
 	fmt.Fprintf(stdout, "\nDone.")
 	if len(reset) > 0 {
 		fmt.Fprintf(stdout, " Reset: %s.", strings.Join(reset, ", "))
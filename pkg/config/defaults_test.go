@@ -255,7 +255,7 @@ func TestDefaultsInstaller_installDefaultFiles_Prompts(t *testing.T) {
 	installer := &defaultsInstaller{embedFS: defaultsFS}
 	require.NoError(t, installer.installDefaultFiles(promptsDir, "defaults/prompts", "prompt"))
 
-	expectedPrompts := []string{"task.txt", "review_first.txt", "review_second.txt", "codex.txt", "make_plan.txt", "finalize.txt", "custom_review.txt", "custom_eval.txt"}
+	expectedPrompts := []string{"task.txt", "review_first.txt", "review_second.txt", "codex.txt", "make_plan.txt", "finalize.txt", "custom_review.txt", "custom_eval.txt", "gemini.txt"}
 	for _, prompt := range expectedPrompts {
 		promptPath := filepath.Join(promptsDir, prompt)
 		assert.FileExists(t, promptPath, "prompt file %s should be installed", prompt)
@@ -297,7 +297,7 @@ func TestDefaultsInstaller_Install_InstallsPromptFiles(t *testing.T) {
 	require.NoError(t, installer.Install(configDir))
 
 	promptsDir := filepath.Join(configDir, "prompts")
-	expectedPrompts := []string{"task.txt", "review_first.txt", "review_second.txt", "codex.txt", "make_plan.txt", "finalize.txt", "custom_review.txt", "custom_eval.txt"}
+	expectedPrompts := []string{"task.txt", "review_first.txt", "review_second.txt", "codex.txt", "make_plan.txt", "finalize.txt", "custom_review.txt", "custom_eval.txt", "gemini.txt"}
 
 	for _, prompt := range expectedPrompts {
 		promptPath := filepath.Join(promptsDir, prompt)
@@ -541,6 +541,43 @@ func TestReset_PreservesCustomAgents(t *testing.T) {
 	assert.NotEqual(t, "modified quality agent", string(data))
 }
 
+func TestReset_ResetsTemplatesPreservesCustom(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "ralphex")
+
+	installer := newDefaultsInstaller(defaultsFS)
+	require.NoError(t, installer.Install(configDir))
+
+	// add a custom template and modify a default one
+	customPath := filepath.Join(configDir, "templates", "refactor.md")
+	require.NoError(t, os.WriteFile(customPath, []byte("# Refactor\n\n## Goal\n"), 0o600))
+	featurePath := filepath.Join(configDir, "templates", "feature.md")
+	require.NoError(t, os.WriteFile(featurePath, []byte("# Feature\n\n## My sections\n"), 0o600))
+
+	// only templates differ, so the templates question is the only one asked
+	stdin := strings.NewReader("y\n")
+	stdout := &bytes.Buffer{}
+
+	result, err := Reset(configDir, stdin, stdout)
+	require.NoError(t, err)
+	assert.False(t, result.PromptsReset)
+	assert.False(t, result.AgentsReset)
+	assert.True(t, result.TemplatesReset)
+	assert.Contains(t, stdout.String(), "feature.md")
+	assert.Contains(t, stdout.String(), "Custom templates (untouched):\n    refactor.md")
+
+	data, err := os.ReadFile(customPath) //nolint:gosec // test
+	require.NoError(t, err)
+	assert.Equal(t, "# Refactor\n\n## Goal\n", string(data))
+
+	// default template restored as-is, not commented out
+	data, err = os.ReadFile(featurePath) //nolint:gosec // test
+	require.NoError(t, err)
+	embedded, err := defaultsFS.ReadFile("defaults/templates/feature.md")
+	require.NoError(t, err)
+	assert.Equal(t, string(embedded), string(data))
+}
+
 func TestReset_SkipsWhenAllDefault(t *testing.T) {
 	tmpDir := t.TempDir()
 	configDir := filepath.Join(tmpDir, "ralphex")
@@ -575,9 +612,10 @@ func TestReset_ResetsAllComponents(t *testing.T) {
 	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("claude_command = custom"), 0o600))
 	require.NoError(t, os.WriteFile(filepath.Join(configDir, "prompts", "task.txt"), []byte("modified prompt"), 0o600))
 	require.NoError(t, os.WriteFile(filepath.Join(configDir, "agents", "quality.txt"), []byte("modified agent"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "templates", "feature.md"), []byte("## modified"), 0o600))
 
 	// reset all with "y" answers
-	stdin := strings.NewReader("y\ny\ny\n")
+	stdin := strings.NewReader("y\ny\ny\ny\n")
 	stdout := &bytes.Buffer{}
 
 	result, err := Reset(configDir, stdin, stdout)
@@ -585,11 +623,12 @@ func TestReset_ResetsAllComponents(t *testing.T) {
 	assert.True(t, result.ConfigReset)
 	assert.True(t, result.PromptsReset)
 	assert.True(t, result.AgentsReset)
+	assert.True(t, result.TemplatesReset)
 
 	// verify summary output
 	output := stdout.String()
 	assert.Contains(t, output, "Done.")
-	assert.Contains(t, output, "Reset: config, prompts, agents")
+	assert.Contains(t, output, "Reset: config, prompts, agents, templates")
 }
 
 func TestReset_ShowsDifferentFilesWithDates(t *testing.T) {
@@ -886,6 +925,11 @@ func TestDumpDefaults(t *testing.T) {
 		agentEntries, err := os.ReadDir(filepath.Join(tmpDir, "agents"))
 		require.NoError(t, err)
 		assert.GreaterOrEqual(t, len(agentEntries), 5, "should have agent files")
+
+		// verify plan templates are dumped
+		data, err = os.ReadFile(filepath.Join(tmpDir, "templates", "feature.md")) //nolint:gosec // test
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(data), "# <Feature title>"))
 	})
 
 	t.Run("prompt_content_is_raw", func(t *testing.T) {
@@ -1268,3 +1312,33 @@ func TestDefaultsInstaller_Install_PreservesCustomAgents(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, entries, 1, "should only have the custom agent")
 }
+
+func TestDefaultsInstaller_Install_WritesTemplatesAsIs(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), "ralphex")
+	installer := newDefaultsInstaller(defaultsFS)
+	require.NoError(t, installer.Install(configDir))
+
+	for _, name := range []string{"feature.md", "bugfix.md"} {
+		data, err := os.ReadFile(filepath.Join(configDir, "templates", name)) //nolint:gosec // test
+		require.NoError(t, err)
+		embedded, err := defaultsFS.ReadFile("defaults/templates/" + name)
+		require.NoError(t, err)
+		assert.Equal(t, string(embedded), string(data), "%s installed without commenting out headings", name)
+	}
+}
+
+func TestDefaultsInstaller_Install_PreservesCustomTemplates(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), "ralphex")
+	templatesDir := filepath.Join(configDir, "templates")
+	require.NoError(t, os.MkdirAll(templatesDir, 0o700))
+	// a heading-only template has content, "#" is not a comment in markdown
+	require.NoError(t, os.WriteFile(filepath.Join(templatesDir, "mine.md"), []byte("# Title\n## Steps\n"), 0o600))
+
+	installer := newDefaultsInstaller(defaultsFS)
+	require.NoError(t, installer.Install(configDir))
+
+	entries, err := os.ReadDir(templatesDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "mine.md", entries[0].Name())
+}
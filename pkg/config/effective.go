@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// WriteEffective writes every config key with the value in effect after merging all layers, in the format
+// of the config file and commented out like installed defaults. a comment before each key names its source:
+// env, repo, global, theme or default. tokens and passwords are masked.
+func (c *Config) WriteEffective(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("# effective ralphex configuration\n")
+	sb.WriteString("# precedence: env > repo > global > default, uncomment a value to pin it in a config file\n")
+	for _, key := range knownKeys {
+		value, source := c.effectiveValue(key)
+		if value != "" && isSecretKey(key) {
+			value = "*****"
+		}
+		fmt.Fprintf(&sb, "\n# %s: %s\n%s = %s\n", key, source, key, value)
+	}
+	if _, err := io.WriteString(w, commentOutContent(sb.String())); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	return nil
+}
+
+// effectiveValue returns the raw value of the key taken from its source (see ValueSource) and the source
+// label. keys not set anywhere are reported as "unset" with an empty value.
+func (c *Config) effectiveValue(key string) (value, source string) {
+	src := c.ValueSource(key)
+	switch {
+	case src == "":
+		return "", "unset"
+	case strings.HasPrefix(src, "env "):
+		return strings.TrimSpace(os.Getenv(EnvVar(key))), src
+	case src == "embedded":
+		// a theme replaces the embedded colors, see ColorConfig.ApplyTheme
+		if color, ok := c.themeColor(key); ok {
+			return color, "theme " + c.Colors.Theme
+		}
+		data, err := defaultsFS.ReadFile("defaults/config")
+		if err != nil {
+			return "", "default"
+		}
+		return iniValue(data, key), "default"
+	}
+
+	label := "repo " + src
+	if c.configDir != "" && src == filepath.Join(c.configDir, "config") {
+		label = "global " + src
+	}
+	data, err := readConfigData(src)
+	if err != nil {
+		return "", label
+	}
+	return iniValue(data, key), label
+}
+
+// themeColor returns the color the configured theme gives to a color_* key, as a hex value.
+// returns false without a theme or for other keys.
+func (c *Config) themeColor(key string) (string, bool) {
+	if c.Colors.Theme == "" || !strings.HasPrefix(key, "color_") {
+		return "", false
+	}
+	theme := c.Colors.ApplyTheme()
+	fields := map[string]string{
+		"color_task": theme.Task, "color_review": theme.Review, "color_codex": theme.Codex,
+		"color_claude_eval": theme.ClaudeEval, "color_warn": theme.Warn, "color_error": theme.Error,
+		"color_signal": theme.Signal, "color_timestamp": theme.Timestamp, "color_info": theme.Info,
+	}
+	rgb, ok := fields[key]
+	if !ok {
+		return "", false
+	}
+	parts := strings.Split(rgb, ",")
+	if len(parts) != 3 {
+		return "", false
+	}
+	hex := "#"
+	for _, p := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return "", false
+		}
+		hex += fmt.Sprintf("%02x", n)
+	}
+	return hex, true
+}
+
+// iniValue returns the trimmed value of a top-level key in INI data, empty string if it is missing.
+func iniValue(data []byte, key string) string {
+	cfg, err := ini.LoadSources(ini.LoadOptions{IgnoreInlineComment: true}, data)
+	if err != nil {
+		return ""
+	}
+	k, err := cfg.Section("").GetKey(key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(k.String())
+}
+
+// isSecretKey reports whether the key holds a credential that shouldn't be printed.
+func isSecretKey(key string) bool {
+	return strings.HasSuffix(key, "_token") || strings.HasSuffix(key, "_password")
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_WriteEffective(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalDir := filepath.Join(tmpDir, "global")
+	localDir := filepath.Join(tmpDir, ".ralphex")
+	require.NoError(t, os.MkdirAll(globalDir, 0o700))
+	require.NoError(t, os.MkdirAll(localDir, 0o700))
+	globalPath := filepath.Join(globalDir, "config")
+	localPath := filepath.Join(localDir, "config")
+	require.NoError(t, os.WriteFile(globalPath,
+		[]byte("plans_dir = global-plans\ncodex_enabled = false\nnotify_telegram_token = secret\n"), 0o600))
+	require.NoError(t, os.WriteFile(localPath, []byte("plans_dir = repo-plans\n"), 0o600))
+	t.Setenv("RALPHEX_TASK_RETRY_COUNT", "5")
+
+	cfg, err := loadConfigFromDirs(globalDir, localDir, "")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, cfg.WriteEffective(&out))
+	text := out.String()
+
+	assert.Contains(t, text, "# task_retry_count: env RALPHEX_TASK_RETRY_COUNT\n# task_retry_count = 5\n")
+	assert.Contains(t, text, "# plans_dir: repo "+localPath+"\n# plans_dir = repo-plans\n")
+	assert.Contains(t, text, "# codex_enabled: global "+globalPath+"\n# codex_enabled = false\n")
+	assert.Contains(t, text, "# iteration_delay_ms: default\n# iteration_delay_ms = 2000\n")
+	assert.Contains(t, text, "# notify_telegram_token: global "+globalPath+"\n# notify_telegram_token = *****\n")
+	assert.NotContains(t, text, "secret")
+	assert.Contains(t, text, "# notify_slack_token: unset\n# notify_slack_token = \n")
+	for line := range strings.SplitSeq(strings.TrimSpace(text), "\n") {
+		if line != "" {
+			assert.True(t, strings.HasPrefix(line, "#"), "line %q must be commented out", line)
+		}
+	}
+	for _, key := range knownKeys {
+		assert.Contains(t, text, "\n# "+key+" = ", "every known key is printed")
+	}
+}
+
+func TestConfig_WriteEffective_Theme(t *testing.T) {
+	globalDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"),
+		[]byte("theme = light\ncolor_error = #ff0000\n"), 0o600))
+
+	cfg, err := loadConfigFromDirs(globalDir, "", "")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, cfg.WriteEffective(&out))
+	assert.Contains(t, out.String(), "# color_task: theme light\n# color_task = #008700\n")
+	assert.Contains(t, out.String(), "# color_error: global "+filepath.Join(globalDir, "config")+"\n# color_error = #ff0000\n")
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envPrefix starts the environment variables overriding config keys, e.g. RALPHEX_CLAUDE_COMMAND.
+const envPrefix = "RALPHEX_"
+
+// EnvVar returns the environment variable that overrides the given config key.
+func EnvVar(key string) string {
+	return envPrefix + strings.ToUpper(key)
+}
+
+// envSetting is a config key set by an environment variable.
+type envSetting struct {
+	key  string
+	data []byte // single "key = value" INI line, parsed like a config file
+}
+
+// envSettings returns the config keys overridden by the environment, in knownKeys order.
+// empty variables are ignored, like empty values in config files. multi-line values are rejected,
+// they would inject other keys into the INI line.
+func envSettings() ([]envSetting, error) {
+	var res []envSetting
+	for _, key := range knownKeys {
+		value, ok := os.LookupEnv(EnvVar(key))
+		if !ok || strings.TrimSpace(value) == "" {
+			continue
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("%s: value must be a single line", EnvVar(key))
+		}
+		res = append(res, envSetting{key: key, data: []byte(key + " = " + value + "\n")})
+	}
+	return res, nil
+}
+
+// parseValuesFromEnv parses the environment overrides into Values.
+// each variable is parsed on its own, so a malformed value fails with the variable name.
+func (vl *valuesLoader) parseValuesFromEnv() (Values, error) {
+	settings, err := envSettings()
+	if err != nil {
+		return Values{}, err
+	}
+	var values Values
+	for _, s := range settings {
+		v, err := vl.parseValuesFromBytes(s.data)
+		if err != nil {
+			return Values{}, fmt.Errorf("%s: %w", EnvVar(s.key), err)
+		}
+		values.mergeFrom(&v)
+	}
+	return values, nil
+}
+
+// parseColorsFromEnv parses the environment overrides of theme and color keys into a ColorConfig.
+func (cl *colorLoader) parseColorsFromEnv() (ColorConfig, error) {
+	settings, err := envSettings()
+	if err != nil {
+		return ColorConfig{}, err
+	}
+	var colors ColorConfig
+	for _, s := range settings {
+		c, err := cl.parseColorsFromBytes(s.data)
+		if err != nil {
+			return ColorConfig{}, fmt.Errorf("%s: %w", EnvVar(s.key), err)
+		}
+		colors.mergeFrom(&c)
+	}
+	return colors, nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvVar(t *testing.T) {
+	assert.Equal(t, "RALPHEX_CLAUDE_COMMAND", EnvVar("claude_command"))
+	assert.Equal(t, "RALPHEX_CODEX_ENABLED", EnvVar("codex_enabled"))
+}
+
+func TestLoad_EnvOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalDir := filepath.Join(tmpDir, "global")
+	localDir := filepath.Join(tmpDir, ".ralphex")
+	require.NoError(t, os.MkdirAll(globalDir, 0o700))
+	require.NoError(t, os.MkdirAll(localDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"),
+		[]byte("claude_command = global-claude\ncodex_enabled = true\ntask_retry_count = 3\nmax_iterations = 30\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"),
+		[]byte("external_review_tool = codex\nplans_dir = repo-plans\n"), 0o600))
+
+	t.Setenv("RALPHEX_CLAUDE_COMMAND", "/opt/claude")
+	t.Setenv("RALPHEX_CODEX_ENABLED", "false")
+	t.Setenv("RALPHEX_EXTERNAL_REVIEW_TOOL", "none")
+	t.Setenv("RALPHEX_TASK_RETRY_COUNT", "0")
+	t.Setenv("RALPHEX_MAX_ITERATIONS", "7")
+	t.Setenv("RALPHEX_PLANS_DIR", "") // empty variables are ignored
+	t.Setenv("RALPHEX_THEME", "light")
+
+	cfg, err := loadConfigFromDirs(globalDir, localDir, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "/opt/claude", cfg.ClaudeCommand, "env wins over global file")
+	assert.False(t, cfg.CodexEnabled, "explicit false from env wins over file true")
+	assert.True(t, cfg.CodexEnabledSet)
+	assert.Equal(t, "none", cfg.ExternalReviewTool, "env wins over repo-local file")
+	assert.Equal(t, 0, cfg.TaskRetryCount)
+	assert.True(t, cfg.TaskRetryCountSet)
+	assert.Equal(t, 7, cfg.MaxIterations, "env wins over global file")
+	assert.Equal(t, "env RALPHEX_MAX_ITERATIONS", cfg.ValueSource("max_iterations"))
+	assert.Equal(t, "repo-plans", cfg.PlansDir)
+	assert.Equal(t, "light", cfg.Colors.Theme)
+	assert.Equal(t, "env RALPHEX_CLAUDE_COMMAND", cfg.ValueSource("claude_command"))
+	assert.Equal(t, filepath.Join(localDir, "config"), cfg.ValueSource("plans_dir"))
+}
+
+func TestLoad_EnvOverridesInvalid(t *testing.T) {
+	tests := []struct {
+		name, env, value, wantErr string
+	}{
+		{name: "malformed bool", env: "RALPHEX_CODEX_ENABLED", value: "maybe",
+			wantErr: "RALPHEX_CODEX_ENABLED: invalid codex_enabled"},
+		{name: "malformed int", env: "RALPHEX_TASK_RETRY_COUNT", value: "three",
+			wantErr: "RALPHEX_TASK_RETRY_COUNT: invalid task_retry_count"},
+		{name: "malformed max iterations", env: "RALPHEX_MAX_ITERATIONS", value: "many",
+			wantErr: "RALPHEX_MAX_ITERATIONS: invalid max_iterations"},
+		{name: "zero max iterations", env: "RALPHEX_MAX_ITERATIONS", value: "0",
+			wantErr: "RALPHEX_MAX_ITERATIONS: invalid max_iterations: must be positive"},
+		{name: "out of range int", env: "RALPHEX_FINALIZE_MAX_ITERATIONS", value: "-1",
+			wantErr: "RALPHEX_FINALIZE_MAX_ITERATIONS: invalid finalize_max_iterations"},
+		{name: "multi-line value", env: "RALPHEX_CLAUDE_COMMAND", value: "claude\ncodex_enabled = false",
+			wantErr: "RALPHEX_CLAUDE_COMMAND: value must be a single line"},
+		{name: "malformed color", env: "RALPHEX_COLOR_TASK", value: "green",
+			wantErr: "RALPHEX_COLOR_TASK:"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			globalDir := t.TempDir()
+			t.Setenv(tc.env, tc.value)
+
+			_, err := loadConfigFromDirs(globalDir, "", "")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
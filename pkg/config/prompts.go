@@ -19,8 +19,10 @@ type Prompts struct {
 	Codex        string
 	MakePlan     string
 	Finalize     string
+	FinalizeFix  string
 	CustomReview string
 	CustomEval   string
+	Gemini       string
 }
 
 // promptLoader implements PromptLoader with embedded filesystem fallback.
@@ -68,6 +70,11 @@ func (p *promptLoader) Load(localDir, globalDir string) (Prompts, error) {
 		return Prompts{}, fmt.Errorf("load finalize prompt: %w", err)
 	}
 
+	prompts.FinalizeFix, err = p.loadPromptWithLocalFallback(localDir, globalDir, finalizeFixPromptFile)
+	if err != nil {
+		return Prompts{}, fmt.Errorf("load finalize_fix prompt: %w", err)
+	}
+
 	prompts.CustomReview, err = p.loadPromptWithLocalFallback(localDir, globalDir, customReviewPromptFile)
 	if err != nil {
 		return Prompts{}, fmt.Errorf("load custom_review prompt: %w", err)
@@ -78,6 +85,11 @@ func (p *promptLoader) Load(localDir, globalDir string) (Prompts, error) {
 		return Prompts{}, fmt.Errorf("load custom_eval prompt: %w", err)
 	}
 
+	prompts.Gemini, err = p.loadPromptWithLocalFallback(localDir, globalDir, geminiPromptFile)
+	if err != nil {
+		return Prompts{}, fmt.Errorf("load gemini prompt: %w", err)
+	}
+
 	return prompts, nil
 }
 
@@ -144,6 +156,23 @@ func (p *promptLoader) loadPromptFromEmbedFS(path string) (string, error) {
 	return strings.TrimSpace(stripLeadingComments(normalizeCRLF(string(data)))), nil
 }
 
+// PromptSource returns where the given prompt file (e.g. "task.txt") is loaded from,
+// following the same local → global → embedded fallback as the prompt loader.
+// returns the file path for local or global overrides, or "embedded" for built-in defaults.
+func (c *Config) PromptSource(filename string) string {
+	pl := newPromptLoader(defaultsFS)
+	for _, dir := range []string{c.localDir, c.configDir} {
+		if dir == "" {
+			continue
+		}
+		path := filepath.Join(dir, "prompts", filename)
+		if content, err := pl.loadPromptFile(path); err == nil && content != "" {
+			return path
+		}
+	}
+	return "embedded"
+}
+
 // normalizeCRLF converts Windows line endings (CRLF) to Unix (LF).
 func normalizeCRLF(content string) string {
 	return strings.ReplaceAll(content, "\r\n", "\n")
@@ -662,6 +662,20 @@ func TestPromptLoader_Load_FinalizePrompt(t *testing.T) {
 	assert.Equal(t, "custom finalize with {{DEFAULT_BRANCH}}", prompts.Finalize)
 }
 
+func TestPromptLoader_Load_FinalizeFixPrompt(t *testing.T) {
+	loader := newPromptLoader(defaultsFS)
+	prompts, err := loader.Load("", filepath.Join(t.TempDir(), "prompts"))
+	require.NoError(t, err)
+	assert.Contains(t, prompts.FinalizeFix, "{{FINALIZE_FAILURES}}", "embedded default should be used")
+
+	globalDir := filepath.Join(t.TempDir(), "prompts")
+	require.NoError(t, os.MkdirAll(globalDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "finalize_fix.txt"), []byte("fix {{FINALIZE_FAILURES}}"), 0o600))
+	prompts, err = loader.Load("", globalDir)
+	require.NoError(t, err)
+	assert.Equal(t, "fix {{FINALIZE_FAILURES}}", prompts.FinalizeFix)
+}
+
 func TestPromptLoader_Load_FinalizePrompt_LocalOverridesGlobal(t *testing.T) {
 	tmpDir := t.TempDir()
 	globalDir := filepath.Join(tmpDir, "global", "prompts")
@@ -776,3 +790,23 @@ func TestPromptLoader_Load_CustomEvalPrompt_LocalOverridesGlobal(t *testing.T) {
 
 	assert.Equal(t, "local custom eval", prompts.CustomEval)
 }
+
+func TestConfig_PromptSource(t *testing.T) {
+	globalDir := t.TempDir()
+	localDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(globalDir, "prompts"), 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(localDir, "prompts"), 0o700))
+
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "prompts", "task.txt"), []byte("global task"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "prompts", "codex.txt"), []byte("global codex"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "prompts", "codex.txt"), []byte("local codex"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "prompts", "finalize.txt"), []byte("# commented\n# out\n"), 0o600))
+
+	c := &Config{configDir: globalDir, localDir: localDir}
+	assert.Equal(t, filepath.Join(globalDir, "prompts", "task.txt"), c.PromptSource("task.txt"))
+	assert.Equal(t, filepath.Join(localDir, "prompts", "codex.txt"), c.PromptSource("codex.txt"))
+	assert.Equal(t, "embedded", c.PromptSource("finalize.txt"), "all-commented file falls back to embedded")
+	assert.Equal(t, "embedded", c.PromptSource("review_first.txt"))
+
+	assert.Equal(t, "embedded", (&Config{}).PromptSource("task.txt"))
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// repoFileNames lists the single-file configs looked up at the repository root.
+// .ralphex.conf uses the INI format of config files, .ralphex.yml a flat YAML mapping of the same keys.
+var repoFileNames = []string{".ralphex.conf", ".ralphex.yml"}
+
+// detectRepoFile returns the repo-root config file for the current directory, or empty string if there is none.
+// os.Getwd() failure is silently ignored, like in detectLocalDir.
+func detectRepoFile() (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", nil
+	}
+	return findRepoFile(cwd)
+}
+
+// findRepoFile looks for .ralphex.conf or .ralphex.yml at the root of the repository containing start.
+// outside a repository there is no repo-root file. having both is an error, one of them would be ignored silently.
+func findRepoFile(start string) (string, error) {
+	root := findRepoRoot(start)
+	if root == "" {
+		return "", nil
+	}
+
+	var found []string
+	for _, name := range repoFileNames {
+		path := filepath.Join(root, name)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			found = append(found, path)
+		}
+	}
+	if len(found) > 1 {
+		return "", fmt.Errorf("both %s and %s found, keep one of them", found[0], found[1])
+	}
+	if len(found) == 0 {
+		return "", nil
+	}
+	return found[0], nil
+}
+
+// readConfigData reads a config file as INI data, converting a .yml file (.ralphex.yml) with yamlToINI.
+// returns nil data if path is empty or the file doesn't exist.
+func readConfigData(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read config %s: %w", path, err)
+	}
+	if filepath.Ext(path) != ".yml" {
+		return data, nil
+	}
+	res, err := yamlToINI(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return res, nil
+}
+
+// yamlToINI converts a flat YAML mapping of config keys to INI "key = value" lines.
+// only known top-level keys with scalar values are accepted, sections like [custom_agents]
+// belong to .ralphex/config. multi-line values are rejected, they would inject other keys.
+func yamlToINI(data []byte) ([]byte, error) {
+	var m map[string]any
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse yaml: %w", err)
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		if !slices.Contains(knownKeys, k) {
+			msg := fmt.Sprintf("unknown key %q", k)
+			if s := suggest(k, knownKeys); s != "" {
+				msg += fmt.Sprintf(", did you mean %q?", s)
+			}
+			return nil, errors.New(msg)
+		}
+		var value string
+		switch v := m[k].(type) {
+		case nil:
+			continue // "key:" without a value, like an empty value in config files
+		case string:
+			value = v
+		case bool, int, float64:
+			value = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("%s: value must be a scalar, got %T", k, v)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("%s: value must be a single line", k)
+		}
+		quoted, err := quoteINIValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		fmt.Fprintf(&sb, "%s = %s\n", k, quoted)
+	}
+	return []byte(sb.String()), nil
+}
+
+// quoteINIValue quotes value for an INI line when the INI parser would read it differently than written:
+// comment characters, quotes, a trailing backslash (line continuation) or surrounding spaces.
+// uses backticks like go-ini does, or triple double quotes for values containing a backtick.
+func quoteINIValue(value string) (string, error) {
+	if !strings.ContainsAny(value, "#;`\"\\") && strings.TrimSpace(value) == value {
+		return value, nil
+	}
+	if !strings.Contains(value, "`") {
+		return "`" + value + "`", nil
+	}
+	if !strings.Contains(value, `"""`) {
+		return `"""` + value + `"""`, nil
+	}
+	return "", errors.New("value can't contain both a backtick and triple double quotes")
+}
+
+// parseValuesFromRepoFile parses values from the repo-root config file, empty Values if there is none.
+func (vl *valuesLoader) parseValuesFromRepoFile(path string) (Values, error) {
+	data, err := readConfigData(path)
+	if err != nil || strings.TrimSpace(stripComments(string(data))) == "" {
+		return Values{}, err
+	}
+	return vl.parseValuesFromBytes(data)
+}
+
+// parseColorsFromRepoFile parses theme and color keys from the repo-root config file.
+func (cl *colorLoader) parseColorsFromRepoFile(path string) (ColorConfig, error) {
+	data, err := readConfigData(path)
+	if err != nil || strings.TrimSpace(stripComments(string(data))) == "" {
+		return ColorConfig{}, err
+	}
+	return cl.parseColorsFromBytes(data)
+}
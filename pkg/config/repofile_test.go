@@ -0,0 +1,178 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRepoFile(t *testing.T) {
+	setup := func(t *testing.T, files ...string) string {
+		t.Helper()
+		base := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(base, ".git"), 0o750))
+		require.NoError(t, os.MkdirAll(filepath.Join(base, "pkg", "sub"), 0o750))
+		for _, f := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(base, f), []byte("plans_dir = plans\n"), 0o600))
+		}
+		return base
+	}
+
+	t.Run("conf at repo root from subdirectory", func(t *testing.T) {
+		base := setup(t, ".ralphex.conf")
+		path, err := findRepoFile(filepath.Join(base, "pkg", "sub"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(base, ".ralphex.conf"), path)
+	})
+
+	t.Run("yml at repo root", func(t *testing.T) {
+		base := setup(t, ".ralphex.yml")
+		path, err := findRepoFile(base)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(base, ".ralphex.yml"), path)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		base := setup(t)
+		path, err := findRepoFile(base)
+		require.NoError(t, err)
+		assert.Empty(t, path)
+	})
+
+	t.Run("not in a repository", func(t *testing.T) {
+		base := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(base, ".ralphex.conf"), []byte("plans_dir = plans\n"), 0o600))
+		path, err := findRepoFile(base)
+		require.NoError(t, err)
+		assert.Empty(t, path)
+	})
+
+	t.Run("both is an error", func(t *testing.T) {
+		base := setup(t, ".ralphex.conf", ".ralphex.yml")
+		_, err := findRepoFile(base)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "keep one of them")
+	})
+}
+
+func TestYamlToINI(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    string
+		wantErr string
+	}{
+		{name: "scalars", yaml: "plans_dir: docs/plans\ncodex_enabled: false\ntask_retry_count: 2\nclaude_args: \"--x --y\"\n",
+			want: "claude_args = --x --y\ncodex_enabled = false\nplans_dir = docs/plans\ntask_retry_count = 2\n"},
+		{name: "comment characters quoted", yaml: "claude_args: \"--x #1; --y\"\ncodex_model: 'a\\'\n",
+			want: "claude_args = `--x #1; --y`\ncodex_model = `a\\`\n"},
+		{name: "quotes and backtick", yaml: "claude_args: '\"--x\" `y`'\n", want: "claude_args = \"\"\"\"--x\" `y`\"\"\"\n"},
+		{name: "backtick and triple quotes", yaml: "claude_args: '`\"\"\"'\n",
+			wantErr: "claude_args: value can't contain both a backtick and triple double quotes"},
+		{name: "empty value skipped", yaml: "plans_dir:\n", want: ""},
+		{name: "empty document", yaml: "", want: ""},
+		{name: "unknown key", yaml: "plan_dir: docs/plans\n", wantErr: `unknown key "plan_dir", did you mean "plans_dir"?`},
+		{name: "nested value", yaml: "custom_agents:\n  security: check\n", wantErr: `unknown key "custom_agents"`},
+		{name: "list value", yaml: "plans_dir: [a, b]\n", wantErr: "plans_dir: value must be a scalar"},
+		{name: "multi-line value", yaml: "plans_dir: |\n  a\n  b\n", wantErr: "plans_dir: value must be a single line"},
+		{name: "not a mapping", yaml: "- a\n", wantErr: "parse yaml"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := yamlToINI([]byte(tc.yaml))
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(got))
+		})
+	}
+}
+
+func TestLoad_RepoFile(t *testing.T) {
+	setup := func(t *testing.T, name, content string) (globalDir, repoFile string) {
+		t.Helper()
+		tmpDir := t.TempDir()
+		globalDir = filepath.Join(tmpDir, "global")
+		require.NoError(t, os.MkdirAll(globalDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"),
+			[]byte("plans_dir = global-plans\ncodex_enabled = true\nagent_backend = claude\ntask_retry_count = 3\n"), 0o600))
+		repoFile = filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(repoFile, []byte(content), 0o600))
+		return globalDir, repoFile
+	}
+
+	tests := []struct{ name, file, content string }{
+		{name: "conf", file: ".ralphex.conf", content: "plans_dir = repo-plans\ncodex_enabled = false\nagent_backend = gemini\n"},
+		{name: "yml", file: ".ralphex.yml", content: "plans_dir: repo-plans\ncodex_enabled: false\nagent_backend: gemini\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name+" wins over global", func(t *testing.T) {
+			globalDir, repoFile := setup(t, tc.file, tc.content)
+
+			cfg, err := loadConfigFromDirs(globalDir, "", repoFile)
+			require.NoError(t, err)
+			assert.Equal(t, "repo-plans", cfg.PlansDir)
+			assert.False(t, cfg.CodexEnabled)
+			assert.True(t, cfg.CodexEnabledSet)
+			assert.Equal(t, "gemini", cfg.AgentBackend)
+			assert.Equal(t, 3, cfg.TaskRetryCount, "inherited from global")
+			assert.Equal(t, repoFile, cfg.RepoFile())
+			assert.Equal(t, repoFile, cfg.ValueSource("plans_dir"))
+			assert.Equal(t, filepath.Join(globalDir, "config"), cfg.ValueSource("task_retry_count"))
+		})
+	}
+
+	t.Run("repo-local dir wins over repo file", func(t *testing.T) {
+		globalDir, repoFile := setup(t, ".ralphex.conf", "plans_dir = repo-plans\nagent_backend = gemini\n")
+		localDir := filepath.Join(filepath.Dir(repoFile), ".ralphex")
+		require.NoError(t, os.MkdirAll(localDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte("plans_dir = local-plans\n"), 0o600))
+
+		cfg, err := loadConfigFromDirs(globalDir, localDir, repoFile)
+		require.NoError(t, err)
+		assert.Equal(t, "local-plans", cfg.PlansDir)
+		assert.Equal(t, "gemini", cfg.AgentBackend)
+	})
+
+	t.Run("yml values with comment characters and quotes", func(t *testing.T) {
+		globalDir, repoFile := setup(t, ".ralphex.yml",
+			"claude_args: \"--append-system-prompt '#1; keep it short'\"\nplans_dir: '\"plans\" #2'\n")
+
+		cfg, err := loadConfigFromDirs(globalDir, "", repoFile)
+		require.NoError(t, err)
+		assert.Equal(t, "--append-system-prompt '#1; keep it short'", cfg.ClaudeArgs)
+		assert.Equal(t, `"plans" #2`, cfg.PlansDir)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		globalDir, repoFile := setup(t, ".ralphex.yml", "task_retry_count: many\n")
+
+		_, err := loadConfigFromDirs(globalDir, "", repoFile)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "parse repo config")
+	})
+}
+
+func TestValidateRepoFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".ralphex.yml")
+	require.NoError(t, os.WriteFile(path, []byte("task_retry_count: many\nplans_dir: docs/plans\n"), 0o600))
+
+	issues, err := validateRepoFile(path)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, path, issues[0].File)
+	assert.Contains(t, issues[0].Message, "task_retry_count")
+
+	require.NoError(t, os.WriteFile(path, []byte("plan_dir: docs/plans\n"), 0o600))
+	issues, err = validateRepoFile(path)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, `unknown key "plan_dir", did you mean "plans_dir"?`, issues[0].Message)
+}
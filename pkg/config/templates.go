@@ -0,0 +1,90 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// PlanTemplate returns the plan template with the given name (file name without .md) for --plan --template.
+// lookup follows the prompt fallback chain: local .ralphex/templates → global templates → embedded defaults.
+// empty files are skipped. an unknown name fails with an error listing the available templates.
+func (c *Config) PlanTemplate(name string) (string, error) {
+	name = strings.TrimSuffix(name, ".md")
+	if name == "" || name != filepath.Base(name) || name == ".." {
+		return "", fmt.Errorf("invalid template name %q", name)
+	}
+
+	filename := name + ".md"
+	for _, dir := range c.templateDirs() {
+		data, err := os.ReadFile(filepath.Join(dir, filename)) //nolint:gosec // name is a plain file name
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", fmt.Errorf("read template %s: %w", filename, err)
+		}
+		if content := strings.TrimSpace(normalizeCRLF(string(data))); content != "" {
+			return content, nil
+		}
+	}
+
+	data, err := defaultsFS.ReadFile(templatesEmbedPath + "/" + filename)
+	if err == nil {
+		return strings.TrimSpace(normalizeCRLF(string(data))), nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("read embedded template %s: %w", filename, err)
+	}
+
+	names, err := c.PlanTemplates()
+	if err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("template %q not found, available: %s", name, strings.Join(names, ", "))
+}
+
+// PlanTemplates returns the sorted names of all plan templates from the local, global and embedded directories.
+func (c *Config) PlanTemplates() ([]string, error) {
+	var names []string
+	add := func(entries []fs.DirEntry) {
+		for _, entry := range entries {
+			name, ok := strings.CutSuffix(entry.Name(), ".md")
+			if entry.IsDir() || !ok || name == "" || slices.Contains(names, name) {
+				continue
+			}
+			names = append(names, name)
+		}
+	}
+
+	for _, dir := range c.templateDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("read templates dir: %w", err)
+		}
+		add(entries)
+	}
+	entries, err := defaultsFS.ReadDir(templatesEmbedPath)
+	if err != nil {
+		return nil, fmt.Errorf("read embedded templates: %w", err)
+	}
+	add(entries)
+
+	slices.Sort(names)
+	return names, nil
+}
+
+// templateDirs returns the local and global plan template directories, local first.
+func (c *Config) templateDirs() []string {
+	var dirs []string
+	for _, dir := range []string{c.localDir, c.configDir} {
+		if dir != "" {
+			dirs = append(dirs, filepath.Join(dir, "templates"))
+		}
+	}
+	return dirs
+}
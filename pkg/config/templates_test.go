@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_PlanTemplate(t *testing.T) {
+	globalDir := t.TempDir()
+	localDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(globalDir, "templates"), 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(localDir, "templates"), 0o700))
+
+	write := func(dir, name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", name), []byte(content), 0o600))
+	}
+	write(globalDir, "feature.md", "# Global feature\r\n\r\n## Overview\r\n")
+	write(globalDir, "spike.md", "# Spike\n\n## Question\n")
+	write(localDir, "spike.md", "# Local spike\n\n## Question\n")
+	write(localDir, "bugfix.md", "  \n")
+
+	c := &Config{configDir: globalDir, localDir: localDir}
+
+	tests := []struct {
+		name, tmpl, want, wantErr string
+	}{
+		{name: "global overrides embedded", tmpl: "feature", want: "# Global feature\n\n## Overview"},
+		{name: "local overrides global", tmpl: "spike", want: "# Local spike\n\n## Question"},
+		{name: "md suffix accepted", tmpl: "spike.md", want: "# Local spike\n\n## Question"},
+		{name: "empty file falls back to embedded", tmpl: "bugfix", want: "# Fix: <Bug title>"},
+		{name: "unknown lists available", tmpl: "nope", wantErr: `template "nope" not found, available: bugfix, feature, spike`},
+		{name: "path rejected", tmpl: "../config", wantErr: `invalid template name "../config"`},
+		{name: "empty name rejected", tmpl: "", wantErr: `invalid template name ""`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := c.PlanTemplate(tc.tmpl)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, got, tc.want)
+		})
+	}
+}
+
+func TestConfig_PlanTemplates(t *testing.T) {
+	t.Run("embedded only", func(t *testing.T) {
+		names, err := (&Config{}).PlanTemplates()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bugfix", "feature"}, names)
+	})
+
+	t.Run("merges dirs without duplicates", func(t *testing.T) {
+		globalDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(globalDir, "templates", "nested.md"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(globalDir, "templates", "feature.md"), []byte("# F"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(globalDir, "templates", "api.md"), []byte("# A"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(globalDir, "templates", "notes.txt"), []byte("x"), 0o600))
+
+		names, err := (&Config{configDir: globalDir, localDir: filepath.Join(t.TempDir(), "missing")}).PlanTemplates()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api", "bugfix", "feature"}, names)
+	})
+}
@@ -0,0 +1,588 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// sectionHeaderRe matches an ini section header line
+var sectionHeaderRe = regexp.MustCompile(`^\[[\w.-]*\]$`)
+
+// agentRefPattern matches {{agent:name}} references in prompt files
+var agentRefPattern = regexp.MustCompile(`\{\{agent:([a-zA-Z0-9_-]+)\}\}`)
+
+// knownKeys lists every key recognized in the config file
+var knownKeys = []string{
+	"config_version",
+	"agent_backend", "claude_command", "claude_args", "claude_output_format", "gemini_command", "gemini_args",
+	"claude_command_wrapper", "remote_path_map",
+	"ollama_url", "ollama_model", "ollama_signal_prompt",
+	"codex_enabled", "codex_command", "codex_model", "codex_reasoning_effort", "codex_timeout_ms", "codex_sandbox",
+	"external_review_tool", "custom_review_script", "review_first_agents", "review_second_agents",
+	"max_iterations", "iteration_delay_ms", "executor_timeout_ms", "task_retry_count", "codex_retry_count",
+	"claude_timeout_minutes", "codex_timeout_minutes",
+	"retry_base_delay_ms", "retry_max_delay_ms",
+	"stall_detection", "stall_iterations", "task_commit_ratio", "strict_task_verification",
+	"plan_change_action", "cost_per_1k_input", "cost_per_1k_output",
+	"price_input", "price_output",
+	"review_loop_iterations", "plan_loop_iterations",
+	"finalize_enabled", "finalize_commands", "finalize_max_iterations", "finalize_strict",
+	"auto_push", "pr_enabled", "git_sign", "branch_prefix", "branch_template",
+	"commit_msg_add_plan", "commit_msg_move_plan",
+	"pre_task_hook", "post_review_hook", "post_finalize_hook",
+	"context_file", "context_max_bytes",
+	"plans_dir", "move_completed", "completed_dir", "completed_dir_date_layout",
+	"watch_dirs", "watch_depth", "watch_ignore", "watch_prune_hours", "watch_idle_minutes", "web_metrics", "web_websocket",
+	"web_listen", "web_auth_token", "progress_dir", "progress_keep", "progress_json", "progress_max_size_mb", "progress_backups",
+	"claude_error_patterns", "gemini_error_patterns", "codex_error_patterns",
+	"codex_ignore_patterns", "codex_min_severity",
+	"notify_channels", "notify_on_error", "notify_on_complete", "notify_on", "notify_timeout_ms",
+	"notify_telegram_token", "notify_telegram_chat",
+	"notify_slack_token", "notify_slack_channel",
+	"notify_custom_script", "notify_webhook_url", "notify_webhook_urls", "notify_webhook_format",
+	"notify_smtp_host", "notify_smtp_port", "notify_smtp_username", "notify_smtp_password", "notify_smtp_starttls",
+	"notify_email_from", "notify_email_to", "notifier",
+	"theme", "color_task", "color_review", "color_codex", "color_claude_eval", "color_warn",
+	"color_error", "color_signal", "color_timestamp", "color_info",
+}
+
+// KnownKeys returns all keys recognized in the config file, grouped as in the embedded defaults.
+func KnownKeys() []string {
+	return slices.Clone(knownKeys)
+}
+
+// knownPromptFiles lists prompt file names loaded from prompts directories
+var knownPromptFiles = []string{
+	taskPromptFile, reviewFirstPromptFile, reviewSecondPromptFile, codexPromptFile,
+	makePlanPromptFile, finalizePromptFile, finalizeFixPromptFile, customReviewPromptFile, customEvalPromptFile,
+	geminiPromptFile,
+}
+
+// agentBackends lists valid values of agent_backend
+var agentBackends = []string{"claude", "gemini", "ollama"}
+
+// claudeOutputFormats lists valid values of claude_output_format
+var claudeOutputFormats = []string{"stream-json", "text"}
+
+// externalReviewTools lists valid values of external_review_tool
+var externalReviewTools = []string{"codex", "gemini", "custom", "none"}
+
+// planChangeActions lists valid values of plan_change_action
+var planChangeActions = []string{"reload", "ask"}
+
+// codexSeverities lists valid values of codex_min_severity
+var codexSeverities = []string{"low", "medium", "high", "critical"}
+
+// webhookFormats lists valid values of notify_webhook_format
+var webhookFormats = []string{"text", "json"}
+
+// notifiers lists valid values of notifier
+var notifiers = []string{"desktop", "webhook", "none"}
+
+// Issue describes a single problem found in user configuration.
+type Issue struct {
+	File    string // path of the file with the problem
+	Line    int    // 1-based line number, 0 if the problem is not tied to a line
+	Message string
+}
+
+// String formats the issue as file:line: message.
+func (i Issue) String() string {
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s", i.File, i.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Message)
+}
+
+// Validate checks global, repo-root file and repo-local (.ralphex/) configuration without installing defaults.
+// unlike Load, it doesn't stop at the first problem: it reports unknown keys (with suggestions),
+// invalid values, unknown prompt files, {{agent:name}} references, review agent lists naming missing agents
+// and per-call timeouts conflicting with each other.
+// returns an error only if the configuration can't be read at all.
+func Validate(configDir string) ([]Issue, error) {
+	globalDir := configDir
+	if globalDir == "" {
+		globalDir = DefaultConfigDir()
+	}
+	localDir := detectLocalDir()
+	issues, err := validateDirs(globalDir, localDir)
+	if err != nil {
+		return nil, err
+	}
+	repoFile, err := detectRepoFile()
+	if err != nil {
+		return nil, err
+	}
+	found, err := validateRepoFile(repoFile)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, found...)
+	if cfg, loadErr := loadConfigFromDirs(globalDir, localDir, repoFile); loadErr == nil {
+		issues = append(issues, validateTimeouts(cfg)...) // values that don't load are reported above
+	}
+	return issues, nil
+}
+
+// validateTimeouts reports per-call timeouts of the merged configuration that conflict with each other.
+// the limit of a claude call is claude_timeout_minutes, else executor_timeout_ms; of an external review call
+// codex_timeout_minutes, else executor_timeout_ms. codex_timeout_ms is the timeout of codex itself,
+// a longer limit on codex calls never takes effect. each issue names the source of the key to change.
+func validateTimeouts(cfg *Config) []Issue {
+	var issues []Issue
+	report := func(key, msg string) {
+		issues = append(issues, Issue{File: cfg.ValueSource(key), Message: msg})
+	}
+
+	codexReview := cfg.CodexEnabled && (cfg.ExternalReviewTool == "" || cfg.ExternalReviewTool == "codex")
+	if codexReview && cfg.CodexTimeoutMs > 0 {
+		codexLimit := time.Duration(cfg.CodexTimeoutMs) * time.Millisecond
+		switch {
+		case cfg.CodexTimeoutMinutes > 0 && time.Duration(cfg.CodexTimeoutMinutes)*time.Minute > codexLimit:
+			report("codex_timeout_minutes", fmt.Sprintf("codex_timeout_minutes = %d is longer than codex_timeout_ms = %d, "+
+				"codex stops at codex_timeout_ms first; lower codex_timeout_minutes or raise codex_timeout_ms",
+				cfg.CodexTimeoutMinutes, cfg.CodexTimeoutMs))
+		case cfg.CodexTimeoutMinutes == 0 && cfg.ExecutorTimeoutMs > cfg.CodexTimeoutMs:
+			report("executor_timeout_ms", fmt.Sprintf("executor_timeout_ms = %d limits codex calls and is longer than "+
+				"codex_timeout_ms = %d, codex stops at codex_timeout_ms first; set codex_timeout_minutes or raise codex_timeout_ms",
+				cfg.ExecutorTimeoutMs, cfg.CodexTimeoutMs))
+		}
+	}
+
+	if cfg.ExecutorTimeoutMs > 0 && cfg.ClaudeTimeoutMinutes > 0 && cfg.CodexTimeoutMinutes > 0 {
+		report("executor_timeout_ms", fmt.Sprintf("executor_timeout_ms = %d has no effect, claude_timeout_minutes and "+
+			"codex_timeout_minutes take precedence for every call", cfg.ExecutorTimeoutMs))
+	}
+	return issues
+}
+
+// validateRepoFile checks the repo-root config file. .ralphex.conf is checked line by line like
+// other config files, .ralphex.yml as a whole, its problems are not tied to a line.
+func validateRepoFile(path string) ([]Issue, error) {
+	if path == "" {
+		return nil, nil
+	}
+	if filepath.Ext(path) != ".yml" {
+		return validateConfigFile(path)
+	}
+
+	data, err := readConfigData(path)
+	if err != nil {
+		return []Issue{{File: path, Message: strings.TrimPrefix(err.Error(), path+": ")}}, nil
+	}
+	vl := newValuesLoader(defaultsFS)
+	cl := newColorLoader(defaultsFS)
+	var issues []Issue
+	for line := range strings.Lines(string(data)) {
+		name, value, _ := strings.Cut(strings.TrimSpace(line), " = ")
+		if msg := validateValue(vl, cl, name, value); msg != "" {
+			issues = append(issues, Issue{File: path, Message: msg})
+		}
+	}
+	return issues, nil
+}
+
+// validateDirs validates configuration in the given global and local directories.
+// localDir can be empty to skip local config.
+func validateDirs(globalDir, localDir string) ([]Issue, error) {
+	dirs := []string{globalDir}
+	if localDir != "" {
+		dirs = append(dirs, localDir)
+	}
+
+	var issues []Issue
+	for _, dir := range dirs {
+		found, err := validateConfigFile(filepath.Join(dir, "config"))
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, found...)
+
+		found, err = validatePromptFileNames(filepath.Join(dir, "prompts"))
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, found...)
+	}
+
+	found, err := validateAgentRefs(globalDir, localDir)
+	if err != nil {
+		return nil, err
+	}
+	return append(issues, found...), nil
+}
+
+// validateConfigFile checks each key = value line of a config file.
+// values are checked by the same parsers Load uses, one line at a time, so every problem gets its line number.
+// a missing file is not a problem, embedded defaults are used instead.
+func validateConfigFile(path string) ([]Issue, error) {
+	f, err := os.Open(path) //nolint:gosec // path is constructed internally
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open config %s: %w", path, err)
+	}
+	defer f.Close()
+
+	vl := newValuesLoader(defaultsFS)
+	cl := newColorLoader(defaultsFS)
+	var issues []Issue
+	section := "" // current supported section, empty for top-level keys
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if sectionHeaderRe.MatchString(line) || (strings.HasPrefix(line, "[") && section != errorPatternsSection) {
+			section = strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+			if section != errorPatternsSection && section != customAgentsSection {
+				section = ""
+				issues = append(issues, Issue{File: path, Line: lineNum,
+					Message: fmt.Sprintf("section %s is not supported, keys must be at top level", line)})
+			}
+			continue
+		}
+		if section != "" {
+			if msg := validateSectionLine(section, line); msg != "" {
+				issues = append(issues, Issue{File: path, Line: lineNum, Message: msg})
+			}
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			issues = append(issues, Issue{File: path, Line: lineNum, Message: fmt.Sprintf("expected key = value, got %q", line)})
+			continue
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		if !slices.Contains(knownKeys, name) {
+			msg := fmt.Sprintf("unknown key %q", name)
+			if s := suggest(name, knownKeys); s != "" {
+				msg += fmt.Sprintf(", did you mean %q?", s)
+			}
+			issues = append(issues, Issue{File: path, Line: lineNum, Message: msg})
+			continue
+		}
+
+		if msg := validateValue(vl, cl, name, value); msg != "" {
+			issues = append(issues, Issue{File: path, Line: lineNum, Message: msg})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read config %s: %w", path, err)
+	}
+	return issues, nil
+}
+
+// validateValue checks a single known key, returns an empty string if the value is valid.
+func validateValue(vl *valuesLoader, cl *colorLoader, name, value string) string {
+	data := []byte(name + " = " + value)
+	if strings.HasPrefix(name, "color_") || name == "theme" {
+		if _, err := cl.parseColorsFromBytes(data); err != nil {
+			return err.Error()
+		}
+		return ""
+	}
+
+	vals, err := vl.parseValuesFromBytes(data)
+	if err != nil {
+		return err.Error()
+	}
+
+	switch name {
+	case "agent_backend":
+		if value != "" && !slices.Contains(agentBackends, value) {
+			return fmt.Sprintf("invalid agent_backend: %q, expected one of %s", value, strings.Join(agentBackends, ", "))
+		}
+	case "claude_output_format":
+		if value != "" && !slices.Contains(claudeOutputFormats, value) {
+			return fmt.Sprintf("invalid claude_output_format: %q, expected one of %s", value, strings.Join(claudeOutputFormats, ", "))
+		}
+	case "external_review_tool":
+		if value != "" && !slices.Contains(externalReviewTools, value) {
+			return fmt.Sprintf("invalid external_review_tool: %q, expected one of %s", value, strings.Join(externalReviewTools, ", "))
+		}
+	case "plan_change_action":
+		if value != "" && !slices.Contains(planChangeActions, value) {
+			return fmt.Sprintf("invalid plan_change_action: %q, expected one of %s", value, strings.Join(planChangeActions, ", "))
+		}
+	case "codex_min_severity":
+		if value != "" && !slices.Contains(codexSeverities, strings.ToLower(value)) {
+			return fmt.Sprintf("invalid codex_min_severity: %q, expected one of %s", value, strings.Join(codexSeverities, ", "))
+		}
+	case "notify_webhook_format":
+		if value != "" && !slices.Contains(webhookFormats, value) {
+			return fmt.Sprintf("invalid notify_webhook_format: %q, expected one of %s", value, strings.Join(webhookFormats, ", "))
+		}
+	case "notifier":
+		if value != "" && !slices.Contains(notifiers, value) {
+			return fmt.Sprintf("invalid notifier: %q, expected one of %s", value, strings.Join(notifiers, ", "))
+		}
+	case "custom_review_script":
+		return validateScript(name, vals.CustomReviewScript)
+	case "pre_task_hook":
+		return validateScript(name, vals.PreTaskHook)
+	case "post_review_hook":
+		return validateScript(name, vals.PostReviewHook)
+	case "post_finalize_hook":
+		return validateScript(name, vals.PostFinalizeHook)
+	}
+	return ""
+}
+
+// validateSectionLine checks a single line of the [error_patterns] or [custom_agents] section.
+func validateSectionLine(section, line string) string {
+	data := []byte("[" + section + "]\n" + line)
+	var err error
+	switch section {
+	case errorPatternsSection:
+		if strings.HasPrefix(line, "[") {
+			return fmt.Sprintf("error pattern %s starts with [, quote it with backticks", line)
+		}
+		_, err = parseErrorPatterns(data)
+	case customAgentsSection:
+		if !strings.Contains(line, "=") {
+			return fmt.Sprintf("expected name = instruction, got %q", line)
+		}
+		_, err = parseConfigAgents(data)
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// validateScript checks that a configured script path exists and is not a directory.
+func validateScript(name, path string) string {
+	if path == "" {
+		return ""
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Sprintf("invalid %s: %s not found", name, path)
+	}
+	if info.IsDir() {
+		return fmt.Sprintf("invalid %s: %s is a directory", name, path)
+	}
+	return ""
+}
+
+// validatePromptFileNames reports files in a prompts directory that are never loaded,
+// typically a typo in the file name.
+func validatePromptFileNames(dir string) ([]Issue, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read prompts dir %s: %w", dir, err)
+	}
+
+	var issues []Issue
+	for _, entry := range entries {
+		if entry.IsDir() || slices.Contains(knownPromptFiles, entry.Name()) {
+			continue
+		}
+		msg := "unknown prompt file, it is ignored"
+		if s := suggest(entry.Name(), knownPromptFiles); s != "" {
+			msg += fmt.Sprintf(", did you mean %q?", s)
+		}
+		issues = append(issues, Issue{File: filepath.Join(dir, entry.Name()), Message: msg})
+	}
+	return issues, nil
+}
+
+// validateAgentRefs checks that every {{agent:name}} reference in the effective prompts
+// (the same local → global → embedded choice Load makes) points to a loaded agent.
+func validateAgentRefs(globalDir, localDir string) ([]Issue, error) {
+	var localAgentsPath, localPromptsPath string
+	if localDir != "" {
+		localAgentsPath = filepath.Join(localDir, "agents")
+		localPromptsPath = filepath.Join(localDir, "prompts")
+	}
+	agents, err := newAgentLoader(defaultsFS).Load(localAgentsPath, filepath.Join(globalDir, "agents"))
+	if err != nil {
+		return nil, fmt.Errorf("load agents: %w", err)
+	}
+	names := make([]string, 0, len(agents))
+	for _, a := range agents {
+		names = append(names, a.Name)
+	}
+	names = append(names, configAgentNames(globalDir, localDir)...)
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	var issues []Issue
+	var setKeys []string
+	for _, dir := range []string{globalDir, localDir} {
+		if dir != "" {
+			found, keys := validateReviewAgents(filepath.Join(dir, "config"), names)
+			issues = append(issues, found...)
+			setKeys = append(setKeys, keys...)
+		}
+	}
+
+	// {{agents}} of the review prompts expands to the default agents unless the list is set
+	defaultAgents := map[string]struct {
+		key    string
+		agents []string
+	}{
+		reviewFirstPromptFile:  {"review_first_agents", defaultReviewFirstAgents},
+		reviewSecondPromptFile: {"review_second_agents", defaultReviewSecondAgents},
+	}
+
+	pl := newPromptLoader(defaultsFS)
+	for _, filename := range knownPromptFiles {
+		source, content, err := effectivePrompt(pl, localPromptsPath, filepath.Join(globalDir, "prompts"), filename)
+		if err != nil {
+			return nil, err
+		}
+		for i, line := range strings.Split(content, "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue // comment lines are stripped before use
+			}
+			if def, ok := defaultAgents[filename]; ok && strings.Contains(line, "{{agents}}") && !slices.Contains(setKeys, def.key) {
+				for _, agent := range def.agents {
+					if !slices.Contains(names, agent) {
+						issues = append(issues, Issue{File: source, Line: i + 1,
+							Message: fmt.Sprintf("agent %q of the default %s not found, set %s", agent, def.key, def.key)})
+					}
+				}
+			}
+			for _, m := range agentRefPattern.FindAllStringSubmatch(line, -1) {
+				if slices.Contains(names, m[1]) {
+					continue
+				}
+				msg := fmt.Sprintf("agent %q not found", m[1])
+				if s := suggest(m[1], names); s != "" {
+					msg += fmt.Sprintf(", did you mean %q?", s)
+				}
+				issues = append(issues, Issue{File: source, Line: i + 1, Message: msg})
+			}
+		}
+	}
+	return issues, nil
+}
+
+// validateReviewAgents reports agents of review_first_agents and review_second_agents in a config file
+// that are neither loaded agent files nor [custom_agents] entries, and returns which of the keys are set.
+// unreadable files are skipped, validateConfigFile reports them.
+func validateReviewAgents(path string, names []string) (issues []Issue, setKeys []string) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is constructed internally
+	if err != nil {
+		return nil, nil
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			break // keys of sections are names and patterns, not config keys
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if key = strings.TrimSpace(key); !ok || (key != "review_first_agents" && key != "review_second_agents") {
+			continue
+		}
+		setKeys = append(setKeys, key)
+		for agent := range strings.SplitSeq(value, ",") {
+			if agent = strings.TrimSpace(agent); agent == "" || slices.Contains(names, agent) {
+				continue
+			}
+			msg := fmt.Sprintf("unknown agent %q in %s, available: %s", agent, key, strings.Join(names, ", "))
+			issues = append(issues, Issue{File: path, Line: i + 1, Message: msg})
+		}
+	}
+	return issues, setKeys
+}
+
+// configAgentNames returns names of agents defined in the [custom_agents] section of global and local config.
+// unreadable files and invalid entries are skipped, validateConfigFile reports them.
+func configAgentNames(globalDir, localDir string) []string {
+	var names []string
+	for _, dir := range []string{globalDir, localDir} {
+		if dir == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "config")) //nolint:gosec // path is constructed internally
+		if err != nil {
+			continue
+		}
+		agents, err := parseConfigAgents(data)
+		if err != nil {
+			continue
+		}
+		for _, a := range agents {
+			names = append(names, a.Name)
+		}
+	}
+	return names
+}
+
+// effectivePrompt returns the source and raw content of the prompt file Load would use.
+// embedded prompts are reported with an "embedded:" source prefix.
+func effectivePrompt(pl *promptLoader, localDir, globalDir, filename string) (source, content string, err error) {
+	for _, dir := range []string{localDir, globalDir} {
+		if dir == "" {
+			continue
+		}
+		path := filepath.Join(dir, filename)
+		loaded, err := pl.loadPromptFile(path)
+		if err != nil {
+			return "", "", err
+		}
+		if loaded == "" {
+			continue
+		}
+		data, err := os.ReadFile(path) //nolint:gosec // path is constructed internally
+		if err != nil {
+			return "", "", fmt.Errorf("read prompt file %s: %w", path, err)
+		}
+		return path, normalizeCRLF(string(data)), nil
+	}
+
+	data, err := defaultsFS.ReadFile("defaults/prompts/" + filename)
+	if err != nil {
+		return "", "", fmt.Errorf("read embedded prompt %s: %w", filename, err)
+	}
+	return "embedded:" + filename, normalizeCRLF(string(data)), nil
+}
+
+// suggest returns the candidate closest to name, or empty string if none is close enough to be a likely typo.
+func suggest(name string, candidates []string) string {
+	best, bestDist := "", len(name)/3+2
+	for _, c := range candidates {
+		if d := levenshtein(name, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,361 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate_EmbeddedDefaultsAreClean(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, DumpDefaults(dir))
+
+	issues, err := validateDirs(dir, "")
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestValidate_KnownKeysCoverDefaults(t *testing.T) {
+	data, err := defaultsFS.ReadFile("defaults/config")
+	require.NoError(t, err)
+
+	// every key documented in the embedded config, commented out or not, must be known;
+	// entries of the sections at the end are names and patterns, not keys
+	topLevel, _, _ := strings.Cut(string(data), "\n# ["+errorPatternsSection+"]")
+	keyRe := regexp.MustCompile(`(?m)^#?\s*([a-z_]+)\s*=`)
+	for _, m := range keyRe.FindAllStringSubmatch(topLevel, -1) {
+		assert.Contains(t, knownKeys, m[1])
+	}
+}
+
+func TestValidate_ConfigFile(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "review.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"), 0o700))
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "valid", content: "# comment\n\nclaude_command = claude\ncolor_task = #00ff00\ncustom_review_script = " + script + "\n"},
+		{name: "unknown key with suggestion", content: "claude_command = claude\niteraton_delay_ms = 100\n",
+			want: []string{`:2: unknown key "iteraton_delay_ms", did you mean "iteration_delay_ms"?`}},
+		{name: "unknown key without suggestion", content: "something_else = 1\n", want: []string{`:1: unknown key "something_else"`}},
+		{name: "non-numeric delay", content: "iteration_delay_ms = soon\n", want: []string{":1: invalid iteration_delay_ms"}},
+		{name: "negative timeout", content: "codex_timeout_ms = -1\n", want: []string{":1: invalid codex_timeout_ms: must be non-negative"}},
+		{name: "negative progress keep", content: "progress_keep = -1\n", want: []string{":1: invalid progress_keep: must be non-negative"}},
+		{name: "negative watch depth", content: "watch_depth = -1\n",
+			want: []string{":1: invalid watch_depth: must be non-negative"}},
+		{name: "negative watch prune hours", content: "watch_prune_hours = -1\n",
+			want: []string{":1: invalid watch_prune_hours: must be non-negative"}},
+		{name: "negative watch idle minutes", content: "watch_idle_minutes = -1\n",
+			want: []string{":1: invalid watch_idle_minutes: must be non-negative"}},
+		{name: "negative progress max size", content: "progress_max_size_mb = -5\n",
+			want: []string{":1: invalid progress_max_size_mb: must be non-negative"}},
+		{name: "zero progress backups", content: "progress_backups = 0\n", want: []string{":1: invalid progress_backups: must be at least 1"}},
+		{name: "stall iterations too low", content: "stall_iterations = 1\n",
+			want: []string{":1: invalid stall_iterations: must be at least 2"}},
+		{name: "negative token cost", content: "cost_per_1k_input = 0.003\ncost_per_1k_output = -1\n",
+			want: []string{":2: invalid cost_per_1k_output: must be non-negative"}},
+		{name: "bad bool", content: "codex_enabled = maybe\n", want: []string{":1: invalid codex_enabled"}},
+		{name: "bad git_sign", content: "git_sign = sometimes\n", want: []string{":1: invalid git_sign"}},
+		{name: "bad branch_prefix", content: "branch_prefix = feature~1/\n", want: []string{":1: invalid branch_prefix"}},
+		{name: "bad branch_template", content: "branch_template = {name}\n", want: []string{":1: invalid branch_template"}},
+		{name: "bad commit_msg_move_plan", content: "commit_msg_move_plan = done {slug}\n",
+			want: []string{":1: invalid commit_msg_move_plan"}},
+		{name: "bad completed_dir", content: "completed_dir = docs/done\n", want: []string{":1: invalid completed_dir"}},
+		{name: "bad completed_dir_date_layout", content: "completed_dir_date_layout = monthly\n",
+			want: []string{":1: invalid completed_dir_date_layout"}},
+		{name: "negative codex_retry_count", content: "codex_retry_count = -1\n",
+			want: []string{":1: invalid codex_retry_count: must be non-negative"}},
+		{name: "bad web_metrics", content: "web_metrics = often\n", want: []string{":1: invalid web_metrics"}},
+		{name: "bad web_websocket", content: "web_websocket = maybe\n", want: []string{":1: invalid web_websocket"}},
+		{name: "malformed color", content: "\ncolor_task = 00ff00\n", want: []string{":2: invalid color_task: hex color must start with #"}},
+		{name: "unknown theme", content: "theme = neon\n",
+			want: []string{`:1: invalid theme: "neon", expected one of dark, light, mono, solarized`}},
+		{name: "bad external tool", content: "external_review_tool = copilot\n",
+			want: []string{`:1: invalid external_review_tool: "copilot", expected one of codex, gemini, custom, none`}},
+		{name: "bad claude output format", content: "claude_output_format = json\n",
+			want: []string{`:1: invalid claude_output_format: "json", expected one of stream-json, text`}},
+		{name: "bad remote path map", content: "remote_path_map = /home/me/src\n",
+			want: []string{`:1: invalid remote_path_map: entry "/home/me/src", expected local=remote`}},
+		{name: "bad agent backend", content: "agent_backend = qwen\n",
+			want: []string{`:1: invalid agent_backend: "qwen", expected one of claude, gemini, ollama`}},
+		{name: "bad plan change action", content: "plan_change_action = pause\n",
+			want: []string{`:1: invalid plan_change_action: "pause", expected one of reload, ask`}},
+		{name: "bad codex min severity", content: "codex_min_severity = nitpick\n",
+			want: []string{`:1: invalid codex_min_severity: "nitpick", expected one of low, medium, high, critical`}},
+		{name: "bad codex ignore pattern", content: "codex_ignore_patterns = (?i)rename,foo(\n",
+			want: []string{":1: invalid codex_ignore_patterns: error parsing regexp"}},
+		{name: "bad webhook format", content: "notify_webhook_format = yaml\n",
+			want: []string{`:1: invalid notify_webhook_format: "yaml", expected one of text, json`}},
+		{name: "bad notifier", content: "notifier = pager\n",
+			want: []string{`:1: invalid notifier: "pager", expected one of desktop, webhook, none`}},
+		{name: "missing review script", content: "custom_review_script = /nonexistent/review.sh\n",
+			want: []string{":1: invalid custom_review_script: /nonexistent/review.sh not found"}},
+		{name: "missing hook script", content: "pre_task_hook = " + script + "\npost_review_hook = /nonexistent/lint.sh\n",
+			want: []string{":2: invalid post_review_hook: /nonexistent/lint.sh not found"}},
+		{name: "section header", content: "[main]\nclaude_command = claude\n", want: []string{":1: section [main] is not supported"}},
+		{name: "error patterns section", content: "claude_command = claude\n[error_patterns]\nAPI Error: (5\\d\\d) = claude /usage\n" +
+			"`[Oo]verloaded` = open https://status.anthropic.com\n(?i)session expired =\n"},
+		{name: "invalid error pattern", content: "[error_patterns]\nok = x\nfoo(bar = claude /usage\n",
+			want: []string{`:3: invalid error_patterns entry "foo(bar": error parsing regexp`}},
+		{name: "unquoted bracket error pattern", content: "[error_patterns]\n[Oo]verloaded = claude /usage\n",
+			want: []string{":2: error pattern [Oo]verloaded = claude /usage starts with [, quote it with backticks"}},
+		{name: "section after error patterns", content: "[error_patterns]\nquota = x\n[main]\n",
+			want: []string{":3: section [main] is not supported"}},
+		{name: "custom agents section", content: "[error_patterns]\nquota = x\n[custom_agents]\napi = check = handling\n"},
+		{name: "invalid custom agent", content: "[custom_agents]\napi =\nbad name = x\nno instruction\n",
+			want: []string{`:2: invalid custom_agents entry "api": instruction is empty`, `:3: invalid custom_agents name "bad name"`,
+				`:4: expected name = instruction, got "no instruction"`}},
+		{name: "no equals sign", content: "claude_command claude\n", want: []string{`:1: expected key = value, got "claude_command claude"`}},
+		{name: "multiple problems", content: "codex_enabeld = true\ntask_retry_count = x\n",
+			want: []string{`:1: unknown key "codex_enabeld", did you mean "codex_enabled"?`, ":2: invalid task_retry_count"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			issues, err := validateConfigFile(path)
+			require.NoError(t, err)
+			require.Len(t, issues, len(tc.want))
+			for i, want := range tc.want {
+				assert.Contains(t, issues[i].String(), path+want)
+			}
+		})
+	}
+}
+
+func TestValidate_MissingConfigFile(t *testing.T) {
+	issues, err := validateConfigFile(filepath.Join(t.TempDir(), "config"))
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestValidate_PromptFiles(t *testing.T) {
+	globalDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(globalDir, "prompts"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "prompts", "reviw_first.txt"), []byte("review"), 0o600))
+
+	issues, err := validateDirs(globalDir, "")
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, filepath.Join(globalDir, "prompts", "reviw_first.txt")+
+		`: unknown prompt file, it is ignored, did you mean "review_first.txt"?`, issues[0].String())
+}
+
+func TestValidate_AgentReferences(t *testing.T) {
+	t.Run("missing agent in user prompt", func(t *testing.T) {
+		globalDir := t.TempDir()
+		promptsDir := filepath.Join(globalDir, "prompts")
+		require.NoError(t, os.MkdirAll(promptsDir, 0o750))
+		content := "# {{agent:ignored}} in a comment\nreview the code\n{{agent:quality}}\n{{agent:qualty}} and {{agent:unknown}}\n"
+		require.NoError(t, os.WriteFile(filepath.Join(promptsDir, "review_first.txt"), []byte(content), 0o600))
+
+		issues, err := validateDirs(globalDir, "")
+		require.NoError(t, err)
+		require.Len(t, issues, 2)
+		path := filepath.Join(promptsDir, "review_first.txt")
+		assert.Equal(t, path+`:4: agent "qualty" not found, did you mean "quality"?`, issues[0].String())
+		assert.Equal(t, path+`:4: agent "unknown" not found`, issues[1].String())
+	})
+
+	t.Run("user agents replace embedded ones", func(t *testing.T) {
+		globalDir := t.TempDir()
+		agentsDir := filepath.Join(globalDir, "agents")
+		require.NoError(t, os.MkdirAll(agentsDir, 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(agentsDir, "quality.txt"), []byte("check quality"), 0o600))
+
+		issues, err := validateDirs(globalDir, "")
+		require.NoError(t, err)
+		require.NotEmpty(t, issues)
+		for _, issue := range issues {
+			assert.Contains(t, issue.File, "embedded:")
+			assert.NotContains(t, issue.Message, `"quality"`)
+		}
+	})
+
+	t.Run("local prompt takes precedence", func(t *testing.T) {
+		globalDir, localDir := t.TempDir(), t.TempDir()
+		for _, dir := range []string{globalDir, localDir} {
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "prompts"), 0o750))
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(globalDir, "prompts", "task.txt"), []byte("{{agent:nope}}"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(localDir, "prompts", "task.txt"), []byte("{{agent:gone}}"), 0o600))
+
+		issues, err := validateDirs(globalDir, localDir)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, filepath.Join(localDir, "prompts", "task.txt")+`:1: agent "gone" not found`, issues[0].String())
+	})
+}
+
+func TestValidate_ConfigAgentReferences(t *testing.T) {
+	globalDir, localDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(localDir, "prompts"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"), []byte("[custom_agents]\napi = check the api\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte("[custom_agents]\nsql = check queries\n"), 0o600))
+	content := "{{agent:api}} {{agent:sql}} {{agent:apx}}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "prompts", "review_first.txt"), []byte(content), 0o600))
+
+	issues, err := validateDirs(globalDir, localDir)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, filepath.Join(localDir, "prompts", "review_first.txt")+`:1: agent "apx" not found, did you mean "api"?`,
+		issues[0].String())
+}
+
+func TestValidate_ReviewAgents(t *testing.T) {
+	t.Run("unknown agent in config", func(t *testing.T) {
+		globalDir, localDir := t.TempDir(), t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"),
+			[]byte("# review_first_agents = nope\nreview_first_agents = quality, api, qualty\n[custom_agents]\napi = check the api\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte("review_second_agents = testing\n"), 0o600))
+
+		issues, err := validateDirs(globalDir, localDir)
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, filepath.Join(globalDir, "config")+`:2: unknown agent "qualty" in review_first_agents, available: `+
+			"api, documentation, implementation, quality, simplification, testing", issues[0].String())
+	})
+
+	t.Run("default agents missing from user agents", func(t *testing.T) {
+		globalDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(globalDir, "agents"), 0o750))
+		for _, name := range []string{"quality", "testing"} {
+			require.NoError(t, os.WriteFile(filepath.Join(globalDir, "agents", name+".txt"), []byte("check "+name), 0o600))
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"), []byte("review_second_agents = quality\n"), 0o600))
+
+		issues, err := validateDirs(globalDir, "")
+		require.NoError(t, err)
+		msgs := make([]string, 0, len(issues))
+		for _, issue := range issues {
+			assert.Equal(t, "embedded:review_first.txt", issue.File)
+			msgs = append(msgs, issue.Message)
+		}
+		assert.Equal(t, []string{
+			`agent "implementation" of the default review_first_agents not found, set review_first_agents`,
+			`agent "simplification" of the default review_first_agents not found, set review_first_agents`,
+			`agent "documentation" of the default review_first_agents not found, set review_first_agents`,
+		}, msgs, "the second review list is set, its defaults are not checked")
+	})
+}
+
+func TestValidate_LocalConfig(t *testing.T) {
+	globalDir, localDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"), []byte("plans_dri = docs\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte("color_info = #zzzzzz\n"), 0o600))
+
+	issues, err := validateDirs(globalDir, localDir)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, filepath.Join(globalDir, "config"), issues[0].File)
+	assert.Contains(t, issues[0].Message, `did you mean "plans_dir"?`)
+	assert.Equal(t, filepath.Join(localDir, "config"), issues[1].File)
+	assert.Contains(t, issues[1].Message, "invalid color_info")
+}
+
+func TestValidate_Timeouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantKey string // key the issue is reported for, empty for no issue
+		wantMsg string
+	}{
+		{name: "defaults"},
+		{name: "executor limit within codex timeout", config: "executor_timeout_ms = 600000\n"},
+		{name: "executor limit longer than codex timeout", config: "executor_timeout_ms = 7200000\n",
+			wantKey: "executor_timeout_ms", wantMsg: "executor_timeout_ms = 7200000 limits codex calls and is longer than codex_timeout_ms = 3600000"},
+		{name: "codex minutes within codex timeout", config: "codex_timeout_minutes = 30\n"},
+		{name: "codex minutes equal to codex timeout", config: "codex_timeout_minutes = 60\n"},
+		{name: "codex minutes longer than codex timeout", config: "codex_timeout_minutes = 90\n",
+			wantKey: "codex_timeout_minutes", wantMsg: "codex_timeout_minutes = 90 is longer than codex_timeout_ms = 3600000"},
+		{name: "codex minutes take precedence over long executor limit",
+			config: "executor_timeout_ms = 7200000\ncodex_timeout_minutes = 30\n"},
+		{name: "raised codex timeout", config: "codex_timeout_minutes = 90\ncodex_timeout_ms = 7200000\n"},
+		{name: "codex limits ignored for gemini", config: "codex_timeout_minutes = 90\nexternal_review_tool = gemini\n"},
+		{name: "codex limits ignored with codex disabled", config: "executor_timeout_ms = 7200000\ncodex_enabled = false\n"},
+		{name: "claude minutes with executor limit", config: "claude_timeout_minutes = 30\nexecutor_timeout_ms = 600000\n"},
+		{name: "executor limit overridden for every call",
+			config:  "claude_timeout_minutes = 30\ncodex_timeout_minutes = 30\nexecutor_timeout_ms = 600000\n",
+			wantKey: "executor_timeout_ms", wantMsg: "executor_timeout_ms = 600000 has no effect"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			globalDir := t.TempDir()
+			configPath := filepath.Join(globalDir, "config")
+			require.NoError(t, os.WriteFile(configPath, []byte(tc.config), 0o600))
+			cfg, err := loadConfigFromDirs(globalDir, "", "")
+			require.NoError(t, err)
+
+			issues := validateTimeouts(cfg)
+			if tc.wantKey == "" {
+				assert.Empty(t, issues)
+				return
+			}
+			require.Len(t, issues, 1)
+			assert.Equal(t, configPath, issues[0].File, "reported for the file setting %s", tc.wantKey)
+			assert.Contains(t, issues[0].Message, tc.wantMsg)
+		})
+	}
+
+	t.Run("reported for the environment", func(t *testing.T) {
+		t.Setenv("RALPHEX_CODEX_TIMEOUT_MINUTES", "90")
+		cfg, err := loadConfigFromDirs(t.TempDir(), "", "")
+		require.NoError(t, err)
+		issues := validateTimeouts(cfg)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "env RALPHEX_CODEX_TIMEOUT_MINUTES", issues[0].File)
+	})
+
+	t.Run("env executor limit longer than a lowered codex timeout", func(t *testing.T) {
+		globalDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"), []byte("codex_timeout_ms = 60000\n"), 0o600))
+		t.Setenv("RALPHEX_EXECUTOR_TIMEOUT_MS", "120000")
+		cfg, err := loadConfigFromDirs(globalDir, "", "")
+		require.NoError(t, err)
+		issues := validateTimeouts(cfg)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "env RALPHEX_EXECUTOR_TIMEOUT_MS", issues[0].File)
+	})
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{name: "claude_comand", want: "claude_command"},
+		{name: "CODEX_ENABLED", want: ""},
+		{name: "colour_task", want: "color_task"},
+		{name: "foo", want: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, suggest(tc.name, knownKeys))
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"same", "same", 0},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, levenshtein(tc.a, tc.b), "%s -> %s", tc.a, tc.b)
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"embed"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"gopkg.in/ini.v1"
 )
@@ -14,28 +18,127 @@ import (
 // set in config. This allows distinguishing explicit false/0 from "not set", enabling
 // proper merge behavior where local config can override global config with zero values.
 type Values struct {
-	ClaudeCommand        string
-	ClaudeArgs           string
-	ClaudeErrorPatterns  []string // patterns to detect in claude output (e.g., rate limit messages)
-	CodexEnabled         bool
-	CodexEnabledSet      bool // tracks if codex_enabled was explicitly set
-	CodexCommand         string
-	CodexModel           string
-	CodexReasoningEffort string
-	CodexTimeoutMs       int
-	CodexTimeoutMsSet    bool // tracks if codex_timeout_ms was explicitly set
-	CodexSandbox         string
-	CodexErrorPatterns   []string // patterns to detect in codex output (e.g., rate limit messages)
-	ExternalReviewTool   string   // "codex", "custom", or "none"
-	CustomReviewScript   string   // path to custom review script (when ExternalReviewTool = "custom")
-	IterationDelayMs     int
-	IterationDelayMsSet  bool // tracks if iteration_delay_ms was explicitly set
-	TaskRetryCount       int
-	TaskRetryCountSet    bool // tracks if task_retry_count was explicitly set
-	FinalizeEnabled      bool
-	FinalizeEnabledSet   bool // tracks if finalize_enabled was explicitly set
-	PlansDir             string
-	WatchDirs            []string // directories to watch for progress files
+	AgentBackend            string // "claude", "gemini" or "ollama"
+	ClaudeCommand           string
+	ClaudeArgs              string
+	ClaudeOutputFormat      string            // "stream-json" or "text", empty keeps --output-format of claude_args
+	ClaudeErrorPatterns     []string          // patterns to detect in claude output (e.g., rate limit messages)
+	ClaudeCommandWrapper    string            // runs claude and codex through this command, e.g. "ssh devbox --"
+	RemotePathMap           map[string]string // local path prefix -> remote path prefix, comma-separated local=remote in config
+	GeminiCommand           string
+	GeminiArgs              string
+	GeminiErrorPatterns     []string // patterns to detect in gemini output (e.g., quota messages)
+	OllamaURL               string
+	OllamaModel             string
+	OllamaSignalPrompt      bool
+	OllamaSignalPromptSet   bool // tracks if ollama_signal_prompt was explicitly set
+	CodexEnabled            bool
+	CodexEnabledSet         bool // tracks if codex_enabled was explicitly set
+	CodexCommand            string
+	CodexModel              string
+	CodexReasoningEffort    string
+	CodexTimeoutMs          int
+	CodexTimeoutMsSet       bool // tracks if codex_timeout_ms was explicitly set
+	CodexSandbox            string
+	CodexErrorPatterns      []string       // patterns to detect in codex output (e.g., rate limit messages)
+	CodexIgnorePatterns     []string       // regexes of codex findings dropped before claude evaluation
+	ErrorPatterns           []ErrorPattern // regexes with help commands from the [error_patterns] section
+	ConfigAgents            []CustomAgent  // agents with inline instructions from the [custom_agents] section
+	CodexMinSeverity        string         // codex findings tagged below this severity are dropped
+	ExternalReviewTool      string         // "codex", "gemini", "custom", or "none"
+	CustomReviewScript      string         // path to custom review script (when ExternalReviewTool = "custom")
+	ReviewFirstAgents       []string       // agents {{agents}} expands to in the first review prompt
+	ReviewSecondAgents      []string       // agents {{agents}} expands to in the second review prompt
+	MaxIterations           int
+	MaxIterationsSet        bool // tracks if max_iterations was explicitly set
+	IterationDelayMs        int
+	IterationDelayMsSet     bool // tracks if iteration_delay_ms was explicitly set
+	ExecutorTimeoutMs       int
+	ExecutorTimeoutMsSet    bool // tracks if executor_timeout_ms was explicitly set
+	ClaudeTimeoutMinutes    int
+	ClaudeTimeoutMinutesSet bool // tracks if claude_timeout_minutes was explicitly set
+	CodexTimeoutMinutes     int
+	CodexTimeoutMinutesSet  bool // tracks if codex_timeout_minutes was explicitly set
+	ReviewLoopIterations    int
+	ReviewLoopIterationsSet bool // tracks if review_loop_iterations was explicitly set
+	PlanLoopIterations      int
+	PlanLoopIterationsSet   bool // tracks if plan_loop_iterations was explicitly set
+	TaskRetryCount          int
+	TaskRetryCountSet       bool // tracks if task_retry_count was explicitly set
+	CodexRetryCount         int
+	CodexRetryCountSet      bool // tracks if codex_retry_count was explicitly set
+	RetryBaseDelayMs        int
+	RetryBaseDelayMsSet     bool // tracks if retry_base_delay_ms was explicitly set
+	RetryMaxDelayMs         int
+	RetryMaxDelayMsSet      bool // tracks if retry_max_delay_ms was explicitly set
+	StallDetection          bool
+	StallDetectionSet       bool // tracks if stall_detection was explicitly set
+	StallIterations         int
+	StallIterationsSet      bool // tracks if stall_iterations was explicitly set
+	TaskCommitRatio         float64
+	TaskCommitRatioSet      bool // tracks if task_commit_ratio was explicitly set
+	StrictTaskVerification  bool
+	StrictTaskVerifySet     bool   // tracks if strict_task_verification was explicitly set
+	PlanChangeAction        string // "reload" or "ask", empty means not set
+	CostPer1kInput          float64
+	CostPer1kInputSet       bool // tracks if cost_per_1k_input was explicitly set
+	CostPer1kOutput         float64
+	CostPer1kOutputSet      bool // tracks if cost_per_1k_output was explicitly set
+	PriceInput              float64
+	PriceInputSet           bool // tracks if price_input was explicitly set
+	PriceOutput             float64
+	PriceOutputSet          bool // tracks if price_output was explicitly set
+	FinalizeEnabled         bool
+	FinalizeEnabledSet      bool     // tracks if finalize_enabled was explicitly set
+	FinalizeCommands        []string // shell commands checked by the finalize step
+	FinalizeIterations      int
+	FinalizeIterationsSet   bool // tracks if finalize_max_iterations was explicitly set
+	FinalizeStrict          bool
+	FinalizeStrictSet       bool // tracks if finalize_strict was explicitly set
+	AutoPush                bool
+	AutoPushSet             bool // tracks if auto_push was explicitly set
+	GitSign                 bool
+	GitSignSet              bool   // tracks if git_sign was explicitly set
+	BranchPrefix            string // prepended to branch names derived from plan files
+	BranchTemplate          string // branch name of a plan with {slug}, {date} and {user} placeholders
+	PREnabled               bool
+	PREnabledSet            bool   // tracks if pr_enabled was explicitly set
+	CommitMsgAddPlan        string // commit message of a plan file with {branch}, {plan} and {date} placeholders
+	CommitMsgMovePlan       string // commit message of a finished plan move with the same placeholders
+	PreTaskHook             string // path to script run before the task phase (tilde-expanded)
+	PostReviewHook          string // path to script run after the review phases (tilde-expanded)
+	PostFinalizeHook        string // path to script run after the finalize step (tilde-expanded)
+	ContextFile             string // project context file injected into prompts as {{PROJECT_CONTEXT}} (tilde-expanded)
+	ContextMaxBytes         int
+	ContextMaxBytesSet      bool // tracks if context_max_bytes was explicitly set
+	PlansDir                string
+	CompletedDir            string // directory in plans_dir finished plans are moved to
+	CompletedDirDateLayout  string // time layout of a dated subdirectory in completed_dir, none if empty
+	MoveCompleted           bool
+	MoveCompletedSet        bool   // tracks if move_completed was explicitly set
+	ProgressDir             string // directory for progress files (tilde-expanded)
+	ProgressKeep            int
+	ProgressKeepSet         bool // tracks if progress_keep was explicitly set
+	ProgressJSON            bool
+	ProgressJSONSet         bool // tracks if progress_json was explicitly set
+	ProgressMaxSizeMB       int
+	ProgressMaxSizeMBSet    bool // tracks if progress_max_size_mb was explicitly set
+	ProgressBackups         int
+	ProgressBackupsSet      bool     // tracks if progress_backups was explicitly set
+	WatchDirs               []string // directories or glob patterns to watch for progress files
+	WatchDepth              int
+	WatchDepthSet           bool     // tracks if watch_depth was explicitly set
+	WatchIgnore             []string // directory names skipped while searching watched directories
+	WatchPruneHours         int
+	WatchPruneHoursSet      bool // tracks if watch_prune_hours was explicitly set
+	WatchIdleMinutes        int
+	WatchIdleMinutesSet     bool // tracks if watch_idle_minutes was explicitly set
+	WebMetrics              bool
+	WebMetricsSet           bool // tracks if web_metrics was explicitly set
+	WebWebSocket            bool
+	WebWebSocketSet         bool   // tracks if web_websocket was explicitly set
+	WebListen               string // address the web dashboard listens on
+	WebAuthToken            string // token required to access the web dashboard
 
 	// notification settings
 	NotifyChannels        []string // channels to use: telegram, email, webhook, slack, custom
@@ -62,9 +165,30 @@ type Values struct {
 	NotifyEmailToSet      bool     // tracks if notify_email_to was explicitly set (allows empty to disable)
 	NotifyWebhookURLs     []string // comma-separated in config
 	NotifyWebhookURLsSet  bool     // tracks if notify_webhook_urls was explicitly set (allows empty to disable)
+	NotifyWebhookFormat   string   // "text" or "json" payload for webhooks
+	NotifyWebhookURL      string   // single webhook URL, shortcut enabling a json webhook channel
 	NotifyCustomScript    string   // path to custom notification script (tilde-expanded)
+	Notifier              string   // "desktop" or "webhook" event notifier, empty for none
 }
 
+// BranchTemplatePlaceholders are the placeholders allowed in branch_template.
+var BranchTemplatePlaceholders = []string{"{slug}", "{date}", "{user}"}
+
+// CommitMessagePlaceholders are the placeholders allowed in commit_msg_add_plan and commit_msg_move_plan.
+var CommitMessagePlaceholders = []string{"{branch}", "{plan}", "{date}"}
+
+// branchPlaceholderRe matches a placeholder in branch_template and commit message templates.
+var branchPlaceholderRe = regexp.MustCompile(`\{[^{}]*\}`)
+
+// config sections, all other keys are top-level
+const (
+	errorPatternsSection = "error_patterns" // user-defined error patterns
+	customAgentsSection  = "custom_agents"  // agents defined inline, name = instruction
+)
+
+// agentNameRe matches a valid agent name, the same characters {{agent:name}} references allow.
+var agentNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
 // valuesLoader implements ValuesLoader with embedded filesystem fallback.
 type valuesLoader struct {
 	embedFS embed.FS
@@ -75,11 +199,12 @@ func newValuesLoader(embedFS embed.FS) *valuesLoader {
 	return &valuesLoader{embedFS: embedFS}
 }
 
-// Load loads values from config files with fallback chain: local → global → embedded.
-// localConfigPath and globalConfigPath are full paths to config files (not directories).
+// Load loads values from config files with fallback chain: local → repo-root file → global → embedded.
+// localConfigPath, repoConfigPath and globalConfigPath are full paths to config files (not directories),
+// repoConfigPath may be a .ralphex.yml (see readConfigData).
 //
 //nolint:dupl // intentional structural similarity with colorLoader.Load
-func (vl *valuesLoader) Load(localConfigPath, globalConfigPath string) (Values, error) {
+func (vl *valuesLoader) Load(localConfigPath, repoConfigPath, globalConfigPath string) (Values, error) {
 	// start with embedded defaults
 	embedded, err := vl.parseValuesFromEmbedded()
 	if err != nil {
@@ -92,16 +217,30 @@ func (vl *valuesLoader) Load(localConfigPath, globalConfigPath string) (Values,
 		return Values{}, fmt.Errorf("parse global config: %w", err)
 	}
 
+	// parse repo-root config file if exists
+	repo, err := vl.parseValuesFromRepoFile(repoConfigPath)
+	if err != nil {
+		return Values{}, fmt.Errorf("parse repo config: %w", err)
+	}
+
 	// parse local config if exists
 	local, err := vl.parseValuesFromFile(localConfigPath)
 	if err != nil {
 		return Values{}, fmt.Errorf("parse local config: %w", err)
 	}
 
-	// merge: embedded → global → local (local wins)
+	// parse RALPHEX_* environment overrides
+	env, err := vl.parseValuesFromEnv()
+	if err != nil {
+		return Values{}, fmt.Errorf("parse environment: %w", err)
+	}
+
+	// merge: embedded → global → repo → local → environment (environment wins)
 	result := embedded
 	result.mergeFrom(&global)
+	result.mergeFrom(&repo)
 	result.mergeFrom(&local)
+	result.mergeFrom(&env)
 
 	return result, nil
 }
@@ -154,13 +293,49 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 	var values Values
 	section := cfg.Section("") // default section (no section header)
 
-	// claude settings
+	// agent settings
+	if key, err := section.GetKey("agent_backend"); err == nil {
+		values.AgentBackend = key.String()
+	}
 	if key, err := section.GetKey("claude_command"); err == nil {
 		values.ClaudeCommand = key.String()
 	}
 	if key, err := section.GetKey("claude_args"); err == nil {
 		values.ClaudeArgs = key.String()
 	}
+	if key, err := section.GetKey("claude_output_format"); err == nil {
+		values.ClaudeOutputFormat = key.String()
+	}
+	if key, err := section.GetKey("claude_command_wrapper"); err == nil {
+		values.ClaudeCommandWrapper = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("remote_path_map"); err == nil {
+		pathMap, mapErr := parsePathMap(key.String())
+		if mapErr != nil {
+			return Values{}, fmt.Errorf("invalid remote_path_map: %w", mapErr)
+		}
+		values.RemotePathMap = pathMap
+	}
+	if key, err := section.GetKey("gemini_command"); err == nil {
+		values.GeminiCommand = key.String()
+	}
+	if key, err := section.GetKey("gemini_args"); err == nil {
+		values.GeminiArgs = key.String()
+	}
+	if key, err := section.GetKey("ollama_url"); err == nil {
+		values.OllamaURL = key.String()
+	}
+	if key, err := section.GetKey("ollama_model"); err == nil {
+		values.OllamaModel = key.String()
+	}
+	if key, err := section.GetKey("ollama_signal_prompt"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid ollama_signal_prompt: %w", boolErr)
+		}
+		values.OllamaSignalPrompt = val
+		values.OllamaSignalPromptSet = true
+	}
 
 	// codex settings
 	if key, err := section.GetKey("codex_enabled"); err == nil {
@@ -202,8 +377,25 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 	if key, err := section.GetKey("custom_review_script"); err == nil {
 		values.CustomReviewScript = expandTilde(key.String())
 	}
+	if values.ReviewFirstAgents, err = parseAgentList(section, "review_first_agents"); err != nil {
+		return Values{}, err
+	}
+	if values.ReviewSecondAgents, err = parseAgentList(section, "review_second_agents"); err != nil {
+		return Values{}, err
+	}
 
 	// timing settings
+	if key, err := section.GetKey("max_iterations"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid max_iterations: %w", intErr)
+		}
+		if val <= 0 {
+			return Values{}, fmt.Errorf("invalid max_iterations: must be positive, got %d", val)
+		}
+		values.MaxIterations = val
+		values.MaxIterationsSet = true
+	}
 	if key, err := section.GetKey("iteration_delay_ms"); err == nil {
 		val, intErr := key.Int()
 		if intErr != nil {
@@ -215,6 +407,63 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		values.IterationDelayMs = val
 		values.IterationDelayMsSet = true
 	}
+	if key, err := section.GetKey("executor_timeout_ms"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid executor_timeout_ms: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid executor_timeout_ms: must be non-negative, got %d", val)
+		}
+		values.ExecutorTimeoutMs = val
+		values.ExecutorTimeoutMsSet = true
+	}
+	if key, err := section.GetKey("claude_timeout_minutes"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid claude_timeout_minutes: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid claude_timeout_minutes: must be non-negative, got %d", val)
+		}
+		values.ClaudeTimeoutMinutes = val
+		values.ClaudeTimeoutMinutesSet = true
+	}
+	if key, err := section.GetKey("codex_timeout_minutes"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid codex_timeout_minutes: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid codex_timeout_minutes: must be non-negative, got %d", val)
+		}
+		values.CodexTimeoutMinutes = val
+		values.CodexTimeoutMinutesSet = true
+	}
+
+	// iteration limits
+	if key, err := section.GetKey("review_loop_iterations"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid review_loop_iterations: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid review_loop_iterations: must be non-negative, got %d", val)
+		}
+		values.ReviewLoopIterations = val
+		values.ReviewLoopIterationsSet = true
+	}
+	if key, err := section.GetKey("plan_loop_iterations"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid plan_loop_iterations: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid plan_loop_iterations: must be non-negative, got %d", val)
+		}
+		values.PlanLoopIterations = val
+		values.PlanLoopIterationsSet = true
+	}
 	if key, err := section.GetKey("task_retry_count"); err == nil {
 		val, intErr := key.Int()
 		if intErr != nil {
@@ -226,6 +475,126 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		values.TaskRetryCount = val
 		values.TaskRetryCountSet = true
 	}
+	if key, err := section.GetKey("codex_retry_count"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid codex_retry_count: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid codex_retry_count: must be non-negative, got %d", val)
+		}
+		values.CodexRetryCount = val
+		values.CodexRetryCountSet = true
+	}
+	if key, err := section.GetKey("retry_base_delay_ms"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid retry_base_delay_ms: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid retry_base_delay_ms: must be non-negative, got %d", val)
+		}
+		values.RetryBaseDelayMs = val
+		values.RetryBaseDelayMsSet = true
+	}
+	if key, err := section.GetKey("retry_max_delay_ms"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid retry_max_delay_ms: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid retry_max_delay_ms: must be non-negative, got %d", val)
+		}
+		values.RetryMaxDelayMs = val
+		values.RetryMaxDelayMsSet = true
+	}
+	if key, err := section.GetKey("stall_detection"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid stall_detection: %w", boolErr)
+		}
+		values.StallDetection = val
+		values.StallDetectionSet = true
+	}
+	if key, err := section.GetKey("stall_iterations"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid stall_iterations: %w", intErr)
+		}
+		if val < 2 {
+			return Values{}, fmt.Errorf("invalid stall_iterations: must be at least 2, got %d", val)
+		}
+		values.StallIterations = val
+		values.StallIterationsSet = true
+	}
+	if key, err := section.GetKey("task_commit_ratio"); err == nil {
+		val, floatErr := key.Float64()
+		if floatErr != nil {
+			return Values{}, fmt.Errorf("invalid task_commit_ratio: %w", floatErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid task_commit_ratio: must be non-negative, got %g", val)
+		}
+		values.TaskCommitRatio = val
+		values.TaskCommitRatioSet = true
+	}
+	if key, err := section.GetKey("strict_task_verification"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid strict_task_verification: %w", boolErr)
+		}
+		values.StrictTaskVerification = val
+		values.StrictTaskVerifySet = true
+	}
+	if key, err := section.GetKey("plan_change_action"); err == nil {
+		values.PlanChangeAction = key.String()
+	}
+
+	// token cost estimation
+	if key, err := section.GetKey("cost_per_1k_input"); err == nil {
+		val, floatErr := key.Float64()
+		if floatErr != nil {
+			return Values{}, fmt.Errorf("invalid cost_per_1k_input: %w", floatErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid cost_per_1k_input: must be non-negative, got %g", val)
+		}
+		values.CostPer1kInput = val
+		values.CostPer1kInputSet = true
+	}
+	if key, err := section.GetKey("cost_per_1k_output"); err == nil {
+		val, floatErr := key.Float64()
+		if floatErr != nil {
+			return Values{}, fmt.Errorf("invalid cost_per_1k_output: %w", floatErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid cost_per_1k_output: must be non-negative, got %g", val)
+		}
+		values.CostPer1kOutput = val
+		values.CostPer1kOutputSet = true
+	}
+	if key, err := section.GetKey("price_input"); err == nil {
+		val, floatErr := key.Float64()
+		if floatErr != nil {
+			return Values{}, fmt.Errorf("invalid price_input: %w", floatErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid price_input: must be non-negative, got %g", val)
+		}
+		values.PriceInput = val
+		values.PriceInputSet = true
+	}
+	if key, err := section.GetKey("price_output"); err == nil {
+		val, floatErr := key.Float64()
+		if floatErr != nil {
+			return Values{}, fmt.Errorf("invalid price_output: %w", floatErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid price_output: must be non-negative, got %g", val)
+		}
+		values.PriceOutput = val
+		values.PriceOutputSet = true
+	}
 
 	// finalize settings
 	if key, err := section.GetKey("finalize_enabled"); err == nil {
@@ -236,11 +605,184 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		values.FinalizeEnabled = val
 		values.FinalizeEnabledSet = true
 	}
+	if key, err := section.GetKey("finalize_commands"); err == nil {
+		for c := range strings.SplitSeq(key.String(), ",") {
+			if t := strings.TrimSpace(c); t != "" {
+				values.FinalizeCommands = append(values.FinalizeCommands, t)
+			}
+		}
+	}
+	if key, err := section.GetKey("finalize_max_iterations"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid finalize_max_iterations: %w", intErr)
+		}
+		if val < 1 {
+			return Values{}, fmt.Errorf("invalid finalize_max_iterations: must be positive, got %d", val)
+		}
+		values.FinalizeIterations = val
+		values.FinalizeIterationsSet = true
+	}
+	if key, err := section.GetKey("finalize_strict"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid finalize_strict: %w", boolErr)
+		}
+		values.FinalizeStrict = val
+		values.FinalizeStrictSet = true
+	}
+
+	// git settings
+	if key, err := section.GetKey("auto_push"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid auto_push: %w", boolErr)
+		}
+		values.AutoPush = val
+		values.AutoPushSet = true
+	}
+	if key, err := section.GetKey("git_sign"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid git_sign: %w", boolErr)
+		}
+		values.GitSign = val
+		values.GitSignSet = true
+	}
+	if key, err := section.GetKey("branch_prefix"); err == nil {
+		prefix := strings.TrimSpace(key.String())
+		if strings.ContainsAny(prefix, " \t~^:?*[\\") || strings.Contains(prefix, "..") || strings.HasPrefix(prefix, "-") {
+			return Values{}, fmt.Errorf("invalid branch_prefix: %q is not allowed in git branch names", prefix)
+		}
+		values.BranchPrefix = prefix
+	}
+	if key, err := section.GetKey("branch_template"); err == nil {
+		tmpl := strings.TrimSpace(key.String())
+		if phErr := validatePlaceholders(tmpl, BranchTemplatePlaceholders); phErr != nil {
+			return Values{}, fmt.Errorf("invalid branch_template: %w", phErr)
+		}
+		values.BranchTemplate = tmpl
+	}
+	if key, err := section.GetKey("commit_msg_add_plan"); err == nil {
+		tmpl := strings.TrimSpace(key.String())
+		if phErr := validatePlaceholders(tmpl, CommitMessagePlaceholders); phErr != nil {
+			return Values{}, fmt.Errorf("invalid commit_msg_add_plan: %w", phErr)
+		}
+		values.CommitMsgAddPlan = tmpl
+	}
+	if key, err := section.GetKey("commit_msg_move_plan"); err == nil {
+		tmpl := strings.TrimSpace(key.String())
+		if phErr := validatePlaceholders(tmpl, CommitMessagePlaceholders); phErr != nil {
+			return Values{}, fmt.Errorf("invalid commit_msg_move_plan: %w", phErr)
+		}
+		values.CommitMsgMovePlan = tmpl
+	}
+	if key, err := section.GetKey("pr_enabled"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid pr_enabled: %w", boolErr)
+		}
+		values.PREnabled = val
+		values.PREnabledSet = true
+	}
+
+	// hook scripts
+	if key, err := section.GetKey("pre_task_hook"); err == nil {
+		values.PreTaskHook = expandTilde(key.String())
+	}
+	if key, err := section.GetKey("post_review_hook"); err == nil {
+		values.PostReviewHook = expandTilde(key.String())
+	}
+	if key, err := section.GetKey("post_finalize_hook"); err == nil {
+		values.PostFinalizeHook = expandTilde(key.String())
+	}
+
+	// project context
+	if key, err := section.GetKey("context_file"); err == nil {
+		values.ContextFile = expandTilde(strings.TrimSpace(key.String()))
+	}
+	if key, err := section.GetKey("context_max_bytes"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid context_max_bytes: %w", intErr)
+		}
+		if val < 1 {
+			return Values{}, fmt.Errorf("invalid context_max_bytes: must be at least 1, got %d", val)
+		}
+		values.ContextMaxBytes = val
+		values.ContextMaxBytesSet = true
+	}
 
 	// paths
 	if key, err := section.GetKey("plans_dir"); err == nil {
 		values.PlansDir = key.String()
 	}
+	if key, err := section.GetKey("move_completed"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid move_completed: %w", boolErr)
+		}
+		values.MoveCompleted = val
+		values.MoveCompletedSet = true
+	}
+	if key, err := section.GetKey("completed_dir"); err == nil {
+		dir := strings.TrimSpace(key.String())
+		if dir == "." || dir == ".." || strings.ContainsAny(dir, `/\`) {
+			return Values{}, fmt.Errorf("invalid completed_dir: %q is not a directory name", dir)
+		}
+		values.CompletedDir = dir
+	}
+	if key, err := section.GetKey("completed_dir_date_layout"); err == nil {
+		layout := strings.TrimSpace(key.String())
+		if layoutErr := validateDateLayout(layout); layoutErr != nil {
+			return Values{}, fmt.Errorf("invalid completed_dir_date_layout: %w", layoutErr)
+		}
+		values.CompletedDirDateLayout = layout
+	}
+	if key, err := section.GetKey("progress_dir"); err == nil {
+		values.ProgressDir = expandTilde(key.String())
+	}
+	if key, err := section.GetKey("progress_keep"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid progress_keep: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid progress_keep: must be non-negative, got %d", val)
+		}
+		values.ProgressKeep = val
+		values.ProgressKeepSet = true
+	}
+	if key, err := section.GetKey("progress_json"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid progress_json: %w", boolErr)
+		}
+		values.ProgressJSON = val
+		values.ProgressJSONSet = true
+	}
+	if key, err := section.GetKey("progress_max_size_mb"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid progress_max_size_mb: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid progress_max_size_mb: must be non-negative, got %d", val)
+		}
+		values.ProgressMaxSizeMB = val
+		values.ProgressMaxSizeMBSet = true
+	}
+	if key, err := section.GetKey("progress_backups"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid progress_backups: %w", intErr)
+		}
+		if val < 1 {
+			return Values{}, fmt.Errorf("invalid progress_backups: must be at least 1, got %d", val)
+		}
+		values.ProgressBackups = val
+		values.ProgressBackupsSet = true
+	}
 
 	// watch directories (comma-separated)
 	if key, err := section.GetKey("watch_dirs"); err == nil {
@@ -253,6 +795,73 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 			}
 		}
 	}
+	if key, err := section.GetKey("watch_depth"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid watch_depth: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid watch_depth: must be non-negative, got %d", val)
+		}
+		values.WatchDepth = val
+		values.WatchDepthSet = true
+	}
+	if key, err := section.GetKey("watch_ignore"); err == nil {
+		for p := range strings.SplitSeq(key.String(), ",") {
+			name := strings.TrimSpace(p)
+			if name == "" {
+				continue
+			}
+			if _, matchErr := filepath.Match(name, ""); matchErr != nil || strings.ContainsAny(name, `/\`) {
+				return Values{}, fmt.Errorf("invalid watch_ignore: %q is not a directory name or pattern", name)
+			}
+			values.WatchIgnore = append(values.WatchIgnore, name)
+		}
+	}
+	if key, err := section.GetKey("watch_prune_hours"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid watch_prune_hours: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid watch_prune_hours: must be non-negative, got %d", val)
+		}
+		values.WatchPruneHours = val
+		values.WatchPruneHoursSet = true
+	}
+	if key, err := section.GetKey("watch_idle_minutes"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid watch_idle_minutes: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid watch_idle_minutes: must be non-negative, got %d", val)
+		}
+		values.WatchIdleMinutes = val
+		values.WatchIdleMinutesSet = true
+	}
+	if key, err := section.GetKey("web_metrics"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid web_metrics: %w", boolErr)
+		}
+		values.WebMetrics = val
+		values.WebMetricsSet = true
+	}
+	if key, err := section.GetKey("web_websocket"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid web_websocket: %w", boolErr)
+		}
+		values.WebWebSocket = val
+		values.WebWebSocketSet = true
+	}
+	if key, err := section.GetKey("web_listen"); err == nil {
+		values.WebListen = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("web_auth_token"); err == nil {
+		values.WebAuthToken = strings.TrimSpace(key.String())
+	}
 
 	// notification settings
 	if err := parseNotifyValues(section, &values); err != nil {
@@ -270,6 +879,16 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 			}
 		}
 	}
+	if key, err := section.GetKey("gemini_error_patterns"); err == nil {
+		val := strings.TrimSpace(key.String())
+		if val != "" {
+			for p := range strings.SplitSeq(val, ",") {
+				if t := strings.TrimSpace(p); t != "" {
+					values.GeminiErrorPatterns = append(values.GeminiErrorPatterns, t)
+				}
+			}
+		}
+	}
 	if key, err := section.GetKey("codex_error_patterns"); err == nil {
 		val := strings.TrimSpace(key.String())
 		if val != "" {
@@ -281,17 +900,58 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		}
 	}
 
+	// codex findings filter
+	if err := parseCodexFilterValues(section, &values); err != nil {
+		return Values{}, err
+	}
+
+	// user-defined error patterns
+	if values.ErrorPatterns, err = parseErrorPatterns(data); err != nil {
+		return Values{}, err
+	}
+	if values.ConfigAgents, err = parseConfigAgents(data); err != nil {
+		return Values{}, err
+	}
+
 	return values, nil
 }
 
 // mergeFrom merges non-empty values from src into dst.
 func (dst *Values) mergeFrom(src *Values) {
+	if src.AgentBackend != "" {
+		dst.AgentBackend = src.AgentBackend
+	}
 	if src.ClaudeCommand != "" {
 		dst.ClaudeCommand = src.ClaudeCommand
 	}
 	if src.ClaudeArgs != "" {
 		dst.ClaudeArgs = src.ClaudeArgs
 	}
+	if src.ClaudeOutputFormat != "" {
+		dst.ClaudeOutputFormat = src.ClaudeOutputFormat
+	}
+	if src.ClaudeCommandWrapper != "" {
+		dst.ClaudeCommandWrapper = src.ClaudeCommandWrapper
+	}
+	if len(src.RemotePathMap) > 0 {
+		dst.RemotePathMap = src.RemotePathMap
+	}
+	if src.GeminiCommand != "" {
+		dst.GeminiCommand = src.GeminiCommand
+	}
+	if src.GeminiArgs != "" {
+		dst.GeminiArgs = src.GeminiArgs
+	}
+	if src.OllamaURL != "" {
+		dst.OllamaURL = src.OllamaURL
+	}
+	if src.OllamaModel != "" {
+		dst.OllamaModel = src.OllamaModel
+	}
+	if src.OllamaSignalPromptSet {
+		dst.OllamaSignalPrompt = src.OllamaSignalPrompt
+		dst.OllamaSignalPromptSet = true
+	}
 	if src.CodexEnabledSet {
 		dst.CodexEnabled = src.CodexEnabled
 		dst.CodexEnabledSet = true
@@ -318,30 +978,243 @@ func (dst *Values) mergeFrom(src *Values) {
 	if src.CustomReviewScript != "" {
 		dst.CustomReviewScript = src.CustomReviewScript
 	}
+	if len(src.ReviewFirstAgents) > 0 {
+		dst.ReviewFirstAgents = src.ReviewFirstAgents
+	}
+	if len(src.ReviewSecondAgents) > 0 {
+		dst.ReviewSecondAgents = src.ReviewSecondAgents
+	}
+	if src.MaxIterationsSet {
+		dst.MaxIterations = src.MaxIterations
+		dst.MaxIterationsSet = true
+	}
 	if src.IterationDelayMsSet {
 		dst.IterationDelayMs = src.IterationDelayMs
 		dst.IterationDelayMsSet = true
 	}
+	if src.ExecutorTimeoutMsSet {
+		dst.ExecutorTimeoutMs = src.ExecutorTimeoutMs
+		dst.ExecutorTimeoutMsSet = true
+	}
+	if src.ClaudeTimeoutMinutesSet {
+		dst.ClaudeTimeoutMinutes = src.ClaudeTimeoutMinutes
+		dst.ClaudeTimeoutMinutesSet = true
+	}
+	if src.CodexTimeoutMinutesSet {
+		dst.CodexTimeoutMinutes = src.CodexTimeoutMinutes
+		dst.CodexTimeoutMinutesSet = true
+	}
+	if src.ReviewLoopIterationsSet {
+		dst.ReviewLoopIterations = src.ReviewLoopIterations
+		dst.ReviewLoopIterationsSet = true
+	}
+	if src.PlanLoopIterationsSet {
+		dst.PlanLoopIterations = src.PlanLoopIterations
+		dst.PlanLoopIterationsSet = true
+	}
 	if src.TaskRetryCountSet {
 		dst.TaskRetryCount = src.TaskRetryCount
 		dst.TaskRetryCountSet = true
 	}
+	if src.CodexRetryCountSet {
+		dst.CodexRetryCount = src.CodexRetryCount
+		dst.CodexRetryCountSet = true
+	}
+	if src.RetryBaseDelayMsSet {
+		dst.RetryBaseDelayMs = src.RetryBaseDelayMs
+		dst.RetryBaseDelayMsSet = true
+	}
+	if src.RetryMaxDelayMsSet {
+		dst.RetryMaxDelayMs = src.RetryMaxDelayMs
+		dst.RetryMaxDelayMsSet = true
+	}
+	if src.StallDetectionSet {
+		dst.StallDetection = src.StallDetection
+		dst.StallDetectionSet = true
+	}
+	if src.StallIterationsSet {
+		dst.StallIterations = src.StallIterations
+		dst.StallIterationsSet = true
+	}
+	if src.TaskCommitRatioSet {
+		dst.TaskCommitRatio = src.TaskCommitRatio
+		dst.TaskCommitRatioSet = true
+	}
+	if src.StrictTaskVerifySet {
+		dst.StrictTaskVerification = src.StrictTaskVerification
+		dst.StrictTaskVerifySet = true
+	}
+	if src.PlanChangeAction != "" {
+		dst.PlanChangeAction = src.PlanChangeAction
+	}
+	if src.CostPer1kInputSet {
+		dst.CostPer1kInput = src.CostPer1kInput
+		dst.CostPer1kInputSet = true
+	}
+	if src.CostPer1kOutputSet {
+		dst.CostPer1kOutput = src.CostPer1kOutput
+		dst.CostPer1kOutputSet = true
+	}
+	if src.PriceInputSet {
+		dst.PriceInput = src.PriceInput
+		dst.PriceInputSet = true
+	}
+	if src.PriceOutputSet {
+		dst.PriceOutput = src.PriceOutput
+		dst.PriceOutputSet = true
+	}
 	if src.FinalizeEnabledSet {
 		dst.FinalizeEnabled = src.FinalizeEnabled
 		dst.FinalizeEnabledSet = true
 	}
+	if len(src.FinalizeCommands) > 0 {
+		dst.FinalizeCommands = src.FinalizeCommands
+	}
+	if src.FinalizeIterationsSet {
+		dst.FinalizeIterations = src.FinalizeIterations
+		dst.FinalizeIterationsSet = true
+	}
+	if src.FinalizeStrictSet {
+		dst.FinalizeStrict = src.FinalizeStrict
+		dst.FinalizeStrictSet = true
+	}
+	if src.AutoPushSet {
+		dst.AutoPush = src.AutoPush
+		dst.AutoPushSet = true
+	}
+	if src.GitSignSet {
+		dst.GitSign = src.GitSign
+		dst.GitSignSet = true
+	}
+	if src.BranchPrefix != "" {
+		dst.BranchPrefix = src.BranchPrefix
+	}
+	if src.BranchTemplate != "" {
+		dst.BranchTemplate = src.BranchTemplate
+	}
+	if src.PREnabledSet {
+		dst.PREnabled = src.PREnabled
+		dst.PREnabledSet = true
+	}
+	if src.CommitMsgAddPlan != "" {
+		dst.CommitMsgAddPlan = src.CommitMsgAddPlan
+	}
+	if src.CommitMsgMovePlan != "" {
+		dst.CommitMsgMovePlan = src.CommitMsgMovePlan
+	}
+	if src.PreTaskHook != "" {
+		dst.PreTaskHook = src.PreTaskHook
+	}
+	if src.PostReviewHook != "" {
+		dst.PostReviewHook = src.PostReviewHook
+	}
+	if src.PostFinalizeHook != "" {
+		dst.PostFinalizeHook = src.PostFinalizeHook
+	}
 	if src.PlansDir != "" {
 		dst.PlansDir = src.PlansDir
 	}
+	if src.MoveCompletedSet {
+		dst.MoveCompleted = src.MoveCompleted
+		dst.MoveCompletedSet = true
+	}
+	if src.CompletedDir != "" {
+		dst.CompletedDir = src.CompletedDir
+	}
+	if src.CompletedDirDateLayout != "" {
+		dst.CompletedDirDateLayout = src.CompletedDirDateLayout
+	}
+	if src.ProgressDir != "" {
+		dst.ProgressDir = src.ProgressDir
+	}
+	if src.ContextFile != "" {
+		dst.ContextFile = src.ContextFile
+	}
+	if src.ContextMaxBytesSet {
+		dst.ContextMaxBytes = src.ContextMaxBytes
+		dst.ContextMaxBytesSet = true
+	}
+	if src.ProgressKeepSet {
+		dst.ProgressKeep = src.ProgressKeep
+		dst.ProgressKeepSet = true
+	}
+	if src.ProgressJSONSet {
+		dst.ProgressJSON = src.ProgressJSON
+		dst.ProgressJSONSet = true
+	}
+	if src.ProgressMaxSizeMBSet {
+		dst.ProgressMaxSizeMB = src.ProgressMaxSizeMB
+		dst.ProgressMaxSizeMBSet = true
+	}
+	if src.ProgressBackupsSet {
+		dst.ProgressBackups = src.ProgressBackups
+		dst.ProgressBackupsSet = true
+	}
 	if len(src.WatchDirs) > 0 {
 		dst.WatchDirs = src.WatchDirs
 	}
+	if src.WatchDepthSet {
+		dst.WatchDepth = src.WatchDepth
+		dst.WatchDepthSet = true
+	}
+	if len(src.WatchIgnore) > 0 {
+		dst.WatchIgnore = src.WatchIgnore
+	}
+	if src.WatchPruneHoursSet {
+		dst.WatchPruneHours = src.WatchPruneHours
+		dst.WatchPruneHoursSet = true
+	}
+	if src.WatchIdleMinutesSet {
+		dst.WatchIdleMinutes = src.WatchIdleMinutes
+		dst.WatchIdleMinutesSet = true
+	}
+	if src.WebMetricsSet {
+		dst.WebMetrics = src.WebMetrics
+		dst.WebMetricsSet = true
+	}
+	if src.WebWebSocketSet {
+		dst.WebWebSocket = src.WebWebSocket
+		dst.WebWebSocketSet = true
+	}
+	if src.WebListen != "" {
+		dst.WebListen = src.WebListen
+	}
+	if src.WebAuthToken != "" {
+		dst.WebAuthToken = src.WebAuthToken
+	}
 	if len(src.ClaudeErrorPatterns) > 0 {
 		dst.ClaudeErrorPatterns = src.ClaudeErrorPatterns
 	}
+	if len(src.GeminiErrorPatterns) > 0 {
+		dst.GeminiErrorPatterns = src.GeminiErrorPatterns
+	}
 	if len(src.CodexErrorPatterns) > 0 {
 		dst.CodexErrorPatterns = src.CodexErrorPatterns
 	}
+	if len(src.CodexIgnorePatterns) > 0 {
+		dst.CodexIgnorePatterns = src.CodexIgnorePatterns
+	}
+	if src.CodexMinSeverity != "" {
+		dst.CodexMinSeverity = src.CodexMinSeverity
+	}
+	// error patterns are merged by pattern, a repeated pattern takes the help command of src
+	for _, p := range src.ErrorPatterns {
+		idx := slices.IndexFunc(dst.ErrorPatterns, func(d ErrorPattern) bool { return d.Pattern == p.Pattern })
+		if idx == -1 {
+			dst.ErrorPatterns = append(dst.ErrorPatterns, p)
+			continue
+		}
+		dst.ErrorPatterns[idx] = p
+	}
+	// config agents are merged by name, a repeated name takes the instruction of src
+	for _, a := range src.ConfigAgents {
+		idx := slices.IndexFunc(dst.ConfigAgents, func(d CustomAgent) bool { return d.Name == a.Name })
+		if idx == -1 {
+			dst.ConfigAgents = append(dst.ConfigAgents, a)
+			continue
+		}
+		dst.ConfigAgents[idx] = a
+	}
 
 	dst.mergeNotifyFrom(src)
 }
@@ -405,9 +1278,18 @@ func (dst *Values) mergeNotifyFrom(src *Values) {
 		dst.NotifyWebhookURLs = src.NotifyWebhookURLs
 		dst.NotifyWebhookURLsSet = true
 	}
+	if src.NotifyWebhookFormat != "" {
+		dst.NotifyWebhookFormat = src.NotifyWebhookFormat
+	}
+	if src.NotifyWebhookURL != "" {
+		dst.NotifyWebhookURL = src.NotifyWebhookURL
+	}
 	if src.NotifyCustomScript != "" {
 		dst.NotifyCustomScript = src.NotifyCustomScript
 	}
+	if src.Notifier != "" {
+		dst.Notifier = src.Notifier
+	}
 }
 
 // parseNotifyValues extracts notification-related settings from an INI section into Values.
@@ -442,6 +1324,15 @@ func parseNotifyValues(section *ini.Section, values *Values) error {
 		values.NotifyOnComplete = val
 		values.NotifyOnCompleteSet = true
 	}
+	// notify_on is a shortcut for both flags above and wins over them in the same file
+	if key, err := section.GetKey("notify_on"); err == nil {
+		onComplete, onError, onErr := parseNotifyOn(key.String())
+		if onErr != nil {
+			return onErr
+		}
+		values.NotifyOnComplete, values.NotifyOnCompleteSet = onComplete, true
+		values.NotifyOnError, values.NotifyOnErrorSet = onError, true
+	}
 	if key, err := section.GetKey("notify_timeout_ms"); err == nil {
 		val, intErr := key.Int()
 		if intErr != nil {
@@ -478,6 +1369,19 @@ func parseNotifyValues(section *ini.Section, values *Values) error {
 	return parseNotifyDestValues(section, values)
 }
 
+// parseNotifyOn maps a notify_on value (success, failure or always) to notify_on_complete and notify_on_error.
+func parseNotifyOn(value string) (onComplete, onError bool, err error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "success":
+		return true, false, nil
+	case "failure":
+		return false, true, nil
+	case "always":
+		return true, true, nil
+	}
+	return false, false, fmt.Errorf("invalid notify_on: %q, expected one of success, failure, always", value)
+}
+
 // parseNotifyDestValues extracts SMTP/email and webhook notification settings from an INI section.
 // split from parseNotifyValues to keep cyclomatic complexity within limits.
 func parseNotifyDestValues(section *ini.Section, values *Values) error {
@@ -493,6 +1397,15 @@ func parseNotifyDestValues(section *ini.Section, values *Values) error {
 			}
 		}
 	}
+	if key, err := section.GetKey("notify_webhook_url"); err == nil {
+		values.NotifyWebhookURL = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("notify_webhook_format"); err == nil {
+		values.NotifyWebhookFormat = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("notifier"); err == nil {
+		values.Notifier = strings.TrimSpace(key.String())
+	}
 
 	// smtp/email settings
 	if key, err := section.GetKey("notify_smtp_host"); err == nil {
@@ -553,3 +1466,178 @@ func expandTilde(path string) string {
 	}
 	return home + path[1:] // replace ~ with home, keep the /
 }
+
+// validatePlaceholders checks that every {name} placeholder of a template is one of allowed.
+func validatePlaceholders(tmpl string, allowed []string) error {
+	for _, ph := range branchPlaceholderRe.FindAllString(tmpl, -1) {
+		if !slices.Contains(allowed, ph) {
+			return fmt.Errorf("unknown placeholder %s, expected one of %s", ph, strings.Join(allowed, ", "))
+		}
+	}
+	return nil
+}
+
+// validateDateLayout checks that a time layout names a relative directory path with date elements in it.
+// an empty layout is valid and means no dated subdirectory.
+func validateDateLayout(layout string) error {
+	if layout == "" {
+		return nil
+	}
+	formatted := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC).Format(layout)
+	if formatted == layout {
+		return fmt.Errorf("%q has no date elements", layout)
+	}
+	for part := range strings.SplitSeq(formatted, "/") {
+		if part == "" || part == "." || part == ".." || strings.Contains(part, `\`) {
+			return fmt.Errorf("%q is not a relative directory path", layout)
+		}
+	}
+	return nil
+}
+
+// ValueSource returns where the given config key (e.g. "plans_dir") is taken from,
+// following the same environment → repo-local → repo-root file → global → embedded precedence as the values loader.
+// returns "env RALPHEX_<KEY>" for environment overrides, the config file path for file overrides,
+// "embedded" for built-in defaults, or empty string if the key is not set anywhere.
+func (c *Config) ValueSource(key string) string {
+	if v, ok := os.LookupEnv(EnvVar(key)); ok && strings.TrimSpace(v) != "" {
+		return "env " + EnvVar(key)
+	}
+	var paths []string
+	if c.localDir != "" {
+		paths = append(paths, filepath.Join(c.localDir, "config"))
+	}
+	if c.repoFile != "" {
+		paths = append(paths, c.repoFile)
+	}
+	if c.configDir != "" {
+		paths = append(paths, filepath.Join(c.configDir, "config"))
+	}
+	for _, path := range paths {
+		data, err := readConfigData(path)
+		if err != nil {
+			continue
+		}
+		if hasKeyValue(data, key) {
+			return path
+		}
+	}
+	if data, err := defaultsFS.ReadFile("defaults/config"); err == nil && hasKeyValue(data, key) {
+		return "embedded"
+	}
+	return ""
+}
+
+// hasKeyValue reports whether INI data sets the key to a non-empty value.
+// empty values don't override lower-precedence sources in mergeFrom, so they don't count.
+func hasKeyValue(data []byte, key string) bool {
+	return iniValue(data, key) != ""
+}
+
+// parseCodexFilterValues parses the codex findings filter settings.
+// ignore patterns are comma-separated regular expressions, each must compile.
+func parseCodexFilterValues(section *ini.Section, values *Values) error {
+	if key, err := section.GetKey("codex_ignore_patterns"); err == nil {
+		for p := range strings.SplitSeq(key.String(), ",") {
+			t := strings.TrimSpace(p)
+			if t == "" {
+				continue
+			}
+			if _, reErr := regexp.Compile(t); reErr != nil {
+				return fmt.Errorf("invalid codex_ignore_patterns: %w", reErr)
+			}
+			values.CodexIgnorePatterns = append(values.CodexIgnorePatterns, t)
+		}
+	}
+	if key, err := section.GetKey("codex_min_severity"); err == nil {
+		values.CodexMinSeverity = strings.ToLower(strings.TrimSpace(key.String()))
+	}
+	return nil
+}
+
+// parseErrorPatterns parses the [error_patterns] section: each key is a regular expression,
+// its value the help command shown when it matches. keys are split on "=" only, so patterns may
+// contain ":"; a pattern starting with "[" or containing "=" must be quoted with backticks.
+func parseErrorPatterns(data []byte) ([]ErrorPattern, error) {
+	cfg, err := ini.LoadSources(ini.LoadOptions{IgnoreInlineComment: true, KeyValueDelimiters: "="}, data)
+	if err != nil {
+		return nil, fmt.Errorf("parse error_patterns: %w", err)
+	}
+	if !cfg.HasSection(errorPatternsSection) {
+		return nil, nil
+	}
+	var patterns []ErrorPattern
+	for _, key := range cfg.Section(errorPatternsSection).Keys() {
+		if _, reErr := regexp.Compile(key.Name()); reErr != nil {
+			return nil, fmt.Errorf("invalid error_patterns entry %q: %w", key.Name(), reErr)
+		}
+		patterns = append(patterns, ErrorPattern{Pattern: key.Name(), HelpCmd: strings.TrimSpace(key.String())})
+	}
+	return patterns, nil
+}
+
+// parseAgentList parses a comma-separated list of agent names, nil if the key is not set.
+// names are checked for valid characters only, Load checks that the agents exist.
+func parseAgentList(section *ini.Section, name string) ([]string, error) {
+	if !section.HasKey(name) {
+		return nil, nil
+	}
+	var agents []string
+	for p := range strings.SplitSeq(section.Key(name).String(), ",") {
+		agent := strings.TrimSpace(p)
+		if agent == "" {
+			continue
+		}
+		if !agentNameRe.MatchString(agent) {
+			return nil, fmt.Errorf("invalid %s: %q is not an agent name", name, agent)
+		}
+		agents = append(agents, agent)
+	}
+	return agents, nil
+}
+
+// parseConfigAgents parses the [custom_agents] section: each key is an agent name usable as {{agent:name}},
+// its value the instruction given to the agent. keys are split on the first "=", so instructions may contain it.
+func parseConfigAgents(data []byte) ([]CustomAgent, error) {
+	cfg, err := ini.LoadSources(ini.LoadOptions{IgnoreInlineComment: true, KeyValueDelimiters: "="}, data)
+	if err != nil {
+		return nil, fmt.Errorf("parse custom_agents: %w", err)
+	}
+	if !cfg.HasSection(customAgentsSection) {
+		return nil, nil
+	}
+	var agents []CustomAgent
+	for _, key := range cfg.Section(customAgentsSection).Keys() {
+		if !agentNameRe.MatchString(key.Name()) {
+			return nil, fmt.Errorf("invalid custom_agents name %q: use letters, digits, - and _", key.Name())
+		}
+		instruction := strings.TrimSpace(key.String())
+		if instruction == "" {
+			return nil, fmt.Errorf("invalid custom_agents entry %q: instruction is empty", key.Name())
+		}
+		agents = append(agents, CustomAgent{Name: key.Name(), Prompt: instruction})
+	}
+	return agents, nil
+}
+
+// parsePathMap parses comma-separated local=remote path prefix pairs, e.g. "/home/me/src=/srv/src".
+// returns nil for an empty value.
+func parsePathMap(value string) (map[string]string, error) {
+	var res map[string]string
+	for p := range strings.SplitSeq(value, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		local, remote, ok := strings.Cut(p, "=")
+		local, remote = strings.TrimSpace(local), strings.TrimSpace(remote)
+		if !ok || local == "" || remote == "" {
+			return nil, fmt.Errorf("entry %q, expected local=remote", p)
+		}
+		if res == nil {
+			res = make(map[string]string)
+		}
+		res[local] = remote
+	}
+	return res, nil
+}
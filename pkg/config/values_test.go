@@ -16,12 +16,19 @@ func Test_newValuesLoader(t *testing.T) {
 
 func TestValuesLoader_Load_EmbeddedOnly(t *testing.T) {
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load("", "")
+	values, err := loader.Load("", "", "")
 	require.NoError(t, err)
 
 	// all values should come from embedded defaults
+	assert.Equal(t, "claude", values.AgentBackend)
 	assert.Equal(t, "claude", values.ClaudeCommand)
 	assert.Equal(t, "--dangerously-skip-permissions --output-format stream-json --verbose", values.ClaudeArgs)
+	assert.Empty(t, values.ClaudeOutputFormat)
+	assert.Equal(t, "gemini", values.GeminiCommand)
+	assert.Equal(t, "--yolo --output-format stream-json", values.GeminiArgs)
+	assert.Equal(t, "http://localhost:11434", values.OllamaURL)
+	assert.Empty(t, values.OllamaModel)
+	assert.True(t, values.OllamaSignalPrompt)
 	assert.True(t, values.CodexEnabled)
 	assert.True(t, values.CodexEnabledSet)
 	assert.Equal(t, "codex", values.CodexCommand)
@@ -34,9 +41,53 @@ func TestValuesLoader_Load_EmbeddedOnly(t *testing.T) {
 	assert.Equal(t, 2000, values.IterationDelayMs)
 	assert.Equal(t, 1, values.TaskRetryCount)
 	assert.True(t, values.TaskRetryCountSet)
+	assert.Equal(t, 2, values.CodexRetryCount)
+	assert.True(t, values.CodexRetryCountSet)
+	assert.Equal(t, 0, values.RetryBaseDelayMs)
+	assert.False(t, values.RetryBaseDelayMsSet)
+	assert.Equal(t, 60000, values.RetryMaxDelayMs)
+	assert.True(t, values.RetryMaxDelayMsSet)
+	assert.True(t, values.StallDetection)
+	assert.True(t, values.StallDetectionSet)
+	assert.Equal(t, 3, values.StallIterations)
+	assert.InDelta(t, 0.5, values.TaskCommitRatio, 0.001)
+	assert.True(t, values.TaskCommitRatioSet)
+	assert.False(t, values.StrictTaskVerification)
+	assert.True(t, values.StrictTaskVerifySet)
+	assert.Empty(t, values.FinalizeCommands)
+	assert.Equal(t, 3, values.FinalizeIterations)
+	assert.True(t, values.FinalizeIterationsSet)
+	assert.False(t, values.FinalizeStrict)
+	assert.True(t, values.GitSign)
+	assert.True(t, values.GitSignSet)
+	assert.Empty(t, values.BranchPrefix)
+	assert.Empty(t, values.BranchTemplate)
+	assert.Empty(t, values.CommitMsgAddPlan)
+	assert.Empty(t, values.CommitMsgMovePlan)
+	assert.Equal(t, 0, values.WatchPruneHours)
+	assert.False(t, values.WatchPruneHoursSet)
+	assert.Equal(t, 2, values.WatchDepth)
+	assert.True(t, values.WatchDepthSet)
+	assert.Equal(t, []string{".git", ".idea", ".vscode", ".cache", ".npm", ".yarn", "node_modules", "vendor",
+		"__pycache__", "target", "build", "dist"}, values.WatchIgnore)
+	assert.Equal(t, 0, values.WatchIdleMinutes)
+	assert.False(t, values.WatchIdleMinutesSet)
+	assert.False(t, values.WebMetrics)
+	assert.False(t, values.WebMetricsSet)
+	assert.False(t, values.WebWebSocket)
+	assert.False(t, values.WebWebSocketSet)
+	assert.Empty(t, values.WebListen)
+	assert.Empty(t, values.WebAuthToken)
 	assert.Equal(t, "docs/plans", values.PlansDir)
+	assert.Equal(t, "completed", values.CompletedDir)
+	assert.Empty(t, values.CompletedDirDateLayout)
+	assert.True(t, values.MoveCompleted)
+	assert.True(t, values.MoveCompletedSet)
 	assert.Equal(t, []string{"You've hit your limit", "API Error:"}, values.ClaudeErrorPatterns)
+	assert.Equal(t, []string{"Quota exceeded", "RESOURCE_EXHAUSTED", "Too Many Requests"}, values.GeminiErrorPatterns)
 	assert.Equal(t, []string{"Rate limit", "quota exceeded"}, values.CodexErrorPatterns)
+	assert.Empty(t, values.CodexIgnorePatterns)
+	assert.Empty(t, values.CodexMinSeverity)
 }
 
 func TestValuesLoader_Load_GlobalOnly(t *testing.T) {
@@ -51,7 +102,7 @@ iteration_delay_ms = 5000
 	require.NoError(t, os.WriteFile(globalConfig, []byte(configContent), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load("", globalConfig)
+	values, err := loader.Load("", "", globalConfig)
 	require.NoError(t, err)
 
 	// values from global config
@@ -86,7 +137,7 @@ plans_dir = local/plans
 	require.NoError(t, os.WriteFile(localConfig, []byte(localContent), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load(localConfig, globalConfig)
+	values, err := loader.Load(localConfig, "", globalConfig)
 	require.NoError(t, err)
 
 	// local values override global
@@ -107,7 +158,7 @@ func TestValuesLoader_Load_PartialConfigs(t *testing.T) {
 	require.NoError(t, os.WriteFile(globalConfig, []byte(globalContent), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load("", globalConfig)
+	values, err := loader.Load("", "", globalConfig)
 	require.NoError(t, err)
 
 	// partial value preserved
@@ -130,9 +181,58 @@ func TestValuesLoader_Load_InvalidConfig(t *testing.T) {
 		{name: "invalid codex_timeout_ms", config: "codex_timeout_ms = abc", errPart: "codex_timeout_ms"},
 		{name: "invalid codex_enabled", config: "codex_enabled = maybe", errPart: "codex_enabled"},
 		{name: "invalid finalize_enabled", config: "finalize_enabled = maybe", errPart: "finalize_enabled"},
+		{name: "invalid finalize_strict", config: "finalize_strict = maybe", errPart: "finalize_strict"},
+		{name: "invalid finalize_max_iterations", config: "finalize_max_iterations = few", errPart: "finalize_max_iterations"},
+		{name: "zero finalize_max_iterations", config: "finalize_max_iterations = 0", errPart: "must be positive"},
+		{name: "invalid auto_push", config: "auto_push = sometimes", errPart: "auto_push"},
+		{name: "invalid git_sign", config: "git_sign = maybe", errPart: "git_sign"},
+		{name: "branch_prefix with space", config: "branch_prefix = my prefix/", errPart: "branch_prefix"},
+		{name: "branch_prefix with dots", config: "branch_prefix = a..b/", errPart: "branch_prefix"},
+		{name: "branch_template unknown placeholder", config: "branch_template = {user}/{name}", errPart: "unknown placeholder {name}"},
+		{name: "commit_msg_add_plan unknown placeholder", config: "commit_msg_add_plan = add {slug}",
+			errPart: "invalid commit_msg_add_plan: unknown placeholder {slug}"},
+		{name: "commit_msg_move_plan unknown placeholder", config: "commit_msg_move_plan = done {user}",
+			errPart: "invalid commit_msg_move_plan: unknown placeholder {user}"},
+		{name: "completed_dir with path", config: "completed_dir = done/plans", errPart: "completed_dir"},
+		{name: "completed_dir parent", config: "completed_dir = ..", errPart: "completed_dir"},
+		{name: "date layout without date", config: "completed_dir_date_layout = archive", errPart: "has no date elements"},
+		{name: "date layout absolute", config: "completed_dir_date_layout = /2006", errPart: "not a relative directory path"},
+		{name: "date layout parent", config: "completed_dir_date_layout = ../2006", errPart: "not a relative directory path"},
+		{name: "invalid web_metrics", config: "web_metrics = often", errPart: "web_metrics"},
+		{name: "invalid web_websocket", config: "web_websocket = maybe", errPart: "web_websocket"},
+		{name: "invalid codex_retry_count", config: "codex_retry_count = twice", errPart: "codex_retry_count"},
+		{name: "negative codex_retry_count", config: "codex_retry_count = -1", errPart: "must be non-negative"},
+		{name: "invalid retry_base_delay_ms", config: "retry_base_delay_ms = soon", errPart: "retry_base_delay_ms"},
+		{name: "negative retry_base_delay_ms", config: "retry_base_delay_ms = -1", errPart: "must be non-negative"},
+		{name: "invalid retry_max_delay_ms", config: "retry_max_delay_ms = 1m", errPart: "retry_max_delay_ms"},
+		{name: "negative retry_max_delay_ms", config: "retry_max_delay_ms = -5", errPart: "must be non-negative"},
+		{name: "invalid watch_prune_hours", config: "watch_prune_hours = soon", errPart: "watch_prune_hours"},
+		{name: "invalid watch_depth", config: "watch_depth = deep", errPart: "invalid watch_depth"},
+		{name: "negative watch_depth", config: "watch_depth = -1", errPart: "must be non-negative"},
+		{name: "watch_ignore with path", config: "watch_ignore = .git, src/gen", errPart: `invalid watch_ignore: "src/gen"`},
+		{name: "watch_ignore bad pattern", config: "watch_ignore = [abc", errPart: "invalid watch_ignore"},
+		{name: "negative watch_prune_hours", config: "watch_prune_hours = -2", errPart: "must be non-negative"},
+		{name: "invalid watch_idle_minutes", config: "watch_idle_minutes = later", errPart: "watch_idle_minutes"},
+		{name: "negative watch_idle_minutes", config: "watch_idle_minutes = -1", errPart: "must be non-negative"},
+		{name: "invalid progress_json", config: "progress_json = maybe", errPart: "progress_json"},
+		{name: "invalid pr_enabled", config: "pr_enabled = maybe", errPart: "pr_enabled"},
+		{name: "invalid move_completed", config: "move_completed = sometimes", errPart: "move_completed"},
+		{name: "invalid price_input", config: "price_input = cheap", errPart: "price_input"},
+		{name: "negative price_output", config: "price_output = -15", errPart: "must be non-negative"},
+		{name: "invalid task_commit_ratio", config: "task_commit_ratio = half", errPart: "task_commit_ratio"},
+		{name: "negative task_commit_ratio", config: "task_commit_ratio = -0.5", errPart: "must be non-negative"},
+		{name: "invalid strict_task_verification", config: "strict_task_verification = maybe", errPart: "strict_task_verification"},
 		{name: "negative task_retry_count", config: "task_retry_count = -1", errPart: "task_retry_count"},
 		{name: "negative codex_timeout_ms", config: "codex_timeout_ms = -100", errPart: "codex_timeout_ms"},
 		{name: "negative iteration_delay_ms", config: "iteration_delay_ms = -50", errPart: "iteration_delay_ms"},
+		{name: "invalid executor_timeout_ms", config: "executor_timeout_ms = abc", errPart: "executor_timeout_ms"},
+		{name: "negative executor_timeout_ms", config: "executor_timeout_ms = -1", errPart: "executor_timeout_ms"},
+		{name: "invalid claude_timeout_minutes", config: "claude_timeout_minutes = 1h", errPart: "claude_timeout_minutes"},
+		{name: "negative codex_timeout_minutes", config: "codex_timeout_minutes = -5", errPart: "must be non-negative"},
+		{name: "invalid review_loop_iterations", config: "review_loop_iterations = many", errPart: "review_loop_iterations"},
+		{name: "negative plan_loop_iterations", config: "plan_loop_iterations = -2", errPart: "plan_loop_iterations"},
+		{name: "remote_path_map without remote", config: "remote_path_map = /home/me/src", errPart: "remote_path_map"},
+		{name: "remote_path_map with empty local", config: "remote_path_map = =/srv/src", errPart: "remote_path_map"},
 	}
 
 	for _, tc := range tests {
@@ -142,7 +242,7 @@ func TestValuesLoader_Load_InvalidConfig(t *testing.T) {
 			require.NoError(t, os.WriteFile(configPath, []byte(tc.config), 0o600))
 
 			loader := newValuesLoader(defaultsFS)
-			_, err := loader.Load("", configPath)
+			_, err := loader.Load("", "", configPath)
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), tc.errPart)
 		})
@@ -151,7 +251,7 @@ func TestValuesLoader_Load_InvalidConfig(t *testing.T) {
 
 func TestValuesLoader_Load_NonExistentFile(t *testing.T) {
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load("/nonexistent/local", "/nonexistent/global")
+	values, err := loader.Load("/nonexistent/local", "", "/nonexistent/global")
 	require.NoError(t, err)
 
 	// should fall back to embedded defaults
@@ -167,7 +267,7 @@ func TestValuesLoader_Load_ExplicitFalseCodexEnabled(t *testing.T) {
 	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load("", configPath)
+	values, err := loader.Load("", "", configPath)
 	require.NoError(t, err)
 
 	// explicit false should be preserved (not overwritten by embedded default true)
@@ -183,7 +283,7 @@ func TestValuesLoader_Load_ExplicitZeroTaskRetryCount(t *testing.T) {
 	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load("", configPath)
+	values, err := loader.Load("", "", configPath)
 	require.NoError(t, err)
 
 	// explicit zero should be preserved (not overwritten by embedded default 1)
@@ -199,7 +299,7 @@ func TestValuesLoader_Load_ExplicitZeroCodexTimeoutMs(t *testing.T) {
 	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load("", configPath)
+	values, err := loader.Load("", "", configPath)
 	require.NoError(t, err)
 
 	// explicit zero should be preserved (not overwritten by embedded default)
@@ -215,7 +315,7 @@ func TestValuesLoader_Load_ExplicitZeroIterationDelayMs(t *testing.T) {
 	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load("", configPath)
+	values, err := loader.Load("", "", configPath)
 	require.NoError(t, err)
 
 	// explicit zero should be preserved (not overwritten by embedded default)
@@ -223,6 +323,324 @@ func TestValuesLoader_Load_ExplicitZeroIterationDelayMs(t *testing.T) {
 	assert.True(t, values.IterationDelayMsSet)
 }
 
+func TestValuesLoader_Load_ExecutorTimeoutMs(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte(`executor_timeout_ms = 600000`), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte(`executor_timeout_ms = 0`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	// embedded default has no limit
+	values, err := loader.Load("", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, values.ExecutorTimeoutMs)
+	assert.False(t, values.ExecutorTimeoutMsSet)
+
+	values, err = loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 600000, values.ExecutorTimeoutMs)
+	assert.True(t, values.ExecutorTimeoutMsSet)
+
+	// explicit zero in local config disables the global limit
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 0, values.ExecutorTimeoutMs)
+	assert.True(t, values.ExecutorTimeoutMsSet)
+}
+
+func TestValuesLoader_Load_ToolTimeoutMinutes(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("claude_timeout_minutes = 30\ncodex_timeout_minutes = 90"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("codex_timeout_minutes = 0"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	// embedded defaults leave both to executor_timeout_ms
+	values, err := loader.Load("", "", "")
+	require.NoError(t, err)
+	assert.Zero(t, values.ClaudeTimeoutMinutes)
+	assert.False(t, values.ClaudeTimeoutMinutesSet)
+	assert.Zero(t, values.CodexTimeoutMinutes)
+	assert.False(t, values.CodexTimeoutMinutesSet)
+
+	values, err = loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 30, values.ClaudeTimeoutMinutes)
+	assert.Equal(t, 90, values.CodexTimeoutMinutes)
+
+	// explicit zero in local config drops the global codex limit, the claude one stays
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 30, values.ClaudeTimeoutMinutes)
+	assert.Zero(t, values.CodexTimeoutMinutes)
+	assert.True(t, values.CodexTimeoutMinutesSet)
+}
+
+func TestValuesLoader_Load_TokenCosts(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("cost_per_1k_input = 0.003\ncost_per_1k_output = 0.015"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("cost_per_1k_output = 0"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	// embedded default has no prices
+	values, err := loader.Load("", "", "")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.0, values.CostPer1kInput, 1e-9)
+	assert.False(t, values.CostPer1kInputSet)
+	assert.False(t, values.CostPer1kOutputSet)
+
+	values, err = loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.003, values.CostPer1kInput, 1e-9)
+	assert.InDelta(t, 0.015, values.CostPer1kOutput, 1e-9)
+
+	// explicit zero in local config overrides the global price
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.003, values.CostPer1kInput, 1e-9)
+	assert.InDelta(t, 0.0, values.CostPer1kOutput, 1e-9)
+	assert.True(t, values.CostPer1kOutputSet)
+}
+
+func TestValuesLoader_Load_TokenPrices(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("price_input = 3\nprice_output = 15"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("price_output = 75"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	// embedded default has no prices
+	values, err := loader.Load("", "", "")
+	require.NoError(t, err)
+	assert.False(t, values.PriceInputSet)
+	assert.False(t, values.PriceOutputSet)
+
+	// local overrides global per key
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.InDelta(t, 3.0, values.PriceInput, 1e-9)
+	assert.InDelta(t, 75.0, values.PriceOutput, 1e-9)
+	assert.True(t, values.PriceOutputSet)
+}
+
+func TestValuesLoader_Load_ErrorPatterns(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("claude_command = claude\n[error_patterns]\n"+
+		"API Error: (5\\d\\d) = claude /usage\n`[Oo]verloaded` = open https://status.anthropic.com\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("[error_patterns]\n`[Oo]verloaded` = wait a bit\n(?i)session expired =\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	// embedded default has no user-defined patterns
+	values, err := loader.Load("", "", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.ErrorPatterns)
+
+	values, err = loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "claude", values.ClaudeCommand, "top-level keys are not affected by the section")
+	assert.Equal(t, []ErrorPattern{
+		{Pattern: `API Error: (5\d\d)`, HelpCmd: "claude /usage"},
+		{Pattern: "[Oo]verloaded", HelpCmd: "open https://status.anthropic.com"},
+	}, values.ErrorPatterns)
+
+	// local config replaces the help command of a repeated pattern and adds new ones
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []ErrorPattern{
+		{Pattern: `API Error: (5\d\d)`, HelpCmd: "claude /usage"},
+		{Pattern: "[Oo]verloaded", HelpCmd: "wait a bit"},
+		{Pattern: "(?i)session expired", HelpCmd: ""},
+	}, values.ErrorPatterns)
+}
+
+func TestValuesLoader_Load_InvalidErrorPattern(t *testing.T) {
+	globalConfig := filepath.Join(t.TempDir(), "global")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("[error_patterns]\nfoo(bar = claude /usage\n"), 0o600))
+
+	_, err := newValuesLoader(defaultsFS).Load("", "", globalConfig)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid error_patterns entry "foo(bar"`)
+}
+
+func TestValuesLoader_Load_ConfigAgents(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("[error_patterns]\nquota = wait\n[custom_agents]\n"+
+		"api = check handlers against docs/api.md, report mismatches as file:line\nsql = look for queries without = placeholders\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig,
+		[]byte("[custom_agents]\nsql = review migrations only\nperf-check = find N+1 queries\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.ConfigAgents, "embedded default defines no config agents")
+
+	values, err = loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []ErrorPattern{{Pattern: "quota", HelpCmd: "wait"}}, values.ErrorPatterns, "sections don't mix")
+	assert.Equal(t, []CustomAgent{
+		{Name: "api", Prompt: "check handlers against docs/api.md, report mismatches as file:line"},
+		{Name: "sql", Prompt: "look for queries without = placeholders"},
+	}, values.ConfigAgents)
+
+	// local config replaces the instruction of a repeated name and adds new agents
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []CustomAgent{
+		{Name: "api", Prompt: "check handlers against docs/api.md, report mismatches as file:line"},
+		{Name: "sql", Prompt: "review migrations only"},
+		{Name: "perf-check", Prompt: "find N+1 queries"},
+	}, values.ConfigAgents)
+}
+
+func TestValuesLoader_parseValuesFromBytes_ReviewAgents(t *testing.T) {
+	vl := newValuesLoader(defaultsFS)
+	vals, err := vl.parseValuesFromBytes([]byte("review_first_agents = testing, quality,, my-agent\nreview_second_agents =\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"testing", "quality", "my-agent"}, vals.ReviewFirstAgents)
+	assert.Empty(t, vals.ReviewSecondAgents)
+
+	_, err = vl.parseValuesFromBytes([]byte("review_second_agents = quality, bad name\n"))
+	require.EqualError(t, err, `invalid review_second_agents: "bad name" is not an agent name`)
+}
+
+func TestParseConfigAgents_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "bad name", content: "[custom_agents]\napi check = review the api\n", wantErr: `invalid custom_agents name "api check"`},
+		{name: "empty instruction", content: "[custom_agents]\napi =\n", wantErr: `invalid custom_agents entry "api": instruction is empty`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseConfigAgents([]byte(tc.content))
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestValuesLoader_Load_LoopIterations(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("review_loop_iterations = 8\nplan_loop_iterations = 12"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("review_loop_iterations = 0"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	// embedded default leaves both derived from max iterations
+	values, err := loader.Load("", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, values.ReviewLoopIterations)
+	assert.False(t, values.ReviewLoopIterationsSet)
+	assert.Equal(t, 0, values.PlanLoopIterations)
+	assert.False(t, values.PlanLoopIterationsSet)
+
+	values, err = loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 8, values.ReviewLoopIterations)
+	assert.Equal(t, 12, values.PlanLoopIterations)
+
+	// explicit zero in local config restores the derived review cap, plan cap stays global
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 0, values.ReviewLoopIterations)
+	assert.True(t, values.ReviewLoopIterationsSet)
+	assert.Equal(t, 12, values.PlanLoopIterations)
+}
+
+func TestValuesLoader_Load_Hooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig,
+		[]byte("pre_task_hook = ~/hooks/generate.sh\npost_review_hook = /hooks/lint.sh"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("post_review_hook = /repo/lint.sh\npost_finalize_hook = /repo/done.sh"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	// no hooks by default
+	values, err := loader.Load("", "", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.PreTaskHook)
+	assert.Empty(t, values.PostReviewHook)
+	assert.Empty(t, values.PostFinalizeHook)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "hooks/generate.sh"), values.PreTaskHook)
+	assert.Equal(t, "/repo/lint.sh", values.PostReviewHook)
+	assert.Equal(t, "/repo/done.sh", values.PostFinalizeHook)
+}
+
+func TestValuesLoader_Load_ProgressFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("progress_dir = ~/ralphex-logs\nprogress_keep = 3\nprogress_json = true\n"+
+		"progress_max_size_mb = 50\nprogress_backups = 5"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("progress_keep = 0\nprogress_json = false\nprogress_max_size_mb = 0"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	// embedded defaults keep the default dir and the last 10 runs
+	values, err := loader.Load("", "", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.ProgressDir)
+	assert.Equal(t, 10, values.ProgressKeep)
+	assert.False(t, values.ProgressJSON)
+	assert.Equal(t, 0, values.ProgressMaxSizeMB)
+	assert.Equal(t, 3, values.ProgressBackups)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	values, err = loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "ralphex-logs"), values.ProgressDir)
+	assert.Equal(t, 3, values.ProgressKeep)
+	assert.True(t, values.ProgressJSON)
+	assert.Equal(t, 50, values.ProgressMaxSizeMB)
+	assert.Equal(t, 5, values.ProgressBackups)
+
+	// explicit zero in local config keeps all runs, explicit false disables events
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 0, values.ProgressKeep)
+	assert.True(t, values.ProgressKeepSet)
+	assert.False(t, values.ProgressJSON)
+	assert.True(t, values.ProgressJSONSet)
+	assert.Equal(t, 0, values.ProgressMaxSizeMB)
+	assert.True(t, values.ProgressMaxSizeMBSet)
+	assert.Equal(t, 5, values.ProgressBackups)
+}
+
 func TestValuesLoader_Load_LocalOverridesCodexEnabled(t *testing.T) {
 	tmpDir := t.TempDir()
 	globalConfig := filepath.Join(tmpDir, "global")
@@ -232,7 +650,7 @@ func TestValuesLoader_Load_LocalOverridesCodexEnabled(t *testing.T) {
 	require.NoError(t, os.WriteFile(localConfig, []byte(`codex_enabled = false`), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load(localConfig, globalConfig)
+	values, err := loader.Load(localConfig, "", globalConfig)
 	require.NoError(t, err)
 
 	assert.False(t, values.CodexEnabled)
@@ -248,7 +666,7 @@ func TestValuesLoader_Load_LocalOverridesTaskRetryCount(t *testing.T) {
 	require.NoError(t, os.WriteFile(localConfig, []byte(`task_retry_count = 0`), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load(localConfig, globalConfig)
+	values, err := loader.Load(localConfig, "", globalConfig)
 	require.NoError(t, err)
 
 	assert.Equal(t, 0, values.TaskRetryCount)
@@ -264,13 +682,302 @@ func TestValuesLoader_Load_LocalOverridesFinalizeEnabled(t *testing.T) {
 	require.NoError(t, os.WriteFile(localConfig, []byte(`finalize_enabled = true`), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load(localConfig, globalConfig)
+	values, err := loader.Load(localConfig, "", globalConfig)
 	require.NoError(t, err)
 
 	assert.True(t, values.FinalizeEnabled)
 	assert.True(t, values.FinalizeEnabledSet)
 }
 
+func TestValuesLoader_Load_WatchPruneHours(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte(`watch_prune_hours = 24`), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte(`watch_prune_hours = 0`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 24, values.WatchPruneHours)
+
+	// local zero overrides global
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 0, values.WatchPruneHours)
+	assert.True(t, values.WatchPruneHoursSet)
+}
+
+func TestValuesLoader_Load_WatchDepthAndIgnore(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("watch_depth = 4\nwatch_ignore = node_modules, *.bak\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("watch_depth = 0\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 4, values.WatchDepth)
+	assert.Equal(t, []string{"node_modules", "*.bak"}, values.WatchIgnore)
+
+	// local zero (unlimited) overrides global
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 0, values.WatchDepth)
+	assert.True(t, values.WatchDepthSet)
+	assert.Equal(t, []string{"node_modules", "*.bak"}, values.WatchIgnore)
+}
+
+func TestValuesLoader_Load_WatchIdleMinutes(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte(`watch_idle_minutes = 30`), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte(`watch_idle_minutes = 0`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 30, values.WatchIdleMinutes)
+
+	// local zero overrides global
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 0, values.WatchIdleMinutes)
+	assert.True(t, values.WatchIdleMinutesSet)
+}
+
+func TestValuesLoader_Load_WebMetrics(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte(`web_metrics = true`), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte(`web_metrics = false`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.True(t, values.WebMetrics)
+
+	// local false overrides global
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.False(t, values.WebMetrics)
+	assert.True(t, values.WebMetricsSet)
+}
+
+func TestValuesLoader_Load_WebWebSocket(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte(`web_websocket = true`), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte(`web_websocket = false`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.True(t, values.WebWebSocket)
+
+	// local false overrides global
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.False(t, values.WebWebSocket)
+	assert.True(t, values.WebWebSocketSet)
+}
+
+func TestValuesLoader_Load_WebListenAndAuthToken(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("web_listen = 0.0.0.0\nweb_auth_token = global-secret\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("web_auth_token =  local-secret \n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0", values.WebListen)
+	assert.Equal(t, "global-secret", values.WebAuthToken)
+
+	// local token overrides global, listen address is kept
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0", values.WebListen)
+	assert.Equal(t, "local-secret", values.WebAuthToken)
+}
+
+func TestValuesLoader_Load_BranchPrefix(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("branch_prefix = ralphex/\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("branch_prefix =  team/ \n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "ralphex/", values.BranchPrefix)
+
+	// local overrides global
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "team/", values.BranchPrefix)
+}
+
+func TestValuesLoader_Load_BranchTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("branch_template = {user}/{slug}\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("branch_template = {date}-{slug}\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "{user}/{slug}", values.BranchTemplate)
+
+	// local overrides global
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "{date}-{slug}", values.BranchTemplate)
+}
+
+func TestValuesLoader_Load_CommitMessages(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig,
+		[]byte("commit_msg_add_plan = docs: add {plan}\ncommit_msg_move_plan = docs: done {plan} on {branch}\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("commit_msg_add_plan = plan({branch}): {date}\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "docs: add {plan}", values.CommitMsgAddPlan)
+	assert.Equal(t, "docs: done {plan} on {branch}", values.CommitMsgMovePlan)
+
+	// local overrides global, unset keys keep the global value
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "plan({branch}): {date}", values.CommitMsgAddPlan)
+	assert.Equal(t, "docs: done {plan} on {branch}", values.CommitMsgMovePlan)
+}
+
+func TestValuesLoader_Load_CompletedDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("completed_dir = archive\ncompleted_dir_date_layout = 2006/01\n"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("completed_dir = done\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "archive", values.CompletedDir)
+	assert.Equal(t, "2006/01", values.CompletedDirDateLayout)
+
+	// local overrides global, the layout not set locally stays
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "done", values.CompletedDir)
+	assert.Equal(t, "2006/01", values.CompletedDirDateLayout)
+}
+
+func TestValuesLoader_Load_MoveCompleted(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte(`move_completed = false`), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte(`move_completed = true`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	values, err := loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.False(t, values.MoveCompleted)
+	assert.True(t, values.MoveCompletedSet)
+
+	// local true overrides global false
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.True(t, values.MoveCompleted)
+}
+
+func TestValuesLoader_Load_AutoPush(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte(`auto_push = true`), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte(`auto_push = false`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	// disabled by default
+	values, err := loader.Load("", "", "")
+	require.NoError(t, err)
+	assert.False(t, values.AutoPush)
+	assert.False(t, values.AutoPushSet)
+
+	values, err = loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.True(t, values.AutoPush)
+
+	// local false overrides global true
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.False(t, values.AutoPush)
+	assert.True(t, values.AutoPushSet)
+}
+
+func TestValuesLoader_Load_PREnabled(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte(`pr_enabled = true`), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte(`pr_enabled = false`), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+
+	// disabled by default
+	values, err := loader.Load("", "", "")
+	require.NoError(t, err)
+	assert.False(t, values.PREnabled)
+	assert.False(t, values.PREnabledSet)
+
+	values, err = loader.Load("", "", globalConfig)
+	require.NoError(t, err)
+	assert.True(t, values.PREnabled)
+
+	// local false overrides global true
+	values, err = loader.Load(localConfig, "", globalConfig)
+	require.NoError(t, err)
+	assert.False(t, values.PREnabled)
+	assert.True(t, values.PREnabledSet)
+}
+
 func TestValuesLoader_Load_AllValuesFromUserConfig(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "config")
@@ -291,7 +998,7 @@ plans_dir = my/plans
 	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load("", configPath)
+	values, err := loader.Load("", "", configPath)
 	require.NoError(t, err)
 
 	assert.Equal(t, "/custom/claude", values.ClaudeCommand)
@@ -316,13 +1023,35 @@ func TestValues_mergeFrom(t *testing.T) {
 			PlansDir:      "dst-plans",
 		}
 		src := Values{
+			AgentBackend:  "gemini",
 			ClaudeCommand: "src-claude",
 			ClaudeArgs:    "src-args",
+			GeminiCommand: "src-gemini",
+			GeminiArgs:    "src-gemini-args",
+			OllamaModel:   "llama3",
+
+			ClaudeOutputFormat:   "text",
+			PlanChangeAction:     "ask",
+			ClaudeCommandWrapper: "ssh devbox --",
+			RemotePathMap:        map[string]string{"/home/me": "/srv"},
+			CommitMsgAddPlan:     "docs: add {plan}",
+			WatchIgnore:          []string{"node_modules"},
 		}
 		dst.mergeFrom(&src)
-
+		assert.Equal(t, []string{"node_modules"}, dst.WatchIgnore)
+		assert.Equal(t, "docs: add {plan}", dst.CommitMsgAddPlan)
+		assert.Empty(t, dst.CommitMsgMovePlan)
+		assert.Equal(t, "ssh devbox --", dst.ClaudeCommandWrapper)
+		assert.Equal(t, map[string]string{"/home/me": "/srv"}, dst.RemotePathMap)
+		assert.Equal(t, "llama3", dst.OllamaModel)
+
+		assert.Equal(t, "gemini", dst.AgentBackend)
 		assert.Equal(t, "src-claude", dst.ClaudeCommand)
 		assert.Equal(t, "src-args", dst.ClaudeArgs)
+		assert.Equal(t, "text", dst.ClaudeOutputFormat)
+		assert.Equal(t, "ask", dst.PlanChangeAction)
+		assert.Equal(t, "src-gemini", dst.GeminiCommand)
+		assert.Equal(t, "src-gemini-args", dst.GeminiArgs)
 		assert.Equal(t, "dst-plans", dst.PlansDir)
 	})
 
@@ -350,6 +1079,16 @@ func TestValues_mergeFrom(t *testing.T) {
 			IterationDelayMsSet: true,
 			TaskRetryCount:      5,
 			TaskRetryCountSet:   true,
+			StallDetection:      true,
+			StallDetectionSet:   true,
+			GitSign:             true,
+			GitSignSet:          true,
+			RetryMaxDelayMs:     60000,
+			RetryMaxDelayMsSet:  true,
+			MoveCompleted:       true,
+			MoveCompletedSet:    true,
+			TaskCommitRatio:     0.5,
+			TaskCommitRatioSet:  true,
 		}
 		src := Values{
 			CodexEnabled:        false,
@@ -360,13 +1099,33 @@ func TestValues_mergeFrom(t *testing.T) {
 			IterationDelayMsSet: true,
 			TaskRetryCount:      0,
 			TaskRetryCountSet:   true,
+			StallDetection:      false,
+			StallDetectionSet:   true,
+			GitSign:             false,
+			GitSignSet:          true,
+			RetryBaseDelayMs:    500,
+			RetryBaseDelayMsSet: true,
+			RetryMaxDelayMs:     0,
+			RetryMaxDelayMsSet:  true,
+			MoveCompleted:       false,
+			MoveCompletedSet:    true,
+			TaskCommitRatio:     0,
+			TaskCommitRatioSet:  true,
 		}
+		src.StrictTaskVerification, src.StrictTaskVerifySet = true, true
 		dst.mergeFrom(&src)
+		assert.Equal(t, 500, dst.RetryBaseDelayMs)
+		assert.Zero(t, dst.TaskCommitRatio)
+		assert.True(t, dst.StrictTaskVerification)
+		assert.Equal(t, 0, dst.RetryMaxDelayMs)
 
 		assert.False(t, dst.CodexEnabled)
 		assert.Equal(t, 0, dst.CodexTimeoutMs)
 		assert.Equal(t, 0, dst.IterationDelayMs)
 		assert.Equal(t, 0, dst.TaskRetryCount)
+		assert.False(t, dst.StallDetection)
+		assert.False(t, dst.GitSign)
+		assert.False(t, dst.MoveCompleted)
 	})
 
 	t.Run("unset flags don't merge", func(t *testing.T) {
@@ -533,6 +1292,12 @@ func TestValuesLoader_parseValuesFromBytes_ErrorPatterns(t *testing.T) {
 			expectedClaude: []string{"hit limit"},
 			expectedCodex:  []string{"rate exceeded"},
 		},
+		{
+			name:           "gemini patterns are separate",
+			input:          "gemini_error_patterns = quota exceeded",
+			expectedClaude: nil,
+			expectedCodex:  nil,
+		},
 		{
 			name:           "empty value",
 			input:          "claude_error_patterns = ",
@@ -597,7 +1362,7 @@ func TestValuesLoader_Load_ErrorPatternsOverride(t *testing.T) {
 	require.NoError(t, os.WriteFile(localConfig, []byte(localContent), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load(localConfig, globalConfig)
+	values, err := loader.Load(localConfig, "", globalConfig)
 	require.NoError(t, err)
 
 	// local should override global completely (not merge)
@@ -619,7 +1384,7 @@ func TestValuesLoader_Load_AllCommentedConfigFallsBackToEmbedded(t *testing.T) {
 	require.NoError(t, os.WriteFile(globalConfig, []byte(commentedConfig), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load("", globalConfig)
+	values, err := loader.Load("", "", globalConfig)
 	require.NoError(t, err)
 
 	// should fall back to embedded defaults since file has no actual content
@@ -645,7 +1410,7 @@ plans_dir = custom/plans
 	require.NoError(t, os.WriteFile(globalConfig, []byte(partialConfig), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load("", globalConfig)
+	values, err := loader.Load("", "", globalConfig)
 	require.NoError(t, err)
 
 	// uncommented values should be used
@@ -675,7 +1440,7 @@ plans_dir = global/plans
 	require.NoError(t, os.WriteFile(localConfig, []byte(localCommented), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load(localConfig, globalConfig)
+	values, err := loader.Load(localConfig, "", globalConfig)
 	require.NoError(t, err)
 
 	// local all-commented falls back, so global values should be used
@@ -698,7 +1463,7 @@ func TestValuesLoader_Load_BothAllCommentedFallsBackToEmbedded(t *testing.T) {
 	require.NoError(t, os.WriteFile(localConfig, []byte(commentedTemplate), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load(localConfig, globalConfig)
+	values, err := loader.Load(localConfig, "", globalConfig)
 	require.NoError(t, err)
 
 	// both all-commented, should fall back to embedded defaults
@@ -725,7 +1490,7 @@ func TestValuesLoader_Load_ExternalReviewTool(t *testing.T) {
 			require.NoError(t, os.WriteFile(configPath, []byte(tc.config), 0o600))
 
 			loader := newValuesLoader(defaultsFS)
-			values, err := loader.Load("", configPath)
+			values, err := loader.Load("", "", configPath)
 			require.NoError(t, err)
 
 			assert.Equal(t, tc.expectedTool, values.ExternalReviewTool)
@@ -744,7 +1509,7 @@ custom_review_script = /path/to/my-review.sh
 	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load("", configPath)
+	values, err := loader.Load("", "", configPath)
 	require.NoError(t, err)
 
 	assert.Equal(t, "custom", values.ExternalReviewTool)
@@ -759,7 +1524,7 @@ func TestValuesLoader_Load_CustomReviewScript_TildeExpansion(t *testing.T) {
 	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load("", configPath)
+	values, err := loader.Load("", "", configPath)
 	require.NoError(t, err)
 
 	// tilde should be expanded to home directory
@@ -777,7 +1542,7 @@ func TestValuesLoader_Load_CustomReviewScript_NoTildeNoChange(t *testing.T) {
 	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load("", configPath)
+	values, err := loader.Load("", "", configPath)
 	require.NoError(t, err)
 
 	// absolute path should not be changed
@@ -832,7 +1597,9 @@ notify_smtp_starttls = true
 notify_email_from = noreply@example.com
 notify_email_to = dev@example.com, ops@example.com
 notify_webhook_urls = https://hook1.example.com, https://hook2.example.com
+notify_webhook_format = json
 notify_custom_script = /usr/local/bin/notify.sh
+notifier = webhook
 `)
 		values, err := vl.parseValuesFromBytes(data)
 		require.NoError(t, err)
@@ -861,7 +1628,9 @@ notify_custom_script = /usr/local/bin/notify.sh
 		assert.True(t, values.NotifyEmailToSet)
 		assert.Equal(t, []string{"https://hook1.example.com", "https://hook2.example.com"}, values.NotifyWebhookURLs)
 		assert.True(t, values.NotifyWebhookURLsSet)
+		assert.Equal(t, "json", values.NotifyWebhookFormat)
 		assert.Equal(t, "/usr/local/bin/notify.sh", values.NotifyCustomScript)
+		assert.Equal(t, "webhook", values.Notifier)
 	})
 
 	t.Run("empty notify config", func(t *testing.T) {
@@ -897,6 +1666,32 @@ notify_custom_script = /usr/local/bin/notify.sh
 		assert.True(t, values.NotifyWebhookURLsSet)
 	})
 
+	t.Run("notify_on sets both flags", func(t *testing.T) {
+		tests := []struct {
+			value                 string
+			wantComplete, wantErr bool
+		}{
+			{value: "success", wantComplete: true, wantErr: false},
+			{value: "failure", wantComplete: false, wantErr: true},
+			{value: "Always", wantComplete: true, wantErr: true},
+		}
+		for _, tc := range tests {
+			values, err := vl.parseValuesFromBytes([]byte("notify_on_error = true\nnotify_on = " + tc.value + "\n"))
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantComplete, values.NotifyOnComplete, tc.value)
+			assert.Equal(t, tc.wantErr, values.NotifyOnError, tc.value)
+			assert.True(t, values.NotifyOnCompleteSet)
+			assert.True(t, values.NotifyOnErrorSet)
+		}
+	})
+
+	t.Run("notify_webhook_url", func(t *testing.T) {
+		values, err := vl.parseValuesFromBytes([]byte("notify_webhook_url = https://hook.example.com/x \n"))
+		require.NoError(t, err)
+		assert.Equal(t, "https://hook.example.com/x", values.NotifyWebhookURL)
+		assert.False(t, values.NotifyWebhookURLsSet)
+	})
+
 	t.Run("tilde expansion for custom script", func(t *testing.T) {
 		data := []byte(`notify_custom_script = ~/.config/ralphex/scripts/notify.sh`)
 		values, err := vl.parseValuesFromBytes(data)
@@ -916,6 +1711,7 @@ func TestValuesLoader_Load_InvalidNotifyConfig(t *testing.T) {
 	}{
 		{name: "invalid notify_on_error", config: "notify_on_error = maybe", errPart: "notify_on_error"},
 		{name: "invalid notify_on_complete", config: "notify_on_complete = nope", errPart: "notify_on_complete"},
+		{name: "invalid notify_on", config: "notify_on = sometimes", errPart: "invalid notify_on"},
 		{name: "invalid notify_timeout_ms", config: "notify_timeout_ms = abc", errPart: "notify_timeout_ms"},
 		{name: "negative notify_timeout_ms", config: "notify_timeout_ms = -100", errPart: "notify_timeout_ms"},
 		{name: "invalid notify_smtp_port", config: "notify_smtp_port = xyz", errPart: "notify_smtp_port"},
@@ -930,7 +1726,7 @@ func TestValuesLoader_Load_InvalidNotifyConfig(t *testing.T) {
 			require.NoError(t, os.WriteFile(configPath, []byte(tc.config), 0o600))
 
 			loader := newValuesLoader(defaultsFS)
-			_, err := loader.Load("", configPath)
+			_, err := loader.Load("", "", configPath)
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), tc.errPart)
 		})
@@ -990,6 +1786,7 @@ func TestValues_mergeFrom_NotifyFields(t *testing.T) {
 			NotifySMTPPassword: "pass",
 			NotifyEmailFrom:    "from@test.com",
 			NotifyCustomScript: "/bin/script.sh",
+			Notifier:           "desktop",
 		}
 		dst.mergeFrom(&src)
 
@@ -1001,6 +1798,7 @@ func TestValues_mergeFrom_NotifyFields(t *testing.T) {
 		assert.Equal(t, "pass", dst.NotifySMTPPassword)
 		assert.Equal(t, "from@test.com", dst.NotifyEmailFrom)
 		assert.Equal(t, "/bin/script.sh", dst.NotifyCustomScript)
+		assert.Equal(t, "desktop", dst.Notifier)
 	})
 
 	t.Run("merge notify slice fields", func(t *testing.T) {
@@ -1085,7 +1883,7 @@ notify_timeout_ms = 5000
 	require.NoError(t, os.WriteFile(localConfig, []byte(localContent), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load(localConfig, globalConfig)
+	values, err := loader.Load(localConfig, "", globalConfig)
 	require.NoError(t, err)
 
 	// local overrides
@@ -1113,7 +1911,7 @@ notify_telegram_chat = -100123
 	require.NoError(t, os.WriteFile(localConfig, []byte(localContent), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load(localConfig, globalConfig)
+	values, err := loader.Load(localConfig, "", globalConfig)
 	require.NoError(t, err)
 
 	assert.Empty(t, values.NotifyChannels, "local empty notify_channels should disable global notifications")
@@ -1131,7 +1929,7 @@ func TestValuesLoader_Load_LocalOverridesExternalReviewTool(t *testing.T) {
 	require.NoError(t, os.WriteFile(localConfig, []byte(`external_review_tool = none`), 0o600))
 
 	loader := newValuesLoader(defaultsFS)
-	values, err := loader.Load(localConfig, globalConfig)
+	values, err := loader.Load(localConfig, "", globalConfig)
 	require.NoError(t, err)
 
 	assert.Equal(t, "none", values.ExternalReviewTool)
@@ -1166,3 +1964,55 @@ func TestValues_mergeFrom_ExternalReviewFields(t *testing.T) {
 		assert.Equal(t, "/old/script.sh", dst.CustomReviewScript)
 	})
 }
+
+func TestValues_mergeFrom_FinalizeFields(t *testing.T) {
+	t.Run("merge finalize fields", func(t *testing.T) {
+		dst := Values{FinalizeCommands: []string{"make test"}, FinalizeIterations: 3, FinalizeIterationsSet: true}
+		src := Values{FinalizeCommands: []string{"go test ./...", "golangci-lint run"}, FinalizeIterations: 5,
+			FinalizeIterationsSet: true, FinalizeStrict: true, FinalizeStrictSet: true}
+		dst.mergeFrom(&src)
+		assert.Equal(t, []string{"go test ./...", "golangci-lint run"}, dst.FinalizeCommands)
+		assert.Equal(t, 5, dst.FinalizeIterations)
+		assert.True(t, dst.FinalizeStrict)
+		assert.True(t, dst.FinalizeStrictSet)
+	})
+
+	t.Run("unset source keeps finalize fields", func(t *testing.T) {
+		dst := Values{FinalizeCommands: []string{"make test"}, FinalizeIterations: 3, FinalizeIterationsSet: true,
+			FinalizeStrict: true, FinalizeStrictSet: true}
+		dst.mergeFrom(&Values{})
+		assert.Equal(t, []string{"make test"}, dst.FinalizeCommands)
+		assert.Equal(t, 3, dst.FinalizeIterations)
+		assert.True(t, dst.FinalizeStrict)
+	})
+}
+
+func TestValuesLoader_parseValuesFromBytes_CodexFilter(t *testing.T) {
+	vl := &valuesLoader{embedFS: defaultsFS}
+
+	tests := []struct {
+		name         string
+		input        string
+		wantPatterns []string
+		wantSeverity string
+		wantErr      string
+	}{
+		{name: "patterns and severity", input: "codex_ignore_patterns = (?i)consider renaming , comment style\ncodex_min_severity = High",
+			wantPatterns: []string{"(?i)consider renaming", "comment style"}, wantSeverity: "high"},
+		{name: "empty values", input: "codex_ignore_patterns =\ncodex_min_severity ="},
+		{name: "invalid regex", input: "codex_ignore_patterns = [a-", wantErr: "invalid codex_ignore_patterns"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := vl.parseValuesFromBytes([]byte(tc.input))
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantPatterns, values.CodexIgnorePatterns)
+			assert.Equal(t, tc.wantSeverity, values.CodexMinSeverity)
+		})
+	}
+}
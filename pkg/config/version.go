@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// configVersionKey records the version of the embedded defaults a config file was created from.
+const configVersionKey = "config_version"
+
+// configKeyRe matches a "key = value" line, set or commented out, capturing the key and the value.
+var configKeyRe = regexp.MustCompile(`(?m)^#?\s*([a-z][a-z0-9_]*)\s*=[ \t]*(.*)$`)
+
+// configSectionRe matches the first section header, set or commented out. keys of the sections are
+// names and patterns, not config keys.
+var configSectionRe = regexp.MustCompile(`(?m)^#?\s*\[`)
+
+// configFileKeys returns the top-level keys mentioned in config data, set or commented out, in file order.
+// a config file installed from the defaults lists every key known at that version as a comment.
+func configFileKeys(data string) []string {
+	if loc := configSectionRe.FindStringIndex(data); loc != nil {
+		data = data[:loc[0]]
+	}
+	var keys []string
+	for _, m := range configKeyRe.FindAllStringSubmatch(data, -1) {
+		if !slices.Contains(keys, m[1]) {
+			keys = append(keys, m[1])
+		}
+	}
+	return keys
+}
+
+// configFileVersion returns config_version of config data, set or commented out.
+// returns 0 for files created before versioning and for malformed values.
+func configFileVersion(data string) int {
+	for _, m := range configKeyRe.FindAllStringSubmatch(data, -1) {
+		if m[1] != configVersionKey {
+			continue
+		}
+		v, err := strconv.Atoi(strings.TrimSpace(m[2]))
+		if err != nil {
+			return 0
+		}
+		return v
+	}
+	return 0
+}
+
+// configUpgrade describes a user config file created from an older version of the embedded defaults.
+type configUpgrade struct {
+	path     string
+	version  int      // config_version of the file
+	current  int      // config_version of the embedded defaults
+	newKeys  []string // keys of the embedded defaults the file doesn't mention
+	upgraded bool     // the file is older than the embedded defaults
+}
+
+// checkConfigUpgrade compares the config file at path with the embedded defaults.
+// files without actual settings are replaced with the current defaults by the installer, they never
+// need an upgrade. errors are ignored, the check is informational and the loaders report unreadable files.
+func checkConfigUpgrade(path string) configUpgrade {
+	if shouldOverwrite(path) {
+		return configUpgrade{}
+	}
+	embedded, err := defaultsFS.ReadFile("defaults/config")
+	if err != nil {
+		return configUpgrade{}
+	}
+	local, err := os.ReadFile(path) //nolint:gosec // user's config file
+	if err != nil {
+		return configUpgrade{}
+	}
+
+	res := configUpgrade{path: path, version: configFileVersion(string(local)), current: configFileVersion(string(embedded))}
+	if res.version >= res.current {
+		return configUpgrade{}
+	}
+	res.upgraded = true
+	localKeys := configFileKeys(string(local))
+	for _, key := range configFileKeys(string(embedded)) {
+		if key != configVersionKey && !slices.Contains(localKeys, key) {
+			res.newKeys = append(res.newKeys, key)
+		}
+	}
+	return res
+}
+
+// UpgradeNotice returns a notice for a global config file created from older embedded defaults,
+// listing the settings added since. returns empty string if the config is up to date.
+func (c *Config) UpgradeNotice() string {
+	u := c.upgrade
+	if !u.upgraded {
+		return ""
+	}
+	msg := fmt.Sprintf("config %s is from config_version %d, the defaults are at %d", u.path, u.version, u.current)
+	if len(u.newKeys) > 0 {
+		msg += ", new settings: " + strings.Join(u.newKeys, ", ")
+	}
+	return msg + ". run ralphex --dump-defaults <dir> to see their descriptions, set " +
+		fmt.Sprintf("%s = %d", configVersionKey, u.current) + " to hide this notice"
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFileKeys(t *testing.T) {
+	data := "# header\n# config_version = 1\n\n# plans_dir: where plans live\nplans_dir = docs/plans\n" +
+		"#   codex_enabled = false\ntask_retry_count=2\nplans_dir = again\n\n# [custom_agents]\n# security = check\n"
+	assert.Equal(t, []string{"config_version", "plans_dir", "codex_enabled", "task_retry_count"}, configFileKeys(data))
+}
+
+func TestConfigFileVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want int
+	}{
+		{name: "set", data: "config_version = 3\n", want: 3},
+		{name: "commented out", data: "# config_version = 2\nplans_dir = x\n", want: 2},
+		{name: "missing", data: "plans_dir = x\n", want: 0},
+		{name: "malformed", data: "config_version = one\n", want: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, configFileVersion(tc.data))
+		})
+	}
+}
+
+func TestEmbeddedConfigVersion(t *testing.T) {
+	data, err := defaultsFS.ReadFile("defaults/config")
+	require.NoError(t, err)
+	assert.Positive(t, configFileVersion(string(data)), "embedded defaults must set config_version")
+	for _, key := range configFileKeys(string(data)) {
+		assert.Contains(t, knownKeys, key, "key mentioned in the embedded defaults must be known")
+	}
+}
+
+func TestConfig_UpgradeNotice(t *testing.T) {
+	writeGlobal := func(t *testing.T, content string) string {
+		t.Helper()
+		globalDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"), []byte(content), 0o600))
+		return globalDir
+	}
+
+	t.Run("old version lists new keys", func(t *testing.T) {
+		// a config created before versioning, mentioning only a few keys of that time
+		globalDir := writeGlobal(t, "# claude_command = claude\nplans_dir = my-plans\n# codex_enabled = true\n")
+
+		cfg, err := loadConfigFromDirs(globalDir, "", "")
+		require.NoError(t, err)
+		assert.Equal(t, "my-plans", cfg.PlansDir)
+
+		notice := cfg.UpgradeNotice()
+		require.NotEmpty(t, notice)
+		assert.Contains(t, notice, filepath.Join(globalDir, "config"))
+		assert.Contains(t, notice, "config_version 0")
+		assert.Contains(t, notice, "new settings: agent_backend, claude_args")
+		assert.Contains(t, notice, "context_file")
+		assert.Contains(t, notice, "--dump-defaults")
+
+		newKeys := cfg.upgrade.newKeys
+		assert.Contains(t, newKeys, "context_file")
+		assert.Contains(t, newKeys, "context_max_bytes")
+		assert.Contains(t, newKeys, "agent_backend")
+		assert.NotContains(t, newKeys, "plans_dir", "keys set in the file are not new")
+		assert.NotContains(t, newKeys, "claude_command", "commented-out keys are not new")
+		assert.NotContains(t, newKeys, "codex_enabled", "commented-out keys are not new")
+		assert.NotContains(t, newKeys, "config_version")
+		assert.Len(t, newKeys, len(knownKeys)-4, "every known key but the three in the file and config_version")
+	})
+
+	t.Run("current version", func(t *testing.T) {
+		globalDir := writeGlobal(t, "config_version = 4\nplans_dir = my-plans\n")
+		cfg, err := loadConfigFromDirs(globalDir, "", "")
+		require.NoError(t, err)
+		assert.Empty(t, cfg.UpgradeNotice())
+	})
+
+	t.Run("newer version", func(t *testing.T) {
+		globalDir := writeGlobal(t, "config_version = 99\nplans_dir = my-plans\n")
+		cfg, err := loadConfigFromDirs(globalDir, "", "")
+		require.NoError(t, err)
+		assert.Empty(t, cfg.UpgradeNotice())
+	})
+
+	t.Run("all-commented config is replaced by the installer", func(t *testing.T) {
+		globalDir := writeGlobal(t, "# plans_dir = my-plans\n")
+		cfg, err := loadConfigFromDirs(globalDir, "", "")
+		require.NoError(t, err)
+		assert.Empty(t, cfg.UpgradeNotice())
+	})
+
+	t.Run("missing config", func(t *testing.T) {
+		cfg, err := loadConfigFromDirs(t.TempDir(), "", "")
+		require.NoError(t, err)
+		assert.Empty(t, cfg.UpgradeNotice())
+	})
+}
@@ -25,7 +25,9 @@ type CodexRunner interface {
 
 // execCodexRunner is the default command runner using os/exec for codex.
 // codex outputs streaming progress to stderr, final response to stdout.
-type execCodexRunner struct{}
+type execCodexRunner struct {
+	stdin io.Reader // sent to the command's stdin, nil for none
+}
 
 func (r *execCodexRunner) Run(ctx context.Context, name string, args ...string) (CodexStreams, func() error, error) {
 	// check context before starting to avoid spawning a process that will be immediately killed
@@ -39,6 +41,7 @@ func (r *execCodexRunner) Run(ctx context.Context, name string, args ...string)
 
 	// create new process group so we can kill all descendants on cleanup
 	setupProcessGroup(cmd)
+	cmd.Stdin = r.stdin
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
@@ -69,8 +72,11 @@ type CodexExecutor struct {
 	Sandbox         string            // sandbox mode, defaults to "read-only"
 	ProjectDoc      string            // path to project documentation file
 	OutputHandler   func(text string) // called for each filtered output line in real-time
+	RawHandler      func(line string) // called with each unfiltered stderr line, then each stdout line, can be nil
 	Debug           bool              // enable debug output
 	ErrorPatterns   []string          // patterns to detect in output (e.g., rate limit messages)
+	RegexPatterns   []ErrorPattern    // user-defined regex patterns, checked before ErrorPatterns
+	Remote          *Remote           // runs codex through a wrapper command with the prompt on stdin, nil runs it locally
 	runner          CodexRunner       // for testing, nil uses default
 }
 
@@ -121,15 +127,26 @@ func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 		"-c", fmt.Sprintf("stream_idle_timeout_ms=%d", timeoutMs),
 	}
 
-	if e.ProjectDoc != "" {
-		args = append(args, "-c", fmt.Sprintf("project_doc=%q", e.ProjectDoc))
+	projectDoc := e.ProjectDoc
+	if e.Remote != nil {
+		projectDoc = e.Remote.MapPaths(projectDoc)
+	}
+	if projectDoc != "" {
+		args = append(args, "-c", fmt.Sprintf("project_doc=%q", projectDoc))
 	}
 
-	args = append(args, prompt)
+	var stdin io.Reader
+	if e.Remote != nil {
+		// "-" makes codex exec read the prompt from stdin
+		cmd, args = e.Remote.Command(cmd, append(args, "-")...)
+		stdin = strings.NewReader(e.Remote.MapPaths(prompt))
+	} else {
+		args = append(args, prompt)
+	}
 
 	runner := e.runner
 	if runner == nil {
-		runner = &execCodexRunner{}
+		runner = &execCodexRunner{stdin: stdin}
 	}
 
 	streams, wait, err := runner.Run(ctx, cmd, args...)
@@ -149,6 +166,13 @@ func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 	// wait for stderr processing to complete
 	stderrRes := <-stderrDone
 
+	// raw stdout goes after stderr, so the raw handler is never called concurrently
+	if e.RawHandler != nil {
+		for line := range strings.Lines(stdoutContent) {
+			e.RawHandler(strings.TrimSuffix(line, "\n"))
+		}
+	}
+
 	// wait for command completion
 	waitErr := wait()
 
@@ -177,12 +201,8 @@ func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 	signal := detectSignal(stdoutContent)
 
 	// check for error patterns in output
-	if pattern := checkErrorPatterns(stdoutContent, e.ErrorPatterns); pattern != "" {
-		return Result{
-			Output: stdoutContent,
-			Signal: signal,
-			Error:  &PatternMatchError{Pattern: pattern, HelpCmd: "codex /status"},
-		}
+	if patternErr := detectErrorPattern(stdoutContent, e.ErrorPatterns, e.RegexPatterns, "codex /status"); patternErr != nil {
+		return Result{Output: stdoutContent, Signal: signal, Error: patternErr}
 	}
 
 	// return stdout content as the result (the actual answer from codex)
@@ -218,6 +238,9 @@ func (e *CodexExecutor) processStderr(ctx context.Context, r io.Reader) stderrRe
 		}
 
 		line := scanner.Text()
+		if e.RawHandler != nil {
+			e.RawHandler(line)
+		}
 
 		// capture non-empty lines for error context, preserving original formatting
 		if strings.TrimSpace(line) != "" {
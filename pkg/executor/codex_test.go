@@ -114,6 +114,23 @@ Even more noise`
 	assert.Equal(t, "<<<RALPHEX:CODEX_REVIEW_DONE>>>", result.Signal)
 }
 
+func TestCodexExecutor_Run_RawHandler(t *testing.T) {
+	mock := &mockCodexRunner{
+		runFunc: func(_ context.Context, _ string, _ ...string) (CodexStreams, func() error, error) {
+			return mockStreams("--------\nmodel: gpt-5\n--------\nthinking\n", "no issues\n<<<RALPHEX:CODEX_REVIEW_DONE>>>"),
+				mockWait(), nil
+		},
+	}
+	var filtered, raw []string
+	e := &CodexExecutor{runner: mock, OutputHandler: func(text string) { filtered = append(filtered, text) },
+		RawHandler: func(line string) { raw = append(raw, line) }}
+
+	result := e.Run(context.Background(), "analyze code")
+	require.NoError(t, result.Error)
+	assert.Equal(t, []string{"--------", "model: gpt-5", "--------", "thinking", "no issues", "<<<RALPHEX:CODEX_REVIEW_DONE>>>"}, raw)
+	assert.NotContains(t, filtered, "thinking\n", "filtered output is unchanged")
+}
+
 func TestCodexExecutor_Run_StdoutIsResult(t *testing.T) {
 	// verify that Result.Output contains stdout content, not stderr
 	stderr := "--------\nheader\n--------\n**progress**\nthinking noise\n"
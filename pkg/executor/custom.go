@@ -54,6 +54,7 @@ type CustomExecutor struct {
 	Script        string            // path to the custom review script
 	OutputHandler func(text string) // called for each output line, can be nil
 	ErrorPatterns []string          // patterns to detect in output (e.g., rate limit messages)
+	RegexPatterns []ErrorPattern    // user-defined regex patterns, checked before ErrorPatterns
 	runner        CustomRunner      // for testing, nil uses default
 }
 
@@ -116,12 +117,8 @@ func (e *CustomExecutor) Run(ctx context.Context, promptContent string) Result {
 	}
 
 	// check for error patterns in output
-	if pattern := checkErrorPatterns(output, e.ErrorPatterns); pattern != "" {
-		return Result{
-			Output: output,
-			Signal: signal,
-			Error:  &PatternMatchError{Pattern: pattern, HelpCmd: e.Script + " --help"},
-		}
+	if patternErr := detectErrorPattern(output, e.ErrorPatterns, e.RegexPatterns, e.Script+" --help"); patternErr != nil {
+		return Result{Output: output, Signal: signal, Error: patternErr}
 	}
 
 	return Result{Output: output, Signal: signal, Error: finalErr}
@@ -233,6 +233,27 @@ func TestCustomExecutor_Run_ErrorPatterns(t *testing.T) {
 	}
 }
 
+func TestCustomExecutor_Run_RegexPatterns(t *testing.T) {
+	mock := &mockCustomRunner{
+		runFunc: func(_ context.Context, _, _ string) (io.Reader, func() error, error) {
+			return strings.NewReader("review failed: token expired at 12:00"), func() error { return nil }, nil
+		},
+	}
+	e := &CustomExecutor{
+		Script:        "/path/to/script.sh",
+		runner:        mock,
+		RegexPatterns: []ErrorPattern{{Pattern: `token expired at \d+:\d+`}},
+	}
+
+	result := e.Run(context.Background(), "prompt")
+
+	var patternErr *PatternMatchError
+	require.ErrorAs(t, result.Error, &patternErr)
+	assert.Equal(t, `token expired at \d+:\d+`, patternErr.Pattern)
+	assert.Equal(t, "/path/to/script.sh --help", patternErr.HelpCmd, "empty help command falls back to the script default")
+	assert.Equal(t, "review failed: token expired at 12:00\n", result.Output)
+}
+
 func TestCustomExecutor_Run_LargeOutput(t *testing.T) {
 	// test that large output lines (>64KB default bufio.Scanner limit) are handled
 	largeContent := strings.Repeat("x", 200*1024) // 200KB
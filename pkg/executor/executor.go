@@ -1,14 +1,17 @@
-// Package executor provides CLI execution for Claude and Codex tools.
+// Package executor provides CLI execution for Claude, Gemini and Codex tools.
 package executor
 
 import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/umputun/ralphex/pkg/status"
@@ -22,11 +25,37 @@ const MaxScannerBuffer = 64 * 1024 * 1024
 
 // Result holds execution result with output and detected signal.
 type Result struct {
-	Output string // accumulated text output
-	Signal string // detected signal (COMPLETED, FAILED, etc.) or empty
-	Error  error  // execution error if any
+	Output       string // accumulated text output
+	Signal       string // detected signal (COMPLETED, FAILED, etc.) or empty
+	Error        error  // execution error if any
+	InputTokens  int    // prompt tokens reported by the tool, 0 if not reported
+	OutputTokens int    // generated tokens reported by the tool, 0 if not reported
+	ToolCalls    int    // tool invocations reported by the tool, 0 if not reported
 }
 
+// HasUsage returns true if the tool reported token counts for the call.
+func (r Result) HasUsage() bool {
+	return r.InputTokens > 0 || r.OutputTokens > 0
+}
+
+// claude output formats, selected by claude_output_format
+const (
+	ClaudeOutputStreamJSON = "stream-json" // JSON events: text, tool calls and usage are told apart
+	ClaudeOutputText       = "text"        // plain text, signals are detected anywhere in the output
+)
+
+// ansiEscapeRe matches terminal escape sequences (CSI and OSC) in plain text output.
+var ansiEscapeRe = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
+
+// StripANSI removes terminal escape sequences from s, e.g. from raw executor output printed without colors.
+func StripANSI(s string) string {
+	return ansiEscapeRe.ReplaceAllString(s, "")
+}
+
+// ErrTimeout is returned when a single executor call exceeds its per-call timeout.
+// distinct from context cancellation, so callers can retry a stuck call instead of aborting the run.
+var ErrTimeout = errors.New("executor timed out")
+
 // PatternMatchError is returned when a configured error pattern is detected in output.
 type PatternMatchError struct {
 	Pattern string // the pattern that matched
@@ -37,6 +66,12 @@ func (e *PatternMatchError) Error() string {
 	return fmt.Sprintf("detected error pattern: %q", e.Pattern)
 }
 
+// ErrorPattern is a user-defined regular expression detected in output, with its own help command.
+type ErrorPattern struct {
+	Pattern string // regular expression matched against the whole output
+	HelpCmd string // command to run for more information, empty uses the tool's default
+}
+
 // CommandRunner abstracts command execution for testing.
 // Returns an io.Reader for streaming output and a wait function for completion.
 type CommandRunner interface {
@@ -44,7 +79,9 @@ type CommandRunner interface {
 }
 
 // execClaudeRunner is the default command runner using os/exec.
-type execClaudeRunner struct{}
+type execClaudeRunner struct {
+	stdin io.Reader // sent to the command's stdin, nil for none
+}
 
 func (r *execClaudeRunner) Run(ctx context.Context, name string, args ...string) (io.Reader, func() error, error) {
 	// check context before starting to avoid spawning a process that will be immediately killed
@@ -58,6 +95,7 @@ func (r *execClaudeRunner) Run(ctx context.Context, name string, args ...string)
 
 	// filter out ANTHROPIC_API_KEY from environment (claude uses different auth)
 	cmd.Env = filterEnv(os.Environ(), "ANTHROPIC_API_KEY")
+	cmd.Stdin = r.stdin
 
 	// create new process group so we can kill all descendants on cleanup
 	setupProcessGroup(cmd)
@@ -150,10 +188,7 @@ func filterEnv(env []string, keysToRemove ...string) []string {
 type streamEvent struct {
 	Type    string `json:"type"`
 	Message struct {
-		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
-		} `json:"content"`
+		Content []streamContent `json:"content"`
 	} `json:"message"`
 	ContentBlock struct {
 		Type string `json:"type"`
@@ -164,15 +199,34 @@ type streamEvent struct {
 		Text string `json:"text"`
 	} `json:"delta"`
 	Result json.RawMessage `json:"result"` // can be string or object with "output" field
+	Usage  *struct {
+		InputTokens              int `json:"input_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+		OutputTokens             int `json:"output_tokens"`
+	} `json:"usage"` // result only: token totals of the session
+}
+
+// streamContent is a content block of a claude message: text or a tool call.
+type streamContent struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text"`
+	Name  string          `json:"name"`  // tool_use only
+	Input json.RawMessage `json:"input"` // tool_use only
 }
 
 // ClaudeExecutor runs claude CLI commands with streaming JSON parsing.
 type ClaudeExecutor struct {
 	Command       string            // command to execute, defaults to "claude"
 	Args          string            // additional arguments (space-separated), defaults to standard args
+	OutputFormat  string            // ClaudeOutputStreamJSON or ClaudeOutputText, overrides --output-format of Args if set
 	OutputHandler func(text string) // called for each text chunk, can be nil
+	ToolHandler   func(text string) // called with a one-line summary of each tool call, can be nil
+	RawHandler    func(line string) // called with each raw output line before parsing, can be nil
 	Debug         bool              // enable debug output
 	ErrorPatterns []string          // patterns to detect in output (e.g., rate limit messages)
+	RegexPatterns []ErrorPattern    // user-defined regex patterns, checked before ErrorPatterns
+	Remote        *Remote           // runs claude through a wrapper command with the prompt on stdin, nil runs it locally
 	cmdRunner     CommandRunner     // for testing, nil uses default
 }
 
@@ -194,11 +248,21 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 			"--verbose",
 		}
 	}
-	args = append(args, "-p", prompt)
+	if e.OutputFormat != "" {
+		args = withOutputFormat(args, e.OutputFormat)
+	}
+	var stdin io.Reader
+	if e.Remote != nil {
+		// claude reads the prompt from stdin when -p has no argument
+		cmd, args = e.Remote.Command(cmd, append(args, "-p")...)
+		stdin = strings.NewReader(e.Remote.MapPaths(prompt))
+	} else {
+		args = append(args, "-p", prompt)
+	}
 
 	runner := e.cmdRunner
 	if runner == nil {
-		runner = &execClaudeRunner{}
+		runner = &execClaudeRunner{stdin: stdin}
 	}
 
 	stdout, wait, err := runner.Run(ctx, cmd, args...)
@@ -206,36 +270,168 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 		return Result{Error: err}
 	}
 
-	result := e.parseStream(ctx, stdout)
+	var result Result
+	if e.OutputFormat == ClaudeOutputText {
+		result = e.parseText(ctx, stdout)
+	} else {
+		result = e.parseStream(ctx, stdout)
+	}
+	return finishRun(ctx, "claude", result, wait(), e.ErrorPatterns, e.RegexPatterns, "claude /usage")
+}
 
-	if err := wait(); err != nil {
+// finishRun combines the parsed output of an agent with its exit status and checks configured error patterns.
+// shared by the stream-json agents (claude, gemini), so both treat failures and rate limits the same way.
+func finishRun(ctx context.Context, tool string, result Result, waitErr error, patterns []string,
+	regexes []ErrorPattern, helpCmd string) Result {
+	if waitErr != nil {
 		// check if it was context cancellation
 		if ctx.Err() != nil {
-			return Result{Output: result.Output, Signal: result.Signal, Error: ctx.Err()}
+			result.Error = ctx.Err()
+			return result
 		}
 		// non-zero exit might still have useful output
 		if result.Output == "" {
-			return Result{Error: fmt.Errorf("claude exited with error: %w", err)}
+			return Result{Error: fmt.Errorf("%s exited with error: %w", tool, waitErr)}
 		}
 	}
 
 	// check for error patterns in output
-	if pattern := checkErrorPatterns(result.Output, e.ErrorPatterns); pattern != "" {
-		return Result{
-			Output: result.Output,
-			Signal: result.Signal,
-			Error:  &PatternMatchError{Pattern: pattern, HelpCmd: "claude /usage"},
-		}
+	if patternErr := detectErrorPattern(result.Output, patterns, regexes, helpCmd); patternErr != nil {
+		result.Error = patternErr
+		return result
 	}
 
 	return result
 }
 
+// withOutputFormat returns args with the value of --output-format set to format, added if missing.
+func withOutputFormat(args []string, format string) []string {
+	res := slices.Clone(args)
+	for i, arg := range res {
+		switch {
+		case arg == "--output-format" && i+1 < len(res):
+			res[i+1] = format
+			return res
+		case strings.HasPrefix(arg, "--output-format="):
+			res[i] = "--output-format=" + format
+			return res
+		}
+	}
+	return append(res, "--output-format", format)
+}
+
 // parseStream reads and parses the JSON stream from claude CLI.
-// checks ctx.Done() on each iteration so cancellation is not blocked by slow pipe reads.
+// token usage is taken from the final result event, cached prompt tokens count as input.
+// tool calls are passed to ToolHandler and counted, not added to the output.
+// signals are detected only in the final assistant text, so a signal quoted earlier in the session,
+// e.g. in a code block or a tool result, doesn't end the loop.
 func (e *ClaudeExecutor) parseStream(ctx context.Context, r io.Reader) Result {
+	var inTokens, outTokens, toolCalls int
+	var final strings.Builder // text of the latest assistant message
+	result := scanStream(ctx, r, e.Debug, e.OutputHandler, e.RawHandler, func(line []byte) (string, bool) {
+		var event streamEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return "", false
+		}
+		switch event.Type {
+		case "assistant":
+			final.Reset()
+			for _, c := range event.Message.Content {
+				switch c.Type {
+				case "text":
+					final.WriteString(c.Text)
+				case "tool_use":
+					toolCalls++
+					final.Reset() // text before a tool call is not the final answer
+					if e.ToolHandler != nil {
+						e.ToolHandler(toolSummary(c.Name, c.Input))
+					}
+				}
+			}
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" {
+				final.WriteString(event.Delta.Text)
+			}
+		case "result":
+			if event.Usage != nil {
+				u := event.Usage
+				inTokens = u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+				outTokens = u.OutputTokens
+			}
+			if text := resultText(event.Result); text != "" {
+				final.Reset()
+				final.WriteString(text)
+			}
+		}
+		text := e.extractText(&event)
+		if event.Type == "assistant" && text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n" // an assistant event is a whole message, end its line so it's logged right away
+		}
+		return text, true
+	})
+	result.InputTokens, result.OutputTokens, result.ToolCalls = inTokens, outTokens, toolCalls
+	result.Signal = detectSignal(final.String())
+	return result
+}
+
+// resultText returns the final text of a result event, a plain string or an object with an "output" field.
+func resultText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var obj struct {
+		Output string `json:"output"`
+	}
+	if err := json.Unmarshal(raw, &obj); err == nil {
+		return obj.Output
+	}
+	return ""
+}
+
+// toolSummary renders a tool call as a one-liner: the tool name and its main argument, cut to 100 characters.
+func toolSummary(name string, input json.RawMessage) string {
+	var args map[string]any
+	_ = json.Unmarshal(input, &args)
+	var arg string
+	for _, key := range []string{"command", "file_path", "path", "pattern", "url", "description", "prompt"} {
+		if v, ok := args[key].(string); ok && v != "" {
+			arg = v
+			break
+		}
+	}
+	arg = strings.Join(strings.Fields(arg), " ")
+	if runes := []rune(arg); len(runes) > 100 {
+		arg = string(runes[:100]) + "..."
+	}
+	if arg == "" {
+		return name
+	}
+	return name + ": " + arg
+}
+
+// parseText reads the plain text output of claude CLI (--output-format text).
+// terminal escape sequences are removed, signals are detected anywhere in the output.
+func (e *ClaudeExecutor) parseText(ctx context.Context, r io.Reader) Result {
+	return scanStream(ctx, r, e.Debug, e.OutputHandler, e.RawHandler, func(line []byte) (string, bool) {
+		return ansiEscapeRe.ReplaceAllString(string(line), "") + "\n", true
+	})
+}
+
+// scanStream reads line-delimited JSON output of an agent CLI and collects its text and signal.
+// decode returns the text of a JSON line, or ok=false for a non-JSON line, which is kept as-is.
+// text is passed to handler line by line as soon as each line is complete, a partial line left
+// at the end of the stream is passed last. every line read is passed to raw first, if set.
+// checks ctx.Done() on each iteration so cancellation is not blocked by slow pipe reads.
+func scanStream(ctx context.Context, r io.Reader, debug bool, handler, raw func(text string),
+	decode func(line []byte) (text string, ok bool)) Result {
 	var output strings.Builder
 	var signal string
+	lines := &lineBuffer{handler: handler}
+	defer lines.Flush()
 
 	scanner := bufio.NewScanner(r)
 	// increase buffer size for large JSON lines (large diffs with parallel agents)
@@ -252,30 +448,28 @@ func (e *ClaudeExecutor) parseStream(ctx context.Context, r io.Reader) Result {
 		if line == "" {
 			continue
 		}
+		if raw != nil {
+			raw(line)
+		}
 
-		var event streamEvent
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
+		text, ok := decode([]byte(line))
+		if !ok {
 			// print non-JSON lines as-is
-			if e.Debug {
+			if debug {
 				fmt.Printf("[debug] non-JSON line: %s\n", line)
 			}
 			output.WriteString(line)
 			output.WriteString("\n")
-			if e.OutputHandler != nil {
-				e.OutputHandler(line + "\n")
-			}
+			lines.Write(line + "\n")
 			continue
 		}
 
-		text := e.extractText(&event)
 		if text != "" {
 			output.WriteString(text)
-			if e.OutputHandler != nil {
-				e.OutputHandler(text)
-			}
+			lines.Write(text)
 
-			// check for signals in text
-			if sig := detectSignal(text); sig != "" {
+			// a signal can be split across chunks, check the new text together with the tail before it
+			if sig := detectSignal(lastBytes(output.String(), len(text)+maxSignalLen()-1)); sig != "" {
 				signal = sig
 			}
 		}
@@ -332,16 +526,68 @@ func (e *ClaudeExecutor) extractText(event *streamEvent) string {
 	return ""
 }
 
+// lineBuffer joins text chunks of a stream and passes complete lines to handler one by one,
+// so a line split across chunks is logged once, with a single timestamp.
+type lineBuffer struct {
+	handler func(text string) // can be nil
+	partial strings.Builder   // text after the last newline
+}
+
+// Write passes the lines completed by text to the handler and keeps the rest until its newline arrives.
+func (b *lineBuffer) Write(text string) {
+	if b.handler == nil {
+		return
+	}
+	for {
+		line, rest, found := strings.Cut(text, "\n")
+		b.partial.WriteString(line)
+		if !found {
+			return
+		}
+		b.handler(b.partial.String() + "\n")
+		b.partial.Reset()
+		text = rest
+	}
+}
+
+// Flush passes the pending partial line, if any, to the handler.
+func (b *lineBuffer) Flush() {
+	if b.handler == nil || b.partial.Len() == 0 {
+		return
+	}
+	b.handler(b.partial.String())
+	b.partial.Reset()
+}
+
+// knownSignals lists the completion signals an agent can emit.
+var knownSignals = []string{
+	status.Completed,
+	status.Failed,
+	status.ReviewDone,
+	status.CodexDone,
+	status.PlanReady,
+}
+
+// maxSignalLen returns the length of the longest known signal.
+func maxSignalLen() int {
+	res := 0
+	for _, sig := range knownSignals {
+		res = max(res, len(sig))
+	}
+	return res
+}
+
+// lastBytes returns the last n bytes of s, or s if it's shorter.
+func lastBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[len(s)-n:]
+}
+
 // detectSignal checks text for completion status.
 // looks for <<<RALPHEX:...>>> format status.
 func detectSignal(text string) string {
-	knownSignals := []string{
-		status.Completed,
-		status.Failed,
-		status.ReviewDone,
-		status.CodexDone,
-		status.PlanReady,
-	}
 	for _, sig := range knownSignals {
 		if strings.Contains(text, sig) {
 			return sig
@@ -350,6 +596,26 @@ func detectSignal(text string) string {
 	return ""
 }
 
+// detectErrorPattern checks output for user-defined regex patterns first, then for substring patterns.
+// returns nil if nothing matches. invalid regexes are skipped, config loading rejects them.
+func detectErrorPattern(output string, patterns []string, regexes []ErrorPattern, defaultHelp string) *PatternMatchError {
+	for _, p := range regexes {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil || !re.MatchString(output) {
+			continue
+		}
+		helpCmd := p.HelpCmd
+		if helpCmd == "" {
+			helpCmd = defaultHelp
+		}
+		return &PatternMatchError{Pattern: p.Pattern, HelpCmd: helpCmd}
+	}
+	if pattern := checkErrorPatterns(output, patterns); pattern != "" {
+		return &PatternMatchError{Pattern: pattern, HelpCmd: defaultHelp}
+	}
+	return nil
+}
+
 // checkErrorPatterns checks output for configured error patterns.
 // Returns the first matching pattern or empty string if none match.
 // Matching is case-insensitive substring search.
@@ -2,10 +2,12 @@ package executor
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -111,7 +113,7 @@ func TestClaudeExecutor_Run_WithOutputHandler(t *testing.T) {
 
 	require.NoError(t, result.Error)
 	assert.Equal(t, "chunk1chunk2", result.Output)
-	assert.Equal(t, []string{"chunk1", "chunk2"}, chunks)
+	assert.Equal(t, []string{"chunk1chunk2"}, chunks, "chunks of one line are passed as a single line")
 }
 
 func TestClaudeExecutor_parseStream(t *testing.T) {
@@ -191,7 +193,7 @@ func TestClaudeExecutor_parseStream(t *testing.T) {
 		{
 			name:       "assistant event type",
 			input:      `{"type":"assistant","message":{"content":[{"type":"text","text":"assistant output"}]}}`,
-			wantOutput: "assistant output",
+			wantOutput: "assistant output\n",
 			wantSignal: "",
 		},
 	}
@@ -207,6 +209,34 @@ func TestClaudeExecutor_parseStream(t *testing.T) {
 	}
 }
 
+func TestClaudeExecutor_parseStream_Usage(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantIn  int
+		wantOut int
+	}{
+		{name: "result with usage",
+			input: `{"type":"assistant","message":{"content":[{"type":"text","text":"done"}]}}
+{"type":"result","subtype":"success","result":"done","usage":{"input_tokens":12,"cache_creation_input_tokens":300,` +
+				`"cache_read_input_tokens":4000,"output_tokens":250}}`,
+			wantIn: 4312, wantOut: 250},
+		{name: "result without usage", input: `{"type":"result","result":"done"}`},
+		{name: "usage outside result is ignored", input: `{"type":"assistant","usage":{"input_tokens":5,"output_tokens":7}}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &ClaudeExecutor{}
+			result := e.parseStream(context.Background(), strings.NewReader(tc.input))
+
+			assert.Equal(t, tc.wantIn, result.InputTokens)
+			assert.Equal(t, tc.wantOut, result.OutputTokens)
+			assert.Equal(t, tc.wantIn > 0, result.HasUsage())
+		})
+	}
+}
+
 func TestClaudeExecutor_parseStream_withHandler(t *testing.T) {
 	input := `{"type":"content_block_delta","delta":{"type":"text_delta","text":"chunk1"}}
 {"type":"content_block_delta","delta":{"type":"text_delta","text":"chunk2"}}`
@@ -221,7 +251,21 @@ func TestClaudeExecutor_parseStream_withHandler(t *testing.T) {
 	result := e.parseStream(context.Background(), strings.NewReader(input))
 
 	assert.Equal(t, "chunk1chunk2", result.Output)
-	assert.Equal(t, []string{"chunk1", "chunk2"}, chunks)
+	assert.Equal(t, []string{"chunk1chunk2"}, chunks, "chunks of one line are passed as a single line")
+}
+
+func TestClaudeExecutor_parseStream_withHandlerLines(t *testing.T) {
+	input := `{"type":"assistant","message":{"content":[{"type":"text","text":"first message"}]}}
+{"type":"assistant","message":{"content":[{"type":"text","text":"second\nmessage"}]}}
+{"type":"content_block_delta","delta":{"type":"text_delta","text":"delta "}}
+{"type":"content_block_delta","delta":{"type":"text_delta","text":"line\nand partial"}}`
+
+	var lines []string
+	e := &ClaudeExecutor{OutputHandler: func(text string) { lines = append(lines, text) }}
+	result := e.parseStream(context.Background(), strings.NewReader(input))
+
+	assert.Equal(t, "first message\nsecond\nmessage\ndelta line\nand partial", result.Output)
+	assert.Equal(t, []string{"first message\n", "second\n", "message\n", "delta line\n", "and partial"}, lines)
 }
 
 func TestClaudeExecutor_parseStream_withDebug(t *testing.T) {
@@ -239,19 +283,13 @@ func TestClaudeExecutor_extractText(t *testing.T) {
 
 	t.Run("assistant event with text", func(t *testing.T) {
 		event := streamEvent{Type: "assistant"}
-		event.Message.Content = []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
-		}{{Type: "text", Text: "assistant message"}}
+		event.Message.Content = []streamContent{{Type: "text", Text: "assistant message"}}
 		assert.Equal(t, "assistant message", e.extractText(&event))
 	})
 
 	t.Run("assistant event with multiple text blocks", func(t *testing.T) {
 		event := streamEvent{Type: "assistant"}
-		event.Message.Content = []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
-		}{{Type: "text", Text: "first"}, {Type: "text", Text: "second"}}
+		event.Message.Content = []streamContent{{Type: "text", Text: "first"}, {Type: "text", Text: "second"}}
 		assert.Equal(t, "firstsecond", e.extractText(&event))
 	})
 
@@ -289,10 +327,7 @@ func TestClaudeExecutor_extractText(t *testing.T) {
 
 	t.Run("message_stop with text content", func(t *testing.T) {
 		event := streamEvent{Type: "message_stop"}
-		event.Message.Content = []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
-		}{
+		event.Message.Content = []streamContent{
 			{Type: "text", Text: "final message"},
 		}
 		assert.Equal(t, "final message", e.extractText(&event))
@@ -300,10 +335,7 @@ func TestClaudeExecutor_extractText(t *testing.T) {
 
 	t.Run("message_stop with non-text content", func(t *testing.T) {
 		event := streamEvent{Type: "message_stop"}
-		event.Message.Content = []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
-		}{
+		event.Message.Content = []streamContent{
 			{Type: "tool_use", Text: "ignored"},
 		}
 		assert.Empty(t, e.extractText(&event))
@@ -342,6 +374,112 @@ func TestDetectSignal(t *testing.T) {
 	}
 }
 
+func TestScanStream_SignalSplitAcrossChunks(t *testing.T) {
+	half := len(status.Completed) / 2
+	input := strings.Join([]string{
+		`{"text":"working\n"}`,
+		`{"text":"done ` + status.Completed[:half] + `"}`,
+		`{"text":"` + status.Completed[half:] + `"}`,
+	}, "\n")
+	decode := func(line []byte) (string, bool) {
+		var v struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(line, &v); err != nil {
+			return "", false
+		}
+		return v.Text, true
+	}
+
+	var lines []string
+	result := scanStream(context.Background(), strings.NewReader(input), false,
+		func(text string) { lines = append(lines, text) }, nil, decode)
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, status.Completed, result.Signal)
+	assert.Equal(t, []string{"working\n", "done " + status.Completed}, lines)
+}
+
+func TestScanStream_RawLines(t *testing.T) {
+	input := `{"text":"working"}` + "\n\nplain line\n" + `{"text":"done"}` + "\n"
+	decode := func(line []byte) (string, bool) {
+		var v struct{ Text string }
+		if err := json.Unmarshal(line, &v); err != nil {
+			return "", false
+		}
+		return v.Text + "\n", true
+	}
+
+	var raw []string
+	result := scanStream(context.Background(), strings.NewReader(input), false, nil,
+		func(line string) { raw = append(raw, line) }, decode)
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, []string{`{"text":"working"}`, "plain line", `{"text":"done"}`}, raw)
+	assert.Equal(t, "working\nplain line\ndone\n", result.Output)
+}
+
+func TestStripANSI(t *testing.T) {
+	assert.Equal(t, "bold plain link", StripANSI("\x1b[1mbold\x1b[0m plain \x1b]8;;http://x\x07link\x1b]8;;\x07"))
+	assert.Equal(t, "no escapes", StripANSI("no escapes"))
+}
+
+func TestScanStream_LinesAsTheyArrive(t *testing.T) {
+	pr, pw := io.Pipe()
+	lines := make(chan string, 10)
+	done := make(chan Result, 1)
+	go func() {
+		done <- scanStream(context.Background(), pr, false, func(text string) { lines <- text }, nil,
+			func(line []byte) (string, bool) { return string(line) + "\n", true })
+	}()
+
+	_, err := pw.Write([]byte("first\n"))
+	require.NoError(t, err)
+	select {
+	case line := <-lines:
+		assert.Equal(t, "first\n", line, "line is passed before the stream ends")
+	case <-time.After(time.Second):
+		t.Fatal("line was not passed to handler while the stream is open")
+	}
+
+	require.NoError(t, pw.Close())
+	res := <-done
+	require.NoError(t, res.Error)
+	assert.Equal(t, "first\n", res.Output)
+}
+
+func TestLineBuffer(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   []string
+	}{
+		{name: "whole lines", chunks: []string{"a\n", "b\n"}, want: []string{"a\n", "b\n"}},
+		{name: "line split across chunks", chunks: []string{"he", "llo", " world\n"}, want: []string{"hello world\n"}},
+		{name: "several lines in a chunk", chunks: []string{"a\nb\nc"}, want: []string{"a\n", "b\n", "c"}},
+		{name: "partial line flushed", chunks: []string{"tail"}, want: []string{"tail"}},
+		{name: "empty lines kept", chunks: []string{"a\n\n", "b\n"}, want: []string{"a\n", "\n", "b\n"}},
+		{name: "no chunks", chunks: nil, want: nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			b := &lineBuffer{handler: func(text string) { got = append(got, text) }}
+			for _, c := range tc.chunks {
+				b.Write(c)
+			}
+			b.Flush()
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("nil handler", func(t *testing.T) {
+		b := &lineBuffer{}
+		b.Write("a\nb")
+		b.Flush()
+	})
+}
+
 func TestClaudeExecutor_Run_WithCustomCommand(t *testing.T) {
 	var capturedCmd string
 	var capturedArgs []string
@@ -408,6 +546,134 @@ func TestClaudeExecutor_Run_WithCustomCommandAndArgs(t *testing.T) {
 	assert.Equal(t, []string{"--skip-perms", "--verbose", "-p", "the prompt"}, capturedArgs)
 }
 
+func TestClaudeExecutor_Run_OutputFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     string
+		format   string
+		wantArgs []string
+	}{
+		{name: "default args", format: ClaudeOutputText,
+			wantArgs: []string{"--dangerously-skip-permissions", "--output-format", "text", "--verbose", "-p", "prompt"}},
+		{name: "custom args replaced", args: "--output-format stream-json --verbose", format: ClaudeOutputText,
+			wantArgs: []string{"--output-format", "text", "--verbose", "-p", "prompt"}},
+		{name: "custom args with equals", args: "--output-format=text", format: ClaudeOutputStreamJSON,
+			wantArgs: []string{"--output-format=stream-json", "-p", "prompt"}},
+		{name: "missing in custom args", args: "--verbose", format: ClaudeOutputStreamJSON,
+			wantArgs: []string{"--verbose", "--output-format", "stream-json", "-p", "prompt"}},
+		{name: "not set keeps args", args: "--output-format json", wantArgs: []string{"--output-format", "json", "-p", "prompt"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var capturedArgs []string
+			mock := &mocks.CommandRunnerMock{
+				RunFunc: func(_ context.Context, _ string, args ...string) (io.Reader, func() error, error) {
+					capturedArgs = args
+					return strings.NewReader(""), func() error { return nil }, nil
+				},
+			}
+			e := &ClaudeExecutor{cmdRunner: mock, Args: tc.args, OutputFormat: tc.format}
+			e.Run(context.Background(), "prompt")
+			assert.Equal(t, tc.wantArgs, capturedArgs)
+		})
+	}
+}
+
+func TestClaudeExecutor_Run_TextOutput(t *testing.T) {
+	out := "\x1b[1mWorking\x1b[0m on it\n\x1b]0;claude\x07done <<<RALPHEX:ALL_TASKS_DONE>>>\n"
+	mock := &mocks.CommandRunnerMock{
+		RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+			return strings.NewReader(out), func() error { return nil }, nil
+		},
+	}
+	var chunks []string
+	e := &ClaudeExecutor{cmdRunner: mock, OutputFormat: ClaudeOutputText, OutputHandler: func(text string) { chunks = append(chunks, text) }}
+
+	result := e.Run(context.Background(), "prompt")
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, "Working on it\ndone <<<RALPHEX:ALL_TASKS_DONE>>>\n", result.Output)
+	assert.Equal(t, []string{"Working on it\n", "done <<<RALPHEX:ALL_TASKS_DONE>>>\n"}, chunks)
+	assert.Equal(t, "<<<RALPHEX:ALL_TASKS_DONE>>>", result.Signal)
+	assert.False(t, result.HasUsage())
+}
+
+func TestClaudeExecutor_parseStream_ToolCalls(t *testing.T) {
+	input := `{"type":"assistant","message":{"content":[{"type":"text","text":"running tests"},` +
+		`{"type":"tool_use","name":"Bash","input":{"command":"go test ./...","description":"run tests"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","content":"ok"}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Read","input":{"file_path":"/tmp/a.go"}}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","name":"TodoWrite","input":{"todos":[]}}]}}
+{"type":"assistant","message":{"content":[{"type":"text","text":"all done"}]}}
+{"type":"result","subtype":"success","result":"all done","usage":{"input_tokens":10,"output_tokens":5}}`
+
+	var tools []string
+	e := &ClaudeExecutor{ToolHandler: func(text string) { tools = append(tools, text) }}
+	result := e.parseStream(context.Background(), strings.NewReader(input))
+
+	assert.Equal(t, "running tests\nall done\n", result.Output, "tool calls are not part of the output")
+	assert.Equal(t, []string{"Bash: go test ./...", "Read: /tmp/a.go", "TodoWrite"}, tools)
+	assert.Equal(t, 3, result.ToolCalls)
+	assert.Equal(t, 10, result.InputTokens)
+}
+
+func TestClaudeExecutor_parseStream_SignalInFinalText(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantSignal string
+	}{
+		{name: "signal quoted before a tool call",
+			input: `{"type":"assistant","message":{"content":[{"type":"text","text":"` +
+				"```\\n<<<RALPHEX:REVIEW_DONE>>>\\n```" + `"},{"type":"tool_use","name":"Bash","input":{}}]}}
+{"type":"assistant","message":{"content":[{"type":"text","text":"found issues, fixing"}]}}`},
+		{name: "signal in earlier message",
+			input: `{"type":"assistant","message":{"content":[{"type":"text","text":"prompt says <<<RALPHEX:REVIEW_DONE>>>"}]}}
+{"type":"assistant","message":{"content":[{"type":"text","text":"still working"}]}}`},
+		{name: "signal in final message",
+			input: `{"type":"assistant","message":{"content":[{"type":"text","text":"checking"}]}}
+{"type":"assistant","message":{"content":[{"type":"text","text":"no issues <<<RALPHEX:REVIEW_DONE>>>"}]}}`,
+			wantSignal: "<<<RALPHEX:REVIEW_DONE>>>"},
+		{name: "signal in result text",
+			input: `{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Bash","input":{}}]}}
+{"type":"result","result":"done <<<RALPHEX:ALL_TASKS_DONE>>>"}`,
+			wantSignal: "<<<RALPHEX:ALL_TASKS_DONE>>>"},
+		{name: "result text overrides earlier messages",
+			input: `{"type":"assistant","message":{"content":[{"type":"text","text":"<<<RALPHEX:ALL_TASKS_DONE>>>"}]}}
+{"type":"result","result":"not done yet"}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &ClaudeExecutor{}
+			result := e.parseStream(context.Background(), strings.NewReader(tc.input))
+			assert.Equal(t, tc.wantSignal, result.Signal)
+		})
+	}
+}
+
+func TestToolSummary(t *testing.T) {
+	tests := []struct {
+		name  string
+		tool  string
+		input string
+		want  string
+	}{
+		{name: "command", tool: "Bash", input: `{"command":"go test ./...","description":"run"}`, want: "Bash: go test ./..."},
+		{name: "multiline command", tool: "Bash", input: `{"command":"cd a &&\n  make"}`, want: "Bash: cd a && make"},
+		{name: "file path", tool: "Edit", input: `{"file_path":"main.go","old_string":"a"}`, want: "Edit: main.go"},
+		{name: "pattern", tool: "Grep", input: `{"pattern":"func main"}`, want: "Grep: func main"},
+		{name: "no known argument", tool: "TodoWrite", input: `{"todos":[]}`, want: "TodoWrite"},
+		{name: "invalid input", tool: "Task", input: `nope`, want: "Task"},
+		{name: "long argument", tool: "Bash", input: `{"command":"` + strings.Repeat("x", 120) + `"}`,
+			want: "Bash: " + strings.Repeat("x", 100) + "..."},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, toolSummary(tc.tool, []byte(tc.input)))
+		})
+	}
+}
+
 func TestSplitArgs(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -563,11 +829,47 @@ func TestCheckErrorPatterns(t *testing.T) {
 	}
 }
 
+func TestDetectErrorPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		patterns []string
+		regexes  []ErrorPattern
+		want     *PatternMatchError
+	}{
+		{name: "nothing configured", output: "API Error: 529", want: nil},
+		{name: "substring match uses default help", output: "API Error: 529", patterns: []string{"API Error:"},
+			want: &PatternMatchError{Pattern: "API Error:", HelpCmd: "claude /usage"}},
+		{name: "regex match uses its help", output: "API Error: 529 overloaded",
+			regexes: []ErrorPattern{{Pattern: `API Error: 5\d\d`, HelpCmd: "open status.anthropic.com"}},
+			want:    &PatternMatchError{Pattern: `API Error: 5\d\d`, HelpCmd: "open status.anthropic.com"}},
+		{name: "regex checked before substrings", output: "API Error: 529", patterns: []string{"API Error:"},
+			regexes: []ErrorPattern{{Pattern: `5\d\d`, HelpCmd: "wait"}},
+			want:    &PatternMatchError{Pattern: `5\d\d`, HelpCmd: "wait"}},
+		{name: "regex without help uses default", output: "session expired",
+			regexes: []ErrorPattern{{Pattern: `(?i)SESSION\s+EXPIRED`}},
+			want:    &PatternMatchError{Pattern: `(?i)SESSION\s+EXPIRED`, HelpCmd: "claude /usage"}},
+		{name: "regex is case sensitive", output: "session expired", regexes: []ErrorPattern{{Pattern: `SESSION`}}, want: nil},
+		{name: "regex no match falls back to substrings", output: "rate limit", patterns: []string{"rate limit"},
+			regexes: []ErrorPattern{{Pattern: `quota \d+`}},
+			want:    &PatternMatchError{Pattern: "rate limit", HelpCmd: "claude /usage"}},
+		{name: "invalid regex skipped", output: "broken (", regexes: []ErrorPattern{{Pattern: `(`}, {Pattern: `broken`}},
+			want: &PatternMatchError{Pattern: "broken", HelpCmd: "claude /usage"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, detectErrorPattern(tc.output, tc.patterns, tc.regexes, "claude /usage"))
+		})
+	}
+}
+
 func TestClaudeExecutor_Run_ErrorPattern(t *testing.T) {
 	tests := []struct {
 		name        string
 		output      string
 		patterns    []string
+		regexes     []ErrorPattern
 		wantError   bool
 		wantPattern string
 		wantHelpCmd string
@@ -614,6 +916,16 @@ func TestClaudeExecutor_Run_ErrorPattern(t *testing.T) {
 			wantHelpCmd: "claude /usage",
 			wantOutput:  "rate limit and quota exceeded",
 		},
+		{
+			name:        "user-defined regex matched",
+			output:      `{"type":"content_block_delta","delta":{"type":"text_delta","text":"API Error: 529 overloaded"}}`,
+			patterns:    []string{"API Error:"},
+			regexes:     []ErrorPattern{{Pattern: `API Error: 5\d\d`, HelpCmd: "open https://status.anthropic.com"}},
+			wantError:   true,
+			wantPattern: `API Error: 5\d\d`,
+			wantHelpCmd: "open https://status.anthropic.com",
+			wantOutput:  "API Error: 529 overloaded",
+		},
 	}
 
 	for _, tc := range tests {
@@ -626,6 +938,7 @@ func TestClaudeExecutor_Run_ErrorPattern(t *testing.T) {
 			e := &ClaudeExecutor{
 				cmdRunner:     mock,
 				ErrorPatterns: tc.patterns,
+				RegexPatterns: tc.regexes,
 			}
 
 			result := e.Run(context.Background(), "test prompt")
@@ -0,0 +1,92 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// geminiEvent represents a JSON event from gemini CLI stream output (--output-format stream-json).
+type geminiEvent struct {
+	Type    string `json:"type"`    // init, message, tool_use, tool_result, error, result
+	Role    string `json:"role"`    // message only: user or assistant
+	Content string `json:"content"` // message only: text, a chunk of it when delta is set
+	Message string `json:"message"` // error only
+	Stats   struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"stats"` // result only
+}
+
+// GeminiExecutor runs gemini CLI commands with streaming JSON parsing.
+// it is a drop-in replacement for ClaudeExecutor as the primary agent:
+// signals and error patterns are detected the same way.
+type GeminiExecutor struct {
+	Command       string            // command to execute, defaults to "gemini"
+	Args          string            // additional arguments (space-separated), defaults to standard args
+	OutputHandler func(text string) // called for each text chunk, can be nil
+	RawHandler    func(line string) // called with each raw output line before parsing, can be nil
+	Debug         bool              // enable debug output
+	ErrorPatterns []string          // patterns to detect in output (e.g., quota messages)
+	RegexPatterns []ErrorPattern    // user-defined regex patterns, checked before ErrorPatterns
+	cmdRunner     CommandRunner     // for testing, nil uses default
+}
+
+// Run executes gemini CLI with the given prompt and parses streaming JSON output.
+func (e *GeminiExecutor) Run(ctx context.Context, prompt string) Result {
+	cmd := e.Command
+	if cmd == "" {
+		cmd = "gemini"
+	}
+
+	// build args from configured string or use defaults
+	var args []string
+	if e.Args != "" {
+		args = splitArgs(e.Args)
+	} else {
+		args = []string{
+			"--yolo",
+			"--output-format", "stream-json",
+		}
+	}
+	args = append(args, "-p", prompt)
+
+	runner := e.cmdRunner
+	if runner == nil {
+		runner = &execClaudeRunner{} // generic process-group runner, the env filter doesn't affect gemini
+	}
+
+	stdout, wait, err := runner.Run(ctx, cmd, args...)
+	if err != nil {
+		return Result{Error: err}
+	}
+
+	var inTokens, outTokens int
+	result := scanStream(ctx, stdout, e.Debug, e.OutputHandler, e.RawHandler, func(line []byte) (string, bool) {
+		var event geminiEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return "", false
+		}
+		if event.Type == "result" {
+			inTokens, outTokens = event.Stats.InputTokens, event.Stats.OutputTokens
+		}
+		return e.extractText(&event), true
+	})
+	result.InputTokens, result.OutputTokens = inTokens, outTokens
+	return finishRun(ctx, "gemini", result, wait(), e.ErrorPatterns, e.RegexPatterns, "gemini /stats")
+}
+
+// extractText extracts text content from gemini stream events.
+// assistant messages carry the text, errors are kept so error patterns can match them.
+func (e *GeminiExecutor) extractText(event *geminiEvent) string {
+	switch event.Type {
+	case "message":
+		if event.Role == "assistant" {
+			return event.Content
+		}
+	case "error":
+		if event.Message != "" {
+			return "error: " + event.Message + "\n"
+		}
+	}
+	return ""
+}
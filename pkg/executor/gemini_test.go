@@ -0,0 +1,124 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor/mocks"
+)
+
+func TestGeminiExecutor_Run(t *testing.T) {
+	tests := []struct {
+		name       string
+		stream     string
+		waitErr    error
+		patterns   []string
+		wantOutput string
+		wantSignal string
+		wantErr    string
+		wantHelp   string
+		wantIn     int
+		wantOut    int
+	}{
+		{name: "assistant deltas with signal",
+			stream: `{"type":"init","session_id":"abc","model":"gemini-2.5-pro"}
+{"type":"message","role":"user","content":"do the task"}
+{"type":"message","role":"assistant","content":"working on it ","delta":true}
+{"type":"tool_use","tool_name":"run_shell_command","parameters":{"command":"go test ./..."}}
+{"type":"tool_result","status":"success","output":"ok"}
+{"type":"message","role":"assistant","content":"done <<<RALPHEX:ALL_TASKS_DONE>>>","delta":true}
+{"type":"result","status":"success","stats":{"total_tokens":100,"input_tokens":80,"output_tokens":20}}`,
+			wantOutput: "working on it done <<<RALPHEX:ALL_TASKS_DONE>>>", wantSignal: "<<<RALPHEX:ALL_TASKS_DONE>>>", wantIn: 80, wantOut: 20},
+		{name: "non-json lines are kept", stream: "Loaded cached credentials.\n" + `{"type":"message","role":"assistant","content":"hi"}`,
+			wantOutput: "Loaded cached credentials.\nhi"},
+		{name: "non-zero exit with output is not an error", stream: `{"type":"message","role":"assistant","content":"partial"}`,
+			waitErr: errors.New("exit status 1"), wantOutput: "partial"},
+		{name: "non-zero exit without output", waitErr: errors.New("exit status 1"), wantErr: "gemini exited with error"},
+		{name: "error event matches pattern",
+			stream:   `{"type":"error","severity":"error","message":"Quota exceeded for quota metric 'Gemini 2.5 Pro Requests'"}`,
+			patterns: []string{"quota exceeded"}, wantOutput: "error: Quota exceeded for quota metric 'Gemini 2.5 Pro Requests'\n",
+			wantErr: `detected error pattern: "quota exceeded"`, wantHelp: "gemini /stats"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var chunks []string
+			mock := &mocks.CommandRunnerMock{
+				RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+					return strings.NewReader(tc.stream), func() error { return tc.waitErr }, nil
+				},
+			}
+			e := &GeminiExecutor{cmdRunner: mock, ErrorPatterns: tc.patterns,
+				OutputHandler: func(text string) { chunks = append(chunks, text) }}
+
+			result := e.Run(context.Background(), "do the task")
+
+			assert.Equal(t, tc.wantOutput, result.Output)
+			assert.Equal(t, tc.wantSignal, result.Signal)
+			assert.Equal(t, tc.wantOutput, strings.Join(chunks, ""))
+			assert.Equal(t, tc.wantIn, result.InputTokens)
+			assert.Equal(t, tc.wantOut, result.OutputTokens)
+			if tc.wantErr == "" {
+				require.NoError(t, result.Error)
+				return
+			}
+			require.ErrorContains(t, result.Error, tc.wantErr)
+			if tc.wantHelp != "" {
+				var patternErr *PatternMatchError
+				require.ErrorAs(t, result.Error, &patternErr)
+				assert.Equal(t, tc.wantHelp, patternErr.HelpCmd)
+			}
+		})
+	}
+}
+
+func TestGeminiExecutor_Run_Args(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		args     string
+		wantCmd  string
+		wantArgs []string
+	}{
+		{name: "defaults", wantCmd: "gemini", wantArgs: []string{"--yolo", "--output-format", "stream-json", "-p", "prompt"}},
+		{name: "custom command and args", command: "/opt/bin/gemini", args: `--model gemini-2.5-flash --output-format stream-json`,
+			wantCmd: "/opt/bin/gemini", wantArgs: []string{"--model", "gemini-2.5-flash", "--output-format", "stream-json", "-p", "prompt"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mocks.CommandRunnerMock{
+				RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+					return strings.NewReader(""), func() error { return nil }, nil
+				},
+			}
+			e := &GeminiExecutor{Command: tc.command, Args: tc.args, cmdRunner: mock}
+			e.Run(context.Background(), "prompt")
+
+			require.Len(t, mock.RunCalls(), 1)
+			assert.Equal(t, tc.wantCmd, mock.RunCalls()[0].Name)
+			assert.Equal(t, tc.wantArgs, mock.RunCalls()[0].Args)
+		})
+	}
+}
+
+func TestGeminiExecutor_Run_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mock := &mocks.CommandRunnerMock{
+		RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+			return strings.NewReader(""), func() error { return context.Canceled }, nil
+		},
+	}
+	e := &GeminiExecutor{cmdRunner: mock}
+
+	result := e.Run(ctx, "prompt")
+	require.ErrorIs(t, result.Error, context.Canceled)
+}
@@ -0,0 +1,148 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// DefaultOllamaURL is the address of a local ollama server.
+const DefaultOllamaURL = "http://localhost:11434"
+
+// ollamaSignalPrompt is the system prompt describing the signal protocol, local models don't know it.
+var ollamaSignalPrompt = fmt.Sprintf(`You are driven by an automation tool that reads special markers from your reply.
+End your reply with exactly one marker on its own line when it applies:
+- %s when all tasks of the plan are done
+- %s when you can't complete the task
+- %s when the review found nothing left to fix
+- %s when the plan is written
+Write markers exactly as shown, including the angle brackets.`,
+	status.Completed, status.Failed, status.ReviewDone, status.PlanReady)
+
+// heuristic signals for replies without markers, checked in order
+var ollamaHeuristics = []struct {
+	re     *regexp.Regexp
+	signal string
+}{
+	{re: regexp.MustCompile(`(?i)\ball (?:the |of the )?tasks (?:are |have been )?(?:now )?(?:completed?|done|finished)\b`), signal: status.Completed},
+	{re: regexp.MustCompile(`(?i)\bno (?:more |further )?(?:issues|problems|findings) (?:were |have been )?found\b`), signal: status.ReviewDone},
+}
+
+// ollamaRequest is the body of POST /api/generate.
+type ollamaRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	System string `json:"system,omitempty"`
+	Stream bool   `json:"stream"`
+}
+
+// ollamaChunk is a single line of the /api/generate stream.
+type ollamaChunk struct {
+	Response        string `json:"response"`
+	Done            bool   `json:"done"`
+	Error           string `json:"error"`
+	PromptEvalCount int    `json:"prompt_eval_count"` // final chunk only
+	EvalCount       int    `json:"eval_count"`        // final chunk only
+}
+
+// OllamaExecutor runs prompts against a local ollama server via its HTTP API.
+// the model has no tools, it only sees the prompt and answers with text. signals are taken from
+// <<<RALPHEX:...>>> markers, or guessed from the reply with completion heuristics when there are none.
+type OllamaExecutor struct {
+	URL           string            // server address, defaults to DefaultOllamaURL
+	Model         string            // model name, required
+	InjectSignals bool              // send ollamaSignalPrompt as the system prompt
+	OutputHandler func(text string) // called for each text chunk, can be nil
+	RawHandler    func(line string) // called with each raw output line before parsing, can be nil
+	Debug         bool              // enable debug output
+	ErrorPatterns []string          // patterns to detect in output
+	RegexPatterns []ErrorPattern    // user-defined regex patterns, checked before ErrorPatterns
+}
+
+// Run sends the prompt to ollama and streams the generated text.
+func (e *OllamaExecutor) Run(ctx context.Context, prompt string) Result {
+	if e.Model == "" {
+		return Result{Error: errors.New("ollama model is not set")}
+	}
+	baseURL := e.URL
+	if baseURL == "" {
+		baseURL = DefaultOllamaURL
+	}
+
+	req := ollamaRequest{Model: e.Model, Prompt: prompt, Stream: true}
+	if e.InjectSignals {
+		req.System = ollamaSignalPrompt
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return Result{Error: fmt.Errorf("marshal ollama request: %w", err)}
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return Result{Error: fmt.Errorf("create ollama request: %w", err)}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// no client timeout, the runner bounds each call with executor_timeout_ms
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return Result{Error: ctx.Err()}
+		}
+		return Result{Error: fmt.Errorf("ollama request: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var chunk ollamaChunk
+		if json.Unmarshal(msg, &chunk) == nil && chunk.Error != "" {
+			return Result{Error: fmt.Errorf("ollama returned %s: %s", resp.Status, chunk.Error)}
+		}
+		return Result{Error: fmt.Errorf("ollama returned %s", resp.Status)}
+	}
+
+	var inTokens, outTokens int
+	result := scanStream(ctx, resp.Body, e.Debug, e.OutputHandler, e.RawHandler, func(line []byte) (string, bool) {
+		var chunk ollamaChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return "", false
+		}
+		if chunk.Error != "" {
+			return "error: " + chunk.Error + "\n", true
+		}
+		if chunk.Done {
+			inTokens, outTokens = chunk.PromptEvalCount, chunk.EvalCount
+		}
+		return chunk.Response, true
+	})
+	result.InputTokens, result.OutputTokens = inTokens, outTokens
+	if result.Error != nil {
+		if ctx.Err() != nil {
+			result.Error = ctx.Err()
+		}
+		return result
+	}
+	if result.Signal == "" {
+		result.Signal = heuristicSignal(result.Output)
+	}
+	return finishRun(ctx, "ollama", result, nil, e.ErrorPatterns, e.RegexPatterns, "ollama ps")
+}
+
+// heuristicSignal guesses the signal of a reply without markers, empty if nothing matches.
+func heuristicSignal(output string) string {
+	for _, h := range ollamaHeuristics {
+		if h.re.MatchString(output) {
+			return h.signal
+		}
+	}
+	return ""
+}
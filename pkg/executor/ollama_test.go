@@ -0,0 +1,132 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// ollamaServer starts a fake ollama server replying with the given status and body, records request bodies.
+func ollamaServer(t *testing.T, code int, body string) (srv *httptest.Server, requests *[]ollamaRequest) {
+	t.Helper()
+	var reqs []ollamaRequest
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/generate", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+		var req ollamaRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		reqs = append(reqs, req)
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &reqs
+}
+
+func TestOllamaExecutor_Run(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       int
+		body       string
+		patterns   []string
+		wantOutput string
+		wantSignal string
+		wantErr    string
+		wantIn     int
+		wantOut    int
+	}{
+		{name: "streamed tokens with marker", code: http.StatusOK,
+			body: `{"response":"done ","done":false}` + "\n" + `{"response":"<<<RALPHEX:ALL_TASKS_DONE>>>","done":false}` + "\n" +
+				`{"response":"","done":true,"prompt_eval_count":120,"eval_count":15}`,
+			wantOutput: "done <<<RALPHEX:ALL_TASKS_DONE>>>", wantSignal: status.Completed, wantIn: 120, wantOut: 15},
+		{name: "completion heuristic", code: http.StatusOK, body: `{"response":"All tasks are completed.","done":true}`,
+			wantOutput: "All tasks are completed.", wantSignal: status.Completed},
+		{name: "review heuristic", code: http.StatusOK, body: `{"response":"I reviewed the diff, no issues found.","done":true}`,
+			wantOutput: "I reviewed the diff, no issues found.", wantSignal: status.ReviewDone},
+		{name: "no signal", code: http.StatusOK, body: `{"response":"working on task 2","done":true}`, wantOutput: "working on task 2"},
+		{name: "marker wins over heuristic", code: http.StatusOK,
+			body:       `{"response":"all tasks done? no. <<<RALPHEX:TASK_FAILED>>>","done":true}`,
+			wantOutput: "all tasks done? no. <<<RALPHEX:TASK_FAILED>>>", wantSignal: status.Failed},
+		{name: "error in stream matches pattern", code: http.StatusOK, body: `{"error":"model requires more system memory"}`,
+			patterns: []string{"more system memory"}, wantOutput: "error: model requires more system memory\n",
+			wantErr: `detected error pattern: "more system memory"`},
+		{name: "http error with message", code: http.StatusNotFound, body: `{"error":"model \"llama9\" not found, try pulling it first"}`,
+			wantErr: `ollama returned 404 Not Found: model "llama9" not found, try pulling it first`},
+		{name: "http error without message", code: http.StatusInternalServerError, body: "boom",
+			wantErr: "ollama returned 500 Internal Server Error"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, _ := ollamaServer(t, tc.code, tc.body)
+			var chunks []string
+			e := &OllamaExecutor{URL: srv.URL, Model: "llama3", ErrorPatterns: tc.patterns,
+				OutputHandler: func(text string) { chunks = append(chunks, text) }}
+
+			result := e.Run(context.Background(), "do the task")
+
+			assert.Equal(t, tc.wantOutput, result.Output)
+			assert.Equal(t, tc.wantSignal, result.Signal)
+			assert.Equal(t, tc.wantOutput, strings.Join(chunks, ""))
+			assert.Equal(t, tc.wantIn, result.InputTokens)
+			assert.Equal(t, tc.wantOut, result.OutputTokens)
+			if tc.wantErr == "" {
+				require.NoError(t, result.Error)
+				return
+			}
+			require.ErrorContains(t, result.Error, tc.wantErr)
+		})
+	}
+}
+
+func TestOllamaExecutor_Run_Request(t *testing.T) {
+	tests := []struct {
+		name       string
+		inject     bool
+		wantSystem string
+	}{
+		{name: "signal prompt injected", inject: true, wantSystem: ollamaSignalPrompt},
+		{name: "no system prompt", inject: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, reqs := ollamaServer(t, http.StatusOK, `{"response":"ok","done":true}`)
+			e := &OllamaExecutor{URL: srv.URL + "/", Model: "qwen2.5-coder", InjectSignals: tc.inject}
+
+			require.NoError(t, e.Run(context.Background(), "the prompt").Error)
+			require.Len(t, *reqs, 1)
+			assert.Equal(t, ollamaRequest{Model: "qwen2.5-coder", Prompt: "the prompt", System: tc.wantSystem, Stream: true}, (*reqs)[0])
+		})
+	}
+}
+
+func TestOllamaExecutor_Run_Errors(t *testing.T) {
+	t.Run("model not set", func(t *testing.T) {
+		result := (&OllamaExecutor{}).Run(context.Background(), "prompt")
+		require.EqualError(t, result.Error, "ollama model is not set")
+	})
+
+	t.Run("server unreachable", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		srv.Close()
+		result := (&OllamaExecutor{URL: srv.URL, Model: "llama3"}).Run(context.Background(), "prompt")
+		require.ErrorContains(t, result.Error, "ollama request:")
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		srv, _ := ollamaServer(t, http.StatusOK, `{"response":"ok","done":true}`)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		result := (&OllamaExecutor{URL: srv.URL, Model: "llama3"}).Run(ctx, "prompt")
+		require.ErrorIs(t, result.Error, context.Canceled)
+	})
+}
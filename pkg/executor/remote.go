@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// remoteLookPathTimeout limits the dependency check through the wrapper, e.g. an ssh connection
+const remoteLookPathTimeout = 30 * time.Second
+
+// shellSafeRe matches words that need no quoting in a POSIX shell command line
+var shellSafeRe = regexp.MustCompile(`^[\w@%+=:,./-]+$`)
+
+// Remote runs agent commands through a wrapper command, e.g. "ssh devbox --", while git stays local.
+// the agent command line is passed to the wrapper as a single shell command, run in the remote
+// working directory. the prompt is sent on stdin, so it is neither limited by argv size
+// nor quoted through the remote shell.
+type Remote struct {
+	Wrapper string            // wrapper command (space-separated), e.g. "ssh devbox --"
+	PathMap map[string]string // local path prefix -> remote path prefix, applied to the prompt and working directory
+	Dir     string            // local working directory, empty uses the current one
+}
+
+// MapPaths replaces local path prefixes in text with their remote counterparts.
+// longer prefixes win, each part of the text is replaced at most once.
+func (r *Remote) MapPaths(text string) string {
+	if len(r.PathMap) == 0 {
+		return text
+	}
+	locals := make([]string, 0, len(r.PathMap))
+	for local := range r.PathMap {
+		if local != "" {
+			locals = append(locals, local)
+		}
+	}
+	sort.Slice(locals, func(i, j int) bool {
+		if len(locals[i]) != len(locals[j]) {
+			return len(locals[i]) > len(locals[j])
+		}
+		return locals[i] < locals[j]
+	})
+	pairs := make([]string, 0, 2*len(locals))
+	for _, local := range locals {
+		pairs = append(pairs, local, r.PathMap[local])
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// Command returns the wrapper command and its arguments running name with args in the remote working directory.
+// an empty wrapper runs the command locally as is.
+func (r *Remote) Command(name string, args ...string) (string, []string) {
+	wrapper := splitArgs(r.Wrapper)
+	if len(wrapper) == 0 {
+		return name, args
+	}
+
+	words := make([]string, 0, len(args)+1)
+	for _, w := range append([]string{name}, args...) {
+		words = append(words, shellQuote(w))
+	}
+	line := strings.Join(words, " ")
+
+	dir := r.Dir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	if dir != "" {
+		line = "cd " + shellQuote(r.MapPaths(dir)) + " && " + line
+	}
+	return wrapper[0], append(wrapper[1:], line)
+}
+
+// LookPath checks that the command is available on the remote side, running "which name" through the wrapper.
+func (r *Remote) LookPath(name string) error {
+	wrapper := splitArgs(r.Wrapper)
+	if len(wrapper) == 0 {
+		return errors.New("empty command wrapper")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteLookPathTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, wrapper[0], append(wrapper[1:], "which "+shellQuote(name))...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %w, output: %s", r.Wrapper, err, msg)
+		}
+		return fmt.Errorf("%s: %w", r.Wrapper, err)
+	}
+	return nil
+}
+
+// shellQuote quotes s as a single word for a POSIX shell.
+func shellQuote(s string) string {
+	if shellSafeRe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,162 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemote_MapPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		pathMap map[string]string
+		text    string
+		want    string
+	}{
+		{name: "no map", text: "read /home/me/src/app/plan.md", want: "read /home/me/src/app/plan.md"},
+		{name: "prefix replaced everywhere", pathMap: map[string]string{"/home/me/src": "/srv/src"},
+			text: "read /home/me/src/app/plan.md and /home/me/src/app/progress.txt",
+			want: "read /srv/src/app/plan.md and /srv/src/app/progress.txt"},
+		{name: "longer prefix wins", pathMap: map[string]string{"/home/me": "/home/dev", "/home/me/src": "/srv/src"},
+			text: "/home/me/src/app and /home/me/notes", want: "/srv/src/app and /home/dev/notes"},
+		{name: "replaced text is not mapped again", pathMap: map[string]string{"/a": "/b", "/b": "/c"},
+			text: "/a/x /b/y", want: "/b/x /c/y"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Remote{Wrapper: "ssh devbox --", PathMap: tc.pathMap}
+			assert.Equal(t, tc.want, r.MapPaths(tc.text))
+		})
+	}
+}
+
+func TestRemote_Command(t *testing.T) {
+	tests := []struct {
+		name     string
+		remote   Remote
+		wantName string
+		wantArgs []string
+	}{
+		{name: "ssh wrapper", remote: Remote{Wrapper: "ssh devbox --", Dir: "/home/me/src/app",
+			PathMap: map[string]string{"/home/me/src": "/srv/src"}},
+			wantName: "ssh", wantArgs: []string{"devbox", "--", "cd /srv/src/app && claude --verbose -p"}},
+		{name: "words are quoted", remote: Remote{Wrapper: "ssh devbox --", Dir: "/home/me/my app"},
+			wantName: "ssh", wantArgs: []string{"devbox", "--", `cd '/home/me/my app' && claude --verbose -p`}},
+		{name: "empty wrapper runs locally", remote: Remote{Dir: "/home/me/src/app"},
+			wantName: "claude", wantArgs: []string{"--verbose", "-p"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			name, args := tc.remote.Command("claude", "--verbose", "-p")
+			assert.Equal(t, tc.wantName, name)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{in: "--output-format", want: "--output-format"},
+		{in: "model=gpt-5", want: "model=gpt-5"},
+		{in: `model="gpt-5"`, want: `'model="gpt-5"'`},
+		{in: "it's", want: `'it'\''s'`},
+		{in: "", want: "''"},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, shellQuote(tc.in), tc.in)
+	}
+}
+
+func TestRemote_LookPath(t *testing.T) {
+	wrapper := fakeWrapper(t)
+
+	r := &Remote{Wrapper: wrapper}
+	require.NoError(t, r.LookPath("sh"))
+
+	err := r.LookPath("nonexistent-command-12345")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), wrapper)
+
+	require.EqualError(t, (&Remote{}).LookPath("sh"), "empty command wrapper")
+}
+
+func TestClaudeExecutor_Run_Remote(t *testing.T) {
+	local, remoteDir := t.TempDir(), t.TempDir()
+	claude := fakeAgent(t, remoteDir,
+		`echo '{"type":"content_block_delta","delta":{"type":"text_delta","text":"done <<<RALPHEX:ALL_TASKS_DONE>>>"}}'`)
+
+	e := &ClaudeExecutor{
+		Command: claude,
+		Args:    "--output-format stream-json",
+		Remote:  &Remote{Wrapper: fakeWrapper(t), Dir: local, PathMap: map[string]string{local: remoteDir}},
+	}
+	result := e.Run(context.Background(), "read "+local+"/plan.md, it's long")
+	require.NoError(t, result.Error)
+	assert.Equal(t, "<<<RALPHEX:ALL_TASKS_DONE>>>", result.Signal)
+
+	assertFakeAgentCall(t, remoteDir, "--output-format stream-json -p", "read "+remoteDir+"/plan.md, it's long")
+}
+
+func TestCodexExecutor_Run_Remote(t *testing.T) {
+	local, remoteDir := t.TempDir(), t.TempDir()
+	codex := fakeAgent(t, remoteDir, `echo "no issues found"`)
+
+	e := &CodexExecutor{
+		Command:    codex,
+		Model:      "gpt-5",
+		ProjectDoc: local + "/CLAUDE.md",
+		Remote:     &Remote{Wrapper: fakeWrapper(t), Dir: local, PathMap: map[string]string{local: remoteDir}},
+	}
+	result := e.Run(context.Background(), "review changes in "+local)
+	require.NoError(t, result.Error)
+	assert.Equal(t, "no issues found\n", result.Output)
+
+	wantArgs := `exec --sandbox read-only -c model="gpt-5" -c model_reasoning_effort=xhigh -c stream_idle_timeout_ms=3600000 ` +
+		`-c project_doc="` + remoteDir + `/CLAUDE.md" -`
+	if os.Getenv("RALPHEX_DOCKER") == "1" {
+		wantArgs = ""
+	}
+	assertFakeAgentCall(t, remoteDir, wantArgs, "review changes in "+remoteDir)
+}
+
+// fakeWrapper creates a script standing in for "ssh host --": it runs its arguments as a shell command line.
+func fakeWrapper(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fake-ssh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\nexec sh -c \"$*\"\n"), 0o700)) //nolint:gosec // test script
+	return path
+}
+
+// fakeAgent creates an agent script recording its working directory, arguments and stdin in dir, then running body.
+func fakeAgent(t *testing.T, dir, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fake-agent")
+	script := "#!/bin/sh\npwd > " + dir + "/pwd\necho \"$*\" > " + dir + "/args\ncat > " + dir + "/stdin\n" + body + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o700)) //nolint:gosec // test script
+	return path
+}
+
+// assertFakeAgentCall checks that the fake agent ran in dir with the given arguments and prompt on stdin.
+// empty wantArgs skips the arguments check.
+func assertFakeAgentCall(t *testing.T, dir, wantArgs, wantStdin string) {
+	t.Helper()
+	pwd, err := os.ReadFile(filepath.Join(dir, "pwd")) //nolint:gosec // test file
+	require.NoError(t, err)
+	wantDir, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	assert.Equal(t, wantDir+"\n", string(pwd))
+
+	if wantArgs != "" {
+		args, err := os.ReadFile(filepath.Join(dir, "args")) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.Equal(t, wantArgs+"\n", string(args))
+	}
+
+	stdin, err := os.ReadFile(filepath.Join(dir, "stdin")) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Equal(t, wantStdin, string(stdin))
+}
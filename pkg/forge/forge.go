@@ -0,0 +1,141 @@
+// Package forge opens pull requests for feature branches via the hosting service CLI.
+package forge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// remoteURLRe matches git remote URLs in https, ssh:// and scp-like (git@host:owner/repo) forms,
+// capturing the host and the repository path without the .git suffix.
+var remoteURLRe = regexp.MustCompile(`^(?:(?:https?|ssh)://)?(?:[^@/]+@)?([^/:]+)(?::\d+)?[:/](.+?)(?:\.git)?/?$`)
+
+//go:generate moq -out mocks/command_runner.go -pkg mocks -skip-ensure -fmt goimports . CommandRunner
+
+// CommandRunner abstracts command execution for testing.
+// Returns trimmed stdout of the command.
+type CommandRunner interface {
+	Run(ctx context.Context, name string, args ...string) (string, error)
+}
+
+// execRunner is the default command runner using os/exec.
+type execRunner struct{}
+
+// Run executes the command and returns its stdout, stderr is included in the error on failure.
+func (r *execRunner) Run(ctx context.Context, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// PullRequest describes a pull request to open.
+type PullRequest struct {
+	Title string
+	Body  string
+	Base  string // branch to merge into, empty for the repository default
+	Head  string // feature branch, must be pushed already
+}
+
+// GitHub opens pull requests with the gh CLI.
+type GitHub struct {
+	cmdRunner CommandRunner
+}
+
+// NewGitHub creates a GitHub forge using the gh CLI from PATH.
+func NewGitHub() *GitHub {
+	return &GitHub{cmdRunner: &execRunner{}}
+}
+
+// CheckDep checks that the gh command is available in PATH.
+func (g *GitHub) CheckDep() error {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return errors.New("gh not found in PATH")
+	}
+	return nil
+}
+
+// CreatePullRequest opens the pull request and returns its URL as printed by gh.
+func (g *GitHub) CreatePullRequest(ctx context.Context, pr PullRequest) (string, error) {
+	if pr.Head == "" {
+		return "", errors.New("create pull request: head branch is required")
+	}
+	args := []string{"pr", "create", "--title", pr.Title, "--body", pr.Body, "--head", pr.Head}
+	if pr.Base != "" {
+		args = append(args, "--base", pr.Base)
+	}
+	out, err := g.cmdRunner.Run(ctx, "gh", args...)
+	if err != nil {
+		return "", fmt.Errorf("create pull request: %w", err)
+	}
+	return out, nil
+}
+
+// FindPullRequest returns the URL of the open pull request for the head branch, empty if there is none.
+func (g *GitHub) FindPullRequest(ctx context.Context, head string) (string, error) {
+	out, err := g.cmdRunner.Run(ctx, "gh", "pr", "list", "--head", head, "--state", "open", "--json", "url", "--jq", ".[0].url // empty")
+	if err != nil {
+		return "", fmt.Errorf("find pull request: %w", err)
+	}
+	return out, nil
+}
+
+// CompareURL returns the web page for opening a pull request of head against base,
+// e.g. https://github.com/owner/repo/compare/master...add-auth?expand=1.
+// returns empty string if the remote URL is not recognized.
+func CompareURL(remoteURL, base, head string) string {
+	m := remoteURLRe.FindStringSubmatch(strings.TrimSpace(remoteURL))
+	if m == nil {
+		return ""
+	}
+	compare := head
+	if base != "" {
+		compare = base + "..." + head
+	}
+	return "https://" + m[1] + "/" + m[2] + "/compare/" + compare + "?expand=1"
+}
+
+// Title derives a pull request title from a branch name, e.g. "add-user-auth" -> "Add user auth".
+func Title(branch string) string {
+	title := strings.Join(strings.FieldsFunc(branch, func(r rune) bool { return r == '-' || r == '_' }), " ")
+	r, size := utf8.DecodeRuneInString(title)
+	if size == 0 {
+		return branch
+	}
+	return string(unicode.ToUpper(r)) + title[size:]
+}
+
+// Body builds a pull request body from the plan content, subjects of commits made during the run
+// and a footer separated by a horizontal rule. empty parts are omitted.
+func Body(planContent string, commits []string, footer string) string {
+	var parts []string
+	if planContent = strings.TrimSpace(planContent); planContent != "" {
+		parts = append(parts, planContent+"\n")
+	}
+	if len(commits) > 0 {
+		var sb strings.Builder
+		sb.WriteString("## Commits\n\n")
+		for _, c := range commits {
+			sb.WriteString("- " + c + "\n")
+		}
+		parts = append(parts, sb.String())
+	}
+	if footer = strings.TrimSpace(footer); footer != "" {
+		parts = append(parts, "---\n\n"+footer+"\n")
+	}
+	return strings.Join(parts, "\n")
+}
@@ -13,7 +13,8 @@ import (
 
 // externalBackend implements the backend interface by shelling out to the git CLI.
 type externalBackend struct {
-	path string // absolute path to repository root
+	path   string // absolute path to repository root
+	noSign bool   // pass --no-gpg-sign to commits, overriding commit.gpgsign
 }
 
 // newExternalBackend creates an externalBackend that shells out to the git CLI.
@@ -51,8 +52,16 @@ func newExternalBackend(path string) (*externalBackend, error) {
 // leading whitespace is preserved (important for porcelain format parsing).
 // on failure, returns error with the combined output for diagnostics.
 func (e *externalBackend) run(args ...string) (string, error) {
+	return e.runEnv(nil, args...)
+}
+
+// runEnv works like run, with extra environment variables (KEY=value) added to the git process.
+func (e *externalBackend) runEnv(env []string, args ...string) (string, error) {
 	cmd := exec.CommandContext(context.Background(), "git", args...)
 	cmd.Dir = e.path
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		msg := strings.TrimSpace(string(out))
@@ -180,73 +189,113 @@ func (e *externalBackend) CheckoutBranch(name string) error {
 	return nil
 }
 
-// IsDirty returns true if the worktree has uncommitted changes (staged or modified tracked files).
-func (e *externalBackend) IsDirty() (bool, error) {
-	out, err := e.run("status", "--porcelain")
-	if err != nil {
-		return false, fmt.Errorf("get status: %w", err)
+// Stash stashes uncommitted changes, untracked files included, leaving the keep paths in the worktree.
+// returns the hash of the stash commit, or empty string if there was nothing to stash.
+func (e *externalBackend) Stash(message string, keep ...string) (string, error) {
+	before, _ := e.run("rev-parse", "-q", "--verify", "refs/stash")
+
+	args := []string{"stash", "push", "--include-untracked", "-m", message, "--", "."}
+	for _, path := range keep {
+		rel, err := e.toRelative(path)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, ":(exclude)"+filepath.ToSlash(rel))
 	}
-	if out == "" {
-		return false, nil
+	if _, err := e.run(args...); err != nil {
+		return "", fmt.Errorf("stash changes: %w", err)
 	}
 
-	// check each line - only count tracked changes (not untracked files marked with ??)
-	for line := range strings.SplitSeq(out, "\n") {
-		if line == "" {
+	after, err := e.run("rev-parse", "-q", "--verify", "refs/stash")
+	if err != nil || after == before {
+		return "", nil // nothing stashed
+	}
+	return after, nil
+}
+
+// StashPop applies the stash entry with the given commit hash and drops it.
+// git keeps the entry when applying it fails, e.g. on a conflict.
+func (e *externalBackend) StashPop(hash string) error {
+	out, err := e.run("stash", "list", "--format=%H")
+	if err != nil {
+		return fmt.Errorf("list stashes: %w", err)
+	}
+	for i, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) != hash {
 			continue
 		}
-		// untracked files (lines starting with "??") don't count as dirty
-		if strings.HasPrefix(line, "??") {
-			continue
+		if _, err := e.run("stash", "pop", fmt.Sprintf("stash@{%d}", i)); err != nil {
+			return fmt.Errorf("pop stash: %w", err)
 		}
-		return true, nil
+		return nil
 	}
-	return false, nil
+	return fmt.Errorf("stash %s not found", hash)
 }
 
-// FileHasChanges returns true if the given file has uncommitted changes.
-func (e *externalBackend) FileHasChanges(path string) (bool, error) {
-	rel, err := e.toRelative(path)
+// Status returns the changed, untracked and ignored paths of the worktree.
+// uses the NUL-separated porcelain format, so paths are never quoted or escaped.
+func (e *externalBackend) Status() ([]FileStatus, error) {
+	// -uall lists individual untracked files, --ignored=matching lists ignored directories without their content
+	out, err := e.run("status", "--porcelain", "-z", "-uall", "--ignored=matching")
 	if err != nil {
-		return false, err
+		return nil, fmt.Errorf("get status: %w", err)
 	}
+	return parseStatus(out), nil
+}
 
-	// use -uall to list individual files, not collapsed directories
-	out, err := e.run("status", "--porcelain", "-uall", "--", rel)
+// IsDirty returns true if the worktree has uncommitted changes (staged or modified tracked files).
+func (e *externalBackend) IsDirty() (bool, error) {
+	files, err := e.Status()
 	if err != nil {
-		return false, fmt.Errorf("check file status: %w", err)
+		return false, err
 	}
-	return out != "", nil
+	for _, f := range files {
+		if f.State == FileTracked {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
-// HasChangesOtherThan returns true if there are uncommitted changes to files other than the given file.
-// this includes modified/deleted tracked files, staged changes, and untracked files (excluding gitignored).
-func (e *externalBackend) HasChangesOtherThan(path string) (bool, error) {
+// FileHasChanges returns true if the given file has uncommitted changes or is untracked.
+func (e *externalBackend) FileHasChanges(path string) (bool, error) {
 	rel, err := e.toRelative(path)
 	if err != nil {
 		return false, err
 	}
-
-	// use -uall to list individual files, not collapsed directories
-	out, err := e.run("status", "--porcelain", "-uall")
+	files, err := e.Status()
 	if err != nil {
-		return false, fmt.Errorf("get status: %w", err)
+		return false, fmt.Errorf("check file status: %w", err)
 	}
-
-	if out == "" {
-		return false, nil
+	rel = filepath.ToSlash(rel)
+	for _, f := range files {
+		if f.State != FileIgnored && (f.Path == rel || f.OrigPath == rel) {
+			return true, nil
+		}
 	}
+	return false, nil
+}
 
-	for line := range strings.SplitSeq(out, "\n") {
-		if line == "" {
-			continue
+// HasChangesOtherThan returns true if there are uncommitted changes to files other than the given files.
+// this includes modified/deleted tracked files, staged changes, and untracked files (excluding gitignored).
+func (e *externalBackend) HasChangesOtherThan(paths ...string) (bool, error) {
+	excluded := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		rel, err := e.toRelative(path)
+		if err != nil {
+			return false, err
 		}
-		// extract file path from porcelain output: "XY path" or "XY path -> newpath"
-		filePath := e.extractPathFromPorcelain(line)
-		if filePath == rel {
-			continue
+		excluded[filepath.ToSlash(rel)] = true
+	}
+
+	files, err := e.Status()
+	if err != nil {
+		return false, err
+	}
+	for _, f := range files {
+		if f.State != FileIgnored && !excluded[f.Path] {
+			return true, nil
 		}
-		return true, nil
 	}
 	return false, nil
 }
@@ -301,13 +350,167 @@ func (e *externalBackend) MoveFile(src, dst string) error {
 
 // Commit creates a commit with the given message.
 func (e *externalBackend) Commit(msg string) error {
-	_, err := e.run("commit", "-m", msg)
-	if err != nil {
+	if err := e.commit(msg); err != nil {
 		return fmt.Errorf("commit: %w", err)
 	}
 	return nil
 }
 
+// setSigning enables or disables commit signing. when enabled, git signs commits per its own
+// config (commit.gpgsign, gpg.format, user.signingkey); when disabled, commits are never signed.
+func (e *externalBackend) setSigning(enabled bool) {
+	e.noSign = !enabled
+}
+
+// commit runs git commit with the message prefixed by the commit.template content, if configured.
+func (e *externalBackend) commit(msg string) error {
+	args := []string{"commit"}
+	if e.noSign {
+		args = append(args, "--no-gpg-sign")
+	}
+	args = append(args, "-m", e.withTemplate(msg))
+	if _, err := e.run(args...); err != nil {
+		if !e.noSign && strings.Contains(err.Error(), "sign") {
+			return fmt.Errorf("%w (set git_sign = false to commit unsigned)", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// withTemplate prepends the content of commit.template to msg.
+// comment lines are dropped, like git does for messages edited from the template.
+// an unset, unreadable or comment-only template leaves msg unchanged.
+func (e *externalBackend) withTemplate(msg string) string {
+	path, err := e.run("config", "--get", "commit.template")
+	if err != nil || path == "" {
+		return msg
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, herr := os.UserHomeDir()
+		if herr != nil {
+			return msg
+		}
+		path = filepath.Join(home, rest)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(e.path, path)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path from git config
+	if err != nil {
+		return msg
+	}
+
+	var lines []string
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	tmpl := strings.TrimSpace(strings.Join(lines, "\n"))
+	if tmpl == "" {
+		return msg
+	}
+	return tmpl + "\n\n" + msg
+}
+
+// Push pushes the branch to the remote and sets it as the upstream of the local branch.
+// credentials are resolved by git itself (credential helpers, ssh agent, environment);
+// terminal prompts are disabled, so missing credentials fail the push instead of blocking it.
+func (e *externalBackend) Push(remote, branch string) error {
+	_, err := e.runEnv([]string{"GIT_TERMINAL_PROMPT=0"}, "push", "--set-upstream", remote, branch)
+	if err != nil {
+		return fmt.Errorf("push: %w", err)
+	}
+	return nil
+}
+
+// RemoteURL returns the fetch URL of the named remote.
+func (e *externalBackend) RemoteURL(name string) (string, error) {
+	url, err := e.run("remote", "get-url", name)
+	if err != nil {
+		return "", fmt.Errorf("get remote url: %w", err)
+	}
+	return url, nil
+}
+
+// CommitSubjects returns subjects of commits in the from..to range, oldest first.
+func (e *externalBackend) CommitSubjects(from, to string) ([]string, error) {
+	out, err := e.run("log", "--reverse", "--format=%s", from+".."+to)
+	if err != nil {
+		return nil, fmt.Errorf("log: %w", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// MergeBase returns the hash of the best common ancestor of two commits, see `git merge-base`.
+func (e *externalBackend) MergeBase(a, b string) (string, error) {
+	out, err := e.run("merge-base", a, b)
+	if err != nil {
+		return "", fmt.Errorf("merge-base: %w", err)
+	}
+	return out, nil
+}
+
+// DiffStat returns the `git diff --stat` summary of changes between two commits, empty if there are none.
+func (e *externalBackend) DiffStat(from, to string) (string, error) {
+	out, err := e.run("diff", "--stat", from, to)
+	if err != nil {
+		return "", fmt.Errorf("diff: %w", err)
+	}
+	return out, nil
+}
+
+// DiffNames returns paths of files changed between two commits, relative to the repository root.
+func (e *externalBackend) DiffNames(from, to string) ([]string, error) {
+	out, err := e.run("diff", "--name-only", from, to)
+	if err != nil {
+		return nil, fmt.Errorf("diff: %w", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// RangeNames returns paths of files changed in a revision range, e.g. "master...HEAD",
+// relative to the repository root.
+func (e *externalBackend) RangeNames(revRange string) ([]string, error) {
+	out, err := e.run("diff", "--name-only", revRange, "--")
+	if err != nil {
+		return nil, fmt.Errorf("diff: %w", err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// AddWorktree creates a linked worktree at path with the branch checked out.
+// the branch is created from HEAD when create is true, otherwise an existing branch is used.
+func (e *externalBackend) AddWorktree(path, branch string, create bool) error {
+	args := []string{"worktree", "add", path, branch}
+	if create {
+		args = []string{"worktree", "add", "-b", branch, path}
+	}
+	if _, err := e.run(args...); err != nil {
+		return fmt.Errorf("add worktree: %w", err)
+	}
+	return nil
+}
+
+// RemoveWorktree removes a linked worktree. fails if the worktree has uncommitted changes.
+func (e *externalBackend) RemoveWorktree(path string) error {
+	if _, err := e.run("worktree", "remove", path); err != nil {
+		return fmt.Errorf("remove worktree: %w", err)
+	}
+	return nil
+}
+
 // CreateInitialCommit stages all non-ignored files and creates an initial commit.
 func (e *externalBackend) CreateInitialCommit(msg string) error {
 	// git add -A respects .gitignore natively
@@ -325,8 +528,7 @@ func (e *externalBackend) CreateInitialCommit(msg string) error {
 		return errors.New("no files to commit")
 	}
 
-	_, err = e.run("commit", "-m", msg)
-	if err != nil {
+	if err := e.commit(msg); err != nil {
 		return fmt.Errorf("commit: %w", err)
 	}
 	return nil
@@ -362,32 +564,66 @@ func (e *externalBackend) diffStats(baseBranch string) (DiffStats, error) {
 	if err != nil {
 		return DiffStats{}, fmt.Errorf("diff numstat: %w", err)
 	}
+	stats, _ := parseNumstat(out)
+	return stats, nil
+}
 
-	if out == "" {
-		return DiffStats{}, nil
+// ChangesBetween returns the commits and files changed between two commits.
+// returns ErrHistoryRewritten if from is not an ancestor of to, commits can't be counted then.
+func (e *externalBackend) ChangesBetween(from, to string) (RangeChanges, error) {
+	if _, err := e.run("merge-base", "--is-ancestor", from, to); err != nil {
+		return RangeChanges{}, fmt.Errorf("%w: %s is not an ancestor of %s", ErrHistoryRewritten, from, to)
 	}
+	count, err := e.run("rev-list", "--count", from+".."+to)
+	if err != nil {
+		return RangeChanges{}, fmt.Errorf("count commits: %w", err)
+	}
+	commits, err := strconv.Atoi(count)
+	if err != nil {
+		return RangeChanges{}, fmt.Errorf("count commits: %w", err)
+	}
+	out, err := e.run("diff", "--numstat", "--no-renames", from, to)
+	if err != nil {
+		return RangeChanges{}, fmt.Errorf("diff numstat: %w", err)
+	}
+	stats, files := parseNumstat(out)
+	return RangeChanges{Commits: commits, Files: files, Stats: stats}, nil
+}
 
-	var result DiffStats
+// UncommittedChanges returns tracked files with staged or unstaged changes against HEAD.
+func (e *externalBackend) UncommittedChanges() (RangeChanges, error) {
+	out, err := e.run("diff", "--numstat", "--no-renames", "HEAD")
+	if err != nil {
+		return RangeChanges{}, fmt.Errorf("diff numstat: %w", err)
+	}
+	stats, files := parseNumstat(out)
+	return RangeChanges{Files: files, Stats: stats}, nil
+}
+
+// parseNumstat parses `git diff --numstat` output into change totals and the changed paths.
+func parseNumstat(out string) (DiffStats, []string) {
+	var stats DiffStats
+	var files []string
 	for line := range strings.SplitSeq(out, "\n") {
 		if line == "" {
 			continue
 		}
-		parts := strings.Fields(line)
+		parts := strings.SplitN(line, "\t", 3)
 		if len(parts) < 3 {
 			continue
 		}
+		stats.Files++
+		files = append(files, parts[2])
 		// binary files show "-" for additions/deletions
 		if parts[0] == "-" || parts[1] == "-" {
-			result.Files++
 			continue
 		}
 		additions, _ := strconv.Atoi(parts[0])
 		deletions, _ := strconv.Atoi(parts[1])
-		result.Files++
-		result.Additions += additions
-		result.Deletions += deletions
+		stats.Additions += additions
+		stats.Deletions += deletions
 	}
-	return result, nil
+	return stats, files
 }
 
 // resolveRef tries to resolve a branch name to a valid git ref.
@@ -425,7 +661,7 @@ func (e *externalBackend) refExists(ref string) bool {
 func (e *externalBackend) toRelative(path string) (string, error) {
 	if !filepath.IsAbs(path) {
 		cleaned := filepath.Clean(path)
-		if strings.HasPrefix(cleaned, "..") {
+		if escapesRoot(cleaned) {
 			return "", fmt.Errorf("path %q escapes repository root", path)
 		}
 		return cleaned, nil
@@ -443,23 +679,40 @@ func (e *externalBackend) toRelative(path string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("path outside repository: %w", err)
 	}
-	if strings.HasPrefix(rel, "..") {
+	if escapesRoot(rel) {
 		return "", fmt.Errorf("path %q is outside repository root %q", path, e.path)
 	}
 	return rel, nil
 }
 
-// extractPathFromPorcelain extracts file path from git status --porcelain output.
-// format: "XY path" or "XY original -> renamed"
-func (e *externalBackend) extractPathFromPorcelain(line string) string {
-	if len(line) < 4 {
-		return ""
-	}
-	// skip the 2-char status code and space
-	path := line[3:]
-	// handle renames: "XY old -> new"
-	if idx := strings.Index(path, " -> "); idx >= 0 {
-		path = path[idx+4:]
+// escapesRoot reports whether a cleaned relative path points above its base directory.
+// "..name" is a file name, not a parent reference; both separators count on windows.
+func escapesRoot(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || strings.HasPrefix(rel, "../")
+}
+
+// parseStatus parses the output of git status --porcelain -z.
+// entries are "XY path", a rename or copy is followed by an entry with the source path.
+func parseStatus(out string) []FileStatus {
+	var files []FileStatus
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		f := FileStatus{Path: entry[3:], Index: entry[0], Worktree: entry[1], State: FileTracked}
+		switch entry[:2] {
+		case "??":
+			f.State = FileUntracked
+		case "!!":
+			f.State = FileIgnored
+		}
+		if (f.Index == 'R' || f.Index == 'C') && i+1 < len(entries) {
+			i++
+			f.OrigPath = entries[i]
+		}
+		files = append(files, f)
 	}
-	return path
+	return files
 }
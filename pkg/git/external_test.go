@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -317,6 +318,106 @@ func TestExternalBackend_CheckoutBranch(t *testing.T) {
 	})
 }
 
+func TestExternalBackend_Stash(t *testing.T) {
+	t.Run("stashes changes and untracked files except kept paths", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "plan.md"), []byte("# Plan"), 0o600))
+
+		hash, err := eb.Stash("test stash", filepath.Join(dir, "plan.md"))
+		require.NoError(t, err)
+		assert.NotEmpty(t, hash)
+
+		files, err := eb.Status()
+		require.NoError(t, err)
+		assert.Equal(t, []FileStatus{{Path: "plan.md", State: FileUntracked, Index: '?', Worktree: '?'}}, files)
+		assert.Contains(t, runGit(t, dir, "stash", "list"), "test stash")
+
+		require.NoError(t, eb.StashPop(hash))
+		content, err := os.ReadFile(filepath.Join(dir, "README.md")) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.Equal(t, "# Changed\n", string(content))
+		assert.FileExists(t, filepath.Join(dir, "new.txt"))
+		assert.Empty(t, runGit(t, dir, "stash", "list"))
+	})
+
+	t.Run("nothing to stash", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "plan.md"), []byte("# Plan"), 0o600))
+		hash, err := eb.Stash("test stash", "plan.md")
+		require.NoError(t, err)
+		assert.Empty(t, hash)
+	})
+
+	t.Run("pops the given entry when others were stashed later", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "first.txt"), []byte("first"), 0o600))
+		first, err := eb.Stash("first")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "second.txt"), []byte("second"), 0o600))
+		_, err = eb.Stash("second")
+		require.NoError(t, err)
+
+		require.NoError(t, eb.StashPop(first))
+		assert.FileExists(t, filepath.Join(dir, "first.txt"))
+		assert.NoFileExists(t, filepath.Join(dir, "second.txt"))
+		assert.Contains(t, runGit(t, dir, "stash", "list"), "second")
+	})
+
+	t.Run("unknown stash", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+
+		err = eb.StashPop("0123456789abcdef0123456789abcdef01234567")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+func TestExternalBackend_Status(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	eb, err := newExternalBackend(dir)
+	require.NoError(t, err)
+
+	files, err := eb.Status()
+	require.NoError(t, err)
+	assert.Empty(t, files, "clean worktree")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.log\nbuild/\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "old.txt"), []byte("old\n"), 0o600))
+	runGit(t, dir, "add", ".gitignore", "old.txt")
+	runGit(t, dir, "commit", "-m", "add gitignore")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Modified\n"), 0o600))
+	runGit(t, dir, "mv", "old.txt", "new.txt")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs", "plans"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "plans", "my plan.md"), []byte("# Plan"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "debug.log"), []byte("log"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "build", "bin"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "build", "bin", "app"), []byte("bin"), 0o600))
+
+	files, err = eb.Status()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []FileStatus{
+		{Path: "README.md", State: FileTracked, Index: ' ', Worktree: 'M'},
+		{Path: "new.txt", OrigPath: "old.txt", State: FileTracked, Index: 'R', Worktree: ' '},
+		{Path: "docs/plans/my plan.md", State: FileUntracked, Index: '?', Worktree: '?'},
+		{Path: "debug.log", State: FileIgnored, Index: '!', Worktree: '!'},
+		{Path: "build/", State: FileIgnored, Index: '!', Worktree: '!'},
+	}, files)
+}
+
 func TestExternalBackend_IsDirty(t *testing.T) {
 	t.Run("clean worktree returns false", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
@@ -516,6 +617,27 @@ func TestExternalBackend_HasChangesOtherThan(t *testing.T) {
 		require.NoError(t, err)
 		assert.True(t, has)
 	})
+
+	t.Run("returns false when all changed files are excluded", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+
+		plansDir := filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(plansDir, 0o750))
+		planA := filepath.Join("docs", "plans", "a.md")
+		planB := filepath.Join("docs", "plans", "b.md")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, planA), []byte("# A"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, planB), []byte("# B"), 0o600))
+
+		has, err := eb.HasChangesOtherThan(planA, planB)
+		require.NoError(t, err)
+		assert.False(t, has)
+
+		has, err = eb.HasChangesOtherThan(planA)
+		require.NoError(t, err)
+		assert.True(t, has)
+	})
 }
 
 func TestExternalBackend_IsIgnored(t *testing.T) {
@@ -643,6 +765,305 @@ func TestExternalBackend_Commit(t *testing.T) {
 	})
 }
 
+func TestExternalBackend_CommitSigning(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+
+	// setupSigningRepo configures ssh commit signing with a throwaway key
+	setupSigningRepo := func(t *testing.T) string {
+		t.Helper()
+		dir := setupExternalTestRepo(t)
+		key := filepath.Join(t.TempDir(), "id_ed25519")
+		out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput()
+		require.NoError(t, err, string(out))
+		runGit(t, dir, "config", "gpg.format", "ssh")
+		runGit(t, dir, "config", "user.signingkey", key)
+		runGit(t, dir, "config", "commit.gpgsign", "true")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "signed.txt"), []byte("test"), 0o600))
+		runGit(t, dir, "add", "signed.txt")
+		return dir
+	}
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		wantSigned bool
+	}{
+		{name: "signs per git config", enabled: true, wantSigned: true},
+		{name: "signing disabled", enabled: false, wantSigned: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := setupSigningRepo(t)
+			eb, err := newExternalBackend(dir)
+			require.NoError(t, err)
+			eb.setSigning(tc.enabled)
+
+			require.NoError(t, eb.Commit("signed commit"))
+			raw := runGit(t, dir, "cat-file", "commit", "HEAD")
+			assert.Equal(t, tc.wantSigned, strings.Contains(raw, "gpgsig"), raw)
+		})
+	}
+
+	t.Run("signing failure suggests git_sign", func(t *testing.T) {
+		dir := setupSigningRepo(t)
+		runGit(t, dir, "config", "user.signingkey", filepath.Join(t.TempDir(), "missing-key"))
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+
+		err = eb.Commit("signed commit")
+		require.ErrorContains(t, err, "git_sign = false")
+	})
+}
+
+func TestExternalBackend_CommitTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string // empty means no commit.template configured
+		want     string
+	}{
+		{name: "no template", want: "test commit"},
+		{name: "template prepended", template: "Refs: PROJ-1\n# describe the change\n", want: "Refs: PROJ-1\n\ntest commit"},
+		{name: "comment-only template", template: "# describe the change\n", want: "test commit"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := setupExternalTestRepo(t)
+			if tc.template != "" {
+				tmpl := filepath.Join(t.TempDir(), "commit-template.txt")
+				require.NoError(t, os.WriteFile(tmpl, []byte(tc.template), 0o600))
+				runGit(t, dir, "config", "commit.template", tmpl)
+			}
+			eb, err := newExternalBackend(dir)
+			require.NoError(t, err)
+
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "tmpl.txt"), []byte("test"), 0o600))
+			require.NoError(t, eb.Add("tmpl.txt"))
+			require.NoError(t, eb.Commit("test commit"))
+			out := runGit(t, dir, "log", "-1", "--format=%B")
+			assert.Equal(t, tc.want, strings.TrimSpace(out))
+		})
+	}
+
+	t.Run("relative template path", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitmessage"), []byte("Team: core\n"), 0o600))
+		runGit(t, dir, "config", "commit.template", ".gitmessage")
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+
+		require.NoError(t, eb.CreateInitialCommit("initial"))
+		out := runGit(t, dir, "log", "-1", "--format=%B")
+		assert.Equal(t, "Team: core\n\ninitial", strings.TrimSpace(out))
+	})
+}
+
+func TestExternalBackend_Push(t *testing.T) {
+	t.Run("pushes branch and sets upstream", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		remote := t.TempDir()
+		runGit(t, remote, "init", "--bare")
+		runGit(t, dir, "remote", "add", "origin", remote)
+		runGit(t, dir, "checkout", "-b", "feature")
+
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+		require.NoError(t, eb.Push("origin", "feature"))
+
+		assert.Equal(t, runGit(t, dir, "rev-parse", "HEAD"), runGit(t, remote, "rev-parse", "feature"))
+		assert.Equal(t, "origin/feature", strings.TrimSpace(runGit(t, dir, "rev-parse", "--abbrev-ref", "feature@{upstream}")))
+	})
+
+	t.Run("fails without remote", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+
+		err = eb.Push("origin", "master")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "push:")
+	})
+}
+
+func TestExternalBackend_RemoteURL(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	eb, err := newExternalBackend(dir)
+	require.NoError(t, err)
+
+	_, err = eb.RemoteURL("origin")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "get remote url:")
+
+	runGit(t, dir, "remote", "add", "origin", "https://github.com/owner/repo.git")
+	url, err := eb.RemoteURL("origin")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/owner/repo.git", url)
+}
+
+func TestExternalBackend_CommitSubjects(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	eb, err := newExternalBackend(dir)
+	require.NoError(t, err)
+	start, err := eb.headHash()
+	require.NoError(t, err)
+
+	subjects, err := eb.CommitSubjects(start, "HEAD")
+	require.NoError(t, err)
+	assert.Empty(t, subjects)
+
+	runGit(t, dir, "commit", "--allow-empty", "-m", "first change")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "second change")
+	subjects, err = eb.CommitSubjects(start, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first change", "second change"}, subjects)
+
+	_, err = eb.CommitSubjects("nonexistent", "HEAD")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "log:")
+}
+
+func TestExternalBackend_MergeBase(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	eb, err := newExternalBackend(dir)
+	require.NoError(t, err)
+	fork, err := eb.headHash()
+	require.NoError(t, err)
+
+	runGit(t, dir, "checkout", "-b", "feature")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "feature change")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "another feature change")
+	runGit(t, dir, "checkout", "master")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "master change")
+	runGit(t, dir, "checkout", "feature")
+
+	base, err := eb.MergeBase("master", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, fork, base, "the fork point, not the tip of master")
+
+	parent, err := eb.run("rev-parse", "HEAD~1")
+	require.NoError(t, err)
+	base, err = eb.MergeBase("HEAD~1", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, parent, base, "an ancestor is its own merge-base")
+
+	_, err = eb.MergeBase("nonexistent", "HEAD")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "merge-base:")
+}
+
+func TestExternalBackend_Diff(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	eb, err := newExternalBackend(dir)
+	require.NoError(t, err)
+	start, err := eb.headHash()
+	require.NoError(t, err)
+
+	stat, err := eb.DiffStat(start, "HEAD")
+	require.NoError(t, err)
+	assert.Empty(t, stat)
+	names, err := eb.DiffNames(start, "HEAD")
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "new.go"), []byte("package pkg\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Test\nmore\n"), 0o600))
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-m", "change files")
+
+	stat, err = eb.DiffStat(start, "HEAD")
+	require.NoError(t, err)
+	assert.Contains(t, stat, "README.md")
+	assert.Contains(t, stat, "pkg/new.go")
+	assert.Contains(t, stat, "2 files changed")
+
+	names, err = eb.DiffNames(start, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "pkg/new.go"}, names)
+
+	_, err = eb.DiffStat("nonexistent", "HEAD")
+	require.ErrorContains(t, err, "diff:")
+	_, err = eb.DiffNames("nonexistent", "HEAD")
+	require.ErrorContains(t, err, "diff:")
+}
+
+func TestExternalBackend_RangeNames(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	eb, err := newExternalBackend(dir)
+	require.NoError(t, err)
+
+	runGit(t, dir, "checkout", "-b", "feature")
+	names, err := eb.RangeNames("master...HEAD")
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "feature.go"), []byte("package main\n"), 0o600))
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-m", "add feature")
+	names, err = eb.RangeNames("master...HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feature.go"}, names)
+
+	_, err = eb.RangeNames("nonexistent...HEAD")
+	require.ErrorContains(t, err, "diff:")
+}
+
+func TestExternalBackend_Worktree(t *testing.T) {
+	t.Run("adds worktree with new branch and removes it", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+
+		wt := filepath.Join(t.TempDir(), "wt")
+		require.NoError(t, eb.AddWorktree(wt, "feature", true))
+		assert.Equal(t, "feature", strings.TrimSpace(runGit(t, wt, "rev-parse", "--abbrev-ref", "HEAD")))
+		assert.Equal(t, "master", strings.TrimSpace(runGit(t, dir, "rev-parse", "--abbrev-ref", "HEAD")), "main worktree untouched")
+
+		require.NoError(t, eb.RemoveWorktree(wt))
+		assert.NoDirExists(t, wt)
+		assert.True(t, eb.BranchExists("feature"), "branch is kept")
+	})
+
+	t.Run("adds worktree for existing branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		runGit(t, dir, "branch", "existing")
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+
+		wt := filepath.Join(t.TempDir(), "wt")
+		require.NoError(t, eb.AddWorktree(wt, "existing", false))
+		assert.Equal(t, "existing", strings.TrimSpace(runGit(t, wt, "rev-parse", "--abbrev-ref", "HEAD")))
+	})
+
+	t.Run("fails for branch checked out in main worktree", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+
+		err = eb.AddWorktree(filepath.Join(t.TempDir(), "wt"), "master", false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "add worktree:")
+	})
+
+	t.Run("remove fails for dirty worktree", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		eb, err := newExternalBackend(dir)
+		require.NoError(t, err)
+
+		wt := filepath.Join(t.TempDir(), "wt")
+		require.NoError(t, eb.AddWorktree(wt, "feature", true))
+		require.NoError(t, os.WriteFile(filepath.Join(wt, "new.txt"), []byte("x"), 0o600))
+
+		err = eb.RemoveWorktree(wt)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "remove worktree:")
+		assert.DirExists(t, wt)
+	})
+}
+
 func TestExternalBackend_CreateInitialCommit(t *testing.T) {
 	t.Run("creates commit with files", func(t *testing.T) {
 		dir := t.TempDir()
@@ -811,24 +1232,68 @@ func TestExternalBackend_toRelative(t *testing.T) {
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "outside repository")
 	})
-}
 
-func TestExternalBackend_extractPathFromPorcelain(t *testing.T) {
-	eb := &externalBackend{path: "/tmp"}
-
-	t.Run("extracts simple path", func(t *testing.T) {
-		assert.Equal(t, "file.txt", eb.extractPathFromPorcelain("?? file.txt"))
+	t.Run("accepts file name starting with dots", func(t *testing.T) {
+		rel, err := eb.toRelative("..notes.md")
+		require.NoError(t, err)
+		assert.Equal(t, "..notes.md", rel)
 	})
 
-	t.Run("extracts modified path", func(t *testing.T) {
-		assert.Equal(t, "README.md", eb.extractPathFromPorcelain(" M README.md"))
-	})
+	t.Run("native separators", func(t *testing.T) {
+		rel, err := eb.toRelative(filepath.FromSlash("docs/plans/../plans/test.md"))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.FromSlash("docs/plans/test.md"), rel)
 
-	t.Run("extracts renamed path", func(t *testing.T) {
-		assert.Equal(t, "new.txt", eb.extractPathFromPorcelain("R  old.txt -> new.txt"))
+		_, err = eb.toRelative(filepath.FromSlash("docs/../../outside.txt"))
+		require.ErrorContains(t, err, "escapes repository root")
 	})
+}
 
-	t.Run("returns empty for short line", func(t *testing.T) {
-		assert.Empty(t, eb.extractPathFromPorcelain("??"))
-	})
+func TestEscapesRoot(t *testing.T) {
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{rel: "..", want: true},
+		{rel: filepath.FromSlash("../x.md"), want: true},
+		{rel: "../x.md", want: true},
+		{rel: filepath.FromSlash("../../a/b"), want: true},
+		{rel: "..x.md", want: false},
+		{rel: filepath.FromSlash("a/../b"), want: false},
+		{rel: "x.md", want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.rel, func(t *testing.T) {
+			assert.Equal(t, tc.want, escapesRoot(tc.rel))
+		})
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want []FileStatus
+	}{
+		{name: "empty", out: ""},
+		{name: "modified and untracked", out: " M README.md\x00?? new file.txt\x00",
+			want: []FileStatus{
+				{Path: "README.md", State: FileTracked, Index: ' ', Worktree: 'M'},
+				{Path: "new file.txt", State: FileUntracked, Index: '?', Worktree: '?'},
+			}},
+		{name: "rename keeps the source path", out: "R  new.txt\x00old.txt\x00A  added.txt\x00",
+			want: []FileStatus{
+				{Path: "new.txt", OrigPath: "old.txt", State: FileTracked, Index: 'R', Worktree: ' '},
+				{Path: "added.txt", State: FileTracked, Index: 'A', Worktree: ' '},
+			}},
+		{name: "ignored directory", out: "!! build/\x00",
+			want: []FileStatus{{Path: "build/", State: FileIgnored, Index: '!', Worktree: '!'}}},
+		{name: "short entry skipped", out: "??\x00 D gone.txt",
+			want: []FileStatus{{Path: "gone.txt", State: FileTracked, Index: ' ', Worktree: 'D'}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseStatus(tc.out))
+		})
+	}
 }
@@ -1,14 +1,21 @@
 package git
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/umputun/ralphex/pkg/plan"
 )
 
+// invalidRefCharsRe matches characters git doesn't allow anywhere in a ref name.
+var invalidRefCharsRe = regexp.MustCompile(`[\x00-\x20\x7f~^:?*\[\\]`)
+
 //go:generate moq -out mocks/logger.go -pkg mocks -skip-ensure -fmt goimports . Logger
 
 // Logger provides logging for git operations output.
@@ -28,17 +35,51 @@ type backend interface {
 	BranchExists(name string) bool
 	CreateBranch(name string) error
 	CheckoutBranch(name string) error
+	Stash(message string, keep ...string) (string, error)
+	StashPop(hash string) error
+	Status() ([]FileStatus, error)
 	IsDirty() (bool, error)
 	FileHasChanges(path string) (bool, error)
-	HasChangesOtherThan(path string) (bool, error)
+	HasChangesOtherThan(paths ...string) (bool, error)
 	IsIgnored(path string) (bool, error)
 	Add(path string) error
 	MoveFile(src, dst string) error
 	Commit(msg string) error
+	setSigning(enabled bool)
 	CreateInitialCommit(msg string) error
+	Push(remote, branch string) error
+	RemoteURL(name string) (string, error)
+	CommitSubjects(from, to string) ([]string, error)
+	MergeBase(a, b string) (string, error)
+	DiffStat(from, to string) (string, error)
+	DiffNames(from, to string) ([]string, error)
+	RangeNames(revRange string) ([]string, error)
+	ChangesBetween(from, to string) (RangeChanges, error)
+	UncommittedChanges() (RangeChanges, error)
+	AddWorktree(path, branch string, create bool) error
+	RemoveWorktree(path string) error
 	diffStats(baseBranch string) (DiffStats, error)
 }
 
+// FileState classifies a path reported by Status.
+type FileState string
+
+// file states reported by Status
+const (
+	FileTracked   FileState = "tracked"   // tracked file with staged or unstaged changes
+	FileUntracked FileState = "untracked" // file not known to git and not ignored
+	FileIgnored   FileState = "ignored"   // path matched by gitignore rules
+)
+
+// FileStatus is a changed, untracked or ignored path in the worktree.
+type FileStatus struct {
+	Path     string    // slash-separated path relative to the repository root, ignored directories end with "/"
+	OrigPath string    // source path of a renamed or copied file, empty otherwise
+	State    FileState // tracked, untracked or ignored
+	Index    byte      // staged status code (X of git status --porcelain), ' ' if unchanged
+	Worktree byte      // unstaged status code (Y of git status --porcelain), ' ' if unchanged
+}
+
 // DiffStats holds statistics about changes between two commits.
 type DiffStats struct {
 	Files     int // number of files changed
@@ -46,13 +87,54 @@ type DiffStats struct {
 	Deletions int // lines deleted
 }
 
+// RangeChanges holds the commits and files changed between two commits.
+type RangeChanges struct {
+	Commits int       // commits reachable from the end but not from the start
+	Files   []string  // paths of changed files, relative to the repository root
+	Stats   DiffStats // changed line counts of the files
+}
+
+// ErrHistoryRewritten is returned by ChangedFilesBetween when the start commit is not an ancestor of the end,
+// e.g. after a rebase or an amended commit.
+var ErrHistoryRewritten = errors.New("history rewritten")
+
 // Service provides git operations for ralphex workflows.
 // It is the single public API for the git package.
 type Service struct {
-	repo backend
-	log  Logger
+	repo      backend
+	log       Logger
+	autoStash AutoStash
+	messages  CommitMessages
+}
+
+// AutoStash selects whether and where uncommitted changes to files other than the plan are
+// restored when ralphex stashes them to create the plan branch.
+type AutoStash string
+
+// auto-stash modes
+const (
+	AutoStashOff      AutoStash = ""         // refuse to create the branch on a dirty worktree
+	AutoStashFeature  AutoStash = "feature"  // stash around branch creation, restore on the feature branch
+	AutoStashOriginal AutoStash = "original" // stash for the whole run, restore on the starting branch after it
+)
+
+// CommitMessages holds templates of the commit messages ralphex makes for plan files.
+// {branch} (the current branch), {plan} (plan file name) and {date} (YYYY-MM-DD) are replaced,
+// an empty template falls back to the default.
+type CommitMessages struct {
+	AddPlan  string // commit of a new or changed plan file, "add plan: {branch}" by default
+	MovePlan string // commit moving a finished plan to the completed directory, "move completed plan: {plan}" by default
 }
 
+// default commit message templates
+const (
+	DefaultAddPlanMessage  = "add plan: {branch}"
+	DefaultMovePlanMessage = "move completed plan: {plan}"
+)
+
+// StashRef identifies a stash entry by its commit hash, empty when there was nothing to stash.
+type StashRef string
+
 // NewService opens a git repository and returns a Service.
 // path is the path to the repository (use "." for current directory).
 // log is used for progress output during operations.
@@ -69,6 +151,132 @@ func (s *Service) Root() string {
 	return s.repo.Root()
 }
 
+// SetSigning controls commit signing. enabled (the default) lets git sign commits per its
+// config (commit.gpgsign, gpg.format, user.signingkey); disabled forces unsigned commits.
+func (s *Service) SetSigning(enabled bool) {
+	s.repo.setSigning(enabled)
+}
+
+// SetAutoStash controls how uncommitted changes to other files are handled.
+// AutoStashOff (the default) makes CreateBranchForPlanAs refuse to create the branch, AutoStashFeature
+// makes it stash the changes and restore them on the feature branch, and AutoStashOriginal makes
+// StashForRun keep them stashed for the whole run, RestoreRunStash brings them back on the starting branch.
+func (s *Service) SetAutoStash(mode AutoStash) {
+	s.autoStash = mode
+}
+
+// SetCommitMessages sets the templates of plan commit messages, empty fields keep the defaults.
+func (s *Service) SetCommitMessages(m CommitMessages) {
+	s.messages = m
+}
+
+// AddPlanMessage renders the commit message of a plan file committed on branch.
+func (s *Service) AddPlanMessage(planFile, branch string) string {
+	return renderCommitMessage(cmp.Or(s.messages.AddPlan, DefaultAddPlanMessage), branch, planFile, time.Now())
+}
+
+// movePlanMessage renders the commit message of a plan moved to the completed directory.
+func (s *Service) movePlanMessage(planFile string) string {
+	branch, _ := s.repo.CurrentBranch() // empty on detached HEAD, the move is committed regardless
+	return renderCommitMessage(cmp.Or(s.messages.MovePlan, DefaultMovePlanMessage), branch, planFile, time.Now())
+}
+
+// renderCommitMessage replaces the {branch}, {plan} and {date} placeholders of a commit message template.
+func renderCommitMessage(tmpl, branch, planFile string, now time.Time) string {
+	return strings.NewReplacer("{branch}", branch, "{plan}", filepath.Base(planFile), "{date}", now.Format("2006-01-02")).Replace(tmpl)
+}
+
+// Stash stashes uncommitted changes, untracked files included, leaving the keep paths in the worktree.
+// returns an empty StashRef if there was nothing to stash.
+func (s *Service) Stash(message string, keep ...string) (StashRef, error) {
+	hash, err := s.repo.Stash(message, keep...)
+	if err != nil {
+		return "", fmt.Errorf("stash: %w", err)
+	}
+	return StashRef(hash), nil
+}
+
+// StashPop restores the stashed changes onto the current branch and drops the stash entry.
+// on a conflict the entry stays in the stash list, so the changes are never lost.
+func (s *Service) StashPop(ref StashRef) error {
+	if ref == "" {
+		return nil
+	}
+	if err := s.repo.StashPop(string(ref)); err != nil {
+		return fmt.Errorf("restore stashed changes, they are kept in the stash as %s (resolve and run git stash drop): %w",
+			ref, err)
+	}
+	return nil
+}
+
+// RunStash is a stash held for a whole run, restored onto the branch it was taken on.
+type RunStash struct {
+	Ref    StashRef // stash entry, empty when there was nothing to stash
+	Name   string   // stash message, ralphex-autostash-<timestamp>
+	Branch string   // branch the changes were stashed on
+}
+
+// StashForRun stashes uncommitted changes, leaving the keep paths (plan files) in the worktree,
+// under a ralphex-autostash-<timestamp> name. RestoreRunStash brings them back after the run.
+// a no-op returning an empty RunStash unless auto-stash is set to AutoStashOriginal.
+func (s *Service) StashForRun(keep ...string) (RunStash, error) {
+	if s.autoStash != AutoStashOriginal {
+		return RunStash{}, nil
+	}
+	branch, err := s.repo.CurrentBranch()
+	if err != nil {
+		return RunStash{}, fmt.Errorf("check current branch: %w", err)
+	}
+	if branch == "" {
+		return RunStash{}, errors.New("autostash needs a branch to restore the changes onto, HEAD is detached")
+	}
+	name := "ralphex-autostash-" + time.Now().Format("20060102-150405")
+	ref, err := s.Stash(name, keep...)
+	if err != nil {
+		return RunStash{}, err
+	}
+	return RunStash{Ref: ref, Name: name, Branch: branch}, nil
+}
+
+// RestoreRunStash switches back to the branch the stash was taken on and pops it there.
+// the stash is never popped onto another branch: if the switch is not possible (e.g. the run
+// left uncommitted changes on the feature branch) or the pop conflicts, the entry stays in
+// the stash list and the error tells how to restore it manually.
+func (s *Service) RestoreRunStash(st RunStash) error {
+	if st.Ref == "" {
+		return nil
+	}
+	entry := fmt.Sprintf("\"$(git stash list --format=%%gd --grep=%s)\"", st.Name)
+	recovery := fmt.Sprintf("stashed changes are kept as %s, restore them with:\n  git checkout %s && git stash pop %s",
+		st.Name, st.Branch, entry)
+
+	current, err := s.repo.CurrentBranch()
+	if err != nil {
+		return fmt.Errorf("check current branch: %w\n%s", err, recovery)
+	}
+	if current != st.Branch {
+		dirty, err := s.repo.IsDirty()
+		if err != nil {
+			return fmt.Errorf("check uncommitted changes: %w\n%s", err, recovery)
+		}
+		if dirty {
+			return fmt.Errorf("not restoring stashed changes onto %s, it has uncommitted changes\n%s", current, recovery)
+		}
+		s.log.Printf("switching back to %s\n", st.Branch)
+		if err := s.repo.CheckoutBranch(st.Branch); err != nil {
+			return fmt.Errorf("checkout branch %s: %w\n%s", st.Branch, err, recovery)
+		}
+	}
+
+	s.log.Printf("restoring stashed changes\n")
+	if err := s.repo.StashPop(string(st.Ref)); err != nil {
+		return fmt.Errorf("restore stashed changes: %w\n"+
+			"stashed changes are kept as %s, resolve the conflicts and drop it with:\n  git stash drop %s",
+			err, st.Name, entry)
+	}
+	return nil
+}
+
 // HeadHash returns the current HEAD commit hash as a hex string.
 func (s *Service) HeadHash() (string, error) {
 	return s.repo.headHash()
@@ -83,6 +291,12 @@ func (s *Service) CurrentBranch() (string, error) {
 	return branch, nil
 }
 
+// Status returns the changed, untracked and ignored paths of the worktree.
+// untracked files are listed individually, ignored directories as a whole.
+func (s *Service) Status() ([]FileStatus, error) {
+	return s.repo.Status()
+}
+
 // IsMainBranch returns true if the current branch is "main" or "master".
 func (s *Service) IsMainBranch() (bool, error) {
 	branch, err := s.repo.CurrentBranch()
@@ -120,6 +334,14 @@ func (s *Service) CreateBranch(name string) error {
 // If on main/master, extracts branch name from plan file and creates/switches to it.
 // If plan file has uncommitted changes and is the only dirty file, auto-commits it.
 func (s *Service) CreateBranchForPlan(planFile string) error {
+	return s.CreateBranchForPlanAs(planFile, plan.ExtractBranchName(planFile))
+}
+
+// CreateBranchForPlanAs works like CreateBranchForPlan but uses the given branch name.
+// uncommitted changes to files listed in pending (e.g. plans queued for later runs)
+// don't block branch creation and are not committed with the plan.
+// with AutoStashFeature, changes to other files are stashed and restored on the feature branch.
+func (s *Service) CreateBranchForPlanAs(planFile, branchName string, pending ...string) error {
 	currentBranch, err := s.repo.CurrentBranch()
 	if err != nil {
 		return fmt.Errorf("check current branch: %w", err)
@@ -129,14 +351,28 @@ func (s *Service) CreateBranchForPlan(planFile string) error {
 		return nil // already on feature branch
 	}
 
-	branchName := plan.ExtractBranchName(planFile)
-
 	// check for uncommitted changes to files other than the plan
-	hasOtherChanges, err := s.repo.HasChangesOtherThan(planFile)
+	keep := append([]string{planFile}, pending...)
+	hasOtherChanges, err := s.repo.HasChangesOtherThan(keep...)
 	if err != nil {
 		return fmt.Errorf("check uncommitted files: %w", err)
 	}
 
+	if hasOtherChanges && s.autoStash == AutoStashFeature {
+		s.log.Printf("stashing uncommitted changes\n")
+		stash, err := s.Stash("ralphex: before "+branchName, keep...)
+		if err != nil {
+			return err
+		}
+		branchErr := s.switchToPlanBranch(planFile, branchName)
+		// the changes come back either way, on the feature branch or where they were
+		s.log.Printf("restoring stashed changes\n")
+		if err := s.StashPop(stash); err != nil {
+			return errors.Join(branchErr, err)
+		}
+		return branchErr
+	}
+
 	if hasOtherChanges {
 		// other files have uncommitted changes - show helpful error
 		return fmt.Errorf("cannot create branch %q: worktree has uncommitted changes\n\n"+
@@ -144,10 +380,17 @@ func (s *Service) CreateBranchForPlan(planFile string) error {
 			"options:\n"+
 			"  git stash && ralphex %s && git stash pop   # stash changes temporarily\n"+
 			"  git commit -am \"wip\"                       # commit changes first\n"+
+			"  ralphex --auto-stash %s                    # stash, create the branch, restore changes on it\n"+
+			"  ralphex --auto-stash=original %s           # stash for the run, restore changes on %s after it\n"+
 			"  ralphex --review                           # skip branch creation (review-only mode)",
-			branchName, currentBranch, planFile)
+			branchName, currentBranch, planFile, planFile, planFile, currentBranch)
 	}
 
+	return s.switchToPlanBranch(planFile, branchName)
+}
+
+// switchToPlanBranch creates or switches to the plan branch and commits the plan file if it has changes.
+func (s *Service) switchToPlanBranch(planFile, branchName string) error {
 	// check if plan file needs to be committed (untracked, modified, or staged)
 	planHasChanges, err := s.repo.FileHasChanges(planFile)
 	if err != nil {
@@ -173,7 +416,7 @@ func (s *Service) CreateBranchForPlan(planFile string) error {
 		if err := s.repo.Add(planFile); err != nil {
 			return fmt.Errorf("stage plan file: %w", err)
 		}
-		if err := s.repo.Commit("add plan: " + branchName); err != nil {
+		if err := s.repo.Commit(s.AddPlanMessage(planFile, branchName)); err != nil {
 			return fmt.Errorf("commit plan file: %w", err)
 		}
 	}
@@ -181,24 +424,24 @@ func (s *Service) CreateBranchForPlan(planFile string) error {
 	return nil
 }
 
-// MovePlanToCompleted moves a plan file to the completed/ subdirectory and commits.
-// Creates the completed/ directory if it doesn't exist.
+// MovePlanToCompleted moves a plan file to the completed directory of plansDir configured by archive and commits.
+// A plan in a subdirectory keeps it under the completed directory (see plan.Archive.CompletedPath), plans outside
+// plansDir move to the completed directory next to them. Creates the destination directory if it doesn't exist.
 // Uses git mv if the file is tracked, falls back to os.Rename for untracked files.
 // If the source file doesn't exist but the destination does, logs a message and returns nil.
-func (s *Service) MovePlanToCompleted(planFile string) error {
+func (s *Service) MovePlanToCompleted(planFile, plansDir string, archive plan.Archive) error {
+	// destination path
+	destPath := archive.CompletedPath(planFile, plansDir, time.Now())
+
 	// create completed directory
-	completedDir := filepath.Join(filepath.Dir(planFile), "completed")
-	if err := os.MkdirAll(completedDir, 0o750); err != nil {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o750); err != nil {
 		return fmt.Errorf("create completed dir: %w", err)
 	}
 
-	// destination path
-	destPath := filepath.Join(completedDir, filepath.Base(planFile))
-
 	// check if already moved (source missing, dest exists)
 	if _, err := os.Stat(planFile); os.IsNotExist(err) {
 		if _, destErr := os.Stat(destPath); destErr == nil {
-			s.log.Printf("plan already in completed/\n")
+			s.log.Printf("plan already in %s\n", filepath.Dir(destPath))
 			return nil
 		}
 	}
@@ -216,8 +459,7 @@ func (s *Service) MovePlanToCompleted(planFile string) error {
 	}
 
 	// commit the move
-	commitMsg := "move completed plan: " + filepath.Base(planFile)
-	if err := s.repo.Commit(commitMsg); err != nil {
+	if err := s.repo.Commit(s.movePlanMessage(planFile)); err != nil {
 		return fmt.Errorf("commit plan move: %w", err)
 	}
 
@@ -225,6 +467,194 @@ func (s *Service) MovePlanToCompleted(planFile string) error {
 	return nil
 }
 
+// Push pushes a feature branch to the remote and sets it as upstream.
+// main and master are never pushed, changes made by ralphex are meant to be reviewed first.
+func (s *Service) Push(remote, branch string) error {
+	if branch == "" || branch == "main" || branch == "master" {
+		return fmt.Errorf("push %q: not a feature branch", branch)
+	}
+	if err := s.repo.Push(remote, branch); err != nil {
+		return fmt.Errorf("push %s to %s: %w", branch, remote, err)
+	}
+	s.log.Printf("pushed %s to %s\n", branch, remote)
+	return nil
+}
+
+// RemoteURL returns the URL of the named remote, an error if the remote is not configured.
+func (s *Service) RemoteURL(name string) (string, error) {
+	url, err := s.repo.RemoteURL(name)
+	if err != nil {
+		return "", fmt.Errorf("remote %s: %w", name, err)
+	}
+	return url, nil
+}
+
+// CommitSubjects returns subjects of commits reachable from to but not from from, oldest first.
+func (s *Service) CommitSubjects(from, to string) ([]string, error) {
+	subjects, err := s.repo.CommitSubjects(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("list commits %s..%s: %w", from, to, err)
+	}
+	return subjects, nil
+}
+
+// MergeBase returns the hash of the best common ancestor of two refs, e.g. the commit HEAD forked from a branch at.
+// for a ref that is an ancestor of the other, e.g. HEAD~5 and HEAD, it is the commit of the ref itself.
+func (s *Service) MergeBase(a, b string) (string, error) {
+	hash, err := s.repo.MergeBase(a, b)
+	if err != nil {
+		return "", fmt.Errorf("merge-base %s %s: %w", a, b, err)
+	}
+	return hash, nil
+}
+
+// DiffStat returns the diff summary (files with changed line counts and a totals line) between two commits.
+// returns an empty string if nothing changed.
+func (s *Service) DiffStat(from, to string) (string, error) {
+	stat, err := s.repo.DiffStat(from, to)
+	if err != nil {
+		return "", fmt.Errorf("diff stat %s..%s: %w", from, to, err)
+	}
+	return stat, nil
+}
+
+// DiffNames returns paths of files changed between two commits, relative to the repository root.
+func (s *Service) DiffNames(from, to string) ([]string, error) {
+	names, err := s.repo.DiffNames(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("diff names %s..%s: %w", from, to, err)
+	}
+	return names, nil
+}
+
+// ChangedFiles returns paths of files changed on HEAD since it forked from base, relative to the repository root.
+// base is a ref, e.g. "master", or a revision range used as-is, e.g. "v1.2..HEAD".
+// returns nil if nothing changed, e.g. HEAD has no commits ahead of base.
+func (s *Service) ChangedFiles(base string) ([]string, error) {
+	revRange := base
+	if !strings.Contains(base, "..") {
+		revRange = base + "...HEAD"
+	}
+	names, err := s.repo.RangeNames(revRange)
+	if err != nil {
+		return nil, fmt.Errorf("changed files %s: %w", revRange, err)
+	}
+	return names, nil
+}
+
+// ChangedFilesBetween returns the commits and files changed between two commits, e.g. HEAD at the start and
+// the end of a phase. returns an error wrapping ErrHistoryRewritten if from is not an ancestor of to.
+func (s *Service) ChangedFilesBetween(from, to string) (RangeChanges, error) {
+	changes, err := s.repo.ChangesBetween(from, to)
+	if err != nil {
+		return RangeChanges{}, fmt.Errorf("changes %s..%s: %w", from, to, err)
+	}
+	return changes, nil
+}
+
+// UncommittedChanges returns tracked files with staged or unstaged changes and their line counts.
+// Commits is always zero.
+func (s *Service) UncommittedChanges() (RangeChanges, error) {
+	changes, err := s.repo.UncommittedChanges()
+	if err != nil {
+		return RangeChanges{}, fmt.Errorf("uncommitted changes: %w", err)
+	}
+	return changes, nil
+}
+
+// WorktreePath returns the directory used for a linked worktree of the branch:
+// a sibling of the repository root named after the repository and the branch, e.g. ../repo-add-auth.
+func (s *Service) WorktreePath(branch string) string {
+	root := s.repo.Root()
+	name := filepath.Base(root) + "-" + strings.ReplaceAll(branch, "/", "-")
+	return filepath.Join(filepath.Dir(root), name)
+}
+
+// AddWorktree creates a linked worktree at path with the branch checked out, creating the branch
+// from HEAD if it doesn't exist. the current worktree and its checked out branch are not touched.
+// an existing directory at path is reused as is, e.g. a worktree left by an interrupted run.
+func (s *Service) AddWorktree(path, branch string) error {
+	if _, err := os.Stat(path); err == nil {
+		s.log.Printf("using existing worktree: %s\n", path)
+		return nil
+	}
+
+	create := !s.repo.BranchExists(branch)
+	if err := s.repo.AddWorktree(path, branch, create); err != nil {
+		return fmt.Errorf("add worktree %s for %s: %w", path, branch, err)
+	}
+	s.log.Printf("created worktree %s on branch %s\n", path, branch)
+	return nil
+}
+
+// RemoveWorktree removes a linked worktree. the branch is kept.
+func (s *Service) RemoveWorktree(path string) error {
+	if err := s.repo.RemoveWorktree(path); err != nil {
+		return fmt.Errorf("remove worktree %s: %w", path, err)
+	}
+	s.log.Printf("removed worktree %s\n", path)
+	return nil
+}
+
+// CommitPlanFile commits the plan file if it is untracked or has uncommitted changes.
+func (s *Service) CommitPlanFile(planFile, msg string) error {
+	hasChanges, err := s.repo.FileHasChanges(planFile)
+	if err != nil {
+		return fmt.Errorf("check plan file status: %w", err)
+	}
+	if !hasChanges {
+		return nil
+	}
+
+	s.log.Printf("committing plan file: %s\n", filepath.Base(planFile))
+	if err := s.repo.Add(planFile); err != nil {
+		return fmt.Errorf("stage plan file: %w", err)
+	}
+	if err := s.repo.Commit(msg); err != nil {
+		return fmt.Errorf("commit plan file: %w", err)
+	}
+	return nil
+}
+
+// BranchExists reports whether a local branch with the given name exists.
+func (s *Service) BranchExists(name string) bool {
+	return s.repo.BranchExists(name)
+}
+
+// ValidateBranchName checks a branch name against the rules of git check-ref-format --branch:
+// no control characters, spaces or any of ~^:?*[\, no "..", "//" or "@{", no path component starting
+// with a dot or ending with .lock, and no leading dash, leading or trailing slash or trailing dot.
+func ValidateBranchName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("branch name is empty")
+	case name == "@" || name == "HEAD":
+		return fmt.Errorf("%q is not a valid branch name", name)
+	case invalidRefCharsRe.MatchString(name):
+		return fmt.Errorf("branch name %q has a space, control character or one of ~^:?*[\\", name)
+	case strings.HasPrefix(name, "-"):
+		return fmt.Errorf("branch name %q starts with a dash", name)
+	case strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") || strings.HasSuffix(name, "."):
+		return fmt.Errorf("branch name %q starts or ends with a slash or ends with a dot", name)
+	case strings.Contains(name, "..") || strings.Contains(name, "//") || strings.Contains(name, "@{"):
+		return fmt.Errorf("branch name %q contains \"..\", \"//\" or \"@{\"", name)
+	}
+	for part := range strings.SplitSeq(name, "/") {
+		if strings.HasPrefix(part, ".") || strings.HasSuffix(part, ".lock") {
+			return fmt.Errorf("branch name %q has a component starting with a dot or ending with .lock", name)
+		}
+	}
+	return nil
+}
+
+// CheckoutBranch switches to an existing branch.
+func (s *Service) CheckoutBranch(name string) error {
+	if err := s.repo.CheckoutBranch(name); err != nil {
+		return fmt.Errorf("checkout branch %s: %w", name, err)
+	}
+	return nil
+}
+
 // EnsureHasCommits checks that the repository has at least one commit.
 // If the repository is empty, calls promptFn to ask user whether to create initial commit.
 // promptFn should return true to create the commit, false to abort.
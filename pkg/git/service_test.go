@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/plan"
 )
 
 // mockLogger implements Logger interface for testing.
@@ -45,6 +49,22 @@ func TestNewService(t *testing.T) {
 	})
 }
 
+func TestService_Status(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Modified\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new"), 0o600))
+
+	files, err := svc.Status()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []FileStatus{
+		{Path: "README.md", State: FileTracked, Index: ' ', Worktree: 'M'},
+		{Path: "new.txt", State: FileUntracked, Index: '?', Worktree: '?'},
+	}, files)
+}
+
 func TestService_IsMainBranch(t *testing.T) {
 	t.Run("returns true for master branch", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
@@ -274,6 +294,391 @@ func TestService_CreateBranchForPlan(t *testing.T) {
 	})
 }
 
+func TestService_CreateBranchForPlanAs(t *testing.T) {
+	t.Run("uses given branch name", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		plansDir := filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(plansDir, 0o750))
+		planFile := filepath.Join(plansDir, "feature.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+
+		require.NoError(t, svc.CreateBranchForPlanAs(planFile, "feature-2"))
+
+		branch, err := svc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "feature-2", branch)
+	})
+
+	t.Run("ignores pending plan files", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		plansDir := filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(plansDir, 0o750))
+		planA := filepath.Join(plansDir, "plan-a.md")
+		planB := filepath.Join(plansDir, "plan-b.md")
+		require.NoError(t, os.WriteFile(planA, []byte("# Plan A"), 0o600))
+		require.NoError(t, os.WriteFile(planB, []byte("# Plan B"), 0o600))
+
+		// without pending list the other plan blocks branch creation
+		err = svc.CreateBranchForPlanAs(planA, "plan-a")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "worktree has uncommitted changes")
+
+		require.NoError(t, svc.CreateBranchForPlanAs(planA, "plan-a", planB))
+
+		// plan a committed, plan b left untouched
+		hasChanges, err := svc.repo.FileHasChanges(planA)
+		require.NoError(t, err)
+		assert.False(t, hasChanges)
+		hasChanges, err = svc.repo.FileHasChanges(planB)
+		require.NoError(t, err)
+		assert.True(t, hasChanges)
+	})
+}
+
+func TestService_CreateBranchForPlanAs_AutoStash(t *testing.T) {
+	setup := func(t *testing.T) (svc *Service, dir, planFile string) {
+		t.Helper()
+		dir = setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		svc.SetAutoStash(AutoStashFeature)
+
+		plansDir := filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(plansDir, 0o750))
+		planFile = filepath.Join(plansDir, "feature.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+		return svc, dir, planFile
+	}
+
+	t.Run("moves uncommitted changes to the new branch", func(t *testing.T) {
+		svc, dir, planFile := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0o600))
+
+		require.NoError(t, svc.CreateBranchForPlanAs(planFile, "feature"))
+
+		branch, err := svc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "feature", branch)
+
+		// plan committed on the branch, other changes back in the worktree, stash dropped
+		hasChanges, err := svc.repo.FileHasChanges(planFile)
+		require.NoError(t, err)
+		assert.False(t, hasChanges)
+		content, err := os.ReadFile(filepath.Join(dir, "README.md")) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.Equal(t, "# Changed\n", string(content))
+		assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+		assert.Empty(t, runGit(t, dir, "stash", "list"))
+	})
+
+	t.Run("conflict keeps the stash", func(t *testing.T) {
+		svc, dir, planFile := setup(t)
+
+		// existing branch changes README.md differently
+		runGit(t, dir, "checkout", "-b", "feature")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Branch\n"), 0o600))
+		runGit(t, dir, "commit", "-am", "branch change")
+		runGit(t, dir, "checkout", "master")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Local\n"), 0o600))
+
+		err := svc.CreateBranchForPlanAs(planFile, "feature")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "restore stashed changes, they are kept in the stash")
+		assert.Contains(t, runGit(t, dir, "stash", "list"), "ralphex: before feature")
+	})
+
+	t.Run("disabled refuses dirty worktree", func(t *testing.T) {
+		svc, dir, planFile := setup(t)
+		svc.SetAutoStash(AutoStashOff)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0o600))
+
+		err := svc.CreateBranchForPlanAs(planFile, "feature")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ralphex --auto-stash")
+		assert.Contains(t, err.Error(), "ralphex --auto-stash=original")
+	})
+
+	t.Run("original leaves branch creation to the run stash", func(t *testing.T) {
+		svc, dir, planFile := setup(t)
+		svc.SetAutoStash(AutoStashOriginal)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0o600))
+
+		err := svc.CreateBranchForPlanAs(planFile, "feature")
+		require.Error(t, err, "changes not stashed by StashForRun still block the branch")
+		assert.Contains(t, err.Error(), "worktree has uncommitted changes")
+		assert.Empty(t, runGit(t, dir, "stash", "list"))
+	})
+}
+
+func TestService_RunStash(t *testing.T) {
+	setup := func(t *testing.T) (svc *Service, dir, planFile string) {
+		t.Helper()
+		dir = setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		svc.SetAutoStash(AutoStashOriginal)
+
+		planFile = filepath.Join(dir, "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Local\n"), 0o600))
+		return svc, dir, planFile
+	}
+
+	t.Run("restores changes on the starting branch", func(t *testing.T) {
+		svc, dir, planFile := setup(t)
+
+		st, err := svc.StashForRun(planFile)
+		require.NoError(t, err)
+		assert.NotEmpty(t, st.Ref)
+		assert.Equal(t, "master", st.Branch)
+		assert.True(t, strings.HasPrefix(st.Name, "ralphex-autostash-"))
+		assert.Contains(t, runGit(t, dir, "stash", "list"), st.Name)
+
+		// the run creates the feature branch and commits on it
+		require.NoError(t, svc.CreateBranchForPlanAs(planFile, "feature"))
+
+		require.NoError(t, svc.RestoreRunStash(st))
+		branch, err := svc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "master", branch)
+		content, err := os.ReadFile(filepath.Join(dir, "README.md")) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.Equal(t, "# Local\n", string(content))
+		assert.Empty(t, runGit(t, dir, "stash", "list"))
+	})
+
+	t.Run("refuses to pop onto a dirty feature branch", func(t *testing.T) {
+		svc, dir, planFile := setup(t)
+
+		st, err := svc.StashForRun(planFile)
+		require.NoError(t, err)
+		require.NoError(t, svc.CreateBranchForPlanAs(planFile, "feature"))
+		// the run failed midway, leaving uncommitted changes on the feature branch
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Half done\n"), 0o600))
+
+		err = svc.RestoreRunStash(st)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not restoring stashed changes onto feature")
+		assert.Contains(t, err.Error(), "git checkout master && git stash pop")
+		branch, err := svc.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "feature", branch)
+		assert.Contains(t, runGit(t, dir, "stash", "list"), st.Name)
+	})
+
+	t.Run("conflicting pop keeps the stash", func(t *testing.T) {
+		svc, dir, planFile := setup(t)
+
+		st, err := svc.StashForRun(planFile)
+		require.NoError(t, err)
+		// the starting branch gets a conflicting commit while the changes are stashed
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Committed\n"), 0o600))
+		runGit(t, dir, "commit", "-am", "conflicting change")
+
+		err = svc.RestoreRunStash(st)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "resolve the conflicts and drop it with")
+		assert.Contains(t, runGit(t, dir, "stash", "list"), st.Name)
+	})
+
+	t.Run("nothing to stash", func(t *testing.T) {
+		svc, dir, planFile := setup(t)
+		runGit(t, dir, "checkout", "--", "README.md")
+
+		st, err := svc.StashForRun(planFile)
+		require.NoError(t, err)
+		assert.Empty(t, st.Ref)
+		require.NoError(t, svc.RestoreRunStash(st))
+	})
+
+	t.Run("other modes don't stash for the run", func(t *testing.T) {
+		for _, mode := range []AutoStash{AutoStashOff, AutoStashFeature} {
+			svc, dir, planFile := setup(t)
+			svc.SetAutoStash(mode)
+
+			st, err := svc.StashForRun(planFile)
+			require.NoError(t, err)
+			assert.Empty(t, st.Ref, "mode %q", mode)
+			assert.Empty(t, runGit(t, dir, "stash", "list"), "mode %q", mode)
+		}
+	})
+}
+
+func TestService_CheckoutBranch(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, svc.CreateBranch("feature"))
+	require.NoError(t, svc.CheckoutBranch("master"))
+	branch, err := svc.CurrentBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "master", branch)
+
+	err = svc.CheckoutBranch("nonexistent")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checkout branch nonexistent")
+}
+
+func TestService_BranchExists(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+
+	assert.True(t, svc.BranchExists("master"))
+	assert.False(t, svc.BranchExists("feature"))
+	require.NoError(t, svc.CreateBranch("feature"))
+	assert.True(t, svc.BranchExists("feature"))
+}
+
+func TestService_Push(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	remote := t.TempDir()
+	runGit(t, remote, "init", "--bare")
+	runGit(t, dir, "remote", "add", "origin", remote)
+
+	log := &mockLogger{}
+	svc, err := NewService(dir, log)
+	require.NoError(t, err)
+
+	for _, branch := range []string{"main", "master", ""} {
+		err = svc.Push("origin", branch)
+		require.Error(t, err, "branch %q", branch)
+		assert.Contains(t, err.Error(), "not a feature branch")
+	}
+	assert.Empty(t, strings.TrimSpace(runGit(t, remote, "branch", "--list")), "nothing should be pushed")
+
+	require.NoError(t, svc.CreateBranch("feature"))
+	require.NoError(t, svc.Push("origin", "feature"))
+	assert.Contains(t, runGit(t, remote, "branch", "--list"), "feature")
+	assert.Contains(t, log.logs, "pushed feature to origin\n")
+
+	err = svc.Push("upstream", "feature")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "push feature to upstream")
+}
+
+func TestService_RemoteURL(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	runGit(t, dir, "remote", "add", "origin", "git@github.com:owner/repo.git")
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+
+	url, err := svc.RemoteURL("origin")
+	require.NoError(t, err)
+	assert.Equal(t, "git@github.com:owner/repo.git", url)
+
+	_, err = svc.RemoteURL("upstream")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "remote upstream")
+}
+
+func TestService_Worktree(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	log := &mockLogger{}
+	svc, err := NewService(dir, log)
+	require.NoError(t, err)
+
+	root := svc.Root()
+	wt := svc.WorktreePath("feat/add-auth")
+	assert.Equal(t, filepath.Join(filepath.Dir(root), filepath.Base(root)+"-feat-add-auth"), wt)
+
+	require.NoError(t, svc.AddWorktree(wt, "feat/add-auth"))
+	assert.Equal(t, "feat/add-auth", strings.TrimSpace(runGit(t, wt, "rev-parse", "--abbrev-ref", "HEAD")))
+	assert.Equal(t, "master", strings.TrimSpace(runGit(t, dir, "rev-parse", "--abbrev-ref", "HEAD")))
+	assert.Contains(t, log.logs, "created worktree "+wt+" on branch feat/add-auth\n")
+
+	// existing directory is reused, e.g. after an interrupted run
+	require.NoError(t, svc.AddWorktree(wt, "feat/add-auth"))
+	assert.Contains(t, log.logs, "using existing worktree: "+wt+"\n")
+
+	// plan file is committed in the worktree only, once
+	wtSvc, err := NewService(wt, noopServiceLogger())
+	require.NoError(t, err)
+	planFile := filepath.Join(wt, "docs", "plans", "auth.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(planFile), 0o750))
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n"), 0o600))
+	require.NoError(t, wtSvc.CommitPlanFile(planFile, "add plan: feat/add-auth"))
+	assert.Equal(t, "add plan: feat/add-auth", strings.TrimSpace(runGit(t, wt, "log", "-1", "--format=%s")))
+	require.NoError(t, wtSvc.CommitPlanFile(planFile, "again"))
+	assert.Equal(t, "add plan: feat/add-auth", strings.TrimSpace(runGit(t, wt, "log", "-1", "--format=%s")))
+	assert.NoFileExists(t, filepath.Join(dir, "docs", "plans", "auth.md"))
+
+	require.NoError(t, svc.RemoveWorktree(wt))
+	assert.NoDirExists(t, wt)
+	assert.Contains(t, log.logs, "removed worktree "+wt+"\n")
+
+	err = svc.RemoveWorktree(wt)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "remove worktree "+wt)
+}
+
+func TestService_CommitMessages(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	lastSubject := func(t *testing.T, dir string) string {
+		t.Helper()
+		return strings.TrimSpace(runGit(t, dir, "log", "-1", "--format=%s"))
+	}
+	commitPlan := func(t *testing.T, msgs CommitMessages) (dir, planFile, plansDir string, svc *Service) {
+		t.Helper()
+		dir = setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		svc.SetCommitMessages(msgs)
+		plansDir = filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(plansDir, 0o750))
+		planFile = filepath.Join(plansDir, "auth.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n"), 0o600))
+		require.NoError(t, svc.CreateBranchForPlanAs(planFile, "feat-auth"))
+		return dir, planFile, plansDir, svc
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		dir, planFile, plansDir, svc := commitPlan(t, CommitMessages{})
+		assert.Equal(t, "add plan: feat-auth", lastSubject(t, dir))
+		require.NoError(t, svc.MovePlanToCompleted(planFile, plansDir, plan.Archive{}))
+		assert.Equal(t, "move completed plan: auth.md", lastSubject(t, dir))
+	})
+
+	t.Run("custom templates", func(t *testing.T) {
+		dir, planFile, plansDir, svc := commitPlan(t, CommitMessages{
+			AddPlan: "docs: plan {plan} for {branch} ({date})", MovePlan: "docs: archive {plan} on {branch}, {date}"})
+		assert.Equal(t, "docs: plan auth.md for feat-auth ("+today+")", lastSubject(t, dir))
+		require.NoError(t, svc.MovePlanToCompleted(planFile, plansDir, plan.Archive{}))
+		assert.Equal(t, "docs: archive auth.md on feat-auth, "+today, lastSubject(t, dir))
+	})
+
+	t.Run("add plan message for a worktree commit", func(t *testing.T) {
+		svc := &Service{messages: CommitMessages{AddPlan: "plan({branch}): {plan}"}}
+		assert.Equal(t, "plan(feat-auth): auth.md", svc.AddPlanMessage("/repo/docs/plans/auth.md", "feat-auth"))
+		assert.Equal(t, "add plan: feat-auth", (&Service{}).AddPlanMessage("/repo/docs/plans/auth.md", "feat-auth"))
+	})
+}
+
+func TestRenderCommitMessage(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		tmpl, want string
+	}{
+		{tmpl: DefaultAddPlanMessage, want: "add plan: feat-auth"},
+		{tmpl: DefaultMovePlanMessage, want: "move completed plan: auth.md"},
+		{tmpl: "{date} {branch} {plan} {branch}", want: "2026-03-04 feat-auth auth.md feat-auth"},
+		{tmpl: "no placeholders", want: "no placeholders"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.tmpl, func(t *testing.T) {
+			assert.Equal(t, tc.want, renderCommitMessage(tc.tmpl, "feat-auth", "docs/plans/auth.md", now))
+		})
+	}
+}
+
 func TestService_MovePlanToCompleted(t *testing.T) {
 	t.Run("moves tracked file", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
@@ -291,7 +696,7 @@ func TestService_MovePlanToCompleted(t *testing.T) {
 		log := &mockLogger{}
 		svc.log = log
 
-		err = svc.MovePlanToCompleted(planFile)
+		err = svc.MovePlanToCompleted(planFile, plansDir, plan.Archive{})
 		require.NoError(t, err)
 
 		// original file should not exist
@@ -319,7 +724,7 @@ func TestService_MovePlanToCompleted(t *testing.T) {
 		planFile := filepath.Join(plansDir, "untracked-feature.md")
 		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
 
-		err = svc.MovePlanToCompleted(planFile)
+		err = svc.MovePlanToCompleted(planFile, plansDir, plan.Archive{})
 		require.NoError(t, err)
 
 		// original file should not exist
@@ -350,7 +755,7 @@ func TestService_MovePlanToCompleted(t *testing.T) {
 		_, err = os.Stat(completedDir)
 		require.True(t, os.IsNotExist(err))
 
-		err = svc.MovePlanToCompleted(planFile)
+		err = svc.MovePlanToCompleted(planFile, plansDir, plan.Archive{})
 		require.NoError(t, err)
 
 		// completed dir should now exist
@@ -378,15 +783,140 @@ func TestService_MovePlanToCompleted(t *testing.T) {
 		require.True(t, os.IsNotExist(err))
 
 		// should return nil (not error)
-		err = svc.MovePlanToCompleted(planFile)
+		err = svc.MovePlanToCompleted(planFile, plansDir, plan.Archive{})
 		require.NoError(t, err)
 
 		// should have logged skip message
 		require.Len(t, log.logs, 1)
-		assert.Contains(t, log.logs[0], "already in completed")
+		assert.Contains(t, log.logs[0], "plan already in "+completedDir)
+	})
+
+	t.Run("keeps subdirectory under completed", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		plansDir := filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(filepath.Join(plansDir, "backend"), 0o750))
+		planFile := filepath.Join(plansDir, "backend", "api.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+		require.NoError(t, svc.repo.Add(planFile))
+		require.NoError(t, svc.repo.Commit("add plan"))
+
+		require.NoError(t, svc.MovePlanToCompleted(planFile, plansDir, plan.Archive{}))
+
+		_, err = os.Stat(filepath.Join(plansDir, "completed", "backend", "api.md"))
+		require.NoError(t, err)
+		_, err = os.Stat(filepath.Join(plansDir, "backend", "completed"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("plan outside plans dir moves next to it", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+
+		planFile := filepath.Join(dir, "adhoc.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+
+		require.NoError(t, svc.MovePlanToCompleted(planFile, filepath.Join(dir, "docs", "plans"), plan.Archive{}))
+
+		_, err = os.Stat(filepath.Join(dir, "completed", "adhoc.md"))
+		require.NoError(t, err)
+	})
+
+	t.Run("custom completed dir", func(t *testing.T) {
+		for _, tracked := range []bool{true, false} {
+			dir := setupExternalTestRepo(t)
+			svc, err := NewService(dir, noopServiceLogger())
+			require.NoError(t, err)
+
+			plansDir := filepath.Join(dir, "docs", "plans")
+			require.NoError(t, os.MkdirAll(filepath.Join(plansDir, "backend"), 0o750))
+			planFile := filepath.Join(plansDir, "backend", "api.md")
+			require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+			if tracked {
+				require.NoError(t, svc.repo.Add(planFile))
+				require.NoError(t, svc.repo.Commit("add plan"))
+			}
+
+			require.NoError(t, svc.MovePlanToCompleted(planFile, plansDir, plan.Archive{Dir: "archive"}))
+
+			_, err = os.Stat(filepath.Join(plansDir, "archive", "backend", "api.md"))
+			require.NoError(t, err, "tracked=%v", tracked)
+			_, err = os.Stat(filepath.Join(plansDir, "completed"))
+			assert.True(t, os.IsNotExist(err), "tracked=%v", tracked)
+			dirty, err := svc.repo.IsDirty()
+			require.NoError(t, err)
+			assert.False(t, dirty, "move committed, tracked=%v", tracked)
+		}
+	})
+
+	t.Run("dated layout", func(t *testing.T) {
+		for _, tracked := range []bool{true, false} {
+			dir := setupExternalTestRepo(t)
+			svc, err := NewService(dir, noopServiceLogger())
+			require.NoError(t, err)
+
+			plansDir := filepath.Join(dir, "docs", "plans")
+			require.NoError(t, os.MkdirAll(plansDir, 0o750))
+			planFile := filepath.Join(plansDir, "feature.md")
+			require.NoError(t, os.WriteFile(planFile, []byte("# Plan"), 0o600))
+			if tracked {
+				require.NoError(t, svc.repo.Add(planFile))
+				require.NoError(t, svc.repo.Commit("add plan"))
+			}
+
+			archive := plan.Archive{Dir: "archive", DateLayout: "2006/01"}
+			require.NoError(t, svc.MovePlanToCompleted(planFile, plansDir, archive))
+
+			dest := filepath.Join(plansDir, "archive", time.Now().Format("2006"), time.Now().Format("01"), "feature.md")
+			_, err = os.Stat(dest)
+			require.NoError(t, err, "tracked=%v", tracked)
+			assert.Equal(t, dest, archive.FindCompleted(planFile))
+			dirty, err := svc.repo.IsDirty()
+			require.NoError(t, err)
+			assert.False(t, dirty, "move committed, tracked=%v", tracked)
+		}
 	})
 }
 
+func TestValidateBranchName(t *testing.T) {
+	tests := []struct {
+		name    string
+		branch  string
+		wantErr string
+	}{
+		{name: "plain", branch: "fix-tests"},
+		{name: "with prefix and date", branch: "ralphex/umputun/2026-03-05-fix-tests"},
+		{name: "dots inside", branch: "v1.2-release"},
+		{name: "empty", branch: "", wantErr: "empty"},
+		{name: "double dot", branch: "invalid..name", wantErr: `contains ".."`},
+		{name: "space", branch: "my branch", wantErr: "has a space"},
+		{name: "tilde", branch: "feature~1", wantErr: "has a space"},
+		{name: "control character", branch: "a\tb", wantErr: "control character"},
+		{name: "leading dash", branch: "-fix", wantErr: "starts with a dash"},
+		{name: "trailing slash", branch: "ralphex/", wantErr: "ends with a slash"},
+		{name: "double slash", branch: "ralphex//fix", wantErr: `"//"`},
+		{name: "trailing dot", branch: "fix.", wantErr: "ends with a dot"},
+		{name: "reflog syntax", branch: "fix@{1}", wantErr: `"@{"`},
+		{name: "hidden component", branch: "ralphex/.fix", wantErr: "starting with a dot"},
+		{name: "lock suffix", branch: "fix.lock", wantErr: "ending with .lock"},
+		{name: "at sign alone", branch: "@", wantErr: "not a valid branch name"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateBranchName(tc.branch)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
 func TestService_EnsureHasCommits(t *testing.T) {
 	t.Run("returns nil when repo has commits", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
@@ -587,6 +1117,75 @@ func TestService_GetDefaultBranch(t *testing.T) {
 	})
 }
 
+func TestService_DiffStat(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+	start, err := svc.HeadHash()
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "feature.txt"), []byte("line1\nline2\n"), 0o600))
+	require.NoError(t, svc.repo.Add("feature.txt"))
+	require.NoError(t, svc.repo.Commit("add feature"))
+
+	stat, err := svc.DiffStat(start, "HEAD")
+	require.NoError(t, err)
+	assert.Contains(t, stat, "feature.txt | 2 ++")
+	assert.Contains(t, stat, "1 file changed, 2 insertions(+)")
+
+	names, err := svc.DiffNames(start, "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feature.txt"}, names)
+
+	_, err = svc.DiffStat("nonexistent", "HEAD")
+	require.ErrorContains(t, err, "diff stat nonexistent..HEAD")
+	_, err = svc.DiffNames("nonexistent", "HEAD")
+	require.ErrorContains(t, err, "diff names nonexistent..HEAD")
+}
+
+func TestService_ChangedFiles(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+
+	// feature branch with two commits, master moves on with a change of its own
+	runGit(t, dir, "checkout", "-b", "feature")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg", "api.go"), []byte("package pkg\n"), 0o600))
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-m", "add api")
+	first, err := svc.HeadHash()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Test\napi docs\n"), 0o600))
+	runGit(t, dir, "commit", "-am", "document api")
+	runGit(t, dir, "checkout", "master")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "master.txt"), []byte("master only\n"), 0o600))
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-m", "master change")
+	runGit(t, dir, "checkout", "feature")
+
+	tests := []struct {
+		name string
+		base string
+		want []string
+	}{
+		{name: "base branch", base: "master", want: []string{"README.md", "pkg/api.go"}},
+		{name: "range", base: first + "..HEAD", want: []string{"README.md"}},
+		{name: "recent commits", base: "HEAD~1", want: []string{"README.md"}},
+		{name: "no commits ahead", base: "feature", want: nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			names, err := svc.ChangedFiles(tc.base)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, names)
+		})
+	}
+
+	_, err = svc.ChangedFiles("nonexistent")
+	require.ErrorContains(t, err, "changed files nonexistent...HEAD")
+}
+
 func TestService_DiffStats(t *testing.T) {
 	t.Run("returns zero stats when on same branch", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
@@ -632,3 +1231,67 @@ func TestService_DiffStats(t *testing.T) {
 		assert.Equal(t, 0, stats.Deletions)
 	})
 }
+
+func TestService_ChangedFilesBetween(t *testing.T) {
+	setup := func(t *testing.T) (svc *Service, dir, start string) {
+		t.Helper()
+		dir = setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		start, err = svc.HeadHash()
+		require.NoError(t, err)
+		return svc, dir, start
+	}
+	commitFile := func(t *testing.T, dir, name, content, msg string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+		runGit(t, dir, "add", name)
+		runGit(t, dir, "commit", "-m", msg)
+	}
+
+	t.Run("commits and files", func(t *testing.T) {
+		svc, dir, start := setup(t)
+		commitFile(t, dir, "a.go", "line1\nline2\n", "add a")
+		commitFile(t, dir, "README.md", "# Changed\n", "edit readme")
+		commitFile(t, dir, "a.go", "line1\n", "trim a")
+		end, err := svc.HeadHash()
+		require.NoError(t, err)
+
+		changes, err := svc.ChangedFilesBetween(start, end)
+		require.NoError(t, err)
+		assert.Equal(t, 3, changes.Commits)
+		assert.ElementsMatch(t, []string{"README.md", "a.go"}, changes.Files)
+		assert.Equal(t, DiffStats{Files: 2, Additions: 2, Deletions: 1}, changes.Stats)
+	})
+
+	t.Run("same commit", func(t *testing.T) {
+		svc, _, start := setup(t)
+		changes, err := svc.ChangedFilesBetween(start, start)
+		require.NoError(t, err)
+		assert.Equal(t, RangeChanges{}, changes)
+	})
+
+	t.Run("amended commit", func(t *testing.T) {
+		svc, dir, _ := setup(t)
+		commitFile(t, dir, "a.go", "v1\n", "add a")
+		start, err := svc.HeadHash()
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("v2\n"), 0o600))
+		runGit(t, dir, "commit", "-a", "--amend", "-m", "add a, amended")
+		end, err := svc.HeadHash()
+		require.NoError(t, err)
+
+		_, err = svc.ChangedFilesBetween(start, end)
+		require.ErrorIs(t, err, ErrHistoryRewritten)
+	})
+
+	t.Run("uncommitted changes", func(t *testing.T) {
+		svc, dir, _ := setup(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Test\nmore\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "new.go"), []byte("untracked\n"), 0o600))
+
+		changes, err := svc.UncommittedChanges()
+		require.NoError(t, err)
+		assert.Equal(t, RangeChanges{Files: []string{"README.md"}, Stats: DiffStats{Files: 1, Additions: 1}}, changes)
+	})
+}
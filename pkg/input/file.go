@@ -0,0 +1,183 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// draft review actions.
+const (
+	DraftAccept = "accept"
+	DraftRevise = "revise"
+	DraftReject = "reject"
+)
+
+// FileCollector implements Collector with answers read from a file, for plan creation without a terminal.
+type FileCollector struct {
+	answers map[string]string // question text or 1-based question number -> answer
+	draft   DraftAnswer       // response to draft reviews, accept if empty
+	asked   int               // number of questions asked so far
+	drafts  int               // number of drafts reviewed so far
+}
+
+// DraftAnswer is the response of an answers file to plan draft reviews.
+type DraftAnswer struct {
+	Action   string `yaml:"action"`             // accept, revise or reject
+	Feedback string `yaml:"feedback,omitempty"` // revision request, required for revise
+}
+
+// answersFile is the layout of an answers file with a draft review response.
+// a file with neither key is a plain mapping of questions to answers.
+type answersFile struct {
+	Questions map[string]string `yaml:"questions,omitempty"`
+	Draft     *DraftAnswer      `yaml:"draft,omitempty"`
+}
+
+// NewFileCollector loads answers from a YAML or JSON file mapping question text, or the 1-based
+// order in which questions are asked, to an answer: an option text, an option number or a free-text answer.
+// the mapping is either the whole file or its questions key, next to a draft key with the draft review response.
+func NewFileCollector(path string) (*FileCollector, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path from the command line
+	if err != nil {
+		return nil, fmt.Errorf("read answers file: %w", err)
+	}
+
+	var file answersFile
+	if err := yaml.Unmarshal(data, &file); err != nil || file.Questions == nil && file.Draft == nil {
+		file = answersFile{Questions: map[string]string{}}
+		if err := yaml.Unmarshal(data, &file.Questions); err != nil {
+			return nil, fmt.Errorf("parse answers file %s: %w", path, err)
+		}
+	}
+	if file.Questions == nil {
+		file.Questions = map[string]string{}
+	}
+
+	c := &FileCollector{answers: file.Questions}
+	if file.Draft != nil {
+		c.draft = *file.Draft
+	}
+	switch c.draft.Action {
+	case "", DraftAccept, DraftReject:
+	case DraftRevise:
+		if strings.TrimSpace(c.draft.Feedback) == "" {
+			return nil, fmt.Errorf("parse answers file %s: draft action revise needs feedback", path)
+		}
+	default:
+		return nil, fmt.Errorf("parse answers file %s: unknown draft action %q, expected accept, revise or reject", path, c.draft.Action)
+	}
+	return c, nil
+}
+
+// AskQuestion returns the answer for the question from the file.
+// the question is looked up by its text (ignoring case and extra whitespace), then by a part of its text, then by its number.
+// unanswered questions fall back to the first option with a warning.
+func (c *FileCollector) AskQuestion(ctx context.Context, question string, options []string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("ask question: %w", err)
+	}
+	c.asked++
+
+	answer, ok := c.lookup(question)
+	if !ok {
+		if len(options) == 0 {
+			return "", fmt.Errorf("no answer for question %q in answers file", question)
+		}
+		log.Printf("[WARN] no answer for question %q in answers file, using the first option %q", question, options[0])
+		return options[0], nil
+	}
+	return resolveAnswer(answer, options), nil
+}
+
+// AskText returns the answer for the question from the file, unanswered questions take the default with a warning.
+func (c *FileCollector) AskText(ctx context.Context, question, defaultAnswer string, _ bool) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("ask question: %w", err)
+	}
+	c.asked++
+
+	if answer, ok := c.lookup(question); ok && strings.TrimSpace(answer) != "" {
+		return strings.TrimSpace(answer), nil
+	}
+	if defaultAnswer == "" {
+		return "", fmt.Errorf("no answer for question %q in answers file", question)
+	}
+	log.Printf("[WARN] no answer for question %q in answers file, using the default %q", question, defaultAnswer)
+	return defaultAnswer, nil
+}
+
+// AskDraftReview responds to a plan draft with the draft action of the file, accept if none.
+// revise applies to the first draft only, the revised draft is accepted so plan creation doesn't loop.
+func (c *FileCollector) AskDraftReview(ctx context.Context, _, _ string) (action, feedback string, err error) {
+	if err := ctx.Err(); err != nil {
+		return "", "", fmt.Errorf("draft review: %w", err)
+	}
+	c.drafts++
+	switch {
+	case c.draft.Action == DraftReject:
+		return DraftReject, "", nil
+	case c.draft.Action == DraftRevise && c.drafts == 1:
+		return DraftRevise, c.draft.Feedback, nil
+	case c.draft.Action == DraftRevise:
+		log.Printf("[INFO] accepting the revised plan draft, answers file revises the first draft only")
+	}
+	return DraftAccept, "", nil
+}
+
+// lookup finds the answer for a question by exact text, normalized text, a part of the text or question number.
+// a question matching several parts gets the answer of the longest one.
+func (c *FileCollector) lookup(question string) (string, bool) {
+	if answer, ok := c.answers[question]; ok {
+		return answer, true
+	}
+	norm := normalizeQuestion(question)
+	for q, answer := range c.answers {
+		if normalizeQuestion(q) == norm {
+			return answer, true
+		}
+	}
+	var best, bestNorm string
+	for q := range c.answers {
+		if _, err := strconv.Atoi(q); err == nil {
+			continue // question numbers are matched by order only
+		}
+		part := normalizeQuestion(q)
+		if part == "" || !strings.Contains(norm, part) {
+			continue
+		}
+		if len(part) > len(bestNorm) || len(part) == len(bestNorm) && q < best {
+			best, bestNorm = q, part
+		}
+	}
+	if best != "" {
+		return c.answers[best], true
+	}
+	answer, ok := c.answers[strconv.Itoa(c.asked)]
+	return answer, ok
+}
+
+// resolveAnswer maps an answer to one of the options by text (ignoring case) or 1-based number.
+// anything else is returned as a free-text answer, like choosing "Other" in the terminal.
+func resolveAnswer(answer string, options []string) string {
+	answer = strings.TrimSpace(answer)
+	for _, opt := range options {
+		if strings.EqualFold(opt, answer) {
+			return opt
+		}
+	}
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+		return options[n-1]
+	}
+	return answer
+}
+
+// normalizeQuestion lowercases a question and collapses whitespace for lookup.
+func normalizeQuestion(q string) string {
+	return strings.ToLower(strings.Join(strings.Fields(q), " "))
+}
@@ -0,0 +1,151 @@
+package input
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFileCollector(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		want      map[string]string
+		wantDraft DraftAnswer
+		wantErr   string
+	}{
+		{name: "yaml", content: "Which database?: PostgreSQL\n2: 1\n",
+			want: map[string]string{"Which database?": "PostgreSQL", "2": "1"}},
+		{name: "json", content: `{"Which database?": "PostgreSQL", "2": "REST"}`,
+			want: map[string]string{"Which database?": "PostgreSQL", "2": "REST"}},
+		{name: "empty file", content: "", want: map[string]string{}},
+		{name: "not a mapping", content: "- a\n- b\n", wantErr: "parse answers file"},
+		{name: "questions and draft", content: "questions:\n  database: PostgreSQL\ndraft:\n  action: revise\n  feedback: add tests\n",
+			want: map[string]string{"database": "PostgreSQL"}, wantDraft: DraftAnswer{Action: DraftRevise, Feedback: "add tests"}},
+		{name: "draft only, json", content: `{"draft": {"action": "reject"}}`,
+			want: map[string]string{}, wantDraft: DraftAnswer{Action: DraftReject}},
+		{name: "revise without feedback", content: "draft:\n  action: revise\n", wantErr: "draft action revise needs feedback"},
+		{name: "unknown draft action", content: "draft:\n  action: edit\n", wantErr: `unknown draft action "edit"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "answers.yml")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			c, err := NewFileCollector(path)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, c.answers)
+			assert.Equal(t, tc.wantDraft, c.draft)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := NewFileCollector(filepath.Join(t.TempDir(), "missing.yml"))
+		require.ErrorContains(t, err, "read answers file")
+	})
+}
+
+func TestFileCollector_AskQuestion(t *testing.T) {
+	c := &FileCollector{answers: map[string]string{
+		"Which database should we use?": "postgresql",
+		"2":                             "3",
+		"Describe the API":              "REST with JSON bodies",
+		"cache":                         "Redis",
+		"cache TTL":                     "5 minutes",
+	}}
+	options := []string{"SQLite", "PostgreSQL", "MySQL"}
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		question string
+		options  []string
+		want     string
+		wantErr  string
+	}{
+		{name: "by text, option matched ignoring case", question: "Which database should we use?", options: options, want: "PostgreSQL"},
+		{name: "by question number, option number", question: "Which logger?", options: options, want: "MySQL"},
+		{name: "normalized text, free-text answer", question: "  describe the   API ", options: options, want: "REST with JSON bodies"},
+		{name: "by part of the text", question: "Which Cache backend?", options: options, want: "Redis"},
+		{name: "longest part wins", question: "What cache TTL should we use?", options: options, want: "5 minutes"},
+		{name: "unanswered falls back to first option", question: "Which queue?", options: options, want: "SQLite"},
+		{name: "unanswered without options", question: "Anything else?", wantErr: "no answer for question"},
+	}
+
+	// questions are asked in order, the second one is answered by its number
+	for _, tc := range tests {
+		got, err := c.AskQuestion(ctx, tc.question, tc.options)
+		if tc.wantErr != "" {
+			require.ErrorContains(t, err, tc.wantErr, tc.name)
+			continue
+		}
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.want, got, tc.name)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := c.AskQuestion(canceled, "Which database should we use?", options)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFileCollector_AskText(t *testing.T) {
+	c := &FileCollector{answers: map[string]string{"What should the endpoint path be?": " /api/v2/users \n"}}
+	ctx := context.Background()
+
+	got, err := c.AskText(ctx, "What should the endpoint path be?", "/api/users", false)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v2/users", got)
+
+	got, err = c.AskText(ctx, "Which port?", "8080", false)
+	require.NoError(t, err)
+	assert.Equal(t, "8080", got, "unanswered takes the default")
+
+	_, err = c.AskText(ctx, "Describe the schema", "", true)
+	require.ErrorContains(t, err, "no answer for question")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = c.AskText(canceled, "What should the endpoint path be?", "", false)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFileCollector_AskDraftReview(t *testing.T) {
+	tests := []struct {
+		name  string
+		draft DraftAnswer
+		want  [][2]string // action and feedback for the first and second draft
+	}{
+		{name: "accepted by default", want: [][2]string{{DraftAccept, ""}, {DraftAccept, ""}}},
+		{name: "accept", draft: DraftAnswer{Action: DraftAccept}, want: [][2]string{{DraftAccept, ""}, {DraftAccept, ""}}},
+		{name: "revise the first draft only", draft: DraftAnswer{Action: DraftRevise, Feedback: "add tests"},
+			want: [][2]string{{DraftRevise, "add tests"}, {DraftAccept, ""}}},
+		{name: "reject", draft: DraftAnswer{Action: DraftReject}, want: [][2]string{{DraftReject, ""}, {DraftReject, ""}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &FileCollector{draft: tc.draft}
+			for _, want := range tc.want {
+				action, feedback, err := c.AskDraftReview(context.Background(), "Review the plan draft", "# Plan")
+				require.NoError(t, err)
+				assert.Equal(t, want[0], action)
+				assert.Equal(t, want[1], feedback)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := (&FileCollector{}).AskDraftReview(ctx, "Review the plan draft", "# Plan")
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -54,8 +54,13 @@ type Collector interface {
 	// Returns the selected or typed text, or error if selection fails.
 	AskQuestion(ctx context.Context, question string, options []string) (string, error)
 
-	// AskDraftReview presents a plan draft for review with Accept/Revise/Reject options.
-	// Returns the selected action ("accept", "revise", or "reject") and feedback text (empty for accept/reject).
+	// AskText asks a question with a free-form answer, a single line or several lines if multiline is set.
+	// An empty answer takes defaultAnswer; empty with no default is an error.
+	AskText(ctx context.Context, question, defaultAnswer string, multiline bool) (string, error)
+
+	// AskDraftReview presents a plan draft for review with Accept/Revise/Reject/Edit options.
+	// Returns the selected action ("accept", "revise", "reject", or "edit") and feedback text:
+	// revision feedback for revise, the hand-edited plan for edit, empty for accept/reject.
 	AskDraftReview(ctx context.Context, question string, planContent string) (action string, feedback string, err error)
 }
 
@@ -65,6 +70,7 @@ type TerminalCollector struct {
 	stdout  io.Writer // for testing, nil uses os.Stdout
 	noColor bool      // if true, skip glamour rendering
 	noFzf   bool      // if true, skip fzf even if available (for testing)
+	editor  string    // for testing, empty uses $EDITOR or vi
 }
 
 // NewTerminalCollector creates a new TerminalCollector with specified options.
@@ -225,6 +231,61 @@ func (c *TerminalCollector) readCustomAnswer(ctx context.Context, reader ...*buf
 	return answer, nil
 }
 
+// multilineEnd is the line ending a multiline answer, like in mail(1)
+const multilineEnd = "."
+
+// AskText prints the question and reads a free-form answer from stdin.
+// a multiline answer ends with a line containing only "." or EOF (Ctrl+D).
+func (c *TerminalCollector) AskText(ctx context.Context, question, defaultAnswer string, multiline bool) (string, error) {
+	stdout := c.getStdout()
+	reader := bufio.NewReader(c.getStdin())
+
+	_, _ = fmt.Fprintln(stdout)
+	_, _ = fmt.Fprintln(stdout, question)
+	hint := ""
+	if defaultAnswer != "" {
+		hint = fmt.Sprintf(" [%s]", defaultAnswer)
+	}
+
+	var answer string
+	if multiline {
+		_, _ = fmt.Fprintf(stdout, "Enter your answer%s, end with a line containing only %q or Ctrl+D:\n", hint, multilineEnd)
+		var lines []string
+		for {
+			line, err := ReadLineWithContext(ctx, reader)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return "", fmt.Errorf("read answer: %w", err)
+			}
+			line = strings.TrimRight(line, "\r\n")
+			if line == multilineEnd {
+				break
+			}
+			if line != "" || err == nil {
+				lines = append(lines, line)
+			}
+			if err != nil { // EOF ends the answer
+				break
+			}
+		}
+		answer = strings.TrimSpace(strings.Join(lines, "\n"))
+	} else {
+		_, _ = fmt.Fprintf(stdout, "Enter your answer%s: ", hint)
+		line, err := ReadLineWithContext(ctx, reader)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("read answer: %w", err)
+		}
+		answer = strings.TrimSpace(line)
+	}
+
+	if answer == "" {
+		answer = defaultAnswer
+	}
+	if answer == "" {
+		return "", errors.New("answer cannot be empty")
+	}
+	return answer, nil
+}
+
 // AskYesNo prompts with [y/N] and returns true for yes.
 // defaults to no on EOF, empty input, context cancellation, or any read error.
 func AskYesNo(ctx context.Context, prompt string, stdin io.Reader, stdout io.Writer) bool {
@@ -247,12 +308,18 @@ const (
 	ActionAccept = "accept"
 	ActionRevise = "revise"
 	ActionReject = "reject"
+	ActionEdit   = "edit"
 )
 
-// AskDraftReview presents a plan draft for review with Accept/Revise/Reject options.
+// defaultEditor is used when $EDITOR is not set
+const defaultEditor = "vi"
+
+// AskDraftReview presents a plan draft for review with Accept/Revise/Reject/Edit options.
 // Shows the rendered plan content, then prompts for action selection.
 // If Revise is selected, prompts for feedback text.
-// Returns action ("accept", "revise", "reject") and feedback (empty for accept/reject).
+// If Edit is selected, opens the draft in $EDITOR (vi if unset) and returns the edited plan as feedback.
+// a failed editor, an empty or unchanged file cancels the edit and the action is asked again.
+// Returns action ("accept", "revise", "reject", "edit") and feedback (empty for accept/reject).
 func (c *TerminalCollector) AskDraftReview(ctx context.Context, question, planContent string) (string, string, error) {
 	stdout := c.getStdout()
 	stdin := c.getStdin()
@@ -269,8 +336,8 @@ func (c *TerminalCollector) AskDraftReview(ctx context.Context, question, planCo
 	_, _ = fmt.Fprintln(stdout, "━━━━━━━━━━━━━━━━━━")
 	_, _ = fmt.Fprintln(stdout)
 
-	// present action options
-	options := []string{"Accept", "Revise", "Reject"}
+	// present action options, edit is last to keep the numbers of the other actions stable
+	options := []string{"Accept", "Revise", "Reject", "Edit"}
 	action, err := c.selectWithNumbers(ctx, question, options)
 	if err != nil {
 		return "", "", fmt.Errorf("select action: %w", err)
@@ -278,6 +345,22 @@ func (c *TerminalCollector) AskDraftReview(ctx context.Context, question, planCo
 
 	actionLower := strings.ToLower(action)
 
+	// if edit, open the draft in the editor, re-prompt if the edit was canceled
+	for actionLower == ActionEdit {
+		edited, editErr := c.editDraft(ctx, planContent)
+		if editErr == nil {
+			return ActionEdit, edited, nil
+		}
+		if ctx.Err() != nil {
+			return "", "", fmt.Errorf("edit draft: %w", ctx.Err())
+		}
+		_, _ = fmt.Fprintf(stdout, "\nedit canceled: %v\n", editErr)
+		if action, err = c.selectWithNumbers(ctx, question, options); err != nil {
+			return "", "", fmt.Errorf("select action: %w", err)
+		}
+		actionLower = strings.ToLower(action)
+	}
+
 	// if revise, prompt for feedback
 	if actionLower == ActionRevise {
 		_, _ = fmt.Fprintln(stdout)
@@ -298,6 +381,56 @@ func (c *TerminalCollector) AskDraftReview(ctx context.Context, question, planCo
 	return actionLower, "", nil
 }
 
+// editDraft writes the draft to a temp file, opens it in the editor and returns the edited content.
+// returns an error if the editor exits non-zero or the file comes back empty or unchanged.
+func (c *TerminalCollector) editDraft(ctx context.Context, planContent string) (string, error) {
+	f, err := os.CreateTemp("", "ralphex-plan-*.md")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+	path := f.Name()
+	defer os.Remove(path)
+
+	_, writeErr := f.WriteString(planContent)
+	if closeErr := f.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr != nil {
+		return "", fmt.Errorf("write temp file: %w", writeErr)
+	}
+
+	editor := c.editor
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if strings.TrimSpace(editor) == "" {
+		editor = defaultEditor
+	}
+
+	// $EDITOR may include arguments, e.g. "code --wait"
+	args := strings.Fields(editor)
+	cmd := exec.CommandContext(ctx, args[0], append(args[1:], path)...) //nolint:gosec // editor is user-configured
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s failed: %w", args[0], err)
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path is our temp file
+	if err != nil {
+		return "", fmt.Errorf("read edited plan: %w", err)
+	}
+	edited := strings.TrimSpace(strings.ReplaceAll(string(data), "\r\n", "\n"))
+	switch edited {
+	case "":
+		return "", errors.New("edited plan is empty")
+	case strings.TrimSpace(planContent):
+		return "", errors.New("no changes made")
+	}
+	return edited, nil
+}
+
 // renderMarkdown renders markdown content for terminal display.
 // if noColor is true, returns the content unchanged.
 func (c *TerminalCollector) renderMarkdown(content string) (string, error) {
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -170,6 +172,52 @@ func TestTerminalCollector_readCustomAnswer(t *testing.T) {
 	})
 }
 
+func TestTerminalCollector_AskText(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		def        string
+		multiline  bool
+		want       string
+		wantErr    string
+		wantPrompt string
+	}{
+		{name: "single line", input: "  /api/v2/users \n", want: "/api/v2/users", wantPrompt: "Enter your answer: "},
+		{name: "empty takes the default", input: "\n", def: "/api/users", want: "/api/users",
+			wantPrompt: "Enter your answer [/api/users]: "},
+		{name: "EOF takes the default", input: "", def: "/api/users", want: "/api/users"},
+		{name: "empty without default", input: "\n", wantErr: "answer cannot be empty"},
+		{name: "multiline ends with a dot", input: "first line\n\nthird line\n.\nnot read\n", multiline: true,
+			want: "first line\n\nthird line", wantPrompt: `end with a line containing only "." or Ctrl+D`},
+		{name: "multiline ends with EOF", input: "first line\nlast line", multiline: true, want: "first line\nlast line"},
+		{name: "multiline empty takes the default", input: ".\n", def: "none", multiline: true, want: "none"},
+		{name: "multiline empty without default", input: "", multiline: true, wantErr: "answer cannot be empty"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			c := &TerminalCollector{stdin: strings.NewReader(tc.input), stdout: &stdout}
+			got, err := c.AskText(context.Background(), "What should the endpoint path be?", tc.def, tc.multiline)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+			assert.Contains(t, stdout.String(), "What should the endpoint path be?")
+			assert.Contains(t, stdout.String(), tc.wantPrompt)
+		})
+	}
+
+	t.Run("context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		c := &TerminalCollector{stdin: strings.NewReader("answer\n"), stdout: &bytes.Buffer{}}
+		_, err := c.AskText(ctx, "What should the endpoint path be?", "", false)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
 func TestTerminalCollector_AskQuestion_appendsOther(t *testing.T) {
 	t.Run("select regular option", func(t *testing.T) {
 		var stdout bytes.Buffer
@@ -460,6 +508,68 @@ func TestTerminalCollector_AskDraftReview(t *testing.T) {
 	})
 }
 
+// writeEditor creates an executable editor script with the given shell body, the edited file is "$1".
+func writeEditor(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "editor.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o700)) //nolint:gosec // test script must be executable
+	return path
+}
+
+func TestTerminalCollector_AskDraftReview_Edit(t *testing.T) {
+	planContent := "# Test Plan\n\n## Overview\n\nThis is a test plan."
+
+	tests := []struct {
+		name         string
+		editor       string // script body
+		args         string // extra editor arguments
+		lines        []string
+		wantAction   string
+		wantFeedback string
+		wantOutput   string
+	}{
+		{name: "edited plan is returned", editor: `printf '# Edited Plan\r\n\n- [ ] task\n\n' > "$1"`, lines: []string{"4"},
+			wantAction: ActionEdit, wantFeedback: "# Edited Plan\n\n- [ ] task"},
+		{name: "editor gets the draft", editor: `grep -q "This is a test plan" "$1" && echo "# Checked" > "$1"`, lines: []string{"4"},
+			wantAction: ActionEdit, wantFeedback: "# Checked"},
+		{name: "editor with arguments", editor: `[ "$1" = "--wait" ] && echo "# Waited" > "$2"`, args: " --wait", lines: []string{"4"},
+			wantAction: ActionEdit, wantFeedback: "# Waited"},
+		{name: "failed editor re-prompts", editor: "exit 1", lines: []string{"4", "1"},
+			wantAction: ActionAccept, wantOutput: "edit canceled: editor"},
+		{name: "empty file re-prompts", editor: `: > "$1"`, lines: []string{"4", "3"},
+			wantAction: ActionReject, wantOutput: "edit canceled: edited plan is empty"},
+		{name: "unchanged file re-prompts", editor: "true", lines: []string{"4", "2", "more tests"},
+			wantAction: ActionRevise, wantFeedback: "more tests", wantOutput: "edit canceled: no changes made"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			c := &TerminalCollector{stdin: &sequentialLineReader{lines: tc.lines}, stdout: &stdout, noColor: true,
+				editor: writeEditor(t, tc.editor) + tc.args}
+
+			action, feedback, err := c.AskDraftReview(context.Background(), "Review the plan", planContent)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantAction, action)
+			assert.Equal(t, tc.wantFeedback, feedback)
+			assert.Contains(t, stdout.String(), "4) Edit")
+			assert.Contains(t, stdout.String(), tc.wantOutput)
+		})
+	}
+
+	t.Run("canceled edit then EOF returns error", func(t *testing.T) {
+		var stdout bytes.Buffer
+		c := &TerminalCollector{stdin: &sequentialLineReader{lines: []string{"4"}}, stdout: &stdout, noColor: true,
+			editor: writeEditor(t, "exit 1")}
+
+		_, _, err := c.AskDraftReview(context.Background(), "Review the plan", planContent)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "select action")
+	})
+}
+
 // eofAfterReader returns data on first read, then EOF on subsequent reads
 type eofAfterReader struct {
 	data     string
@@ -0,0 +1,105 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RecordingCollector wraps a Collector and saves the answers given to it in the answers file format,
+// so an interactive plan creation can be replayed with FileCollector.
+// the file is rewritten after each answer, an interrupted session keeps the answers given so far.
+// the response to the first draft review is saved as the draft action, edited drafts are not recorded.
+type RecordingCollector struct {
+	inner Collector
+	path  string
+
+	mu     sync.Mutex
+	file   answersFile
+	drafts int // number of drafts reviewed so far
+}
+
+// NewRecordingCollector creates a collector recording the answers of inner to path.
+// the file is created right away, so a path that can't be written fails before any question is asked.
+func NewRecordingCollector(inner Collector, path string) (*RecordingCollector, error) {
+	c := &RecordingCollector{inner: inner, path: path, file: answersFile{Questions: map[string]string{}}}
+	if err := c.save(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// AskQuestion asks the inner collector and records the answer for the question text.
+func (c *RecordingCollector) AskQuestion(ctx context.Context, question string, options []string) (string, error) {
+	answer, err := c.inner.AskQuestion(ctx, question, options)
+	if err != nil {
+		return "", err //nolint:wrapcheck // pass through collector errors as-is
+	}
+
+	c.record(question, answer)
+	return answer, nil
+}
+
+// record saves the answer for the question text.
+func (c *RecordingCollector) record(question, answer string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.file.Questions[question] = answer
+	if err := c.save(); err != nil {
+		log.Printf("[WARN] %v", err)
+	}
+}
+
+// AskText asks the inner collector and records the answer for the question text.
+func (c *RecordingCollector) AskText(ctx context.Context, question, defaultAnswer string, multiline bool) (string, error) {
+	answer, err := c.inner.AskText(ctx, question, defaultAnswer, multiline)
+	if err != nil {
+		return "", err //nolint:wrapcheck // pass through collector errors as-is
+	}
+	c.record(question, answer)
+	return answer, nil
+}
+
+// AskDraftReview asks the inner collector and records the response to the first draft.
+func (c *RecordingCollector) AskDraftReview(ctx context.Context, question, planContent string) (action, feedback string, err error) {
+	action, feedback, err = c.inner.AskDraftReview(ctx, question, planContent)
+	if err != nil {
+		return "", "", err //nolint:wrapcheck // pass through collector errors as-is
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drafts++
+	if c.drafts > 1 {
+		return action, feedback, nil
+	}
+	switch action {
+	case DraftAccept, DraftReject:
+		c.file.Draft = &DraftAnswer{Action: action}
+	case DraftRevise:
+		c.file.Draft = &DraftAnswer{Action: action, Feedback: feedback}
+	default:
+		log.Printf("[WARN] draft review %q is not recorded, the replay accepts the draft", action)
+		return action, feedback, nil
+	}
+	if err := c.save(); err != nil {
+		log.Printf("[WARN] %v", err)
+	}
+	return action, feedback, nil
+}
+
+// save writes the recorded answers to the file.
+func (c *RecordingCollector) save() error {
+	data, err := yaml.Marshal(c.file)
+	if err != nil {
+		return fmt.Errorf("encode recorded answers: %w", err)
+	}
+	if err := os.WriteFile(c.path, data, 0o600); err != nil {
+		return fmt.Errorf("write recorded answers: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package input
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingCollector(t *testing.T) {
+	inner := &FileCollector{
+		answers: map[string]string{"Which database?": "2", "Describe the API": "REST with JSON bodies"},
+		draft:   DraftAnswer{Action: DraftRevise, Feedback: "add tests"},
+	}
+	path := filepath.Join(t.TempDir(), "recorded.yml")
+	c, err := NewRecordingCollector(inner, path)
+	require.NoError(t, err)
+	_, err = os.Stat(path)
+	require.NoError(t, err, "file created before any question")
+
+	ctx := context.Background()
+	answer, err := c.AskQuestion(ctx, "Which database?", []string{"SQLite", "PostgreSQL"})
+	require.NoError(t, err)
+	assert.Equal(t, "PostgreSQL", answer)
+	answer, err = c.AskQuestion(ctx, "Describe the API", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "REST with JSON bodies", answer)
+	answer, err = c.AskText(ctx, "Which port?", "8080", false)
+	require.NoError(t, err)
+	assert.Equal(t, "8080", answer)
+
+	action, feedback, err := c.AskDraftReview(ctx, "Review the plan draft", "# Plan")
+	require.NoError(t, err)
+	assert.Equal(t, DraftRevise, action)
+	assert.Equal(t, "add tests", feedback)
+	action, _, err = c.AskDraftReview(ctx, "Review the plan draft", "# Plan v2")
+	require.NoError(t, err)
+	assert.Equal(t, DraftAccept, action, "second draft passed through, not recorded")
+
+	// the recording replays the same session
+	replay, err := NewFileCollector(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Which database?": "PostgreSQL", "Describe the API": "REST with JSON bodies",
+		"Which port?": "8080"}, replay.answers)
+	assert.Equal(t, DraftAnswer{Action: DraftRevise, Feedback: "add tests"}, replay.draft)
+	answer, err = replay.AskQuestion(ctx, "Which database?", []string{"SQLite", "PostgreSQL"})
+	require.NoError(t, err)
+	assert.Equal(t, "PostgreSQL", answer)
+}
+
+func TestRecordingCollector_NothingRecorded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recorded.yml")
+	_, err := NewRecordingCollector(&FileCollector{}, path)
+	require.NoError(t, err)
+
+	replay, err := NewFileCollector(path)
+	require.NoError(t, err)
+	assert.Empty(t, replay.answers)
+	assert.Equal(t, DraftAnswer{}, replay.draft)
+}
+
+func TestRecordingCollector_Errors(t *testing.T) {
+	t.Run("unwritable path", func(t *testing.T) {
+		_, err := NewRecordingCollector(&FileCollector{}, filepath.Join(t.TempDir(), "missing", "recorded.yml"))
+		require.ErrorContains(t, err, "write recorded answers")
+	})
+
+	t.Run("inner error is not recorded", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "recorded.yml")
+		c, err := NewRecordingCollector(&FileCollector{}, path)
+		require.NoError(t, err)
+
+		_, err = c.AskQuestion(context.Background(), "Anything else?", nil)
+		require.ErrorContains(t, err, "no answer for question")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, _, err = c.AskDraftReview(ctx, "Review the plan draft", "# Plan")
+		require.ErrorIs(t, err, context.Canceled)
+
+		replay, err := NewFileCollector(path)
+		require.NoError(t, err)
+		assert.Empty(t, replay.answers)
+		assert.Equal(t, DraftAnswer{}, replay.draft)
+	})
+}
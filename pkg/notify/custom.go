@@ -5,7 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
+	"time"
 )
 
 // customChannel runs a user script for notifications.
@@ -18,7 +20,12 @@ func newCustomChannel(scriptPath string) *customChannel {
 	return &customChannel{scriptPath: scriptPath}
 }
 
+// waitDelay bounds how long send waits for script output after the context is done,
+// so a script leaving background children holding its stdout can't block shutdown.
+const waitDelay = time.Second
+
 // send marshals Result to JSON and pipes it to the script's stdin.
+// the main fields are also passed as RALPHEX_* environment variables for simple shell scripts.
 func (c *customChannel) send(ctx context.Context, r Result) error {
 	data, err := json.Marshal(r)
 	if err != nil {
@@ -27,6 +34,16 @@ func (c *customChannel) send(ctx context.Context, r Result) error {
 
 	cmd := exec.CommandContext(ctx, c.scriptPath) //nolint:gosec // path comes from user config, not user input
 	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(),
+		"RALPHEX_STATUS="+r.Status,
+		"RALPHEX_MODE="+r.Mode,
+		"RALPHEX_PLAN="+r.PlanFile,
+		"RALPHEX_BRANCH="+r.Branch,
+		"RALPHEX_ELAPSED="+r.Duration,
+		"RALPHEX_PROGRESS_LOG="+r.ProgressLog,
+		"RALPHEX_ERROR="+r.Error,
+	)
+	cmd.WaitDelay = waitDelay
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
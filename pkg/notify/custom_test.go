@@ -57,6 +57,36 @@ func TestCustomChannel_Send(t *testing.T) {
 		assert.Equal(t, r, got)
 	})
 
+	t.Run("passes result fields as environment", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		envFile := filepath.Join(tmpDir, "env.txt")
+		script := filepath.Join(tmpDir, "env.sh")
+		err := os.WriteFile(script, //nolint:gosec // test helper script needs execute permission
+			[]byte("#!/bin/sh\nenv | grep '^RALPHEX_' | sort > "+envFile+"\n"), 0o700)
+		require.NoError(t, err)
+
+		ch := newCustomChannel(script)
+		err = ch.send(context.Background(), Result{Status: "failure", Mode: "full", PlanFile: "docs/plans/a.md",
+			Branch: "a", Duration: "1m 2s", Error: "rate limit", ProgressLog: ".ralphex/progress/progress-a.txt"})
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(envFile) //nolint:gosec // path from t.TempDir()
+		require.NoError(t, err)
+		assert.Equal(t, "RALPHEX_BRANCH=a\nRALPHEX_ELAPSED=1m 2s\nRALPHEX_ERROR=rate limit\nRALPHEX_MODE=full\n"+
+			"RALPHEX_PLAN=docs/plans/a.md\nRALPHEX_PROGRESS_LOG=.ralphex/progress/progress-a.txt\nRALPHEX_STATUS=failure\n", string(data))
+	})
+
+	t.Run("background child does not block return", func(t *testing.T) {
+		script := filepath.Join(t.TempDir(), "bg.sh")
+		err := os.WriteFile(script, //nolint:gosec // test helper script needs execute permission
+			[]byte("#!/bin/sh\nsleep 10 &\nexit 0\n"), 0o700)
+		require.NoError(t, err)
+
+		start := time.Now()
+		_ = newCustomChannel(script).send(context.Background(), Result{Status: "success"})
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
 	t.Run("non-zero exit code returns error", func(t *testing.T) {
 		script := filepath.Join("testdata", "fail.sh")
 		ch := newCustomChannel(script)
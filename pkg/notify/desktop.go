@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// desktopNotifier shows event notifications on the local desktop,
+// with terminal-notifier on macOS and notify-send on Linux.
+type desktopNotifier struct {
+	goos    string
+	timeout time.Duration
+	run     func(ctx context.Context, name string, args ...string) error // overridden in tests
+}
+
+// newDesktopNotifier creates a desktop notifier for the current OS.
+func newDesktopNotifier(timeout time.Duration) *desktopNotifier {
+	return &desktopNotifier{goos: runtime.GOOS, timeout: timeout, run: runNotifyCommand}
+}
+
+// Notify shows the notification, error level notifications are marked critical or play a sound.
+func (d *desktopNotifier) Notify(title, body string, level Level) error {
+	name, args, err := desktopCommand(d.goos, title, body, level)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	if err := d.run(ctx, name, args...); err != nil {
+		return fmt.Errorf("desktop notification: %w", err)
+	}
+	return nil
+}
+
+// desktopCommand returns the command showing a desktop notification on the given OS.
+func desktopCommand(goos, title, body string, level Level) (string, []string, error) {
+	switch goos {
+	case "darwin":
+		args := []string{"-title", title, "-message", body, "-group", "ralphex"}
+		if level == LevelError {
+			args = append(args, "-sound", "Basso")
+		}
+		return "terminal-notifier", args, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		urgency := "normal"
+		if level == LevelError {
+			urgency = "critical"
+		}
+		return "notify-send", []string{"--app-name=ralphex", "--urgency=" + urgency, title, body}, nil
+	default:
+		return "", nil, fmt.Errorf("desktop notifications are not supported on %s", goos)
+	}
+}
+
+// runNotifyCommand runs the notification command, its output is included in the error.
+func runNotifyCommand(ctx context.Context, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = waitDelay
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %w, output: %s", name, err, msg)
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDesktopCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		goos     string
+		level    Level
+		wantName string
+		wantArgs []string
+		wantErr  string
+	}{
+		{name: "macos info", goos: "darwin", level: LevelInfo, wantName: "terminal-notifier",
+			wantArgs: []string{"-title", "title", "-message", "body", "-group", "ralphex"}},
+		{name: "macos error plays a sound", goos: "darwin", level: LevelError, wantName: "terminal-notifier",
+			wantArgs: []string{"-title", "title", "-message", "body", "-group", "ralphex", "-sound", "Basso"}},
+		{name: "linux info", goos: "linux", level: LevelInfo, wantName: "notify-send",
+			wantArgs: []string{"--app-name=ralphex", "--urgency=normal", "title", "body"}},
+		{name: "linux error is critical", goos: "linux", level: LevelError, wantName: "notify-send",
+			wantArgs: []string{"--app-name=ralphex", "--urgency=critical", "title", "body"}},
+		{name: "unsupported", goos: "windows", level: LevelInfo, wantErr: "not supported on windows"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			name, args, err := desktopCommand(tc.goos, "title", "body", tc.level)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantName, name)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestDesktopNotifier_Notify(t *testing.T) {
+	t.Run("runs the command", func(t *testing.T) {
+		var gotName string
+		var gotArgs []string
+		d := &desktopNotifier{goos: "linux", timeout: time.Second, run: func(ctx context.Context, name string, args ...string) error {
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			gotName, gotArgs = name, args
+			return nil
+		}}
+		require.NoError(t, d.Notify("ralphex failed", "error: boom", LevelError))
+		assert.Equal(t, "notify-send", gotName)
+		assert.Equal(t, []string{"--app-name=ralphex", "--urgency=critical", "ralphex failed", "error: boom"}, gotArgs)
+	})
+
+	t.Run("command failure", func(t *testing.T) {
+		d := &desktopNotifier{goos: "linux", timeout: time.Second, run: func(context.Context, string, ...string) error {
+			return errors.New("exec: not found")
+		}}
+		err := d.Notify("ralphex started", "mode: full", LevelInfo)
+		require.ErrorContains(t, err, "desktop notification: exec: not found")
+	})
+
+	t.Run("unsupported os", func(t *testing.T) {
+		d := &desktopNotifier{goos: "plan9", timeout: time.Second, run: func(context.Context, string, ...string) error {
+			t.Fatal("should not run")
+			return nil
+		}}
+		require.ErrorContains(t, d.Notify("ralphex started", "mode: full", LevelInfo), "not supported on plan9")
+	})
+}
+
+func TestRunNotifyCommand(t *testing.T) {
+	require.NoError(t, runNotifyCommand(context.Background(), "sh", "-c", "exit 0"))
+	err := runNotifyCommand(context.Background(), "sh", "-c", "echo no display >&2; exit 1")
+	require.ErrorContains(t, err, "output: no display")
+}
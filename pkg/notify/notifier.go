@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Level is the severity of an event notification.
+type Level string
+
+// event notification levels
+const (
+	LevelInfo  Level = "info"
+	LevelError Level = "error"
+)
+
+// defaultTimeoutMs is used when notify_timeout_ms is not set
+const defaultTimeoutMs = 10000
+
+// Notifier sends short event notifications, e.g. when a run starts, fails or completes.
+// unlike Service, which reports the final Result to all configured channels,
+// a Notifier delivers a title and a body to a single destination.
+type Notifier interface {
+	Notify(title, body string, level Level) error
+}
+
+// NewNotifier creates the event notifier selected by Params.Notifier.
+// returns nil, nil if no notifier is configured.
+func NewNotifier(p Params) (Notifier, error) {
+	timeout := time.Duration(p.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultTimeoutMs * time.Millisecond
+	}
+	switch strings.TrimSpace(strings.ToLower(p.Notifier)) {
+	case "", "none":
+		return nil, nil //nolint:nilnil // nil,nil signals "no notifier configured"
+	case "desktop":
+		return newDesktopNotifier(timeout), nil
+	case "webhook":
+		channels, err := makeWebhookChannels(p)
+		if err != nil {
+			return nil, fmt.Errorf("webhook notifier: %w", err)
+		}
+		return &webhookNotifier{channels: channels, timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier: %q", p.Notifier)
+	}
+}
+
+// webhookNotifier posts event notifications to the configured webhook URLs.
+type webhookNotifier struct {
+	channels []channel
+	timeout  time.Duration
+}
+
+// webhookEvent is the json payload of an event notification.
+type webhookEvent struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Level Level  `json:"level"`
+}
+
+// Notify posts the notification to every webhook URL, as "title\n\nbody" text or as json.
+// all URLs are tried, the returned error joins the failures.
+func (w *webhookNotifier) Notify(title, body string, level Level) error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	defer cancel()
+
+	text := title + "\n\n" + body
+	var errs []error
+	for _, ch := range w.channels {
+		msg := text
+		if ch.jsonPayload {
+			data, err := json.Marshal(webhookEvent{Title: title, Body: body, Level: level})
+			if err != nil {
+				return fmt.Errorf("marshal event: %w", err)
+			}
+			msg = string(data)
+		}
+		if err := ch.notifier.Send(ctx, ch.dest, msg); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", ch.dest, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNotifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   Params
+		wantNil  bool
+		wantType any
+		wantErr  string
+	}{
+		{name: "not configured", params: Params{}, wantNil: true},
+		{name: "none", params: Params{Notifier: "none"}, wantNil: true},
+		{name: "desktop", params: Params{Notifier: "desktop"}, wantType: &desktopNotifier{}},
+		{name: "webhook", params: Params{Notifier: " Webhook ", WebhookURLs: []string{"http://localhost/hook"}},
+			wantType: &webhookNotifier{}},
+		{name: "webhook without urls", params: Params{Notifier: "webhook"}, wantErr: "webhook notifier: notify_webhook_urls is required"},
+		{name: "webhook with bad format", params: Params{Notifier: "webhook", WebhookURLs: []string{"http://localhost/hook"},
+			WebhookFormat: "xml"}, wantErr: "invalid notify_webhook_format"},
+		{name: "unknown", params: Params{Notifier: "pager"}, wantErr: `unknown notifier: "pager"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := NewNotifier(tc.params)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				assert.Nil(t, n)
+				return
+			}
+			require.NoError(t, err)
+			if tc.wantNil {
+				assert.Nil(t, n)
+				return
+			}
+			assert.IsType(t, tc.wantType, n)
+		})
+	}
+
+	t.Run("timeout from params", func(t *testing.T) {
+		n, err := NewNotifier(Params{Notifier: "desktop", TimeoutMs: 500})
+		require.NoError(t, err)
+		assert.Equal(t, 500*time.Millisecond, n.(*desktopNotifier).timeout)
+
+		n, err = NewNotifier(Params{Notifier: "desktop"})
+		require.NoError(t, err)
+		assert.Equal(t, 10*time.Second, n.(*desktopNotifier).timeout)
+	})
+}
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	type request struct {
+		contentType string
+		body        string
+	}
+	newServer := func(t *testing.T) (*httptest.Server, chan request) {
+		t.Helper()
+		reqs := make(chan request, 1)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			reqs <- request{contentType: r.Header.Get("Content-Type"), body: string(body)}
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(ts.Close)
+		return ts, reqs
+	}
+
+	t.Run("text", func(t *testing.T) {
+		ts, reqs := newServer(t)
+		n, err := NewNotifier(Params{Notifier: "webhook", WebhookURLs: []string{ts.URL}})
+		require.NoError(t, err)
+
+		require.NoError(t, n.Notify("ralphex started", "mode: full", LevelInfo))
+		assert.Equal(t, "ralphex started\n\nmode: full", (<-reqs).body)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		ts, reqs := newServer(t)
+		n, err := NewNotifier(Params{Notifier: "webhook", WebhookURLs: []string{ts.URL}, WebhookFormat: "json"})
+		require.NoError(t, err)
+
+		require.NoError(t, n.Notify("ralphex failed", "error: boom", LevelError))
+		req := <-reqs
+		assert.Equal(t, "application/json", req.contentType)
+		var got map[string]string
+		require.NoError(t, json.Unmarshal([]byte(req.body), &got))
+		assert.Equal(t, map[string]string{"title": "ralphex failed", "body": "error: boom", "level": "error"}, got)
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		ts, _ := newServer(t)
+		ts.Close()
+		n, err := NewNotifier(Params{Notifier: "webhook", WebhookURLs: []string{ts.URL}})
+		require.NoError(t, err)
+
+		err = n.Notify("ralphex completed", "mode: full", LevelInfo)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "webhook "+ts.URL)
+	})
+}
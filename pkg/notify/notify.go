@@ -3,6 +3,7 @@ package notify
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"html"
@@ -33,7 +34,9 @@ type Params struct {
 	EmailFrom     string
 	EmailTo       []string
 	WebhookURLs   []string
+	WebhookFormat string // "text" (default) posts the formatted message, "json" posts Result as JSON
 	CustomScript  string
+	Notifier      string // event notifier, "desktop" or "webhook", empty or "none" for none
 }
 
 // Service orchestrates sending notifications through configured channels.
@@ -49,9 +52,10 @@ type Service struct {
 
 // channel pairs a notifier with its destination URI.
 type channel struct {
-	notifier   ntfy.Notifier
-	dest       string
-	htmlEscape bool // true for channels that use HTML parse mode (e.g., telegram)
+	notifier    ntfy.Notifier
+	dest        string
+	htmlEscape  bool // true for channels that use HTML parse mode (e.g., telegram)
+	jsonPayload bool // true for channels that receive Result as JSON instead of the text message
 }
 
 // logger interface for dependency injection.
@@ -61,15 +65,16 @@ type logger interface {
 
 // Result holds completion data for notifications.
 type Result struct {
-	Status    string `json:"status"` // "success" or "failure"
-	Mode      string `json:"mode"`
-	PlanFile  string `json:"plan_file"`
-	Branch    string `json:"branch"`
-	Duration  string `json:"duration"`
-	Files     int    `json:"files"`
-	Additions int    `json:"additions"`
-	Deletions int    `json:"deletions"`
-	Error     string `json:"error,omitempty"`
+	Status      string `json:"status"` // "success" or "failure"
+	Mode        string `json:"mode"`
+	PlanFile    string `json:"plan_file"`
+	Branch      string `json:"branch"`
+	Duration    string `json:"duration"`
+	Files       int    `json:"files"`
+	Additions   int    `json:"additions"`
+	Deletions   int    `json:"deletions"`
+	Error       string `json:"error,omitempty"`
+	ProgressLog string `json:"progress_log,omitempty"` // path to the progress log of the run
 }
 
 // New creates a notification Service from the given Params.
@@ -93,7 +98,7 @@ func New(p Params, log logger) (*Service, error) {
 		log:        log,
 	}
 	if svc.timeoutMs <= 0 {
-		svc.timeoutMs = 10000
+		svc.timeoutMs = defaultTimeoutMs
 	}
 
 	for _, ch := range p.Channels {
@@ -176,7 +181,15 @@ func (s *Service) Send(ctx context.Context, r Result) {
 	// send to go-pkgz/notify channels
 	for _, ch := range s.channels {
 		text := msg
-		if ch.htmlEscape {
+		switch {
+		case ch.jsonPayload:
+			data, err := json.Marshal(r)
+			if err != nil {
+				s.log.Print("[WARN] notification failed for %s: marshal result: %v", ch.notifier, err)
+				continue
+			}
+			text = string(data)
+		case ch.htmlEscape:
 			text = html.EscapeString(msg)
 		}
 		if err := ch.notifier.Send(sendCtx, ch.dest, text); err != nil {
@@ -291,15 +304,27 @@ func makeSlackChannel(p Params) (channel, error) {
 }
 
 // makeWebhookChannels creates webhook notifiers for each configured URL.
+// with "json" format the Result is posted as application/json instead of the text message.
 func makeWebhookChannels(p Params) ([]channel, error) {
 	if len(p.WebhookURLs) == 0 {
 		return nil, errors.New("notify_webhook_urls is required")
 	}
 
-	wh := ntfy.NewWebhook(ntfy.WebhookParams{})
+	var params ntfy.WebhookParams
+	var isJSON bool
+	switch strings.TrimSpace(strings.ToLower(p.WebhookFormat)) {
+	case "", "text":
+	case "json":
+		params.Headers = []string{"Content-Type: application/json"}
+		isJSON = true
+	default:
+		return nil, fmt.Errorf("invalid notify_webhook_format %q, expected text or json", p.WebhookFormat)
+	}
+
+	wh := ntfy.NewWebhook(params)
 	var channels []channel
 	for _, u := range p.WebhookURLs {
-		channels = append(channels, channel{notifier: wh, dest: u})
+		channels = append(channels, channel{notifier: wh, dest: u, jsonPayload: isJSON})
 	}
 	return channels, nil
 }
@@ -2,8 +2,12 @@ package notify
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"sync"
 	"testing"
@@ -88,6 +92,27 @@ func TestNew(t *testing.T) {
 		assert.True(t, svc.onComplete)
 	})
 
+	t.Run("webhook channel json format", func(t *testing.T) {
+		svc, err := New(Params{
+			Channels:      []string{"webhook"},
+			WebhookURLs:   []string{"https://example.com/hook"},
+			WebhookFormat: "json",
+		}, &mockLogger{})
+		require.NoError(t, err)
+		require.Len(t, svc.channels, 1)
+		assert.True(t, svc.channels[0].jsonPayload)
+	})
+
+	t.Run("webhook channel invalid format", func(t *testing.T) {
+		_, err := New(Params{
+			Channels:      []string{"webhook"},
+			WebhookURLs:   []string{"https://example.com/hook"},
+			WebhookFormat: "xml",
+		}, &mockLogger{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid notify_webhook_format "xml"`)
+	})
+
 	t.Run("webhook channel missing urls", func(t *testing.T) {
 		_, err := New(Params{Channels: []string{"webhook"}}, &mockLogger{})
 		require.Error(t, err)
@@ -405,6 +430,78 @@ func TestService_Send(t *testing.T) {
 	})
 }
 
+func TestService_Send_Webhook(t *testing.T) {
+	type request struct {
+		contentType string
+		body        string
+	}
+	newServer := func(t *testing.T) (*httptest.Server, chan request) {
+		t.Helper()
+		reqs := make(chan request, 1)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			reqs <- request{contentType: r.Header.Get("Content-Type"), body: string(body)}
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(ts.Close)
+		return ts, reqs
+	}
+	result := Result{Status: "failure", Mode: "full", PlanFile: "docs/plans/a.md", Branch: "a", Duration: "3m",
+		Error: "claude error: rate limit", ProgressLog: ".ralphex/progress/progress-a.txt"}
+
+	t.Run("json payload", func(t *testing.T) {
+		success := Result{Status: "success", Mode: "full", PlanFile: "docs/plans/a.md", Branch: "a", Duration: "12m 34s",
+			Files: 3, Additions: 40, Deletions: 2}
+		for _, res := range []Result{result, success} {
+			t.Run(res.Status, func(t *testing.T) {
+				ts, reqs := newServer(t)
+				log := &mockLogger{}
+				svc, err := New(Params{Channels: []string{"webhook"}, OnError: true, OnComplete: true,
+					WebhookURLs: []string{ts.URL}, WebhookFormat: "json"}, log)
+				require.NoError(t, err)
+
+				svc.Send(context.Background(), res)
+				req := <-reqs
+				assert.Equal(t, "application/json", req.contentType)
+				var got Result
+				require.NoError(t, json.Unmarshal([]byte(req.body), &got))
+				assert.Equal(t, res, got)
+				var fields map[string]any
+				require.NoError(t, json.Unmarshal([]byte(req.body), &fields))
+				for _, key := range []string{"status", "mode", "plan_file", "branch", "duration"} {
+					assert.Contains(t, fields, key)
+				}
+				assert.Empty(t, log.getMsgs())
+			})
+		}
+	})
+
+	t.Run("text payload by default", func(t *testing.T) {
+		ts, reqs := newServer(t)
+		svc, err := New(Params{Channels: []string{"webhook"}, OnError: true, WebhookURLs: []string{ts.URL}}, &mockLogger{})
+		require.NoError(t, err)
+
+		svc.Send(context.Background(), result)
+		req := <-reqs
+		assert.Contains(t, req.body, "ralphex failed on")
+		assert.Contains(t, req.body, "error:    claude error: rate limit")
+	})
+
+	t.Run("unreachable webhook logs warning", func(t *testing.T) {
+		ts, _ := newServer(t)
+		ts.Close()
+		log := &mockLogger{}
+		svc, err := New(Params{Channels: []string{"webhook"}, OnError: true, WebhookURLs: []string{ts.URL},
+			WebhookFormat: "json"}, log)
+		require.NoError(t, err)
+
+		svc.Send(context.Background(), result)
+		msgs := log.getMsgs()
+		require.Len(t, msgs, 1)
+		assert.Contains(t, msgs[0], "[WARN] notification failed")
+	})
+}
+
 func TestService_FormatMessage(t *testing.T) {
 	svc := &Service{hostname: "build-server"}
 
@@ -0,0 +1,147 @@
+package plan
+
+import (
+	"regexp"
+	"strings"
+)
+
+// listItemRe matches the start of any markdown list item, used to end a checkbox continuation.
+var listItemRe = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s`)
+
+// Checkbox is a task checkbox of a plan file.
+type Checkbox struct {
+	Text string // task text, continuation lines joined with a space
+	Done bool   // checked ("- [x]")
+	Line int    // 1-based line of the checkbox
+}
+
+// ParseCheckboxes returns the "- [ ]" and "- [x]" items of plan content in order, nested items included.
+// indented lines following an item that are not list items themselves continue its text,
+// a blank line or a heading ends it. fenced code blocks and the front-matter block are skipped.
+func ParseCheckboxes(content string) []Checkbox {
+	var result []Checkbox
+	current := -1 // index of the checkbox that may continue on the next line, -1 for none
+	indent := 0   // indentation of the current checkbox
+	inFence := false
+	skip := frontMatterLines(content)
+	for i, line := range strings.Split(content, "\n") {
+		if i < skip {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			current = -1
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		if m := checkboxRe.FindStringSubmatch(line); m != nil {
+			result = append(result, Checkbox{Text: strings.TrimSpace(m[2]), Done: m[1] != " ", Line: i + 1})
+			current, indent = len(result)-1, lineIndent(line)
+			continue
+		}
+		if current < 0 || trimmed == "" || strings.HasPrefix(trimmed, "#") || listItemRe.MatchString(line) || lineIndent(line) <= indent {
+			current = -1
+			continue
+		}
+		result[current].Text = strings.TrimSpace(result[current].Text + " " + trimmed)
+	}
+	return result
+}
+
+// NewlyCompleted returns checkboxes open in before and done in after, in the order of after.
+// items are matched by text; the n-th item with a given text matches the n-th one with the same text,
+// so duplicates and items added or removed in between don't break matching.
+func NewlyCompleted(before, after []Checkbox) []Checkbox {
+	type key struct {
+		text string
+		n    int
+	}
+	seen := make(map[string]int)
+	wasOpen := make(map[key]bool, len(before))
+	for _, cb := range before {
+		k := key{text: cb.Text, n: seen[cb.Text]}
+		seen[cb.Text]++
+		wasOpen[k] = !cb.Done
+	}
+
+	var result []Checkbox
+	clear(seen)
+	for _, cb := range after {
+		k := key{text: cb.Text, n: seen[cb.Text]}
+		seen[cb.Text]++
+		if cb.Done && wasOpen[k] {
+			result = append(result, cb)
+		}
+	}
+	return result
+}
+
+// Reopen unchecks the done items of plan content that match items by text and returns the new content.
+// an item listed n times reopens the first n checked items with its text. only the box of each reopened
+// line changes from "[x]" to "[ ]", the rest of the content is kept byte-for-byte.
+func Reopen(content string, items []Checkbox) string {
+	pending := make(map[string]int, len(items))
+	for _, cb := range items {
+		pending[cb.Text]++
+	}
+	lines := strings.Split(content, "\n")
+	for _, cb := range ParseCheckboxes(content) {
+		if !cb.Done || pending[cb.Text] == 0 {
+			continue
+		}
+		pending[cb.Text]--
+		line := lines[cb.Line-1]
+		if m := checkboxRe.FindStringSubmatchIndex(line); m != nil {
+			lines[cb.Line-1] = line[:m[2]] + " " + line[m[3]:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CountDone returns the number of checked items.
+func CountDone(items []Checkbox) int {
+	done := 0
+	for _, cb := range items {
+		if cb.Done {
+			done++
+		}
+	}
+	return done
+}
+
+// CountTasks returns the number of checked "- [x]" lines and of all "- [ ]" and "- [x]" lines of plan content,
+// nested items included and the front-matter skipped. this is the scan the runner uses to decide if a plan
+// has uncompleted tasks left, so unlike ParseCheckboxes it doesn't skip fenced code blocks.
+func CountTasks(content string) (done, total int) {
+	for line := range strings.SplitSeq(StripFrontMatter(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "- [ ]"):
+			total++
+		case strings.HasPrefix(trimmed, "- [x]"), strings.HasPrefix(trimmed, "- [X]"):
+			done++
+			total++
+		}
+	}
+	return done, total
+}
+
+// lineIndent returns the width of leading whitespace, a tab counts as 4 spaces.
+func lineIndent(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
@@ -0,0 +1,137 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCheckboxes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Checkbox
+	}{
+		{name: "no checkboxes", content: "# Plan\n\ntext\n"},
+		{name: "open and done", content: "### Task 1\n- [ ] first\n- [x] second\n- [X] third\n", want: []Checkbox{
+			{Text: "first", Line: 2}, {Text: "second", Done: true, Line: 3}, {Text: "third", Done: true, Line: 4},
+		}},
+		{name: "nested", content: "- [ ] parent\n  - [x] child one\n  - [ ] child two\n    - [ ] grandchild\n", want: []Checkbox{
+			{Text: "parent", Line: 1}, {Text: "child one", Done: true, Line: 2},
+			{Text: "child two", Line: 3}, {Text: "grandchild", Line: 4},
+		}},
+		{name: "multi-line task", content: "- [ ] add config validation\n  for all keys\n  and values\n- [ ] next\n", want: []Checkbox{
+			{Text: "add config validation for all keys and values", Line: 1}, {Text: "next", Line: 4},
+		}},
+		{name: "continuation ends at blank line", content: "- [ ] task\n\n  indented paragraph\n", want: []Checkbox{
+			{Text: "task", Line: 1},
+		}},
+		{name: "continuation ends at list item", content: "- [ ] task\n  - plain note\n  more note\n", want: []Checkbox{
+			{Text: "task", Line: 1},
+		}},
+		{name: "unindented line is not a continuation", content: "- [ ] task\nnext paragraph\n", want: []Checkbox{
+			{Text: "task", Line: 1},
+		}},
+		{name: "code fence skipped", content: "```\n- [ ] example\n```\n- [ ] real\n", want: []Checkbox{
+			{Text: "real", Line: 4},
+		}},
+		{name: "malformed not counted", content: "-[ ] a\n* [ ] b\n- [ ] c\n", want: []Checkbox{{Text: "c", Line: 3}}},
+		{name: "front-matter skipped", content: "---\nnotes: |\n  - [ ] example\n---\n- [ ] real\n", want: []Checkbox{
+			{Text: "real", Line: 5},
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ParseCheckboxes(tc.content))
+		})
+	}
+}
+
+func TestNewlyCompleted(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []string
+	}{
+		{name: "nothing changed", before: "- [ ] a\n- [x] b\n", after: "- [ ] a\n- [x] b\n"},
+		{name: "one completed", before: "- [ ] a\n- [ ] b\n", after: "- [x] a\n- [ ] b\n", want: []string{"a"}},
+		{name: "nested completed", before: "- [ ] a\n  - [ ] a1\n  - [ ] a2\n", after: "- [ ] a\n  - [x] a1\n  - [x] a2\n",
+			want: []string{"a1", "a2"}},
+		{name: "duplicates matched by occurrence", before: "- [x] add tests\n- [ ] add tests\n",
+			after: "- [x] add tests\n- [x] add tests\n", want: []string{"add tests"}},
+		{name: "task added above", before: "- [ ] b\n", after: "- [ ] new\n- [x] b\n", want: []string{"b"}},
+		{name: "new task added as done", before: "- [ ] a\n", after: "- [ ] a\n- [x] extra\n"},
+		{name: "unchecked again", before: "- [x] a\n", after: "- [ ] a\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, cb := range NewlyCompleted(ParseCheckboxes(tc.before), ParseCheckboxes(tc.after)) {
+				got = append(got, cb.Text)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestReopen(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		items   []string
+		want    string
+	}{
+		{name: "nothing to reopen", content: "- [x] a\n- [ ] b\n", want: "- [x] a\n- [ ] b\n"},
+		{name: "one reopened", content: "# Plan\n- [x] a\n- [X] b\n", items: []string{"b"}, want: "# Plan\n- [x] a\n- [ ] b\n"},
+		{name: "nested and crlf kept", content: "- [ ] a\r\n\t- [x] a1  \r\n- [x] b\r\n", items: []string{"a1"},
+			want: "- [ ] a\r\n\t- [ ] a1  \r\n- [x] b\r\n"},
+		{name: "multi-line task", content: "- [x] add config\n  for all keys\n- [x] b", items: []string{"add config for all keys"},
+			want: "- [ ] add config\n  for all keys\n- [x] b"},
+		{name: "duplicates reopened by count", content: "- [x] add tests\n- [x] add tests\n- [x] add tests\n",
+			items: []string{"add tests", "add tests"}, want: "- [ ] add tests\n- [ ] add tests\n- [x] add tests\n"},
+		{name: "open and unknown items ignored", content: "- [ ] a\n- [x] b\n", items: []string{"a", "c"},
+			want: "- [ ] a\n- [x] b\n"},
+		{name: "code fence untouched", content: "```\n- [x] a\n```\n- [x] a\n", items: []string{"a"},
+			want: "```\n- [x] a\n```\n- [ ] a\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			items := make([]Checkbox, 0, len(tc.items))
+			for _, text := range tc.items {
+				items = append(items, Checkbox{Text: text, Done: true})
+			}
+			assert.Equal(t, tc.want, Reopen(tc.content, items))
+		})
+	}
+}
+
+func TestCountDone(t *testing.T) {
+	assert.Equal(t, 0, CountDone(nil))
+	assert.Equal(t, 2, CountDone(ParseCheckboxes("- [x] a\n- [ ] b\n  - [x] c\n")))
+}
+
+func TestCountTasks(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantDone  int
+		wantTotal int
+	}{
+		{name: "empty", content: ""},
+		{name: "no tasks", content: "# Plan\nsome notes\n"},
+		{name: "mixed", content: "# Plan\n- [x] a\n- [ ] b\n  - [X] c\n* [ ] not a task\n", wantDone: 2, wantTotal: 3},
+		{name: "all done", content: "- [x] a\n- [x] b\n", wantDone: 2, wantTotal: 2},
+		{name: "front-matter skipped", content: "---\nnote: \"- [ ] x\"\n---\n- [ ] a\n", wantTotal: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			done, total := CountTasks(tc.content)
+			assert.Equal(t, tc.wantDone, done)
+			assert.Equal(t, tc.wantTotal, total)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package plan
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// checkboxRe matches the task checkboxes the runner recognizes: "- [ ]" and "- [x]".
+var checkboxRe = regexp.MustCompile(`^\s*- \[([ xX])\]\s*(.*)$`)
+
+// looseCheckboxRe matches lines that look like a checkbox, including malformed ones
+// such as "-[ ]", "* [ ]", "+ [x]" or "- []".
+var looseCheckboxRe = regexp.MustCompile(`^\s*[-*+]\s*\[\s*[xX]?\s*\]`)
+
+// LintIssue is a problem found in a plan file.
+type LintIssue struct {
+	Line  int    // 1-based line number, 0 for issues of the whole file
+	Text  string // description of the problem
+	Fatal bool   // the plan can't be executed; otherwise it's a warning
+}
+
+// String formats the issue as "error|warning: line N: text".
+func (i LintIssue) String() string {
+	kind := "warning"
+	if i.Fatal {
+		kind = "error"
+	}
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s", kind, i.Text)
+	}
+	return fmt.Sprintf("%s: line %d: %s", kind, i.Line, i.Text)
+}
+
+// LintFile reads and lints a plan file, see Lint.
+func LintFile(path string, requireTasks bool) ([]LintIssue, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // path is the user-selected plan file
+	if err != nil {
+		return nil, fmt.Errorf("read plan file: %w", err)
+	}
+	return Lint(content, requireTasks), nil
+}
+
+// Lint checks plan content for problems that make task detection unreliable.
+// empty or non-UTF-8 content is fatal. checkbox-like lines the runner doesn't recognize,
+// duplicate task text within a section and, with requireTasks, a plan without tasks are warnings.
+// lines inside fenced code blocks and the front-matter block are ignored.
+func Lint(content []byte, requireTasks bool) []LintIssue {
+	if !utf8.Valid(content) {
+		return []LintIssue{{Text: "plan file is not valid UTF-8", Fatal: true}}
+	}
+	if strings.TrimSpace(string(content)) == "" {
+		return []LintIssue{{Text: "plan file is empty", Fatal: true}}
+	}
+
+	var issues []LintIssue
+	tasks := 0
+	firstSeen := make(map[string]int) // task text -> line of its first occurrence in the current section
+	inFence := false
+	skip := frontMatterLines(string(content))
+	for i, line := range strings.Split(string(content), "\n") {
+		lineNum := i + 1
+		if i < skip {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			// sections repeat generic items like "Add tests", duplicates are only checked within one
+			firstSeen = make(map[string]int)
+			continue
+		}
+
+		if m := checkboxRe.FindStringSubmatch(line); m != nil {
+			tasks++
+			text := strings.TrimSpace(m[2])
+			if first, ok := firstSeen[text]; ok && text != "" {
+				issues = append(issues, LintIssue{Line: lineNum, Text: fmt.Sprintf("duplicate task %q, first at line %d", text, first)})
+				continue
+			}
+			firstSeen[text] = lineNum
+			continue
+		}
+		if looseCheckboxRe.MatchString(line) {
+			issues = append(issues, LintIssue{Line: lineNum,
+				Text: fmt.Sprintf("malformed checkbox %q, expected \"- [ ]\" or \"- [x]\"", strings.TrimSpace(line))})
+		}
+	}
+
+	if requireTasks && tasks == 0 {
+		issues = append(issues, LintIssue{Text: "plan has no tasks (\"- [ ]\" checkboxes), nothing to execute"})
+	}
+	return issues
+}
@@ -0,0 +1,68 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		requireTasks bool
+		want         []LintIssue
+	}{
+		{name: "clean plan", content: "# Plan\n\n### Task 1\n- [ ] first\n- [x] second\n- [X] third\n", requireTasks: true},
+		{name: "empty", content: " \n\n", requireTasks: true, want: []LintIssue{{Text: "plan file is empty", Fatal: true}}},
+		{name: "not utf-8", content: "# Plan\n- [ ] \xff\xfe\n", requireTasks: true,
+			want: []LintIssue{{Text: "plan file is not valid UTF-8", Fatal: true}}},
+		{name: "malformed checkboxes", content: "- [ ] ok\n-[ ] no space\n* [ ] star\n  + [x] plus\n- [] empty\n",
+			requireTasks: true, want: []LintIssue{
+				{Line: 2, Text: `malformed checkbox "-[ ] no space", expected "- [ ]" or "- [x]"`},
+				{Line: 3, Text: `malformed checkbox "* [ ] star", expected "- [ ]" or "- [x]"`},
+				{Line: 4, Text: `malformed checkbox "+ [x] plus", expected "- [ ]" or "- [x]"`},
+				{Line: 5, Text: `malformed checkbox "- [] empty", expected "- [ ]" or "- [x]"`},
+			}},
+		{name: "no tasks required", content: "# Plan\n\njust text\n", requireTasks: true,
+			want: []LintIssue{{Text: `plan has no tasks ("- [ ]" checkboxes), nothing to execute`}}},
+		{name: "no tasks not required", content: "# Plan\n\njust text\n"},
+		{name: "front-matter ignored", content: "---\nagents: [quality]\nnotes: \"* [ ] todo\"\n---\n# Plan\n- [ ] task\n", requireTasks: true},
+		{name: "only malformed tasks", content: "* [ ] task\n", requireTasks: true, want: []LintIssue{
+			{Line: 1, Text: `malformed checkbox "* [ ] task", expected "- [ ]" or "- [x]"`},
+			{Text: `plan has no tasks ("- [ ]" checkboxes), nothing to execute`},
+		}},
+		{name: "duplicate tasks", content: "- [ ] run tests\n- [x] other\n- [ ] run tests\n", requireTasks: true,
+			want: []LintIssue{{Line: 3, Text: `duplicate task "run tests", first at line 1`}}},
+		{name: "same task in different sections", content: "### Task 1\n- [ ] add tests\n### Task 2\n- [ ] add tests\n",
+			requireTasks: true},
+		{name: "code fence ignored", content: "- [ ] task\n```md\n* [ ] example\n- [ ] task\n```\n", requireTasks: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Lint([]byte(tc.content), tc.requireTasks))
+		})
+	}
+}
+
+func TestLintIssue_String(t *testing.T) {
+	assert.Equal(t, "error: plan file is empty", LintIssue{Text: "plan file is empty", Fatal: true}.String())
+	assert.Equal(t, "warning: line 3: bad", LintIssue{Line: 3, Text: "bad"}.String())
+}
+
+func TestLintFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(path, []byte("-[ ] task\n"), 0o600))
+
+	issues, err := LintFile(path, false)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 1, issues[0].Line)
+
+	_, err = LintFile(filepath.Join(t.TempDir(), "missing.md"), false)
+	require.ErrorContains(t, err, "read plan file")
+}
@@ -7,10 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
 	"strings"
 	"time"
 
@@ -21,6 +24,15 @@ import (
 // datePrefixRe matches date-like prefixes in plan filenames (e.g., "2024-01-15-").
 var datePrefixRe = regexp.MustCompile(`^[\d-]+`)
 
+// DefaultCompletedDir is the name of the directory finished plans are moved to unless configured otherwise.
+const DefaultCompletedDir = "completed"
+
+// Archive describes where finished plans are moved, configured by completed_dir and completed_dir_date_layout.
+type Archive struct {
+	Dir        string // directory name, DefaultCompletedDir if empty
+	DateLayout string // time layout of a dated subdirectory in Dir, e.g. "2006-01", none if empty
+}
+
 // ErrNoPlansFound is returned when no plan files exist in the plans directory.
 var ErrNoPlansFound = errors.New("no plans found")
 
@@ -28,6 +40,7 @@ var ErrNoPlansFound = errors.New("no plans found")
 type Selector struct {
 	PlansDir string
 	Colors   *progress.Colors
+	Archive  Archive // finished plans are skipped
 }
 
 // NewSelector creates a new Selector with the given plans directory and colors.
@@ -39,7 +52,8 @@ func NewSelector(plansDir string, colors *progress.Colors) *Selector {
 }
 
 // Select selects and prepares a plan file.
-// if planFile is provided, validates it exists and returns absolute path.
+// if planFile is provided, validates it exists and returns absolute path. a glob pattern
+// (e.g. 'docs/plans/backend/*.md') resolves to its only match or picks one of the matches with fzf.
 // if planFile is empty and optional is true, returns empty string without error.
 // if planFile is empty and optional is false, uses fzf for selection.
 func (s *Selector) Select(ctx context.Context, planFile string, optional bool) (string, error) {
@@ -61,13 +75,51 @@ func (s *Selector) Select(ctx context.Context, planFile string, optional bool) (
 	return abs, nil
 }
 
+// SelectMultiple selects one or more plan files for sequential execution.
+// if planFiles are provided, validates each exists and returns absolute paths in the given order,
+// a glob pattern matching several plans lets the user mark some of them with fzf.
+// if none are provided, uses fzf in multi-select mode (a single plan is auto-selected).
+func (s *Selector) SelectMultiple(ctx context.Context, planFiles []string) ([]string, error) {
+	var selected []string
+	for _, p := range planFiles {
+		matches, err := s.expandGlob(ctx, p, true)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, matches...)
+	}
+	if len(planFiles) == 0 {
+		var err error
+		if selected, err = s.selectWithFzf(ctx, true); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]string, 0, len(selected))
+	for _, p := range selected {
+		if _, err := os.Stat(p); err != nil {
+			return nil, fmt.Errorf("plan file not found: %s", p)
+		}
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, fmt.Errorf("resolve plan path: %w", err)
+		}
+		result = append(result, abs)
+	}
+	return result, nil
+}
+
 // selectPlan handles the logic for selecting a plan file.
 func (s *Selector) selectPlan(ctx context.Context, planFile string, optional bool) (string, error) {
 	if planFile != "" {
-		if _, err := os.Stat(planFile); err != nil {
+		matches, err := s.expandGlob(ctx, planFile, false)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(matches[0]); err != nil {
 			return "", fmt.Errorf("plan file not found: %s", planFile)
 		}
-		return planFile, nil
+		return matches[0], nil
 	}
 
 	// for review-only modes, plan is optional
@@ -76,58 +128,278 @@ func (s *Selector) selectPlan(ctx context.Context, planFile string, optional boo
 	}
 
 	// use fzf to select plan
-	return s.selectWithFzf(ctx)
+	selected, err := s.selectWithFzf(ctx, false)
+	if err != nil {
+		return "", err
+	}
+	return selected[0], nil
+}
+
+// expandGlob resolves a plan argument that is a glob pattern to the plans it matches, plans in completed/ excluded.
+// several matches are offered in fzf. an existing file or a plain path is returned as is.
+func (s *Selector) expandGlob(ctx context.Context, planFile string, multi bool) ([]string, error) {
+	if !strings.ContainsAny(planFile, "*?[") {
+		return []string{planFile}, nil
+	}
+	if _, err := os.Stat(planFile); err == nil {
+		return []string{planFile}, nil // literal file name with glob characters
+	}
+	matches, err := filepath.Glob(planFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plan pattern %s: %w", planFile, err)
+	}
+	matches = slices.DeleteFunc(matches, func(m string) bool { return s.Archive.contains(m) || !strings.HasSuffix(m, ".md") })
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: no plans match %s", ErrNoPlansFound, planFile)
+	}
+	return s.choose(ctx, "", matches, multi)
 }
 
-// selectWithFzf uses fzf to interactively select a plan file from the plans directory.
-func (s *Selector) selectWithFzf(ctx context.Context) (string, error) {
+// selectWithFzf uses fzf to interactively select plan files from the plans directory and its subdirectories.
+// with multi set, fzf runs in multi-select mode (tab to mark) and may return several plans.
+func (s *Selector) selectWithFzf(ctx context.Context, multi bool) ([]string, error) {
 	if _, err := os.Stat(s.PlansDir); err != nil {
 		if os.IsNotExist(err) {
-			return "", fmt.Errorf("%w: %s (directory missing)", ErrNoPlansFound, s.PlansDir)
+			return nil, fmt.Errorf("%w: %s (directory missing)", ErrNoPlansFound, s.PlansDir)
 		}
-		return "", fmt.Errorf("cannot access plans directory %s: %w", s.PlansDir, err)
+		return nil, fmt.Errorf("cannot access plans directory %s: %w", s.PlansDir, err)
 	}
 
-	// find plan files (excluding completed/)
-	plans, err := filepath.Glob(filepath.Join(s.PlansDir, "*.md"))
+	plans, err := findPlans(s.PlansDir, s.Archive.name())
 	if err != nil || len(plans) == 0 {
-		return "", fmt.Errorf("%w: %s", ErrNoPlansFound, s.PlansDir)
+		return nil, fmt.Errorf("%w: %s", ErrNoPlansFound, s.PlansDir)
 	}
+	return s.choose(ctx, s.PlansDir, plans, multi)
+}
 
+// choose returns the only plan of the list, or lets the user pick with fzf.
+// fzf shows paths relative to baseDir (the current directory if empty) and runs there, so the preview works.
+func (s *Selector) choose(ctx context.Context, baseDir string, plans []string, multi bool) ([]string, error) {
 	// auto-select if single plan (no fzf needed)
 	if len(plans) == 1 {
 		s.Colors.Info().Printf("auto-selected: %s\n", plans[0])
-		return plans[0], nil
+		return plans, nil
 	}
 
 	// multiple plans require fzf
 	if _, lookupErr := exec.LookPath("fzf"); lookupErr != nil {
-		return "", errors.New("fzf not found, please provide plan file as argument")
+		return nil, errors.New("fzf not found, please provide plan file as argument")
+	}
+
+	// map displayed relative paths back to plan paths
+	byName := make(map[string]string, len(plans))
+	names := make([]string, 0, len(plans))
+	for _, p := range plans {
+		name := p
+		if baseDir != "" {
+			if rel, err := filepath.Rel(baseDir, p); err == nil {
+				name = rel
+			}
+		}
+		byName[name] = p
+		names = append(names, name)
 	}
 
 	// use fzf for selection
-	cmd := exec.CommandContext(ctx, "fzf",
-		"--prompt=select plan: ",
-		"--preview=head -50 {}",
-		"--preview-window=right:60%",
-	)
-	cmd.Stdin = strings.NewReader(strings.Join(plans, "\n"))
+	args := []string{"--prompt=select plan: ", "--preview=" + fzfPreview(runtime.GOOS), "--preview-window=right:60%"}
+	if multi {
+		args = append(args, "--multi", "--prompt=select plans (tab to mark): ")
+	}
+	cmd := exec.CommandContext(ctx, "fzf", args...)
+	cmd.Dir = baseDir
+	cmd.Stdin = strings.NewReader(strings.Join(names, "\n"))
 	cmd.Stderr = os.Stderr
 
 	out, err := cmd.Output()
 	if err != nil {
-		return "", errors.New("no plan selected")
+		return nil, errors.New("no plan selected")
+	}
+
+	var selected []string
+	for line := range strings.SplitSeq(strings.TrimSpace(string(out)), "\n") {
+		if p, ok := byName[strings.TrimSpace(line)]; ok {
+			selected = append(selected, p)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, errors.New("no plan selected")
 	}
+	return selected, nil
+}
 
-	return strings.TrimSpace(string(out)), nil
+// Pending returns the plan files in the plans directory and its subdirectories, skipping finished plans.
+// a missing plans directory has no plans.
+func (s *Selector) Pending() ([]string, error) {
+	if _, err := os.Stat(s.PlansDir); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return findPlans(s.PlansDir, s.Archive.name())
 }
 
-// FindRecent finds the most recently modified plan file in the plans directory
+// Completed returns the plan files moved to the completed directory at any level of the plans directory.
+// a missing plans directory has no completed plans.
+func (s *Selector) Completed() ([]string, error) {
+	if _, err := os.Stat(s.PlansDir); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	var plans []string
+	err := filepath.WalkDir(s.PlansDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".md") {
+			return nil
+		}
+		if rel, relErr := filepath.Rel(s.PlansDir, path); relErr == nil && s.Archive.contains(rel) {
+			plans = append(plans, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find completed plans in %s: %w", s.PlansDir, err)
+	}
+	return plans, nil
+}
+
+// fzfPreview returns the fzf preview command showing the top of a plan file. fzf runs it with sh, or with
+// cmd on windows, which has no head; type prints the whole file and the preview window shows its top.
+func fzfPreview(goos string) string {
+	if goos == "windows" {
+		return "type {}"
+	}
+	return "head -50 {}"
+}
+
+// findPlans returns .md files in dir and its subdirectories, skipping directories named completedDir at any level.
+func findPlans(dir, completedDir string) ([]string, error) {
+	var plans []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && d.Name() == completedDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), ".md") {
+			plans = append(plans, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find plans in %s: %w", dir, err)
+	}
+	return plans, nil
+}
+
+// name returns the name of the directory finished plans are moved to.
+func (a Archive) name() string {
+	if a.Dir == "" {
+		return DefaultCompletedDir
+	}
+	return a.Dir
+}
+
+// contains returns true if any directory of the path is the completed directory.
+func (a Archive) contains(path string) bool {
+	return slices.Contains(strings.Split(filepath.ToSlash(filepath.Dir(path)), "/"), a.name())
+}
+
+// CompletedPath returns where a finished plan is moved: the completed directory in plansDir, keeping the plan's
+// subdirectory (docs/plans/backend/x.md -> docs/plans/completed/backend/x.md), under a subdirectory named
+// by formatting now with DateLayout if set (docs/plans/completed/2026-01/backend/x.md).
+// plans outside plansDir, or any plan if plansDir is empty, go to the completed directory next to the plan file.
+func (a Archive) CompletedPath(planFile, plansDir string, now time.Time) string {
+	completedDir := a.name()
+	if a.DateLayout != "" {
+		completedDir = filepath.Join(completedDir, filepath.FromSlash(now.Format(a.DateLayout)))
+	}
+	fallback := filepath.Join(filepath.Dir(planFile), completedDir, filepath.Base(planFile))
+	if plansDir == "" {
+		return fallback
+	}
+	absPlan, err := filepath.Abs(planFile)
+	if err != nil {
+		return fallback
+	}
+	absDir, err := filepath.Abs(plansDir)
+	if err != nil {
+		return fallback
+	}
+	rel, err := filepath.Rel(absDir, absPlan)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fallback
+	}
+	if filepath.IsAbs(planFile) {
+		return filepath.Join(absDir, completedDir, rel)
+	}
+	return filepath.Join(plansDir, completedDir, rel)
+}
+
+// FindCompleted returns the location of a plan moved by CompletedPath without knowing the plans directory:
+// the completed directory in each parent directory is checked for the plan's path relative to that parent.
+// with DateLayout, dated subdirectories are checked and the last one in name order wins.
+// returns empty string if the plan is not found in any completed directory.
+func (a Archive) FindCompleted(planFile string) string {
+	// a dated subdirectory has as many directory levels as the layout has path elements
+	depth := len(strings.FieldsFunc(a.DateLayout, func(r rune) bool { return r == '/' }))
+	dir := filepath.Dir(planFile)
+	for {
+		rel, err := filepath.Rel(dir, planFile)
+		if err != nil {
+			return ""
+		}
+		candidate := filepath.Join(dir, a.name(), rel)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+		if depth > 0 {
+			if dated := findDated(filepath.Join(dir, a.name()), depth, rel); dated != "" {
+				return dated
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// findDated returns the plan at rel in the last, in name order, dated subdirectory of completedDir
+// that has it, with depth directory levels per dated subdirectory. returns empty string if none has it.
+func findDated(completedDir string, depth int, rel string) string {
+	dirs := []string{completedDir}
+	for range depth {
+		var next []string
+		for _, dir := range dirs {
+			entries, err := os.ReadDir(dir) // sorted by name
+			if err != nil {
+				continue
+			}
+			for _, e := range entries {
+				if e.IsDir() {
+					next = append(next, filepath.Join(dir, e.Name()))
+				}
+			}
+		}
+		dirs = next
+	}
+	for _, dir := range slices.Backward(dirs) {
+		candidate := filepath.Join(dir, rel)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// FindRecent finds the most recently modified plan file in the plans directory and its subdirectories
 // that was modified after the given start time.
 func (s *Selector) FindRecent(startTime time.Time) string {
-	// find all .md files in plansDir (excluding completed/ subdirectory)
-	pattern := filepath.Join(s.PlansDir, "*.md")
-	plans, err := filepath.Glob(pattern)
+	plans, err := findPlans(s.PlansDir, s.Archive.name())
 	if err != nil || len(plans) == 0 {
 		return ""
 	}
@@ -165,6 +437,24 @@ func ExtractBranchName(planFile string) string {
 	return branchName
 }
 
+// SplitTitle returns the text of the plan's first "# " heading and the plan content after it.
+// without such a heading, title is empty and body is the whole plan. the front-matter block is skipped.
+func SplitTitle(content string) (title, body string) {
+	content = strings.TrimSpace(StripFrontMatter(strings.ReplaceAll(content, "\r\n", "\n")))
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if heading, ok := strings.CutPrefix(line, "# "); ok {
+			return strings.TrimSpace(heading), strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+		}
+		break
+	}
+	return "", content
+}
+
 // PromptDescription prompts the user to enter a plan description.
 // returns empty string if user cancels (Ctrl+C or Ctrl+D).
 func PromptDescription(ctx context.Context, r io.Reader, colors *progress.Colors) string {
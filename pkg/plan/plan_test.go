@@ -80,7 +80,7 @@ func TestSelector_SelectWithFzf(t *testing.T) {
 
 	t.Run("missing directory returns error", func(t *testing.T) {
 		sel := NewSelector("/nonexistent", colors)
-		_, err := sel.selectWithFzf(context.Background())
+		_, err := sel.selectWithFzf(context.Background(), false)
 		require.Error(t, err)
 		assert.ErrorIs(t, err, ErrNoPlansFound)
 	})
@@ -88,7 +88,7 @@ func TestSelector_SelectWithFzf(t *testing.T) {
 	t.Run("empty directory returns error", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		sel := NewSelector(tmpDir, colors)
-		_, err := sel.selectWithFzf(context.Background())
+		_, err := sel.selectWithFzf(context.Background(), false)
 		require.Error(t, err)
 		assert.ErrorIs(t, err, ErrNoPlansFound)
 	})
@@ -99,9 +99,263 @@ func TestSelector_SelectWithFzf(t *testing.T) {
 		require.NoError(t, os.WriteFile(planFile, []byte("# Test"), 0o600))
 
 		sel := NewSelector(tmpDir, colors)
-		result, err := sel.selectWithFzf(context.Background())
+		result, err := sel.selectWithFzf(context.Background(), false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{planFile}, result)
+	})
+
+	t.Run("single plan in subdirectory auto-selects, completed ignored", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "backend", "completed"), 0o750))
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "completed", "frontend"), 0o750))
+		planFile := filepath.Join(tmpDir, "backend", "api.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# API"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "backend", "completed", "old.md"), []byte("# Old"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "completed", "frontend", "ui.md"), []byte("# UI"), 0o600))
+
+		sel := NewSelector(tmpDir, colors)
+		result, err := sel.selectWithFzf(context.Background(), false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{planFile}, result)
+	})
+}
+
+func TestFindPlans(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, f := range []string{"a.md", "notes.txt", "backend/b.md", "backend/deep/c.md", "backend/completed/d.md", "completed/e.md",
+		"archive/f.md"} {
+		path := filepath.Join(tmpDir, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte("# Plan"), 0o600))
+	}
+
+	plans, err := findPlans(tmpDir, DefaultCompletedDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(tmpDir, "a.md"), filepath.Join(tmpDir, "archive", "f.md"),
+		filepath.Join(tmpDir, "backend", "b.md"), filepath.Join(tmpDir, "backend", "deep", "c.md")}, plans)
+
+	plans, err = findPlans(tmpDir, "archive")
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(tmpDir, "a.md"), filepath.Join(tmpDir, "backend", "b.md"),
+		filepath.Join(tmpDir, "backend", "completed", "d.md"), filepath.Join(tmpDir, "backend", "deep", "c.md"),
+		filepath.Join(tmpDir, "completed", "e.md")}, plans)
+}
+
+func TestSelector_Pending(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, f := range []string{"a.md", "backend/b.md", "done/c.md"} {
+		path := filepath.Join(tmpDir, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte("# Plan"), 0o600))
+	}
+
+	s := NewSelector(tmpDir, nil)
+	s.Archive = Archive{Dir: "done"}
+	plans, err := s.Pending()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(tmpDir, "a.md"), filepath.Join(tmpDir, "backend", "b.md")}, plans)
+
+	plans, err = NewSelector(filepath.Join(tmpDir, "missing"), nil).Pending()
+	require.NoError(t, err)
+	assert.Empty(t, plans)
+}
+
+func TestSelector_Completed(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, f := range []string{"a.md", "done/c.md", "done/2026-01/d.md", "backend/done/e.md", "done/notes.txt"} {
+		path := filepath.Join(tmpDir, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte("# Plan"), 0o600))
+	}
+
+	s := NewSelector(tmpDir, nil)
+	s.Archive = Archive{Dir: "done"}
+	plans, err := s.Completed()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(tmpDir, "backend", "done", "e.md"), filepath.Join(tmpDir, "done", "2026-01", "d.md"),
+		filepath.Join(tmpDir, "done", "c.md")}, plans)
+
+	plans, err = NewSelector(filepath.Join(tmpDir, "missing"), nil).Completed()
+	require.NoError(t, err)
+	assert.Empty(t, plans)
+}
+
+func TestSelector_Select_Glob(t *testing.T) {
+	colors := progress.NewColors(config.ColorConfig{
+		Task: "0,255,0", Review: "255,255,0", Codex: "255,165,0",
+		ClaudeEval: "0,255,255", Warn: "255,165,0", Error: "255,0,0",
+		Signal: "255,0,255", Timestamp: "128,128,128", Info: "255,255,255",
+	})
+
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "backend", "completed"), 0o750))
+	planFile := filepath.Join(tmpDir, "backend", "api.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# API"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "backend", "notes.txt"), []byte("notes"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "backend", "completed", "old.md"), []byte("# Old"), 0o600))
+	sel := NewSelector(tmpDir, colors)
+
+	t.Run("single match is selected", func(t *testing.T) {
+		result, err := sel.Select(context.Background(), filepath.Join(tmpDir, "backend", "*"), false)
 		require.NoError(t, err)
 		assert.Equal(t, planFile, result)
+
+		results, err := sel.SelectMultiple(context.Background(), []string{filepath.Join(tmpDir, "*", "*.md")})
+		require.NoError(t, err)
+		assert.Equal(t, []string{planFile}, results)
+	})
+
+	t.Run("no match returns error", func(t *testing.T) {
+		_, err := sel.Select(context.Background(), filepath.Join(tmpDir, "frontend", "*.md"), false)
+		require.ErrorIs(t, err, ErrNoPlansFound)
+		assert.Contains(t, err.Error(), "no plans match")
+	})
+
+	t.Run("invalid pattern returns error", func(t *testing.T) {
+		_, err := sel.Select(context.Background(), filepath.Join(tmpDir, "[a-.md"), false)
+		require.ErrorContains(t, err, "invalid plan pattern")
+	})
+}
+
+func TestArchive_CompletedPath(t *testing.T) {
+	now := time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		archive  Archive
+		planFile string
+		plansDir string
+		want     string
+	}{
+		{name: "top level plan", planFile: "/repo/docs/plans/feature.md", plansDir: "/repo/docs/plans",
+			want: "/repo/docs/plans/completed/feature.md"},
+		{name: "plan in subdirectory", planFile: "/repo/docs/plans/backend/api.md", plansDir: "/repo/docs/plans",
+			want: "/repo/docs/plans/completed/backend/api.md"},
+		{name: "relative paths", planFile: "docs/plans/backend/api.md", plansDir: "docs/plans",
+			want: "docs/plans/completed/backend/api.md"},
+		{name: "plan outside plans dir", planFile: "/repo/adhoc/plan.md", plansDir: "/repo/docs/plans",
+			want: "/repo/adhoc/completed/plan.md"},
+		{name: "no plans dir", planFile: "/repo/docs/plans/backend/api.md", want: "/repo/docs/plans/backend/completed/api.md"},
+		{name: "custom dir", archive: Archive{Dir: "archive"}, planFile: "/repo/docs/plans/backend/api.md",
+			plansDir: "/repo/docs/plans", want: "/repo/docs/plans/archive/backend/api.md"},
+		{name: "dated layout", archive: Archive{DateLayout: "2006-01"}, planFile: "/repo/docs/plans/backend/api.md",
+			plansDir: "/repo/docs/plans", want: "/repo/docs/plans/completed/2026-03/backend/api.md"},
+		{name: "nested dated layout", archive: Archive{Dir: "archive", DateLayout: "2006/01-02"},
+			planFile: "/repo/adhoc/plan.md", plansDir: "/repo/docs/plans", want: "/repo/adhoc/archive/2026/03-05/plan.md"},
+		{name: "sibling dir sharing the prefix", planFile: "/repo/docs/plans-old/plan.md", plansDir: "/repo/docs/plans",
+			want: "/repo/docs/plans-old/completed/plan.md"},
+		{name: "file name starting with dots", planFile: "/repo/docs/plans/..draft.md", plansDir: "/repo/docs/plans",
+			want: "/repo/docs/plans/completed/..draft.md"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.archive.CompletedPath(filepath.FromSlash(tc.planFile), filepath.FromSlash(tc.plansDir), now)
+			assert.Equal(t, filepath.FromSlash(tc.want), got)
+		})
+	}
+}
+
+func TestArchive_contains(t *testing.T) {
+	tests := []struct {
+		archive Archive
+		path    string
+		want    bool
+	}{
+		{path: "completed/feature.md", want: true},
+		{path: "backend/completed/api.md", want: true},
+		{path: "completed/2026-03/backend/api.md", want: true},
+		{path: "backend/api.md", want: false},
+		{path: "completed.md", want: false},
+		{path: "not-completed/api.md", want: false},
+		{archive: Archive{Dir: "archive"}, path: "archive/api.md", want: true},
+		{archive: Archive{Dir: "archive"}, path: "completed/api.md", want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			// native separators, backslashes on windows
+			assert.Equal(t, tc.want, tc.archive.contains(filepath.FromSlash(tc.path)))
+		})
+	}
+}
+
+func TestFzfPreview(t *testing.T) {
+	assert.Equal(t, "head -50 {}", fzfPreview("linux"))
+	assert.Equal(t, "head -50 {}", fzfPreview("darwin"))
+	assert.Equal(t, "type {}", fzfPreview("windows"))
+}
+
+func TestArchive_FindCompleted(t *testing.T) {
+	tmpDir := t.TempDir()
+	nested := filepath.Join(tmpDir, "completed", "backend", "api.md")
+	sibling := filepath.Join(tmpDir, "frontend", "completed", "ui.md")
+	custom := filepath.Join(tmpDir, "archive", "backend", "api.md")
+	datedOld := filepath.Join(tmpDir, "archive", "2026", "01", "backend", "db.md")
+	datedNew := filepath.Join(tmpDir, "archive", "2026", "02", "backend", "db.md")
+	for _, f := range []string{nested, sibling, custom, datedOld, datedNew} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(f), 0o750))
+		require.NoError(t, os.WriteFile(f, []byte("# Plan"), 0o600))
+	}
+
+	assert.Equal(t, nested, Archive{}.FindCompleted(filepath.Join(tmpDir, "backend", "api.md")))
+	assert.Equal(t, sibling, Archive{}.FindCompleted(filepath.Join(tmpDir, "frontend", "ui.md")))
+	assert.Empty(t, Archive{}.FindCompleted(filepath.Join(tmpDir, "backend", "missing.md")))
+
+	assert.Equal(t, custom, Archive{Dir: "archive"}.FindCompleted(filepath.Join(tmpDir, "backend", "api.md")))
+	assert.Empty(t, Archive{Dir: "archive"}.FindCompleted(filepath.Join(tmpDir, "backend", "db.md")), "no dated layout")
+
+	dated := Archive{Dir: "archive", DateLayout: "2006/01"}
+	assert.Equal(t, datedNew, dated.FindCompleted(filepath.Join(tmpDir, "backend", "db.md")), "latest dated dir wins")
+	assert.Equal(t, custom, dated.FindCompleted(filepath.Join(tmpDir, "backend", "api.md")), "undated plan still found")
+	assert.Empty(t, Archive{Dir: "archive", DateLayout: "2006-01"}.FindCompleted(filepath.Join(tmpDir, "backend", "db.md")),
+		"layout depth differs")
+}
+
+func TestSelector_SelectMultiple(t *testing.T) {
+	colors := progress.NewColors(config.ColorConfig{
+		Task: "0,255,0", Review: "255,255,0", Codex: "255,165,0",
+		ClaudeEval: "0,255,255", Warn: "255,165,0", Error: "255,0,0",
+		Signal: "255,0,255", Timestamp: "128,128,128", Info: "255,255,255",
+	})
+
+	t.Run("returns absolute paths in given order", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		planA := filepath.Join(tmpDir, "b-second.md")
+		planB := filepath.Join(tmpDir, "a-first.md")
+		require.NoError(t, os.WriteFile(planA, []byte("# A"), 0o600))
+		require.NoError(t, os.WriteFile(planB, []byte("# B"), 0o600))
+
+		sel := NewSelector(tmpDir, colors)
+		result, err := sel.SelectMultiple(context.Background(), []string{planA, planB})
+		require.NoError(t, err)
+		assert.Equal(t, []string{planA, planB}, result)
+	})
+
+	t.Run("missing plan returns error", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		planA := filepath.Join(tmpDir, "a.md")
+		require.NoError(t, os.WriteFile(planA, []byte("# A"), 0o600))
+
+		sel := NewSelector(tmpDir, colors)
+		_, err := sel.SelectMultiple(context.Background(), []string{planA, filepath.Join(tmpDir, "missing.md")})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "plan file not found")
+	})
+
+	t.Run("no args with single plan auto-selects", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		planFile := filepath.Join(tmpDir, "only.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Only"), 0o600))
+
+		sel := NewSelector(tmpDir, colors)
+		result, err := sel.SelectMultiple(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{planFile}, result)
+	})
+
+	t.Run("no args with no plans returns error", func(t *testing.T) {
+		sel := NewSelector(t.TempDir(), colors)
+		_, err := sel.SelectMultiple(context.Background(), nil)
+		require.ErrorIs(t, err, ErrNoPlansFound)
 	})
 }
 
@@ -133,6 +387,19 @@ func TestSelector_FindRecent(t *testing.T) {
 		assert.Equal(t, newPlan, result)
 	})
 
+	t.Run("finds plan in subdirectory", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		startTime := time.Now()
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "backend"), 0o750))
+		planFile := filepath.Join(tmpDir, "backend", "api.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# API"), 0o600))
+		newTime := startTime.Add(time.Second)
+		require.NoError(t, os.Chtimes(planFile, newTime, newTime))
+
+		sel := NewSelector(tmpDir, colors)
+		assert.Equal(t, planFile, sel.FindRecent(startTime))
+	})
+
 	t.Run("returns empty if no plans after startTime", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		planFile := filepath.Join(tmpDir, "old.md")
@@ -180,11 +447,21 @@ func TestExtractBranchName(t *testing.T) {
 			planFile: "/path/to/2024-01-15-.md",
 			want:     "2024-01-15-",
 		},
+		{
+			name:     "plan in subdirectory",
+			planFile: "docs/plans/backend/2024-01-15-api.md",
+			want:     "api",
+		},
 		{
 			name:     "no extension",
 			planFile: "/path/to/feature",
 			want:     "feature",
 		},
+		{
+			name:     "native separators",
+			planFile: filepath.FromSlash("docs/plans/backend/2024-01-15-api.md"),
+			want:     "api",
+		},
 	}
 
 	for _, tt := range tests {
@@ -195,6 +472,32 @@ func TestExtractBranchName(t *testing.T) {
 	}
 }
 
+func TestSplitTitle(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantTitle string
+		wantBody  string
+	}{
+		{name: "title and body", content: "# Add auth\n\n## Overview\nAdd login.\n",
+			wantTitle: "Add auth", wantBody: "## Overview\nAdd login."},
+		{name: "front matter skipped", content: "---\nbranch: auth\n---\n\n# Add auth\ntext\n",
+			wantTitle: "Add auth", wantBody: "text"},
+		{name: "crlf line endings", content: "# Add auth\r\ntext\r\n", wantTitle: "Add auth", wantBody: "text"},
+		{name: "no title", content: "## Overview\ntext\n", wantBody: "## Overview\ntext"},
+		{name: "title not first", content: "intro\n# Add auth\n", wantBody: "intro\n# Add auth"},
+		{name: "empty", content: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			title, body := SplitTitle(tc.content)
+			assert.Equal(t, tc.wantTitle, title)
+			assert.Equal(t, tc.wantBody, body)
+		})
+	}
+}
+
 func TestPromptDescription(t *testing.T) {
 	colors := progress.NewColors(config.ColorConfig{
 		Task: "0,255,0", Review: "255,255,0", Codex: "255,165,0",
@@ -0,0 +1,83 @@
+package plan
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// untitledPlanName names a saved plan without a title heading.
+const untitledPlanName = "plan"
+
+// maxSlugLen limits the title part of a saved plan's file name.
+const maxSlugLen = 60
+
+// slugUnsafeRe matches runs of characters replaced by a dash in a plan file name.
+var slugUnsafeRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Save writes plan markdown read from r to a new file in dir and returns its path.
+// the file is named after the date and the first "# " heading, e.g. 2024-01-15-add-caching.md,
+// a name already taken gets a numeric suffix. dir is created if missing.
+func Save(r io.Reader, dir string, now time.Time) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("read plan: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return "", errors.New("plan is empty")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("create plans dir: %w", err)
+	}
+
+	base := now.Format("2006-01-02") + "-" + titleSlug(data)
+	name := base + ".md"
+	for i := 2; ; i++ {
+		path := filepath.Join(dir, name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) //nolint:gosec // path built from plans dir
+		if errors.Is(err, fs.ErrExist) {
+			name = fmt.Sprintf("%s-%d.md", base, i)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("create plan file: %w", err)
+		}
+		if _, err := f.Write(data); err != nil {
+			_ = f.Close()
+			return "", fmt.Errorf("write plan file: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return "", fmt.Errorf("close plan file: %w", err)
+		}
+		return path, nil
+	}
+}
+
+// titleSlug returns the first "# " heading of the plan reduced to lowercase words joined by dashes,
+// untitledPlanName if there is no such heading.
+func titleSlug(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		title, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "# ")
+		if !ok {
+			continue
+		}
+		slug := strings.Trim(slugUnsafeRe.ReplaceAllString(strings.ToLower(title), "-"), "-")
+		if len(slug) > maxSlugLen {
+			slug = strings.TrimRight(slug[:maxSlugLen], "-")
+		}
+		if slug != "" {
+			return slug
+		}
+		break
+	}
+	return untitledPlanName
+}
@@ -0,0 +1,56 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSave(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	dir := filepath.Join(t.TempDir(), "docs", "plans")
+	content := "# Add Caching Layer!\n\n### Task 1: cache\n- [ ] add cache\n"
+
+	path, err := Save(strings.NewReader(content), dir, now)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "2024-01-15-add-caching-layer.md"), path)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+	assert.Equal(t, "add-caching-layer", ExtractBranchName(path))
+
+	// same title on the same day gets a suffix
+	path, err = Save(strings.NewReader(content), dir, now)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "2024-01-15-add-caching-layer-2.md"), path)
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := Save(strings.NewReader(" \n\n"), dir, now)
+		require.ErrorContains(t, err, "plan is empty")
+	})
+}
+
+func TestTitleSlug(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "title", content: "# Fix Tests\n", want: "fix-tests"},
+		{name: "title after front matter", content: "some intro\n\n# Plan: Rate limits (v2)\n", want: "plan-rate-limits-v2"},
+		{name: "subheading only", content: "## Overview\ntext\n", want: "plan"},
+		{name: "symbols only", content: "# !!!\n", want: "plan"},
+		{name: "long title", content: "# " + strings.Repeat("word ", 20) + "\n",
+			want: strings.TrimRight(strings.Repeat("word-", 12), "-")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, titleSlug([]byte(tc.content)))
+		})
+	}
+}
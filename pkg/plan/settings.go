@@ -0,0 +1,103 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SettingsKeys lists the keys a plan can set in its front-matter.
+var SettingsKeys = []string{"max_iterations", "codex", "finalize", "branch", "agents"}
+
+// Settings holds execution settings declared in the YAML front-matter of a plan file.
+// nil and empty fields are not set by the plan, the flag or config value applies.
+type Settings struct {
+	MaxIterations *int     `yaml:"max_iterations"` // maximum task iterations
+	Codex         *bool    `yaml:"codex"`          // external review enabled, overrides codex_enabled
+	Finalize      *bool    `yaml:"finalize"`       // finalize step enabled, overrides finalize_enabled
+	Branch        string   `yaml:"branch"`         // branch name used as-is, overrides branch_prefix and branch_template
+	Agents        []string `yaml:"agents"`         // {{agent:name}} references expanded in review prompts, others are dropped
+}
+
+// ReadSettings reads the front-matter settings of a plan file, see ParseSettings.
+func ReadSettings(path string) (Settings, []string, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // path is the user-selected plan file
+	if err != nil {
+		return Settings{}, nil, fmt.Errorf("read plan file: %w", err)
+	}
+	return ParseSettings(string(content))
+}
+
+// ParseSettings parses the front-matter settings of plan content.
+// unknown keys don't fail, they are returned as warnings listing the valid ones.
+// malformed YAML, values of the wrong type and a non-positive max_iterations are errors.
+// content without front-matter has no settings.
+func ParseSettings(content string) (Settings, []string, error) {
+	header, _, ok := splitFrontMatter(content)
+	if !ok {
+		return Settings{}, nil, nil
+	}
+
+	var keys map[string]any
+	if err := yaml.Unmarshal([]byte(header), &keys); err != nil {
+		return Settings{}, nil, fmt.Errorf("parse plan front-matter: %w", err)
+	}
+	var warnings []string
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		if !slices.Contains(SettingsKeys, key) {
+			warnings = append(warnings, fmt.Sprintf("unknown plan front-matter key %q, valid keys: %s", key,
+				strings.Join(SettingsKeys, ", ")))
+		}
+	}
+
+	var s Settings
+	if err := yaml.Unmarshal([]byte(header), &s); err != nil {
+		return Settings{}, nil, fmt.Errorf("parse plan front-matter: %w", err)
+	}
+	if s.MaxIterations != nil && *s.MaxIterations < 1 {
+		return Settings{}, nil, errors.New("plan front-matter: max_iterations must be positive")
+	}
+	return s, warnings, nil
+}
+
+// StripFrontMatter returns plan content without its front-matter block.
+func StripFrontMatter(content string) string {
+	_, body, ok := splitFrontMatter(content)
+	if !ok {
+		return content
+	}
+	return body
+}
+
+// frontMatterLines returns the number of lines the front-matter block takes, delimiters included,
+// zero if content has no front-matter.
+func frontMatterLines(content string) int {
+	header, _, ok := splitFrontMatter(content)
+	if !ok {
+		return 0
+	}
+	return strings.Count(header, "\n") + 2
+}
+
+// splitFrontMatter splits a YAML block delimited by "---" lines off the start of content.
+// returns the YAML between the delimiters and the content after the closing one.
+func splitFrontMatter(content string) (header, body string, ok bool) {
+	after, found := strings.CutPrefix(content, "---\n")
+	if !found {
+		return "", content, false
+	}
+	if rest, isEmpty := strings.CutPrefix(after, "---"); isEmpty && (rest == "" || rest[0] == '\n') {
+		return "", strings.TrimPrefix(rest, "\n"), true
+	}
+	header, body, found = strings.Cut(after, "\n---")
+	// closing delimiter must be on its own line
+	if !found || (body != "" && body[0] != '\n') {
+		return "", content, false
+	}
+	return header + "\n", strings.TrimPrefix(body, "\n"), true
+}
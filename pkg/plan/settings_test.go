@@ -0,0 +1,83 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSettings(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	boolPtr := func(v bool) *bool { return &v }
+	tests := []struct {
+		name         string
+		content      string
+		want         Settings
+		wantWarnings []string
+		wantErr      string
+	}{
+		{name: "no front-matter", content: "# Plan\n- [ ] task\n"},
+		{name: "empty front-matter", content: "---\n---\n# Plan\n"},
+		{name: "all keys", content: "---\nmax_iterations: 20\ncodex: false\nfinalize: true\nbranch: custom-name\n" +
+			"agents: [quality, testing]\n---\n# Plan\n",
+			want: Settings{MaxIterations: intPtr(20), Codex: boolPtr(false), Finalize: boolPtr(true), Branch: "custom-name",
+				Agents: []string{"quality", "testing"}}},
+		{name: "unknown keys warn", content: "---\ncodex: true\nmodel: opus\nauthor: me\n---\n# Plan\n",
+			want: Settings{Codex: boolPtr(true)}, wantWarnings: []string{
+				`unknown plan front-matter key "author", valid keys: max_iterations, codex, finalize, branch, agents`,
+				`unknown plan front-matter key "model", valid keys: max_iterations, codex, finalize, branch, agents`,
+			}},
+		{name: "unterminated is not front-matter", content: "---\ncodex: false\n# Plan\n"},
+		{name: "malformed yaml", content: "---\ncodex: [\n---\n", wantErr: "parse plan front-matter"},
+		{name: "wrong type", content: "---\nmax_iterations: many\n---\n", wantErr: "parse plan front-matter"},
+		{name: "zero iterations", content: "---\nmax_iterations: 0\n---\n", wantErr: "max_iterations must be positive"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, warnings, err := ParseSettings(tc.content)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantWarnings, warnings)
+		})
+	}
+}
+
+func TestReadSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(path, []byte("---\nbranch: feature-x\n---\n# Plan\n"), 0o600))
+	s, warnings, err := ReadSettings(path)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "feature-x", s.Branch)
+
+	_, _, err = ReadSettings(filepath.Join(t.TempDir(), "missing.md"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestStripFrontMatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "front-matter", content: "---\ncodex: false\n---\n# Plan\n", want: "# Plan\n"},
+		{name: "empty front-matter", content: "---\n---\n# Plan\n", want: "# Plan\n"},
+		{name: "none", content: "# Plan\n---\n", want: "# Plan\n---\n"},
+		{name: "closing delimiter not on its own line", content: "---\na: 1\n---x\n", want: "---\na: 1\n---x\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, StripFrontMatter(tc.content))
+		})
+	}
+	assert.Equal(t, 4, frontMatterLines("---\na: 1\nb: 2\n---\n# Plan\n"))
+	assert.Equal(t, 2, frontMatterLines("---\n---\n"))
+	assert.Equal(t, 0, frontMatterLines("# Plan\n"))
+}
@@ -0,0 +1,134 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/git"
+)
+
+// ChangeReporter reports what the run changed in the repository, for the per-phase change summaries.
+// implemented by git.Service.
+type ChangeReporter interface {
+	HeadHash() (string, error)
+	ChangedFilesBetween(from, to string) (git.RangeChanges, error)
+	UncommittedChanges() (git.RangeChanges, error)
+}
+
+// PhaseChanges holds the commits and files a pipeline stage changed, or the whole run for the total.
+type PhaseChanges struct {
+	Stage         Stage    // empty for the run total
+	Commits       int      // commits made during the stage
+	Files         []string // paths of changed files, relative to the repository root
+	Additions     int      // lines added
+	Deletions     int      // lines deleted
+	HeadUnchanged bool     // HEAD didn't move during the stage
+	Rewritten     bool     // history was rewritten during the stage, files and lines are of uncommitted changes
+}
+
+// Format renders the changes as a single summary line, e.g.
+// "task phase: 7 commits, 23 files changed (+812/-310)".
+func (c PhaseChanges) Format() string {
+	name := "run total"
+	if c.Stage != "" {
+		name = strings.ReplaceAll(string(c.Stage), "_", "-") + " phase"
+	}
+	switch {
+	case c.HeadUnchanged:
+		return name + ": no commits, HEAD unchanged"
+	case c.Rewritten:
+		return fmt.Sprintf("%s: history rewritten (rebase or amend), %s with uncommitted changes (+%d/-%d)",
+			name, plural(len(c.Files), "file"), c.Additions, c.Deletions)
+	}
+	return fmt.Sprintf("%s: %s, %s changed (+%d/-%d)", name, plural(c.Commits, "commit"), plural(len(c.Files), "file"),
+		c.Additions, c.Deletions)
+}
+
+// plural renders a count with the noun, adding "s" unless the count is one.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// changeTracker records HEAD at the start of each stage and the changes made until the next one.
+type changeTracker struct {
+	stage  Stage  // stage being tracked, empty if none
+	start  string // HEAD at the start of the stage
+	phases []PhaseChanges
+}
+
+// SetChangeReporter sets the reporter of repository changes. when set, a summary of the commits and files
+// changed is printed at the end of each stage, see Changes.
+func (r *Runner) SetChangeReporter(c ChangeReporter) {
+	r.changeRep = c
+}
+
+// Changes returns the changes of each stage in the order stages ran, and the total of the run.
+// the total sums commits and lines of all stages, files changed by several stages are counted once.
+func (r *Runner) Changes() (phases []PhaseChanges, total PhaseChanges) {
+	for _, p := range r.changes.phases {
+		total.Commits += p.Commits
+		total.Additions += p.Additions
+		total.Deletions += p.Deletions
+		for _, f := range p.Files {
+			if !slices.Contains(total.Files, f) {
+				total.Files = append(total.Files, f)
+			}
+		}
+	}
+	return slices.Clone(r.changes.phases), total
+}
+
+// startStageChanges finishes the stage tracked so far and remembers HEAD at the start of the next one.
+func (r *Runner) startStageChanges(s Stage) {
+	r.finishStageChanges()
+	if r.changeRep == nil {
+		return
+	}
+	head, err := r.changeRep.HeadHash()
+	if err != nil {
+		r.log.Print("warning: failed to get HEAD for %s changes: %v", s, err)
+		return
+	}
+	r.changes.stage, r.changes.start = s, head
+}
+
+// finishStageChanges records and prints the changes of the tracked stage, from its start to the current HEAD.
+// when the start commit is no longer an ancestor of HEAD, e.g. after a rebase or amend, commits can't be counted
+// and the uncommitted changes are reported instead. failures are logged as warnings, summaries are informational.
+func (r *Runner) finishStageChanges() {
+	if r.changeRep == nil || r.changes.stage == "" {
+		return
+	}
+	pc := PhaseChanges{Stage: r.changes.stage}
+	start := r.changes.start
+	r.changes.stage, r.changes.start = "", ""
+
+	head, err := r.changeRep.HeadHash()
+	if err != nil {
+		r.log.Print("warning: failed to get HEAD for %s changes: %v", pc.Stage, err)
+		return
+	}
+	if head == start {
+		pc.HeadUnchanged = true
+	} else {
+		changes, err := r.changeRep.ChangedFilesBetween(start, head)
+		if errors.Is(err, git.ErrHistoryRewritten) {
+			pc.Rewritten = true
+			changes, err = r.changeRep.UncommittedChanges()
+		}
+		if err != nil {
+			r.log.Print("warning: failed to get %s changes: %v", pc.Stage, err)
+			return
+		}
+		pc.Commits, pc.Files = changes.Commits, changes.Files
+		pc.Additions, pc.Deletions = changes.Stats.Additions, changes.Stats.Deletions
+	}
+
+	r.changes.phases = append(r.changes.phases, pc)
+	r.log.Print("%s", pc.Format())
+}
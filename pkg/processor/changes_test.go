@@ -0,0 +1,134 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestPhaseChanges_Format(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes PhaseChanges
+		want    string
+	}{
+		{name: "commits", changes: PhaseChanges{Stage: StageTask, Commits: 7, Files: make([]string, 23), Additions: 812, Deletions: 310},
+			want: "task phase: 7 commits, 23 files changed (+812/-310)"},
+		{name: "single", changes: PhaseChanges{Stage: StagePostReview, Commits: 1, Files: []string{"a.go"}, Additions: 1},
+			want: "post-review phase: 1 commit, 1 file changed (+1/-0)"},
+		{name: "head unchanged", changes: PhaseChanges{Stage: StageReview, HeadUnchanged: true},
+			want: "review phase: no commits, HEAD unchanged"},
+		{name: "rewritten", changes: PhaseChanges{Stage: StageCodex, Rewritten: true, Files: []string{"a.go", "b.go"}, Additions: 4, Deletions: 2},
+			want: "codex phase: history rewritten (rebase or amend), 2 files with uncommitted changes (+4/-2)"},
+		{name: "total", changes: PhaseChanges{Commits: 3, Files: []string{"a.go"}, Additions: 5, Deletions: 1},
+			want: "run total: 3 commits, 1 file changed (+5/-1)"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.changes.Format())
+		})
+	}
+}
+
+func TestRunner_PhaseChanges(t *testing.T) {
+	// review mode: review → codex (disabled) → post-review, the first review commits
+	run := func(t *testing.T, rep *mocks.ChangeReporterMock, head *string) (*Runner, string) {
+		t.Helper()
+		calls := 0
+		claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+			calls++
+			if calls == 1 {
+				*head = "h1" // first review commits its fixes
+			}
+			return executor.Result{Output: "done", Signal: status.ReviewDone}
+		}}
+		log := newMockLogger("progress.txt")
+		cfg := Config{Mode: ModeReview, MaxIterations: 50, AppConfig: testAppConfig(t)}
+		r := NewWithExecutors(cfg, log, claude, &mocks.ExecutorMock{}, nil, &status.PhaseHolder{})
+		r.SetChangeReporter(rep)
+		require.NoError(t, r.Run(context.Background()))
+		var lines strings.Builder
+		for _, c := range log.PrintCalls() {
+			lines.WriteString(fmt.Sprintf(c.Format, c.Args...) + "\n")
+		}
+		return r, lines.String()
+	}
+
+	t.Run("commits and unchanged head", func(t *testing.T) {
+		head := "h0"
+		rep := &mocks.ChangeReporterMock{
+			HeadHashFunc: func() (string, error) { return head, nil },
+			ChangedFilesBetweenFunc: func(string, string) (git.RangeChanges, error) {
+				return git.RangeChanges{Commits: 2, Files: []string{"a.go", "b.go"},
+					Stats: git.DiffStats{Files: 2, Additions: 10, Deletions: 3}}, nil
+			},
+		}
+		r, lines := run(t, rep, &head)
+
+		require.Len(t, rep.ChangedFilesBetweenCalls(), 1)
+		assert.Equal(t, "h0", rep.ChangedFilesBetweenCalls()[0].From)
+		assert.Equal(t, "h1", rep.ChangedFilesBetweenCalls()[0].To)
+		assert.Contains(t, lines, "review phase: 2 commits, 2 files changed (+10/-3)\n")
+		assert.Contains(t, lines, "codex phase: no commits, HEAD unchanged\n")
+		assert.Contains(t, lines, "post-review phase: no commits, HEAD unchanged\n")
+
+		phases, total := r.Changes()
+		require.Len(t, phases, 3)
+		assert.Equal(t, []Stage{StageReview, StageCodex, StagePostReview}, []Stage{phases[0].Stage, phases[1].Stage, phases[2].Stage})
+		assert.Equal(t, PhaseChanges{Commits: 2, Files: []string{"a.go", "b.go"}, Additions: 10, Deletions: 3}, total)
+	})
+
+	t.Run("rewritten history falls back to uncommitted changes", func(t *testing.T) {
+		head := "h0"
+		rep := &mocks.ChangeReporterMock{
+			HeadHashFunc: func() (string, error) { return head, nil },
+			ChangedFilesBetweenFunc: func(from, to string) (git.RangeChanges, error) {
+				return git.RangeChanges{}, fmt.Errorf("changes %s..%s: %w", from, to, git.ErrHistoryRewritten)
+			},
+			UncommittedChangesFunc: func() (git.RangeChanges, error) {
+				return git.RangeChanges{Files: []string{"c.go"}, Stats: git.DiffStats{Files: 1, Additions: 4}}, nil
+			},
+		}
+		r, lines := run(t, rep, &head)
+
+		assert.Len(t, rep.UncommittedChangesCalls(), 1)
+		assert.Contains(t, lines, "review phase: history rewritten (rebase or amend), 1 file with uncommitted changes (+4/-0)\n")
+		phases, _ := r.Changes()
+		require.Len(t, phases, 3)
+		assert.True(t, phases[0].Rewritten)
+	})
+
+	t.Run("errors are warnings", func(t *testing.T) {
+		head := "h0"
+		rep := &mocks.ChangeReporterMock{
+			HeadHashFunc: func() (string, error) { return head, nil },
+			ChangedFilesBetweenFunc: func(string, string) (git.RangeChanges, error) {
+				return git.RangeChanges{}, errors.New("boom")
+			},
+		}
+		r, lines := run(t, rep, &head)
+
+		assert.Contains(t, lines, "warning: failed to get review changes: boom\n")
+		phases, _ := r.Changes()
+		assert.Len(t, phases, 2, "the failed stage is left out")
+	})
+
+	t.Run("without reporter", func(t *testing.T) {
+		r := &Runner{}
+		r.startStageChanges(StageTask)
+		r.finishStageChanges()
+		phases, total := r.Changes()
+		assert.Empty(t, phases)
+		assert.Equal(t, PhaseChanges{}, total)
+	})
+}
@@ -0,0 +1,90 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// Stage identifies a resumable step of the execution pipeline.
+// unlike status.Phase, stages distinguish the review loop before external review from the one after it.
+type Stage string
+
+// stage constants, in pipeline order.
+const (
+	StageTask       Stage = "task"        // task execution loop
+	StageReview     Stage = "review"      // first review and pre-codex review loop
+	StageCodex      Stage = "codex"       // external review loop
+	StagePostReview Stage = "post_review" // claude review loop after external review
+	StageFinalize   Stage = "finalize"    // optional finalize step
+)
+
+// stageOrder lists stages in pipeline order, used to decide which stages a resumed run skips.
+var stageOrder = []Stage{StageTask, StageReview, StageCodex, StagePostReview, StageFinalize}
+
+// Checkpoint records the progress of a run, so an interrupted run can resume
+// from the stage it was in instead of starting over.
+type Checkpoint struct {
+	PlanFile  string       `json:"plan_file"`
+	Mode      Mode         `json:"mode"`
+	Stage     Stage        `json:"stage"`
+	Phase     status.Phase `json:"phase"`
+	Iteration int          `json:"iteration"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// runTimeSuffix matches the run start time progress.NewLogger appends to progress file names
+var runTimeSuffix = regexp.MustCompile(`-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}$`)
+
+// CheckpointPath returns the checkpoint file path kept next to the given progress log.
+// the run start time is dropped from the name, so every run of the same plan and mode
+// shares one checkpoint and a new run can resume from the one before it.
+func CheckpointPath(progressPath string) string {
+	base := strings.TrimSuffix(progressPath, filepath.Ext(progressPath))
+	return runTimeSuffix.ReplaceAllString(base, "") + ".checkpoint.json"
+}
+
+// LoadCheckpoint reads the checkpoint file at path.
+func LoadCheckpoint(path string) (Checkpoint, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path derived from progress log path
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("read checkpoint: %w", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return Checkpoint{}, fmt.Errorf("parse checkpoint %s: %w", path, err)
+	}
+	if !slices.Contains(stageOrder, cp.Stage) {
+		return Checkpoint{}, fmt.Errorf("parse checkpoint %s: unknown stage %q", path, cp.Stage)
+	}
+	return cp, nil
+}
+
+// saveCheckpoint writes the checkpoint atomically (temp file + rename),
+// so an interrupted write never leaves a truncated checkpoint behind.
+func saveCheckpoint(path string, cp Checkpoint) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("rename checkpoint: %w", err)
+	}
+	return nil
+}
+
+// stageIndex returns the position of the stage in the pipeline, -1 for unknown stages.
+func stageIndex(s Stage) int {
+	return slices.Index(stageOrder, s)
+}
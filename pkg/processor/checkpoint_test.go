@@ -0,0 +1,69 @@
+package processor_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestCheckpointPath(t *testing.T) {
+	tests := []struct {
+		progress string
+		want     string
+	}{
+		{progress: ".ralphex/progress/progress-feature.txt", want: ".ralphex/progress/progress-feature.checkpoint.json"},
+		{progress: "/tmp/progress-review", want: "/tmp/progress-review.checkpoint.json"},
+		{progress: ".ralphex/progress/progress-feature-2024-06-01T15-04-05.txt", want: ".ralphex/progress/progress-feature.checkpoint.json"},
+		{progress: ".ralphex/progress/progress-2024-06-01-fix-review-2024-06-01T15-04-05.txt",
+			want: ".ralphex/progress/progress-2024-06-01-fix-review.checkpoint.json"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.progress, func(t *testing.T) {
+			assert.Equal(t, tc.want, processor.CheckpointPath(tc.progress))
+		})
+	}
+}
+
+func TestLoadCheckpoint(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cp.json")
+		data := `{"plan_file":"docs/plans/a.md","mode":"full","stage":"post_review","phase":"review","iteration":2}`
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+		cp, err := processor.LoadCheckpoint(path)
+		require.NoError(t, err)
+		assert.Equal(t, "docs/plans/a.md", cp.PlanFile)
+		assert.Equal(t, processor.ModeFull, cp.Mode)
+		assert.Equal(t, processor.StagePostReview, cp.Stage)
+		assert.Equal(t, status.PhaseReview, cp.Phase)
+		assert.Equal(t, 2, cp.Iteration)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := processor.LoadCheckpoint(filepath.Join(t.TempDir(), "missing.json"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cp.json")
+		require.NoError(t, os.WriteFile(path, []byte("{broken"), 0o600))
+		_, err := processor.LoadCheckpoint(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "parse checkpoint")
+	})
+
+	t.Run("unknown stage", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cp.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"stage":"bogus"}`), 0o600))
+		_, err := processor.LoadCheckpoint(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown stage")
+	})
+}
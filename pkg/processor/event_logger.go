@@ -0,0 +1,253 @@
+package processor
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// signalNameRe matches <<<RALPHEX:NAME>>> signals in executor output, capturing the name
+var signalNameRe = regexp.MustCompile(`<<<RALPHEX:([A-Z_]+)>>>`)
+
+// event names written by EventLogger
+const (
+	EventRunStart       = "run_start"
+	EventRunEnd         = "run_end"
+	EventPhaseStart     = "phase_start"
+	EventPhaseEnd       = "phase_end"
+	EventIterationStart = "iteration_start"
+	EventIterationEnd   = "iteration_end"
+	EventSection        = "section"
+	EventSignal         = "signal"
+	EventError          = "error"
+)
+
+// Event is a single structured event, written as one JSON object per line.
+type Event struct {
+	Time       time.Time    `json:"time"`
+	Event      string       `json:"event"`
+	Phase      status.Phase `json:"phase,omitempty"`
+	Section    string       `json:"section,omitempty"`
+	Iteration  int          `json:"iteration,omitempty"`
+	Signal     string       `json:"signal,omitempty"`
+	DurationMs int64        `json:"duration_ms,omitempty"`
+	Plan       string       `json:"plan,omitempty"`
+	Mode       string       `json:"mode,omitempty"`
+	Branch     string       `json:"branch,omitempty"`
+	Status     string       `json:"status,omitempty"`   // run_end only: success or failure
+	Error      string       `json:"error,omitempty"`    // error only
+	Pattern    string       `json:"pattern,omitempty"`  // error only, the matched error pattern
+	HelpCmd    string       `json:"help_cmd,omitempty"` // error only, command suggested for the matched pattern
+
+	Usage      []EventUsage `json:"usage,omitempty"`       // run_end only: token usage per phase, if a usage source is set
+	UsageTotal *EventUsage  `json:"usage_total,omitempty"` // run_end only: token usage of the whole run
+}
+
+// EventUsage is the token usage of a phase or the whole run in the run_end event.
+// token counts are null when no call reported them, cost is omitted without token prices.
+type EventUsage struct {
+	Phase        status.Phase `json:"phase,omitempty"`
+	InputTokens  *int         `json:"input_tokens"`
+	OutputTokens *int         `json:"output_tokens"`
+	Cost         *float64     `json:"cost,omitempty"` // estimated, in the currency of the configured prices
+}
+
+// usageSource provides token usage collected during a run, implemented by Runner.
+type usageSource interface {
+	Usage() (phases []Usage, total Usage)
+}
+
+// EventRunInfo describes the run recorded in the run_start event.
+type EventRunInfo struct {
+	Plan   string
+	Mode   Mode
+	Branch string
+}
+
+// EventLogger wraps a Logger and writes structured run events as newline-delimited JSON:
+// phase transitions, iteration start and end with durations, detected signals and the run result.
+// all calls are forwarded to the inner logger, so the runner doesn't know about it.
+//
+// Thread safety: EventLogger is NOT goroutine-safe, like BroadcastLogger in the web package.
+type EventLogger struct {
+	inner  Logger
+	enc    *json.Encoder
+	holder *status.PhaseHolder
+	now    func() time.Time
+
+	usage      usageSource
+	perMInput  float64 // price of a million input tokens for the usage cost
+	perMOutput float64 // price of a million output tokens for the usage cost
+
+	runStart   time.Time
+	phaseStart time.Time
+	iteration  *Event // open iteration, ended by the next section, phase change or Finish
+	finished   bool
+}
+
+// NewEventLogger creates a logger writing events to w and forwarding everything to inner.
+// registers an OnChange callback on the holder for phase events and writes the run_start event.
+func NewEventLogger(inner Logger, w io.Writer, holder *status.PhaseHolder, info EventRunInfo) *EventLogger {
+	e := &EventLogger{inner: inner, enc: json.NewEncoder(w), holder: holder, now: time.Now}
+	e.runStart = e.now()
+	e.phaseStart = e.runStart
+	e.write(Event{Event: EventRunStart, Plan: info.Plan, Mode: string(info.Mode), Branch: info.Branch})
+	if phase := holder.Get(); phase != "" {
+		e.write(Event{Event: EventPhaseStart, Phase: phase})
+	}
+	holder.OnChange(e.onPhaseChanged)
+	return e
+}
+
+// SetUsage sets the source of token usage recorded in the run_end event.
+// prices are per million input and output tokens, zero prices omit the estimated cost.
+func (e *EventLogger) SetUsage(src usageSource, perMInput, perMOutput float64) {
+	e.usage, e.perMInput, e.perMOutput = src, perMInput, perMOutput
+}
+
+// onPhaseChanged closes the open iteration and the old phase, then starts the new phase.
+func (e *EventLogger) onPhaseChanged(old, cur status.Phase) {
+	if e.finished {
+		return
+	}
+	e.endIteration()
+	now := e.now()
+	if old != "" {
+		e.write(Event{Event: EventPhaseEnd, Phase: old, DurationMs: now.Sub(e.phaseStart).Milliseconds()})
+	}
+	e.phaseStart = now
+	e.write(Event{Event: EventPhaseStart, Phase: cur})
+}
+
+// Print forwards to the inner logger.
+func (e *EventLogger) Print(format string, args ...any) { e.inner.Print(format, args...) }
+
+// PrintRaw forwards to the inner logger.
+func (e *EventLogger) PrintRaw(format string, args ...any) { e.inner.PrintRaw(format, args...) }
+
+// PrintSection forwards to the inner logger and records the section.
+// sections with an iteration number start an iteration, any section ends the previous one.
+func (e *EventLogger) PrintSection(section status.Section) {
+	e.inner.PrintSection(section)
+	e.endIteration()
+	if section.Iteration == 0 {
+		e.write(Event{Event: EventSection, Section: section.Label})
+		return
+	}
+	e.iteration = &Event{Time: e.now(), Phase: e.holder.Get(), Section: section.Label, Iteration: section.Iteration}
+	e.write(Event{Event: EventIterationStart, Section: section.Label, Iteration: section.Iteration})
+}
+
+// PrintAligned forwards to the inner logger and records signals found in the text.
+func (e *EventLogger) PrintAligned(text string) {
+	e.inner.PrintAligned(text)
+	for _, m := range signalNameRe.FindAllStringSubmatch(text, -1) {
+		if m[1] == "END" {
+			continue // closes question and draft payloads, not a signal
+		}
+		e.write(Event{Event: EventSignal, Signal: m[1]})
+	}
+}
+
+// PrintTool forwards to the inner logger.
+func (e *EventLogger) PrintTool(text string) { e.inner.PrintTool(text) }
+
+// LogQuestion forwards to the inner logger.
+func (e *EventLogger) LogQuestion(question string, options []string) {
+	e.inner.LogQuestion(question, options)
+}
+
+// LogAnswer forwards to the inner logger.
+func (e *EventLogger) LogAnswer(answer string) { e.inner.LogAnswer(answer) }
+
+// LogDraftReview forwards to the inner logger.
+func (e *EventLogger) LogDraftReview(action, feedback string) {
+	e.inner.LogDraftReview(action, feedback)
+}
+
+// Path returns the progress file path of the inner logger.
+func (e *EventLogger) Path() string { return e.inner.Path() }
+
+// Finish ends the open iteration and phase and writes the run result.
+// a run error is recorded as an error event, with pattern details for executor.PatternMatchError.
+// events are not written after Finish.
+func (e *EventLogger) Finish(runErr error) {
+	if e.finished {
+		return
+	}
+	e.endIteration()
+	now := e.now()
+	if phase := e.holder.Get(); phase != "" {
+		e.write(Event{Event: EventPhaseEnd, Phase: phase, DurationMs: now.Sub(e.phaseStart).Milliseconds()})
+	}
+
+	result := "success"
+	if runErr != nil {
+		result = "failure"
+		ev := Event{Event: EventError, Error: runErr.Error()}
+		var patternErr *executor.PatternMatchError
+		if errors.As(runErr, &patternErr) {
+			ev.Pattern, ev.HelpCmd = patternErr.Pattern, patternErr.HelpCmd
+		}
+		e.write(ev)
+	}
+	end := Event{Event: EventRunEnd, Status: result, DurationMs: now.Sub(e.runStart).Milliseconds()}
+	if e.usage != nil {
+		phases, total := e.usage.Usage()
+		for _, u := range phases {
+			end.Usage = append(end.Usage, e.eventUsage(u))
+		}
+		t := e.eventUsage(total)
+		end.UsageTotal = &t
+	}
+	e.write(end)
+	e.finished = true
+}
+
+// eventUsage converts usage for the run_end event, without token counts if none were reported.
+func (e *EventLogger) eventUsage(u Usage) EventUsage {
+	res := EventUsage{Phase: u.Phase}
+	if !u.Available() {
+		return res
+	}
+	res.InputTokens, res.OutputTokens = &u.InputTokens, &u.OutputTokens
+	if e.perMInput > 0 || e.perMOutput > 0 {
+		cost := u.Cost(e.perMInput, e.perMOutput)
+		res.Cost = &cost
+	}
+	return res
+}
+
+// endIteration writes iteration_end for the open iteration, if any.
+func (e *EventLogger) endIteration() {
+	if e.iteration == nil {
+		return
+	}
+	it := e.iteration
+	e.iteration = nil
+	e.write(Event{Event: EventIterationEnd, Phase: it.Phase, Section: it.Section, Iteration: it.Iteration,
+		DurationMs: e.now().Sub(it.Time).Milliseconds()})
+}
+
+// write encodes the event as a JSON line. the phase defaults to the current one.
+// errors are logged but not propagated since the text progress log is the primary output.
+func (e *EventLogger) write(ev Event) {
+	if e.finished {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = e.now()
+	}
+	if ev.Phase == "" && ev.Event != EventRunStart && ev.Event != EventRunEnd {
+		ev.Phase = e.holder.Get()
+	}
+	if err := e.enc.Encode(ev); err != nil {
+		log.Printf("[WARN] failed to write %s event: %v", ev.Event, err)
+	}
+}
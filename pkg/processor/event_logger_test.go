@@ -0,0 +1,181 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// readEvents parses newline-delimited JSON events, failing on any invalid line.
+func readEvents(t *testing.T, data []byte) []Event {
+	t.Helper()
+	var events []Event
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var ev Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev), "invalid json line: %s", scanner.Text())
+		events = append(events, ev)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestEventLogger(t *testing.T) {
+	start := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	clock := start
+	holder := &status.PhaseHolder{}
+	inner := newMockLogger("progress.txt")
+	var buf bytes.Buffer
+
+	e := NewEventLogger(inner, &buf, holder, EventRunInfo{Plan: "docs/plans/feature.md", Mode: ModeFull, Branch: "feature"})
+	e.now = func() time.Time { return clock }
+	e.runStart, e.phaseStart = start, start
+
+	holder.Set(status.PhaseTask)
+	e.PrintSection(status.NewTaskIterationSection(1))
+	clock = clock.Add(5 * time.Second)
+	e.PrintAligned("all done\n<<<RALPHEX:ALL_TASKS_DONE>>>\n")
+	e.Print("task %d completed", 1)
+	clock = clock.Add(time.Second)
+	holder.Set(status.PhaseReview)
+	e.PrintSection(status.NewGenericSection("pre-review hook"))
+	e.PrintSection(status.NewClaudeReviewSection(1, ": all findings"))
+	clock = clock.Add(3 * time.Second)
+	e.Finish(fmt.Errorf("claude execution: %w", &executor.PatternMatchError{Pattern: "You've hit your limit", HelpCmd: "claude /usage"}))
+
+	// nothing is written after finish
+	e.PrintSection(status.NewClaudeReviewSection(2, ""))
+	holder.Set(status.PhaseFinalize)
+
+	type row struct {
+		event     string
+		phase     status.Phase
+		iteration int
+		duration  time.Duration
+	}
+	events := readEvents(t, buf.Bytes())
+	got := make([]row, 0, len(events))
+	for _, ev := range events {
+		got = append(got, row{ev.Event, ev.Phase, ev.Iteration, time.Duration(ev.DurationMs) * time.Millisecond})
+	}
+	assert.Equal(t, []row{
+		{EventRunStart, "", 0, 0},
+		{EventPhaseStart, status.PhaseTask, 0, 0},
+		{EventIterationStart, status.PhaseTask, 1, 0},
+		{EventSignal, status.PhaseTask, 0, 0},
+		{EventIterationEnd, status.PhaseTask, 1, 6 * time.Second},
+		{EventPhaseEnd, status.PhaseTask, 0, 6 * time.Second},
+		{EventPhaseStart, status.PhaseReview, 0, 0},
+		{EventSection, status.PhaseReview, 0, 0},
+		{EventIterationStart, status.PhaseReview, 1, 0},
+		{EventIterationEnd, status.PhaseReview, 1, 3 * time.Second},
+		{EventPhaseEnd, status.PhaseReview, 0, 3 * time.Second},
+		{EventError, status.PhaseReview, 0, 0},
+		{EventRunEnd, "", 0, 9 * time.Second},
+	}, got)
+
+	assert.Equal(t, "docs/plans/feature.md", events[0].Plan)
+	assert.Equal(t, "full", events[0].Mode)
+	assert.Equal(t, "feature", events[0].Branch)
+	assert.Equal(t, "ALL_TASKS_DONE", events[3].Signal)
+	assert.Equal(t, "task iteration 1", events[4].Section)
+	assert.Equal(t, "pre-review hook", events[7].Section)
+	assert.Equal(t, "claude execution: detected error pattern: \"You've hit your limit\"", events[11].Error)
+	assert.Equal(t, "You've hit your limit", events[11].Pattern)
+	assert.Equal(t, "claude /usage", events[11].HelpCmd)
+	assert.Equal(t, "failure", events[12].Status)
+	assert.Equal(t, start.Add(9*time.Second), events[12].Time)
+
+	// everything is forwarded to the inner logger
+	assert.Len(t, inner.PrintSectionCalls(), 4)
+	assert.Len(t, inner.PrintAlignedCalls(), 1)
+	assert.Len(t, inner.PrintCalls(), 1)
+	assert.Equal(t, "progress.txt", e.Path())
+}
+
+func TestEventLogger_Success(t *testing.T) {
+	holder := &status.PhaseHolder{}
+	holder.Set(status.PhaseReview)
+	var buf bytes.Buffer
+
+	e := NewEventLogger(newMockLogger("progress.txt"), &buf, holder, EventRunInfo{Mode: ModeReview})
+	e.PrintAligned("<<<RALPHEX:QUESTION>>>{}<<<RALPHEX:END>>> <<<RALPHEX:REVIEW_DONE>>>")
+	e.Finish(nil)
+	e.Finish(nil) // second finish is a no-op
+
+	events := readEvents(t, buf.Bytes())
+	names := make([]string, 0, len(events))
+	for _, ev := range events {
+		names = append(names, ev.Event)
+	}
+	assert.Equal(t, []string{EventRunStart, EventPhaseStart, EventSignal, EventSignal, EventPhaseEnd, EventRunEnd}, names)
+	assert.Equal(t, "QUESTION", events[2].Signal)
+	assert.Equal(t, "REVIEW_DONE", events[3].Signal)
+	assert.Equal(t, "success", events[5].Status)
+	assert.Empty(t, events[5].Error)
+}
+
+// stubUsage is a usageSource returning fixed usage.
+type stubUsage struct {
+	phases []Usage
+	total  Usage
+}
+
+func (s stubUsage) Usage() (phases []Usage, total Usage) { return s.phases, s.total }
+
+func TestEventLogger_Usage(t *testing.T) {
+	src := stubUsage{
+		phases: []Usage{
+			{Phase: status.PhaseTask, InputTokens: 2_000_000, OutputTokens: 100_000, Calls: 2, Reported: 2},
+			{Phase: status.PhaseCodex, Calls: 1},
+		},
+		total: Usage{InputTokens: 2_000_000, OutputTokens: 100_000, Calls: 3, Reported: 2},
+	}
+
+	t.Run("with prices", func(t *testing.T) {
+		var buf bytes.Buffer
+		e := NewEventLogger(newMockLogger("progress.txt"), &buf, &status.PhaseHolder{}, EventRunInfo{Mode: ModeFull})
+		e.SetUsage(src, 3, 15)
+		e.Finish(nil)
+
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		last := string(lines[len(lines)-1])
+		assert.Contains(t, last, `"usage":[{"phase":"task","input_tokens":2000000,"output_tokens":100000,"cost":7.5},`+
+			`{"phase":"codex","input_tokens":null,"output_tokens":null}]`)
+		assert.Contains(t, last, `"usage_total":{"input_tokens":2000000,"output_tokens":100000,"cost":7.5}`)
+	})
+
+	t.Run("without prices", func(t *testing.T) {
+		var buf bytes.Buffer
+		e := NewEventLogger(newMockLogger("progress.txt"), &buf, &status.PhaseHolder{}, EventRunInfo{Mode: ModeFull})
+		e.SetUsage(src, 0, 0)
+		e.Finish(nil)
+
+		events := readEvents(t, buf.Bytes())
+		end := events[len(events)-1]
+		require.Equal(t, EventRunEnd, end.Event)
+		require.Len(t, end.Usage, 2)
+		require.NotNil(t, end.Usage[0].InputTokens)
+		assert.Equal(t, 2_000_000, *end.Usage[0].InputTokens)
+		assert.Nil(t, end.Usage[0].Cost)
+		assert.Nil(t, end.Usage[1].InputTokens, "codex reported no tokens")
+		require.NotNil(t, end.UsageTotal)
+		assert.Equal(t, 100_000, *end.UsageTotal.OutputTokens)
+	})
+
+	t.Run("no usage source", func(t *testing.T) {
+		var buf bytes.Buffer
+		e := NewEventLogger(newMockLogger("progress.txt"), &buf, &status.PhaseHolder{}, EventRunInfo{Mode: ModeFull})
+		e.Finish(nil)
+		assert.NotContains(t, buf.String(), "usage")
+	})
+}
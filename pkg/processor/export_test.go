@@ -5,15 +5,21 @@ import "time"
 // TestRunnerConfig provides test access to runner's internal configuration.
 // this file is only compiled during test builds (`go test`).
 type TestRunnerConfig struct {
-	IterationDelay time.Duration
-	TaskRetryCount int
+	IterationDelay  time.Duration
+	TaskRetryCount  int
+	CodexRetryCount int
+	ClaudeTimeout   time.Duration
+	ReviewTimeout   time.Duration
 }
 
 // TestConfig returns internal configuration values for testing.
 func (r *Runner) TestConfig() TestRunnerConfig {
 	return TestRunnerConfig{
-		IterationDelay: r.iterationDelay,
-		TaskRetryCount: r.taskRetryCount,
+		IterationDelay:  r.iterationDelay,
+		TaskRetryCount:  r.taskRetryCount,
+		CodexRetryCount: r.codexRetryCount,
+		ClaudeTimeout:   r.claudeTimeout,
+		ReviewTimeout:   r.reviewTimeout,
 	}
 }
 
@@ -26,3 +32,8 @@ func (r *Runner) TestHasUncompletedTasks() bool {
 func (r *Runner) TestBuildCodexPrompt(isFirst bool, claudeResponse string) string {
 	return r.buildCodexPrompt(isFirst, claudeResponse)
 }
+
+// TestSetGemini sets the gemini reviewer used when external_review_tool = gemini.
+func (r *Runner) TestSetGemini(e Executor) {
+	r.gemini = e
+}
@@ -0,0 +1,130 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// defaultFinalizeIterations is used when finalize_max_iterations is not set
+const defaultFinalizeIterations = 3
+
+// maxFinalizeOutput caps the output of a failing finalize command passed to the fix prompt
+const maxFinalizeOutput = 16 * 1024
+
+// ErrFinalizeChecksFailed is returned with finalize_strict when finalize commands still fail
+// after finalize_max_iterations fix attempts.
+var ErrFinalizeChecksFailed = errors.New("finalize checks failed")
+
+// runFinalizeCommands runs finalize_commands and, while any of them fails, asks claude to fix the failures
+// and runs them again, up to finalize_max_iterations times. claude is not called if all commands pass.
+// failures left after the last attempt are logged and don't fail the run unless finalize_strict is set.
+func (r *Runner) runFinalizeCommands(ctx context.Context) error {
+	failures, err := r.runFinalizeChecks(ctx)
+	if err != nil {
+		return err
+	}
+	if failures == "" {
+		r.log.Print("finalize: all checks passed")
+		return nil
+	}
+
+	iterations := r.cfg.AppConfig.FinalizeIterations
+	if iterations <= 0 {
+		iterations = defaultFinalizeIterations
+	}
+	for i := 1; i <= iterations; i++ {
+		if err := r.checkStop(); err != nil {
+			return err
+		}
+		r.log.Print("finalize: checks failed, fix attempt %d/%d", i, iterations)
+		prompt := r.replacePromptVariables(r.cfg.AppConfig.FinalizeFixPrompt)
+		prompt = strings.ReplaceAll(prompt, "{{FINALIZE_FAILURES}}", failures)
+		result := r.runExecutor(ctx, r.claude.Run, prompt)
+		if result.Error != nil {
+			if errors.Is(result.Error, context.Canceled) || errors.Is(result.Error, context.DeadlineExceeded) {
+				return fmt.Errorf("finalize step: %w", result.Error)
+			}
+			if r.handlePatternMatchError(result.Error, r.agentName()) != nil {
+				return nil //nolint:nilerr // intentional: best-effort semantics, log but don't propagate
+			}
+			r.log.Print("finalize fix failed: %v", result.Error)
+		}
+		if result.Signal == SignalFailed {
+			r.log.Print("finalize fix reported failure")
+		}
+
+		if failures, err = r.runFinalizeChecks(ctx); err != nil {
+			return err
+		}
+		if failures == "" {
+			r.log.Print("finalize: all checks passed after %d fix attempt(s)", i)
+			return nil
+		}
+	}
+
+	if r.cfg.AppConfig.FinalizeStrict {
+		return fmt.Errorf("finalize step: %w after %d fix attempts", ErrFinalizeChecksFailed, iterations)
+	}
+	r.log.Print("finalize: checks still failing after %d fix attempts (non-blocking)", iterations)
+	return nil
+}
+
+// runFinalizeChecks runs all finalize commands in order, streaming their output to the log.
+// returns a report of the failed commands, empty if all of them passed.
+func (r *Runner) runFinalizeChecks(ctx context.Context) (string, error) {
+	var report strings.Builder
+	for _, command := range r.cfg.AppConfig.FinalizeCommands {
+		r.log.Print("finalize: $ %s", command)
+		output, code, err := r.runFinalizeCommand(ctx, command)
+		if err != nil {
+			return "", err
+		}
+		if code == 0 {
+			continue
+		}
+		r.log.Print("finalize: %q exited with code %d", command, code)
+		if len(output) > maxFinalizeOutput {
+			output = "...\n" + output[len(output)-maxFinalizeOutput:]
+		}
+		fmt.Fprintf(&report, "$ %s\nexit code: %d\n```\n%s\n```\n\n", command, code, strings.TrimRight(output, "\n"))
+	}
+	return strings.TrimSuffix(report.String(), "\n"), nil
+}
+
+// runFinalizeCommand runs a single command in the shell and returns its combined output and exit code.
+// an error is returned only if the command can't be run or the context is canceled.
+func (r *Runner) runFinalizeCommand(ctx context.Context, command string) (string, int, error) {
+	var captured strings.Builder
+	out := &hookOutput{log: r.log}
+	cmd := shellCommand(ctx, command)
+	cmd.Stdout = io.MultiWriter(out, &captured)
+	cmd.Stderr = cmd.Stdout // same writer, so stdout and stderr lines are not interleaved mid-line
+	cmd.WaitDelay = hookWaitDelay
+
+	err := cmd.Run()
+	out.flush()
+	if err == nil || errors.Is(err, exec.ErrWaitDelay) {
+		return captured.String(), 0, nil
+	}
+	if ctx.Err() != nil {
+		return "", 0, fmt.Errorf("finalize step: %w", ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return captured.String(), exitErr.ExitCode(), nil
+	}
+	return "", 0, fmt.Errorf("run finalize command %q: %w", command, err)
+}
+
+// shellCommand returns a command running the given command line in the platform shell.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
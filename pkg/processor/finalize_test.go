@@ -0,0 +1,110 @@
+package processor_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestRunner_FinalizeCommands(t *testing.T) {
+	const reviews = 3 // first review, pre-codex and post-codex review loops (codex disabled)
+
+	tests := []struct {
+		name       string
+		commands   []string // %MARKER% is replaced with a file created by the fix call
+		fixes      int      // fix calls creating the marker, 0 never creates it
+		strict     bool
+		wantErr    error
+		wantFixes  int
+		wantOutput []string
+		wantPrompt []string
+	}{
+		{name: "all checks pass, claude not called", commands: []string{"echo checking", "true"},
+			wantOutput: []string{"checking\n", "finalize: all checks passed"}},
+		{name: "failure fixed on the second attempt", commands: []string{"true", "echo missing >&2; test -f %MARKER%"},
+			fixes: 2, wantFixes: 2,
+			wantOutput: []string{"fix attempt 1/3", "fix attempt 2/3", "all checks passed after 2 fix attempt(s)"},
+			wantPrompt: []string{"$ echo missing >&2; test -f", "exit code: 1", "missing"}},
+		{name: "failures left are not blocking", commands: []string{"echo broken; exit 3"}, wantFixes: 3,
+			wantOutput: []string{"checks still failing after 3 fix attempts (non-blocking)"},
+			wantPrompt: []string{"exit code: 3", "broken"}},
+		{name: "failures left fail the strict run", commands: []string{"exit 1"}, strict: true, wantFixes: 3,
+			wantErr: processor.ErrFinalizeChecksFailed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			marker := filepath.Join(t.TempDir(), "fixed")
+			calls := 0
+			claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+				calls++
+				if calls <= reviews {
+					return executor.Result{Output: "review done", Signal: status.ReviewDone}
+				}
+				if calls-reviews == tc.fixes {
+					require.NoError(t, os.WriteFile(marker, nil, 0o600))
+				}
+				return executor.Result{Output: "fixed"}
+			}}
+			appCfg := testAppConfig(t)
+			appCfg.FinalizeCommands = nil
+			for _, c := range tc.commands {
+				appCfg.FinalizeCommands = append(appCfg.FinalizeCommands, strings.ReplaceAll(c, "%MARKER%", marker))
+			}
+			appCfg.FinalizeIterations = 3
+			appCfg.FinalizeStrict = tc.strict
+
+			log := newMockLogger("progress.txt")
+			cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, FinalizeEnabled: true, AppConfig: appCfg}
+			r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+			err := r.Run(context.Background())
+
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, claude.RunCalls(), reviews+tc.wantFixes)
+			output := printedLines(log)
+			for _, want := range tc.wantOutput {
+				assert.Contains(t, output, want)
+			}
+			for _, want := range tc.wantPrompt {
+				assert.Contains(t, claude.RunCalls()[reviews].Prompt, want)
+			}
+			if tc.wantFixes > 0 {
+				assert.NotContains(t, claude.RunCalls()[reviews].Prompt, "{{FINALIZE_FAILURES}}")
+			}
+		})
+	}
+}
+
+func TestRunner_FinalizeCommands_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	claude := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+		return executor.Result{Output: "review done", Signal: status.ReviewDone}
+	}}
+	appCfg := testAppConfig(t)
+	appCfg.FinalizeCommands = []string{"sleep 10"}
+
+	log := newMockLogger("progress.txt")
+	log.PrintFunc = func(format string, _ ...any) {
+		if strings.HasPrefix(format, "finalize: $") {
+			cancel()
+		}
+	}
+	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, FinalizeEnabled: true, AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	err := r.Run(ctx)
+
+	require.ErrorIs(t, err, context.Canceled)
+}
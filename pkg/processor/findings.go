@@ -0,0 +1,157 @@
+package processor
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+// severities in ascending order, valid values of codex_min_severity
+var severities = []string{"low", "medium", "high", "critical"}
+
+// codex priority tags, P0 is the most severe
+var priorityToSeverity = map[string]string{"p0": "critical", "p1": "high", "p2": "medium", "p3": "low"}
+
+var (
+	// findingItemRe matches a top-level list item (at most one leading space), capturing its text
+	findingItemRe = regexp.MustCompile(`^ ?(?:[-*•]|\d+[.)])\s+(.+)$`)
+	// findingSeverityRe matches a severity tag at the start of a finding: [high], high:, [P1], **medium**
+	findingSeverityRe = regexp.MustCompile(`(?i)^[\[(*\s]*(critical|high|medium|low|p[0-3])\b`)
+	// findingSeverityFieldRe matches an explicit "severity: x" anywhere in a finding
+	findingSeverityFieldRe = regexp.MustCompile(`(?i)\bseverity\W{0,3}(critical|high|medium|low)\b`)
+)
+
+// finding is a single item of external review output.
+type finding struct {
+	text     string // item text including continuation lines, without the list marker
+	severity string // one of severities, empty if not tagged
+}
+
+// findingFilter drops external review findings matching ignore patterns or below the minimum severity.
+type findingFilter struct {
+	ignore      []*regexp.Regexp
+	minSeverity string // empty keeps findings of any severity
+}
+
+// parseFindings splits review output into list items, continuation lines and code blocks belong to the previous item.
+// returns nil if the output has no list items, such output can't be filtered.
+func parseFindings(output string) []finding {
+	var findings []finding
+	inCode := false
+	for line := range strings.SplitSeq(output, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		if m := findingItemRe.FindStringSubmatch(line); m != nil && !inCode {
+			findings = append(findings, finding{text: strings.TrimSpace(m[1])})
+			continue
+		}
+		if len(findings) > 0 && strings.TrimSpace(line) != "" {
+			last := &findings[len(findings)-1]
+			last.text += "\n" + strings.TrimSpace(line)
+		}
+	}
+	for i := range findings {
+		findings[i].severity = findingSeverity(findings[i].text)
+	}
+	return findings
+}
+
+// findingSeverity returns the tagged severity of a finding, empty if it has none.
+func findingSeverity(text string) string {
+	m := findingSeverityRe.FindStringSubmatch(text)
+	if m == nil {
+		m = findingSeverityFieldRe.FindStringSubmatch(text)
+	}
+	if m == nil {
+		return ""
+	}
+	sev := strings.ToLower(m[1])
+	if s, ok := priorityToSeverity[sev]; ok {
+		return s
+	}
+	return sev
+}
+
+// apply splits findings into kept and suppressed. untagged findings are never dropped by severity.
+func (f findingFilter) apply(findings []finding) (kept, suppressed []finding) {
+	minIdx := slices.Index(severities, f.minSeverity)
+	for _, fd := range findings {
+		if f.ignored(fd) || (minIdx > 0 && fd.severity != "" && slices.Index(severities, fd.severity) < minIdx) {
+			suppressed = append(suppressed, fd)
+			continue
+		}
+		kept = append(kept, fd)
+	}
+	return kept, suppressed
+}
+
+// ignored returns true if the finding matches any ignore pattern.
+func (f findingFilter) ignored(fd finding) bool {
+	for _, re := range f.ignore {
+		if re.MatchString(fd.text) {
+			return true
+		}
+	}
+	return false
+}
+
+// active returns true if the filter can drop anything.
+func (f findingFilter) active() bool {
+	return len(f.ignore) > 0 || slices.Index(severities, f.minSeverity) > 0
+}
+
+// formatFindings renders findings back as a list for the evaluation prompt.
+func formatFindings(findings []finding) string {
+	lines := make([]string, 0, len(findings))
+	for _, fd := range findings {
+		lines = append(lines, "- "+strings.ReplaceAll(fd.text, "\n", "\n  "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newCodexFilter builds the codex findings filter from config.
+// invalid patterns are rejected by config loading, here they are skipped with a warning.
+func newCodexFilter(appCfg *config.Config, log Logger) findingFilter {
+	if appCfg == nil {
+		return findingFilter{}
+	}
+	f := findingFilter{minSeverity: appCfg.CodexMinSeverity}
+	if f.minSeverity != "" && !slices.Contains(severities, f.minSeverity) {
+		log.Print("warning: unknown codex_min_severity %q, findings are not filtered by severity", f.minSeverity)
+		f.minSeverity = ""
+	}
+	for _, p := range appCfg.CodexIgnorePatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			log.Print("warning: invalid codex_ignore_patterns entry %q: %v", p, err)
+			continue
+		}
+		f.ignore = append(f.ignore, re)
+	}
+	return f
+}
+
+// filterCodexFindings drops codex findings matching codex_ignore_patterns or below codex_min_severity.
+// dropped findings are logged, the rest is returned as a list for claude evaluation.
+// output without list items is returned unchanged.
+func (r *Runner) filterCodexFindings(output string) (kept string, allDropped bool) {
+	if !r.codexFilter.active() {
+		return output, false
+	}
+	findings := parseFindings(output)
+	keptFindings, suppressed := r.codexFilter.apply(findings)
+	if len(suppressed) == 0 {
+		return output, false
+	}
+	r.log.Print("suppressed %d of %d codex findings:", len(suppressed), len(findings))
+	for _, fd := range suppressed {
+		r.log.Print("  - %s", strings.ReplaceAll(fd.text, "\n", " "))
+	}
+	if len(keptFindings) == 0 {
+		return "", true
+	}
+	return formatFindings(keptFindings), false
+}
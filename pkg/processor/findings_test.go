@@ -0,0 +1,100 @@
+package processor
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFindings(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []finding
+	}{
+		{name: "no list items", output: "NO ISSUES FOUND", want: nil},
+		{name: "tagged items with preamble and continuation",
+			output: "Found 2 issues:\n\n- [high] pkg/a.go:10 - nil map write\n  happens on first call\n- [low] pkg/b.go:3 - consider renaming x\n",
+			want: []finding{
+				{text: "[high] pkg/a.go:10 - nil map write\nhappens on first call", severity: "high"},
+				{text: "[low] pkg/b.go:3 - consider renaming x", severity: "low"},
+			}},
+		{name: "numbered items and priority tags",
+			output: "1. [P1] race in worker pool\n2) **P3** typo in comment",
+			want: []finding{
+				{text: "[P1] race in worker pool", severity: "high"},
+				{text: "**P3** typo in comment", severity: "low"},
+			}},
+		{name: "severity field and untagged item",
+			output: "* pkg/a.go:1 missing error check (severity: medium)\n* pkg/c.go:7 unclear naming",
+			want: []finding{
+				{text: "pkg/a.go:1 missing error check (severity: medium)", severity: "medium"},
+				{text: "pkg/c.go:7 unclear naming"},
+			}},
+		{name: "code block lines belong to the item",
+			output: "- [medium] wrong default\n```diff\n- old := 1\n+ old := 2\n```\n- [low] nit",
+			want: []finding{
+				{text: "[medium] wrong default\n```diff\n- old := 1\n+ old := 2\n```", severity: "medium"},
+				{text: "[low] nit", severity: "low"},
+			}},
+		{name: "nested bullets are continuation", output: "- [high] bug\n  - detail one\n  - detail two",
+			want: []finding{{text: "[high] bug\n- detail one\n- detail two", severity: "high"}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseFindings(tc.output))
+		})
+	}
+}
+
+func TestFindingFilter_Apply(t *testing.T) {
+	findings := []finding{
+		{text: "[critical] sql injection", severity: "critical"},
+		{text: "[medium] missing error check", severity: "medium"},
+		{text: "[low] consider renaming variable x", severity: "low"},
+		{text: "untagged: consider renaming y"},
+		{text: "untagged: possible nil deref"},
+	}
+
+	tests := []struct {
+		name           string
+		filter         findingFilter
+		wantKept       []string
+		wantSuppressed []string
+		wantActive     bool
+	}{
+		{name: "empty filter keeps all", filter: findingFilter{},
+			wantKept: []string{findings[0].text, findings[1].text, findings[2].text, findings[3].text, findings[4].text}},
+		{name: "min severity low keeps all", filter: findingFilter{minSeverity: "low"},
+			wantKept: []string{findings[0].text, findings[1].text, findings[2].text, findings[3].text, findings[4].text}},
+		{name: "min severity high keeps untagged", filter: findingFilter{minSeverity: "high"}, wantActive: true,
+			wantKept:       []string{findings[0].text, findings[3].text, findings[4].text},
+			wantSuppressed: []string{findings[1].text, findings[2].text}},
+		{name: "ignore pattern", filter: findingFilter{ignore: []*regexp.Regexp{regexp.MustCompile(`(?i)consider renaming`)}},
+			wantActive: true, wantKept: []string{findings[0].text, findings[1].text, findings[4].text},
+			wantSuppressed: []string{findings[2].text, findings[3].text}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			kept, suppressed := tc.filter.apply(findings)
+			texts := func(ff []finding) []string {
+				var res []string
+				for _, f := range ff {
+					res = append(res, f.text)
+				}
+				return res
+			}
+			assert.Equal(t, tc.wantKept, texts(kept))
+			assert.Equal(t, tc.wantSuppressed, texts(suppressed))
+			assert.Equal(t, tc.wantActive, tc.filter.active())
+		})
+	}
+}
+
+func TestFormatFindings(t *testing.T) {
+	got := formatFindings([]finding{{text: "[high] bug\nmore detail"}, {text: "[low] nit"}})
+	assert.Equal(t, "- [high] bug\n  more detail\n- [low] nit", got)
+}
@@ -0,0 +1,103 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// hookWaitDelay bounds waiting for hook output after the hook exits or is killed,
+// so a background child holding the output pipe can't hang the run
+const hookWaitDelay = time.Second
+
+// runPreHook runs a hook script before a phase. a failing pre-hook aborts the run.
+func (r *Runner) runPreHook(ctx context.Context, name, script string) error {
+	if script == "" {
+		return nil
+	}
+	if err := r.runHook(ctx, name, script); err != nil {
+		return fmt.Errorf("%s hook: %w", name, err)
+	}
+	return nil
+}
+
+// runPostHook runs a hook script after a phase, best-effort like finalize:
+// failures are logged but don't block success, context cancellation is propagated.
+func (r *Runner) runPostHook(ctx context.Context, name, script string) error {
+	if script == "" {
+		return nil
+	}
+	if err := r.runHook(ctx, name, script); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s hook: %w", name, ctx.Err())
+		}
+		r.log.Print("warning: %s hook failed (non-blocking): %v", name, err)
+	}
+	return nil
+}
+
+// runHook executes the hook script, streaming its combined output to the log line by line.
+// the plan file, branch, mode and progress log path are passed as environment variables.
+func (r *Runner) runHook(ctx context.Context, name, script string) error {
+	r.log.PrintSection(status.NewGenericSection(name + " hook"))
+
+	out := &hookOutput{log: r.log}
+	cmd := exec.CommandContext(ctx, script)
+	cmd.Env = append(os.Environ(),
+		"RALPHEX_PLAN="+r.cfg.PlanFile,
+		"RALPHEX_BRANCH="+r.cfg.Branch,
+		"RALPHEX_MODE="+string(r.cfg.Mode),
+		"RALPHEX_PROGRESS_LOG="+r.cfg.ProgressPath,
+	)
+	cmd.Stdout = out
+	cmd.Stderr = out // same writer, so stdout and stderr lines are not interleaved mid-line
+	cmd.WaitDelay = hookWaitDelay
+
+	err := cmd.Run()
+	out.flush()
+	if err == nil || errors.Is(err, exec.ErrWaitDelay) {
+		return nil // ErrWaitDelay means the hook succeeded but left a child holding its output
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("interrupted: %w", ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%s exited with code %d", script, exitErr.ExitCode())
+	}
+	return fmt.Errorf("run %s: %w", script, err)
+}
+
+// hookOutput passes complete lines of hook output to the logger, buffering partial lines.
+type hookOutput struct {
+	log Logger
+	buf []byte
+}
+
+// Write implements io.Writer.
+func (h *hookOutput) Write(p []byte) (int, error) {
+	h.buf = append(h.buf, p...)
+	for {
+		idx := bytes.IndexByte(h.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		h.log.PrintAligned(string(h.buf[:idx+1]))
+		h.buf = h.buf[idx+1:]
+	}
+	return len(p), nil
+}
+
+// flush logs a trailing line without a newline, if any.
+func (h *hookOutput) flush() {
+	if len(h.buf) > 0 {
+		h.log.PrintAligned(string(h.buf) + "\n")
+		h.buf = nil
+	}
+}
@@ -0,0 +1,166 @@
+package processor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// writeHook creates an executable hook script with the given shell body.
+func writeHook(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o700)) //nolint:gosec // test script must be executable
+	return path
+}
+
+// printedLines collects aligned output and formatted Print calls of a mock logger.
+func printedLines(log *mocks.LoggerMock) string {
+	var sb strings.Builder
+	for _, c := range log.PrintAlignedCalls() {
+		sb.WriteString(c.Text)
+	}
+	for _, c := range log.PrintCalls() {
+		sb.WriteString(fmt.Sprintf(c.Format, c.Args...) + "\n")
+	}
+	return sb.String()
+}
+
+func TestRunner_Hooks(t *testing.T) {
+	fullRun := func() []executor.Result {
+		return []executor.Result{
+			{Output: "task done", Signal: status.Completed},    // task phase
+			{Output: "review done", Signal: status.ReviewDone}, // first review
+			{Output: "review done", Signal: status.ReviewDone}, // pre-codex review loop
+			{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop (codex disabled)
+			{Output: "finalized"},                              // finalize
+		}
+	}
+
+	tests := []struct {
+		name          string
+		mode          processor.Mode
+		finalize      bool
+		preTask       string // hook script body, empty for no hook
+		postReview    string
+		postFinalize  string
+		results       []executor.Result
+		wantErr       string
+		wantClaude    int
+		wantOutput    []string
+		notWantOutput []string
+	}{
+		{name: "pre-task hook gets plan and branch", mode: processor.ModeFull, finalize: true,
+			preTask: `echo "plan=$RALPHEX_PLAN branch=$RALPHEX_BRANCH mode=$RALPHEX_MODE"`, results: fullRun(), wantClaude: 5,
+			wantOutput: []string{"plan=PLAN branch=feature-x mode=full"}},
+		{name: "failing pre-task hook aborts the run", mode: processor.ModeFull,
+			preTask: "echo generate failed; exit 3", results: fullRun(), wantErr: "pre-task hook:", wantClaude: 0,
+			wantOutput: []string{"generate failed"}},
+		{name: "failing post-review hook does not block success", mode: processor.ModeFull,
+			postReview: "echo lint failed >&2; exit 1", results: fullRun()[:4], wantClaude: 4,
+			wantOutput: []string{"lint failed", "post-review hook failed (non-blocking)"}},
+		{name: "post-finalize hook runs after finalize", mode: processor.ModeFull, finalize: true,
+			postReview: "echo after review", postFinalize: "printf 'after finalize'", results: fullRun(), wantClaude: 5,
+			wantOutput: []string{"after review\n", "after finalize\n"}},
+		{name: "post-finalize hook skipped when finalize disabled", mode: processor.ModeFull,
+			postFinalize: "echo after finalize", results: fullRun()[:4], wantClaude: 4,
+			notWantOutput: []string{"after finalize"}},
+		{name: "failing post-finalize hook does not block success", mode: processor.ModeFull, finalize: true,
+			postFinalize: "exit 2", results: fullRun(), wantClaude: 5,
+			wantOutput: []string{"post-finalize hook failed (non-blocking)", "exited with code 2"}},
+		{name: "tasks-only runs pre-task hook", mode: processor.ModeTasksOnly,
+			preTask: "echo before tasks", postReview: "echo after review", results: fullRun()[:1], wantClaude: 1,
+			wantOutput: []string{"before tasks"}, notWantOutput: []string{"after review"}},
+		{name: "tasks-review runs post-review hook", mode: processor.ModeTasksReview,
+			preTask: "echo before tasks", postReview: "echo after review", results: fullRun()[:4], wantClaude: 4,
+			wantOutput: []string{"before tasks", "after review"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			planFile := filepath.Join(tmpDir, "plan.md")
+			require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+			appConfig := testAppConfig(t)
+			if tc.preTask != "" {
+				appConfig.PreTaskHook = writeHook(t, tmpDir, "pre-task.sh", tc.preTask)
+			}
+			if tc.postReview != "" {
+				appConfig.PostReviewHook = writeHook(t, tmpDir, "post-review.sh", tc.postReview)
+			}
+			if tc.postFinalize != "" {
+				appConfig.PostFinalizeHook = writeHook(t, tmpDir, "post-finalize.sh", tc.postFinalize)
+			}
+
+			log := newMockLogger("progress.txt")
+			claude := newMockExecutor(tc.results)
+			cfg := processor.Config{
+				Mode:            tc.mode,
+				PlanFile:        planFile,
+				MaxIterations:   50,
+				FinalizeEnabled: tc.finalize,
+				Branch:          "feature-x",
+				AppConfig:       appConfig,
+			}
+			r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+			err := r.Run(context.Background())
+
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Len(t, claude.RunCalls(), tc.wantClaude)
+
+			output := printedLines(log)
+			for _, want := range tc.wantOutput {
+				assert.Contains(t, output, strings.ReplaceAll(want, "PLAN", planFile))
+			}
+			for _, notWant := range tc.notWantOutput {
+				assert.NotContains(t, output, notWant)
+			}
+		})
+	}
+}
+
+func TestRunner_Hooks_CanceledPostHook(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	appConfig := testAppConfig(t)
+	appConfig.PostReviewHook = writeHook(t, tmpDir, "post-review.sh", "sleep 5")
+
+	log := newMockLogger("progress.txt")
+	log.PrintSectionFunc = func(section status.Section) {
+		if strings.Contains(section.Label, "post-review hook") {
+			cancel() // cancel while the hook starts, like ctrl+c during a long lint
+		}
+	}
+	claude := newMockExecutor([]executor.Result{
+		{Output: "review done", Signal: status.ReviewDone}, // first review
+		{Output: "review done", Signal: status.ReviewDone}, // pre-codex review loop
+		{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop (codex disabled)
+	})
+	cfg := processor.Config{Mode: processor.ModeReview, PlanFile: planFile, MaxIterations: 50, AppConfig: appConfig}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+
+	err := r.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Contains(t, err.Error(), "post-review hook")
+}
@@ -13,6 +13,15 @@ import (
 //
 //		// make and configure a mocked processor.GitChecker
 //		mockedGitChecker := &GitCheckerMock{
+//			ChangedFilesFunc: func(base string) ([]string, error) {
+//				panic("mock out the ChangedFiles method")
+//			},
+//			CommitSubjectsFunc: func(from string, to string) ([]string, error) {
+//				panic("mock out the CommitSubjects method")
+//			},
+//			DiffStatFunc: func(from string, to string) (string, error) {
+//				panic("mock out the DiffStat method")
+//			},
 //			HeadHashFunc: func() (string, error) {
 //				panic("mock out the HeadHash method")
 //			},
@@ -23,16 +32,151 @@ import (
 //
 //	}
 type GitCheckerMock struct {
+	// ChangedFilesFunc mocks the ChangedFiles method.
+	ChangedFilesFunc func(base string) ([]string, error)
+
+	// CommitSubjectsFunc mocks the CommitSubjects method.
+	CommitSubjectsFunc func(from string, to string) ([]string, error)
+
+	// DiffStatFunc mocks the DiffStat method.
+	DiffStatFunc func(from string, to string) (string, error)
+
 	// HeadHashFunc mocks the HeadHash method.
 	HeadHashFunc func() (string, error)
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// ChangedFiles holds details about calls to the ChangedFiles method.
+		ChangedFiles []struct {
+			// Base is the base argument value.
+			Base string
+		}
+		// CommitSubjects holds details about calls to the CommitSubjects method.
+		CommitSubjects []struct {
+			// From is the from argument value.
+			From string
+			// To is the to argument value.
+			To string
+		}
+		// DiffStat holds details about calls to the DiffStat method.
+		DiffStat []struct {
+			// From is the from argument value.
+			From string
+			// To is the to argument value.
+			To string
+		}
 		// HeadHash holds details about calls to the HeadHash method.
 		HeadHash []struct {
 		}
 	}
-	lockHeadHash sync.RWMutex
+	lockChangedFiles   sync.RWMutex
+	lockCommitSubjects sync.RWMutex
+	lockDiffStat       sync.RWMutex
+	lockHeadHash       sync.RWMutex
+}
+
+// ChangedFiles calls ChangedFilesFunc.
+func (mock *GitCheckerMock) ChangedFiles(base string) ([]string, error) {
+	if mock.ChangedFilesFunc == nil {
+		panic("GitCheckerMock.ChangedFilesFunc: method is nil but GitChecker.ChangedFiles was just called")
+	}
+	callInfo := struct {
+		Base string
+	}{
+		Base: base,
+	}
+	mock.lockChangedFiles.Lock()
+	mock.calls.ChangedFiles = append(mock.calls.ChangedFiles, callInfo)
+	mock.lockChangedFiles.Unlock()
+	return mock.ChangedFilesFunc(base)
+}
+
+// ChangedFilesCalls gets all the calls that were made to ChangedFiles.
+// Check the length with:
+//
+//	len(mockedGitChecker.ChangedFilesCalls())
+func (mock *GitCheckerMock) ChangedFilesCalls() []struct {
+	Base string
+} {
+	var calls []struct {
+		Base string
+	}
+	mock.lockChangedFiles.RLock()
+	calls = mock.calls.ChangedFiles
+	mock.lockChangedFiles.RUnlock()
+	return calls
+}
+
+// CommitSubjects calls CommitSubjectsFunc.
+func (mock *GitCheckerMock) CommitSubjects(from string, to string) ([]string, error) {
+	if mock.CommitSubjectsFunc == nil {
+		panic("GitCheckerMock.CommitSubjectsFunc: method is nil but GitChecker.CommitSubjects was just called")
+	}
+	callInfo := struct {
+		From string
+		To   string
+	}{
+		From: from,
+		To:   to,
+	}
+	mock.lockCommitSubjects.Lock()
+	mock.calls.CommitSubjects = append(mock.calls.CommitSubjects, callInfo)
+	mock.lockCommitSubjects.Unlock()
+	return mock.CommitSubjectsFunc(from, to)
+}
+
+// CommitSubjectsCalls gets all the calls that were made to CommitSubjects.
+// Check the length with:
+//
+//	len(mockedGitChecker.CommitSubjectsCalls())
+func (mock *GitCheckerMock) CommitSubjectsCalls() []struct {
+	From string
+	To   string
+} {
+	var calls []struct {
+		From string
+		To   string
+	}
+	mock.lockCommitSubjects.RLock()
+	calls = mock.calls.CommitSubjects
+	mock.lockCommitSubjects.RUnlock()
+	return calls
+}
+
+// DiffStat calls DiffStatFunc.
+func (mock *GitCheckerMock) DiffStat(from string, to string) (string, error) {
+	if mock.DiffStatFunc == nil {
+		panic("GitCheckerMock.DiffStatFunc: method is nil but GitChecker.DiffStat was just called")
+	}
+	callInfo := struct {
+		From string
+		To   string
+	}{
+		From: from,
+		To:   to,
+	}
+	mock.lockDiffStat.Lock()
+	mock.calls.DiffStat = append(mock.calls.DiffStat, callInfo)
+	mock.lockDiffStat.Unlock()
+	return mock.DiffStatFunc(from, to)
+}
+
+// DiffStatCalls gets all the calls that were made to DiffStat.
+// Check the length with:
+//
+//	len(mockedGitChecker.DiffStatCalls())
+func (mock *GitCheckerMock) DiffStatCalls() []struct {
+	From string
+	To   string
+} {
+	var calls []struct {
+		From string
+		To   string
+	}
+	mock.lockDiffStat.RLock()
+	calls = mock.calls.DiffStat
+	mock.lockDiffStat.RUnlock()
+	return calls
 }
 
 // HeadHash calls HeadHashFunc.
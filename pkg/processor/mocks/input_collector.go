@@ -20,6 +20,9 @@ import (
 //			AskQuestionFunc: func(ctx context.Context, question string, options []string) (string, error) {
 //				panic("mock out the AskQuestion method")
 //			},
+//			AskTextFunc: func(ctx context.Context, question string, defaultAnswer string, multiline bool) (string, error) {
+//				panic("mock out the AskText method")
+//			},
 //		}
 //
 //		// use mockedInputCollector in code that requires processor.InputCollector
@@ -33,6 +36,9 @@ type InputCollectorMock struct {
 	// AskQuestionFunc mocks the AskQuestion method.
 	AskQuestionFunc func(ctx context.Context, question string, options []string) (string, error)
 
+	// AskTextFunc mocks the AskText method.
+	AskTextFunc func(ctx context.Context, question string, defaultAnswer string, multiline bool) (string, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// AskDraftReview holds details about calls to the AskDraftReview method.
@@ -53,9 +59,21 @@ type InputCollectorMock struct {
 			// Options is the options argument value.
 			Options []string
 		}
+		// AskText holds details about calls to the AskText method.
+		AskText []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Question is the question argument value.
+			Question string
+			// DefaultAnswer is the defaultAnswer argument value.
+			DefaultAnswer string
+			// Multiline is the multiline argument value.
+			Multiline bool
+		}
 	}
 	lockAskDraftReview sync.RWMutex
 	lockAskQuestion    sync.RWMutex
+	lockAskText        sync.RWMutex
 }
 
 // AskDraftReview calls AskDraftReviewFunc.
@@ -137,3 +155,47 @@ func (mock *InputCollectorMock) AskQuestionCalls() []struct {
 	mock.lockAskQuestion.RUnlock()
 	return calls
 }
+
+// AskText calls AskTextFunc.
+func (mock *InputCollectorMock) AskText(ctx context.Context, question string, defaultAnswer string, multiline bool) (string, error) {
+	if mock.AskTextFunc == nil {
+		panic("InputCollectorMock.AskTextFunc: method is nil but InputCollector.AskText was just called")
+	}
+	callInfo := struct {
+		Ctx           context.Context
+		Question      string
+		DefaultAnswer string
+		Multiline     bool
+	}{
+		Ctx:           ctx,
+		Question:      question,
+		DefaultAnswer: defaultAnswer,
+		Multiline:     multiline,
+	}
+	mock.lockAskText.Lock()
+	mock.calls.AskText = append(mock.calls.AskText, callInfo)
+	mock.lockAskText.Unlock()
+	return mock.AskTextFunc(ctx, question, defaultAnswer, multiline)
+}
+
+// AskTextCalls gets all the calls that were made to AskText.
+// Check the length with:
+//
+//	len(mockedInputCollector.AskTextCalls())
+func (mock *InputCollectorMock) AskTextCalls() []struct {
+	Ctx           context.Context
+	Question      string
+	DefaultAnswer string
+	Multiline     bool
+} {
+	var calls []struct {
+		Ctx           context.Context
+		Question      string
+		DefaultAnswer string
+		Multiline     bool
+	}
+	mock.lockAskText.RLock()
+	calls = mock.calls.AskText
+	mock.lockAskText.RUnlock()
+	return calls
+}
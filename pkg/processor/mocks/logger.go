@@ -39,6 +39,9 @@ import (
 //			PrintSectionFunc: func(section status.Section)  {
 //				panic("mock out the PrintSection method")
 //			},
+//			PrintToolFunc: func(text string)  {
+//				panic("mock out the PrintTool method")
+//			},
 //		}
 //
 //		// use mockedLogger in code that requires processor.Logger
@@ -70,6 +73,9 @@ type LoggerMock struct {
 	// PrintSectionFunc mocks the PrintSection method.
 	PrintSectionFunc func(section status.Section)
 
+	// PrintToolFunc mocks the PrintTool method.
+	PrintToolFunc func(text string)
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// LogAnswer holds details about calls to the LogAnswer method.
@@ -118,6 +124,11 @@ type LoggerMock struct {
 			// Section is the section argument value.
 			Section status.Section
 		}
+		// PrintTool holds details about calls to the PrintTool method.
+		PrintTool []struct {
+			// Text is the text argument value.
+			Text string
+		}
 	}
 	lockLogAnswer      sync.RWMutex
 	lockLogDraftReview sync.RWMutex
@@ -127,6 +138,7 @@ type LoggerMock struct {
 	lockPrintAligned   sync.RWMutex
 	lockPrintRaw       sync.RWMutex
 	lockPrintSection   sync.RWMutex
+	lockPrintTool      sync.RWMutex
 }
 
 // LogAnswer calls LogAnswerFunc.
@@ -395,3 +407,35 @@ func (mock *LoggerMock) PrintSectionCalls() []struct {
 	mock.lockPrintSection.RUnlock()
 	return calls
 }
+
+// PrintTool calls PrintToolFunc.
+func (mock *LoggerMock) PrintTool(text string) {
+	if mock.PrintToolFunc == nil {
+		panic("LoggerMock.PrintToolFunc: method is nil but Logger.PrintTool was just called")
+	}
+	callInfo := struct {
+		Text string
+	}{
+		Text: text,
+	}
+	mock.lockPrintTool.Lock()
+	mock.calls.PrintTool = append(mock.calls.PrintTool, callInfo)
+	mock.lockPrintTool.Unlock()
+	mock.PrintToolFunc(text)
+}
+
+// PrintToolCalls gets all the calls that were made to PrintTool.
+// Check the length with:
+//
+//	len(mockedLogger.PrintToolCalls())
+func (mock *LoggerMock) PrintToolCalls() []struct {
+	Text string
+} {
+	var calls []struct {
+		Text string
+	}
+	mock.lockPrintTool.RLock()
+	calls = mock.calls.PrintTool
+	mock.lockPrintTool.RUnlock()
+	return calls
+}
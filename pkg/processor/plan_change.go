@@ -0,0 +1,97 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+// ErrPlanRemoved is returned when the plan file disappears during the task phase.
+var ErrPlanRemoved = errors.New("plan file removed during the run")
+
+// ErrPlanChangeAborted is returned when the user chose to abort after the plan file changed.
+var ErrPlanChangeAborted = errors.New("aborted after the plan file changed")
+
+// plan change actions, see plan_change_action
+const (
+	planChangeReload = "reload"
+	planChangeAsk    = "ask"
+)
+
+// options of the question asked by the "ask" plan change action
+const (
+	planChangeContinue = "Continue with the new version"
+	planChangeRestore  = "Restore the previous version"
+	planChangeAbort    = "Abort the run"
+)
+
+// readPlan returns the content of the plan file, nil without a plan.
+// a missing plan file, also not found in the completed directory, is ErrPlanRemoved.
+func (r *Runner) readPlan() ([]byte, error) {
+	if r.cfg.PlanFile == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(r.resolvePlanFilePath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s was moved or deleted", ErrPlanRemoved, r.cfg.PlanFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read plan file: %w", err)
+	}
+	return content, nil
+}
+
+// reconcilePlan compares the plan file with the snapshot taken after the last task iteration,
+// so edits made outside of the agent, e.g. while the run was paused, don't go unnoticed.
+// returns the plan content to continue with and true if it changed and the task prompt has to be rebuilt.
+// with plan_change_action = ask the user chooses to continue with the new version, restore the snapshot or abort,
+// otherwise a notice is logged and the new version is used.
+func (r *Runner) reconcilePlan(ctx context.Context, snapshot []byte) ([]byte, bool, error) {
+	content, err := r.readPlan()
+	if err != nil {
+		return nil, false, err
+	}
+	if bytes.Equal(content, snapshot) {
+		return snapshot, false, nil
+	}
+
+	path := r.resolvePlanFilePath()
+	r.log.Print("plan file %s changed since the last iteration", path)
+	if r.planChangeAction() == planChangeAsk && r.inputCollector != nil {
+		options := []string{planChangeContinue, planChangeRestore, planChangeAbort}
+		answer, askErr := r.inputCollector.AskQuestion(ctx, "Plan file changed since the last iteration", options)
+		if askErr != nil {
+			return nil, false, fmt.Errorf("ask about plan change: %w", askErr)
+		}
+		switch answer {
+		case planChangeContinue:
+		case planChangeRestore:
+			if err := os.WriteFile(path, snapshot, 0o600); err != nil {
+				return nil, false, fmt.Errorf("restore plan file: %w", err)
+			}
+			r.log.Print("restored the previous version of the plan")
+			return snapshot, false, nil
+		default:
+			return nil, false, ErrPlanChangeAborted
+		}
+	}
+
+	r.log.Print("continuing with the new version of the plan")
+	if items := plan.ParseCheckboxes(string(content)); len(items) > 0 {
+		r.log.Print("plan progress: %d/%d done", plan.CountDone(items), len(items))
+	}
+	return content, true, nil
+}
+
+// planChangeAction returns the configured plan change action, reload by default.
+func (r *Runner) planChangeAction() string {
+	if r.cfg.AppConfig == nil || r.cfg.AppConfig.PlanChangeAction == "" {
+		return planChangeReload
+	}
+	return r.cfg.AppConfig.PlanChangeAction
+}
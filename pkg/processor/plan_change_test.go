@@ -0,0 +1,118 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+)
+
+func TestRunner_reconcilePlan(t *testing.T) {
+	const (
+		before = "# Plan\n- [x] Task 1\n- [ ] Task 2\n"
+		after  = "# Plan\n- [x] Task 1\n- [ ] Task 2\n- [ ] Task 3\n"
+	)
+	answer := func(text string, err error) *mocks.InputCollectorMock {
+		return &mocks.InputCollectorMock{AskQuestionFunc: func(context.Context, string, []string) (string, error) {
+			return text, err
+		}}
+	}
+	tests := []struct {
+		name        string
+		action      string
+		collector   *mocks.InputCollectorMock
+		content     string // plan file content before reconcile, empty removes the file
+		want        string
+		wantChanged bool
+		wantFile    string
+		wantErr     error
+		wantLog     string
+	}{
+		{name: "unchanged", content: before, want: before, wantFile: before},
+		{name: "reload by default", content: after, want: after, wantChanged: true, wantFile: after,
+			wantLog: "plan progress: 1/3 done"},
+		{name: "ask without collector reloads", action: "ask", content: after, want: after, wantChanged: true, wantFile: after},
+		{name: "ask, continue", action: "ask", collector: answer(planChangeContinue, nil), content: after,
+			want: after, wantChanged: true, wantFile: after, wantLog: "continuing with the new version of the plan"},
+		{name: "ask, restore", action: "ask", collector: answer(planChangeRestore, nil), content: after,
+			want: before, wantFile: before, wantLog: "restored the previous version of the plan"},
+		{name: "ask, abort", action: "ask", collector: answer(planChangeAbort, nil), content: after,
+			wantFile: after, wantErr: ErrPlanChangeAborted},
+		{name: "ask, other answer aborts", action: "ask", collector: answer("keep going", nil), content: after,
+			wantFile: after, wantErr: ErrPlanChangeAborted},
+		{name: "ask fails", action: "ask", collector: answer("", context.Canceled), content: after,
+			wantFile: after, wantErr: context.Canceled},
+		{name: "removed", content: "", wantErr: ErrPlanRemoved},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			planFile := filepath.Join(t.TempDir(), "plan.md")
+			if tc.content != "" {
+				require.NoError(t, os.WriteFile(planFile, []byte(tc.content), 0o600))
+			}
+			log := newMockLogger("")
+			r := &Runner{cfg: Config{PlanFile: planFile, AppConfig: &config.Config{PlanChangeAction: tc.action}}, log: log}
+			if tc.collector != nil {
+				r.inputCollector = tc.collector
+			}
+
+			got, changed, err := r.reconcilePlan(context.Background(), []byte(before))
+			var lines []string
+			for _, c := range log.PrintCalls() {
+				lines = append(lines, fmt.Sprintf(c.Format, c.Args...))
+			}
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.want, string(got))
+				assert.Equal(t, tc.wantChanged, changed)
+			}
+			if tc.wantFile != "" {
+				data, readErr := os.ReadFile(planFile) //nolint:gosec // test file in temp dir
+				require.NoError(t, readErr)
+				assert.Equal(t, tc.wantFile, string(data))
+			}
+			if tc.wantLog != "" {
+				assert.Contains(t, strings.Join(lines, "\n"), tc.wantLog)
+			}
+			if tc.collector != nil {
+				require.Len(t, tc.collector.AskQuestionCalls(), 1)
+				assert.Equal(t, []string{planChangeContinue, planChangeRestore, planChangeAbort},
+					tc.collector.AskQuestionCalls()[0].Options)
+			}
+		})
+	}
+}
+
+func TestRunner_readPlan(t *testing.T) {
+	t.Run("no plan", func(t *testing.T) {
+		r := &Runner{cfg: Config{}}
+		content, err := r.readPlan()
+		require.NoError(t, err)
+		assert.Nil(t, content)
+	})
+
+	t.Run("removed plan", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		r := &Runner{cfg: Config{PlanFile: planFile}}
+		_, err := r.readPlan()
+		require.ErrorIs(t, err, ErrPlanRemoved)
+		assert.Contains(t, err.Error(), planFile+" was moved or deleted")
+	})
+
+	t.Run("unreadable plan", func(t *testing.T) {
+		r := &Runner{cfg: Config{PlanFile: t.TempDir()}}
+		_, err := r.readPlan()
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrPlanRemoved)
+	})
+}
@@ -3,11 +3,12 @@ package processor
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/plan"
 )
 
 // agentRefPattern matches {{agent:name}} template syntax
@@ -16,7 +17,7 @@ var agentRefPattern = regexp.MustCompile(`\{\{agent:([a-zA-Z0-9_-]+)\}\}`)
 // getGoal returns the goal string based on whether a plan file is configured.
 func (r *Runner) getGoal() string {
 	if r.cfg.PlanFile == "" {
-		return "current branch vs " + r.getDefaultBranch()
+		return "current branch vs " + r.getBaseBranch()
 	}
 	return "implementation of plan at " + r.resolvePlanFilePath()
 }
@@ -29,8 +30,8 @@ func (r *Runner) getPlanFileRef() string {
 	return r.resolvePlanFilePath()
 }
 
-// resolvePlanFilePath returns the actual path to the plan file, checking if it was moved to completed/.
-// returns original path if file exists there, completed/ path if moved, or original path as fallback.
+// resolvePlanFilePath returns the actual path to the plan file, checking if it was moved to the completed directory.
+// returns original path if file exists there, the completed path if moved, or original path as fallback.
 func (r *Runner) resolvePlanFilePath() string {
 	if r.cfg.PlanFile == "" {
 		return ""
@@ -46,9 +47,8 @@ func (r *Runner) resolvePlanFilePath() string {
 		return r.cfg.PlanFile
 	}
 
-	// check if file was moved to completed/ subdirectory
-	completedPath := filepath.Join(filepath.Dir(r.cfg.PlanFile), "completed", filepath.Base(r.cfg.PlanFile))
-	if _, err := os.Stat(completedPath); err == nil {
+	// check if file was moved to the completed directory, possibly in a parent directory
+	if completedPath := r.planArchive().FindCompleted(r.cfg.PlanFile); completedPath != "" {
 		return completedPath
 	}
 
@@ -56,6 +56,14 @@ func (r *Runner) resolvePlanFilePath() string {
 	return r.cfg.PlanFile
 }
 
+// planArchive returns where finished plans are moved, the default completed directory without app config.
+func (r *Runner) planArchive() plan.Archive {
+	if r.cfg.AppConfig == nil {
+		return plan.Archive{}
+	}
+	return plan.Archive{Dir: r.cfg.AppConfig.CompletedDir, DateLayout: r.cfg.AppConfig.CompletedDirDateLayout}
+}
+
 // getProgressFileRef returns progress file reference or fallback text for prompts.
 func (r *Runner) getProgressFileRef() string {
 	if r.cfg.ProgressPath == "" {
@@ -64,8 +72,39 @@ func (r *Runner) getProgressFileRef() string {
 	return r.cfg.ProgressPath
 }
 
+// getChangedFilesRef returns the files changed in the reviewed range as a markdown list, or fallback text for prompts.
+func (r *Runner) getChangedFilesRef() string {
+	if r.git == nil {
+		return "(changed files unavailable)"
+	}
+	files, err := r.git.ChangedFiles(r.getDiffRange())
+	if err != nil {
+		r.log.Print("warning: failed to list changed files: %v", err)
+		return "(changed files unavailable)"
+	}
+	if len(files) == 0 {
+		return "(no changed files)"
+	}
+	return "- " + strings.Join(files, "\n- ")
+}
+
+// getProjectContextRef returns the project context file content for prompts, empty if there is none.
+// truncated content ends with a note, so the agent knows it may read the rest of the file itself.
+func (r *Runner) getProjectContextRef() string {
+	if r.cfg.AppConfig == nil || r.cfg.AppConfig.ProjectContext.Content == "" {
+		return ""
+	}
+	pc := r.cfg.AppConfig.ProjectContext
+	ref := fmt.Sprintf("PROJECT CONTEXT (from %s, follow these project conventions):\n%s", pc.File, pc.Content)
+	if pc.Truncated() {
+		ref += fmt.Sprintf("\n[truncated to %d of %d bytes, read %s for the rest]", len(pc.Content), pc.Size, pc.File)
+	}
+	return ref
+}
+
 // replaceBaseVariables replaces common template variables in prompts.
-// supported: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}
+// supported: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}, {{BASE_BRANCH}}, {{DIFF_RANGE}}, {{CHANGED_FILES}},
+// {{PROJECT_CONTEXT}}
 // this is the core replacement function used by all prompt builders.
 func (r *Runner) replaceBaseVariables(prompt string) string {
 	result := prompt
@@ -73,21 +112,29 @@ func (r *Runner) replaceBaseVariables(prompt string) string {
 	result = strings.ReplaceAll(result, "{{PROGRESS_FILE}}", r.getProgressFileRef())
 	result = strings.ReplaceAll(result, "{{GOAL}}", r.getGoal())
 	result = strings.ReplaceAll(result, "{{DEFAULT_BRANCH}}", r.getDefaultBranch())
+	result = strings.ReplaceAll(result, "{{BASE_BRANCH}}", r.getBaseBranch())
+	result = strings.ReplaceAll(result, "{{DIFF_RANGE}}", r.getDiffRange())
+	// listing files runs git, skipped for prompts that don't use it
+	if strings.Contains(result, "{{CHANGED_FILES}}") {
+		result = strings.ReplaceAll(result, "{{CHANGED_FILES}}", r.getChangedFilesRef())
+	}
+	// last, so variables written in the context file stay as they are
+	result = strings.ReplaceAll(result, config.ProjectContextVar, r.getProjectContextRef())
 	return result
 }
 
 // getDiffInstruction returns the appropriate git diff command based on iteration.
-// first iteration: compares default branch to HEAD (all changes in feature branch)
+// first iteration: the reviewed range, base branch to HEAD by default (all changes in feature branch)
 // subsequent iterations: shows uncommitted changes only (fixes from previous iteration)
 func (r *Runner) getDiffInstruction(isFirstIteration bool) string {
 	if isFirstIteration {
-		return fmt.Sprintf("git diff %s...HEAD", r.getDefaultBranch())
+		return "git diff " + r.getDiffRange()
 	}
 	return "git diff"
 }
 
 // replaceVariablesWithIteration replaces all template variables including iteration-aware ones.
-// supported: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}, {{DIFF_INSTRUCTION}}, {{agent:name}}
+// supported: base variables (see replaceBaseVariables), {{DIFF_INSTRUCTION}}, {{agent:name}}
 // this variant is used when iteration context is needed (e.g., custom review prompts).
 func (r *Runner) replaceVariablesWithIteration(prompt string, isFirstIteration bool) string {
 	result := r.replaceBaseVariables(prompt)
@@ -117,18 +164,20 @@ Report findings only - no positive observations.`, modelClause, subagent, prompt
 // expandAgentReferences replaces {{agent:name}} patterns with Task tool instructions.
 // returns prompt unchanged if AppConfig is nil or no agents are configured.
 // missing agents log a warning and leave the reference as-is for visibility.
+// with Config.Agents set (plan front-matter), references to agents not listed there are dropped.
+// agents come from agent files and the [custom_agents] config section, config wins on name collisions.
 func (r *Runner) expandAgentReferences(prompt string) string {
 	if r.cfg.AppConfig == nil {
 		return prompt
 	}
-	agents := r.cfg.AppConfig.CustomAgents
-	if len(agents) == 0 {
+	fileAgents, configAgents := r.cfg.AppConfig.CustomAgents, r.cfg.AppConfig.ConfigAgents
+	if len(fileAgents) == 0 && len(configAgents) == 0 {
 		return prompt
 	}
 
-	// build agent lookup map
-	agentMap := make(map[string]config.CustomAgent, len(agents))
-	for _, agent := range agents {
+	// build agent lookup map, config agents are added last to replace files of the same name
+	agentMap := make(map[string]config.CustomAgent, len(fileAgents)+len(configAgents))
+	for _, agent := range slices.Concat(fileAgents, configAgents) {
 		agentMap[agent.Name] = agent
 	}
 
@@ -136,6 +185,11 @@ func (r *Runner) expandAgentReferences(prompt string) string {
 		// extract name directly from match: {{agent:NAME}} -> NAME
 		name := match[8 : len(match)-2] // skip "{{agent:" and "}}"
 
+		if r.cfg.Agents != nil && !slices.Contains(r.cfg.Agents, name) {
+			r.log.Print("agent %q skipped, not in the agents of the plan", name)
+			return ""
+		}
+
 		agent, ok := agentMap[name]
 		if !ok {
 			r.log.Print("[WARN] agent %q not found, leaving reference unexpanded", name)
@@ -151,8 +205,32 @@ func (r *Runner) expandAgentReferences(prompt string) string {
 	})
 }
 
+// expandAgentList replaces {{agents}} with a header and an {{agent:name}} reference for each of the agents,
+// in order, expanded by expandAgentReferences like references written in the prompt.
+// with Config.Agents set (plan front-matter), agents not listed there are dropped along with their header.
+func (r *Runner) expandAgentList(prompt string, agents []string) string {
+	if !strings.Contains(prompt, "{{agents}}") {
+		return prompt
+	}
+	refs := make([]string, 0, len(agents))
+	for _, name := range agents {
+		if r.cfg.Agents != nil && !slices.Contains(r.cfg.Agents, name) {
+			r.log.Print("agent %q skipped, not in the agents of the plan", name)
+			continue
+		}
+		refs = append(refs, fmt.Sprintf("### agent: %s\n{{agent:%s}}", name, name))
+	}
+	return strings.ReplaceAll(prompt, "{{agents}}", strings.Join(refs, "\n\n"))
+}
+
+// replaceReviewPromptVariables replaces all template variables of a review prompt,
+// {{agents}} expands to the given agents (review_first_agents or review_second_agents).
+func (r *Runner) replaceReviewPromptVariables(prompt string, agents []string) string {
+	return r.replacePromptVariables(r.expandAgentList(prompt, agents))
+}
+
 // replacePromptVariables replaces all template variables including agent references.
-// supported: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}, {{agent:name}}
+// supported: base variables (see replaceBaseVariables), {{agent:name}}
 // note: {{CODEX_OUTPUT}} and {{PLAN_DESCRIPTION}} are handled by specific build functions.
 func (r *Runner) replacePromptVariables(prompt string) string {
 	result := r.replaceBaseVariables(prompt)
@@ -168,6 +246,22 @@ func (r *Runner) getDefaultBranch() string {
 	return r.cfg.DefaultBranch
 }
 
+// getBaseBranch returns the branch reviews compare against, the default branch unless set.
+func (r *Runner) getBaseBranch() string {
+	if r.cfg.BaseBranch == "" {
+		return r.getDefaultBranch()
+	}
+	return r.cfg.BaseBranch
+}
+
+// getDiffRange returns the revision range under review, changes of HEAD since it forked from the base branch unless set.
+func (r *Runner) getDiffRange() string {
+	if r.cfg.DiffRange == "" {
+		return r.getBaseBranch() + "...HEAD"
+	}
+	return r.cfg.DiffRange
+}
+
 // buildCodexEvaluationPrompt creates the prompt for claude to evaluate codex review output.
 // uses the codex prompt loaded from config (either user-provided or embedded default).
 // agent references ({{agent:name}}) are expanded via replacePromptVariables.
@@ -176,15 +270,44 @@ func (r *Runner) buildCodexEvaluationPrompt(codexOutput string) string {
 	return strings.ReplaceAll(prompt, "{{CODEX_OUTPUT}}", codexOutput)
 }
 
+// buildGeminiEvaluationPrompt creates the prompt for claude to evaluate gemini review output.
+// uses the gemini prompt loaded from config (either user-provided or embedded default).
+func (r *Runner) buildGeminiEvaluationPrompt(geminiOutput string) string {
+	prompt := r.replacePromptVariables(r.cfg.AppConfig.GeminiPrompt)
+	return strings.ReplaceAll(prompt, "{{GEMINI_OUTPUT}}", geminiOutput)
+}
+
 // buildPlanPrompt creates the prompt for interactive plan creation.
 // uses the make_plan prompt loaded from config (either user-provided or embedded default).
-// replaces {{PLAN_DESCRIPTION}} plus all base variables.
+// replaces {{PLAN_DESCRIPTION}}, {{TEMPLATE}} plus all base variables.
+// a custom make_plan prompt without {{TEMPLATE}} gets the chosen template appended.
 func (r *Runner) buildPlanPrompt() string {
 	prompt := r.cfg.AppConfig.MakePlanPrompt
 	prompt = strings.ReplaceAll(prompt, "{{PLAN_DESCRIPTION}}", r.cfg.PlanDescription)
+	tmpl := r.planTemplateRef()
+	if tmpl != "" && !strings.Contains(prompt, "{{TEMPLATE}}") {
+		prompt += "\n\n{{TEMPLATE}}"
+	}
+	prompt = strings.ReplaceAll(prompt, "{{TEMPLATE}}", tmpl)
 	return r.replaceBaseVariables(prompt)
 }
 
+// planTemplateRef returns the {{TEMPLATE}} replacement, the chosen plan template with instructions
+// to keep its section headers, or an empty string when plan mode runs without a template.
+func (r *Runner) planTemplateRef() string {
+	if r.cfg.PlanTemplate == "" {
+		return ""
+	}
+	return `PLAN TEMPLATE: use the template below for both the draft and the plan file instead of the default structure.
+Keep every section header exactly as written and in the same order, do not add, rename or remove headers.
+Fill in each section: replace the <placeholders> with real content, add or drop tasks and checkboxes as the plan needs,
+keeping the task header format.
+
+---
+` + r.cfg.PlanTemplate + `
+---`
+}
+
 // buildCustomReviewPrompt creates the prompt for custom review tool execution.
 // uses the custom_review prompt loaded from config with {{DIFF_INSTRUCTION}} expanded.
 // claudeResponse from previous iteration is appended if present.
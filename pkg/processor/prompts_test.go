@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
 )
 
 func TestRunner_replacePromptVariables_TaskPrompt(t *testing.T) {
@@ -29,7 +31,7 @@ func TestRunner_replacePromptVariables_ReviewFirstPrompt(t *testing.T) {
 	t.Run("with plan file and progress path", func(t *testing.T) {
 		appCfg := testAppConfig(t)
 		r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", ProgressPath: "progress-test.txt", DefaultBranch: "main", AppConfig: appCfg}, log: newMockLogger("")}
-		prompt := r.replacePromptVariables(appCfg.ReviewFirstPrompt)
+		prompt := r.replaceReviewPromptVariables(appCfg.ReviewFirstPrompt, appCfg.ReviewFirstAgents)
 
 		assert.Contains(t, prompt, "docs/plans/test.md")
 		assert.Contains(t, prompt, "progress-test.txt") // progress file should be substituted
@@ -48,7 +50,7 @@ func TestRunner_replacePromptVariables_ReviewFirstPrompt(t *testing.T) {
 	t.Run("without plan file uses default branch in goal", func(t *testing.T) {
 		appCfg := testAppConfig(t)
 		r := &Runner{cfg: Config{PlanFile: "", ProgressPath: "progress.txt", DefaultBranch: "trunk", AppConfig: appCfg}, log: newMockLogger("")}
-		prompt := r.replacePromptVariables(appCfg.ReviewFirstPrompt)
+		prompt := r.replaceReviewPromptVariables(appCfg.ReviewFirstPrompt, appCfg.ReviewFirstAgents)
 
 		assert.Contains(t, prompt, "current branch vs trunk")
 		assert.Contains(t, prompt, "progress.txt")
@@ -58,7 +60,7 @@ func TestRunner_replacePromptVariables_ReviewFirstPrompt(t *testing.T) {
 	t.Run("fallback to master when default branch not set", func(t *testing.T) {
 		appCfg := testAppConfig(t)
 		r := &Runner{cfg: Config{PlanFile: "", ProgressPath: "progress.txt", AppConfig: appCfg}, log: newMockLogger("")}
-		prompt := r.replacePromptVariables(appCfg.ReviewFirstPrompt)
+		prompt := r.replaceReviewPromptVariables(appCfg.ReviewFirstPrompt, appCfg.ReviewFirstAgents)
 
 		assert.Contains(t, prompt, "current branch vs master")
 	})
@@ -68,7 +70,7 @@ func TestRunner_replacePromptVariables_ReviewSecondPrompt(t *testing.T) {
 	t.Run("with plan file and progress path", func(t *testing.T) {
 		appCfg := testAppConfig(t)
 		r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", ProgressPath: "progress-test.txt", DefaultBranch: "main", AppConfig: appCfg}, log: newMockLogger("")}
-		prompt := r.replacePromptVariables(appCfg.ReviewSecondPrompt)
+		prompt := r.replaceReviewPromptVariables(appCfg.ReviewSecondPrompt, appCfg.ReviewSecondAgents)
 
 		assert.Contains(t, prompt, "docs/plans/test.md")
 		assert.Contains(t, prompt, "progress-test.txt") // progress file should be substituted
@@ -88,7 +90,7 @@ func TestRunner_replacePromptVariables_ReviewSecondPrompt(t *testing.T) {
 	t.Run("without plan file uses default branch in goal", func(t *testing.T) {
 		appCfg := testAppConfig(t)
 		r := &Runner{cfg: Config{PlanFile: "", ProgressPath: "progress.txt", DefaultBranch: "develop", AppConfig: appCfg}, log: newMockLogger("")}
-		prompt := r.replacePromptVariables(appCfg.ReviewSecondPrompt)
+		prompt := r.replaceReviewPromptVariables(appCfg.ReviewSecondPrompt, appCfg.ReviewSecondAgents)
 
 		assert.Contains(t, prompt, "current branch vs develop")
 		assert.Contains(t, prompt, "progress.txt")
@@ -102,8 +104,8 @@ func TestRunner_replacePromptVariables_NoAgentWarningsInEmbeddedPrompts(t *testi
 	log := newMockLogger("")
 	r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", ProgressPath: "progress.txt", DefaultBranch: "main", AppConfig: appCfg}, log: log}
 
-	r.replacePromptVariables(appCfg.ReviewFirstPrompt)
-	r.replacePromptVariables(appCfg.ReviewSecondPrompt)
+	r.replaceReviewPromptVariables(appCfg.ReviewFirstPrompt, appCfg.ReviewFirstAgents)
+	r.replaceReviewPromptVariables(appCfg.ReviewSecondPrompt, appCfg.ReviewSecondAgents)
 
 	// verify no "not found" warnings were logged
 	for _, call := range log.PrintCalls() {
@@ -143,21 +145,21 @@ func TestRunner_replacePromptVariables_CustomReviewFirstPrompt(t *testing.T) {
 
 	t.Run("with plan file", func(t *testing.T) {
 		r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", AppConfig: appCfg}}
-		prompt := r.replacePromptVariables(appCfg.ReviewFirstPrompt)
+		prompt := r.replaceReviewPromptVariables(appCfg.ReviewFirstPrompt, appCfg.ReviewFirstAgents)
 
 		assert.Equal(t, "Custom first review for implementation of plan at docs/plans/test.md", prompt)
 	})
 
 	t.Run("without plan file uses default branch", func(t *testing.T) {
 		r := &Runner{cfg: Config{PlanFile: "", DefaultBranch: "main", AppConfig: appCfg}}
-		prompt := r.replacePromptVariables(appCfg.ReviewFirstPrompt)
+		prompt := r.replaceReviewPromptVariables(appCfg.ReviewFirstPrompt, appCfg.ReviewFirstAgents)
 
 		assert.Equal(t, "Custom first review for current branch vs main", prompt)
 	})
 
 	t.Run("without plan file fallback to master", func(t *testing.T) {
 		r := &Runner{cfg: Config{PlanFile: "", AppConfig: appCfg}}
-		prompt := r.replacePromptVariables(appCfg.ReviewFirstPrompt)
+		prompt := r.replaceReviewPromptVariables(appCfg.ReviewFirstPrompt, appCfg.ReviewFirstAgents)
 
 		assert.Equal(t, "Custom first review for current branch vs master", prompt)
 	})
@@ -168,7 +170,7 @@ func TestRunner_replacePromptVariables_CustomReviewSecondPrompt(t *testing.T) {
 		ReviewSecondPrompt: "Custom second review for {{GOAL}}",
 	}
 	r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", AppConfig: appCfg}}
-	prompt := r.replacePromptVariables(appCfg.ReviewSecondPrompt)
+	prompt := r.replaceReviewPromptVariables(appCfg.ReviewSecondPrompt, appCfg.ReviewSecondAgents)
 
 	assert.Equal(t, "Custom second review for implementation of plan at docs/plans/test.md", prompt)
 }
@@ -235,6 +237,99 @@ func TestRunner_replacePromptVariables_DefaultBranch(t *testing.T) {
 	})
 }
 
+func TestRunner_replacePromptVariables_ProjectContext(t *testing.T) {
+	t.Run("with context file", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.ProjectContext = config.ProjectContext{File: "CLAUDE.md", Content: "never touch migrations", Size: 22}
+		r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
+
+		result := r.replacePromptVariables("Task.\n{{PROJECT_CONTEXT}}")
+		assert.Equal(t, "Task.\nPROJECT CONTEXT (from CLAUDE.md, follow these project conventions):\nnever touch migrations", result)
+
+		// embedded prompts carry the context
+		assert.Contains(t, r.replacePromptVariables(appCfg.TaskPrompt), "never touch migrations")
+		assert.Contains(t, r.replaceReviewPromptVariables(appCfg.ReviewFirstPrompt, appCfg.ReviewFirstAgents), "never touch migrations")
+		assert.Contains(t, r.buildCodexEvaluationPrompt("no issues"), "never touch migrations")
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.ProjectContext = config.ProjectContext{File: "CLAUDE.md", Content: "never", Size: 22}
+		r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
+
+		result := r.replacePromptVariables("{{PROJECT_CONTEXT}}")
+		assert.True(t, strings.HasSuffix(result, "never\n[truncated to 5 of 22 bytes, read CLAUDE.md for the rest]"), result)
+	})
+
+	t.Run("without context file", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
+
+		assert.Equal(t, "Task.\n", r.replacePromptVariables("Task.\n{{PROJECT_CONTEXT}}"))
+		assert.NotContains(t, r.replacePromptVariables(appCfg.TaskPrompt), "{{PROJECT_CONTEXT}}")
+	})
+}
+
+func TestRunner_replacePromptVariables_ReviewRange(t *testing.T) {
+	const prompt = "base={{BASE_BRANCH}} range={{DIFF_RANGE}} goal={{GOAL}}\n{{CHANGED_FILES}}"
+	files := func(names []string, err error) *mocks.GitCheckerMock {
+		return &mocks.GitCheckerMock{ChangedFilesFunc: func(string) ([]string, error) { return names, err }}
+	}
+	tests := []struct {
+		name      string
+		cfg       Config
+		git       *mocks.GitCheckerMock
+		want      string
+		wantRange string
+	}{
+		{name: "default branch", cfg: Config{DefaultBranch: "main"}, git: files([]string{"a.go", "pkg/b.go"}, nil),
+			want: "base=main range=main...HEAD goal=current branch vs main\n- a.go\n- pkg/b.go", wantRange: "main...HEAD"},
+		{name: "base branch", cfg: Config{DefaultBranch: "main", BaseBranch: "release"}, git: files([]string{"a.go"}, nil),
+			want: "base=release range=release...HEAD goal=current branch vs release\n- a.go", wantRange: "release...HEAD"},
+		{name: "recent commits", cfg: Config{DefaultBranch: "main", BaseBranch: "HEAD~5"}, git: files([]string{"a.go"}, nil),
+			want: "base=HEAD~5 range=HEAD~5...HEAD goal=current branch vs HEAD~5\n- a.go", wantRange: "HEAD~5...HEAD"},
+		{name: "diff range", cfg: Config{BaseBranch: "v1.2", DiffRange: "v1.2..HEAD"}, git: files(nil, nil),
+			want: "base=v1.2 range=v1.2..HEAD goal=current branch vs v1.2\n(no changed files)", wantRange: "v1.2..HEAD"},
+		{name: "git error", cfg: Config{}, git: files(nil, errors.New("bad revision")),
+			want: "base=master range=master...HEAD goal=current branch vs master\n(changed files unavailable)"},
+		{name: "no git checker", cfg: Config{},
+			want: "base=master range=master...HEAD goal=current branch vs master\n(changed files unavailable)"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Runner{cfg: tc.cfg, log: newMockLogger("")}
+			if tc.git != nil {
+				r.git = tc.git
+			}
+			assert.Equal(t, tc.want, r.replacePromptVariables(prompt))
+			if tc.wantRange != "" {
+				require.Len(t, tc.git.ChangedFilesCalls(), 1)
+				assert.Equal(t, tc.wantRange, tc.git.ChangedFilesCalls()[0].Base)
+			}
+		})
+	}
+
+	t.Run("changed files are listed only when used", func(t *testing.T) {
+		git := files([]string{"a.go"}, nil)
+		r := &Runner{cfg: Config{}, log: newMockLogger(""), git: git}
+		assert.Equal(t, "git diff master...HEAD", r.replacePromptVariables("git diff {{DIFF_RANGE}}"))
+		assert.Empty(t, git.ChangedFilesCalls())
+	})
+}
+
+func TestRunner_buildCodexPrompt_ReviewRange(t *testing.T) {
+	git := &mocks.GitCheckerMock{ChangedFilesFunc: func(string) ([]string, error) { return []string{"api.go"}, nil }}
+	r := &Runner{cfg: Config{DiffRange: "v1.2..HEAD"}, log: newMockLogger(""), git: git}
+
+	prompt := r.buildCodexPrompt(true, "")
+	assert.Contains(t, prompt, "Review the code changes in v1.2..HEAD.")
+	assert.Contains(t, prompt, "Run: git diff v1.2..HEAD\n\nChanged files:\n- api.go")
+
+	prompt = r.buildCodexPrompt(false, "")
+	assert.Contains(t, prompt, "Run: git diff\n")
+	assert.NotContains(t, prompt, "Changed files:")
+}
+
 func TestRunner_getPlanFileRef(t *testing.T) {
 	t.Run("with plan file", func(t *testing.T) {
 		r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md"}}
@@ -277,6 +372,17 @@ func TestRunner_resolvePlanFilePath(t *testing.T) {
 		assert.Equal(t, completedPath, r.resolvePlanFilePath())
 	})
 
+	t.Run("plan from subdirectory moved to completed of plans dir", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		plansDir := filepath.Join(tmpDir, "docs", "plans")
+		completedPath := filepath.Join(plansDir, "completed", "backend", "test.md")
+		require.NoError(t, os.MkdirAll(filepath.Dir(completedPath), 0o700))
+		require.NoError(t, os.WriteFile(completedPath, []byte("# plan"), 0o600))
+
+		r := &Runner{cfg: Config{PlanFile: filepath.Join(plansDir, "backend", "test.md")}}
+		assert.Equal(t, completedPath, r.resolvePlanFilePath())
+	})
+
 	t.Run("file not found anywhere returns original path", func(t *testing.T) {
 		r := &Runner{cfg: Config{PlanFile: "/nonexistent/path/plan.md"}}
 		assert.Equal(t, "/nonexistent/path/plan.md", r.resolvePlanFilePath())
@@ -377,6 +483,110 @@ func TestRunner_expandAgentReferences_MultipleAgents(t *testing.T) {
 	assert.NotContains(t, result, "{{agent:agent-b}}")
 }
 
+func TestRunner_expandAgentReferences_ConfigAgents(t *testing.T) {
+	appCfg := &config.Config{
+		CustomAgents: []config.CustomAgent{
+			{Name: "quality", Prompt: "file quality prompt", Options: config.Options{Model: "opus"}},
+			{Name: "testing", Prompt: "file testing prompt"},
+		},
+		ConfigAgents: []config.CustomAgent{
+			{Name: "quality", Prompt: "config quality prompt"},
+			{Name: "api", Prompt: "check handlers in {{DEFAULT_BRANCH}} follow docs/api.md"},
+		},
+	}
+	r := &Runner{cfg: Config{AppConfig: appCfg, DefaultBranch: "main"}, log: newMockLogger("")}
+
+	result := r.expandAgentReferences("{{agent:quality}}\n{{agent:testing}}\n{{agent:api}}")
+
+	assert.Contains(t, result, "config quality prompt", "config agent wins on a name collision")
+	assert.NotContains(t, result, "file quality prompt")
+	assert.NotContains(t, result, "with model=opus", "options of the replaced file agent are not kept")
+	assert.Contains(t, result, "file testing prompt")
+	assert.Contains(t, result, "check handlers in main follow docs/api.md")
+	assert.NotContains(t, result, "{{agent:")
+}
+
+func TestRunner_replacePromptVariables_ConfigAgentInReviewPrompt(t *testing.T) {
+	appCfg := testAppConfig(t)
+	appCfg.ConfigAgents = []config.CustomAgent{{Name: "api", Prompt: "check handlers follow docs/api.md"}}
+	appCfg.ReviewFirstPrompt += "\n{{agent:api}}\n"
+	r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", AppConfig: appCfg}, log: newMockLogger("")}
+
+	prompt := r.replaceReviewPromptVariables(appCfg.ReviewFirstPrompt, appCfg.ReviewFirstAgents)
+	assert.Contains(t, prompt, "Use the Task tool to launch a general-purpose agent with this prompt:\n\"check handlers follow docs/api.md\"")
+	assert.Contains(t, prompt, "Review code for bugs, security issues, and quality problems.", "file agents are still expanded")
+	assert.NotContains(t, prompt, "{{agent:")
+}
+
+func TestRunner_expandAgentReferences_PlanAgents(t *testing.T) {
+	appCfg := &config.Config{
+		CustomAgents: []config.CustomAgent{
+			{Name: "agent-a", Prompt: "first agent prompt"},
+			{Name: "agent-b", Prompt: "second agent prompt"},
+		},
+	}
+	log := newMockLogger("")
+	r := &Runner{cfg: Config{AppConfig: appCfg, Agents: []string{"agent-b"}}, log: log}
+
+	result := r.expandAgentReferences("Run {{agent:agent-a}} then {{agent:agent-b}}.")
+	assert.NotContains(t, result, "first agent prompt")
+	assert.NotContains(t, result, "{{agent:agent-a}}", "reference to an agent not listed is dropped")
+	assert.Contains(t, result, "second agent prompt")
+	assert.Equal(t, "agent %q skipped, not in the agents of the plan", log.PrintCalls()[0].Format)
+
+	r.cfg.Agents = []string{}
+	result = r.expandAgentReferences("Run {{agent:agent-a}} then {{agent:agent-b}}.")
+	assert.Equal(t, "Run  then .", result, "empty list drops all agents")
+}
+
+func TestRunner_replacePromptVariables_ReviewPromptAgents(t *testing.T) {
+	appCfg := testAppConfig(t)
+	r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", AppConfig: appCfg, Agents: []string{"quality", "documentation"}},
+		log: newMockLogger("")}
+	prompt := r.replaceReviewPromptVariables(appCfg.ReviewFirstPrompt, appCfg.ReviewFirstAgents)
+
+	assert.Contains(t, prompt, "Review code for bugs, security issues, and quality problems.")
+	assert.Contains(t, prompt, "Review code changes and identify missing documentation updates.")
+	assert.NotContains(t, prompt, "Review test coverage and quality.", "excluded agent is not rendered")
+	assert.NotContains(t, prompt, "{{agent:")
+	assert.Equal(t, 2, strings.Count(prompt, "Use the Task tool"))
+}
+
+func TestRunner_replaceReviewPromptVariables_AgentList(t *testing.T) {
+	appCfg := testAppConfig(t)
+	appCfg.ConfigAgents = []config.CustomAgent{{Name: "api", Prompt: "check handlers follow docs/api.md"}}
+
+	t.Run("selected agents in order with headers", func(t *testing.T) {
+		r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", AppConfig: appCfg}, log: newMockLogger("")}
+		prompt := r.replaceReviewPromptVariables(appCfg.ReviewFirstPrompt, []string{"api", "testing"})
+
+		assert.NotContains(t, prompt, "{{agents}}")
+		assert.NotContains(t, prompt, "{{agent:")
+		assert.Equal(t, 2, strings.Count(prompt, "Use the Task tool"))
+		api, testing := strings.Index(prompt, "### agent: api\n"), strings.Index(prompt, "### agent: testing\n")
+		require.NotEqual(t, -1, api)
+		require.NotEqual(t, -1, testing)
+		assert.Less(t, api, testing, "agents keep the configured order")
+		assert.Contains(t, prompt, "### agent: api\nUse the Task tool to launch a general-purpose agent with this prompt:\n\"check handlers")
+		assert.NotContains(t, prompt, "Review code for bugs, security issues, and quality problems.", "unlisted agents are left out")
+	})
+
+	t.Run("plan agents drop the header too", func(t *testing.T) {
+		r := &Runner{cfg: Config{AppConfig: appCfg, Agents: []string{"testing"}}, log: newMockLogger("")}
+		prompt := r.replaceReviewPromptVariables("Agents:\n{{agents}}\nend", []string{"api", "testing"})
+		assert.Equal(t, 1, strings.Count(prompt, "### agent:"))
+		assert.Contains(t, prompt, "Agents:\n### agent: testing\nUse the Task tool")
+	})
+
+	t.Run("explicit references still work", func(t *testing.T) {
+		r := &Runner{cfg: Config{AppConfig: appCfg}, log: newMockLogger("")}
+		prompt := r.replaceReviewPromptVariables("{{agent:api}}\n{{agents}}", []string{"quality"})
+		assert.Contains(t, prompt, "check handlers follow docs/api.md")
+		assert.Contains(t, prompt, "### agent: quality\n")
+		assert.Equal(t, 2, strings.Count(prompt, "Use the Task tool"))
+	})
+}
+
 func TestRunner_expandAgentReferences_MissingAgent(t *testing.T) {
 	appCfg := &config.Config{
 		CustomAgents: []config.CustomAgent{{Name: "existing", Prompt: "exists"}},
@@ -688,6 +898,47 @@ func TestRunner_buildPlanPrompt(t *testing.T) {
 
 		assert.Equal(t, "Create plan for: custom feature\nLog: custom-progress.txt", prompt)
 	})
+
+	t.Run("injects plan template", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		tmpl := "# <Title>\n\n## Overview\n<overview>\n\n## Risks\n- [ ] <risk>"
+		r := &Runner{cfg: Config{
+			PlanDescription: "add caching",
+			PlanTemplate:    tmpl,
+			ProgressPath:    "progress.txt",
+			AppConfig:       appCfg,
+		}, log: newMockLogger("")}
+
+		prompt := r.buildPlanPrompt()
+
+		assert.Contains(t, prompt, "PLAN TEMPLATE:")
+		assert.Contains(t, prompt, tmpl)
+		assert.NotContains(t, prompt, "{{TEMPLATE}}")
+		assert.Less(t, strings.Index(prompt, tmpl), strings.Index(prompt, "Step 0"), "template placed before the steps")
+	})
+
+	t.Run("no template leaves no trace", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		r := &Runner{cfg: Config{PlanDescription: "add caching", ProgressPath: "progress.txt", AppConfig: appCfg}, log: newMockLogger("")}
+
+		prompt := r.buildPlanPrompt()
+
+		assert.NotContains(t, prompt, "PLAN TEMPLATE:")
+		assert.NotContains(t, prompt, "{{TEMPLATE}}")
+	})
+
+	t.Run("custom prompt without variable gets template appended", func(t *testing.T) {
+		r := &Runner{cfg: Config{
+			PlanDescription: "custom feature",
+			PlanTemplate:    "## Overview",
+			AppConfig:       &config.Config{MakePlanPrompt: "Create plan for: {{PLAN_DESCRIPTION}}"},
+		}, log: newMockLogger("")}
+
+		prompt := r.buildPlanPrompt()
+
+		assert.True(t, strings.HasPrefix(prompt, "Create plan for: custom feature\n\nPLAN TEMPLATE:"))
+		assert.True(t, strings.HasSuffix(prompt, "---\n## Overview\n---"))
+	})
 }
 
 func TestRunner_getDiffInstruction(t *testing.T) {
@@ -708,6 +959,11 @@ func TestRunner_getDiffInstruction(t *testing.T) {
 		result := r.getDiffInstruction(true)
 		assert.Equal(t, "git diff master...HEAD", result)
 	})
+
+	t.Run("first iteration uses diff range", func(t *testing.T) {
+		r := &Runner{cfg: Config{DefaultBranch: "main", BaseBranch: "v1.2", DiffRange: "v1.2..HEAD"}}
+		assert.Equal(t, "git diff v1.2..HEAD", r.getDiffInstruction(true))
+	})
 }
 
 func TestRunner_replaceVariablesWithIteration(t *testing.T) {
@@ -0,0 +1,40 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// transientErrorRe matches agent call errors caused by timeouts or network failures, worth retrying
+var transientErrorRe = regexp.MustCompile(`(?i)time[ds]? ?out|connection (?:reset|refused|closed|error)|broken pipe|` +
+	`network is unreachable|no route to host|temporary failure|unexpected eof|econnreset|etimedout|socket hang up|overloaded`)
+
+// retryDelay returns the delay before the given retry (1-based): the base delay doubled for each
+// earlier retry, capped by the max delay if set.
+func (r *Runner) retryDelay(retry int) time.Duration {
+	delay := r.retryBaseDelay
+	for i := 1; i < retry && (r.retryMaxDelay <= 0 || delay < r.retryMaxDelay); i++ {
+		delay *= 2
+	}
+	if r.retryMaxDelay > 0 && delay > r.retryMaxDelay {
+		return r.retryMaxDelay
+	}
+	return delay
+}
+
+// transientError reports whether a failed task call is worth retrying: the executor timeout or
+// an error matching transientErrorRe. rate limits (PatternMatchError) and cancellation are never transient.
+func transientError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var patternErr *executor.PatternMatchError
+	if errors.As(err, &patternErr) {
+		return false
+	}
+	return errors.Is(err, executor.ErrTimeout) || transientErrorRe.MatchString(err.Error())
+}
@@ -0,0 +1,68 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+func TestRunner_retryDelay(t *testing.T) {
+	tests := []struct {
+		name      string
+		base, max time.Duration
+		want      []time.Duration // delays of retries 1, 2, ...
+	}{
+		{name: "doubles without limit", base: time.Second, want: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}},
+		{name: "capped by max", base: time.Second, max: 3 * time.Second,
+			want: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}},
+		{name: "base above max", base: 10 * time.Second, max: 3 * time.Second, want: []time.Duration{3 * time.Second}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Runner{retryBaseDelay: tc.base, retryMaxDelay: tc.max}
+			for i, want := range tc.want {
+				assert.Equal(t, want, r.retryDelay(i+1), "retry %d", i+1)
+			}
+		})
+	}
+
+	r := &Runner{retryBaseDelay: time.Second, retryMaxDelay: time.Minute}
+	assert.Equal(t, time.Minute, r.retryDelay(1000), "many retries don't overflow")
+}
+
+func TestTransientError(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{name: "executor timeout", err: fmt.Errorf("claude: %w", executor.ErrTimeout), want: true},
+		{name: "connection reset", err: errors.New("stream read: read tcp: connection reset by peer"), want: true},
+		{name: "i/o timeout", err: errors.New("dial tcp 1.2.3.4:443: i/o timeout"), want: true},
+		{name: "api overloaded", err: errors.New("claude exited with error: API Error: Overloaded"), want: true},
+		{name: "unexpected eof", err: errors.New("stream read: unexpected EOF"), want: true},
+		{name: "plain exit status", err: errors.New("claude exited with error: exit status 1")},
+		{name: "rate limit pattern", err: &executor.PatternMatchError{Pattern: "connection reset", HelpCmd: "claude /usage"}},
+		{name: "canceled", err: context.Canceled},
+		{name: "canceled context", ctx: canceled, err: errors.New("connection reset")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := tc.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			assert.Equal(t, tc.want, transientError(ctx, tc.err))
+		})
+	}
+}
@@ -2,6 +2,7 @@
 package processor
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -12,12 +13,17 @@ import (
 
 	"github.com/umputun/ralphex/pkg/config"
 	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/notify"
+	"github.com/umputun/ralphex/pkg/plan"
 	"github.com/umputun/ralphex/pkg/status"
 )
 
 // DefaultIterationDelay is the pause between iterations to allow system to settle.
 const DefaultIterationDelay = 2 * time.Second
 
+// DefaultCodexRetryCount is the number of retries of a failed external review call.
+const DefaultCodexRetryCount = 2
+
 const (
 	minReviewIterations    = 3    // minimum claude review iterations
 	reviewIterationDivisor = 10   // review iterations = max_iterations / divisor
@@ -28,38 +34,74 @@ const (
 	maxCodexSummaryLen     = 5000 // max chars for codex output summary
 )
 
+// agent backends selectable with agent_backend
+const (
+	AgentClaude = "claude"
+	AgentGemini = "gemini"
+	AgentOllama = "ollama"
+)
+
 // Mode represents the execution mode.
 type Mode string
 
 const (
-	ModeFull      Mode = "full"       // full execution: tasks + reviews + codex
-	ModeReview    Mode = "review"     // skip tasks, run full review pipeline
-	ModeCodexOnly Mode = "codex-only" // skip tasks and first review, run only codex loop
-	ModeTasksOnly Mode = "tasks-only" // run only task phase, skip all reviews
-	ModePlan      Mode = "plan"       // interactive plan creation mode
+	ModeFull        Mode = "full"         // full execution: tasks + reviews + codex
+	ModeReview      Mode = "review"       // skip tasks, run full review pipeline
+	ModeCodexOnly   Mode = "codex-only"   // skip tasks and first review, run only codex loop
+	ModeTasksOnly   Mode = "tasks-only"   // run only task phase, skip all reviews
+	ModeTasksReview Mode = "tasks-review" // tasks + claude reviews, no external review and no finalize
+	ModePlan        Mode = "plan"         // interactive plan creation mode
 )
 
 // Config holds runner configuration.
 type Config struct {
-	PlanFile         string         // path to plan file (required for full mode)
-	PlanDescription  string         // plan description for interactive plan creation mode
-	ProgressPath     string         // path to progress file
-	Mode             Mode           // execution mode
-	MaxIterations    int            // maximum iterations for task phase
-	Debug            bool           // enable debug output
-	NoColor          bool           // disable color output
-	IterationDelayMs int            // delay between iterations in milliseconds
-	TaskRetryCount   int            // number of times to retry failed tasks
-	CodexEnabled     bool           // whether codex review is enabled
-	FinalizeEnabled  bool           // whether finalize step is enabled
-	DefaultBranch    string         // default branch name (detected from repo)
-	AppConfig        *config.Config // full application config (for executors and prompts)
+	PlanFile            string         // path to plan file (required for full mode)
+	PlanDescription     string         // plan description for interactive plan creation mode
+	PlanTemplate        string         // plan template content for interactive plan creation, empty for none
+	ProgressPath        string         // path to progress file
+	Mode                Mode           // execution mode
+	MaxIterations       int            // maximum iterations for task phase
+	Debug               bool           // enable debug output
+	Trace               bool           // also print the raw output of the executors, see rawOutputHandler
+	NoColor             bool           // disable color output
+	IterationDelayMs    int            // delay between iterations in milliseconds
+	ExecutorTimeoutMs   int            // timeout for each individual executor call in milliseconds, 0 means no limit
+	ClaudeTimeoutMin    int            // timeout for each claude call in minutes, 0 uses ExecutorTimeoutMs
+	CodexTimeoutMin     int            // timeout for each external review call in minutes, 0 uses ExecutorTimeoutMs
+	TaskRetryCount      int            // number of times to retry failed tasks
+	CodexRetryCount     int            // number of times to retry a failed external review call
+	RetryBaseDelayMs    int            // delay before the first retry in milliseconds, 0 uses IterationDelayMs
+	RetryMaxDelayMs     int            // upper bound of the retry delay in milliseconds, 0 means no limit
+	StallIterations     int            // identical task iterations without commits that abort the run, 0 disables
+	TaskCommitRatio     float64        // minimum commits per completed task at the end of the task phase
+	StrictTaskVerify    bool           // reopen tasks checked off without a commit when the commit check fails
+	MaxReviewIterations int            // maximum iterations of each claude review loop, 0 derives it from MaxIterations
+	MaxPlanIterations   int            // maximum plan creation iterations, 0 derives it from MaxIterations
+	CodexEnabled        bool           // whether codex review is enabled
+	FinalizeEnabled     bool           // whether finalize step is enabled
+	DefaultBranch       string         // default branch name (detected from repo)
+	BaseBranch          string         // branch reviews compare against, empty uses DefaultBranch
+	DiffRange           string         // revision range to review, e.g. v1.2..HEAD, empty is BaseBranch...HEAD
+	Branch              string         // current branch name, passed to hook scripts
+	Resume              bool           // skip stages completed before the checkpoint, if it matches plan file and mode
+	AppConfig           *config.Config // full application config (for executors and prompts)
+	Agents              []string       // agents expanded from {{agent:name}} references, others are dropped; nil expands all
+	Findings            []Findings     // review findings evaluated instead of running the external review tool, nil runs it
+}
+
+// Findings is a file of review findings, e.g. from a human PR review, passed with --findings.
+type Findings struct {
+	File    string // path of the findings file
+	Content string // file content
 }
 
 //go:generate moq -out mocks/executor.go -pkg mocks -skip-ensure -fmt goimports . Executor
 //go:generate moq -out mocks/logger.go -pkg mocks -skip-ensure -fmt goimports . Logger
 //go:generate moq -out mocks/input_collector.go -pkg mocks -skip-ensure -fmt goimports . InputCollector
 //go:generate moq -out mocks/git_checker.go -pkg mocks -skip-ensure -fmt goimports . GitChecker
+//go:generate moq -out mocks/metrics.go -pkg mocks -skip-ensure -fmt goimports . Metrics
+//go:generate moq -out mocks/notifier.go -pkg mocks -skip-ensure -fmt goimports . Notifier
+//go:generate moq -out mocks/change_reporter.go -pkg mocks -skip-ensure -fmt goimports . ChangeReporter
 
 // Executor runs CLI commands and returns results.
 type Executor interface {
@@ -72,6 +114,7 @@ type Logger interface {
 	PrintRaw(format string, args ...any)
 	PrintSection(section status.Section)
 	PrintAligned(text string)
+	PrintTool(text string)
 	LogQuestion(question string, options []string)
 	LogAnswer(answer string)
 	LogDraftReview(action string, feedback string)
@@ -81,42 +124,120 @@ type Logger interface {
 // InputCollector provides interactive input collection for plan creation.
 type InputCollector interface {
 	AskQuestion(ctx context.Context, question string, options []string) (string, error)
+	AskText(ctx context.Context, question, defaultAnswer string, multiline bool) (string, error)
 	AskDraftReview(ctx context.Context, question string, planContent string) (action string, feedback string, err error)
 }
 
-// GitChecker provides git state inspection for the review loop.
+// GitChecker provides git state inspection for the review loop, the reviewed files, the task commit check
+// and the finalize diff summary.
 type GitChecker interface {
 	HeadHash() (string, error)
+	DiffStat(from, to string) (string, error)
+	ChangedFiles(base string) ([]string, error)
+	CommitSubjects(from, to string) ([]string, error)
+}
+
+// Metrics receives counters of the run for monitoring, labeled with the current phase and the run mode.
+type Metrics interface {
+	Iteration(phase status.Phase, mode string)
+	ExecutorCall(phase status.Phase, mode string, d time.Duration, failed bool)
+	RateLimitHit(phase status.Phase, mode string)
+}
+
+// Notifier sends event notifications when the run starts, aborts on a failure and completes.
+type Notifier interface {
+	Notify(title, body string, level notify.Level) error
 }
 
 // Runner orchestrates the execution loop.
 type Runner struct {
-	cfg            Config
-	log            Logger
-	claude         Executor
-	codex          Executor
-	custom         *executor.CustomExecutor
-	git            GitChecker
-	inputCollector InputCollector
-	phaseHolder    *status.PhaseHolder
-	iterationDelay time.Duration
-	taskRetryCount int
+	cfg             Config
+	log             Logger
+	claude          Executor
+	codex           Executor
+	custom          *executor.CustomExecutor
+	gemini          Executor // external gemini reviewer, nil unless external_review_tool = gemini
+	git             GitChecker
+	inputCollector  InputCollector
+	phaseHolder     *status.PhaseHolder
+	pauseHolder     *status.PauseHolder // nil if the run can't be paused
+	stopHolder      *status.StopHolder  // stop after the current iteration, requested by Ctrl+C or RequestStop
+	metrics         Metrics             // nil if metrics are not collected
+	notifier        Notifier            // nil if event notifications are disabled
+	iterationDelay  time.Duration
+	claudeTimeout   time.Duration // per-call limit of claude calls, 0 means no limit
+	reviewTimeout   time.Duration // per-call limit of external review calls, 0 means no limit
+	taskRetryCount  int
+	codexRetryCount int            // retries of a failed external review call
+	retryBaseDelay  time.Duration  // delay before the first task or review retry, doubling for each next one
+	retryMaxDelay   time.Duration  // upper bound of the retry delay, 0 means no limit
+	codexFilter     findingFilter  // drops codex findings before claude evaluation
+	usage           usageTracker   // token usage per phase
+	changeRep       ChangeReporter // optional, reports commits and files changed per stage, see changes.go
+	changes         changeTracker  // changes per stage, recorded only with changeRep
+	startHead       string         // HEAD at the start of the run, for {{DIFF_SUMMARY}}; empty if not captured
+
+	// checkpoint state, see checkpoint.go
+	checkpointPath string // empty if checkpoints are not used for this run
+	stage          Stage  // current pipeline stage
+	iteration      int    // current iteration within the stage
+	resumeFrom     Stage  // stage to resume from, empty to run all stages
 }
 
 // New creates a new Runner with the given configuration and shared phase holder.
 // If codex is enabled but the binary is not found in PATH, it is automatically disabled with a warning.
 func New(cfg Config, log Logger, holder *status.PhaseHolder) *Runner {
-	// build claude executor with config values
-	claudeExec := &executor.ClaudeExecutor{
-		OutputHandler: func(text string) {
-			log.PrintAligned(text)
-		},
-		Debug: cfg.Debug,
-	}
-	if cfg.AppConfig != nil {
-		claudeExec.Command = cfg.AppConfig.ClaudeCommand
-		claudeExec.Args = cfg.AppConfig.ClaudeArgs
-		claudeExec.ErrorPatterns = cfg.AppConfig.ClaudeErrorPatterns
+	regexPatterns := executorErrorPatterns(cfg.AppConfig)
+	remote := commandRemote(cfg.AppConfig)
+	raw := rawOutputHandler(cfg, log)
+
+	// build the primary agent executor, claude unless agent_backend selects another one
+	var agentExec Executor
+	switch {
+	case cfg.AppConfig != nil && cfg.AppConfig.AgentBackend == AgentGemini:
+		agentExec = &executor.GeminiExecutor{
+			Command:       cfg.AppConfig.GeminiCommand,
+			Args:          cfg.AppConfig.GeminiArgs,
+			ErrorPatterns: cfg.AppConfig.GeminiErrorPatterns,
+			RegexPatterns: regexPatterns,
+			OutputHandler: func(text string) {
+				log.PrintAligned(text)
+			},
+			RawHandler: raw,
+			Debug:      cfg.Debug,
+		}
+	case cfg.AppConfig != nil && cfg.AppConfig.AgentBackend == AgentOllama:
+		agentExec = &executor.OllamaExecutor{
+			URL:           cfg.AppConfig.OllamaURL,
+			Model:         cfg.AppConfig.OllamaModel,
+			InjectSignals: cfg.AppConfig.OllamaSignalPrompt,
+			RegexPatterns: regexPatterns,
+			OutputHandler: func(text string) {
+				log.PrintAligned(text)
+			},
+			RawHandler: raw,
+			Debug:      cfg.Debug,
+		}
+	default:
+		claudeExec := &executor.ClaudeExecutor{
+			OutputHandler: func(text string) {
+				log.PrintAligned(text)
+			},
+			ToolHandler: func(text string) {
+				log.PrintTool(text)
+			},
+			RawHandler: raw,
+			Debug:      cfg.Debug,
+		}
+		if cfg.AppConfig != nil {
+			claudeExec.Command = cfg.AppConfig.ClaudeCommand
+			claudeExec.Args = cfg.AppConfig.ClaudeArgs
+			claudeExec.OutputFormat = cfg.AppConfig.ClaudeOutputFormat
+			claudeExec.ErrorPatterns = cfg.AppConfig.ClaudeErrorPatterns
+			claudeExec.RegexPatterns = regexPatterns
+			claudeExec.Remote = remote
+		}
+		agentExec = claudeExec
 	}
 
 	// build codex executor with config values
@@ -124,7 +245,8 @@ func New(cfg Config, log Logger, holder *status.PhaseHolder) *Runner {
 		OutputHandler: func(text string) {
 			log.PrintAligned(text)
 		},
-		Debug: cfg.Debug,
+		RawHandler: raw,
+		Debug:      cfg.Debug,
 	}
 	if cfg.AppConfig != nil {
 		codexExec.Command = cfg.AppConfig.CodexCommand
@@ -133,6 +255,8 @@ func New(cfg Config, log Logger, holder *status.PhaseHolder) *Runner {
 		codexExec.TimeoutMs = cfg.AppConfig.CodexTimeoutMs
 		codexExec.Sandbox = cfg.AppConfig.CodexSandbox
 		codexExec.ErrorPatterns = cfg.AppConfig.CodexErrorPatterns
+		codexExec.RegexPatterns = regexPatterns
+		codexExec.Remote = remote
 	}
 
 	// build custom executor if custom review script is configured
@@ -144,23 +268,86 @@ func New(cfg Config, log Logger, holder *status.PhaseHolder) *Runner {
 				log.PrintAligned(text)
 			},
 			ErrorPatterns: cfg.AppConfig.CodexErrorPatterns, // reuse codex error patterns
+			RegexPatterns: regexPatterns,
+		}
+	}
+
+	// build gemini reviewer if gemini is the external review tool, it shares the gemini backend settings
+	var geminiExec Executor
+	if cfg.AppConfig != nil && cfg.AppConfig.ExternalReviewTool == "gemini" {
+		geminiExec = &executor.GeminiExecutor{
+			Command:       cfg.AppConfig.GeminiCommand,
+			Args:          cfg.AppConfig.GeminiArgs,
+			ErrorPatterns: cfg.AppConfig.GeminiErrorPatterns,
+			RegexPatterns: regexPatterns,
+			OutputHandler: func(text string) {
+				log.PrintAligned(text)
+			},
+			RawHandler: raw,
+			Debug:      cfg.Debug,
+		}
+	}
+
+	// auto-disable external review if gemini is selected but not installed
+	if cfg.CodexEnabled && geminiExec != nil {
+		geminiCmd := cfg.AppConfig.GeminiCommand
+		if geminiCmd == "" {
+			geminiCmd = "gemini"
+		}
+		if _, err := exec.LookPath(geminiCmd); err != nil {
+			log.Print("warning: gemini not found (%s: %v), disabling external review phase", geminiCmd, err)
+			cfg.CodexEnabled = false
 		}
 	}
 
 	// auto-disable codex if the binary is not installed AND we need codex
-	// (skip this check if using custom external review tool or external review is disabled)
-	if cfg.CodexEnabled && needsCodexBinary(cfg.AppConfig) {
+	// (skip this check if using custom external review tool, external review is disabled or findings replace it)
+	if cfg.CodexEnabled && cfg.Findings == nil && needsCodexBinary(cfg.AppConfig) {
 		codexCmd := codexExec.Command
 		if codexCmd == "" {
 			codexCmd = "codex"
 		}
-		if _, err := exec.LookPath(codexCmd); err != nil {
+		if err := lookPath(remote, codexCmd); err != nil {
 			log.Print("warning: codex not found (%s: %v), disabling codex review phase", codexCmd, err)
 			cfg.CodexEnabled = false
 		}
 	}
 
-	return NewWithExecutors(cfg, log, claudeExec, codexExec, customExec, holder)
+	r := NewWithExecutors(cfg, log, agentExec, codexExec, customExec, holder)
+	r.gemini = geminiExec
+	return r
+}
+
+// rawOutputHandler returns the handler printing raw executor output lines at trace level, nil otherwise.
+// lines go through log once, so a dashboard wrapping it broadcasts each of them once too.
+// terminal escape sequences are removed with colors disabled.
+func rawOutputHandler(cfg Config, log Logger) func(line string) {
+	if !cfg.Trace {
+		return nil
+	}
+	return func(line string) {
+		if cfg.NoColor {
+			line = executor.StripANSI(line)
+		}
+		log.PrintRaw("[trace] %s\n", line)
+	}
+}
+
+// commandRemote returns the wrapper claude and codex run through, nil if claude_command_wrapper is not set.
+func commandRemote(appCfg *config.Config) *executor.Remote {
+	if appCfg == nil || appCfg.ClaudeCommandWrapper == "" {
+		return nil
+	}
+	return &executor.Remote{Wrapper: appCfg.ClaudeCommandWrapper, PathMap: appCfg.RemotePathMap}
+}
+
+// lookPath checks that the command is available, locally or through the wrapper if remote is set.
+func lookPath(remote *executor.Remote, name string) error {
+	if remote != nil {
+		return remote.LookPath(name) //nolint:wrapcheck // error already names the wrapper
+	}
+	_, err := exec.LookPath(name)
+	return err //nolint:wrapcheck // caller adds the command name
 }
 
 // NewWithExecutors creates a new Runner with custom executors (for testing).
@@ -180,30 +367,140 @@ func NewWithExecutors(cfg Config, log Logger, claude, codex Executor, custom *ex
 		retryCount = cfg.TaskRetryCount
 	}
 
-	return &Runner{
-		cfg:            cfg,
-		log:            log,
-		claude:         claude,
-		codex:          codex,
-		custom:         custom,
-		phaseHolder:    holder,
-		iterationDelay: iterDelay,
-		taskRetryCount: retryCount,
+	// same for the external review retries, 0 set explicitly disables them
+	codexRetryCount := DefaultCodexRetryCount
+	if cfg.AppConfig != nil && cfg.AppConfig.CodexRetryCountSet {
+		codexRetryCount = cfg.CodexRetryCount
+	} else if cfg.CodexRetryCount > 0 {
+		codexRetryCount = cfg.CodexRetryCount
+	}
+
+	r := &Runner{
+		cfg:             cfg,
+		log:             log,
+		claude:          claude,
+		codex:           codex,
+		custom:          custom,
+		phaseHolder:     holder,
+		stopHolder:      &status.StopHolder{},
+		iterationDelay:  iterDelay,
+		claudeTimeout:   callTimeout(cfg.ClaudeTimeoutMin, cfg.ExecutorTimeoutMs),
+		reviewTimeout:   callTimeout(cfg.CodexTimeoutMin, cfg.ExecutorTimeoutMs),
+		taskRetryCount:  retryCount,
+		codexRetryCount: codexRetryCount,
+		retryBaseDelay:  iterDelay,
+		retryMaxDelay:   time.Duration(max(cfg.RetryMaxDelayMs, 0)) * time.Millisecond,
+		codexFilter:     newCodexFilter(cfg.AppConfig, log),
+	}
+	if cfg.RetryBaseDelayMs > 0 {
+		r.retryBaseDelay = time.Duration(cfg.RetryBaseDelayMs) * time.Millisecond
+	}
+
+	// checkpoints are written on each phase transition for modes with several stages.
+	// tasks-only resumes naturally from plan checkboxes, plan mode is interactive.
+	if cfg.ProgressPath != "" && holder != nil && modeHasStages(cfg.Mode) {
+		r.checkpointPath = CheckpointPath(cfg.ProgressPath)
+		holder.OnChange(r.onPhaseChange)
 	}
+	return r
 }
 
-// SetInputCollector sets the input collector for plan creation mode.
+// SetInputCollector sets the input collector for plan creation mode and for plan_change_action = ask.
 func (r *Runner) SetInputCollector(c InputCollector) {
 	r.inputCollector = c
 }
 
+// SetPauseHolder sets the pause control shared with the signal handler and the web dashboard.
+// task, review and external review loops wait at the top of each iteration while it is paused.
+func (r *Runner) SetPauseHolder(h *status.PauseHolder) {
+	r.pauseHolder = h
+}
+
+// SetStopHolder sets the stop control shared with the signal handler and the web dashboard.
+// all loops check it before each iteration and return ErrStopped once a stop was requested.
+func (r *Runner) SetStopHolder(h *status.StopHolder) {
+	r.stopHolder = h
+}
+
+// RequestStop asks the run to stop after its current iteration, returns false if it is not running
+// or a stop was already requested.
+func (r *Runner) RequestStop() bool {
+	return r.stopHolder.Request()
+}
+
+// SetMetrics sets the collector of run metrics, served by the web dashboard.
+func (r *Runner) SetMetrics(m Metrics) {
+	r.metrics = m
+}
+
+// SetNotifier sets the event notifier, see notifier option.
+func (r *Runner) SetNotifier(n Notifier) {
+	r.notifier = n
+}
+
 // SetGitChecker sets the git checker for no-commit detection in review loops.
 func (r *Runner) SetGitChecker(g GitChecker) {
 	r.git = g
 }
 
 // Run executes the main loop based on configured mode.
+// with Config.Resume, stages completed before the saved checkpoint are skipped.
+// the checkpoint is removed once the run completes successfully.
 func (r *Runner) Run(ctx context.Context) error {
+	r.stopHolder.Begin()
+	defer r.stopHolder.End()
+	// a paused run can't reach its next iteration, resume it so the stop takes effect
+	r.stopHolder.OnRequest(func() {
+		if r.pauseHolder != nil {
+			r.pauseHolder.Resume()
+		}
+	})
+
+	if r.cfg.Resume {
+		r.loadResumeStage()
+	}
+	if r.cfg.FinalizeEnabled && r.cfg.AppConfig != nil && strings.Contains(r.cfg.AppConfig.FinalizePrompt, "{{DIFF_SUMMARY}}") {
+		r.startHead = r.headHash()
+	}
+	r.notify("ralphex started", "", notify.LevelInfo)
+	err := r.runMode(ctx)
+	r.finishStageChanges()
+	if err != nil {
+		details := "error: " + err.Error()
+		if phase := r.phaseHolder.Get(); phase != "" {
+			details = fmt.Sprintf("phase: %s\n%s", phase, details)
+		}
+		r.notify("ralphex failed", details, notify.LevelError)
+		return err
+	}
+	r.removeCheckpoint()
+	r.notify("ralphex completed", "", notify.LevelInfo)
+	return nil
+}
+
+// notify sends an event notification about the run, best-effort: failures are logged as warnings.
+// the body starts with the mode, plan file and branch of the run, followed by details if any.
+func (r *Runner) notify(title, details string, level notify.Level) {
+	if r.notifier == nil {
+		return
+	}
+	lines := []string{"mode: " + string(r.cfg.Mode)}
+	if r.cfg.PlanFile != "" {
+		lines = append(lines, "plan: "+r.cfg.PlanFile)
+	}
+	if r.cfg.Branch != "" {
+		lines = append(lines, "branch: "+r.cfg.Branch)
+	}
+	if details != "" {
+		lines = append(lines, details)
+	}
+	if err := r.notifier.Notify(title, strings.Join(lines, "\n"), level); err != nil {
+		r.log.Print("warning: notification failed: %v", err)
+	}
+}
+
+// runMode dispatches to the pipeline of the configured mode.
+func (r *Runner) runMode(ctx context.Context) error {
 	switch r.cfg.Mode {
 	case ModeFull:
 		return r.runFull(ctx)
@@ -213,6 +510,8 @@ func (r *Runner) Run(ctx context.Context) error {
 		return r.runCodexOnly(ctx)
 	case ModeTasksOnly:
 		return r.runTasksOnly(ctx)
+	case ModeTasksReview:
+		return r.runTasksReview(ctx)
 	case ModePlan:
 		return r.runPlanCreation(ctx)
 	default:
@@ -220,6 +519,41 @@ func (r *Runner) Run(ctx context.Context) error {
 	}
 }
 
+// PlannedPhases returns the ordered list of phases Run would go through for the configured mode.
+// the external review phase is omitted when external review is disabled, and finalize is included
+// only when enabled. consecutive review loops are reported as a single review phase.
+// used by --dry-run to render the pipeline without invoking executors.
+func (r *Runner) PlannedPhases() []status.Phase {
+	var phases []status.Phase
+	switch r.cfg.Mode {
+	case ModeFull:
+		phases = append(phases, status.PhaseTask, status.PhaseReview)
+	case ModeReview:
+		phases = append(phases, status.PhaseReview)
+	case ModeCodexOnly:
+	case ModeTasksOnly:
+		return []status.Phase{status.PhaseTask}
+	case ModeTasksReview:
+		return []status.Phase{status.PhaseTask, status.PhaseReview}
+	case ModePlan:
+		return []status.Phase{status.PhasePlan}
+	default:
+		return nil
+	}
+
+	// shared codex → post-codex review → finalize tail, mirrors runCodexAndPostReview
+	if r.ExternalReviewTool() != "none" {
+		phases = append(phases, status.PhaseCodex)
+	}
+	if len(phases) == 0 || phases[len(phases)-1] != status.PhaseReview {
+		phases = append(phases, status.PhaseReview)
+	}
+	if r.cfg.FinalizeEnabled {
+		phases = append(phases, status.PhaseFinalize)
+	}
+	return phases
+}
+
 // runFull executes the complete pipeline: tasks → review → codex → review.
 func (r *Runner) runFull(ctx context.Context) error {
 	if r.cfg.PlanFile == "" {
@@ -227,24 +561,21 @@ func (r *Runner) runFull(ctx context.Context) error {
 	}
 
 	// phase 1: task execution
-	r.phaseHolder.Set(status.PhaseTask)
-	r.log.PrintRaw("starting task execution phase\n")
-
-	if err := r.runTaskPhase(ctx); err != nil {
-		return fmt.Errorf("task phase: %w", err)
-	}
-
-	// phase 2: first review pass - address ALL findings
-	r.phaseHolder.Set(status.PhaseReview)
-	r.log.PrintSection(status.NewGenericSection("claude review 0: all findings"))
+	if !r.skipStage(StageTask) {
+		r.enterStage(StageTask, status.PhaseTask)
+		if err := r.runPreHook(ctx, "pre-task", r.cfg.AppConfig.PreTaskHook); err != nil {
+			return err
+		}
+		r.log.PrintRaw("starting task execution phase\n")
 
-	if err := r.runClaudeReview(ctx, r.replacePromptVariables(r.cfg.AppConfig.ReviewFirstPrompt)); err != nil {
-		return fmt.Errorf("first review: %w", err)
+		if err := r.runTaskPhase(ctx); err != nil {
+			return fmt.Errorf("task phase: %w", err)
+		}
 	}
 
-	// phase 2.1: claude review loop (critical/major) before codex
-	if err := r.runClaudeReviewLoop(ctx); err != nil {
-		return fmt.Errorf("pre-codex review loop: %w", err)
+	// phase 2: first review pass and critical/major review loop
+	if err := r.runPreCodexReview(ctx); err != nil {
+		return err
 	}
 
 	// phase 2.5+3: codex → post-codex review → finalize
@@ -258,17 +589,13 @@ func (r *Runner) runFull(ctx context.Context) error {
 
 // runReviewOnly executes only the review pipeline: review → codex → review.
 func (r *Runner) runReviewOnly(ctx context.Context) error {
-	// phase 1: first review
-	r.phaseHolder.Set(status.PhaseReview)
-	r.log.PrintSection(status.NewGenericSection("claude review 0: all findings"))
-
-	if err := r.runClaudeReview(ctx, r.replacePromptVariables(r.cfg.AppConfig.ReviewFirstPrompt)); err != nil {
-		return fmt.Errorf("first review: %w", err)
+	if empty, err := r.reviewRangeEmpty(); err != nil || empty {
+		return err
 	}
 
-	// phase 1.1: claude review loop (critical/major) before codex
-	if err := r.runClaudeReviewLoop(ctx); err != nil {
-		return fmt.Errorf("pre-codex review loop: %w", err)
+	// phase 1: first review pass and critical/major review loop
+	if err := r.runPreCodexReview(ctx); err != nil {
+		return err
 	}
 
 	// phase 2+3: codex → post-codex review → finalize
@@ -282,6 +609,10 @@ func (r *Runner) runReviewOnly(ctx context.Context) error {
 
 // runCodexOnly executes only the codex pipeline: codex → review → finalize.
 func (r *Runner) runCodexOnly(ctx context.Context) error {
+	if empty, err := r.reviewRangeEmpty(); err != nil || empty {
+		return err
+	}
+
 	if err := r.runCodexAndPostReview(ctx); err != nil {
 		return err
 	}
@@ -290,26 +621,87 @@ func (r *Runner) runCodexOnly(ctx context.Context) error {
 	return nil
 }
 
+// reviewRangeEmpty reports whether the reviewed range has no changed files, e.g. no commits ahead of the base branch.
+// review-only modes skip all phases then instead of spending claude calls on an empty diff.
+// returns false without a git checker, the range can't be inspected.
+func (r *Runner) reviewRangeEmpty() (bool, error) {
+	if r.git == nil {
+		return false, nil
+	}
+	files, err := r.git.ChangedFiles(r.getDiffRange())
+	if err != nil {
+		return false, fmt.Errorf("list files to review: %w", err)
+	}
+	if len(files) > 0 {
+		return false, nil
+	}
+	r.log.Print("no changes in %s, nothing to review, skipping review phases", r.getDiffRange())
+	return true, nil
+}
+
+// runPreCodexReview runs the first review pass (address ALL findings) followed by
+// the claude review loop (critical/major) before codex. shared by runFull and runReviewOnly.
+func (r *Runner) runPreCodexReview(ctx context.Context) error {
+	if r.skipStage(StageReview) {
+		return nil
+	}
+
+	r.enterStage(StageReview, status.PhaseReview)
+	if err := r.checkStop(); err != nil {
+		return err
+	}
+	r.log.PrintSection(status.NewGenericSection("claude review 0: all findings"))
+
+	prompt := r.replaceReviewPromptVariables(r.cfg.AppConfig.ReviewFirstPrompt, r.cfg.AppConfig.ReviewFirstAgents)
+	if err := r.runClaudeReview(ctx, prompt); err != nil {
+		return fmt.Errorf("first review: %w", err)
+	}
+
+	if err := r.runClaudeReviewLoop(ctx); err != nil {
+		return fmt.Errorf("pre-codex review loop: %w", err)
+	}
+	return nil
+}
+
 // runCodexAndPostReview runs the shared codex → post-codex claude review → finalize pipeline.
 // used by runFull, runReviewOnly, and runCodexOnly to avoid duplicating this sequence.
 func (r *Runner) runCodexAndPostReview(ctx context.Context) error {
-	// codex external review loop
-	r.phaseHolder.Set(status.PhaseCodex)
-	r.log.PrintSection(status.NewGenericSection("codex external review"))
-
-	if err := r.runCodexLoop(ctx); err != nil {
-		return fmt.Errorf("codex loop: %w", err)
+	// codex external review loop, or evaluation of the supplied findings
+	if !r.skipStage(StageCodex) {
+		r.enterStage(StageCodex, status.PhaseCodex)
+		if r.cfg.Findings != nil {
+			r.log.PrintSection(status.NewGenericSection("review findings evaluation"))
+			if err := r.runFindingsEvaluation(ctx); err != nil {
+				return fmt.Errorf("findings evaluation: %w", err)
+			}
+		} else {
+			r.log.PrintSection(status.NewGenericSection("codex external review"))
+			if err := r.runCodexLoop(ctx); err != nil {
+				return fmt.Errorf("codex loop: %w", err)
+			}
+		}
 	}
 
 	// claude review loop (critical/major) after codex
-	r.phaseHolder.Set(status.PhaseReview)
+	if !r.skipStage(StagePostReview) {
+		r.enterStage(StagePostReview, status.PhaseReview)
 
-	if err := r.runClaudeReviewLoop(ctx); err != nil {
-		return fmt.Errorf("post-codex review loop: %w", err)
+		if err := r.runClaudeReviewLoop(ctx); err != nil {
+			return fmt.Errorf("post-codex review loop: %w", err)
+		}
+		if err := r.runPostHook(ctx, "post-review", r.cfg.AppConfig.PostReviewHook); err != nil {
+			return err
+		}
 	}
 
 	// optional finalize step (best-effort, but propagates context cancellation)
-	return r.runFinalize(ctx)
+	if err := r.runFinalize(ctx); err != nil {
+		return err
+	}
+	if !r.cfg.FinalizeEnabled {
+		return nil
+	}
+	return r.runPostHook(ctx, "post-finalize", r.cfg.AppConfig.PostFinalizeHook)
 }
 
 // runTasksOnly executes only task phase, skipping all reviews.
@@ -319,6 +711,10 @@ func (r *Runner) runTasksOnly(ctx context.Context) error {
 	}
 
 	r.phaseHolder.Set(status.PhaseTask)
+	r.startStageChanges(StageTask)
+	if err := r.runPreHook(ctx, "pre-task", r.cfg.AppConfig.PreTaskHook); err != nil {
+		return err
+	}
 	r.log.PrintRaw("starting task execution phase\n")
 
 	if err := r.runTaskPhase(ctx); err != nil {
@@ -329,33 +725,126 @@ func (r *Runner) runTasksOnly(ctx context.Context) error {
 	return nil
 }
 
+// runTasksReview executes tasks followed by claude reviews: tasks → review → review.
+// external review and finalize never run in this mode, regardless of configuration.
+func (r *Runner) runTasksReview(ctx context.Context) error {
+	if r.cfg.PlanFile == "" {
+		return errors.New("plan file required for tasks-review mode")
+	}
+
+	if !r.skipStage(StageTask) {
+		r.enterStage(StageTask, status.PhaseTask)
+		if err := r.runPreHook(ctx, "pre-task", r.cfg.AppConfig.PreTaskHook); err != nil {
+			return err
+		}
+		r.log.PrintRaw("starting task execution phase\n")
+
+		if err := r.runTaskPhase(ctx); err != nil {
+			return fmt.Errorf("task phase: %w", err)
+		}
+	}
+
+	// first review pass and critical/major review loop
+	if err := r.runPreCodexReview(ctx); err != nil {
+		return err
+	}
+
+	// second claude review loop, in place of external review and the loop after it
+	if !r.skipStage(StagePostReview) {
+		r.enterStage(StagePostReview, status.PhaseReview)
+
+		if err := r.runClaudeReviewLoop(ctx); err != nil {
+			return fmt.Errorf("post-review loop: %w", err)
+		}
+		if err := r.runPostHook(ctx, "post-review", r.cfg.AppConfig.PostReviewHook); err != nil {
+			return err
+		}
+	}
+
+	r.log.Print("task and review phases completed successfully")
+	return nil
+}
+
 // runTaskPhase executes tasks until completion or max iterations.
 // executes ONE Task section per iteration.
 func (r *Runner) runTaskPhase(ctx context.Context) error {
 	prompt := r.replacePromptVariables(r.cfg.AppConfig.TaskPrompt)
 	retryCount := 0
+	stall := newStallDetector(r.cfg.StallIterations)
+	startItems := r.planCheckboxes()
+	var startHead string
+	if len(startItems) > 0 {
+		r.log.Print("plan progress: %d/%d done", plan.CountDone(startItems), len(startItems))
+		startHead = r.headHash()
+	}
+	var uncommitted []plan.Checkbox // items checked off in iterations that didn't move HEAD
+	planContent, err := r.readPlan()
+	if err != nil {
+		return fmt.Errorf("task phase: %w", err)
+	}
 
 	for i := 1; i <= r.cfg.MaxIterations; i++ {
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("task phase: %w", ctx.Err())
-		default:
+		if err := r.waitIfPaused(ctx); err != nil {
+			return fmt.Errorf("task phase: %w", err)
+		}
+		if err := r.checkStop(); err != nil {
+			return err
+		}
+		// the plan may have been edited between iterations, the agent's own edits are taken in below
+		content, changed, err := r.reconcilePlan(ctx, planContent)
+		if err != nil {
+			return fmt.Errorf("task phase: %w", err)
+		}
+		planContent = content
+		if changed {
+			prompt = r.replacePromptVariables(r.cfg.AppConfig.TaskPrompt)
 		}
 
+		r.startIteration(i)
 		r.log.PrintSection(status.NewTaskIterationSection(i))
 
-		result := r.claude.Run(ctx, prompt)
+		itemsBefore := r.planCheckboxes()
+		var headBefore string
+		if stall != nil || (r.git != nil && len(itemsBefore) > 0) {
+			headBefore = r.headHash()
+		}
+		result := r.runExecutor(ctx, r.claude.Run, prompt)
+		if content, readErr := r.readPlan(); readErr == nil {
+			planContent = content // a missing plan is reported before the next iteration
+		}
 		if result.Error != nil {
-			if err := r.handlePatternMatchError(result.Error, "claude"); err != nil {
+			// a stuck call or a network failure is retried like a FAILED signal, other errors abort the phase
+			if transientError(ctx, result.Error) && retryCount < r.taskRetryCount {
+				retryCount++
+				delay := r.retryDelay(retryCount)
+				r.log.Print("task failed: %v, retry %d/%d in %s", result.Error, retryCount, r.taskRetryCount, delay)
+				if err := r.sleepWithContext(ctx, delay); err != nil {
+					return fmt.Errorf("interrupted: %w", err)
+				}
+				continue
+			}
+			if err := r.handlePatternMatchError(result.Error, r.agentName()); err != nil {
 				return err
 			}
 			return fmt.Errorf("claude execution: %w", result.Error)
 		}
+		uncommitted = append(uncommitted, r.reportTaskProgress(itemsBefore, headBefore)...)
 
 		if result.Signal == SignalCompleted {
 			// verify plan actually has no uncompleted checkboxes
 			if r.hasUncompletedTasks() {
 				r.log.Print("warning: completion signal received but plan still has [ ] items, continuing...")
+				if stall != nil && stall.record(result.Output, headBefore, r.headHash()) {
+					return r.stalledError()
+				}
+				continue
+			}
+			reopened, err := r.verifyTaskCommits(startItems, startHead, uncommitted)
+			if err != nil {
+				return fmt.Errorf("task phase: %w", err)
+			}
+			if reopened != nil {
+				planContent, uncommitted = reopened, nil
 				continue
 			}
 			r.log.PrintRaw("\nall tasks completed, starting code review...\n")
@@ -364,9 +853,10 @@ func (r *Runner) runTaskPhase(ctx context.Context) error {
 
 		if result.Signal == SignalFailed {
 			if retryCount < r.taskRetryCount {
-				r.log.Print("task failed, retrying...")
 				retryCount++
-				if err := r.sleepWithContext(ctx, r.iterationDelay); err != nil {
+				delay := r.retryDelay(retryCount)
+				r.log.Print("task failed (FAILED signal), retry %d/%d in %s", retryCount, r.taskRetryCount, delay)
+				if err := r.sleepWithContext(ctx, delay); err != nil {
 					return fmt.Errorf("interrupted: %w", err)
 				}
 				continue
@@ -374,6 +864,10 @@ func (r *Runner) runTaskPhase(ctx context.Context) error {
 			return errors.New("task execution failed after retry (FAILED signal received)")
 		}
 
+		if stall != nil && stall.record(result.Output, headBefore, r.headHash()) {
+			return r.stalledError()
+		}
+
 		retryCount = 0
 		// continue with same prompt - it reads from plan file each time
 		if err := r.sleepWithContext(ctx, r.iterationDelay); err != nil {
@@ -384,11 +878,102 @@ func (r *Runner) runTaskPhase(ctx context.Context) error {
 	return fmt.Errorf("max iterations (%d) reached without completion", r.cfg.MaxIterations)
 }
 
+// planCheckboxes returns the checkboxes of the plan file, nil without a plan or if it can't be read.
+func (r *Runner) planCheckboxes() []plan.Checkbox {
+	if r.cfg.PlanFile == "" {
+		return nil
+	}
+	content, err := os.ReadFile(r.resolvePlanFilePath())
+	if err != nil {
+		return nil
+	}
+	return plan.ParseCheckboxes(string(content))
+}
+
+// reportTaskProgress logs plan items checked off during a task iteration with the running done/total counts.
+// items checked off while HEAD didn't move are flagged and returned, a completion without a commit is usually not real.
+func (r *Runner) reportTaskProgress(before []plan.Checkbox, headBefore string) []plan.Checkbox {
+	if len(before) == 0 {
+		return nil
+	}
+	after := r.planCheckboxes()
+	completed := plan.NewlyCompleted(before, after)
+	if len(completed) == 0 {
+		return nil
+	}
+	done := plan.CountDone(after) - len(completed)
+	for _, item := range completed {
+		done++
+		r.log.Print("task completed: %s (%d/%d done)", item.Text, done, len(after))
+	}
+	if headBefore != "" && r.headHash() == headBefore {
+		r.log.Print("warning: %d task(s) checked off without a new commit, the completion may not be real", len(completed))
+		return completed
+	}
+	return nil
+}
+
+// verifyTaskCommits checks at the end of the task phase that the tasks completed since its start are backed
+// by commits. no commits, or fewer than task_commit_ratio per completed task, is reported with a warning.
+// with strict_task_verification the items checked off in iterations without a commit are reopened in the plan
+// file and its new content is returned, so the task phase goes on; nil means the phase can complete.
+func (r *Runner) verifyTaskCommits(startItems []plan.Checkbox, startHead string, uncommitted []plan.Checkbox) ([]byte, error) {
+	if startHead == "" {
+		return nil, nil
+	}
+	completed := plan.NewlyCompleted(startItems, r.planCheckboxes())
+	if len(completed) == 0 {
+		return nil, nil
+	}
+	commits := 0
+	if head := r.headHash(); head != "" && head != startHead {
+		subjects, err := r.git.CommitSubjects(startHead, head)
+		if err != nil {
+			r.log.Print("warning: failed to count task phase commits: %v", err)
+			return nil, nil
+		}
+		commits = len(subjects)
+	}
+	if commits > 0 && float64(commits)/float64(len(completed)) >= r.cfg.TaskCommitRatio {
+		return nil, nil
+	}
+
+	r.log.PrintSection(status.NewGenericSection("task verification"))
+	r.log.Print("warning: %d task(s) completed with %d commit(s) since the task phase started, "+
+		"the plan may have been checked off without doing the work", len(completed), commits)
+	for _, item := range uncommitted {
+		r.log.Print("checked off without a commit: %s", item.Text)
+	}
+	if !r.cfg.StrictTaskVerify || len(uncommitted) == 0 {
+		return nil, nil
+	}
+
+	content, err := r.readPlan()
+	if err != nil {
+		return nil, err
+	}
+	reopened := []byte(plan.Reopen(string(content), uncommitted))
+	if bytes.Equal(reopened, content) {
+		return nil, nil // the items are no longer checked in the plan
+	}
+	if err := os.WriteFile(r.resolvePlanFilePath(), reopened, 0o600); err != nil {
+		return nil, fmt.Errorf("reopen tasks: %w", err)
+	}
+	r.log.Print("reopened %d task(s) checked off without a commit, continuing...", len(uncommitted))
+	return reopened, nil
+}
+
+// stalledError logs and returns ErrStalled for a task phase stuck in a loop.
+func (r *Runner) stalledError() error {
+	r.log.Print("error: the last %d iterations produced the same output without commits, stopping", r.cfg.StallIterations)
+	return fmt.Errorf("%w: %d iterations with the same output and no commits", ErrStalled, r.cfg.StallIterations)
+}
+
 // runClaudeReview runs Claude review with the given prompt until REVIEW_DONE.
 func (r *Runner) runClaudeReview(ctx context.Context, prompt string) error {
-	result := r.claude.Run(ctx, prompt)
+	result := r.runExecutor(ctx, r.claude.Run, prompt)
 	if result.Error != nil {
-		if err := r.handlePatternMatchError(result.Error, "claude"); err != nil {
+		if err := r.handlePatternMatchError(result.Error, r.agentName()); err != nil {
 			return err
 		}
 		return fmt.Errorf("claude execution: %w", result.Error)
@@ -405,26 +990,46 @@ func (r *Runner) runClaudeReview(ctx context.Context, prompt string) error {
 	return nil
 }
 
+// reviewIterations returns the iteration cap of each claude review loop.
+// configured MaxReviewIterations wins, otherwise it is 10% of max_iterations (min 3).
+func (r *Runner) reviewIterations() int {
+	if r.cfg.MaxReviewIterations > 0 {
+		return r.cfg.MaxReviewIterations
+	}
+	return max(minReviewIterations, r.cfg.MaxIterations/reviewIterationDivisor)
+}
+
+// planIterations returns the iteration cap of interactive plan creation.
+// configured MaxPlanIterations wins, otherwise it is 20% of max_iterations (min 5).
+func (r *Runner) planIterations() int {
+	if r.cfg.MaxPlanIterations > 0 {
+		return r.cfg.MaxPlanIterations
+	}
+	return max(minPlanIterations, r.cfg.MaxIterations/planIterationDivisor)
+}
+
 // runClaudeReviewLoop runs claude review iterations using second review prompt.
 func (r *Runner) runClaudeReviewLoop(ctx context.Context) error {
-	// review iterations = 10% of max_iterations
-	maxReviewIterations := max(minReviewIterations, r.cfg.MaxIterations/reviewIterationDivisor)
+	maxReviewIterations := r.reviewIterations()
 
 	for i := 1; i <= maxReviewIterations; i++ {
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("review: %w", ctx.Err())
-		default:
+		if err := r.waitIfPaused(ctx); err != nil {
+			return fmt.Errorf("review: %w", err)
+		}
+		if err := r.checkStop(); err != nil {
+			return err
 		}
 
+		r.startIteration(i)
 		r.log.PrintSection(status.NewClaudeReviewSection(i, ": critical/major"))
 
 		// capture HEAD hash before running claude for no-commit detection
 		headBefore := r.headHash()
 
-		result := r.claude.Run(ctx, r.replacePromptVariables(r.cfg.AppConfig.ReviewSecondPrompt))
+		prompt := r.replaceReviewPromptVariables(r.cfg.AppConfig.ReviewSecondPrompt, r.cfg.AppConfig.ReviewSecondAgents)
+		result := r.runExecutor(ctx, r.claude.Run, prompt)
 		if result.Error != nil {
-			if err := r.handlePatternMatchError(result.Error, "claude"); err != nil {
+			if err := r.handlePatternMatchError(result.Error, r.agentName()); err != nil {
 				return err
 			}
 			return fmt.Errorf("claude execution: %w", result.Error)
@@ -470,13 +1075,31 @@ func (r *Runner) headHash() string {
 	return hash
 }
 
-// externalReviewTool returns the effective external review tool to use.
+// diffSummary returns the diff stat of changes committed since the start of the run,
+// or a placeholder if there are none or they can't be determined.
+func (r *Runner) diffSummary() string {
+	if r.git == nil || r.startHead == "" {
+		return "(diff summary unavailable)"
+	}
+	stat, err := r.git.DiffStat(r.startHead, "HEAD")
+	if err != nil {
+		r.log.Print("warning: failed to get diff summary: %v", err)
+		return "(diff summary unavailable)"
+	}
+	if stat == "" {
+		return "(no changes committed during this run)"
+	}
+	return stat
+}
+
+// ExternalReviewTool returns the effective external review tool to use.
 // handles backward compatibility: codex_enabled = false → "none"
 // the CodexEnabled flag takes precedence for backward compatibility.
-func (r *Runner) externalReviewTool() string {
+func (r *Runner) ExternalReviewTool() string {
 	// backward compatibility: codex_enabled = false means no external review
-	// this takes precedence over external_review_tool setting
-	if !r.cfg.CodexEnabled {
+	// this takes precedence over external_review_tool setting.
+	// tasks-review mode never runs external review.
+	if !r.cfg.CodexEnabled || r.cfg.Mode == ModeTasksReview {
 		return "none"
 	}
 
@@ -489,9 +1112,9 @@ func (r *Runner) externalReviewTool() string {
 	return "codex"
 }
 
-// runCodexLoop runs the external review loop (codex or custom) until no findings.
+// runCodexLoop runs the external review loop (codex, gemini or custom) until no findings.
 func (r *Runner) runCodexLoop(ctx context.Context) error {
-	tool := r.externalReviewTool()
+	tool := r.ExternalReviewTool()
 
 	// skip external review phase if disabled
 	if tool == "none" {
@@ -499,30 +1122,103 @@ func (r *Runner) runCodexLoop(ctx context.Context) error {
 		return nil
 	}
 
-	// custom review tool
-	if tool == "custom" {
+	reviewer, err := r.externalReviewer(tool)
+	if err != nil {
+		return err
+	}
+	return r.runExternalReviewLoop(ctx, reviewer)
+}
+
+// runFindingsEvaluation passes the findings supplied with --findings to claude for evaluation and fixing,
+// in place of the external review loop. there is no tool to re-review the fixes, so claude runs once;
+// the post-codex review loop checks the result. empty findings are skipped like an empty codex output.
+func (r *Runner) runFindingsEvaluation(ctx context.Context) error {
+	findings := joinFindings(r.cfg.Findings)
+	if findings == "" {
+		r.log.Print("findings are empty, skipping...")
+		return nil
+	}
+	if err := r.waitIfPaused(ctx); err != nil {
+		return fmt.Errorf("findings: %w", err)
+	}
+	if err := r.checkStop(); err != nil {
+		return err
+	}
+
+	r.startIteration(1)
+	r.showExternalReviewSummary("findings", findings)
+
+	r.phaseHolder.Set(status.PhaseClaudeEval)
+	r.log.PrintSection(status.NewClaudeEvalSection())
+	result := r.runExecutor(ctx, r.claude.Run, r.buildCodexEvaluationPrompt(findings))
+	r.phaseHolder.Set(status.PhaseCodex)
+	if result.Error != nil {
+		if err := r.handlePatternMatchError(result.Error, r.agentName()); err != nil {
+			return err
+		}
+		return fmt.Errorf("claude execution: %w", result.Error)
+	}
+	return nil
+}
+
+// joinFindings concatenates the content of findings files, each headed with its file name.
+// returns empty string if all files are empty.
+func joinFindings(findings []Findings) string {
+	parts := make([]string, 0, len(findings))
+	for _, f := range findings {
+		content := strings.TrimSpace(f.Content)
+		if content == "" {
+			continue
+		}
+		if len(findings) > 1 {
+			content = fmt.Sprintf("findings from %s:\n\n%s", f.File, content)
+		}
+		parts = append(parts, content)
+	}
+	return strings.Join(parts, "\n\n---\n\n")
+}
+
+// externalReviewer returns the review loop callbacks for the given external review tool.
+// the loop itself is shared, so a new tool only needs a case here.
+func (r *Runner) externalReviewer(tool string) (externalReviewConfig, error) {
+	switch tool {
+	case "custom":
 		if r.custom == nil {
-			return errors.New("custom review script not configured")
+			return externalReviewConfig{}, errors.New("custom review script not configured")
 		}
-		return r.runExternalReviewLoop(ctx, externalReviewConfig{
+		return externalReviewConfig{
 			name:            "custom",
 			runReview:       func(ctx context.Context, prompt string) executor.Result { return r.custom.Run(ctx, prompt) },
 			buildPrompt:     r.buildCustomReviewPrompt,
 			buildEvalPrompt: r.buildCustomEvaluationPrompt,
 			showSummary:     r.showCustomSummary,
 			makeSection:     status.NewCustomIterationSection,
-		})
+		}, nil
+	case "gemini":
+		if r.gemini == nil {
+			return externalReviewConfig{}, errors.New("gemini reviewer not configured")
+		}
+		// gemini gets the same review prompt as codex, so severity filtering applies to its findings too
+		return externalReviewConfig{
+			name:            "gemini",
+			runReview:       r.gemini.Run,
+			buildPrompt:     r.buildCodexPrompt,
+			buildEvalPrompt: r.buildGeminiEvaluationPrompt,
+			showSummary:     func(output string) { r.showExternalReviewSummary("gemini", output) },
+			makeSection:     status.NewGeminiIterationSection,
+			filterFindings:  r.filterCodexFindings,
+		}, nil
+	default:
+		return externalReviewConfig{
+			name:            "codex",
+			runReview:       r.codex.Run,
+			buildPrompt:     r.buildCodexPrompt,
+			buildEvalPrompt: r.buildCodexEvaluationPrompt,
+			showSummary:     r.showCodexSummary,
+			makeSection:     status.NewCodexIterationSection,
+			filterFindings:  r.filterCodexFindings,
+		}, nil
 	}
-
-	// default: codex review
-	return r.runExternalReviewLoop(ctx, externalReviewConfig{
-		name:            "codex",
-		runReview:       r.codex.Run,
-		buildPrompt:     r.buildCodexPrompt,
-		buildEvalPrompt: r.buildCodexEvaluationPrompt,
-		showSummary:     r.showCodexSummary,
-		makeSection:     status.NewCodexIterationSection,
-	})
 }
 
 // externalReviewConfig holds callbacks for running an external review tool.
@@ -533,6 +1229,7 @@ type externalReviewConfig struct {
 	buildEvalPrompt func(output string) string                               // build evaluation prompt for claude
 	showSummary     func(output string)                                      // display review findings summary
 	makeSection     func(iteration int) status.Section                       // create section header
+	filterFindings  func(output string) (kept string, allDropped bool)       // optional, drops findings before evaluation
 }
 
 // runExternalReviewLoop runs a generic external review tool-claude loop until no findings.
@@ -543,16 +1240,18 @@ func (r *Runner) runExternalReviewLoop(ctx context.Context, cfg externalReviewCo
 	var claudeResponse string // first iteration has no prior response
 
 	for i := 1; i <= maxIterations; i++ {
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("%s loop: %w", cfg.name, ctx.Err())
-		default:
+		if err := r.waitIfPaused(ctx); err != nil {
+			return fmt.Errorf("%s loop: %w", cfg.name, err)
+		}
+		if err := r.checkStop(); err != nil {
+			return err
 		}
 
+		r.startIteration(i)
 		r.log.PrintSection(cfg.makeSection(i))
 
 		// run external review tool
-		reviewResult := cfg.runReview(ctx, cfg.buildPrompt(i == 1, claudeResponse))
+		reviewResult := r.runExternalReview(ctx, cfg, cfg.buildPrompt(i == 1, claudeResponse))
 		if reviewResult.Error != nil {
 			if err := r.handlePatternMatchError(reviewResult.Error, cfg.name); err != nil {
 				return err
@@ -565,18 +1264,32 @@ func (r *Runner) runExternalReviewLoop(ctx context.Context, cfg externalReviewCo
 			break
 		}
 
+		reviewOutput := reviewResult.Output
+		if cfg.filterFindings != nil {
+			var allDropped bool
+			if reviewOutput, allDropped = cfg.filterFindings(reviewOutput); allDropped {
+				if i == 1 {
+					r.log.Print("all %s findings filtered out, skipping evaluation", cfg.name)
+					return nil
+				}
+				// fixes of earlier iterations are not committed yet, let claude finish as if nothing was found
+				r.log.Print("all %s findings filtered out, finishing review", cfg.name)
+				reviewOutput = "NO ISSUES FOUND"
+			}
+		}
+
 		// show findings summary before Claude evaluation
-		cfg.showSummary(reviewResult.Output)
+		cfg.showSummary(reviewOutput)
 
 		// pass output to claude for evaluation and fixing
 		r.phaseHolder.Set(status.PhaseClaudeEval)
 		r.log.PrintSection(status.NewClaudeEvalSection())
-		claudeResult := r.claude.Run(ctx, cfg.buildEvalPrompt(reviewResult.Output))
+		claudeResult := r.runExecutor(ctx, r.claude.Run, cfg.buildEvalPrompt(reviewOutput))
 
 		// restore codex phase for next iteration
 		r.phaseHolder.Set(status.PhaseCodex)
 		if claudeResult.Error != nil {
-			if err := r.handlePatternMatchError(claudeResult.Error, "claude"); err != nil {
+			if err := r.handlePatternMatchError(claudeResult.Error, r.agentName()); err != nil {
 				return err
 			}
 			return fmt.Errorf("claude execution: %w", claudeResult.Error)
@@ -615,9 +1328,14 @@ The code implements the plan at: %s
 	// different diff command based on iteration
 	var diffInstruction, diffDescription string
 	if isFirst {
-		defaultBranch := r.getDefaultBranch()
-		diffInstruction = fmt.Sprintf("Run: git diff %s...HEAD", defaultBranch)
-		diffDescription = fmt.Sprintf("code changes between %s and HEAD branch", defaultBranch)
+		diffInstruction = "Run: git diff " + r.getDiffRange()
+		diffDescription = fmt.Sprintf("code changes between %s and HEAD branch", r.getBaseBranch())
+		if r.cfg.DiffRange != "" {
+			diffDescription = "code changes in " + r.cfg.DiffRange
+		}
+		if r.git != nil {
+			diffInstruction += "\n\nChanged files:\n" + r.getChangedFilesRef()
+		}
 	} else {
 		diffInstruction = "Run: git diff"
 		diffDescription = "uncommitted changes (Claude's fixes from previous iteration)"
@@ -634,7 +1352,9 @@ Analyze for:
 - Error handling gaps
 - Code quality issues
 
-Report findings with file:line references. If no issues found, say "NO ISSUES FOUND".`, planContext, diffDescription, diffInstruction)
+Report each finding as a list item starting with its severity, then file:line reference and description:
+- [critical|high|medium|low] file:line - description
+If no issues found, say "NO ISSUES FOUND".`, planContext, diffDescription, diffInstruction)
 
 	if claudeResponse != "" {
 		return fmt.Sprintf(`%s
@@ -652,21 +1372,15 @@ If Claude's arguments are invalid, explain why the issues still exist.`, basePro
 	return basePrompt
 }
 
-// hasUncompletedTasks checks if plan file has any uncompleted checkboxes.
+// hasUncompletedTasks checks if plan file has any uncompleted checkboxes, ignoring its front-matter.
 func (r *Runner) hasUncompletedTasks() bool {
 	content, err := os.ReadFile(r.resolvePlanFilePath())
 	if err != nil {
 		return true // assume incomplete if can't read
 	}
 
-	// look for uncompleted checkbox pattern: [ ] (not [x])
-	for line := range strings.SplitSeq(string(content), "\n") {
-		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, "- [ ]") {
-			return true
-		}
-	}
-	return false
+	done, total := plan.CountTasks(string(content))
+	return done < total
 }
 
 // showCodexSummary displays a condensed summary of codex output before Claude evaluation.
@@ -716,6 +1430,7 @@ var ErrUserRejectedPlan = errors.New("user rejected plan")
 type draftReviewResult struct {
 	handled  bool   // true if draft was found and handled
 	feedback string // revision feedback (non-empty only for "revise" action)
+	edited   string // hand-edited plan (non-empty only for "edit" action)
 	err      error  // error if review failed or user rejected
 }
 
@@ -738,8 +1453,13 @@ func (r *Runner) handlePlanDraft(ctx context.Context, output string) draftReview
 		return draftReviewResult{handled: true, err: fmt.Errorf("collect draft review: %w", askErr)}
 	}
 
-	// log the draft review action and feedback to progress file
-	r.log.LogDraftReview(action, feedback)
+	// log the draft review action and feedback to progress file.
+	// the edited plan is passed in the next prompt, not logged as feedback
+	if action == "edit" {
+		r.log.LogDraftReview(action, "")
+	} else {
+		r.log.LogDraftReview(action, feedback)
+	}
 
 	switch action {
 	case "accept":
@@ -751,33 +1471,49 @@ func (r *Runner) handlePlanDraft(ctx context.Context, output string) draftReview
 	case "reject":
 		r.log.Print("plan rejected by user")
 		return draftReviewResult{handled: true, err: ErrUserRejectedPlan}
+	case "edit":
+		r.log.Print("draft edited by user, re-running with the edited plan...")
+		return draftReviewResult{handled: true, edited: feedback}
 	}
 
 	return draftReviewResult{handled: true}
 }
 
+// questionResult holds the result of handling a QUESTION signal.
+type questionResult struct {
+	handled   bool   // true if a question was found and answered
+	malformed string // parse error of a malformed question signal, passed back to claude
+	err       error  // error if collecting the answer failed
+}
+
 // handlePlanQuestion processes QUESTION signal if present in output.
-// returns true if question was found and handled, false otherwise.
-// returns error if question handling failed.
-func (r *Runner) handlePlanQuestion(ctx context.Context, output string) (bool, error) {
+// choice questions offer the options, text and multiline questions take a free-form answer.
+// a malformed signal is logged and reported in the result, so claude can be asked again.
+func (r *Runner) handlePlanQuestion(ctx context.Context, output string) questionResult {
 	question, err := ParseQuestionPayload(output)
 	if err != nil {
-		// log malformed signals (but not "no signal" which is expected)
-		if !errors.Is(err, ErrNoQuestionSignal) {
-			r.log.Print("warning: %v", err)
+		if errors.Is(err, ErrNoQuestionSignal) {
+			return questionResult{}
 		}
-		return false, nil
+		r.log.Print("warning: %v", err)
+		return questionResult{malformed: err.Error()}
 	}
 
-	r.log.LogQuestion(question.Question, question.Options)
-
-	answer, askErr := r.inputCollector.AskQuestion(ctx, question.Question, question.Options)
-	if askErr != nil {
-		return true, fmt.Errorf("collect answer: %w", askErr)
+	var answer string
+	switch question.Type {
+	case QuestionText, QuestionMultiline:
+		r.log.LogQuestion(question.Question, nil)
+		answer, err = r.inputCollector.AskText(ctx, question.Question, question.Default, question.Type == QuestionMultiline)
+	default:
+		r.log.LogQuestion(question.Question, question.Options)
+		answer, err = r.inputCollector.AskQuestion(ctx, question.Question, question.Options)
+	}
+	if err != nil {
+		return questionResult{handled: true, err: fmt.Errorf("collect answer: %w", err)}
 	}
 
 	r.log.LogAnswer(answer)
-	return true, nil
+	return questionResult{handled: true}
 }
 
 // runPlanCreation executes the interactive plan creation loop.
@@ -795,11 +1531,10 @@ func (r *Runner) runPlanCreation(ctx context.Context) error {
 	r.log.PrintRaw("starting interactive plan creation\n")
 	r.log.Print("plan request: %s", r.cfg.PlanDescription)
 
-	// plan iterations use 20% of max_iterations
-	maxPlanIterations := max(minPlanIterations, r.cfg.MaxIterations/planIterationDivisor)
+	maxPlanIterations := r.planIterations()
 
-	// track revision feedback for context in next iteration
-	var lastRevisionFeedback string
+	// track revision feedback, the hand-edited draft and a malformed question for context in next iteration
+	var lastRevisionFeedback, lastEditedDraft, lastQuestionError string
 
 	for i := 1; i <= maxPlanIterations; i++ {
 		select {
@@ -807,7 +1542,11 @@ func (r *Runner) runPlanCreation(ctx context.Context) error {
 			return fmt.Errorf("plan creation: %w", ctx.Err())
 		default:
 		}
+		if err := r.checkStop(); err != nil {
+			return err
+		}
 
+		r.startIteration(i)
 		r.log.PrintSection(status.NewPlanIterationSection(i))
 
 		prompt := r.buildPlanPrompt()
@@ -816,10 +1555,20 @@ func (r *Runner) runPlanCreation(ctx context.Context) error {
 			prompt = fmt.Sprintf("%s\n\n---\nPREVIOUS DRAFT FEEDBACK:\nUser requested revisions with this feedback:\n%s\n\nPlease revise the plan accordingly and present a new PLAN_DRAFT.", prompt, lastRevisionFeedback)
 			lastRevisionFeedback = "" // clear after use
 		}
+		// the hand-edited draft is authoritative, claude writes it as the plan file
+		if lastEditedDraft != "" {
+			prompt = fmt.Sprintf("%s\n\n---\nUSER-EDITED DRAFT:\nThe user edited the plan draft by hand. This is the authoritative version of the plan:\n\n%s\n\nDo not present another PLAN_DRAFT. Write this plan to the plan file as-is (Step 4), then emit PLAN_READY.", prompt, lastEditedDraft)
+			lastEditedDraft = "" // clear after use
+		}
+		if lastQuestionError != "" {
+			prompt = fmt.Sprintf("%s\n\n---\nMALFORMED QUESTION:\nYour previous QUESTION signal could not be parsed: %s\n\n"+
+				"Ask the question again as a valid QUESTION signal with a JSON payload.", prompt, lastQuestionError)
+			lastQuestionError = "" // clear after use
+		}
 
-		result := r.claude.Run(ctx, prompt)
+		result := r.runExecutor(ctx, r.claude.Run, prompt)
 		if result.Error != nil {
-			if err := r.handlePatternMatchError(result.Error, "claude"); err != nil {
+			if err := r.handlePatternMatchError(result.Error, r.agentName()); err != nil {
 				return err
 			}
 			return fmt.Errorf("claude execution: %w", result.Error)
@@ -842,6 +1591,7 @@ func (r *Runner) runPlanCreation(ctx context.Context) error {
 		}
 		if draftResult.handled {
 			lastRevisionFeedback = draftResult.feedback
+			lastEditedDraft = draftResult.edited
 			if err := r.sleepWithContext(ctx, r.iterationDelay); err != nil {
 				return fmt.Errorf("interrupted: %w", err)
 			}
@@ -849,11 +1599,12 @@ func (r *Runner) runPlanCreation(ctx context.Context) error {
 		}
 
 		// check for QUESTION signal
-		handled, err := r.handlePlanQuestion(ctx, result.Output)
-		if err != nil {
-			return err
+		question := r.handlePlanQuestion(ctx, result.Output)
+		if question.err != nil {
+			return question.err
 		}
-		if handled {
+		lastQuestionError = question.malformed
+		if question.handled {
 			if err := r.sleepWithContext(ctx, r.iterationDelay); err != nil {
 				return fmt.Errorf("interrupted: %w", err)
 			}
@@ -869,11 +1620,52 @@ func (r *Runner) runPlanCreation(ctx context.Context) error {
 	return fmt.Errorf("max plan iterations (%d) reached without completion", maxPlanIterations)
 }
 
+// executorErrorPatterns converts user-defined error patterns from config for the executors.
+func executorErrorPatterns(appCfg *config.Config) []executor.ErrorPattern {
+	if appCfg == nil || len(appCfg.ErrorPatterns) == 0 {
+		return nil
+	}
+	res := make([]executor.ErrorPattern, 0, len(appCfg.ErrorPatterns))
+	for _, p := range appCfg.ErrorPatterns {
+		res = append(res, executor.ErrorPattern{Pattern: p.Pattern, HelpCmd: p.HelpCmd})
+	}
+	return res
+}
+
+// runExternalReview runs the external review tool, retrying a failed call up to codexRetryCount times
+// with exponential backoff (see retryDelay), so a network blip doesn't fail the whole run.
+// pattern matches (rate limits), timeouts and cancellation are returned without retries.
+func (r *Runner) runExternalReview(ctx context.Context, cfg externalReviewConfig, prompt string) executor.Result {
+	for attempt := 1; ; attempt++ {
+		result := r.runExecutorWithin(ctx, cfg.runReview, prompt, r.reviewTimeout)
+		if result.Error == nil || attempt > r.codexRetryCount || !retryableReviewError(ctx, result.Error) {
+			return result
+		}
+		delay := r.retryDelay(attempt)
+		r.log.Print("%s execution failed: %v, retry %d/%d in %s", cfg.name, result.Error, attempt, r.codexRetryCount, delay)
+		if err := r.sleepWithContext(ctx, delay); err != nil {
+			return executor.Result{Error: err}
+		}
+	}
+}
+
+// retryableReviewError reports whether a failed external review call is worth retrying.
+func retryableReviewError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, executor.ErrTimeout) {
+		return false
+	}
+	var patternErr *executor.PatternMatchError
+	return !errors.As(err, &patternErr)
+}
+
 // handlePatternMatchError checks if err is a PatternMatchError and logs appropriate messages.
 // Returns the error if it's a pattern match (to trigger graceful exit), nil otherwise.
 func (r *Runner) handlePatternMatchError(err error, tool string) error {
 	var patternErr *executor.PatternMatchError
 	if errors.As(err, &patternErr) {
+		if r.metrics != nil {
+			r.metrics.RateLimitHit(r.phaseHolder.Get(), string(r.cfg.Mode))
+		}
 		r.log.Print("error: detected %q in %s output", patternErr.Pattern, tool)
 		r.log.Print("run '%s' for more information", patternErr.HelpCmd)
 		return err
@@ -881,6 +1673,14 @@ func (r *Runner) handlePatternMatchError(err error, tool string) error {
 	return nil
 }
 
+// agentName returns the name of the primary agent backend for log messages.
+func (r *Runner) agentName() string {
+	if r.cfg.AppConfig != nil && (r.cfg.AppConfig.AgentBackend == AgentGemini || r.cfg.AppConfig.AgentBackend == AgentOllama) {
+		return r.cfg.AppConfig.AgentBackend
+	}
+	return AgentClaude
+}
+
 // runFinalize executes the optional finalize step after successful reviews.
 // runs once, best-effort: failures are logged but don't block success.
 // exception: context cancellation is propagated (user wants to abort).
@@ -889,11 +1689,21 @@ func (r *Runner) runFinalize(ctx context.Context) error {
 		return nil
 	}
 
-	r.phaseHolder.Set(status.PhaseFinalize)
+	r.enterStage(StageFinalize, status.PhaseFinalize)
+	if err := r.checkStop(); err != nil {
+		return err
+	}
 	r.log.PrintSection(status.NewGenericSection("finalize step"))
 
+	if len(r.cfg.AppConfig.FinalizeCommands) > 0 {
+		return r.runFinalizeCommands(ctx)
+	}
+
 	prompt := r.replacePromptVariables(r.cfg.AppConfig.FinalizePrompt)
-	result := r.claude.Run(ctx, prompt)
+	if strings.Contains(prompt, "{{DIFF_SUMMARY}}") {
+		prompt = strings.ReplaceAll(prompt, "{{DIFF_SUMMARY}}", r.diffSummary())
+	}
+	result := r.runExecutor(ctx, r.claude.Run, prompt)
 
 	if result.Error != nil {
 		// propagate context cancellation - user wants to abort
@@ -901,7 +1711,7 @@ func (r *Runner) runFinalize(ctx context.Context) error {
 			return fmt.Errorf("finalize step: %w", result.Error)
 		}
 		// pattern match (rate limit) - log via shared helper, but don't fail (best-effort)
-		if r.handlePatternMatchError(result.Error, "claude") != nil {
+		if r.handlePatternMatchError(result.Error, r.agentName()) != nil {
 			return nil //nolint:nilerr // intentional: best-effort semantics, log but don't propagate
 		}
 		// best-effort: log error but don't fail
@@ -918,6 +1728,167 @@ func (r *Runner) runFinalize(ctx context.Context) error {
 	return nil
 }
 
+// runExecutor runs a single claude call, bounded by the claude timeout if configured, see runExecutorWithin.
+func (r *Runner) runExecutor(ctx context.Context, run func(context.Context, string) executor.Result, prompt string) executor.Result {
+	return r.runExecutorWithin(ctx, run, prompt, r.claudeTimeout)
+}
+
+// runExecutorWithin runs a single executor call, bounded by timeout if it is positive.
+// a call that hits its own deadline while ctx is still active returns executor.ErrTimeout,
+// so it can be told apart from cancellation of the whole run, and is logged as it happens.
+func (r *Runner) runExecutorWithin(ctx context.Context, run func(context.Context, string) executor.Result, prompt string,
+	timeout time.Duration) executor.Result {
+	start, phase := time.Now(), r.phaseHolder.Get()
+	result := r.runExecutorCall(ctx, run, prompt, timeout)
+	r.recordUsage(result)
+	if r.metrics != nil {
+		r.metrics.ExecutorCall(phase, string(r.cfg.Mode), time.Since(start), result.Error != nil)
+	}
+	return result
+}
+
+// runExecutorCall runs the executor with the per-call timeout applied, see runExecutorWithin.
+func (r *Runner) runExecutorCall(ctx context.Context, run func(context.Context, string) executor.Result, prompt string,
+	timeout time.Duration) executor.Result {
+	if timeout <= 0 {
+		return run(ctx, prompt)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := run(callCtx, prompt)
+	if result.Error != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		result.Error = fmt.Errorf("%w after %s", executor.ErrTimeout, timeout)
+		r.log.Print("execution timed out after %s", timeout)
+	}
+	return result
+}
+
+// callTimeout returns the per-call limit of a tool: its own timeout in minutes if set, the executor timeout otherwise.
+func callTimeout(minutes, executorMs int) time.Duration {
+	if minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return time.Duration(max(executorMs, 0)) * time.Millisecond
+}
+
+// startIteration records the current iteration within the stage and counts it in metrics.
+func (r *Runner) startIteration(i int) {
+	r.iteration = i
+	if r.metrics != nil {
+		r.metrics.Iteration(r.phaseHolder.Get(), string(r.cfg.Mode))
+	}
+}
+
+// enterStage records the current pipeline stage and switches to its phase.
+// the phase change triggers the checkpoint save via the phase holder callback.
+func (r *Runner) enterStage(s Stage, phase status.Phase) {
+	r.stage = s
+	r.iteration = 0
+	r.startStageChanges(s)
+	r.phaseHolder.Set(phase)
+}
+
+// skipStage reports whether the stage was completed before the checkpoint this run resumes from.
+func (r *Runner) skipStage(s Stage) bool {
+	if r.resumeFrom == "" || stageIndex(s) >= stageIndex(r.resumeFrom) {
+		return false
+	}
+	r.log.Print("skipping %s stage, completed before checkpoint", s)
+	return true
+}
+
+// onPhaseChange saves the checkpoint on each phase transition.
+// failures are logged but don't interrupt the run, the checkpoint is a convenience.
+func (r *Runner) onPhaseChange(_, cur status.Phase) {
+	if r.stage == "" {
+		return
+	}
+	cp := Checkpoint{
+		PlanFile:  r.cfg.PlanFile,
+		Mode:      r.cfg.Mode,
+		Stage:     r.stage,
+		Phase:     cur,
+		Iteration: r.iteration,
+		UpdatedAt: time.Now(),
+	}
+	if err := saveCheckpoint(r.checkpointPath, cp); err != nil {
+		r.log.Print("warning: %v", err)
+	}
+}
+
+// loadResumeStage sets the stage to resume from, if a checkpoint for the same plan file and mode exists.
+func (r *Runner) loadResumeStage() {
+	if r.checkpointPath == "" {
+		return
+	}
+	cp, err := LoadCheckpoint(r.checkpointPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			r.log.Print("no checkpoint found, starting from the beginning")
+			return
+		}
+		r.log.Print("warning: ignoring checkpoint: %v", err)
+		return
+	}
+	if cp.PlanFile != r.cfg.PlanFile || cp.Mode != r.cfg.Mode {
+		r.log.Print("checkpoint is for a different plan or mode, starting from the beginning")
+		return
+	}
+	r.resumeFrom = cp.Stage
+	r.log.Print("resuming from %s stage (checkpoint saved %s)", cp.Stage, cp.UpdatedAt.Format(time.DateTime))
+}
+
+// removeCheckpoint deletes the checkpoint after a successful run.
+func (r *Runner) removeCheckpoint() {
+	if r.checkpointPath == "" {
+		return
+	}
+	if err := os.Remove(r.checkpointPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		r.log.Print("warning: failed to remove checkpoint: %v", err)
+	}
+}
+
+// modeHasStages returns true for modes running several pipeline stages, which can be resumed.
+func modeHasStages(mode Mode) bool {
+	return mode == ModeFull || mode == ModeReview || mode == ModeCodexOnly || mode == ModeTasksReview
+}
+
+// waitIfPaused blocks while the run is paused from the dashboard or by a signal.
+// returns ctx.Err() if the context is canceled, before or during the pause.
+func (r *Runner) waitIfPaused(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err //nolint:wrapcheck // callers wrap with their phase
+	}
+	if r.pauseHolder == nil || !r.pauseHolder.Paused() {
+		return nil
+	}
+	r.log.Print("paused by user, waiting for resume...")
+	if err := r.pauseHolder.Wait(ctx); err != nil {
+		return err //nolint:wrapcheck // callers wrap with their phase
+	}
+	r.log.Print("resumed by user")
+	return nil
+}
+
+// ErrStopped is returned when the user asked the run to stop after its current iteration.
+var ErrStopped = errors.New("stopped by user")
+
+// checkStop returns ErrStopped with the last completed iteration if a stop was requested, nil otherwise.
+// called between iterations, so the agent call in progress and its commits are never cut off.
+func (r *Runner) checkStop() error {
+	if !r.stopHolder.Requested() {
+		return nil
+	}
+	err := fmt.Errorf("%w before %s phase", ErrStopped, r.phaseHolder.Get())
+	if r.iteration > 0 {
+		err = fmt.Errorf("%w after iteration %d", ErrStopped, r.iteration)
+	}
+	r.log.Print("%v", err)
+	return err
+}
+
 // sleepWithContext pauses for the given duration but returns immediately if context is canceled.
 // returns ctx.Err() on cancellation, nil on normal completion.
 func (r *Runner) sleepWithContext(ctx context.Context, d time.Duration) error {
@@ -932,13 +1903,13 @@ func (r *Runner) sleepWithContext(ctx context.Context, d time.Duration) error {
 }
 
 // needsCodexBinary returns true if the current configuration requires the codex binary.
-// returns false when external_review_tool is "custom" or "none", since codex isn't used.
+// returns false when external_review_tool is "gemini", "custom" or "none", since codex isn't used.
 func needsCodexBinary(appConfig *config.Config) bool {
 	if appConfig == nil {
 		return true // default behavior assumes codex
 	}
 	switch appConfig.ExternalReviewTool {
-	case "custom", "none":
+	case "gemini", "custom", "none":
 		return false
 	default:
 		return true // "codex" or empty (default) requires codex binary
@@ -2,8 +2,12 @@ package processor_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,6 +19,7 @@ import (
 
 	"github.com/umputun/ralphex/pkg/config"
 	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/notify"
 	"github.com/umputun/ralphex/pkg/processor"
 	"github.com/umputun/ralphex/pkg/processor/mocks"
 	"github.com/umputun/ralphex/pkg/status"
@@ -50,6 +55,7 @@ func newMockLogger(path string) *mocks.LoggerMock {
 		PrintRawFunc:       func(_ string, _ ...any) {},
 		PrintSectionFunc:   func(_ status.Section) {},
 		PrintAlignedFunc:   func(_ string) {},
+		PrintToolFunc:      func(_ string) {},
 		LogQuestionFunc:    func(_ string, _ []string) {},
 		LogAnswerFunc:      func(_ string) {},
 		LogDraftReviewFunc: func(_, _ string) {},
@@ -184,6 +190,159 @@ func TestRunner_RunCodexOnly_NoFindings(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestRunner_RunCodexOnly_FindingsFile(t *testing.T) {
+	t.Run("findings replace codex", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "fixed", Signal: status.CodexDone},        // findings evaluation
+			{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop
+		})
+		codex := newMockExecutor(nil)
+
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t),
+			Findings: []processor.Findings{{File: "review.md", Content: "- pkg/a.go:3 - nil map write\n"}}}
+		r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+		require.NoError(t, r.Run(context.Background()))
+
+		assert.Empty(t, codex.RunCalls(), "codex must not run with a findings file")
+		require.Len(t, claude.RunCalls(), 2)
+		evalPrompt := claude.RunCalls()[0].Prompt
+		assert.Contains(t, evalPrompt, "- pkg/a.go:3 - nil map write")
+		assert.NotContains(t, evalPrompt, "{{CODEX_OUTPUT}}")
+		assert.NotContains(t, evalPrompt, "findings from review.md", "a single file has no header")
+	})
+
+	t.Run("multiple files are concatenated", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "fixed"},
+			{Output: "review done", Signal: status.ReviewDone},
+		})
+		codex := newMockExecutor(nil)
+
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t),
+			Findings: []processor.Findings{{File: "pr.md", Content: "first finding"}, {File: "empty.md", Content: "\n"},
+				{File: "lint.txt", Content: "second finding"}}}
+		r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+		require.NoError(t, r.Run(context.Background()))
+
+		assert.Empty(t, codex.RunCalls())
+		require.Len(t, claude.RunCalls(), 2, "findings are evaluated once, even without a done signal")
+		assert.Contains(t, claude.RunCalls()[0].Prompt,
+			"findings from pr.md:\n\nfirst finding\n\n---\n\nfindings from lint.txt:\n\nsecond finding")
+		assert.NotContains(t, claude.RunCalls()[0].Prompt, "empty.md")
+	})
+
+	t.Run("empty findings skip evaluation", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop
+		})
+		codex := newMockExecutor(nil)
+
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t),
+			Findings: []processor.Findings{{File: "review.md", Content: "  \n"}}}
+		r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+		require.NoError(t, r.Run(context.Background()))
+
+		assert.Empty(t, codex.RunCalls())
+		assert.Len(t, claude.RunCalls(), 1, "only the post-codex review runs")
+		assert.Contains(t, printedLines(log), "findings are empty, skipping...")
+	})
+}
+
+func TestRunner_CodexFindingsFilter(t *testing.T) {
+	t.Run("all findings filtered skips claude evaluation", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop
+		})
+		codex := newMockExecutor([]executor.Result{
+			{Output: "- [low] pkg/a.go:12 - consider renaming variable x"},
+		})
+
+		appCfg := testAppConfig(t)
+		appCfg.CodexIgnorePatterns = []string{`(?i)consider renaming`}
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+		require.NoError(t, r.Run(context.Background()))
+
+		assert.Len(t, codex.RunCalls(), 1)
+		require.Len(t, claude.RunCalls(), 1, "only the post-codex review runs")
+		assert.NotContains(t, claude.RunCalls()[0].Prompt, "consider renaming")
+		lines := printedLines(log)
+		assert.Contains(t, lines, "suppressed 1 of 1 codex findings:")
+		assert.Contains(t, lines, "  - [low] pkg/a.go:12 - consider renaming variable x")
+		assert.Contains(t, lines, "all codex findings filtered out, skipping evaluation")
+	})
+
+	t.Run("evaluation gets surviving findings only", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "done", Signal: status.CodexDone},         // codex evaluation
+			{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop
+		})
+		codex := newMockExecutor([]executor.Result{
+			{Output: "Findings:\n- [high] pkg/a.go:3 - nil map write\n- [low] pkg/b.go:9 - comment style\n- [medium] pkg/c.go:1 - naming"},
+		})
+
+		appCfg := testAppConfig(t)
+		appCfg.CodexMinSeverity = "medium"
+		appCfg.CodexIgnorePatterns = []string{`naming$`}
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+		require.NoError(t, r.Run(context.Background()))
+
+		require.Len(t, claude.RunCalls(), 2)
+		evalPrompt := claude.RunCalls()[0].Prompt
+		assert.Contains(t, evalPrompt, "- [high] pkg/a.go:3 - nil map write")
+		assert.NotContains(t, evalPrompt, "comment style")
+		assert.NotContains(t, evalPrompt, "pkg/c.go")
+		assert.Contains(t, printedLines(log), "suppressed 2 of 3 codex findings:")
+	})
+
+	t.Run("all findings filtered in later iteration lets claude finish", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "fixed the bug"},                          // first codex evaluation, fixes without signal
+			{Output: "committed", Signal: status.CodexDone},    // second evaluation with nothing left
+			{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop
+		})
+		codex := newMockExecutor([]executor.Result{
+			{Output: "- [high] pkg/a.go:3 - nil map write"},
+			{Output: "- [low] pkg/a.go:3 - comment style"},
+		})
+
+		appCfg := testAppConfig(t)
+		appCfg.CodexMinSeverity = "medium"
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+			AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+		require.NoError(t, r.Run(context.Background()))
+
+		require.Len(t, claude.RunCalls(), 3)
+		assert.Contains(t, claude.RunCalls()[1].Prompt, "NO ISSUES FOUND")
+		assert.NotContains(t, claude.RunCalls()[1].Prompt, "comment style")
+		assert.Contains(t, printedLines(log), "all codex findings filtered out, finishing review")
+	})
+
+	t.Run("no filter passes output unchanged", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "done", Signal: status.CodexDone},
+			{Output: "review done", Signal: status.ReviewDone},
+		})
+		codex := newMockExecutor([]executor.Result{{Output: "Findings:\n- [low] pkg/b.go:9 - comment style"}})
+
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+		require.NoError(t, r.Run(context.Background()))
+
+		require.Len(t, claude.RunCalls(), 2)
+		assert.Contains(t, claude.RunCalls()[0].Prompt, "Findings:\n- [low] pkg/b.go:9 - comment style")
+	})
+}
+
 func TestRunner_CodexDisabled_SkipsCodexPhase(t *testing.T) {
 	log := newMockLogger("progress.txt")
 	claude := newMockExecutor([]executor.Result{
@@ -220,6 +379,68 @@ func TestRunner_RunTasksOnly_Success(t *testing.T) {
 	assert.Len(t, claude.RunCalls(), 1)
 }
 
+func TestRunner_RunTasksReview_Success(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	log := newMockLogger("progress.txt")
+	claude := newMockExecutor([]executor.Result{
+		{Output: "task done", Signal: status.Completed},    // task phase completes
+		{Output: "review done", Signal: status.ReviewDone}, // first review
+		{Output: "review done", Signal: status.ReviewDone}, // pre-codex review loop
+		{Output: "review done", Signal: status.ReviewDone}, // post review loop
+	})
+	codex := newMockExecutor(nil)
+
+	holder := &status.PhaseHolder{}
+	var phases []status.Phase
+	holder.OnChange(func(_, cur status.Phase) { phases = append(phases, cur) })
+
+	appCfg := testAppConfig(t)
+	appCfg.FinalizePrompt = "finalize prompt"
+	// codex and finalize are enabled, tasks-review mode ignores both
+	cfg := processor.Config{Mode: processor.ModeTasksReview, PlanFile: planFile, MaxIterations: 50,
+		CodexEnabled: true, FinalizeEnabled: true, AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, holder)
+	err := r.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, codex.RunCalls(), "codex should not be called in tasks-review mode")
+	require.Len(t, claude.RunCalls(), 4, "task + first review + two review loops, no finalize")
+	assert.Equal(t, []status.Phase{status.PhaseTask, status.PhaseReview}, phases)
+	assert.Equal(t, "none", r.ExternalReviewTool())
+}
+
+func TestRunner_RunTasksReview_NoPlanFile(t *testing.T) {
+	r := processor.NewWithExecutors(processor.Config{Mode: processor.ModeTasksReview}, newMockLogger(""),
+		newMockExecutor(nil), newMockExecutor(nil), nil, &status.PhaseHolder{})
+	err := r.Run(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plan file required for tasks-review mode")
+}
+
+func TestRunner_RunTasksReview_ReviewError(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	claude := newMockExecutor([]executor.Result{
+		{Output: "task done", Signal: status.Completed},
+		{Output: "review failed", Signal: status.Failed}, // first review fails
+	})
+	codex := newMockExecutor(nil)
+
+	cfg := processor.Config{Mode: processor.ModeTasksReview, PlanFile: planFile, MaxIterations: 50, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex, nil, &status.PhaseHolder{})
+	err := r.Run(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "first review")
+	assert.Empty(t, codex.RunCalls())
+}
+
 func TestRunner_RunTasksOnly_NoPlanFile(t *testing.T) {
 	log := newMockLogger("")
 	claude := newMockExecutor(nil)
@@ -374,6 +595,105 @@ func TestRunner_CodexPhase_Error(t *testing.T) {
 	assert.Len(t, codex.RunCalls(), 1, "codex should be called once")
 }
 
+func TestRunner_CodexPhase_Retry(t *testing.T) {
+	patternErr := &executor.PatternMatchError{Pattern: "rate limit", HelpCmd: "codex /status"}
+	tests := []struct {
+		name        string
+		codex       []executor.Result
+		wantErr     string
+		wantCalls   int
+		wantRetries int
+	}{
+		{name: "transient error then success", codex: []executor.Result{
+			{Error: errors.New("stream disconnected")}, {Output: "found issue in foo.go:10"},
+		}, wantCalls: 2, wantRetries: 1},
+		{name: "fails after all retries", codex: []executor.Result{
+			{Error: errors.New("network error 1")}, {Error: errors.New("network error 2")}, {Error: errors.New("network error 3")},
+		}, wantErr: "codex execution: network error 3", wantCalls: 3, wantRetries: 2},
+		{name: "pattern match not retried", codex: []executor.Result{{Output: "Rate limit exceeded", Error: patternErr}},
+			wantErr: "rate limit", wantCalls: 1},
+		{name: "timeout not retried", codex: []executor.Result{{Error: fmt.Errorf("%w after 1s", executor.ErrTimeout)}},
+			wantErr: "codex execution: executor timed out", wantCalls: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := newMockLogger("progress.txt")
+			claude := newMockExecutor([]executor.Result{
+				{Output: "done", Signal: processor.SignalCodexDone},         // codex evaluation
+				{Output: "review done", Signal: processor.SignalReviewDone}, // post-codex review loop
+			})
+			codex := newMockExecutor(tc.codex)
+
+			cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+				CodexRetryCount: 2, AppConfig: testAppConfig(t)}
+			r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+			err := r.Run(context.Background())
+
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Len(t, codex.RunCalls(), tc.wantCalls)
+			lines := printedLines(log)
+			assert.Equal(t, tc.wantRetries, strings.Count(lines, "codex execution failed:"))
+			if tc.wantRetries > 0 {
+				assert.Contains(t, lines, "retry 1/2 in 1ms")
+			}
+			if tc.wantRetries > 1 {
+				assert.Contains(t, lines, "retry 2/2 in 2ms", "backoff doubles the delay")
+			}
+		})
+	}
+}
+
+func TestRunner_CodexPhase_RetryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	log := newMockLogger("progress.txt")
+	claude := newMockExecutor(nil)
+	codex := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+		cancel() // ctrl+c while codex runs
+		return executor.Result{Error: context.Canceled}
+	}}
+
+	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+		CodexRetryCount: 2, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+	err := r.Run(ctx)
+
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, codex.RunCalls(), 1, "canceled run is not retried")
+	assert.NotContains(t, printedLines(log), "execution failed")
+}
+
+func TestRunner_CustomReview_Retry(t *testing.T) {
+	log := newMockLogger("progress.txt")
+	claude := newMockExecutor([]executor.Result{
+		{Output: "done", Signal: processor.SignalCodexDone},         // custom evaluation
+		{Output: "review done", Signal: processor.SignalReviewDone}, // post-codex review loop
+	})
+	appCfg := testAppConfig(t)
+	appCfg.ExternalReviewTool = "custom"
+	appCfg.CustomReviewScript = "/path/to/script.sh"
+	customExec := &executor.CustomExecutor{Script: appCfg.CustomReviewScript}
+	idx := 0
+	customExec.SetRunner(&mockCustomRunnerImpl{idx: &idx, results: []executor.Result{
+		{Error: errors.New("connection reset")},
+		{Output: "found issue in foo.go:10"},
+	}})
+
+	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, IterationDelayMs: 1,
+		CodexRetryCount: 2, AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), customExec, &status.PhaseHolder{})
+	require.NoError(t, r.Run(context.Background()))
+
+	assert.Equal(t, 2, idx, "custom script retried once")
+	assert.Contains(t, printedLines(log), "custom execution failed:")
+}
+
 func TestRunner_ClaudeExecution_Error(t *testing.T) {
 	tmpDir := t.TempDir()
 	planFile := filepath.Join(tmpDir, "plan.md")
@@ -398,8 +718,10 @@ func TestRunner_ConfigValues(t *testing.T) {
 		name               string
 		iterationDelayMs   int
 		taskRetryCount     int
+		codexRetryCount    int
 		expectedDelay      time.Duration
 		expectedRetryCount int
+		expectedCodexRetry int
 	}{
 		{
 			name:               "default values",
@@ -407,6 +729,7 @@ func TestRunner_ConfigValues(t *testing.T) {
 			taskRetryCount:     0,
 			expectedDelay:      processor.DefaultIterationDelay,
 			expectedRetryCount: 1,
+			expectedCodexRetry: processor.DefaultCodexRetryCount,
 		},
 		{
 			name:               "custom delay",
@@ -414,6 +737,7 @@ func TestRunner_ConfigValues(t *testing.T) {
 			taskRetryCount:     0,
 			expectedDelay:      500 * time.Millisecond,
 			expectedRetryCount: 1,
+			expectedCodexRetry: processor.DefaultCodexRetryCount,
 		},
 		{
 			name:               "custom retry count",
@@ -421,6 +745,14 @@ func TestRunner_ConfigValues(t *testing.T) {
 			taskRetryCount:     3,
 			expectedDelay:      processor.DefaultIterationDelay,
 			expectedRetryCount: 3,
+			expectedCodexRetry: processor.DefaultCodexRetryCount,
+		},
+		{
+			name:               "custom codex retry count",
+			codexRetryCount:    5,
+			expectedDelay:      processor.DefaultIterationDelay,
+			expectedRetryCount: 1,
+			expectedCodexRetry: 5,
 		},
 	}
 
@@ -433,12 +765,14 @@ func TestRunner_ConfigValues(t *testing.T) {
 			cfg := processor.Config{
 				IterationDelayMs: tc.iterationDelayMs,
 				TaskRetryCount:   tc.taskRetryCount,
+				CodexRetryCount:  tc.codexRetryCount,
 			}
 			r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
 
 			testCfg := r.TestConfig()
 			assert.Equal(t, tc.expectedDelay, testCfg.IterationDelay)
 			assert.Equal(t, tc.expectedRetryCount, testCfg.TaskRetryCount)
+			assert.Equal(t, tc.expectedCodexRetry, testCfg.CodexRetryCount)
 		})
 	}
 }
@@ -469,6 +803,11 @@ func TestRunner_HasUncompletedTasks(t *testing.T) {
 			content:  "# Plan\n- [x] Task 1\n  - [ ] Subtask",
 			expected: true,
 		},
+		{
+			name:     "checkbox in front-matter ignored",
+			content:  "---\nnotes: |\n  - [ ] not a task\n---\n# Plan\n- [x] Task 1",
+			expected: false,
+		},
 	}
 
 	for _, tc := range tests {
@@ -490,24 +829,39 @@ func TestRunner_HasUncompletedTasks(t *testing.T) {
 }
 
 func TestRunner_HasUncompletedTasks_CompletedDir(t *testing.T) {
-	tmpDir := t.TempDir()
-	plansDir := filepath.Join(tmpDir, "docs", "plans")
-	completedDir := filepath.Join(plansDir, "completed")
-	require.NoError(t, os.MkdirAll(completedDir, 0o700))
-
-	// file is in completed/, but config references original path
-	originalPath := filepath.Join(plansDir, "plan.md")
-	completedPath := filepath.Join(completedDir, "plan.md")
-	require.NoError(t, os.WriteFile(completedPath, []byte("# Plan\n- [ ] Task 1"), 0o600))
+	month := time.Now().Format("2006-01")
+	tests := []struct {
+		name       string
+		appConfig  *config.Config
+		completed  string // location of the moved plan relative to the plans dir
+		content    string
+		incomplete bool
+	}{
+		{name: "uncompleted tasks", completed: "completed/plan.md", content: "# Plan\n- [ ] Task 1", incomplete: true},
+		{name: "all tasks done", completed: "completed/plan.md", content: "# Plan\n- [x] Task 1"},
+		{name: "custom completed dir", appConfig: &config.Config{CompletedDir: "archive"},
+			completed: "archive/plan.md", content: "# Plan\n- [x] Task 1"},
+		{name: "dated layout", appConfig: &config.Config{CompletedDir: "archive", CompletedDirDateLayout: "2006-01"},
+			completed: "archive/" + month + "/plan.md", content: "# Plan\n- [x] Task 1"},
+		{name: "custom dir not configured", completed: "archive/plan.md", content: "# Plan\n- [x] Task 1",
+			incomplete: true}, // not found, assumed incomplete
+	}
 
-	log := newMockLogger("")
-	claude := newMockExecutor(nil)
-	codex := newMockExecutor(nil)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			plansDir := filepath.Join(t.TempDir(), "docs", "plans")
+			completedPath := filepath.Join(plansDir, filepath.FromSlash(tc.completed))
+			require.NoError(t, os.MkdirAll(filepath.Dir(completedPath), 0o700))
+			require.NoError(t, os.WriteFile(completedPath, []byte(tc.content), 0o600))
 
-	cfg := processor.Config{PlanFile: originalPath}
-	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+			// file is in the completed dir, but config references original path
+			cfg := processor.Config{PlanFile: filepath.Join(plansDir, "plan.md"), AppConfig: tc.appConfig}
+			r := processor.NewWithExecutors(cfg, newMockLogger(""), newMockExecutor(nil), newMockExecutor(nil), nil,
+				&status.PhaseHolder{})
 
-	assert.True(t, r.TestHasUncompletedTasks())
+			assert.Equal(t, tc.incomplete, r.TestHasUncompletedTasks())
+		})
+	}
 }
 
 func TestRunner_BuildCodexPrompt_CompletedDir(t *testing.T) {
@@ -565,110 +919,819 @@ func TestRunner_TaskRetryCount_UsedCorrectly(t *testing.T) {
 	assert.Contains(t, err.Error(), "FAILED signal")
 	// should have tried 3 times: initial + 2 retries
 	assert.Len(t, claude.RunCalls(), 3)
-}
 
-// newMockInputCollector creates a mock input collector with predefined answers.
-func newMockInputCollector(answers []string) *mocks.InputCollectorMock {
-	idx := 0
-	return &mocks.InputCollectorMock{
-		AskQuestionFunc: func(_ context.Context, _ string, _ []string) (string, error) {
-			if idx >= len(answers) {
-				return "", errors.New("no more mock answers")
-			}
-			answer := answers[idx]
-			idx++
-			return answer, nil
-		},
-	}
-}
+	// retries back off from the iteration delay, doubling each time
+	lines := printedLines(log)
+	assert.Contains(t, lines, "retry 1/2 in 1ms")
+	assert.Contains(t, lines, "retry 2/2 in 2ms")
+
+	t.Run("backoff grows up to the max delay", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "error", Signal: status.Failed},
+			{Error: errors.New("claude exited with error: read: connection reset by peer")},
+			{Output: "error", Signal: status.Failed},
+			{Output: "error", Signal: status.Failed},
+		})
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, TaskRetryCount: 3,
+			IterationDelayMs: 1, RetryBaseDelayMs: 2, RetryMaxDelayMs: 5, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		err := r.Run(context.Background())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "FAILED signal")
+		assert.Len(t, claude.RunCalls(), 4)
+		lines := printedLines(log)
+		assert.Contains(t, lines, "retry 1/3 in 2ms")
+		assert.Contains(t, lines, "task failed: claude exited with error: read: connection reset by peer, retry 2/3 in 4ms")
+		assert.Contains(t, lines, "retry 3/3 in 5ms", "delay is capped by retry_max_delay_ms")
+	})
 
-func TestRunner_RunPlan_Success(t *testing.T) {
-	log := newMockLogger("progress-plan.txt")
-	claude := newMockExecutor([]executor.Result{
-		{Output: "plan created", Signal: status.PlanReady},
+	t.Run("transient error retried then succeeds", func(t *testing.T) {
+		donePlan := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(donePlan, []byte("# Plan\n- [x] Task 1"), 0o600))
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Error: errors.New("claude exited with error: dial tcp: i/o timeout")},
+			{Output: "done", Signal: status.Completed},
+		})
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: donePlan, MaxIterations: 10, TaskRetryCount: 1,
+			IterationDelayMs: 1, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+
+		require.NoError(t, r.Run(context.Background()))
+		assert.Len(t, claude.RunCalls(), 2)
 	})
-	codex := newMockExecutor(nil)
-	inputCollector := newMockInputCollector(nil)
 
-	cfg := processor.Config{
-		Mode:             processor.ModePlan,
-		PlanDescription:  "add health check endpoint",
-		MaxIterations:    50,
-		IterationDelayMs: 1,
-		AppConfig:        testAppConfig(t),
-	}
-	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	t.Run("rate limit is not retried", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "You've hit your limit", Error: &executor.PatternMatchError{Pattern: "You've hit your limit", HelpCmd: "claude /usage"}},
+			{Output: "done", Signal: status.Completed},
+		})
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, TaskRetryCount: 2,
+			IterationDelayMs: 1, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		err := r.Run(context.Background())
+
+		var patternErr *executor.PatternMatchError
+		require.ErrorAs(t, err, &patternErr)
+		assert.Len(t, claude.RunCalls(), 1)
+		assert.NotContains(t, printedLines(log), "retry")
+	})
 
-	require.NoError(t, err)
-	assert.Len(t, claude.RunCalls(), 1)
+	t.Run("other errors are not retried", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Error: errors.New("claude exited with error: exit status 2")},
+			{Output: "done", Signal: status.Completed},
+		})
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, TaskRetryCount: 2,
+			IterationDelayMs: 1, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		err := r.Run(context.Background())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exit status 2")
+		assert.Len(t, claude.RunCalls(), 1)
+	})
 }
 
-func TestRunner_RunPlan_WithQuestion(t *testing.T) {
-	log := newMockLogger("progress-plan.txt")
-	questionSignal := `Let me ask a question.
+func TestRunner_ExecutorTimeout_RetriesTask(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
 
-<<<RALPHEX:QUESTION>>>
-{"question": "Which cache backend?", "options": ["Redis", "In-memory", "File-based"]}
-<<<RALPHEX:END>>>`
+	log := newMockLogger("progress.txt")
 
-	claude := newMockExecutor([]executor.Result{
-		{Output: questionSignal},                           // first iteration - asks question
-		{Output: "plan created", Signal: status.PlanReady}, // second iteration - completes
-	})
+	// every call hangs until its context is done
+	claude := &mocks.ExecutorMock{
+		RunFunc: func(ctx context.Context, _ string) executor.Result {
+			<-ctx.Done()
+			return executor.Result{Error: ctx.Err()}
+		},
+	}
 	codex := newMockExecutor(nil)
-	inputCollector := newMockInputCollector([]string{"Redis"})
 
 	cfg := processor.Config{
-		Mode:             processor.ModePlan,
-		PlanDescription:  "add caching layer",
-		MaxIterations:    50,
+		Mode:              processor.ModeFull,
+		PlanFile:          planFile,
+		MaxIterations:     10,
+		TaskRetryCount:    2,
+		ExecutorTimeoutMs: 10,
+		// use 1ms delay for faster tests
 		IterationDelayMs: 1,
 		AppConfig:        testAppConfig(t),
 	}
 	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	r.SetInputCollector(inputCollector)
 	err := r.Run(context.Background())
 
-	require.NoError(t, err)
-	assert.Len(t, claude.RunCalls(), 2)
-	assert.Len(t, inputCollector.AskQuestionCalls(), 1)
-	assert.Equal(t, "Which cache backend?", inputCollector.AskQuestionCalls()[0].Question)
-	assert.Equal(t, []string{"Redis", "In-memory", "File-based"}, inputCollector.AskQuestionCalls()[0].Options)
+	require.Error(t, err)
+	require.ErrorIs(t, err, executor.ErrTimeout)
+	assert.NotErrorIs(t, err, context.DeadlineExceeded)
+	// should have tried 3 times: initial + 2 retries
+	assert.Len(t, claude.RunCalls(), 3)
+	assert.Equal(t, 3, strings.Count(printedLines(log), "execution timed out after 10ms"))
 }
 
-func TestRunner_RunPlan_NoPlanDescription(t *testing.T) {
-	log := newMockLogger("")
-	claude := newMockExecutor(nil)
-	codex := newMockExecutor(nil)
-	inputCollector := newMockInputCollector(nil)
+func TestRunner_StallDetection(t *testing.T) {
+	tests := []struct {
+		name      string
+		commits   bool
+		wantCalls int
+		wantErr   string
+	}{
+		{name: "identical output, no commits", commits: false, wantCalls: 3, wantErr: "task phase stalled"},
+		{name: "identical output but commits happening", commits: true, wantCalls: 10, wantErr: "max iterations (10) reached"},
+	}
 
-	cfg := processor.Config{Mode: processor.ModePlan, AppConfig: testAppConfig(t)}
-	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	r.SetInputCollector(inputCollector)
-	err := r.Run(context.Background())
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			planFile := filepath.Join(tmpDir, "plan.md")
+			require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
 
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "plan description required")
+			log := newMockLogger("progress.txt")
+			claude := &mocks.ExecutorMock{
+				RunFunc: func(_ context.Context, _ string) executor.Result {
+					return executor.Result{Output: "let me look at the plan again"}
+				},
+			}
+			commit := 0
+			gitMock := &mocks.GitCheckerMock{
+				HeadHashFunc: func() (string, error) {
+					if tc.commits {
+						commit++
+					}
+					return fmt.Sprintf("head-%d", commit), nil
+				},
+			}
+
+			cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, StallIterations: 3,
+				IterationDelayMs: 1, AppConfig: testAppConfig(t)}
+			r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+			r.SetGitChecker(gitMock)
+			err := r.Run(context.Background())
+
+			require.ErrorContains(t, err, tc.wantErr)
+			assert.Equal(t, !tc.commits, errors.Is(err, processor.ErrStalled))
+			assert.Len(t, claude.RunCalls(), tc.wantCalls)
+		})
+	}
 }
 
-func TestRunner_RunPlan_NoInputCollector(t *testing.T) {
-	log := newMockLogger("")
-	claude := newMockExecutor(nil)
-	codex := newMockExecutor(nil)
+func TestRunner_StallDetection_DisabledByDefault(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
 
-	cfg := processor.Config{Mode: processor.ModePlan, PlanDescription: "test", AppConfig: testAppConfig(t)}
-	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	// don't set input collector
+	claude := &mocks.ExecutorMock{
+		RunFunc: func(_ context.Context, _ string) executor.Result { return executor.Result{Output: "same"} },
+	}
+	gitMock := &mocks.GitCheckerMock{HeadHashFunc: func() (string, error) { return "head", nil }}
+
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+		AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	r.SetGitChecker(gitMock)
 	err := r.Run(context.Background())
 
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "input collector required")
+	require.ErrorContains(t, err, "max iterations (5) reached")
+	assert.Len(t, claude.RunCalls(), 5)
 }
 
-func TestRunner_RunPlan_FailedSignal(t *testing.T) {
-	log := newMockLogger("progress-plan.txt")
+func TestRunner_TokenUsage(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	// first iteration doesn't report tokens, second one does and completes
+	claude := newMockExecutor([]executor.Result{
+		{Output: "working"},
+		{Output: "done", Signal: status.Completed, InputTokens: 1200, OutputTokens: 300},
+	})
+	appCfg := testAppConfig(t)
+	appCfg.CostPer1kInput, appCfg.CostPer1kOutput = 0.003, 0.015
+	log := newMockLogger("progress.txt")
+
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+		AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	require.NoError(t, r.Run(context.Background()))
+
+	phases, total := r.Usage()
+	assert.Equal(t, []processor.Usage{
+		{Phase: status.PhaseTask, InputTokens: 1200, OutputTokens: 300, Calls: 2, Reported: 1},
+	}, phases)
+	assert.Equal(t, processor.Usage{InputTokens: 1200, OutputTokens: 300, Calls: 2, Reported: 1}, total)
+
+	lines := printedLines(log)
+	assert.Contains(t, lines, "tokens: n/a, run total n/a")
+	assert.Contains(t, lines, "tokens: 1.2k in / 300 out, run total 1.2k in / 300 out (~$0.01)")
+}
+
+func TestRunner_TaskProgress(t *testing.T) {
+	tests := []struct {
+		name        string
+		commits     bool
+		wantWarning bool
+	}{
+		{name: "tasks committed", commits: true},
+		{name: "tasks checked off without commit", commits: false, wantWarning: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			planFile := filepath.Join(tmpDir, "plan.md")
+			plans := []string{
+				"# Plan\n### Task 1\n- [x] setup\n- [ ] add config\n  validation\n### Task 2\n- [ ] add tests\n  - [ ] nested\n",
+				"# Plan\n### Task 1\n- [x] setup\n- [x] add config\n  validation\n### Task 2\n- [ ] add tests\n  - [ ] nested\n",
+				"# Plan\n### Task 1\n- [x] setup\n- [x] add config\n  validation\n### Task 2\n- [x] add tests\n  - [x] nested\n",
+			}
+			require.NoError(t, os.WriteFile(planFile, []byte(plans[0]), 0o600))
+
+			// each call checks off the next task, the last one completes
+			calls, commit := 0, 0
+			claude := &mocks.ExecutorMock{
+				RunFunc: func(_ context.Context, _ string) executor.Result {
+					calls++
+					require.NoError(t, os.WriteFile(planFile, []byte(plans[calls]), 0o600))
+					if tc.commits {
+						commit++
+					}
+					if calls == len(plans)-1 {
+						return executor.Result{Output: "done", Signal: status.Completed}
+					}
+					return executor.Result{Output: "working"}
+				},
+			}
+			gitMock := &mocks.GitCheckerMock{
+				HeadHashFunc:       func() (string, error) { return fmt.Sprintf("head-%d", commit), nil },
+				CommitSubjectsFunc: func(_, _ string) ([]string, error) { return make([]string, commit), nil },
+			}
+			log := newMockLogger("progress.txt")
+
+			cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+				AppConfig: testAppConfig(t)}
+			r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+			r.SetGitChecker(gitMock)
+			require.NoError(t, r.Run(context.Background()))
+
+			lines := printedLines(log)
+			assert.Contains(t, lines, "plan progress: 1/4 done")
+			assert.Contains(t, lines, "task completed: add config validation (2/4 done)")
+			assert.Contains(t, lines, "task completed: add tests (3/4 done)")
+			assert.Contains(t, lines, "task completed: nested (4/4 done)")
+			warnings := strings.Count(lines, "checked off without a new commit")
+			if tc.wantWarning {
+				assert.Equal(t, 2, warnings)
+				return
+			}
+			assert.Zero(t, warnings)
+		})
+	}
+}
+
+func TestRunner_TaskCommitVerification(t *testing.T) {
+	const (
+		open = "# Plan\n\nnotes  \n### Task 1\n- [ ] add config\n  validation\n### Task 2\n- [ ] add tests\r\n"
+		done = "# Plan\n\nnotes  \n### Task 1\n- [x] add config\n  validation\n### Task 2\n- [X] add tests\r\n"
+	)
+
+	tests := []struct {
+		name        string
+		commits     bool
+		strict      bool
+		wantWarning bool
+		wantReopen  bool
+	}{
+		{name: "tasks committed", commits: true, strict: true},
+		{name: "no commits warns", wantWarning: true},
+		{name: "no commits with strict verification reopens", strict: true, wantWarning: true, wantReopen: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			planFile := filepath.Join(t.TempDir(), "plan.md")
+			require.NoError(t, os.WriteFile(planFile, []byte(open), 0o600))
+
+			// the first call checks off both tasks and reports completion, the retry after a reopen commits them
+			calls, commits := 0, 0
+			var reopened string
+			claude := &mocks.ExecutorMock{
+				RunFunc: func(_ context.Context, _ string) executor.Result {
+					calls++
+					if calls == 2 {
+						data, err := os.ReadFile(planFile)
+						require.NoError(t, err)
+						reopened = string(data)
+						commits = 2
+					}
+					require.NoError(t, os.WriteFile(planFile, []byte(done), 0o600))
+					if tc.commits {
+						commits = 2
+					}
+					return executor.Result{Output: "done", Signal: status.Completed}
+				},
+			}
+			gitMock := &mocks.GitCheckerMock{
+				HeadHashFunc: func() (string, error) { return fmt.Sprintf("head-%d", commits), nil },
+				CommitSubjectsFunc: func(from, to string) ([]string, error) {
+					assert.Equal(t, "head-0", from)
+					assert.Equal(t, "head-2", to)
+					return []string{"add config validation", "add tests"}, nil
+				},
+			}
+			log := newMockLogger("progress.txt")
+
+			cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1,
+				TaskCommitRatio: 0.5, StrictTaskVerify: tc.strict, AppConfig: testAppConfig(t)}
+			r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+			r.SetGitChecker(gitMock)
+			require.NoError(t, r.Run(context.Background()))
+
+			lines := printedLines(log)
+			assert.Equal(t, tc.wantWarning, strings.Contains(lines, "2 task(s) completed with 0 commit(s) since the task phase started"))
+			assert.Equal(t, tc.wantWarning, strings.Contains(lines, "checked off without a commit: add config validation"))
+			if !tc.wantReopen {
+				assert.Equal(t, 1, calls)
+				assert.NotContains(t, lines, "reopened")
+				return
+			}
+			assert.Equal(t, 2, calls, "the task phase goes on after the reopen")
+			assert.Contains(t, lines, "reopened 2 task(s) checked off without a commit, continuing...")
+			assert.Equal(t, open, reopened, "only the checkboxes are flipped back")
+			assert.Len(t, gitMock.CommitSubjectsCalls(), 1)
+		})
+	}
+}
+
+func TestRunner_ExecutorTimeout_RecoversOnRetry(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	log := newMockLogger("progress.txt")
+
+	// first call hangs, the rest complete immediately
+	calls := 0
+	claude := &mocks.ExecutorMock{
+		RunFunc: func(ctx context.Context, _ string) executor.Result {
+			calls++
+			if calls == 1 {
+				<-ctx.Done()
+				return executor.Result{Error: ctx.Err()}
+			}
+			return executor.Result{Output: "done", Signal: status.Completed}
+		},
+	}
+
+	cfg := processor.Config{
+		Mode:              processor.ModeTasksOnly,
+		PlanFile:          planFile,
+		MaxIterations:     10,
+		TaskRetryCount:    1,
+		ExecutorTimeoutMs: 10,
+		IterationDelayMs:  1,
+		AppConfig:         testAppConfig(t),
+	}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	err := r.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Len(t, claude.RunCalls(), 2)
+}
+
+func TestRunner_ExecutorTimeout_FailsReview(t *testing.T) {
+	log := newMockLogger("progress.txt")
+
+	claude := &mocks.ExecutorMock{
+		RunFunc: func(ctx context.Context, _ string) executor.Result {
+			<-ctx.Done()
+			return executor.Result{Error: ctx.Err()}
+		},
+	}
+
+	cfg := processor.Config{
+		Mode:              processor.ModeReview,
+		MaxIterations:     50,
+		TaskRetryCount:    2,
+		ExecutorTimeoutMs: 10,
+		IterationDelayMs:  1,
+		AppConfig:         testAppConfig(t),
+	}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	err := r.Run(context.Background())
+
+	require.ErrorIs(t, err, executor.ErrTimeout)
+	// review calls are not retried
+	assert.Len(t, claude.RunCalls(), 1)
+}
+
+func TestRunner_ToolTimeouts(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        processor.Config
+		wantClaude time.Duration
+		wantReview time.Duration
+	}{
+		{name: "no limits"},
+		{name: "executor timeout for both", cfg: processor.Config{ExecutorTimeoutMs: 5000},
+			wantClaude: 5 * time.Second, wantReview: 5 * time.Second},
+		{name: "per-tool minutes take precedence", cfg: processor.Config{ExecutorTimeoutMs: 5000, ClaudeTimeoutMin: 30},
+			wantClaude: 30 * time.Minute, wantReview: 5 * time.Second},
+		{name: "codex minutes only", cfg: processor.Config{CodexTimeoutMin: 45}, wantReview: 45 * time.Minute},
+		{name: "claude minutes only", cfg: processor.Config{ClaudeTimeoutMin: 20}, wantClaude: 20 * time.Minute},
+		{name: "both minutes override executor timeout",
+			cfg:        processor.Config{ExecutorTimeoutMs: 5000, ClaudeTimeoutMin: 20, CodexTimeoutMin: 45},
+			wantClaude: 20 * time.Minute, wantReview: 45 * time.Minute},
+		{name: "codex minutes with executor timeout", cfg: processor.Config{ExecutorTimeoutMs: 5000, CodexTimeoutMin: 45},
+			wantClaude: 5 * time.Second, wantReview: 45 * time.Minute},
+		{name: "negative executor timeout means no limit", cfg: processor.Config{ExecutorTimeoutMs: -1}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.cfg.AppConfig = testAppConfig(t)
+			r := processor.NewWithExecutors(tc.cfg, newMockLogger("progress.txt"), newMockExecutor(nil), newMockExecutor(nil), nil,
+				&status.PhaseHolder{})
+			assert.Equal(t, tc.wantClaude, r.TestConfig().ClaudeTimeout)
+			assert.Equal(t, tc.wantReview, r.TestConfig().ReviewTimeout)
+		})
+	}
+}
+
+func TestRunner_ClaudeTimeout_OverridesExecutorTimeout(t *testing.T) {
+	log := newMockLogger("progress.txt")
+	// each call takes longer than executor_timeout_ms but well within claude_timeout_minutes
+	claude := &mocks.ExecutorMock{
+		RunFunc: func(ctx context.Context, _ string) executor.Result {
+			select {
+			case <-time.After(30 * time.Millisecond):
+				return executor.Result{Output: "review done", Signal: status.ReviewDone}
+			case <-ctx.Done():
+				return executor.Result{Error: ctx.Err()}
+			}
+		},
+	}
+
+	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, ExecutorTimeoutMs: 10, ClaudeTimeoutMin: 1,
+		IterationDelayMs: 1, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	require.NoError(t, r.Run(context.Background()))
+	assert.NotContains(t, printedLines(log), "execution timed out")
+}
+
+func TestRunner_ExecutorTimeout_ParentCancelNotTimeout(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+
+	log := newMockLogger("progress.txt")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	claude := &mocks.ExecutorMock{
+		RunFunc: func(ctx context.Context, _ string) executor.Result {
+			cancel()
+			<-ctx.Done()
+			return executor.Result{Error: ctx.Err()}
+		},
+	}
+
+	cfg := processor.Config{
+		Mode:              processor.ModeTasksOnly,
+		PlanFile:          planFile,
+		MaxIterations:     10,
+		TaskRetryCount:    2,
+		ExecutorTimeoutMs: 60000,
+		IterationDelayMs:  1,
+		AppConfig:         testAppConfig(t),
+	}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	err := r.Run(ctx)
+
+	require.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, executor.ErrTimeout)
+	assert.Len(t, claude.RunCalls(), 1)
+}
+
+// resumeTestConfig returns a full-mode config with distinguishable prompts and external review disabled,
+// with the progress log (and so the checkpoint) in a temp dir.
+func resumeTestConfig(t *testing.T) processor.Config {
+	t.Helper()
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	appCfg := testAppConfig(t)
+	appCfg.TaskPrompt = "TASK PROMPT"
+	appCfg.ReviewFirstPrompt = "REVIEW FIRST PROMPT"
+	appCfg.ReviewSecondPrompt = "REVIEW SECOND PROMPT"
+	appCfg.ExternalReviewTool = "none"
+
+	return processor.Config{
+		Mode:             processor.ModeFull,
+		PlanFile:         planFile,
+		ProgressPath:     filepath.Join(tmpDir, "progress-plan.txt"),
+		MaxIterations:    10,
+		IterationDelayMs: 1,
+		AppConfig:        appCfg,
+	}
+}
+
+func TestRunner_Resume_AfterCancelInReview(t *testing.T) {
+	cfg := resumeTestConfig(t)
+	cpPath := processor.CheckpointPath(cfg.ProgressPath)
+
+	// first run: tasks complete, then the run is canceled during the first review
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	claude := &mocks.ExecutorMock{
+		RunFunc: func(_ context.Context, prompt string) executor.Result {
+			if prompt == "TASK PROMPT" {
+				return executor.Result{Signal: status.Completed}
+			}
+			cancel()
+			return executor.Result{Error: context.Canceled}
+		},
+	}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	err := r.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	cp, err := processor.LoadCheckpoint(cpPath)
+	require.NoError(t, err)
+	assert.Equal(t, processor.StageReview, cp.Stage)
+	assert.Equal(t, status.PhaseReview, cp.Phase)
+	assert.Equal(t, cfg.PlanFile, cp.PlanFile)
+	assert.Equal(t, processor.ModeFull, cp.Mode)
+
+	// resumed run starts at review, tasks are not re-run
+	claude = newMockExecutor([]executor.Result{
+		{Signal: status.ReviewDone}, // first review
+		{Signal: status.ReviewDone}, // pre-codex review loop
+		{Signal: status.ReviewDone}, // post-codex review loop
+	})
+	cfg.Resume = true
+	r = processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	require.NoError(t, r.Run(context.Background()))
+
+	calls := claude.RunCalls()
+	require.Len(t, calls, 3)
+	assert.Equal(t, "REVIEW FIRST PROMPT", calls[0].Prompt)
+	for _, c := range calls {
+		assert.NotEqual(t, "TASK PROMPT", c.Prompt)
+	}
+
+	// checkpoint is removed after successful completion
+	_, err = os.Stat(cpPath)
+	assert.True(t, os.IsNotExist(err), "checkpoint should be removed")
+}
+
+func TestRunner_Resume_PostReviewSkipsEarlierStages(t *testing.T) {
+	cfg := resumeTestConfig(t)
+	cfg.Resume = true
+	cp := `{"plan_file":"` + cfg.PlanFile + `","mode":"full","stage":"post_review","phase":"review"}`
+	require.NoError(t, os.WriteFile(processor.CheckpointPath(cfg.ProgressPath), []byte(cp), 0o600))
+
+	claude := newMockExecutor([]executor.Result{{Signal: status.ReviewDone}})
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	require.NoError(t, r.Run(context.Background()))
+
+	calls := claude.RunCalls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "REVIEW SECOND PROMPT", calls[0].Prompt)
+}
+
+func TestRunner_Resume_IgnoresMismatchedCheckpoint(t *testing.T) {
+	tests := []struct {
+		name string
+		cp   func(cfg processor.Config) string
+	}{
+		{name: "other plan", cp: func(processor.Config) string {
+			return `{"plan_file":"other.md","mode":"full","stage":"post_review"}`
+		}},
+		{name: "other mode", cp: func(cfg processor.Config) string {
+			return `{"plan_file":"` + cfg.PlanFile + `","mode":"review","stage":"post_review"}`
+		}},
+		{name: "corrupted", cp: func(processor.Config) string { return "{broken" }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := resumeTestConfig(t)
+			cfg.Resume = true
+			require.NoError(t, os.WriteFile(processor.CheckpointPath(cfg.ProgressPath), []byte(tc.cp(cfg)), 0o600))
+
+			claude := newMockExecutor([]executor.Result{
+				{Signal: status.Completed},  // tasks
+				{Signal: status.ReviewDone}, // first review
+				{Signal: status.ReviewDone}, // pre-codex review loop
+				{Signal: status.ReviewDone}, // post-codex review loop
+			})
+			r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+			require.NoError(t, r.Run(context.Background()))
+
+			calls := claude.RunCalls()
+			require.Len(t, calls, 4)
+			assert.Equal(t, "TASK PROMPT", calls[0].Prompt)
+		})
+	}
+}
+
+func TestRunner_Checkpoint_KeepsDashboardPhaseCallback(t *testing.T) {
+	cfg := resumeTestConfig(t)
+	holder := &status.PhaseHolder{}
+	var phases []status.Phase
+	holder.OnChange(func(_, cur status.Phase) { phases = append(phases, cur) })
+
+	claude := newMockExecutor([]executor.Result{
+		{Signal: status.Completed},
+		{Signal: status.ReviewDone},
+		{Signal: status.ReviewDone},
+		{Signal: status.ReviewDone},
+	})
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, holder)
+	require.NoError(t, r.Run(context.Background()))
+
+	// callback registered before the runner still fires
+	assert.Equal(t, []status.Phase{status.PhaseTask, status.PhaseReview, status.PhaseCodex, status.PhaseReview}, phases)
+}
+
+// newMockInputCollector creates a mock input collector with predefined answers.
+func newMockInputCollector(answers []string) *mocks.InputCollectorMock {
+	idx := 0
+	return &mocks.InputCollectorMock{
+		AskQuestionFunc: func(_ context.Context, _ string, _ []string) (string, error) {
+			if idx >= len(answers) {
+				return "", errors.New("no more mock answers")
+			}
+			answer := answers[idx]
+			idx++
+			return answer, nil
+		},
+	}
+}
+
+func TestRunner_RunPlan_Success(t *testing.T) {
+	log := newMockLogger("progress-plan.txt")
+	claude := newMockExecutor([]executor.Result{
+		{Output: "plan created", Signal: status.PlanReady},
+	})
+	codex := newMockExecutor(nil)
+	inputCollector := newMockInputCollector(nil)
+
+	cfg := processor.Config{
+		Mode:             processor.ModePlan,
+		PlanDescription:  "add health check endpoint",
+		MaxIterations:    50,
+		IterationDelayMs: 1,
+		AppConfig:        testAppConfig(t),
+	}
+	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+	r.SetInputCollector(inputCollector)
+	err := r.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Len(t, claude.RunCalls(), 1)
+}
+
+func TestRunner_RunPlan_WithQuestion(t *testing.T) {
+	log := newMockLogger("progress-plan.txt")
+	questionSignal := `Let me ask a question.
+
+<<<RALPHEX:QUESTION>>>
+{"question": "Which cache backend?", "options": ["Redis", "In-memory", "File-based"]}
+<<<RALPHEX:END>>>`
+
+	claude := newMockExecutor([]executor.Result{
+		{Output: questionSignal},                           // first iteration - asks question
+		{Output: "plan created", Signal: status.PlanReady}, // second iteration - completes
+	})
+	codex := newMockExecutor(nil)
+	inputCollector := newMockInputCollector([]string{"Redis"})
+
+	cfg := processor.Config{
+		Mode:             processor.ModePlan,
+		PlanDescription:  "add caching layer",
+		MaxIterations:    50,
+		IterationDelayMs: 1,
+		AppConfig:        testAppConfig(t),
+	}
+	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+	r.SetInputCollector(inputCollector)
+	err := r.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Len(t, claude.RunCalls(), 2)
+	assert.Len(t, inputCollector.AskQuestionCalls(), 1)
+	assert.Equal(t, "Which cache backend?", inputCollector.AskQuestionCalls()[0].Question)
+	assert.Equal(t, []string{"Redis", "In-memory", "File-based"}, inputCollector.AskQuestionCalls()[0].Options)
+}
+
+func TestRunner_RunPlan_WithTextQuestion(t *testing.T) {
+	log := newMockLogger("progress-plan.txt")
+	questionSignal := `<<<RALPHEX:QUESTION>>>
+{"question": "Describe the expected API", "type": "multiline", "default": "REST"}
+<<<RALPHEX:END>>>`
+
+	claude := newMockExecutor([]executor.Result{
+		{Output: questionSignal},
+		{Output: "plan created", Signal: status.PlanReady},
+	})
+	inputCollector := &mocks.InputCollectorMock{
+		AskTextFunc: func(_ context.Context, _, _ string, _ bool) (string, error) {
+			return "GET /health\nGET /metrics", nil
+		},
+	}
+
+	cfg := processor.Config{
+		Mode:             processor.ModePlan,
+		PlanDescription:  "add monitoring",
+		MaxIterations:    50,
+		IterationDelayMs: 1,
+		AppConfig:        testAppConfig(t),
+	}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	r.SetInputCollector(inputCollector)
+	require.NoError(t, r.Run(context.Background()))
+
+	require.Len(t, inputCollector.AskTextCalls(), 1)
+	call := inputCollector.AskTextCalls()[0]
+	assert.Equal(t, "Describe the expected API", call.Question)
+	assert.Equal(t, "REST", call.DefaultAnswer)
+	assert.True(t, call.Multiline)
+	assert.Empty(t, inputCollector.AskQuestionCalls())
+
+	require.Len(t, log.LogQuestionCalls(), 1)
+	assert.Nil(t, log.LogQuestionCalls()[0].Options)
+	require.Len(t, log.LogAnswerCalls(), 1)
+	assert.Equal(t, "GET /health\nGET /metrics", log.LogAnswerCalls()[0].Answer)
+}
+
+func TestRunner_RunPlan_MalformedQuestion(t *testing.T) {
+	log := newMockLogger("progress-plan.txt")
+	malformedSignal := `<<<RALPHEX:QUESTION>>>
+{"question": "Which cache backend?", "options": ["Redis",
+<<<RALPHEX:END>>>`
+
+	claude := newMockExecutor([]executor.Result{
+		{Output: malformedSignal},                          // first iteration - broken question
+		{Output: "plan created", Signal: status.PlanReady}, // second iteration - completes
+	})
+	inputCollector := newMockInputCollector(nil)
+
+	cfg := processor.Config{
+		Mode:             processor.ModePlan,
+		PlanDescription:  "add caching layer",
+		MaxIterations:    50,
+		IterationDelayMs: 1,
+		AppConfig:        testAppConfig(t),
+	}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	r.SetInputCollector(inputCollector)
+	require.NoError(t, r.Run(context.Background()))
+
+	assert.Empty(t, inputCollector.AskQuestionCalls())
+	calls := claude.RunCalls()
+	require.Len(t, calls, 2)
+	assert.NotContains(t, calls[0].Prompt, "MALFORMED QUESTION")
+	assert.Contains(t, calls[1].Prompt, "MALFORMED QUESTION")
+	assert.Contains(t, calls[1].Prompt, "invalid JSON")
+}
+
+func TestRunner_RunPlan_NoPlanDescription(t *testing.T) {
+	log := newMockLogger("")
+	claude := newMockExecutor(nil)
+	codex := newMockExecutor(nil)
+	inputCollector := newMockInputCollector(nil)
+
+	cfg := processor.Config{Mode: processor.ModePlan, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+	r.SetInputCollector(inputCollector)
+	err := r.Run(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plan description required")
+}
+
+func TestRunner_RunPlan_NoInputCollector(t *testing.T) {
+	log := newMockLogger("")
+	claude := newMockExecutor(nil)
+	codex := newMockExecutor(nil)
+
+	cfg := processor.Config{Mode: processor.ModePlan, PlanDescription: "test", AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+	// don't set input collector
+	err := r.Run(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "input collector required")
+}
+
+func TestRunner_RunPlan_FailedSignal(t *testing.T) {
+	log := newMockLogger("progress-plan.txt")
 	claude := newMockExecutor([]executor.Result{
 		{Output: "error", Signal: status.Failed},
 	})
@@ -718,6 +1781,33 @@ func TestRunner_RunPlan_MaxIterations(t *testing.T) {
 	assert.Contains(t, err.Error(), "max plan iterations")
 }
 
+func TestRunner_RunPlan_MaxPlanIterationsConfigured(t *testing.T) {
+	log := newMockLogger("progress-plan.txt")
+	claude := newMockExecutor([]executor.Result{
+		{Output: "exploring..."},
+		{Output: "still exploring..."},
+		{Output: "more exploring..."},
+	})
+	inputCollector := newMockInputCollector(nil)
+
+	// configured value overrides derived max(5, 10/5) = 5
+	cfg := processor.Config{
+		Mode:              processor.ModePlan,
+		PlanDescription:   "test",
+		MaxIterations:     10,
+		MaxPlanIterations: 2,
+		IterationDelayMs:  1,
+		AppConfig:         testAppConfig(t),
+	}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	r.SetInputCollector(inputCollector)
+	err := r.Run(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max plan iterations (2)")
+	assert.Len(t, claude.RunCalls(), 2)
+}
+
 func TestRunner_RunPlan_ContextCanceled(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // cancel immediately
@@ -855,39 +1945,456 @@ func TestRunner_New_CodexNotInstalled_CustomReviewStillWorks(t *testing.T) {
 	}
 	assert.False(t, foundWarning, "should NOT log warning about codex when using custom external review")
 
-	// verify runner was created
-	assert.NotNil(t, r, "runner should be created")
-}
+	// verify runner was created
+	assert.NotNil(t, r, "runner should be created")
+}
+
+func TestRunner_New_CodexNotInstalled_NoneReviewStillWorks(t *testing.T) {
+	log := newMockLogger("progress.txt")
+
+	appCfg := testAppConfig(t)
+	appCfg.CodexCommand = "/nonexistent/path/to/codex" // command that doesn't exist
+	appCfg.ExternalReviewTool = "none"                 // external review disabled
+
+	cfg := processor.Config{
+		Mode:          processor.ModeCodexOnly,
+		MaxIterations: 50,
+		CodexEnabled:  true,
+		AppConfig:     appCfg,
+	}
+
+	// use processor.New (not NewWithExecutors) to trigger LookPath check
+	r := processor.New(cfg, log, &status.PhaseHolder{})
+
+	// verify NO warning was logged (no external review means no codex needed)
+	var foundWarning bool
+	for _, call := range log.PrintCalls() {
+		if strings.Contains(call.Format, "codex not found") {
+			foundWarning = true
+			break
+		}
+	}
+	assert.False(t, foundWarning, "should NOT log warning about codex when external review is disabled")
+
+	// verify runner was created
+	assert.NotNil(t, r, "runner should be created")
+}
+
+func TestRunner_New_GeminiBackend_RunTasksOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	// fake gemini cli emitting stream-json events, records its args
+	argsFile := filepath.Join(tmpDir, "args.txt")
+	script := filepath.Join(tmpDir, "gemini.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+echo "$@" > `+argsFile+`
+echo '{"type":"init","session_id":"s1","model":"gemini-2.5-pro"}'
+echo '{"type":"message","role":"assistant","content":"all done <<<RALPHEX:ALL_TASKS_DONE>>>","delta":true}'
+echo '{"type":"result","status":"success"}'
+`), 0o700)) //nolint:gosec // test script must be executable
+
+	appCfg := testAppConfig(t)
+	appCfg.AgentBackend = processor.AgentGemini
+	appCfg.ClaudeCommand = "/nonexistent/path/to/claude" // must not be used
+	appCfg.GeminiCommand = script
+	appCfg.GeminiArgs = "--yolo --output-format stream-json"
+
+	log := newMockLogger("progress.txt")
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, AppConfig: appCfg}
+	r := processor.New(cfg, log, &status.PhaseHolder{})
+	require.NoError(t, r.Run(context.Background()))
+
+	args, err := os.ReadFile(argsFile) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(args), "--yolo --output-format stream-json -p "), "got args: %s", args)
+
+	assert.Contains(t, printedLines(log), "all done <<<RALPHEX:ALL_TASKS_DONE>>>")
+}
+
+func TestRunner_New_TraceRawOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	// fake claude cli emitting stream-json events and a colored stderr line
+	script := filepath.Join(tmpDir, "claude.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+echo '{"type":"assistant","message":{"content":[{"type":"text","text":"done <<<RALPHEX:ALL_TASKS_DONE>>>"}]}}'
+printf '\033[1mslow down\033[0m\n' >&2
+echo '{"type":"result","result":"done <<<RALPHEX:ALL_TASKS_DONE>>>"}'
+`), 0o700)) //nolint:gosec // test script must be executable
+
+	assistantLine := `[trace] {"type":"assistant","message":{"content":[{"type":"text","text":"done <<<RALPHEX:ALL_TASKS_DONE>>>"}]}}`
+	resultLine := `[trace] {"type":"result","result":"done <<<RALPHEX:ALL_TASKS_DONE>>>"}`
+	tests := []struct {
+		name    string
+		trace   bool
+		noColor bool
+		want    []string
+	}{
+		{name: "default level", want: nil},
+		{name: "trace", trace: true,
+			want: []string{assistantLine, "[trace] \x1b[1mslow down\x1b[0m", resultLine}},
+		{name: "trace without colors", trace: true, noColor: true,
+			want: []string{assistantLine, "[trace] slow down", resultLine}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			appCfg := testAppConfig(t)
+			appCfg.ClaudeCommand = script
+			appCfg.ClaudeArgs = "--output-format stream-json"
+
+			log := newMockLogger("progress.txt")
+			cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10,
+				Trace: tc.trace, NoColor: tc.noColor, AppConfig: appCfg}
+			r := processor.New(cfg, log, &status.PhaseHolder{})
+			require.NoError(t, r.Run(context.Background()))
+
+			var traced []string
+			for _, call := range log.PrintRawCalls() {
+				if line := fmt.Sprintf(call.Format, call.Args...); strings.HasPrefix(line, "[trace] ") {
+					traced = append(traced, strings.TrimSuffix(line, "\n"))
+				}
+			}
+			assert.Equal(t, tc.want, traced)
+			assert.Contains(t, printedLines(log), "done <<<RALPHEX:ALL_TASKS_DONE>>>", "parsed output is still printed")
+		})
+	}
+}
+
+func TestRunner_New_OllamaBackend_RunTasksOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	// fake ollama server, the reply has no marker so the completion heuristic must pick it up
+	var gotReq struct{ Model, System string }
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&gotReq))
+		_, _ = w.Write([]byte(`{"response":"All tasks are completed.","done":false}` + "\n" + `{"response":"","done":true}`))
+	}))
+	defer srv.Close()
+
+	appCfg := testAppConfig(t)
+	appCfg.AgentBackend = processor.AgentOllama
+	appCfg.ClaudeCommand = "/nonexistent/path/to/claude" // must not be used
+	appCfg.OllamaURL = srv.URL
+	appCfg.OllamaModel = "qwen2.5-coder"
+
+	log := newMockLogger("progress.txt")
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, AppConfig: appCfg}
+	r := processor.New(cfg, log, &status.PhaseHolder{})
+	require.NoError(t, r.Run(context.Background()))
+
+	assert.Equal(t, "qwen2.5-coder", gotReq.Model)
+	assert.Contains(t, gotReq.System, "<<<RALPHEX:ALL_TASKS_DONE>>>", "signal protocol is injected by default")
+	assert.Contains(t, printedLines(log), "All tasks are completed.")
+}
+
+func TestRunner_PauseResume(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	log := newMockLogger("progress.txt")
+	claude := newMockExecutor([]executor.Result{{Output: "task done", Signal: status.Completed}})
+	pause := &status.PauseHolder{}
+	pause.Pause()
+
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	r.SetPauseHolder(pause)
+
+	done := make(chan error, 1)
+	go func() { done <- r.Run(context.Background()) }()
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(printedLines(log), "paused by user, waiting for resume...")
+	}, time.Second, 5*time.Millisecond)
+	assert.Empty(t, claude.RunCalls(), "no iteration runs while paused")
+
+	pause.Resume()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("run didn't finish after resume")
+	}
+	assert.Len(t, claude.RunCalls(), 1)
+	assert.Contains(t, printedLines(log), "resumed by user")
+}
+
+func TestRunner_PauseCanceled(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+
+	log := newMockLogger("progress.txt")
+	claude := newMockExecutor(nil)
+	pause := &status.PauseHolder{}
+	pause.Pause()
+
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	r.SetPauseHolder(pause)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := r.Run(ctx)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, claude.RunCalls())
+	assert.True(t, pause.Paused())
+}
+
+func TestRunner_PauseDuringIteration(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	log := newMockLogger("progress.txt")
+	pause := &status.PauseHolder{}
+	paused := make(chan struct{})
+	release := make(chan struct{})
+	claude := &mocks.ExecutorMock{}
+	claude.RunFunc = func(_ context.Context, _ string) executor.Result {
+		if len(claude.RunCalls()) == 1 {
+			// pause arrives while the agent call is in progress, the call itself is not cut off
+			pause.Pause()
+			close(paused)
+			<-release
+			return executor.Result{Output: "first iteration"}
+		}
+		return executor.Result{Output: "task done", Signal: status.Completed}
+	}
+
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+		AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	r.SetPauseHolder(pause)
+
+	done := make(chan error, 1)
+	go func() { done <- r.Run(context.Background()) }()
+
+	<-paused
+	close(release)
+	require.Eventually(t, func() bool {
+		return strings.Contains(printedLines(log), "paused by user, waiting for resume...")
+	}, time.Second, 5*time.Millisecond)
+	select {
+	case err := <-done:
+		t.Fatalf("run finished while paused: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Len(t, claude.RunCalls(), 1, "next iteration waits for resume")
+
+	pause.Resume()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("run didn't finish after resume")
+	}
+	assert.Len(t, claude.RunCalls(), 2)
+}
+
+func TestRunner_PlanChangedBetweenIterations(t *testing.T) {
+	// the first iteration pauses the run, the plan is changed while paused and the run resumed
+	start := func(t *testing.T, change func(planFile string)) (*mocks.LoggerMock, *mocks.ExecutorMock, error) {
+		t.Helper()
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+
+		log := newMockLogger("progress.txt")
+		pause := &status.PauseHolder{}
+		paused := make(chan struct{})
+		claude := &mocks.ExecutorMock{}
+		claude.RunFunc = func(_ context.Context, _ string) executor.Result {
+			if len(claude.RunCalls()) == 1 {
+				require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600), "agent's own edit")
+				pause.Pause()
+				close(paused)
+				return executor.Result{Output: "first iteration"}
+			}
+			return executor.Result{Output: "task done", Signal: status.Completed}
+		}
+
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		r.SetPauseHolder(pause)
+
+		done := make(chan error, 1)
+		go func() { done <- r.Run(context.Background()) }()
+		<-paused
+		require.Eventually(t, func() bool {
+			return strings.Contains(printedLines(log), "paused by user, waiting for resume...")
+		}, time.Second, 5*time.Millisecond)
+		change(planFile)
+		pause.Resume()
+		select {
+		case err := <-done:
+			return log, claude, err
+		case <-time.After(5 * time.Second):
+			t.Fatal("run didn't finish after resume")
+		}
+		return nil, nil, nil
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		log, claude, err := start(t, func(string) {})
+		require.NoError(t, err)
+		assert.Len(t, claude.RunCalls(), 2)
+		assert.NotContains(t, printedLines(log), "changed since the last iteration", "agent's own edits are expected")
+	})
+
+	t.Run("edited", func(t *testing.T) {
+		log, claude, err := start(t, func(planFile string) {
+			require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1\n- [x] Task 2"), 0o600))
+		})
+		require.NoError(t, err)
+		assert.Len(t, claude.RunCalls(), 2)
+		assert.Contains(t, printedLines(log), "changed since the last iteration")
+		assert.Contains(t, printedLines(log), "plan progress: 2/2 done")
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		_, claude, err := start(t, func(planFile string) { require.NoError(t, os.Remove(planFile)) })
+		require.ErrorIs(t, err, processor.ErrPlanRemoved)
+		assert.Len(t, claude.RunCalls(), 1)
+	})
+}
+
+func TestRunner_RequestStop(t *testing.T) {
+	t.Run("task phase stops after the current iteration", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		planFile := filepath.Join(tmpDir, "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1\n- [ ] Task 2\n- [ ] Task 3"), 0o600))
+
+		log := newMockLogger("progress.txt")
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			AppConfig: testAppConfig(t)}
+		var r *processor.Runner
+		claude := &mocks.ExecutorMock{RunFunc: func(_ context.Context, _ string) executor.Result {
+			if len(log.PrintSectionCalls()) == 2 {
+				assert.True(t, r.RequestStop(), "stop requested mid-run")
+				assert.False(t, r.RequestStop(), "second request is a no-op")
+			}
+			return executor.Result{Output: "task done"}
+		}}
+		r = processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+
+		err := r.Run(context.Background())
+		require.ErrorIs(t, err, processor.ErrStopped)
+		assert.EqualError(t, err, "task phase: stopped by user after iteration 2")
+		assert.Len(t, claude.RunCalls(), 2, "the iteration in progress finishes, the next one doesn't start")
+		assert.Contains(t, printedLines(log), "stopped by user after iteration 2")
+		assert.False(t, r.RequestStop(), "no stop once the run returned")
+	})
+
+	t.Run("stop between stages", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		planFile := filepath.Join(tmpDir, "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+		log := newMockLogger("progress.txt")
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			AppConfig: testAppConfig(t)}
+		var r *processor.Runner
+		claude := &mocks.ExecutorMock{RunFunc: func(_ context.Context, _ string) executor.Result {
+			r.RequestStop()
+			return executor.Result{Output: "all done", Signal: status.Completed}
+		}}
+		r = processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+
+		err := r.Run(context.Background())
+		require.ErrorIs(t, err, processor.ErrStopped)
+		assert.Contains(t, err.Error(), "stopped by user before review phase")
+		assert.Len(t, claude.RunCalls(), 1, "review doesn't start after the task phase")
+	})
+
+	t.Run("stop resumes a paused run", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		planFile := filepath.Join(tmpDir, "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor(nil)
+		pause := &status.PauseHolder{}
+		pause.Pause()
+		cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		r.SetPauseHolder(pause)
+
+		done := make(chan error, 1)
+		go func() { done <- r.Run(context.Background()) }()
+		require.Eventually(t, func() bool {
+			return strings.Contains(printedLines(log), "paused by user, waiting for resume...")
+		}, time.Second, 5*time.Millisecond)
+
+		require.True(t, r.RequestStop())
+		select {
+		case err := <-done:
+			require.ErrorIs(t, err, processor.ErrStopped)
+			assert.Contains(t, err.Error(), "stopped by user before task phase")
+		case <-time.After(5 * time.Second):
+			t.Fatal("run didn't stop")
+		}
+		assert.Empty(t, claude.RunCalls())
+		assert.False(t, pause.Paused())
+	})
+}
+
+func TestRunner_ErrorPatternMatch_UserDefinedRegex(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"response":"server said: HTTP 503 overloaded","done":true}`))
+	}))
+	defer srv.Close()
+
+	appCfg := testAppConfig(t)
+	appCfg.AgentBackend = processor.AgentOllama
+	appCfg.OllamaURL = srv.URL
+	appCfg.OllamaModel = "qwen2.5-coder"
+	appCfg.ErrorPatterns = []config.ErrorPattern{{Pattern: `HTTP 5\d\d`, HelpCmd: "curl localhost:11434"}}
 
-func TestRunner_New_CodexNotInstalled_NoneReviewStillWorks(t *testing.T) {
 	log := newMockLogger("progress.txt")
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, AppConfig: appCfg}
+	r := processor.New(cfg, log, &status.PhaseHolder{})
+	err := r.Run(context.Background())
 
-	appCfg := testAppConfig(t)
-	appCfg.CodexCommand = "/nonexistent/path/to/codex" // command that doesn't exist
-	appCfg.ExternalReviewTool = "none"                 // external review disabled
+	var patternErr *executor.PatternMatchError
+	require.ErrorAs(t, err, &patternErr)
+	assert.Equal(t, `HTTP 5\d\d`, patternErr.Pattern)
+	assert.Equal(t, "curl localhost:11434", patternErr.HelpCmd)
+	assert.Contains(t, printedLines(log), "run 'curl localhost:11434' for more information")
+}
 
-	cfg := processor.Config{
-		Mode:          processor.ModeCodexOnly,
-		MaxIterations: 50,
-		CodexEnabled:  true,
-		AppConfig:     appCfg,
-	}
+func TestRunner_ErrorPatternMatch_GeminiBackendToolName(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
 
-	// use processor.New (not NewWithExecutors) to trigger LookPath check
-	r := processor.New(cfg, log, &status.PhaseHolder{})
+	log := newMockLogger("progress.txt")
+	claude := newMockExecutor([]executor.Result{
+		{Output: "quota exceeded", Error: &executor.PatternMatchError{Pattern: "Quota exceeded", HelpCmd: "gemini /stats"}},
+	})
+	appCfg := testAppConfig(t)
+	appCfg.AgentBackend = processor.AgentGemini
 
-	// verify NO warning was logged (no external review means no codex needed)
-	var foundWarning bool
-	for _, call := range log.PrintCalls() {
-		if strings.Contains(call.Format, "codex not found") {
-			foundWarning = true
-			break
-		}
-	}
-	assert.False(t, foundWarning, "should NOT log warning about codex when external review is disabled")
+	cfg := processor.Config{Mode: processor.ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, AppConfig: appCfg}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	err := r.Run(context.Background())
 
-	// verify runner was created
-	assert.NotNil(t, r, "runner should be created")
+	var patternErr *executor.PatternMatchError
+	require.ErrorAs(t, err, &patternErr)
+	assert.Contains(t, printedLines(log), `error: detected "Quota exceeded" in gemini output`)
+	assert.Contains(t, printedLines(log), "run 'gemini /stats' for more information")
 }
 
 func TestRunner_ErrorPatternMatch_ClaudeInTaskPhase(t *testing.T) {
@@ -925,6 +2432,103 @@ func TestRunner_ErrorPatternMatch_ClaudeInTaskPhase(t *testing.T) {
 	assert.True(t, foundHelpLog, "should log help command")
 }
 
+func TestRunner_Metrics(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] Task 1"), 0o600))
+
+	log := newMockLogger("progress.txt")
+	claude := newMockExecutor([]executor.Result{
+		{Output: "working..."},
+		{Output: "You've hit your limit", Error: &executor.PatternMatchError{Pattern: "You've hit your limit", HelpCmd: "claude /usage"}},
+	})
+	metrics := &mocks.MetricsMock{
+		IterationFunc:    func(status.Phase, string) {},
+		ExecutorCallFunc: func(status.Phase, string, time.Duration, bool) {},
+		RateLimitHitFunc: func(status.Phase, string) {},
+	}
+
+	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	r.SetMetrics(metrics)
+	require.Error(t, r.Run(context.Background()))
+
+	iterations := metrics.IterationCalls()
+	require.Len(t, iterations, 2)
+	for _, call := range iterations {
+		assert.Equal(t, status.PhaseTask, call.Phase)
+		assert.Equal(t, "full", call.Mode)
+	}
+	calls := metrics.ExecutorCallCalls()
+	require.Len(t, calls, 2)
+	assert.Equal(t, status.PhaseTask, calls[0].Phase)
+	assert.False(t, calls[0].Failed)
+	assert.True(t, calls[1].Failed)
+	require.Len(t, metrics.RateLimitHitCalls(), 1)
+	assert.Equal(t, status.PhaseTask, metrics.RateLimitHitCalls()[0].Phase)
+}
+
+func TestRunner_Notifier(t *testing.T) {
+	reviewsDone := []executor.Result{
+		{Output: "review done", Signal: status.ReviewDone}, // first review
+		{Output: "review done", Signal: status.ReviewDone}, // pre-codex review loop
+		{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop (codex disabled)
+	}
+	type event struct {
+		title string
+		level notify.Level
+	}
+	tests := []struct {
+		name       string
+		results    []executor.Result
+		notifyErr  error
+		wantErr    bool
+		wantEvents []event
+		wantBody   string // expected in the body of the last notification
+		wantLog    string
+	}{
+		{name: "success", results: reviewsDone,
+			wantEvents: []event{{"ralphex started", notify.LevelInfo}, {"ralphex completed", notify.LevelInfo}},
+			wantBody:   "mode: review\nbranch: feature-x"},
+		{name: "failure aborting the review phase", results: []executor.Result{{Error: errors.New("claude crashed")}},
+			wantErr:    true,
+			wantEvents: []event{{"ralphex started", notify.LevelInfo}, {"ralphex failed", notify.LevelError}},
+			wantBody:   "phase: review\nerror: first review: claude execution: claude crashed"},
+		{name: "notifier failure is not fatal", results: reviewsDone, notifyErr: errors.New("notify-send not found"),
+			wantEvents: []event{{"ralphex started", notify.LevelInfo}, {"ralphex completed", notify.LevelInfo}},
+			wantLog:    "warning: notification failed: notify-send not found"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := newMockLogger("progress.txt")
+			notifier := &mocks.NotifierMock{NotifyFunc: func(string, string, notify.Level) error { return tc.notifyErr }}
+			cfg := processor.Config{Mode: processor.ModeReview, Branch: "feature-x", MaxIterations: 50, AppConfig: testAppConfig(t)}
+			r := processor.NewWithExecutors(cfg, log, newMockExecutor(tc.results), newMockExecutor(nil), nil, &status.PhaseHolder{})
+			r.SetNotifier(notifier)
+
+			err := r.Run(context.Background())
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			calls := notifier.NotifyCalls()
+			require.Len(t, calls, len(tc.wantEvents))
+			for i, want := range tc.wantEvents {
+				assert.Equal(t, want.title, calls[i].Title)
+				assert.Equal(t, want.level, calls[i].Level)
+			}
+			if tc.wantBody != "" {
+				assert.Contains(t, calls[len(calls)-1].Body, tc.wantBody)
+			}
+			if tc.wantLog != "" {
+				assert.Contains(t, printedLines(log), tc.wantLog)
+			}
+		})
+	}
+}
+
 func TestRunner_ErrorPatternMatch_CodexInReviewPhase(t *testing.T) {
 	log := newMockLogger("progress.txt")
 	claude := newMockExecutor([]executor.Result{
@@ -1121,6 +2725,52 @@ func TestRunner_RunPlan_PlanDraft_ReviseFlow(t *testing.T) {
 	assert.Contains(t, secondPrompt, "PREVIOUS DRAFT FEEDBACK")
 }
 
+func TestRunner_RunPlan_PlanDraft_EditFlow(t *testing.T) {
+	log := newMockLogger("progress-plan.txt")
+	planDraftSignal := `<<<RALPHEX:PLAN_DRAFT>>>
+# Test Plan
+## Tasks
+- [ ] Task 1
+<<<RALPHEX:END>>>`
+
+	claude := newMockExecutor([]executor.Result{
+		{Output: planDraftSignal},                          // first iteration - emits draft
+		{Output: "plan created", Signal: status.PlanReady}, // second iteration - writes the edited plan
+	})
+	editedPlan := "# Test Plan\n## Tasks\n- [ ] Task 1 (edited by hand)"
+	inputCollector := newMockInputCollectorWithDraftReview(nil, []struct {
+		action   string
+		feedback string
+		err      error
+	}{
+		{action: "edit", feedback: editedPlan, err: nil},
+	})
+
+	cfg := processor.Config{
+		Mode:             processor.ModePlan,
+		PlanDescription:  "add health endpoint",
+		MaxIterations:    50,
+		IterationDelayMs: 1,
+		AppConfig:        testAppConfig(t),
+	}
+	r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	r.SetInputCollector(inputCollector)
+	err := r.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Len(t, claude.RunCalls(), 2)
+	assert.Len(t, inputCollector.AskDraftReviewCalls(), 1)
+
+	// the edited plan is sent to claude as the authoritative draft, not logged as feedback
+	secondPrompt := claude.RunCalls()[1].Prompt
+	assert.Contains(t, secondPrompt, "USER-EDITED DRAFT")
+	assert.Contains(t, secondPrompt, editedPlan)
+	assert.NotContains(t, secondPrompt, "PREVIOUS DRAFT FEEDBACK")
+	require.Len(t, log.LogDraftReviewCalls(), 1)
+	assert.Equal(t, "edit", log.LogDraftReviewCalls()[0].Action)
+	assert.Empty(t, log.LogDraftReviewCalls()[0].Feedback)
+}
+
 func TestRunner_RunPlan_PlanDraft_RejectFlow(t *testing.T) {
 	log := newMockLogger("progress-plan.txt")
 	planDraftSignal := `<<<RALPHEX:PLAN_DRAFT>>>
@@ -1422,6 +3072,83 @@ func TestRunner_Finalize_FailedSignalDoesNotBlockSuccess(t *testing.T) {
 	assert.True(t, foundFailureLog, "should log finalize failure signal")
 }
 
+func TestRunner_ReviewModes_EmptyRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    processor.Mode
+		err     error
+		wantErr string
+		wantLog bool
+	}{
+		{name: "review skipped", mode: processor.ModeReview, wantLog: true},
+		{name: "codex only skipped", mode: processor.ModeCodexOnly, wantLog: true},
+		{name: "git error", mode: processor.ModeReview, err: errors.New("bad revision"),
+			wantErr: "list files to review: bad revision"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := newMockLogger("progress.txt")
+			claude := newMockExecutor(nil)
+			codex := newMockExecutor(nil)
+			gitMock := &mocks.GitCheckerMock{
+				HeadHashFunc:     func() (string, error) { return "head", nil },
+				ChangedFilesFunc: func(string) ([]string, error) { return nil, tc.err },
+			}
+			cfg := processor.Config{Mode: tc.mode, MaxIterations: 50, CodexEnabled: true, FinalizeEnabled: true,
+				DefaultBranch: "main", BaseBranch: "release", AppConfig: testAppConfig(t)}
+			r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+			r.SetGitChecker(gitMock)
+
+			err := r.Run(context.Background())
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Empty(t, claude.RunCalls(), "no claude calls for an empty range")
+			assert.Empty(t, codex.RunCalls())
+			require.Len(t, gitMock.ChangedFilesCalls(), 1)
+			assert.Equal(t, "release...HEAD", gitMock.ChangedFilesCalls()[0].Base)
+
+			var logged bool
+			for _, c := range log.PrintCalls() {
+				if fmt.Sprintf(c.Format, c.Args...) == "no changes in release...HEAD, nothing to review, skipping review phases" {
+					logged = true
+				}
+			}
+			assert.Equal(t, tc.wantLog, logged)
+		})
+	}
+}
+
+func TestRunner_ReviewPrompt_SinceMergeBase(t *testing.T) {
+	// main passes the merge-base of --since as the base, the review covers only the commits after it
+	const mergeBase = "3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8c7d6e5f4a"
+	claude := newMockExecutor([]executor.Result{
+		{Output: "review done", Signal: status.ReviewDone}, // first review
+		{Output: "review done", Signal: status.ReviewDone}, // pre-codex review loop
+		{Output: "review done", Signal: status.ReviewDone}, // post-codex review loop (codex disabled)
+	})
+	gitMock := &mocks.GitCheckerMock{
+		HeadHashFunc:     func() (string, error) { return "head", nil },
+		ChangedFilesFunc: func(string) ([]string, error) { return []string{"pkg/api.go"}, nil },
+	}
+	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, IterationDelayMs: 1, DefaultBranch: "main",
+		BaseBranch: mergeBase, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+	r.SetGitChecker(gitMock)
+	require.NoError(t, r.Run(context.Background()))
+
+	require.NotEmpty(t, gitMock.ChangedFilesCalls())
+	assert.Equal(t, mergeBase+"...HEAD", gitMock.ChangedFilesCalls()[0].Base)
+	require.NotEmpty(t, claude.RunCalls())
+	prompt := claude.RunCalls()[0].Prompt
+	assert.Contains(t, prompt, "git diff "+mergeBase+"...HEAD")
+	assert.Contains(t, prompt, "git log "+mergeBase+"..HEAD")
+	assert.Contains(t, prompt, "pkg/api.go")
+	assert.NotContains(t, prompt, "main...HEAD", "the default branch is not the review base")
+}
+
 func TestRunner_Finalize_RunsInReviewOnlyMode(t *testing.T) {
 	log := newMockLogger("progress.txt")
 	claude := newMockExecutor([]executor.Result{
@@ -1447,6 +3174,54 @@ func TestRunner_Finalize_RunsInReviewOnlyMode(t *testing.T) {
 	assert.Len(t, claude.RunCalls(), 4)
 }
 
+func TestRunner_Finalize_DiffSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		git      bool
+		stat     string
+		statErr  error
+		wantText string
+	}{
+		{name: "changes since start", git: true, stat: " a.go | 2 +-\n 1 file changed", wantText: "changes:\n a.go | 2 +-\n 1 file changed"},
+		{name: "no changes", git: true, wantText: "changes:\n(no changes committed during this run)"},
+		{name: "diff error", git: true, statErr: errors.New("bad revision"), wantText: "changes:\n(diff summary unavailable)"},
+		{name: "no git checker", wantText: "changes:\n(diff summary unavailable)"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			claude := newMockExecutor([]executor.Result{
+				{Output: "review done", Signal: status.ReviewDone},
+				{Output: "review done", Signal: status.ReviewDone},
+				{Output: "review done", Signal: status.ReviewDone},
+				{Output: "finalize done"},
+			})
+			appCfg := testAppConfig(t)
+			appCfg.FinalizePrompt = "changes:\n{{DIFF_SUMMARY}}"
+			cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, FinalizeEnabled: true, AppConfig: appCfg}
+			r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+			gitMock := &mocks.GitCheckerMock{
+				HeadHashFunc:     func() (string, error) { return "start", nil },
+				DiffStatFunc:     func(_, _ string) (string, error) { return tc.stat, tc.statErr },
+				ChangedFilesFunc: func(string) ([]string, error) { return []string{"a.go"}, nil },
+			}
+			if tc.git {
+				r.SetGitChecker(gitMock)
+			}
+
+			require.NoError(t, r.Run(context.Background()))
+			calls := claude.RunCalls()
+			require.Len(t, calls, 4)
+			assert.Equal(t, tc.wantText, calls[3].Prompt)
+			if tc.git {
+				require.Len(t, gitMock.DiffStatCalls(), 1)
+				assert.Equal(t, "start", gitMock.DiffStatCalls()[0].From)
+				assert.Equal(t, "HEAD", gitMock.DiffStatCalls()[0].To)
+			}
+		})
+	}
+}
+
 func TestRunner_Finalize_RunsInCodexOnlyMode(t *testing.T) {
 	log := newMockLogger("progress.txt")
 	claude := newMockExecutor([]executor.Result{
@@ -1723,6 +3498,95 @@ func TestRunner_ExternalReviewTool_Custom_NotConfigured(t *testing.T) {
 	assert.Contains(t, err.Error(), "custom review script not configured")
 }
 
+func TestRunner_ExternalReviewTool_Gemini(t *testing.T) {
+	t.Run("evaluates findings with gemini prompt", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "done", Signal: processor.SignalCodexDone},         // gemini evaluation
+			{Output: "review done", Signal: processor.SignalReviewDone}, // post-codex review loop
+		})
+		codex := newMockExecutor(nil)
+		gemini := newMockExecutor([]executor.Result{{Output: "- [high] foo.go:10 - nil dereference"}})
+
+		appCfg := testAppConfig(t)
+		appCfg.ExternalReviewTool = "gemini"
+
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
+		r.TestSetGemini(gemini)
+		require.NoError(t, r.Run(context.Background()))
+
+		assert.Empty(t, codex.RunCalls(), "codex should not be called when external_review_tool=gemini")
+		require.Len(t, gemini.RunCalls(), 1)
+		assert.Contains(t, gemini.RunCalls()[0].Prompt, "NO ISSUES FOUND")
+		require.Len(t, claude.RunCalls(), 2)
+		assert.Contains(t, claude.RunCalls()[0].Prompt, "Gemini reviewed the code")
+		assert.Contains(t, claude.RunCalls()[0].Prompt, "foo.go:10 - nil dereference")
+
+		var sections []status.Section
+		for _, call := range log.PrintSectionCalls() {
+			sections = append(sections, call.Section)
+		}
+		assert.Contains(t, sections, status.NewGeminiIterationSection(1))
+	})
+
+	t.Run("all findings filtered skips evaluation", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "review done", Signal: processor.SignalReviewDone}, // post-codex review loop
+		})
+		gemini := newMockExecutor([]executor.Result{{Output: "- [low] foo.go:1 - naming nit"}})
+
+		appCfg := testAppConfig(t)
+		appCfg.ExternalReviewTool = "gemini"
+		appCfg.CodexMinSeverity = "medium"
+
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+		r.TestSetGemini(gemini)
+		require.NoError(t, r.Run(context.Background()))
+
+		assert.Len(t, gemini.RunCalls(), 1)
+		require.Len(t, claude.RunCalls(), 1, "claude should only run the post-codex review")
+		assert.NotContains(t, claude.RunCalls()[0].Prompt, "Gemini reviewed the code")
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.ExternalReviewTool = "gemini"
+
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), newMockExecutor(nil), newMockExecutor(nil), nil,
+			&status.PhaseHolder{})
+		err := r.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "gemini reviewer not configured")
+	})
+}
+
+func TestRunner_New_GeminiReviewNotInstalled(t *testing.T) {
+	log := newMockLogger("progress.txt")
+
+	appCfg := testAppConfig(t)
+	appCfg.CodexCommand = "/nonexistent/path/to/codex"
+	appCfg.GeminiCommand = "/nonexistent/path/to/gemini"
+	appCfg.ExternalReviewTool = "gemini"
+
+	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
+	r := processor.New(cfg, log, &status.PhaseHolder{})
+	require.NotNil(t, r)
+
+	var formats []string
+	for _, call := range log.PrintCalls() {
+		formats = append(formats, call.Format)
+	}
+	assert.Contains(t, formats, "warning: gemini not found (%s: %v), disabling external review phase")
+	for _, f := range formats {
+		assert.NotContains(t, f, "codex not found", "gemini review doesn't need the codex binary")
+	}
+	assert.Equal(t, "none", r.ExternalReviewTool())
+}
+
 // mockCustomRunnerImpl is a mock implementation of executor.CustomRunner for testing.
 type mockCustomRunnerImpl struct {
 	results []executor.Result
@@ -1751,6 +3615,7 @@ func TestRunner_ReviewLoop_NoCommitExit(t *testing.T) {
 
 	// mock git checker returns same hash both times (no commits made)
 	gitMock := &mocks.GitCheckerMock{
+		ChangedFilesFunc: func(string) ([]string, error) { return []string{"a.go"}, nil },
 		HeadHashFunc: func() (string, error) {
 			return "abc123def456abc123def456abc123def456abcd", nil
 		},
@@ -1797,6 +3662,7 @@ func TestRunner_ReviewLoop_CommitDetected_ContinuesLoop(t *testing.T) {
 	}
 	hashIdx := 0
 	gitMock := &mocks.GitCheckerMock{
+		ChangedFilesFunc: func(string) ([]string, error) { return []string{"a.go"}, nil },
 		HeadHashFunc: func() (string, error) {
 			require.Less(t, hashIdx, len(hashes), "unexpected extra HeadHash call #%d", hashIdx)
 			h := hashes[hashIdx]
@@ -1816,58 +3682,113 @@ func TestRunner_ReviewLoop_CommitDetected_ContinuesLoop(t *testing.T) {
 }
 
 func TestRunner_ReviewLoop_GitCheckerNil_SkipsNoCommitCheck(t *testing.T) {
-	log := newMockLogger("progress.txt")
+	tests := []struct {
+		name      string
+		maxReview int
+		wantLoop  int
+	}{
+		{name: "derived cap", wantLoop: 3}, // max(3, 30/10) = 3 per loop
+		{name: "configured cap", maxReview: 5, wantLoop: 5},
+	}
 
-	// ModeReview flow: first review → pre-codex review loop → codex (disabled) → post-codex review loop
-	// max review iterations = max(3, 30/10) = 3 per loop
-	claude := newMockExecutor([]executor.Result{
-		{Output: "review done", Signal: status.ReviewDone}, // first review
-		{Output: "review done", Signal: status.ReviewDone}, // pre-codex review loop (exits immediately)
-		{Output: "looking at code"},                        // post-codex review loop 1
-		{Output: "looking at code"},                        // post-codex review loop 2
-		{Output: "looking at code"},                        // post-codex review loop 3
-	})
-	codex := newMockExecutor(nil)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// ModeReview flow: first review → pre-codex review loop → codex (disabled) → post-codex review loop
+			results := []executor.Result{
+				{Output: "review done", Signal: status.ReviewDone}, // first review
+				{Output: "review done", Signal: status.ReviewDone}, // pre-codex review loop (exits immediately)
+			}
+			for range tc.wantLoop + 1 { // one extra result to catch running past the cap
+				results = append(results, executor.Result{Output: "looking at code"})
+			}
+			claude := newMockExecutor(results)
 
-	// no git checker - nil
-	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 30, CodexEnabled: false, AppConfig: testAppConfig(t)}
-	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	err := r.Run(context.Background())
+			// no git checker - nil
+			cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 30, MaxReviewIterations: tc.maxReview,
+				IterationDelayMs: 1, CodexEnabled: false, AppConfig: testAppConfig(t)}
+			r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+			require.NoError(t, r.Run(context.Background()))
 
-	require.NoError(t, err)
-	// first review + pre-codex loop (1 iteration) + post-codex loop (3 iterations, max reached)
-	assert.Len(t, claude.RunCalls(), 5)
+			// first review + pre-codex loop (1 iteration) + post-codex loop (max reached)
+			assert.Len(t, claude.RunCalls(), 2+tc.wantLoop)
+		})
+	}
 }
 
-func TestRunner_ReviewLoop_GitCheckerError_SkipsNoCommitCheck(t *testing.T) {
-	log := newMockLogger("progress.txt")
+func TestRunner_ReviewLoop_MaxReviewIterations(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxIterations int
+		maxReview     int
+		wantLoopCalls int
+	}{
+		{name: "derived minimum", maxIterations: 10, wantLoopCalls: 3},
+		{name: "derived from max iterations", maxIterations: 50, wantLoopCalls: 5},
+		{name: "configured above derived", maxIterations: 30, maxReview: 6, wantLoopCalls: 6},
+		{name: "configured below minimum", maxIterations: 100, maxReview: 1, wantLoopCalls: 1},
+	}
 
-	// ModeReview flow: first review → pre-codex review loop → codex (disabled) → post-codex review loop
-	// max review iterations = max(3, 30/10) = 3 per loop
-	claude := newMockExecutor([]executor.Result{
-		{Output: "review done", Signal: status.ReviewDone}, // first review
-		{Output: "review done", Signal: status.ReviewDone}, // pre-codex review loop (exits immediately)
-		{Output: "looking at code"},                        // post-codex review loop 1
-		{Output: "looking at code"},                        // post-codex review loop 2
-		{Output: "looking at code"},                        // post-codex review loop 3
-	})
-	codex := newMockExecutor(nil)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// first review and pre-codex loop complete, post-codex loop never signals done
+			results := []executor.Result{
+				{Output: "review done", Signal: status.ReviewDone},
+				{Output: "review done", Signal: status.ReviewDone},
+			}
+			for range tc.wantLoopCalls + 2 {
+				results = append(results, executor.Result{Output: "looking at code"})
+			}
+			claude := newMockExecutor(results)
 
-	// git checker always returns error — should degrade gracefully (run to max iterations)
-	gitMock := &mocks.GitCheckerMock{
-		HeadHashFunc: func() (string, error) {
-			return "", errors.New("git HEAD error")
-		},
+			cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: tc.maxIterations,
+				MaxReviewIterations: tc.maxReview, IterationDelayMs: 1, CodexEnabled: false, AppConfig: testAppConfig(t)}
+			r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+			require.NoError(t, r.Run(context.Background()))
+			assert.Len(t, claude.RunCalls(), 2+tc.wantLoopCalls)
+		})
 	}
+}
 
-	cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 30, CodexEnabled: false, AppConfig: testAppConfig(t)}
-	r := processor.NewWithExecutors(cfg, log, claude, codex, nil, &status.PhaseHolder{})
-	r.SetGitChecker(gitMock)
-	err := r.Run(context.Background())
+func TestRunner_ReviewLoop_GitCheckerError_SkipsNoCommitCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxReview int
+		wantLoop  int
+	}{
+		{name: "derived cap", wantLoop: 3}, // max(3, 30/10) = 3 per loop
+		{name: "configured cap", maxReview: 4, wantLoop: 4},
+	}
 
-	require.NoError(t, err)
-	// first review + pre-codex loop (1 iteration) + post-codex loop (3 iterations, max reached)
-	assert.Len(t, claude.RunCalls(), 5)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// ModeReview flow: first review → pre-codex review loop → codex (disabled) → post-codex review loop
+			results := []executor.Result{
+				{Output: "review done", Signal: status.ReviewDone}, // first review
+				{Output: "review done", Signal: status.ReviewDone}, // pre-codex review loop (exits immediately)
+			}
+			for range tc.wantLoop + 1 { // one extra result to catch running past the cap
+				results = append(results, executor.Result{Output: "looking at code"})
+			}
+			claude := newMockExecutor(results)
+
+			// git checker always returns error — should degrade gracefully (run to max iterations)
+			gitMock := &mocks.GitCheckerMock{
+				ChangedFilesFunc: func(string) ([]string, error) { return []string{"a.go"}, nil },
+				HeadHashFunc: func() (string, error) {
+					return "", errors.New("git HEAD error")
+				},
+			}
+
+			cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 30, MaxReviewIterations: tc.maxReview,
+				IterationDelayMs: 1, CodexEnabled: false, AppConfig: testAppConfig(t)}
+			r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil), nil, &status.PhaseHolder{})
+			r.SetGitChecker(gitMock)
+			require.NoError(t, r.Run(context.Background()))
+
+			// first review + pre-codex loop (1 iteration) + post-codex loop (max reached)
+			assert.Len(t, claude.RunCalls(), 2+tc.wantLoop)
+		})
+	}
 }
 
 // TestRunner_SleepWithContext_CancelDuringDelay verifies that context cancellation
@@ -1912,3 +3833,42 @@ func TestRunner_SleepWithContext_CancelDuringDelay(t *testing.T) {
 	assert.Less(t, elapsed, time.Duration(longDelay)*time.Millisecond,
 		"should exit promptly on cancellation, not wait for full iteration delay")
 }
+
+func TestRunner_PlannedPhases(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     processor.Mode
+		codex    bool
+		tool     string
+		finalize bool
+		want     []status.Phase
+	}{
+		{name: "full", mode: processor.ModeFull, codex: true,
+			want: []status.Phase{status.PhaseTask, status.PhaseReview, status.PhaseCodex, status.PhaseReview}},
+		{name: "full with finalize", mode: processor.ModeFull, codex: true, finalize: true,
+			want: []status.Phase{status.PhaseTask, status.PhaseReview, status.PhaseCodex, status.PhaseReview, status.PhaseFinalize}},
+		{name: "full without external review", mode: processor.ModeFull, codex: false,
+			want: []status.Phase{status.PhaseTask, status.PhaseReview}},
+		{name: "full with tool none", mode: processor.ModeFull, codex: true, tool: "none",
+			want: []status.Phase{status.PhaseTask, status.PhaseReview}},
+		{name: "review", mode: processor.ModeReview, codex: true, tool: "custom",
+			want: []status.Phase{status.PhaseReview, status.PhaseCodex, status.PhaseReview}},
+		{name: "codex only", mode: processor.ModeCodexOnly, codex: true, finalize: true,
+			want: []status.Phase{status.PhaseCodex, status.PhaseReview, status.PhaseFinalize}},
+		{name: "tasks only ignores finalize", mode: processor.ModeTasksOnly, codex: true, finalize: true,
+			want: []status.Phase{status.PhaseTask}},
+		{name: "tasks review ignores codex and finalize", mode: processor.ModeTasksReview, codex: true, finalize: true,
+			want: []status.Phase{status.PhaseTask, status.PhaseReview}},
+		{name: "plan", mode: processor.ModePlan, want: []status.Phase{status.PhasePlan}},
+		{name: "unknown mode", mode: "invalid", want: nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			appCfg := &config.Config{ExternalReviewTool: tc.tool}
+			cfg := processor.Config{Mode: tc.mode, CodexEnabled: tc.codex, FinalizeEnabled: tc.finalize, AppConfig: appCfg}
+			r := processor.NewWithExecutors(cfg, newMockLogger(""), newMockExecutor(nil), newMockExecutor(nil), nil, &status.PhaseHolder{})
+			assert.Equal(t, tc.want, r.PlannedPhases())
+		})
+	}
+}
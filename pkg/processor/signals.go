@@ -28,10 +28,19 @@ var questionSignalRe = regexp.MustCompile(`<<<RALPHEX:QUESTION>>>\s*([\s\S]*?)\s
 // planDraftSignalRe matches the PLAN_DRAFT signal block with plan content
 var planDraftSignalRe = regexp.MustCompile(`<<<RALPHEX:PLAN_DRAFT>>>\s*([\s\S]*?)\s*<<<RALPHEX:END>>>`)
 
+// question types of the QUESTION signal
+const (
+	QuestionChoice    = "choice"    // one of the options, or a typed answer; used when type is not set
+	QuestionText      = "text"      // single-line free-form answer
+	QuestionMultiline = "multiline" // free-form answer of several lines
+)
+
 // QuestionPayload represents a question signal from Claude during plan creation
 type QuestionPayload struct {
 	Question string   `json:"question"`
-	Options  []string `json:"options"`
+	Type     string   `json:"type,omitempty"` // QuestionChoice, QuestionText or QuestionMultiline
+	Options  []string `json:"options,omitempty"`
+	Default  string   `json:"default,omitempty"` // answer of a text question left empty
 	Context  string   `json:"context,omitempty"`
 }
 
@@ -81,12 +90,19 @@ func ParseQuestionPayload(output string) (*QuestionPayload, error) {
 		return nil, fmt.Errorf("malformed question signal: invalid JSON: %w", err)
 	}
 
-	// validate required fields
+	// validate required fields, signals without a type are choice questions
 	if payload.Question == "" {
 		return nil, errors.New("malformed question signal: missing question field")
 	}
-	if len(payload.Options) == 0 {
-		return nil, errors.New("malformed question signal: missing or empty options field")
+	switch payload.Type {
+	case "", QuestionChoice:
+		payload.Type = QuestionChoice
+		if len(payload.Options) == 0 {
+			return nil, errors.New("malformed question signal: missing or empty options field")
+		}
+	case QuestionText, QuestionMultiline:
+	default:
+		return nil, fmt.Errorf("malformed question signal: unknown type %q, expected choice, text or multiline", payload.Type)
 	}
 
 	return &payload, nil
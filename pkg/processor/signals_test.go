@@ -81,6 +81,7 @@ func TestParseQuestionPayload_ValidJSON(t *testing.T) {
 some output after`,
 			expected: &QuestionPayload{
 				Question: "Which cache backend?",
+				Type:     QuestionChoice,
 				Options:  []string{"Redis", "In-memory", "File-based"},
 			},
 		},
@@ -91,6 +92,7 @@ some output after`,
 <<<RALPHEX:END>>>`,
 			expected: &QuestionPayload{
 				Question: "Select authentication method",
+				Type:     QuestionChoice,
 				Options:  []string{"JWT", "Session", "OAuth"},
 				Context:  "Project uses REST API",
 			},
@@ -104,6 +106,7 @@ some output after`,
 <<<RALPHEX:END>>>`,
 			expected: &QuestionPayload{
 				Question: "Pick one",
+				Type:     QuestionChoice,
 				Options:  []string{"A", "B"},
 			},
 		},
@@ -120,9 +123,31 @@ some output after`,
 [10:30:20] waiting for user input...`,
 			expected: &QuestionPayload{
 				Question: "How should data be stored?",
+				Type:     QuestionChoice,
 				Options:  []string{"Database", "File system"},
 			},
 		},
+		{
+			name: "explicit choice type",
+			output: `<<<RALPHEX:QUESTION>>>
+{"question": "Pick one", "type": "choice", "options": ["A", "B"], "default": "A"}
+<<<RALPHEX:END>>>`,
+			expected: &QuestionPayload{Question: "Pick one", Type: QuestionChoice, Options: []string{"A", "B"}, Default: "A"},
+		},
+		{
+			name: "text question without options",
+			output: `<<<RALPHEX:QUESTION>>>
+{"question": "Which port should the server listen on?", "type": "text", "default": "8080"}
+<<<RALPHEX:END>>>`,
+			expected: &QuestionPayload{Question: "Which port should the server listen on?", Type: QuestionText, Default: "8080"},
+		},
+		{
+			name: "multiline question",
+			output: `<<<RALPHEX:QUESTION>>>
+{"question": "Describe the expected API", "type": "multiline", "context": "no spec found"}
+<<<RALPHEX:END>>>`,
+			expected: &QuestionPayload{Question: "Describe the expected API", Type: QuestionMultiline, Context: "no spec found"},
+		},
 	}
 
 	for _, tc := range tests {
@@ -201,6 +226,20 @@ func TestParseQuestionPayload_MalformedJSON(t *testing.T) {
 <<<RALPHEX:END>>>`,
 			errContains: "invalid JSON",
 		},
+		{
+			name: "choice type without options",
+			output: `<<<RALPHEX:QUESTION>>>
+{"question": "test", "type": "choice"}
+<<<RALPHEX:END>>>`,
+			errContains: "missing or empty options field",
+		},
+		{
+			name: "unknown type",
+			output: `<<<RALPHEX:QUESTION>>>
+{"question": "test", "type": "number"}
+<<<RALPHEX:END>>>`,
+			errContains: `unknown type "number", expected choice, text or multiline`,
+		},
 	}
 
 	for _, tc := range tests {
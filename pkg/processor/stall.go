@@ -0,0 +1,61 @@
+package processor
+
+import (
+	"errors"
+	"hash/fnv"
+	"regexp"
+	"strings"
+)
+
+// ErrStalled is returned when task iterations repeat the same output without making commits.
+var ErrStalled = errors.New("task phase stalled")
+
+// stallNumberRe matches digit runs, replaced before hashing so counters and durations don't break similarity
+var stallNumberRe = regexp.MustCompile(`\d+`)
+
+// stallDetector tracks the last task iterations to detect an agent stuck in a loop.
+// an iteration is a stall candidate when its normalized output hashes the same as the previous ones,
+// the run is stalled when the whole window is identical and HEAD didn't move since the window started.
+type stallDetector struct {
+	limit  int // iterations in the window
+	window []stallEntry
+}
+
+type stallEntry struct {
+	hash       uint64
+	headBefore string // HEAD before the iteration ran, empty if unknown
+}
+
+// newStallDetector creates a detector for the given number of iterations, nil if detection is disabled.
+func newStallDetector(limit int) *stallDetector {
+	if limit < 2 {
+		return nil
+	}
+	return &stallDetector{limit: limit}
+}
+
+// record adds an iteration's output and the HEAD hashes around it, returns true if the run is stalled.
+// unknown HEAD (no git checker or a git error) never counts as a stall, commits can't be ruled out.
+func (d *stallDetector) record(output, headBefore, headAfter string) bool {
+	d.window = append(d.window, stallEntry{hash: stallHash(output), headBefore: headBefore})
+	if len(d.window) > d.limit {
+		d.window = d.window[1:]
+	}
+	if len(d.window) < d.limit || headAfter == "" || d.window[0].headBefore != headAfter {
+		return false
+	}
+	for _, e := range d.window[1:] {
+		if e.hash != d.window[0].hash {
+			return false
+		}
+	}
+	return true
+}
+
+// stallHash returns a hash of the output ignoring case, whitespace and numbers.
+func stallHash(output string) uint64 {
+	normalized := stallNumberRe.ReplaceAllString(strings.ToLower(output), "0")
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(strings.Join(strings.Fields(normalized), " ")))
+	return h.Sum64()
+}
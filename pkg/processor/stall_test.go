@@ -0,0 +1,55 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStallDetector(t *testing.T) {
+	type iteration struct {
+		output, headBefore, headAfter string
+	}
+	tests := []struct {
+		name       string
+		limit      int
+		iterations []iteration
+		want       []bool
+	}{
+		{name: "identical output without commits stalls at the limit", limit: 3,
+			iterations: []iteration{{"working", "a", "a"}, {"working", "a", "a"}, {"working", "a", "a"}, {"working", "a", "a"}},
+			want:       []bool{false, false, true, true}},
+		{name: "identical output with commits", limit: 3,
+			iterations: []iteration{{"working", "a", "b"}, {"working", "b", "c"}, {"working", "c", "d"}, {"working", "d", "e"}},
+			want:       []bool{false, false, false, false}},
+		{name: "commit in the oldest iteration of the window", limit: 2,
+			iterations: []iteration{{"working", "a", "b"}, {"working", "b", "b"}, {"working", "b", "b"}},
+			want:       []bool{false, false, true}},
+		{name: "different output", limit: 2,
+			iterations: []iteration{{"task 1 done", "a", "a"}, {"reading plan", "a", "a"}, {"fixing tests", "a", "a"}},
+			want:       []bool{false, false, false}},
+		{name: "numbers, case and whitespace are ignored", limit: 2,
+			iterations: []iteration{{"Ran 12 tests in 3.1s\n", "a", "a"}, {"ran 14 tests  in 2.9s", "a", "a"}},
+			want:       []bool{false, true}},
+		{name: "unknown head never stalls", limit: 2,
+			iterations: []iteration{{"working", "", ""}, {"working", "", ""}, {"working", "", ""}},
+			want:       []bool{false, false, false}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := newStallDetector(tc.limit)
+			got := make([]bool, 0, len(tc.iterations))
+			for _, it := range tc.iterations {
+				got = append(got, d.record(it.output, it.headBefore, it.headAfter))
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNewStallDetector_Disabled(t *testing.T) {
+	assert.Nil(t, newStallDetector(0))
+	assert.Nil(t, newStallDetector(1))
+	assert.NotNil(t, newStallDetector(2))
+}
@@ -25,6 +25,7 @@ func newMockLogger(path string) *mocks.LoggerMock { //nolint:unparam // path is
 		PrintRawFunc:       func(_ string, _ ...any) {},
 		PrintSectionFunc:   func(_ status.Section) {},
 		PrintAlignedFunc:   func(_ string) {},
+		PrintToolFunc:      func(_ string) {},
 		LogQuestionFunc:    func(_ string, _ []string) {},
 		LogAnswerFunc:      func(_ string) {},
 		LogDraftReviewFunc: func(_, _ string) {},
@@ -0,0 +1,140 @@
+package processor
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// Usage holds token counts reported by the agents, for a single phase or the whole run.
+type Usage struct {
+	Phase        status.Phase // empty for the run total
+	InputTokens  int
+	OutputTokens int
+	Calls        int // executor calls counted
+	Reported     int // calls that reported token counts, codex and custom scripts never do
+	ToolCalls    int // tool invocations reported by the agent, only claude with stream-json output reports them
+}
+
+// Available returns true if at least one call reported token counts.
+func (u Usage) Available() bool {
+	return u.Reported > 0
+}
+
+// Cost estimates the price of the counted tokens for the given prices of a million input and output tokens.
+func (u Usage) Cost(perMInput, perMOutput float64) float64 {
+	return float64(u.InputTokens)/1_000_000*perMInput + float64(u.OutputTokens)/1_000_000*perMOutput
+}
+
+// Format renders token counts with an estimated cost if any price is set, "n/a" if nothing was reported.
+// the number of tool calls is appended if any were reported.
+func (u Usage) Format(perMInput, perMOutput float64) string {
+	if !u.Available() {
+		return notAvailable
+	}
+	res := fmt.Sprintf("%s in / %s out", formatTokens(u.InputTokens), formatTokens(u.OutputTokens))
+	if perMInput > 0 || perMOutput > 0 {
+		res += fmt.Sprintf(" (~$%.2f)", u.Cost(perMInput, perMOutput))
+	}
+	if u.ToolCalls > 0 {
+		res += fmt.Sprintf(", %d tool calls", u.ToolCalls)
+	}
+	return res
+}
+
+// notAvailable is shown instead of token counts and costs when no call reported tokens
+const notAvailable = "n/a"
+
+// Columns renders input tokens, output tokens and the estimated cost for a table, "n/a" if nothing was reported.
+// cost is empty if no price is set.
+func (u Usage) Columns(perMInput, perMOutput float64) (in, out, cost string) {
+	if !u.Available() {
+		in, out = notAvailable, notAvailable
+		if perMInput > 0 || perMOutput > 0 {
+			cost = notAvailable
+		}
+		return in, out, cost
+	}
+	if perMInput > 0 || perMOutput > 0 {
+		cost = fmt.Sprintf("$%.2f", u.Cost(perMInput, perMOutput))
+	}
+	return formatTokens(u.InputTokens), formatTokens(u.OutputTokens), cost
+}
+
+// formatTokens renders a token count in a short form: 950, 12.3k, 1.25M.
+func formatTokens(n int) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprintf("%d", n)
+	case n < 1_000_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%.2fM", float64(n)/1_000_000)
+	}
+}
+
+// usageTracker accumulates token usage per phase, in the order phases first ran.
+type usageTracker struct {
+	phases []Usage
+}
+
+// add accumulates usage of calls made in the given phase.
+func (t *usageTracker) add(phase status.Phase, calls Usage) {
+	idx := -1
+	for i := range t.phases {
+		if t.phases[i].Phase == phase {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		t.phases = append(t.phases, Usage{Phase: phase})
+		idx = len(t.phases) - 1
+	}
+	t.phases[idx].addCalls(calls)
+}
+
+// total returns the usage of all phases combined.
+func (t *usageTracker) total() Usage {
+	var res Usage
+	for _, u := range t.phases {
+		res.addCalls(u)
+	}
+	return res
+}
+
+// addCalls adds the counts of other to u, keeping the phase of u.
+func (u *Usage) addCalls(other Usage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.Calls += other.Calls
+	u.Reported += other.Reported
+	u.ToolCalls += other.ToolCalls
+}
+
+// Usage returns token usage per phase in the order phases ran, and the run total.
+// phases where no call reported tokens are included, their Available is false.
+func (r *Runner) Usage() (phases []Usage, total Usage) {
+	return slices.Clone(r.usage.phases), r.usage.total()
+}
+
+// recordUsage adds the tokens of an executor call to the current phase and logs the running total.
+func (r *Runner) recordUsage(res executor.Result) {
+	call := Usage{Calls: 1, ToolCalls: res.ToolCalls}
+	if res.HasUsage() {
+		call.InputTokens, call.OutputTokens, call.Reported = res.InputTokens, res.OutputTokens, 1
+	}
+	r.usage.add(r.phaseHolder.Get(), call)
+	perMIn, perMOut := r.tokenPrices()
+	r.log.Print("tokens: %s, run total %s", call.Format(0, 0), r.usage.total().Format(perMIn, perMOut))
+}
+
+// tokenPrices returns the configured prices of a million input and output tokens, zero if not set.
+func (r *Runner) tokenPrices() (perMInput, perMOutput float64) {
+	if r.cfg.AppConfig == nil {
+		return 0, 0
+	}
+	return r.cfg.AppConfig.TokenPrices()
+}
@@ -0,0 +1,94 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestUsage_Format(t *testing.T) {
+	tests := []struct {
+		name      string
+		usage     Usage
+		perMIn    float64
+		perMOut   float64
+		want      string
+		wantAvail bool
+	}{
+		{name: "nothing reported", usage: Usage{Calls: 3}, want: "n/a"},
+		{name: "small counts", usage: Usage{InputTokens: 950, OutputTokens: 40, Calls: 1, Reported: 1},
+			want: "950 in / 40 out", wantAvail: true},
+		{name: "large counts", usage: Usage{InputTokens: 1_250_000, OutputTokens: 12_345, Calls: 2, Reported: 2},
+			want: "1.25M in / 12.3k out", wantAvail: true},
+		{name: "with prices", usage: Usage{InputTokens: 100_000, OutputTokens: 10_000, Calls: 1, Reported: 1},
+			perMIn: 3, perMOut: 15, want: "100.0k in / 10.0k out (~$0.45)", wantAvail: true},
+		{name: "prices without tokens", usage: Usage{Calls: 1}, perMIn: 3, want: "n/a"},
+		{name: "with tool calls", usage: Usage{InputTokens: 950, OutputTokens: 40, Calls: 1, Reported: 1, ToolCalls: 7},
+			perMIn: 3, want: "950 in / 40 out (~$0.00), 7 tool calls", wantAvail: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.usage.Format(tc.perMIn, tc.perMOut))
+			assert.Equal(t, tc.wantAvail, tc.usage.Available())
+		})
+	}
+}
+
+func TestUsage_Columns(t *testing.T) {
+	tests := []struct {
+		name            string
+		usage           Usage
+		perMIn, perMOut float64
+		wantIn, wantOut string
+		wantCost        string
+	}{
+		{name: "tokens without prices", usage: Usage{InputTokens: 45_100, OutputTokens: 7_800, Calls: 3, Reported: 3},
+			wantIn: "45.1k", wantOut: "7.8k"},
+		{name: "tokens with prices", usage: Usage{InputTokens: 100_000, OutputTokens: 10_000, Calls: 1, Reported: 1},
+			perMIn: 3, perMOut: 15, wantIn: "100.0k", wantOut: "10.0k", wantCost: "$0.45"},
+		{name: "nothing reported", usage: Usage{Calls: 2}, wantIn: "n/a", wantOut: "n/a"},
+		{name: "nothing reported with prices", usage: Usage{Calls: 2}, perMIn: 3, wantIn: "n/a", wantOut: "n/a", wantCost: "n/a"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			in, out, cost := tc.usage.Columns(tc.perMIn, tc.perMOut)
+			assert.Equal(t, tc.wantIn, in)
+			assert.Equal(t, tc.wantOut, out)
+			assert.Equal(t, tc.wantCost, cost)
+		})
+	}
+}
+
+func TestUsage_Cost(t *testing.T) {
+	u := Usage{InputTokens: 2_000_000, OutputTokens: 500_000}
+	assert.InDelta(t, 13.5, u.Cost(3, 15), 1e-9)
+	assert.InDelta(t, 6.0, u.Cost(3, 0), 1e-9)
+	assert.InDelta(t, 0.0, u.Cost(0, 0), 1e-9)
+}
+
+func TestUsageTracker(t *testing.T) {
+	var tr usageTracker
+	tr.add(status.PhaseTask, Usage{InputTokens: 1000, OutputTokens: 100, Calls: 1, Reported: 1, ToolCalls: 4})
+	tr.add(status.PhaseCodex, Usage{Calls: 1})
+	tr.add(status.PhaseTask, Usage{InputTokens: 500, OutputTokens: 50, Calls: 1, Reported: 1, ToolCalls: 2})
+	tr.add(status.PhaseClaudeEval, Usage{InputTokens: 200, OutputTokens: 20, Calls: 1, Reported: 1})
+	tr.add(status.PhaseCodex, Usage{Calls: 1})
+
+	assert.Equal(t, []Usage{
+		{Phase: status.PhaseTask, InputTokens: 1500, OutputTokens: 150, Calls: 2, Reported: 2, ToolCalls: 6},
+		{Phase: status.PhaseCodex, Calls: 2},
+		{Phase: status.PhaseClaudeEval, InputTokens: 200, OutputTokens: 20, Calls: 1, Reported: 1},
+	}, tr.phases)
+	assert.Equal(t, Usage{InputTokens: 1700, OutputTokens: 170, Calls: 5, Reported: 3, ToolCalls: 6}, tr.total())
+	assert.InDelta(t, 0.00765, tr.total().Cost(3, 15), 1e-9)
+}
+
+func TestUsageTracker_Empty(t *testing.T) {
+	var tr usageTracker
+	assert.Empty(t, tr.phases)
+	assert.Equal(t, Usage{}, tr.total())
+	assert.Equal(t, "n/a", tr.total().Format(3, 15))
+}
@@ -0,0 +1,132 @@
+package progress
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// palette is the set of colors the terminal can show.
+type palette int
+
+const (
+	paletteTrueColor palette = iota // 24-bit RGB
+	palette256                      // xterm 256-color
+	palette16                       // basic ANSI colors
+	paletteNone                     // colors disabled
+)
+
+// String returns a human-readable palette name.
+func (p palette) String() string {
+	switch p {
+	case palette256:
+		return "256 colors"
+	case palette16:
+		return "16 colors"
+	case paletteNone:
+		return "no color"
+	default:
+		return "truecolor"
+	}
+}
+
+// detectPalette picks the palette from the environment.
+// NO_COLOR (any non-empty value) disables colors, COLORTERM=truecolor|24bit keeps RGB,
+// a TERM with 256color downgrades to 256 colors, any other TERM to 16 colors.
+// an unset TERM keeps RGB, terminals without it (e.g. Windows Terminal) support truecolor.
+func detectPalette(getenv func(string) string) palette {
+	if getenv("NO_COLOR") != "" {
+		return paletteNone
+	}
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return paletteTrueColor
+	}
+	term := getenv("TERM")
+	switch {
+	case term == "":
+		return paletteTrueColor
+	case strings.Contains(term, "256color"):
+		return palette256
+	default:
+		return palette16
+	}
+}
+
+// newPaletteColor creates a color for the RGB value, reduced to what the palette can show.
+func newPaletteColor(p palette, r, g, b int) *color.Color {
+	switch p {
+	case palette256:
+		return color.New(38, 5, color.Attribute(rgbTo256(r, g, b)))
+	case palette16:
+		return color.New(rgbTo16(r, g, b))
+	case paletteNone:
+		c := color.New()
+		c.DisableColor()
+		return c
+	default:
+		return color.RGB(r, g, b)
+	}
+}
+
+// cubeLevels are the channel values of the xterm 6x6x6 color cube.
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// rgbTo256 returns the nearest xterm 256-color index, from the 6x6x6 cube (16-231) or the gray ramp (232-255).
+func rgbTo256(r, g, b int) int {
+	nearestLevel := func(v int) int {
+		best := 0
+		for i, lvl := range cubeLevels {
+			if abs(v-lvl) < abs(v-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	ri, gi, bi := nearestLevel(r), nearestLevel(g), nearestLevel(b)
+	cubeIdx := 16 + 36*ri + 6*gi + bi
+	cubeDist := colorDistance(r, g, b, cubeLevels[ri], cubeLevels[gi], cubeLevels[bi])
+
+	// gray ramp runs from 8 to 238 in steps of 10
+	grayStep := min(max((r+g+b)/3-3, 0)/10, 23)
+	gray := 8 + grayStep*10
+	if colorDistance(r, g, b, gray, gray, gray) < cubeDist {
+		return 232 + grayStep
+	}
+	return cubeIdx
+}
+
+// ansi16 lists the basic ANSI foreground colors with their usual xterm RGB values.
+var ansi16 = []struct {
+	attr    color.Attribute
+	r, g, b int
+}{
+	{color.FgBlack, 0, 0, 0}, {color.FgRed, 205, 0, 0}, {color.FgGreen, 0, 205, 0}, {color.FgYellow, 205, 205, 0},
+	{color.FgBlue, 0, 0, 238}, {color.FgMagenta, 205, 0, 205}, {color.FgCyan, 0, 205, 205}, {color.FgWhite, 229, 229, 229},
+	{color.FgHiBlack, 127, 127, 127}, {color.FgHiRed, 255, 0, 0}, {color.FgHiGreen, 0, 255, 0}, {color.FgHiYellow, 255, 255, 0},
+	{color.FgHiBlue, 92, 92, 255}, {color.FgHiMagenta, 255, 0, 255}, {color.FgHiCyan, 0, 255, 255}, {color.FgHiWhite, 255, 255, 255},
+}
+
+// rgbTo16 returns the nearest basic ANSI foreground color.
+func rgbTo16(r, g, b int) color.Attribute {
+	best, bestDist := ansi16[0].attr, -1
+	for _, c := range ansi16 {
+		if d := colorDistance(r, g, b, c.r, c.g, c.b); bestDist < 0 || d < bestDist {
+			best, bestDist = c.attr, d
+		}
+	}
+	return best
+}
+
+// colorDistance returns the squared euclidean distance between two RGB colors.
+func colorDistance(r1, g1, b1, r2, g2, b2 int) int {
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,122 @@
+package progress
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+func TestDetectPalette(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want palette
+	}{
+		{name: "no terminal info", env: map[string]string{}, want: paletteTrueColor},
+		{name: "colorterm truecolor", env: map[string]string{"COLORTERM": "truecolor", "TERM": "xterm"}, want: paletteTrueColor},
+		{name: "colorterm 24bit", env: map[string]string{"COLORTERM": "24bit", "TERM": "xterm-256color"}, want: paletteTrueColor},
+		{name: "term 256color", env: map[string]string{"TERM": "xterm-256color"}, want: palette256},
+		{name: "tmux 256color", env: map[string]string{"TERM": "tmux-256color"}, want: palette256},
+		{name: "basic term", env: map[string]string{"TERM": "xterm"}, want: palette16},
+		{name: "no_color wins", env: map[string]string{"NO_COLOR": "1", "COLORTERM": "truecolor"}, want: paletteNone},
+		{name: "empty no_color ignored", env: map[string]string{"NO_COLOR": "", "TERM": "xterm"}, want: palette16},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := detectPalette(func(k string) string { return tc.env[k] })
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRgbTo256(t *testing.T) {
+	tests := []struct {
+		name    string
+		r, g, b int
+		want    int
+	}{
+		{name: "black", r: 0, g: 0, b: 0, want: 16},
+		{name: "white", r: 255, g: 255, b: 255, want: 231},
+		{name: "green", r: 0, g: 255, b: 0, want: 46},
+		{name: "red", r: 255, g: 0, b: 0, want: 196},
+		{name: "cyan", r: 0, g: 255, b: 255, want: 51},
+		{name: "default codex magenta", r: 208, g: 150, b: 217, want: 176},
+		{name: "default timestamp gray", r: 138, g: 138, b: 138, want: 245},
+		{name: "dark gray", r: 30, g: 30, b: 30, want: 234},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, rgbTo256(tc.r, tc.g, tc.b))
+		})
+	}
+}
+
+func TestRgbTo16(t *testing.T) {
+	tests := []struct {
+		name    string
+		r, g, b int
+		want    color.Attribute
+	}{
+		{name: "green", r: 0, g: 255, b: 0, want: color.FgHiGreen},
+		{name: "dark green", r: 0, g: 135, b: 0, want: color.FgGreen},
+		{name: "red", r: 255, g: 0, b: 0, want: color.FgHiRed},
+		{name: "gray", r: 138, g: 138, b: 138, want: color.FgHiBlack},
+		{name: "light gray", r: 220, g: 220, b: 220, want: color.FgWhite},
+		{name: "black", r: 0, g: 0, b: 0, want: color.FgBlack},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, rgbTo16(tc.r, tc.g, tc.b))
+		})
+	}
+}
+
+func TestNewPaletteColor(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = origNoColor }()
+
+	assert.Equal(t, "\x1b[38;2;0;255;0m", openSeq(newPaletteColor(paletteTrueColor, 0, 255, 0)))
+	assert.Equal(t, "\x1b[38;5;46m", openSeq(newPaletteColor(palette256, 0, 255, 0)))
+	assert.Equal(t, "\x1b[92m", openSeq(newPaletteColor(palette16, 0, 255, 0)))
+	assert.Empty(t, openSeq(newPaletteColor(paletteNone, 0, 255, 0)))
+}
+
+func TestNewColors_Theme(t *testing.T) {
+	origNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = origNoColor }()
+
+	t.Run("theme fills every role", func(t *testing.T) {
+		c := newColors(config.ColorConfig{Theme: "solarized"}, paletteTrueColor)
+		assert.Equal(t, "\x1b[38;2;133;153;0m", openSeq(c.task))
+		assert.Equal(t, "\x1b[38;2;220;50;47m", openSeq(c.Error()))
+		assert.Equal(t, "\x1b[38;2;131;148;150m", openSeq(c.Info()))
+	})
+
+	t.Run("explicit color overrides theme", func(t *testing.T) {
+		c := newColors(config.ColorConfig{Theme: "solarized", Error: "1,2,3"}, paletteTrueColor)
+		assert.Equal(t, "\x1b[38;2;1;2;3m", openSeq(c.Error()))
+		assert.Equal(t, "\x1b[38;2;133;153;0m", openSeq(c.task), "other roles keep theme colors")
+	})
+
+	t.Run("theme reduced to palette", func(t *testing.T) {
+		c := newColors(config.ColorConfig{Theme: "dark"}, palette256)
+		assert.Equal(t, "\x1b[38;5;46m", openSeq(c.task))
+		assert.Equal(t, "256 colors", c.Palette())
+	})
+
+	t.Run("no theme panics on missing colors", func(t *testing.T) {
+		assert.Panics(t, func() { newColors(config.ColorConfig{}, paletteTrueColor) })
+	})
+}
+
+// openSeq returns the escape sequence the color writes before its text.
+func openSeq(c *color.Color) string {
+	s, _, _ := strings.Cut(c.Sprint("x"), "x")
+	return s
+}
@@ -2,10 +2,14 @@
 package progress
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -30,22 +34,31 @@ type Colors struct {
 	timestamp  *color.Color
 	info       *color.Color
 	phases     map[status.Phase]*color.Color
+	palette    palette
 }
 
 // NewColors creates Colors from config.ColorConfig.
+// a theme is expanded first, colors set explicitly override it.
+// colors are reduced to the palette the terminal supports, see detectPalette.
 // all colors must be provided - use config with embedded defaults fallback.
 // panics if any color value is invalid (configuration error).
 func NewColors(cfg config.ColorConfig) *Colors {
-	c := &Colors{phases: make(map[status.Phase]*color.Color)}
-	c.task = parseColorOrPanic(cfg.Task, "task")
-	c.review = parseColorOrPanic(cfg.Review, "review")
-	c.codex = parseColorOrPanic(cfg.Codex, "codex")
-	c.claudeEval = parseColorOrPanic(cfg.ClaudeEval, "claude_eval")
-	c.warn = parseColorOrPanic(cfg.Warn, "warn")
-	c.err = parseColorOrPanic(cfg.Error, "error")
-	c.signal = parseColorOrPanic(cfg.Signal, "signal")
-	c.timestamp = parseColorOrPanic(cfg.Timestamp, "timestamp")
-	c.info = parseColorOrPanic(cfg.Info, "info")
+	return newColors(cfg, detectPalette(os.Getenv))
+}
+
+// newColors creates Colors from config.ColorConfig for the given palette.
+func newColors(cfg config.ColorConfig, p palette) *Colors {
+	cfg = cfg.ApplyTheme()
+	c := &Colors{phases: make(map[status.Phase]*color.Color), palette: p}
+	c.task = parseColorOrPanic(cfg.Task, "task", p)
+	c.review = parseColorOrPanic(cfg.Review, "review", p)
+	c.codex = parseColorOrPanic(cfg.Codex, "codex", p)
+	c.claudeEval = parseColorOrPanic(cfg.ClaudeEval, "claude_eval", p)
+	c.warn = parseColorOrPanic(cfg.Warn, "warn", p)
+	c.err = parseColorOrPanic(cfg.Error, "error", p)
+	c.signal = parseColorOrPanic(cfg.Signal, "signal", p)
+	c.timestamp = parseColorOrPanic(cfg.Timestamp, "timestamp", p)
+	c.info = parseColorOrPanic(cfg.Info, "info", p)
 
 	c.phases[status.PhaseTask] = c.task
 	c.phases[status.PhaseReview] = c.review
@@ -57,8 +70,8 @@ func NewColors(cfg config.ColorConfig) *Colors {
 	return c
 }
 
-// parseColorOrPanic parses RGB string and returns color, panics on invalid input.
-func parseColorOrPanic(s, name string) *color.Color {
+// parseColorOrPanic parses RGB string and returns color for the palette, panics on invalid input.
+func parseColorOrPanic(s, name string, p palette) *color.Color {
 	parseRGB := func(s string) []int {
 		if s == "" {
 			return nil
@@ -88,9 +101,12 @@ func parseColorOrPanic(s, name string) *color.Color {
 	if rgb == nil {
 		panic(fmt.Sprintf("invalid color_%s value: %q", name, s))
 	}
-	return color.RGB(rgb[0], rgb[1], rgb[2])
+	return newPaletteColor(p, rgb[0], rgb[1], rgb[2])
 }
 
+// Palette returns the name of the palette the colors were reduced to, e.g. "256 colors".
+func (c *Colors) Palette() string { return c.palette.String() }
+
 // Info returns the info color for informational messages.
 func (c *Colors) Info() *color.Color { return c.info }
 
@@ -114,6 +130,12 @@ func (c *Colors) Error() *color.Color { return c.err }
 // Signal returns the signal color.
 func (c *Colors) Signal() *color.Color { return c.signal }
 
+// output formats of the stdout log
+const (
+	FormatText = "text" // colored human-readable lines
+	FormatJSON = "json" // one JSON object per event, for log aggregators
+)
+
 // Logger writes timestamped output to both file and stdout.
 type Logger struct {
 	file      *os.File
@@ -121,6 +143,24 @@ type Logger struct {
 	startTime time.Time
 	holder    *status.PhaseHolder
 	colors    *Colors
+	jsonOut   bool   // write stdout as JSON lines instead of colored text
+	plan      string // plan file, included in JSON lines
+	branch    string // git branch, included in JSON lines
+
+	header     string // file header, repeated at the top of the file after rotation
+	size       int64  // bytes written to the current file
+	maxSize    int64  // rotate when the file reaches this size, 0 disables rotation
+	maxBackups int    // rotated backups to keep
+}
+
+// jsonLine is a single stdout event in the json format.
+type jsonLine struct {
+	Timestamp time.Time    `json:"timestamp"`
+	Phase     status.Phase `json:"phase"`
+	Level     string       `json:"level"`
+	Message   string       `json:"message"`
+	Plan      string       `json:"plan,omitempty"`
+	Branch    string       `json:"branch,omitempty"`
 }
 
 // Config holds logger configuration.
@@ -130,8 +170,16 @@ type Config struct {
 	Mode            string // execution mode: full, review, codex-only, plan
 	Branch          string // current git branch
 	NoColor         bool   // disable color output (sets color.NoColor globally)
+	Dir             string // directory for progress files, DefaultDir if empty
+	Keep            int    // number of per-run progress files to keep for the same plan and mode, 0 keeps all
+	Format          string // stdout format, FormatText (default) or FormatJSON. the progress file is always text
+	MaxSizeBytes    int64  // rotate the progress file when it exceeds this size, 0 disables rotation
+	MaxBackups      int    // rotated backups to keep, DefaultMaxBackups if 0
 }
 
+// DefaultMaxBackups is the number of rotated progress file backups kept when Config.MaxBackups is not set.
+const DefaultMaxBackups = 3
+
 // NewLogger creates a logger writing to both a progress file and stdout.
 // colors must be provided (created via NewColors from config).
 // holder is the shared PhaseHolder for reading the current execution phase.
@@ -140,8 +188,17 @@ func NewLogger(cfg Config, colors *Colors, holder *status.PhaseHolder) (*Logger,
 	if cfg.NoColor {
 		color.NoColor = true
 	}
+	if cfg.Format != "" && cfg.Format != FormatText && cfg.Format != FormatJSON {
+		return nil, fmt.Errorf("unknown log format %q, expected %s or %s", cfg.Format, FormatText, FormatJSON)
+	}
 
-	progressPath := progressFilename(cfg.PlanFile, cfg.PlanDescription, cfg.Mode)
+	dir := cfg.Dir
+	if dir == "" {
+		dir = DefaultDir
+	}
+	startTime := time.Now()
+	basePath := progressFilename(dir, cfg.PlanFile, cfg.PlanDescription, cfg.Mode)
+	progressPath := runFilename(basePath, startTime)
 
 	// ensure progress files are tracked by creating parent dir
 	if dir := filepath.Dir(progressPath); dir != "." {
@@ -166,9 +223,17 @@ func NewLogger(cfg Config, colors *Colors, holder *status.PhaseHolder) (*Logger,
 	l := &Logger{
 		file:      f,
 		stdout:    os.Stdout,
-		startTime: time.Now(),
+		startTime: startTime,
 		holder:    holder,
 		colors:    colors,
+		jsonOut:   cfg.Format == FormatJSON,
+		plan:      cfg.PlanFile,
+		branch:    cfg.Branch,
+		maxSize:   cfg.MaxSizeBytes,
+	}
+	l.maxBackups = cfg.MaxBackups
+	if l.maxBackups <= 0 {
+		l.maxBackups = DefaultMaxBackups
 	}
 
 	// write header
@@ -176,16 +241,35 @@ func NewLogger(cfg Config, colors *Colors, holder *status.PhaseHolder) (*Logger,
 	if planStr == "" {
 		planStr = "(no plan - review only)"
 	}
-	l.writeFile("# Ralphex Progress Log\n")
-	l.writeFile("Plan: %s\n", planStr)
-	l.writeFile("Branch: %s\n", cfg.Branch)
-	l.writeFile("Mode: %s\n", cfg.Mode)
-	l.writeFile("Started: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-	l.writeFile("%s\n\n", strings.Repeat("-", 60))
+	var header strings.Builder
+	header.WriteString("# Ralphex Progress Log\n")
+	fmt.Fprintf(&header, "Plan: %s\n", planStr)
+	fmt.Fprintf(&header, "Branch: %s\n", cfg.Branch)
+	fmt.Fprintf(&header, "Mode: %s\n", cfg.Mode)
+	fmt.Fprintf(&header, "Started: %s\n", startTime.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&header, "%s\n\n", strings.Repeat("-", 60))
+	l.header = header.String()
+	l.writeFile("%s", l.header)
+
+	// pruning is best-effort, old logs left behind don't affect the run
+	if err := pruneProgressFiles(basePath, cfg.Keep); err != nil {
+		l.Warn("failed to prune old progress files: %v", err)
+	}
 
 	return l, nil
 }
 
+// NewConsoleLogger creates a logger writing to stdout only, without a progress file.
+// used where no progress file should be created (e.g. --dry-run); Path returns empty string.
+func NewConsoleLogger(colors *Colors, holder *status.PhaseHolder) *Logger {
+	return &Logger{
+		stdout:    os.Stdout,
+		startTime: time.Now(),
+		holder:    holder,
+		colors:    colors,
+	}
+}
+
 // Path returns the progress file path.
 func (l *Logger) Path() string {
 	if l.file == nil {
@@ -197,6 +281,9 @@ func (l *Logger) Path() string {
 // timestampFormat is the format for timestamps: YY-MM-DD HH:MM:SS
 const timestampFormat = "06-01-02 15:04:05"
 
+// ToolPrefix starts the lines of tool call one-liners written by PrintTool.
+const ToolPrefix = "→ "
+
 // Print writes a timestamped message to both file and stdout.
 func (l *Logger) Print(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
@@ -205,6 +292,11 @@ func (l *Logger) Print(format string, args ...any) {
 	// write to file without color
 	l.writeFile("[%s] %s\n", timestamp, msg)
 
+	if l.jsonOut {
+		l.writeJSON("info", msg)
+		return
+	}
+
 	// write to stdout with color
 	phaseColor := l.colors.ForPhase(l.holder.Get())
 	tsStr := l.colors.Timestamp().Sprintf("[%s]", timestamp)
@@ -216,6 +308,10 @@ func (l *Logger) Print(format string, args ...any) {
 func (l *Logger) PrintRaw(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
 	l.writeFile("%s", msg)
+	if l.jsonOut {
+		l.writeJSON("output", msg)
+		return
+	}
 	l.writeStdout("%s", msg)
 }
 
@@ -224,6 +320,10 @@ func (l *Logger) PrintRaw(format string, args ...any) {
 func (l *Logger) PrintSection(section status.Section) {
 	header := fmt.Sprintf("\n--- %s ---\n", section.Label)
 	l.writeFile("%s", header)
+	if l.jsonOut {
+		l.writeJSON("section", section.Label)
+		return
+	}
 	l.writeStdout("%s", l.colors.Warn().Sprint(header))
 }
 
@@ -333,6 +433,15 @@ func (l *Logger) PrintAligned(text string) {
 		tsPrefix := l.colors.Timestamp().Sprintf("[%s]", timestamp)
 		l.writeFile("[%s] %s\n", timestamp, displayLine)
 
+		if l.jsonOut {
+			if sig := extractSignal(line); sig != "" {
+				l.writeJSON("signal", sig)
+			} else {
+				l.writeJSON("output", displayLine)
+			}
+			continue
+		}
+
 		// use red for signal lines
 		lineColor := phaseColor
 
@@ -346,6 +455,19 @@ func (l *Logger) PrintAligned(text string) {
 	}
 }
 
+// PrintTool writes a one-line summary of an agent's tool call, dimmed in the timestamp color.
+// format: "[timestamp] → <tool>: <argument>"
+func (l *Logger) PrintTool(text string) {
+	timestamp := time.Now().Format(timestampFormat)
+	l.writeFile("[%s] %s%s\n", timestamp, ToolPrefix, text)
+	if l.jsonOut {
+		l.writeJSON("tool", text)
+		return
+	}
+	tsStr := l.colors.Timestamp().Sprintf("[%s]", timestamp)
+	l.writeStdout("%s %s\n", tsStr, l.colors.Timestamp().Sprint(ToolPrefix+text))
+}
+
 // extractSignal extracts signal name from <<<RALPHEX:SIGNAL_NAME>>> format.
 // returns empty string if no signal found.
 func extractSignal(line string) string {
@@ -402,6 +524,10 @@ func (l *Logger) Error(format string, args ...any) {
 	timestamp := time.Now().Format(timestampFormat)
 
 	l.writeFile("[%s] ERROR: %s\n", timestamp, msg)
+	if l.jsonOut {
+		l.writeJSON("error", msg)
+		return
+	}
 
 	tsStr := l.colors.Timestamp().Sprintf("[%s]", timestamp)
 	errStr := l.colors.Error().Sprintf("ERROR: %s", msg)
@@ -414,6 +540,10 @@ func (l *Logger) Warn(format string, args ...any) {
 	timestamp := time.Now().Format(timestampFormat)
 
 	l.writeFile("[%s] WARN: %s\n", timestamp, msg)
+	if l.jsonOut {
+		l.writeJSON("warn", msg)
+		return
+	}
 
 	tsStr := l.colors.Timestamp().Sprintf("[%s]", timestamp)
 	warnStr := l.colors.Warn().Sprintf("WARN: %s", msg)
@@ -422,29 +552,61 @@ func (l *Logger) Warn(format string, args ...any) {
 
 // LogQuestion logs a question and its options for plan creation mode.
 // format: QUESTION: <question>\n OPTIONS: <opt1>, <opt2>, ...
+// the OPTIONS line is left out for free-form questions, which have no options.
 func (l *Logger) LogQuestion(question string, options []string) {
 	timestamp := time.Now().Format(timestampFormat)
 
 	l.writeFile("[%s] QUESTION: %s\n", timestamp, question)
-	l.writeFile("[%s] OPTIONS: %s\n", timestamp, strings.Join(options, ", "))
+	if len(options) > 0 {
+		l.writeFile("[%s] OPTIONS: %s\n", timestamp, strings.Join(options, ", "))
+	}
+	if l.jsonOut {
+		l.writeJSON("question", question)
+		if len(options) > 0 {
+			l.writeJSON("options", strings.Join(options, ", "))
+		}
+		return
+	}
 
 	tsStr := l.colors.Timestamp().Sprintf("[%s]", timestamp)
-	questionStr := l.colors.Info().Sprintf("QUESTION: %s", question)
-	optionsStr := l.colors.Info().Sprintf("OPTIONS: %s", strings.Join(options, ", "))
-	l.writeStdout("%s %s\n", tsStr, questionStr)
-	l.writeStdout("%s %s\n", tsStr, optionsStr)
+	l.writeStdout("%s %s\n", tsStr, l.colors.Info().Sprintf("QUESTION: %s", question))
+	if len(options) > 0 {
+		l.writeStdout("%s %s\n", tsStr, l.colors.Info().Sprintf("OPTIONS: %s", strings.Join(options, ", ")))
+	}
 }
 
 // LogAnswer logs the user's answer for plan creation mode.
-// format: ANSWER: <answer>
+// format: ANSWER: <answer>, each further line of a multiline answer is logged indented under it.
 func (l *Logger) LogAnswer(answer string) {
 	timestamp := time.Now().Format(timestampFormat)
+	lines := AnswerLines(answer)
 
-	l.writeFile("[%s] ANSWER: %s\n", timestamp, answer)
+	for _, line := range lines {
+		l.writeFile("[%s] %s\n", timestamp, line)
+	}
+	if l.jsonOut {
+		l.writeJSON("answer", answer)
+		return
+	}
 
 	tsStr := l.colors.Timestamp().Sprintf("[%s]", timestamp)
-	answerStr := l.colors.Info().Sprintf("ANSWER: %s", answer)
-	l.writeStdout("%s %s\n", tsStr, answerStr)
+	for _, line := range lines {
+		l.writeStdout("%s %s\n", tsStr, l.colors.Info().Sprint(line))
+	}
+}
+
+// AnswerLines formats an answer as log lines: "ANSWER: <first line>", further lines indented to match.
+func AnswerLines(answer string) []string {
+	lines := strings.Split(answer, "\n")
+	res := make([]string, 0, len(lines))
+	for i, line := range lines {
+		if i == 0 {
+			res = append(res, "ANSWER: "+line)
+			continue
+		}
+		res = append(res, "        "+line)
+	}
+	return res
 }
 
 // LogDraftReview logs the user's draft review action and optional feedback.
@@ -454,6 +616,14 @@ func (l *Logger) LogDraftReview(action, feedback string) {
 	timestamp := time.Now().Format(timestampFormat)
 
 	l.writeFile("[%s] DRAFT REVIEW: %s\n", timestamp, action)
+	if l.jsonOut {
+		l.writeJSON("draft_review", action)
+		if feedback != "" {
+			l.writeFile("[%s] FEEDBACK: %s\n", timestamp, feedback)
+			l.writeJSON("feedback", feedback)
+		}
+		return
+	}
 
 	tsStr := l.colors.Timestamp().Sprintf("[%s]", timestamp)
 	actionStr := l.colors.Info().Sprintf("DRAFT REVIEW: %s", action)
@@ -493,6 +663,7 @@ func (l *Logger) Close() error {
 		return nil
 	}
 
+	l.maxSize = 0 // the footer stays with the rest of the log
 	l.writeFile("\n%s\n", strings.Repeat("-", 60))
 	l.writeFile("Completed: %s (%s)\n", time.Now().Format("2006-01-02 15:04:05"), l.Elapsed())
 
@@ -507,48 +678,203 @@ func (l *Logger) Close() error {
 }
 
 func (l *Logger) writeFile(format string, args ...any) {
-	if l.file != nil {
-		fmt.Fprintf(l.file, format, args...)
+	if l.file == nil {
+		return
+	}
+	n, _ := fmt.Fprintf(l.file, format, args...)
+	l.size += int64(n)
+	if l.maxSize > 0 && l.size >= l.maxSize {
+		l.rotate()
 	}
 }
 
+// rotate moves the progress file to the first backup, shifting older backups and dropping the oldest,
+// and continues in a new file at the same path, starting with the same header.
+// the path stays the same, so Path, the checkpoint and web tailers keep working.
+// the new file is locked before the old one is released, the session never looks inactive.
+// rotation is best-effort: on failure it is disabled and writing continues to the current file.
+func (l *Logger) rotate() {
+	path := l.file.Name()
+	fail := func(err error) {
+		log.Printf("[WARN] failed to rotate progress file %s, rotation disabled: %v", path, err)
+		l.maxSize = 0
+	}
+
+	if err := os.Remove(BackupPath(path, l.maxBackups)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fail(err)
+		return
+	}
+	for n := l.maxBackups - 1; n >= 1; n-- {
+		if err := os.Rename(BackupPath(path, n), BackupPath(path, n+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fail(err)
+			return
+		}
+	}
+	if err := os.Rename(path, BackupPath(path, 1)); err != nil {
+		fail(err)
+		return
+	}
+
+	f, err := os.Create(path) //nolint:gosec // same path as the rotated progress file
+	if err == nil {
+		if err = lockFile(f); err != nil {
+			f.Close()
+		}
+	}
+	if err != nil {
+		_ = os.Rename(BackupPath(path, 1), path) // keep writing to the current file under its name
+		fail(err)
+		return
+	}
+
+	_ = unlockFile(l.file)
+	_ = l.file.Close()
+	l.file = f
+	l.size = 0
+	n, _ := f.WriteString(l.header)
+	l.size = int64(n)
+}
+
 func (l *Logger) writeStdout(format string, args ...any) {
 	fmt.Fprintf(l.stdout, format, args...)
 }
 
-// progressDir is the directory for progress files within the project.
-const progressDir = ".ralphex/progress"
+// writeJSON writes a single stdout event as a JSON line.
+func (l *Logger) writeJSON(level, message string) {
+	data, err := json.Marshal(jsonLine{Timestamp: time.Now(), Phase: l.holder.Get(), Level: level,
+		Message: message, Plan: l.plan, Branch: l.branch})
+	if err != nil {
+		return // a struct of strings always marshals
+	}
+	l.writeStdout("%s\n", data)
+}
+
+// DefaultDir is the default directory for progress files within the project.
+const DefaultDir = ".ralphex/progress"
 
-// progressFilename returns progress file path based on plan and mode.
-func progressFilename(planFile, planDescription, mode string) string {
+// runTimeFormat is the layout of the start time appended to progress file names, one file per run.
+// sortable, so the lexical order of file names is the order of runs.
+const runTimeFormat = "2006-01-02T15-04-05"
+
+// progressFilename returns the base progress file path for the plan and mode, without the run time.
+func progressFilename(dir, planFile, planDescription, mode string) string {
 	// plan mode uses sanitized plan description
 	if mode == "plan" && planDescription != "" {
 		sanitized := sanitizePlanName(planDescription)
-		return filepath.Join(progressDir, fmt.Sprintf("progress-plan-%s.txt", sanitized))
+		return filepath.Join(dir, fmt.Sprintf("progress-plan-%s.txt", sanitized))
 	}
 
 	if planFile != "" {
 		stem := strings.TrimSuffix(filepath.Base(planFile), ".md")
 		switch mode {
 		case "codex-only":
-			return filepath.Join(progressDir, fmt.Sprintf("progress-%s-codex.txt", stem))
+			return filepath.Join(dir, fmt.Sprintf("progress-%s-codex.txt", stem))
 		case "review":
-			return filepath.Join(progressDir, fmt.Sprintf("progress-%s-review.txt", stem))
+			return filepath.Join(dir, fmt.Sprintf("progress-%s-review.txt", stem))
 		default:
-			return filepath.Join(progressDir, fmt.Sprintf("progress-%s.txt", stem))
+			return filepath.Join(dir, fmt.Sprintf("progress-%s.txt", stem))
 		}
 	}
 
 	switch mode {
 	case "codex-only":
-		return filepath.Join(progressDir, "progress-codex.txt")
+		return filepath.Join(dir, "progress-codex.txt")
 	case "review":
-		return filepath.Join(progressDir, "progress-review.txt")
+		return filepath.Join(dir, "progress-review.txt")
 	case "plan":
-		return filepath.Join(progressDir, "progress-plan.txt")
+		return filepath.Join(dir, "progress-plan.txt")
 	default:
-		return filepath.Join(progressDir, "progress.txt")
+		return filepath.Join(dir, "progress.txt")
+	}
+}
+
+// runFilename returns the progress file path of a single run, the base path with the run start time,
+// e.g. .ralphex/progress/progress-feature-2024-06-01T15-04-05.txt.
+func runFilename(basePath string, start time.Time) string {
+	return strings.TrimSuffix(basePath, ".txt") + "-" + start.Format(runTimeFormat) + ".txt"
+}
+
+// backupSuffixRe matches the suffix of rotated progress file backups, e.g. ".1.txt"
+var backupSuffixRe = regexp.MustCompile(`\.\d+\.txt$`)
+
+// BackupPath returns the path of the nth rotated backup of a progress file,
+// e.g. progress-feature-2024-06-01T15-04-05.1.txt for progress-feature-2024-06-01T15-04-05.txt.
+func BackupPath(progressPath string, n int) string {
+	return strings.TrimSuffix(progressPath, ".txt") + "." + strconv.Itoa(n) + ".txt"
+}
+
+// IsBackupPath reports whether the path is a rotated backup of a progress file rather than an active log.
+func IsBackupPath(path string) bool {
+	return backupSuffixRe.MatchString(filepath.Base(path))
+}
+
+// EventsPath returns the path of the structured event log kept next to a progress file,
+// e.g. progress-feature-2024-06-01T15-04-05.jsonl for progress-feature-2024-06-01T15-04-05.txt.
+func EventsPath(progressPath string) string {
+	return strings.TrimSuffix(progressPath, ".txt") + ".jsonl"
+}
+
+// pruneProgressFiles removes the oldest run files of the given base path, keeping the newest keep files.
+// files of other plans or modes are not touched, and neither are files locked by an active session.
+// the event log and rotated backups of a removed run are removed with it.
+func pruneProgressFiles(basePath string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	dir := filepath.Dir(basePath)
+	prefix := strings.TrimSuffix(filepath.Base(basePath), ".txt") + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read progress dir: %w", err)
+	}
+
+	var runs []string // names are sorted by os.ReadDir, which is the order of runs
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".txt") || IsBackupPath(name) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".txt")
+		if _, err := time.Parse(runTimeFormat, stamp); err != nil {
+			continue // another plan sharing the prefix, e.g. feature-review for feature
+		}
+		runs = append(runs, filepath.Join(dir, name))
+	}
+
+	var errs []error
+	for _, path := range runs[:max(len(runs)-keep, 0)] {
+		if isLocked(path) {
+			continue
+		}
+		files := []string{path, EventsPath(path)}
+		backups, _ := filepath.Glob(strings.TrimSuffix(path, ".txt") + ".*.txt") // only fails on a bad pattern
+		for _, b := range backups {
+			if IsBackupPath(b) {
+				files = append(files, b)
+			}
+		}
+		for _, p := range files {
+			if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isLocked reports whether the progress file is held by an active session.
+func isLocked(path string) bool {
+	if IsPathLockedByCurrentProcess(path) {
+		return true
+	}
+	f, err := os.Open(path) //nolint:gosec // path is a progress file found in the progress dir
+	if err != nil {
+		return false
 	}
+	defer f.Close()
+	acquired, err := TryLockFile(f)
+	return err == nil && !acquired
 }
 
 // sanitizePlanName converts plan description to a safe filename component.
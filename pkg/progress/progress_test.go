@@ -2,8 +2,12 @@ package progress
 
 import (
 	"bytes"
+	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -17,8 +21,15 @@ import (
 )
 
 // testColors returns a Colors instance for testing with valid RGB values.
+// assertRunFilename checks that path is a per-run progress file of the given base name.
+func assertRunFilename(t *testing.T, wantBase, path string) {
+	t.Helper()
+	stem := regexp.QuoteMeta(strings.TrimSuffix(wantBase, ".txt"))
+	assert.Regexp(t, `^`+stem+`-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.txt$`, filepath.Base(path))
+}
+
 func testColors() *Colors {
-	return NewColors(config.ColorConfig{
+	return newColors(config.ColorConfig{
 		Task:       "0,255,0",
 		Review:     "0,255,255",
 		Codex:      "255,0,255",
@@ -28,7 +39,7 @@ func testColors() *Colors {
 		Signal:     "255,100,100",
 		Timestamp:  "138,138,138",
 		Info:       "180,180,180",
-	})
+	}, paletteTrueColor)
 }
 
 func TestNewLogger(t *testing.T) {
@@ -47,6 +58,7 @@ func TestNewLogger(t *testing.T) {
 		{name: "full mode no plan", cfg: Config{Mode: "full", Branch: "main"}, wantBase: "progress.txt", wantDir: ".ralphex/progress"},
 		{name: "review mode no plan", cfg: Config{Mode: "review", Branch: "main"}, wantBase: "progress-review.txt", wantDir: ".ralphex/progress"},
 		{name: "codex-only mode no plan", cfg: Config{Mode: "codex-only", Branch: "main"}, wantBase: "progress-codex.txt", wantDir: ".ralphex/progress"},
+		{name: "custom dir", cfg: Config{PlanFile: "docs/plans/feature.md", Mode: "full", Dir: "logs/ralphex"}, wantBase: "progress-feature.txt", wantDir: "logs/ralphex"},
 	}
 
 	for _, tc := range tests {
@@ -61,8 +73,8 @@ func TestNewLogger(t *testing.T) {
 			require.NoError(t, err)
 			defer l.Close()
 
-			assert.Equal(t, tc.wantBase, filepath.Base(l.Path()))
-			assert.Contains(t, l.Path(), tc.wantDir)
+			assertRunFilename(t, tc.wantBase, l.Path())
+			assert.Equal(t, tc.wantDir, filepath.Dir(l.Path()))
 
 			// verify header written
 			content, err := os.ReadFile(l.Path())
@@ -73,6 +85,27 @@ func TestNewLogger(t *testing.T) {
 	}
 }
 
+func TestNewConsoleLogger(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(origDir) }()
+
+	l := NewConsoleLogger(testColors(), &status.PhaseHolder{})
+	var buf bytes.Buffer
+	l.stdout = &buf
+
+	l.Print("console message %d", 7)
+	l.LogDiffStats(1, 2, 3)
+	assert.Contains(t, buf.String(), "console message 7")
+	assert.Empty(t, l.Path())
+	require.NoError(t, l.Close())
+
+	// no progress directory should be created
+	_, err := os.Stat(filepath.Join(tmpDir, ".ralphex"))
+	assert.True(t, os.IsNotExist(err))
+}
+
 func TestLogger_Print(t *testing.T) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
@@ -99,6 +132,80 @@ func TestLogger_Print(t *testing.T) {
 	assert.Contains(t, buf.String(), "test message 42")
 }
 
+func TestLogger_JSONFormat(t *testing.T) {
+	dir := t.TempDir()
+	holder := &status.PhaseHolder{}
+	l, err := NewLogger(Config{PlanFile: "docs/plans/feature.md", Mode: "full", Branch: "feature", Dir: dir, Format: FormatJSON},
+		testColors(), holder)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+	var buf bytes.Buffer
+	l.stdout = &buf
+
+	holder.Set(status.PhaseTask)
+	l.PrintSection(status.NewTaskIterationSection(1))
+	l.Print("starting %s", "task")
+	l.PrintAligned("working on it\n<<<RALPHEX:ALL_TASKS_DONE>>>\n")
+	l.PrintTool("Bash: go test ./...")
+	holder.Set(status.PhaseReview)
+	l.Warn("slow %s", "review")
+	l.Error("failed")
+	l.PrintRaw("raw chunk")
+	l.LogQuestion("which db?", []string{"postgres", "sqlite"})
+	l.LogAnswer("sqlite")
+	l.LogDraftReview("revise", "more tests")
+
+	type entry struct {
+		Timestamp time.Time    `json:"timestamp"`
+		Phase     status.Phase `json:"phase"`
+		Level     string       `json:"level"`
+		Message   string       `json:"message"`
+		Plan      string       `json:"plan"`
+		Branch    string       `json:"branch"`
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	got := make([]entry, 0, len(lines))
+	for _, line := range lines {
+		var e entry
+		require.NoError(t, json.Unmarshal([]byte(line), &e), "invalid json line: %s", line)
+		assert.False(t, e.Timestamp.IsZero())
+		assert.Equal(t, "docs/plans/feature.md", e.Plan)
+		assert.Equal(t, "feature", e.Branch)
+		e.Timestamp, e.Plan, e.Branch = time.Time{}, "", ""
+		got = append(got, e)
+	}
+	assert.Equal(t, []entry{
+		{Phase: status.PhaseTask, Level: "section", Message: "task iteration 1"},
+		{Phase: status.PhaseTask, Level: "info", Message: "starting task"},
+		{Phase: status.PhaseTask, Level: "output", Message: "working on it"},
+		{Phase: status.PhaseTask, Level: "signal", Message: "ALL_TASKS_DONE"},
+		{Phase: status.PhaseTask, Level: "tool", Message: "Bash: go test ./..."},
+		{Phase: status.PhaseReview, Level: "warn", Message: "slow review"},
+		{Phase: status.PhaseReview, Level: "error", Message: "failed"},
+		{Phase: status.PhaseReview, Level: "output", Message: "raw chunk"},
+		{Phase: status.PhaseReview, Level: "question", Message: "which db?"},
+		{Phase: status.PhaseReview, Level: "options", Message: "postgres, sqlite"},
+		{Phase: status.PhaseReview, Level: "answer", Message: "sqlite"},
+		{Phase: status.PhaseReview, Level: "draft_review", Message: "revise"},
+		{Phase: status.PhaseReview, Level: "feedback", Message: "more tests"},
+	}, got)
+
+	// the progress file stays in the text format, the dashboard and resume read it
+	content, err := os.ReadFile(l.Path())
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "--- task iteration 1 ---")
+	assert.Contains(t, string(content), "] starting task\n")
+	assert.Contains(t, string(content), "] → Bash: go test ./...\n")
+	assert.Contains(t, string(content), "] FEEDBACK: more tests\n")
+	assert.NotContains(t, string(content), `"level"`)
+}
+
+func TestNewLogger_UnknownFormat(t *testing.T) {
+	_, err := NewLogger(Config{Mode: "full", Dir: t.TempDir(), Format: "xml"}, testColors(), &status.PhaseHolder{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown log format "xml"`)
+}
+
 func TestLogger_PrintRaw(t *testing.T) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
@@ -393,27 +500,224 @@ func TestGetProgressFilename(t *testing.T) {
 		mode            string
 		want            string
 	}{
-		{"full mode with plan", "docs/plans/feature.md", "", "full", filepath.Join(progressDir, "progress-feature.txt")},
-		{"review mode with plan", "docs/plans/feature.md", "", "review", filepath.Join(progressDir, "progress-feature-review.txt")},
-		{"codex-only mode with plan", "docs/plans/feature.md", "", "codex-only", filepath.Join(progressDir, "progress-feature-codex.txt")},
-		{"full mode no plan", "", "", "full", filepath.Join(progressDir, "progress.txt")},
-		{"review mode no plan", "", "", "review", filepath.Join(progressDir, "progress-review.txt")},
-		{"codex-only mode no plan", "", "", "codex-only", filepath.Join(progressDir, "progress-codex.txt")},
-		{"full with date prefix", "plans/2024-01-15-refactor.md", "", "full", filepath.Join(progressDir, "progress-2024-01-15-refactor.txt")},
-		{"plan mode with description", "", "implement caching", "plan", filepath.Join(progressDir, "progress-plan-implement-caching.txt")},
-		{"plan mode with complex description", "", "Add User Authentication!", "plan", filepath.Join(progressDir, "progress-plan-add-user-authentication.txt")},
-		{"plan mode no description", "", "", "plan", filepath.Join(progressDir, "progress-plan.txt")},
-		{"plan mode with special chars", "", "fix: bug #123", "plan", filepath.Join(progressDir, "progress-plan-fix-bug-123.txt")},
+		{"full mode with plan", "docs/plans/feature.md", "", "full", filepath.Join(DefaultDir, "progress-feature.txt")},
+		{"review mode with plan", "docs/plans/feature.md", "", "review", filepath.Join(DefaultDir, "progress-feature-review.txt")},
+		{"codex-only mode with plan", "docs/plans/feature.md", "", "codex-only", filepath.Join(DefaultDir, "progress-feature-codex.txt")},
+		{"full mode no plan", "", "", "full", filepath.Join(DefaultDir, "progress.txt")},
+		{"review mode no plan", "", "", "review", filepath.Join(DefaultDir, "progress-review.txt")},
+		{"codex-only mode no plan", "", "", "codex-only", filepath.Join(DefaultDir, "progress-codex.txt")},
+		{"full with date prefix", "plans/2024-01-15-refactor.md", "", "full", filepath.Join(DefaultDir, "progress-2024-01-15-refactor.txt")},
+		{"plan mode with description", "", "implement caching", "plan", filepath.Join(DefaultDir, "progress-plan-implement-caching.txt")},
+		{"plan mode with complex description", "", "Add User Authentication!", "plan", filepath.Join(DefaultDir, "progress-plan-add-user-authentication.txt")},
+		{"plan mode no description", "", "", "plan", filepath.Join(DefaultDir, "progress-plan.txt")},
+		{"plan mode with special chars", "", "fix: bug #123", "plan", filepath.Join(DefaultDir, "progress-plan-fix-bug-123.txt")},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := progressFilename(tc.planFile, tc.planDescription, tc.mode)
+			got := progressFilename(DefaultDir, tc.planFile, tc.planDescription, tc.mode)
 			assert.Equal(t, tc.want, got)
 		})
 	}
 }
 
+func TestRunFilename(t *testing.T) {
+	start := time.Date(2024, 6, 1, 15, 4, 5, 0, time.UTC)
+	assert.Equal(t, filepath.Join(DefaultDir, "progress-feature-2024-06-01T15-04-05.txt"),
+		runFilename(filepath.Join(DefaultDir, "progress-feature.txt"), start))
+	assert.Equal(t, "progress-2024-06-01T15-04-05.txt", runFilename("progress.txt", start))
+}
+
+func TestEventsPath(t *testing.T) {
+	assert.Equal(t, filepath.Join(".ralphex", "progress", "progress-feature-2024-06-01T10-00-00.jsonl"),
+		EventsPath(filepath.Join(".ralphex", "progress", "progress-feature-2024-06-01T10-00-00.txt")))
+}
+
+func TestPruneProgressFiles(t *testing.T) {
+	files := []string{
+		"progress-feature-2024-06-01T10-00-00.txt",
+		"progress-feature-2024-06-02T10-00-00.txt",
+		"progress-feature-2024-06-03T10-00-00.txt",
+		"progress-feature-2024-06-04T10-00-00.txt",
+		"progress-feature.txt",                                 // single file from older versions
+		"progress-feature-review-2024-06-01T10-00-00.txt",      // same plan, other mode
+		"progress-feature-x-2024-06-01T10-00-00.txt",           // other plan sharing the prefix
+		"progress-feature.checkpoint.json",                     // resume checkpoint
+		"progress-feature-2024-06-01T10-00-00.checkpoint.json", // not a progress log
+	}
+
+	tests := []struct {
+		name string
+		keep int
+		want []string // remaining per-run files of the plan
+	}{
+		{name: "keep all", keep: 0, want: files[:4]},
+		{name: "keep two", keep: 2, want: files[2:4]},
+		{name: "keep more than present", keep: 10, want: files[:4]},
+		{name: "keep one", keep: 1, want: files[3:4]},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, f), []byte("log"), 0o600))
+			}
+
+			require.NoError(t, pruneProgressFiles(filepath.Join(dir, "progress-feature.txt"), tc.keep))
+
+			for _, f := range files[:4] {
+				_, err := os.Stat(filepath.Join(dir, f))
+				if slices.Contains(tc.want, f) {
+					assert.NoError(t, err, "%s should be kept", f)
+				} else {
+					assert.ErrorIs(t, err, os.ErrNotExist, "%s should be removed", f)
+				}
+			}
+			for _, f := range files[4:] {
+				assert.FileExists(t, filepath.Join(dir, f))
+			}
+		})
+	}
+
+	t.Run("active session is kept", func(t *testing.T) {
+		dir := t.TempDir()
+		old := filepath.Join(dir, "progress-feature-2024-06-01T10-00-00.txt")
+		require.NoError(t, os.WriteFile(old, []byte("log"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "progress-feature-2024-06-02T10-00-00.txt"), []byte("log"), 0o600))
+
+		f, err := os.Open(old) //nolint:gosec // test file
+		require.NoError(t, err)
+		defer f.Close()
+		require.NoError(t, lockFile(f))
+		defer func() { _ = unlockFile(f) }()
+
+		require.NoError(t, pruneProgressFiles(filepath.Join(dir, "progress-feature.txt"), 1))
+		assert.FileExists(t, old)
+	})
+
+	t.Run("event log is removed with its run", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, f := range []string{"progress-feature-2024-06-01T10-00-00", "progress-feature-2024-06-02T10-00-00"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, f+".txt"), []byte("log"), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, f+".jsonl"), []byte("{}"), 0o600))
+		}
+
+		require.NoError(t, pruneProgressFiles(filepath.Join(dir, "progress-feature.txt"), 1))
+		assert.NoFileExists(t, filepath.Join(dir, "progress-feature-2024-06-01T10-00-00.jsonl"))
+		assert.FileExists(t, filepath.Join(dir, "progress-feature-2024-06-02T10-00-00.jsonl"))
+	})
+
+	t.Run("rotated backups are removed with their run", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, f := range []string{"progress-feature-2024-06-01T10-00-00", "progress-feature-2024-06-02T10-00-00"} {
+			for _, name := range []string{f + ".txt", f + ".1.txt", f + ".2.txt"} {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("log"), 0o600))
+			}
+		}
+
+		require.NoError(t, pruneProgressFiles(filepath.Join(dir, "progress-feature.txt"), 1))
+		assert.NoFileExists(t, filepath.Join(dir, "progress-feature-2024-06-01T10-00-00.1.txt"))
+		assert.NoFileExists(t, filepath.Join(dir, "progress-feature-2024-06-01T10-00-00.2.txt"))
+		assert.FileExists(t, filepath.Join(dir, "progress-feature-2024-06-02T10-00-00.1.txt"))
+		assert.FileExists(t, filepath.Join(dir, "progress-feature-2024-06-02T10-00-00.2.txt"))
+	})
+
+	t.Run("new logger prunes old runs", func(t *testing.T) {
+		dir := t.TempDir()
+		old := filepath.Join(dir, "progress-feature-2024-06-01T10-00-00.txt")
+		require.NoError(t, os.WriteFile(old, []byte("log"), 0o600))
+
+		l, err := NewLogger(Config{PlanFile: "docs/plans/feature.md", Mode: "full", Dir: dir, Keep: 1}, testColors(),
+			&status.PhaseHolder{})
+		require.NoError(t, err)
+		defer l.Close()
+
+		assert.NoFileExists(t, old)
+		assert.FileExists(t, l.Path())
+	})
+}
+
+func TestLogger_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewLogger(Config{PlanFile: "docs/plans/feature.md", Mode: "full", Branch: "feature", Dir: dir,
+		MaxSizeBytes: 1024, MaxBackups: 2}, testColors(), &status.PhaseHolder{})
+	require.NoError(t, err)
+	l.stdout = io.Discard
+	path := l.Path()
+
+	// each line is about 100 bytes, 35 lines fill the file three times
+	for i := range 35 {
+		l.Print("line %02d %s", i, strings.Repeat("x", 70))
+	}
+
+	assert.Equal(t, path, l.Path(), "the active path doesn't change")
+	assert.True(t, IsPathLockedByCurrentProcess(path))
+	assert.FileExists(t, BackupPath(path, 1))
+	assert.FileExists(t, BackupPath(path, 2))
+	assert.NoFileExists(t, BackupPath(path, 3), "only two backups are kept")
+
+	// the active file is reopened with the header and the latest lines
+	active, err := os.ReadFile(path) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Less(t, len(active), 1024)
+	assert.True(t, strings.HasPrefix(string(active), "# Ralphex Progress Log\nPlan: docs/plans/feature.md\nBranch: feature\n"))
+	assert.Contains(t, string(active), "line 34")
+
+	// backups hold older lines, the newest backup the ones right before the active file
+	backup, err := os.ReadFile(BackupPath(path, 1)) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, len(backup), 1024)
+	assert.True(t, strings.HasPrefix(string(backup), "# Ralphex Progress Log\n"))
+	assert.NotContains(t, string(backup), "line 34")
+
+	// the lock is held on the new file and released on close
+	f, err := os.Open(path) //nolint:gosec // test file
+	require.NoError(t, err)
+	defer f.Close()
+	acquired, err := TryLockFile(f)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	require.NoError(t, l.Close())
+	assert.False(t, IsPathLockedByCurrentProcess(path))
+	active, err = os.ReadFile(path) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Contains(t, string(active), "Completed:")
+}
+
+func TestLogger_NoRotationByDefault(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewLogger(Config{PlanFile: "docs/plans/feature.md", Mode: "full", Dir: dir}, testColors(), &status.PhaseHolder{})
+	require.NoError(t, err)
+	defer l.Close()
+	l.stdout = io.Discard
+
+	for i := range 100 {
+		l.Print("line %d %s", i, strings.Repeat("x", 100))
+	}
+	assert.NoFileExists(t, BackupPath(l.Path(), 1))
+}
+
+func TestBackupPath(t *testing.T) {
+	assert.Equal(t, "logs/progress-feature-2024-06-01T10-00-00.1.txt", BackupPath("logs/progress-feature-2024-06-01T10-00-00.txt", 1))
+	assert.Equal(t, "progress.12.txt", BackupPath("progress.txt", 12))
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"logs/progress-feature-2024-06-01T10-00-00.1.txt", true},
+		{"progress-feature.12.txt", true},
+		{"logs/progress-feature-2024-06-01T10-00-00.txt", false},
+		{"progress-v1.2-2024-06-01T10-00-00.txt", false},
+		{"progress-feature.1.jsonl", false},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, IsBackupPath(tc.path), tc.path)
+	}
+}
+
 func TestSanitizePlanName(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -697,7 +1001,7 @@ func TestParseColorOrPanic(t *testing.T) {
 		for _, tc := range tests {
 			t.Run(tc.name, func(t *testing.T) {
 				assert.NotPanics(t, func() {
-					c := parseColorOrPanic(tc.s, "test")
+					c := parseColorOrPanic(tc.s, "test", paletteTrueColor)
 					assert.NotNil(t, c)
 				})
 			})
@@ -727,7 +1031,7 @@ func TestParseColorOrPanic(t *testing.T) {
 		for _, tc := range tests {
 			t.Run(tc.name, func(t *testing.T) {
 				assert.Panics(t, func() {
-					parseColorOrPanic(tc.s, "test")
+					parseColorOrPanic(tc.s, "test", paletteTrueColor)
 				})
 			})
 		}
@@ -763,6 +1067,25 @@ func TestLogger_LogQuestion(t *testing.T) {
 	assert.Contains(t, output, "OPTIONS: Redis, In-memory, File-based")
 }
 
+func TestLogger_LogQuestion_NoOptions(t *testing.T) {
+	holder := &status.PhaseHolder{}
+	l, err := NewLogger(Config{Mode: "plan", PlanDescription: "test", Branch: "main", Dir: t.TempDir(), NoColor: true},
+		testColors(), holder)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	var buf bytes.Buffer
+	l.stdout = &buf
+
+	l.LogQuestion("Which port?", nil)
+
+	content, err := os.ReadFile(l.Path())
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "QUESTION: Which port?")
+	assert.NotContains(t, string(content), "OPTIONS:")
+	assert.NotContains(t, buf.String(), "OPTIONS:")
+}
+
 func TestLogger_LogAnswer(t *testing.T) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
@@ -788,6 +1111,56 @@ func TestLogger_LogAnswer(t *testing.T) {
 	assert.Contains(t, buf.String(), "ANSWER: Redis")
 }
 
+func TestLogger_LogAnswer_Multiline(t *testing.T) {
+	holder := &status.PhaseHolder{}
+	l, err := NewLogger(Config{Mode: "plan", PlanDescription: "test", Branch: "main", Dir: t.TempDir(), NoColor: true},
+		testColors(), holder)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+
+	var buf bytes.Buffer
+	l.stdout = &buf
+
+	l.LogAnswer("GET /health\nGET /metrics")
+
+	content, err := os.ReadFile(l.Path())
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "ANSWER: GET /health\n")
+	assert.Contains(t, string(content), "        GET /metrics\n")
+	assert.Contains(t, buf.String(), "        GET /metrics")
+}
+
+func TestAnswerLines(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   []string
+	}{
+		{answer: "Redis", want: []string{"ANSWER: Redis"}},
+		{answer: "", want: []string{"ANSWER: "}},
+		{answer: "first\nsecond\n\nfourth", want: []string{"ANSWER: first", "        second", "        ", "        fourth"}},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.want, AnswerLines(tc.answer), tc.answer)
+	}
+}
+
+func TestLogger_PrintTool(t *testing.T) {
+	holder := &status.PhaseHolder{}
+	l, err := NewLogger(Config{Mode: "full", PlanFile: "plan.md", Branch: "main", Dir: t.TempDir(), NoColor: true},
+		testColors(), holder)
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+	var buf bytes.Buffer
+	l.stdout = &buf
+
+	l.PrintTool("Read: main.go")
+
+	content, err := os.ReadFile(l.Path())
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "] → Read: main.go\n")
+	assert.Regexp(t, `^\[[\d-]+ [\d:]+\] → Read: main.go\n$`, buf.String())
+}
+
 func TestLogger_LogDraftReview_Accept(t *testing.T) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
@@ -879,7 +1252,7 @@ func TestLogger_PlanModeFilename(t *testing.T) {
 			require.NoError(t, err)
 			defer l.Close()
 
-			assert.Equal(t, tc.wantBase, filepath.Base(l.Path()))
+			assertRunFilename(t, tc.wantBase, l.Path())
 			assert.Contains(t, l.Path(), ".ralphex/progress")
 
 			content, err := os.ReadFile(l.Path())
@@ -0,0 +1,81 @@
+package status
+
+import (
+	"context"
+	"sync"
+)
+
+// PauseHolder stores the paused state of a run in a thread-safe way.
+// the web dashboard pauses and resumes the run, the runner waits at the top of each iteration while paused.
+type PauseHolder struct {
+	mu       sync.Mutex
+	resumeCh chan struct{} // closed on resume, nil while running
+	onChange []func(paused bool)
+}
+
+// OnChange registers a callback that fires when the run is paused or resumed.
+// repeated Pause or Resume calls that don't change the state don't fire it.
+func (h *PauseHolder) OnChange(fn func(paused bool)) {
+	h.mu.Lock()
+	h.onChange = append(h.onChange, fn)
+	h.mu.Unlock()
+}
+
+// Pause marks the run as paused, returns false if it was already paused.
+func (h *PauseHolder) Pause() bool {
+	h.mu.Lock()
+	if h.resumeCh != nil {
+		h.mu.Unlock()
+		return false
+	}
+	h.resumeCh = make(chan struct{})
+	callbacks := h.onChange
+	h.mu.Unlock()
+
+	for _, cb := range callbacks {
+		cb(true)
+	}
+	return true
+}
+
+// Resume releases a paused run, returns false if it wasn't paused.
+func (h *PauseHolder) Resume() bool {
+	h.mu.Lock()
+	if h.resumeCh == nil {
+		h.mu.Unlock()
+		return false
+	}
+	close(h.resumeCh)
+	h.resumeCh = nil
+	callbacks := h.onChange
+	h.mu.Unlock()
+
+	for _, cb := range callbacks {
+		cb(false)
+	}
+	return true
+}
+
+// Paused returns true if the run is paused.
+func (h *PauseHolder) Paused() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.resumeCh != nil
+}
+
+// Wait blocks while the run is paused. returns nil once resumed (or if not paused) and ctx.Err() on cancellation.
+func (h *PauseHolder) Wait(ctx context.Context) error {
+	for {
+		h.mu.Lock()
+		ch := h.resumeCh
+		h.mu.Unlock()
+		if ch == nil {
+			return nil
+		}
+		select {
+		case <-ch: // re-check, the run may have been paused again right after resume
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck // callers wrap with their phase
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package status
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseHolder_PauseResume(t *testing.T) {
+	h := &PauseHolder{}
+	var changes []bool
+	h.OnChange(func(paused bool) { changes = append(changes, paused) })
+
+	assert.False(t, h.Paused())
+	assert.False(t, h.Resume(), "resume of a running holder is a no-op")
+
+	assert.True(t, h.Pause())
+	assert.True(t, h.Paused())
+	assert.False(t, h.Pause(), "second pause is a no-op")
+
+	assert.True(t, h.Resume())
+	assert.False(t, h.Paused())
+	assert.Equal(t, []bool{true, false}, changes, "callbacks fire only on state changes")
+}
+
+func TestPauseHolder_Wait(t *testing.T) {
+	t.Run("not paused returns immediately", func(t *testing.T) {
+		h := &PauseHolder{}
+		require.NoError(t, h.Wait(context.Background()))
+	})
+
+	t.Run("returns after resume", func(t *testing.T) {
+		h := &PauseHolder{}
+		h.Pause()
+		done := make(chan error, 1)
+		go func() { done <- h.Wait(context.Background()) }()
+
+		select {
+		case <-done:
+			t.Fatal("wait returned while paused")
+		case <-time.After(20 * time.Millisecond):
+		}
+		h.Resume()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("wait didn't return after resume")
+		}
+	})
+
+	t.Run("returns on context cancel", func(t *testing.T) {
+		h := &PauseHolder{}
+		h.Pause()
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- h.Wait(ctx) }()
+		cancel()
+		select {
+		case err := <-done:
+			require.ErrorIs(t, err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("wait didn't return after cancel")
+		}
+		assert.True(t, h.Paused(), "cancel doesn't resume")
+	})
+}
@@ -7,14 +7,14 @@ import "sync"
 type PhaseHolder struct {
 	mu       sync.RWMutex
 	phase    Phase
-	onChange func(old, cur Phase)
+	onChange []func(old, cur Phase)
 }
 
 // OnChange registers a callback that fires when the phase changes.
-// only one callback is supported; subsequent calls replace the previous one.
+// callbacks accumulate (runner, event log and dashboard each add one) and run outside the lock.
 func (h *PhaseHolder) OnChange(fn func(old, cur Phase)) {
 	h.mu.Lock()
-	h.onChange = fn
+	h.onChange = append(h.onChange, fn)
 	h.mu.Unlock()
 }
 
@@ -23,10 +23,13 @@ func (h *PhaseHolder) Set(p Phase) {
 	h.mu.Lock()
 	old := h.phase
 	h.phase = p
-	cb := h.onChange
+	callbacks := h.onChange
 	h.mu.Unlock()
 
-	if old != p && cb != nil {
+	if old == p {
+		return
+	}
+	for _, cb := range callbacks {
 		cb(old, p)
 	}
 }
@@ -50,6 +50,18 @@ func TestPhaseHolder_OnChange_NotFiredOnSamePhase(t *testing.T) {
 	assert.Equal(t, 1, callCount)
 }
 
+func TestPhaseHolder_OnChange_MultipleCallbacks(t *testing.T) {
+	h := &PhaseHolder{}
+
+	var calls []string
+	h.OnChange(func(_, cur Phase) { calls = append(calls, "first:"+string(cur)) })
+	h.OnChange(func(_, cur Phase) { calls = append(calls, "second:"+string(cur)) })
+
+	h.Set(PhaseTask)
+
+	assert.Equal(t, []string{"first:task", "second:task"}, calls)
+}
+
 func TestPhaseHolder_OnChange_NilCallbackSafe(t *testing.T) {
 	h := &PhaseHolder{}
 	// no callback registered - should not panic
@@ -31,6 +31,8 @@ const (
 	SectionPlanIteration
 	// SectionCustomIteration represents a custom review tool iteration.
 	SectionCustomIteration
+	// SectionGeminiIteration represents a Gemini external review iteration.
+	SectionGeminiIteration
 )
 
 // Section carries structured information about a section header.
@@ -106,3 +108,12 @@ func NewCustomIterationSection(iteration int) Section {
 		Label:     fmt.Sprintf("custom review iteration %d", iteration),
 	}
 }
+
+// NewGeminiIterationSection creates a section for Gemini external review iteration.
+func NewGeminiIterationSection(iteration int) Section {
+	return Section{
+		Type:      SectionGeminiIteration,
+		Iteration: iteration,
+		Label:     fmt.Sprintf("gemini review iteration %d", iteration),
+	}
+}
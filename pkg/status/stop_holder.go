@@ -0,0 +1,60 @@
+package status
+
+import "sync"
+
+// StopHolder stores a request to stop the run after its current iteration in a thread-safe way.
+// the first Ctrl+C requests the stop, the runner checks it between iterations and exits cleanly.
+type StopHolder struct {
+	mu        sync.Mutex
+	running   int // number of runs in progress, a stop can be requested only while one is running
+	requested bool
+	onRequest []func()
+}
+
+// OnRequest registers a callback that fires when a stop is requested.
+// it fires at most once, a second Request returns false and its caller cancels the run instead.
+func (h *StopHolder) OnRequest(fn func()) {
+	h.mu.Lock()
+	h.onRequest = append(h.onRequest, fn)
+	h.mu.Unlock()
+}
+
+// Begin marks a run as in progress, End must be called when it returns.
+func (h *StopHolder) Begin() {
+	h.mu.Lock()
+	h.running++
+	h.mu.Unlock()
+}
+
+// End marks a run started with Begin as finished.
+func (h *StopHolder) End() {
+	h.mu.Lock()
+	h.running--
+	h.mu.Unlock()
+}
+
+// Request asks the run in progress to stop after its current iteration.
+// returns false if no run is in progress or a stop was already requested,
+// the caller is expected to cancel the run instead.
+func (h *StopHolder) Request() bool {
+	h.mu.Lock()
+	if h.running <= 0 || h.requested {
+		h.mu.Unlock()
+		return false
+	}
+	h.requested = true
+	callbacks := h.onRequest
+	h.mu.Unlock()
+
+	for _, cb := range callbacks {
+		cb()
+	}
+	return true
+}
+
+// Requested returns true if a stop was requested.
+func (h *StopHolder) Requested() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.requested
+}
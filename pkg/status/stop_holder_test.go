@@ -0,0 +1,35 @@
+package status
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopHolder_Request(t *testing.T) {
+	h := &StopHolder{}
+	calls := 0
+	h.OnRequest(func() { calls++ })
+
+	assert.False(t, h.Request(), "no run in progress")
+	assert.False(t, h.Requested())
+
+	h.Begin()
+	assert.True(t, h.Request())
+	assert.True(t, h.Requested())
+	assert.False(t, h.Request(), "second request is a no-op")
+	h.End()
+
+	assert.True(t, h.Requested(), "request outlives the run, a queue stops before its next plan")
+	assert.Equal(t, 1, calls, "callbacks fire only on the first request")
+}
+
+func TestStopHolder_NestedRuns(t *testing.T) {
+	h := &StopHolder{}
+	h.Begin()
+	h.Begin()
+	h.End()
+	assert.True(t, h.Request(), "one run still in progress")
+	h.End()
+	assert.False(t, h.Request())
+}
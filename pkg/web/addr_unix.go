@@ -0,0 +1,13 @@
+//go:build !windows
+
+package web
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isAddrInUse reports whether err is a bind failure caused by the port already being in use.
+func isAddrInUse(err error) bool {
+	return errors.Is(err, syscall.EADDRINUSE)
+}
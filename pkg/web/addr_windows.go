@@ -0,0 +1,15 @@
+//go:build windows
+
+package web
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// isAddrInUse reports whether err is a bind failure caused by the port already being in use.
+// winsock reports it as WSAEADDRINUSE, which syscall.EADDRINUSE doesn't match on windows.
+func isAddrInUse(err error) bool {
+	return errors.Is(err, windows.WSAEADDRINUSE)
+}
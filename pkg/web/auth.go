@@ -0,0 +1,49 @@
+package web
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// authCookieName holds the dashboard token after it was passed once as ?token=,
+// so the page's own requests (static files, API calls, EventSource) are authorized without changes to the JS.
+const authCookieName = "ralphex_token"
+
+// withAuth wraps the handler to require cfg.AuthToken on every route, a no-op without a token.
+// the token is accepted as "Authorization: Bearer <token>", as ?token= query parameter
+// (EventSource can't set headers, and it's how the dashboard is opened in a browser) or from the auth cookie.
+// unauthorized requests get 401.
+func (s *Server) withAuth(next http.Handler) http.Handler {
+	if s.cfg.AuthToken == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("token"); token != "" && s.validToken(token) {
+			http.SetCookie(w, &http.Cookie{
+				Name:     authCookieName,
+				Value:    token,
+				Path:     "/",
+				HttpOnly: true,
+				SameSite: http.SameSiteStrictMode,
+			})
+			next.ServeHTTP(w, r)
+			return
+		}
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && s.validToken(token) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if c, err := r.Cookie(authCookieName); err == nil && s.validToken(c.Value) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer realm="ralphex"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// validToken compares the token with the configured one in constant time.
+func (s *Server) validToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.AuthToken)) == 1
+}
@@ -0,0 +1,106 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Auth(t *testing.T) {
+	newHandler := func(t *testing.T, token string) http.Handler {
+		t.Helper()
+		session := NewSession("main", "/tmp/test.txt")
+		t.Cleanup(session.Close)
+		srv, err := NewServer(ServerConfig{PlanName: "test", AuthToken: token}, session)
+		require.NoError(t, err)
+		handler, err := srv.handler()
+		require.NoError(t, err)
+		return handler
+	}
+
+	tests := []struct {
+		name   string
+		token  string // configured token
+		path   string
+		header string // Authorization header
+		cookie string // auth cookie value
+		want   int
+	}{
+		{name: "no token configured", path: "/", want: http.StatusOK},
+		{name: "missing token", token: "secret", path: "/", want: http.StatusUnauthorized},
+		{name: "missing token on api", token: "secret", path: "/api/sessions", want: http.StatusUnauthorized},
+		{name: "missing token on static", token: "secret", path: "/static/app.js", want: http.StatusUnauthorized},
+		{name: "bearer header", token: "secret", path: "/api/sessions", header: "Bearer secret", want: http.StatusOK},
+		{name: "wrong bearer header", token: "secret", path: "/api/sessions", header: "Bearer nope", want: http.StatusUnauthorized},
+		{name: "basic header", token: "secret", path: "/api/sessions", header: "Basic secret", want: http.StatusUnauthorized},
+		{name: "query token", token: "secret", path: "/?token=secret", want: http.StatusOK},
+		{name: "wrong query token", token: "secret", path: "/?token=nope", want: http.StatusUnauthorized},
+		{name: "cookie", token: "secret", path: "/static/app.js", cookie: "secret", want: http.StatusOK},
+		{name: "wrong cookie", token: "secret", path: "/static/app.js", cookie: "nope", want: http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, http.NoBody)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: authCookieName, Value: tc.cookie})
+			}
+			w := httptest.NewRecorder()
+			newHandler(t, tc.token).ServeHTTP(w, req)
+			assert.Equal(t, tc.want, w.Code)
+			if tc.want == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer realm="ralphex"`, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+
+	t.Run("query token sets cookie for the page requests", func(t *testing.T) {
+		handler := newHandler(t, "secret")
+		ts := httptest.NewServer(handler)
+		defer ts.Close()
+
+		resp, err := http.Get(ts.URL + "/?token=secret")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var cookie *http.Cookie
+		for _, c := range resp.Cookies() {
+			if c.Name == authCookieName {
+				cookie = c
+			}
+		}
+		require.NotNil(t, cookie)
+		assert.True(t, cookie.HttpOnly)
+
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/sessions", http.NoBody)
+		require.NoError(t, err)
+		req.AddCookie(cookie)
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("sse endpoint accepts query token", func(t *testing.T) {
+		sm := NewSessionManager()
+		defer sm.Close()
+		srv, err := NewServerWithSessions(ServerConfig{AuthToken: "secret"}, sm)
+		require.NoError(t, err)
+		handler, err := srv.handler()
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events?session=missing", http.NoBody))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		// the request reaches the SSE handler, which reports the unknown session
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events?session=missing&token=secret", http.NoBody))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
+	"github.com/umputun/ralphex/pkg/progress"
 	"github.com/umputun/ralphex/pkg/status"
 )
 
@@ -16,6 +18,7 @@ type Logger interface {
 	PrintRaw(format string, args ...any)
 	PrintSection(section status.Section)
 	PrintAligned(text string)
+	PrintTool(text string)
 	LogQuestion(question string, options []string)
 	LogAnswer(answer string)
 	LogDraftReview(action string, feedback string)
@@ -49,18 +52,24 @@ func NewBroadcastLogger(inner Logger, session *Session, holder *status.PhaseHold
 }
 
 // onPhaseChanged handles phase transition events.
-// emits task_end event if transitioning away from task phase with an active task.
-func (b *BroadcastLogger) onPhaseChanged(old, _ status.Phase) {
+// emits task_end event if transitioning away from task phase with an active task,
+// then starts the new phase on the session timeline.
+func (b *BroadcastLogger) onPhaseChanged(old, cur status.Phase) {
 	if old == status.PhaseTask && b.currentTask > 0 {
 		b.broadcast(NewTaskEndEvent(old, b.currentTask, fmt.Sprintf("task %d completed", b.currentTask)))
 		b.currentTask = 0
 	}
+	b.session.StartPhase(cur, time.Now())
 }
 
 // Print writes a timestamped message and broadcasts it.
 func (b *BroadcastLogger) Print(format string, args ...any) {
 	b.inner.Print(format, args...)
-	b.broadcast(NewOutputEvent(b.holder.Get(), formatText(format, args...)))
+	text := formatText(format, args...)
+	if pc, ok := parsePhaseChanges(text); ok {
+		b.session.AddPhaseChanges(pc)
+	}
+	b.broadcast(NewOutputEvent(b.holder.Get(), text))
 }
 
 // PrintRaw writes without timestamp and broadcasts it.
@@ -111,17 +120,27 @@ func (b *BroadcastLogger) PrintAligned(text string) {
 	}
 }
 
+// PrintTool writes a tool call one-liner and broadcasts it.
+func (b *BroadcastLogger) PrintTool(text string) {
+	b.inner.PrintTool(text)
+	b.broadcast(NewOutputEvent(b.holder.Get(), progress.ToolPrefix+text))
+}
+
 // LogQuestion logs a question and its options for plan creation mode.
 func (b *BroadcastLogger) LogQuestion(question string, options []string) {
 	b.inner.LogQuestion(question, options)
 	b.broadcast(NewOutputEvent(b.holder.Get(), "QUESTION: "+question))
-	b.broadcast(NewOutputEvent(b.holder.Get(), "OPTIONS: "+strings.Join(options, ", ")))
+	if len(options) > 0 {
+		b.broadcast(NewOutputEvent(b.holder.Get(), "OPTIONS: "+strings.Join(options, ", ")))
+	}
 }
 
 // LogAnswer logs the user's answer for plan creation mode.
 func (b *BroadcastLogger) LogAnswer(answer string) {
 	b.inner.LogAnswer(answer)
-	b.broadcast(NewOutputEvent(b.holder.Get(), "ANSWER: "+answer))
+	for _, line := range progress.AnswerLines(answer) {
+		b.broadcast(NewOutputEvent(b.holder.Get(), line))
+	}
 }
 
 // LogDraftReview logs the user's draft review action and optional feedback.
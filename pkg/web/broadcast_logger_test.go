@@ -2,6 +2,7 @@ package web
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -70,6 +71,22 @@ func TestBroadcastLogger_Print(t *testing.T) {
 	assert.Equal(t, []any{"world"}, mockLogger.PrintCalls()[0].Args)
 }
 
+func TestBroadcastLogger_Print_PhaseChanges(t *testing.T) {
+	mockLogger := &mocks.LoggerMock{
+		PrintFunc: func(string, ...any) {},
+	}
+	session := NewSession("test", "/tmp/test.txt")
+	defer session.Close()
+
+	bl := NewBroadcastLogger(mockLogger, session, &status.PhaseHolder{})
+
+	bl.Print("%s", "task phase: 7 commits, 23 files changed (+812/-310)")
+	bl.Print("hello")
+
+	assert.Equal(t, []PhaseChanges{{Phase: "task", Commits: 7, Files: 23, Additions: 812, Deletions: 310}},
+		session.GetPhaseChanges())
+}
+
 func TestBroadcastLogger_PrintRaw(t *testing.T) {
 	mockLogger := &mocks.LoggerMock{
 		PrintRawFunc: func(string, ...any) {},
@@ -123,6 +140,26 @@ func TestBroadcastLogger_PrintAligned(t *testing.T) {
 	assert.Equal(t, "aligned text", mockLogger.PrintAlignedCalls()[0].Text)
 }
 
+func TestBroadcastLogger_PrintTool(t *testing.T) {
+	mockLogger := &mocks.LoggerMock{
+		PrintToolFunc: func(string) {},
+	}
+	session := NewSession("test", "/tmp/test.txt")
+	defer session.Close()
+
+	holder := &status.PhaseHolder{}
+	holder.Set(status.PhaseTask)
+	bl := NewBroadcastLogger(mockLogger, session, holder)
+
+	bl.PrintTool("Bash: make test")
+
+	require.Len(t, mockLogger.PrintToolCalls(), 1)
+	assert.Equal(t, "Bash: make test", mockLogger.PrintToolCalls()[0].Text)
+	ev, ok := session.LastEvent()
+	require.True(t, ok)
+	assert.Equal(t, "→ Bash: make test", ev.Event.Text)
+}
+
 func TestBroadcastLogger_Path(t *testing.T) {
 	mockLogger := &mocks.LoggerMock{
 		PathFunc: func() string { return "/test/progress.txt" },
@@ -309,3 +346,28 @@ func TestExtractTerminalSignal(t *testing.T) {
 		})
 	}
 }
+
+func TestBroadcastLogger_PhaseTransition_Timeline(t *testing.T) {
+	mockLogger := &mocks.LoggerMock{PrintFunc: func(string, ...any) {}}
+	session := NewSession("test", "/tmp/test.txt")
+	defer session.Close()
+
+	holder := &status.PhaseHolder{}
+	bl := NewBroadcastLogger(mockLogger, session, holder)
+
+	for _, phase := range []status.Phase{status.PhaseTask, status.PhaseReview, status.PhaseCodex} {
+		holder.Set(phase)
+		time.Sleep(5 * time.Millisecond)
+		bl.Print("working on %s", phase)
+	}
+
+	timeline := session.GetTimeline()
+	require.Len(t, timeline, 3)
+	for i, phase := range []status.Phase{status.PhaseTask, status.PhaseReview, status.PhaseCodex} {
+		assert.Equal(t, phase, timeline[i].Phase)
+		assert.Positive(t, timeline[i].DurationMs, "phase %s", phase)
+		if i > 0 {
+			assert.Equal(t, timeline[i-1].End, timeline[i].Start, "phase %s starts where the previous one ends", phase)
+		}
+	}
+}
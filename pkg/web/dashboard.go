@@ -2,12 +2,18 @@ package web
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
+	"log"
+	"net"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"time"
 
+	plans "github.com/umputun/ralphex/pkg/plan"
 	"github.com/umputun/ralphex/pkg/progress"
 	"github.com/umputun/ralphex/pkg/status"
 )
@@ -17,39 +23,91 @@ const serverStartupTimeout = 100 * time.Millisecond
 
 // DashboardConfig holds configuration for dashboard initialization.
 type DashboardConfig struct {
-	BaseLog         Logger           // base progress logger
-	Port            int              // web server port
-	PlanFile        string           // path to plan file (empty for watch-only mode)
-	Branch          string           // current git branch
-	WatchDirs       []string         // CLI watch directories
-	ConfigWatchDirs []string         // config file watch directories
-	Colors          *progress.Colors // colors for output
+	BaseLog         Logger              // base progress logger
+	Listen          string              // address to listen on, empty for 127.0.0.1
+	Port            int                 // web server port
+	PortAuto        bool                // use the next free port when Port is in use
+	PlanFile        string              // path to plan file (empty for watch-only mode)
+	Branch          string              // current git branch
+	WatchDirs       []string            // CLI watch directories
+	ConfigWatchDirs []string            // config file watch directories
+	Watch           WatchOptions        // search depth and ignored directories of watched directories
+	Colors          *progress.Colors    // colors for output
+	Pause           *status.PauseHolder // pause control of the run, nil disables pause/resume
+	Stop            *status.StopHolder  // stop requests of the run, shown as stopping; nil if the run can't be stopped
+	PruneAfter      time.Duration       // drop stopped watched sessions not modified for this long, zero keeps them
+	IdleAfter       time.Duration       // mark running watched sessions completed after this long without writes, zero disables
+	Metrics         bool                // serve prometheus metrics on /metrics
+	AuthToken       string              // token required to access the dashboard, generated if empty on a non-loopback address
+	WebSocket       bool                // stream over a websocket that also accepts control commands
+	Questions       *QuestionRelay      // plan questions of the run answerable from the dashboard, nil disables
+	PlanArchive     plans.Archive       // where plans moved as completed are looked up
+	Open            bool                // open the dashboard in the browser once the server listens
 }
 
 // Dashboard manages web server and file watching for progress monitoring.
 type Dashboard struct {
+	listen          string
 	port            int
+	portAuto        bool
 	planFile        string
 	branch          string
 	baseLog         Logger
 	watchDirs       []string
 	configWatchDirs []string
+	watchOpts       WatchOptions
 	colors          *progress.Colors
 	holder          *status.PhaseHolder
+	pause           *status.PauseHolder
+	stop            *status.StopHolder
+	pruneAfter      time.Duration
+	idleAfter       time.Duration
+	metrics         bool
+	authToken       string
+	tokenGenerated  bool // authToken was generated, the printed URL includes it
+	webSocket       bool
+	questions       *QuestionRelay
+	planArchive     plans.Archive
+	open            bool
+	goos            string
+	startBrowser    func(name string, args ...string) error // overridden in tests
+
+	srv     *Server  // set by Start
+	session *Session // set by Start
 }
 
 // NewDashboard creates a new dashboard with the given configuration.
+// a dashboard listening beyond loopback without a configured token gets a generated one.
 func NewDashboard(cfg DashboardConfig, holder *status.PhaseHolder) *Dashboard {
-	return &Dashboard{
+	d := &Dashboard{
+		listen:          cfg.Listen,
 		port:            cfg.Port,
+		portAuto:        cfg.PortAuto,
 		planFile:        cfg.PlanFile,
 		branch:          cfg.Branch,
 		baseLog:         cfg.BaseLog,
 		watchDirs:       cfg.WatchDirs,
 		configWatchDirs: cfg.ConfigWatchDirs,
+		watchOpts:       cfg.Watch,
 		colors:          cfg.Colors,
 		holder:          holder,
+		pause:           cfg.Pause,
+		stop:            cfg.Stop,
+		pruneAfter:      cfg.PruneAfter,
+		idleAfter:       cfg.IdleAfter,
+		metrics:         cfg.Metrics,
+		authToken:       cfg.AuthToken,
+		webSocket:       cfg.WebSocket,
+		questions:       cfg.Questions,
+		planArchive:     cfg.PlanArchive,
+		open:            cfg.Open,
+		goos:            runtime.GOOS,
+		startBrowser:    startBrowser,
 	}
+	if d.authToken == "" && !isLoopback(d.listen) {
+		d.authToken, d.tokenGenerated = rand.Text(), true
+	}
+	return d
 }
 
 // Start creates the web server and broadcast logger, starting the server in background.
@@ -59,6 +117,34 @@ func (d *Dashboard) Start(ctx context.Context) (*BroadcastLogger, error) {
 	// create session for SSE streaming (handles both live streaming and history replay)
 	session := NewSession("main", d.baseLog.Path())
 	broadcastLog := NewBroadcastLogger(d.baseLog, session, d.holder)
+	if d.pause != nil {
+		session.SetPauseHolder(d.pause)
+		d.pause.OnChange(func(paused bool) {
+			if err := session.Publish(NewPauseEvent(d.holder.Get(), paused)); err != nil {
+				log.Printf("[WARN] failed to publish pause event: %v", err)
+			}
+		})
+	}
+	if d.stop != nil {
+		d.stop.OnRequest(func() {
+			if err := session.Publish(NewStopEvent(d.holder.Get())); err != nil {
+				log.Printf("[WARN] failed to publish stop event: %v", err)
+			}
+		})
+	}
+	if d.questions != nil {
+		session.SetQuestionRelay(d.questions)
+		d.questions.OnAsk(func(q Question) {
+			if err := session.Publish(NewQuestionEvent(d.holder.Get(), q)); err != nil {
+				log.Printf("[WARN] failed to publish question event: %v", err)
+			}
+		})
+		d.questions.OnClose(func(answer string) {
+			if err := session.Publish(NewQuestionClosedEvent(d.holder.Get(), answer)); err != nil {
+				log.Printf("[WARN] failed to publish question event: %v", err)
+			}
+		})
+	}
 
 	// extract plan name for display
 	planName := "(no plan)"
@@ -67,10 +153,17 @@ func (d *Dashboard) Start(ctx context.Context) (*BroadcastLogger, error) {
 	}
 
 	cfg := ServerConfig{
-		Port:     d.port,
-		PlanName: planName,
-		Branch:   d.branch,
-		PlanFile: d.planFile,
+		Listen:          d.listen,
+		Port:            d.port,
+		PortAuto:        d.portAuto,
+		PlanName:        planName,
+		Branch:          d.branch,
+		PlanFile:        d.planFile,
+		MetricsEnabled:  d.metrics,
+		AuthToken:       d.authToken,
+		EnableWebSocket: d.webSocket,
+		PlanArchive:     d.planArchive,
+		HistoryDir:      filepath.Dir(d.baseLog.Path()),
 	}
 
 	// determine if we should use multi-session mode
@@ -83,6 +176,8 @@ func (d *Dashboard) Start(ctx context.Context) (*BroadcastLogger, error) {
 	if useMultiSession {
 		// multi-session mode: use SessionManager and Watcher
 		sm := NewSessionManager()
+		sm.SetPruneAfter(d.pruneAfter)
+		sm.SetIdleAfter(d.idleAfter)
 
 		// register the live execution session so dashboard uses it instead of creating a duplicate
 		// this ensures live events from BroadcastLogger go to the same session the dashboard displays
@@ -96,6 +191,7 @@ func (d *Dashboard) Start(ctx context.Context) (*BroadcastLogger, error) {
 		if err != nil {
 			return nil, fmt.Errorf("create watcher: %w", err)
 		}
+		watcher.SetOptions(d.watchOpts)
 
 		srv, err = NewServerWithSessions(cfg, sm)
 		if err != nil {
@@ -134,10 +230,32 @@ func (d *Dashboard) Start(ctx context.Context) (*BroadcastLogger, error) {
 		}
 	}()
 
-	d.colors.Info().Printf("web dashboard: http://localhost:%d\n", d.port)
+	d.srv, d.session = srv, session
+	d.useBoundPort(srv)
+	d.colors.Info().Printf("web dashboard: %s\n", d.url())
+	d.openBrowser()
 	return broadcastLog, nil
 }
 
+// Stop ends the streams of the session started by Start and shuts the server down, freeing the port.
+// a no-op if the dashboard wasn't started.
+func (d *Dashboard) Stop() error {
+	if d.srv == nil {
+		return nil
+	}
+	d.session.Close() // ends open SSE and websocket streams, otherwise shutdown waits for them
+	return d.srv.Stop()
+}
+
+// Metrics returns the collector served on /metrics, for the runner to report its counters to.
+// returns nil if the dashboard wasn't started or metrics are disabled.
+func (d *Dashboard) Metrics() *Metrics {
+	if d.srv == nil || !d.metrics {
+		return nil
+	}
+	return d.srv.metrics
+}
+
 // RunWatchOnly runs the web dashboard in watch-only mode without plan execution.
 // monitors directories for progress files and serves the multi-session dashboard.
 func (d *Dashboard) RunWatchOnly(ctx context.Context, dirs []string) error {
@@ -147,43 +265,52 @@ func (d *Dashboard) RunWatchOnly(ctx context.Context, dirs []string) error {
 	}
 
 	// setup server and watcher
-	srvErrCh, watchErrCh, err := setupWatchMode(ctx, d.port, dirs)
+	serverCfg := ServerConfig{
+		Listen:          d.listen,
+		Port:            d.port,
+		PortAuto:        d.portAuto,
+		PlanName:        "(watch mode)",
+		MetricsEnabled:  d.metrics,
+		AuthToken:       d.authToken,
+		EnableWebSocket: d.webSocket,
+		PlanArchive:     d.planArchive,
+	}
+	srv, srvErrCh, watchErrCh, err := setupWatchMode(ctx, serverCfg, dirs, d.watchOpts, d.pruneAfter, d.idleAfter)
 	if err != nil {
 		return err
 	}
+	d.useBoundPort(srv)
 
 	// print startup info
-	printWatchInfo(dirs, d.port, d.colors)
+	printWatchInfo(dirs, d.url(), d.colors)
+	d.openBrowser()
 
 	// monitor for errors until shutdown
 	return monitorErrors(ctx, srvErrCh, watchErrCh, d.colors)
 }
 
 // setupWatchMode creates and starts the web server and file watcher for watch-only mode.
-// returns error channels for monitoring both components.
-func setupWatchMode(ctx context.Context, port int, dirs []string) (chan error, chan error, error) {
+// returns the started server and error channels for monitoring both components.
+func setupWatchMode(ctx context.Context, serverCfg ServerConfig, dirs []string, watchOpts WatchOptions,
+	pruneAfter, idleAfter time.Duration) (*Server, chan error, chan error, error) {
 	sm := NewSessionManager()
+	sm.SetPruneAfter(pruneAfter)
+	sm.SetIdleAfter(idleAfter)
 	watcher, err := NewWatcher(dirs, sm)
 	if err != nil {
-		return nil, nil, fmt.Errorf("create watcher: %w", err)
-	}
-
-	serverCfg := ServerConfig{
-		Port:     port,
-		PlanName: "(watch mode)",
-		Branch:   "",
-		PlanFile: "",
+		return nil, nil, nil, fmt.Errorf("create watcher: %w", err)
 	}
+	watcher.SetOptions(watchOpts)
 
 	srv, err := NewServerWithSessions(serverCfg, sm)
 	if err != nil {
-		return nil, nil, fmt.Errorf("create web server: %w", err)
+		return nil, nil, nil, fmt.Errorf("create web server: %w", err)
 	}
 
 	// start server with startup check
-	srvErrCh, err := startServerAsync(ctx, srv, port)
+	srvErrCh, err := startServerAsync(ctx, srv, serverCfg.Port)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	// start watcher in background
@@ -195,12 +322,16 @@ func setupWatchMode(ctx context.Context, port int, dirs []string) (chan error, c
 		close(watchErrCh)
 	}()
 
-	return srvErrCh, watchErrCh, nil
+	return srv, srvErrCh, watchErrCh, nil
 }
 
-// startServerAsync starts a web server in the background and waits briefly for startup errors.
-// returns the error channel for monitoring late errors, or an error if startup fails.
+// startServerAsync binds the web server address, starts serving in the background and waits briefly
+// for startup errors. returns the error channel for monitoring late errors, or an error if startup fails.
 func startServerAsync(ctx context.Context, srv *Server, port int) (chan error, error) {
+	if err := srv.Listen(); err != nil {
+		return nil, fmt.Errorf("web server failed to start on port %d: %w", port, err)
+	}
+
 	errCh := make(chan error, 1)
 	go func() {
 		if err := srv.Start(ctx); err != nil {
@@ -254,11 +385,57 @@ func monitorErrors(ctx context.Context, srvErrCh, watchErrCh chan error, colors
 }
 
 // printWatchInfo prints startup information for watch-only mode.
-func printWatchInfo(dirs []string, port int, colors *progress.Colors) {
+func printWatchInfo(dirs []string, url string, colors *progress.Colors) {
 	colors.Info().Printf("watch-only mode: monitoring %d directories\n", len(dirs))
 	for _, dir := range dirs {
 		colors.Info().Printf("  %s\n", dir)
 	}
-	colors.Info().Printf("web dashboard: http://localhost:%d\n", port)
+	colors.Info().Printf("web dashboard: %s\n", url)
 	colors.Info().Printf("press Ctrl+C to exit\n")
 }
+
+// useBoundPort switches the dashboard to the port the server is bound to, it differs from the configured one
+// with PortAuto or a random port (0). a fallback from a port in use is reported.
+func (d *Dashboard) useBoundPort(srv *Server) {
+	port := srv.Port()
+	if d.port != 0 && port != d.port {
+		d.colors.Info().Printf("port %d is in use, web dashboard uses port %d\n", d.port, port)
+	}
+	d.port = port
+}
+
+// url returns the dashboard address to print.
+// a configured token is replaced by a placeholder, terminal output often ends up in logs,
+// a generated one is printed as is since there is no other way to learn it.
+func (d *Dashboard) url() string {
+	if d.tokenGenerated {
+		return dashboardURL(d.listen, d.port, d.authToken)
+	}
+	if d.authToken != "" {
+		return dashboardURL(d.listen, d.port, "<dashboard token>")
+	}
+	return dashboardURL(d.listen, d.port, "")
+}
+
+// dashboardURL returns the dashboard address for the listen address, with ?token= if token is set.
+// loopback and wildcard addresses are shown as localhost.
+func dashboardURL(listen string, port int, token string) string {
+	host := listen
+	if ip := net.ParseIP(listen); listen == "" || ip != nil && (ip.IsLoopback() || ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	url := "http://" + net.JoinHostPort(host, strconv.Itoa(port))
+	if token != "" {
+		url += "/?token=" + token
+	}
+	return url
+}
+
+// isLoopback reports whether the listen address accepts local connections only.
+func isLoopback(listen string) bool {
+	if listen == "" || listen == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(listen)
+	return ip != nil && ip.IsLoopback()
+}
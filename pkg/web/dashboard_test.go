@@ -2,6 +2,7 @@ package web
 
 import (
 	"context"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/umputun/ralphex/pkg/progress"
 	"github.com/umputun/ralphex/pkg/status"
+	"github.com/umputun/ralphex/pkg/web/mocks"
 )
 
 func TestNewDashboard(t *testing.T) {
@@ -77,6 +79,124 @@ func TestDashboard_Start_SingleSession(t *testing.T) {
 	assert.Equal(t, baseLog.Path(), broadcastLog.Path())
 }
 
+func TestDashboard_Start_PublishesPauseAndStopEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+	colors := testColors()
+	holder := &status.PhaseHolder{}
+	baseLog, err := progress.NewLogger(progress.Config{Mode: "test", Branch: "main", NoColor: true, Dir: tmpDir}, colors, holder)
+	require.NoError(t, err)
+	defer baseLog.Close()
+
+	pause, stop := &status.PauseHolder{}, &status.StopHolder{}
+	d := NewDashboard(DashboardConfig{BaseLog: baseLog, Port: 0, Colors: colors, Pause: pause, Stop: stop}, holder)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	broadcastLog, err := d.Start(ctx)
+	require.NoError(t, err)
+
+	holder.Set(status.PhaseTask)
+	pause.Pause()
+	pause.Resume()
+	stop.Begin()
+	defer stop.End()
+	stop.Request()
+
+	var types []EventType
+	for _, e := range broadcastLog.session.EventsSince(0) {
+		types = append(types, e.Event.Type)
+	}
+	assert.Equal(t, []EventType{EventTypePaused, EventTypeResumed, EventTypeStopping}, types)
+	assert.Equal(t, pause, broadcastLog.session.PauseHolder())
+}
+
+func TestDashboard_Start_PublishesQuestionEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+	colors := testColors()
+	holder := &status.PhaseHolder{}
+	baseLog, err := progress.NewLogger(progress.Config{Mode: "plan", Branch: "main", NoColor: true, Dir: tmpDir}, colors, holder)
+	require.NoError(t, err)
+	defer baseLog.Close()
+
+	inner := &mocks.CollectorMock{
+		AskQuestionFunc: func(context.Context, string, []string) (string, error) { return "sqlite", nil },
+	}
+	questions := NewQuestionRelay(inner)
+	d := NewDashboard(DashboardConfig{BaseLog: baseLog, Port: 0, Colors: colors, Questions: questions, WebSocket: true}, holder)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	broadcastLog, err := d.Start(ctx)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, d.Stop()) }()
+
+	holder.Set(status.PhasePlan)
+	_, err = questions.AskQuestion(ctx, "which db?", []string{"postgres", "sqlite"})
+	require.NoError(t, err)
+
+	events := broadcastLog.session.EventsSince(0)
+	require.Len(t, events, 2)
+	assert.Equal(t, []string{"postgres", "sqlite"}, events[0].Event.Options)
+	assert.Equal(t, EventTypeQuestion, events[0].Event.Type)
+	assert.Equal(t, EventTypeQuestionClosed, events[1].Event.Type)
+	assert.Equal(t, "sqlite", events[1].Event.Text)
+	assert.Equal(t, questions, broadcastLog.session.QuestionRelay())
+}
+
+func TestDashboard_Stop(t *testing.T) {
+	tmpDir := t.TempDir()
+	colors := testColors()
+	holder := &status.PhaseHolder{}
+	baseLog, err := progress.NewLogger(progress.Config{Mode: "test", Branch: "main", NoColor: true, Dir: tmpDir}, colors, holder)
+	require.NoError(t, err)
+	defer baseLog.Close()
+
+	assert.NoError(t, NewDashboard(DashboardConfig{}, holder).Stop(), "not started")
+
+	port := freePort(t)
+	d := NewDashboard(DashboardConfig{BaseLog: baseLog, Port: port, Colors: colors}, holder)
+	_, err = d.Start(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, d.Stop())
+
+	// the port is free again for the next dashboard
+	d = NewDashboard(DashboardConfig{BaseLog: baseLog, Port: port, Colors: colors}, holder)
+	_, err = d.Start(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, d.Stop())
+}
+
+func TestDashboard_Metrics(t *testing.T) {
+	tmpDir := t.TempDir()
+	colors := testColors()
+	holder := &status.PhaseHolder{}
+	baseLog, err := progress.NewLogger(progress.Config{Mode: "test", Branch: "main", NoColor: true, Dir: tmpDir}, colors, holder)
+	require.NoError(t, err)
+	defer baseLog.Close()
+
+	d := NewDashboard(DashboardConfig{BaseLog: baseLog, Port: freePort(t), Colors: colors, Metrics: true}, holder)
+	assert.Nil(t, d.Metrics(), "not started")
+	_, err = d.Start(t.Context())
+	require.NoError(t, err)
+	defer d.Stop()
+	require.NotNil(t, d.Metrics())
+	assert.Same(t, d.srv.metrics, d.Metrics())
+
+	disabled := NewDashboard(DashboardConfig{BaseLog: baseLog, Port: freePort(t), Colors: colors}, holder)
+	_, err = disabled.Start(t.Context())
+	require.NoError(t, err)
+	defer disabled.Stop()
+	assert.Nil(t, disabled.Metrics(), "metrics disabled")
+}
+
+// freePort returns a port free at the moment of the call.
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+	return port
+}
+
 func TestDashboard_Start_MultiSession(t *testing.T) {
 	tmpDir := t.TempDir()
 	oldWd, wdErr := os.Getwd()
@@ -160,10 +280,11 @@ func TestSetupWatchMode(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	srvErrCh, watchErrCh, err := setupWatchMode(ctx, 0, []string{tmpDir})
+	srv, srvErrCh, watchErrCh, err := setupWatchMode(ctx, ServerConfig{Port: 0, PlanName: "(watch mode)"}, []string{tmpDir}, WatchOptions{}, 0, 0)
 	require.NoError(t, err)
 	assert.NotNil(t, srvErrCh)
 	assert.NotNil(t, watchErrCh)
+	assert.NotZero(t, srv.Port(), "random port is resolved")
 }
 
 func TestStartServerAsync_Success(t *testing.T) {
@@ -286,5 +407,59 @@ func TestPrintWatchInfo(t *testing.T) {
 	colors := testColors()
 
 	// just verify it doesn't panic
-	printWatchInfo([]string{"/tmp", "/var"}, 8080, colors)
+	printWatchInfo([]string{"/tmp", "/var"}, dashboardURL("", 8080, ""), colors)
+}
+
+func TestDashboardURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		listen string
+		token  string
+		want   string
+	}{
+		{name: "default", want: "http://localhost:8080"},
+		{name: "loopback", listen: "127.0.0.1", want: "http://localhost:8080"},
+		{name: "all interfaces", listen: "0.0.0.0", want: "http://localhost:8080"},
+		{name: "all ipv6 interfaces", listen: "::", want: "http://localhost:8080"},
+		{name: "specific address", listen: "192.168.1.10", want: "http://192.168.1.10:8080"},
+		{name: "ipv6 address", listen: "fd00::1", want: "http://[fd00::1]:8080"},
+		{name: "with token", listen: "10.0.0.1", token: "secret", want: "http://10.0.0.1:8080/?token=secret"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, dashboardURL(tc.listen, 8080, tc.token))
+		})
+	}
+}
+
+func TestNewDashboard_AuthToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		listen        string
+		token         string
+		wantGenerated bool
+		wantURL       string
+	}{
+		{name: "loopback without token", wantURL: "http://localhost:8080"},
+		{name: "localhost without token", listen: "localhost", wantURL: "http://localhost:8080"},
+		{name: "loopback with token", listen: "127.0.0.1", token: "secret", wantURL: "http://localhost:8080/?token=<dashboard token>"},
+		{name: "all interfaces with token", listen: "0.0.0.0", token: "secret", wantURL: "http://localhost:8080/?token=<dashboard token>"},
+		{name: "all interfaces without token", listen: "0.0.0.0", wantGenerated: true},
+		{name: "lan address without token", listen: "192.168.1.10", wantGenerated: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewDashboard(DashboardConfig{Listen: tc.listen, Port: 8080, AuthToken: tc.token, Colors: testColors()}, &status.PhaseHolder{})
+			assert.Equal(t, tc.wantGenerated, d.tokenGenerated)
+			if !tc.wantGenerated {
+				assert.Equal(t, tc.token, d.authToken)
+				assert.Equal(t, tc.wantURL, d.url())
+				return
+			}
+			assert.Len(t, d.authToken, 26)
+			assert.Equal(t, dashboardURL(tc.listen, 8080, d.authToken), d.url(), "generated token is printed")
+			other := NewDashboard(DashboardConfig{Listen: tc.listen, Port: 8080}, &status.PhaseHolder{})
+			assert.NotEqual(t, d.authToken, other.authToken)
+		})
+	}
 }
@@ -3,6 +3,7 @@ package web
 import (
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 // DiffStats holds git diff statistics for a session.
@@ -39,3 +40,58 @@ func parseDiffStats(text string) (DiffStats, bool) {
 		Deletions: deletions,
 	}, true
 }
+
+// PhaseChanges holds the change summary the runner prints at the end of each pipeline phase,
+// e.g. "task phase: 7 commits, 23 files changed (+812/-310)".
+type PhaseChanges struct {
+	Phase         string `json:"phase"`
+	Commits       int    `json:"commits"`
+	Files         int    `json:"files"`
+	Additions     int    `json:"additions"`
+	Deletions     int    `json:"deletions"`
+	HeadUnchanged bool   `json:"headUnchanged,omitempty"` // no commits during the phase
+	Rewritten     bool   `json:"rewritten,omitempty"`     // history rewritten, files and lines are of uncommitted changes
+}
+
+var (
+	phaseCommitsPattern   = regexp.MustCompile(`^([a-z-]+) phase: (\d+) commits?, (\d+) files? changed \(\+(\d+)/-(\d+)\)$`)
+	phaseUnchangedPattern = regexp.MustCompile(`^([a-z-]+) phase: no commits, HEAD unchanged$`)
+	phaseRewrittenPattern = regexp.MustCompile(
+		`^([a-z-]+) phase: history rewritten \(rebase or amend\), (\d+) files? with uncommitted changes \(\+(\d+)/-(\d+)\)$`)
+)
+
+// parsePhaseChanges parses a phase change summary line, see processor.PhaseChanges.Format.
+func parsePhaseChanges(text string) (PhaseChanges, bool) {
+	text = strings.TrimSpace(text)
+	if m := phaseUnchangedPattern.FindStringSubmatch(text); m != nil {
+		return PhaseChanges{Phase: m[1], HeadUnchanged: true}, true
+	}
+	if m := phaseCommitsPattern.FindStringSubmatch(text); m != nil {
+		nums, ok := atois(m[2:])
+		if !ok {
+			return PhaseChanges{}, false
+		}
+		return PhaseChanges{Phase: m[1], Commits: nums[0], Files: nums[1], Additions: nums[2], Deletions: nums[3]}, true
+	}
+	if m := phaseRewrittenPattern.FindStringSubmatch(text); m != nil {
+		nums, ok := atois(m[2:])
+		if !ok {
+			return PhaseChanges{}, false
+		}
+		return PhaseChanges{Phase: m[1], Files: nums[0], Additions: nums[1], Deletions: nums[2], Rewritten: true}, true
+	}
+	return PhaseChanges{}, false
+}
+
+// atois converts decimal strings to ints, false if any of them doesn't fit.
+func atois(strs []string) ([]int, bool) {
+	res := make([]int, 0, len(strs))
+	for _, s := range strs {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, false
+		}
+		res = append(res, n)
+	}
+	return res, true
+}
@@ -38,3 +38,34 @@ func TestParseDiffStats(t *testing.T) {
 		assert.False(t, ok)
 	})
 }
+
+func TestParsePhaseChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want PhaseChanges
+		ok   bool
+	}{
+		{name: "commits", text: "task phase: 7 commits, 23 files changed (+812/-310)",
+			want: PhaseChanges{Phase: "task", Commits: 7, Files: 23, Additions: 812, Deletions: 310}, ok: true},
+		{name: "singular", text: "review phase: 1 commit, 1 file changed (+3/-0)",
+			want: PhaseChanges{Phase: "review", Commits: 1, Files: 1, Additions: 3}, ok: true},
+		{name: "head unchanged", text: "codex phase: no commits, HEAD unchanged",
+			want: PhaseChanges{Phase: "codex", HeadUnchanged: true}, ok: true},
+		{name: "history rewritten",
+			text: "claude-eval phase: history rewritten (rebase or amend), 2 files with uncommitted changes (+5/-1)",
+			want: PhaseChanges{Phase: "claude-eval", Files: 2, Additions: 5, Deletions: 1, Rewritten: true}, ok: true},
+		{name: "surrounding whitespace", text: "  task phase: 0 commits, 0 files changed (+0/-0)\n",
+			want: PhaseChanges{Phase: "task"}, ok: true},
+		{name: "run total is not a phase", text: "run total: 7 commits, 23 files changed (+812/-310)"},
+		{name: "regular line", text: "task phase started"},
+		{name: "empty", text: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := parsePhaseChanges(tc.text)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package web
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// unsafeFilenameRe matches runs of characters not kept in download file names.
+var unsafeFilenameRe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// handleDownload sends the progress file of the session as an attachment.
+// accepts ?session=<id> like /events, without it the single-session mode session is used.
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
+	session, err := s.getSession(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	s.serveProgressFile(w, r, session)
+}
+
+// handleSessionDownload sends the progress file of the session from the path as an attachment.
+func (s *Server) handleSessionDownload(w http.ResponseWriter, r *http.Request) {
+	session := s.sessionByID(r.PathValue("id"))
+	if session == nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	s.serveProgressFile(w, r, session)
+}
+
+// serveProgressFile streams the session's progress file as it is on disk, with range and conditional request support.
+// a progress file removed or rotated away gets 404.
+func (s *Server) serveProgressFile(w http.ResponseWriter, r *http.Request, session *Session) {
+	f, err := os.Open(session.Path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			http.Error(w, "progress file not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[WARN] failed to open progress file %s: %v", session.Path, err)
+		http.Error(w, "unable to read progress file", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil || fi.IsDir() {
+		http.Error(w, "progress file not found", http.StatusNotFound)
+		return
+	}
+
+	// the header of the file names the plan and branch, the session metadata covers a file without one
+	meta, err := ParseProgressHeader(session.Path)
+	if err != nil || meta.PlanPath == "" && meta.Branch == "" {
+		meta = session.GetMetadata()
+	}
+	if meta.PlanPath == "" && meta.Branch == "" && session == s.session {
+		meta.PlanPath, meta.Branch = s.cfg.PlanFile, s.cfg.Branch
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition",
+		mime.FormatMediaType("attachment", map[string]string{"filename": downloadFilename(meta, session.Path)}))
+	http.ServeContent(w, r, "", fi.ModTime(), f)
+}
+
+// downloadFilename returns the attachment name for a progress file: progress-<plan>-<branch>.txt,
+// with the parts that are known, or the progress file's own name if neither is.
+// a placeholder plan like "(no plan - review only)" is skipped.
+func downloadFilename(meta SessionMetadata, path string) string {
+	parts := []string{"progress"}
+	add := func(part string) {
+		if part = strings.Trim(unsafeFilenameRe.ReplaceAllString(part, "-"), "-."); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if !strings.HasPrefix(meta.PlanPath, "(") {
+		add(strings.TrimSuffix(filepath.Base(meta.PlanPath), filepath.Ext(meta.PlanPath)))
+	}
+	add(meta.Branch)
+	if len(parts) == 1 {
+		return filepath.Base(path)
+	}
+	return strings.Join(parts, "-") + ".txt"
+}
@@ -0,0 +1,123 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_HandleDownload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress-add-auth.txt")
+	createProgressFile(t, path, "docs/plans/add-auth.md", "feature/auth", "full")
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	session := NewSession("main", path)
+	defer session.Close()
+	srv, err := NewServer(ServerConfig{}, session)
+	require.NoError(t, err)
+	handler, err := srv.handler()
+	require.NoError(t, err)
+
+	for _, target := range []string{"/download", "/session/main/download"} {
+		t.Run(target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, http.NoBody))
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, want, w.Body.Bytes())
+			assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+			assert.Equal(t, `attachment; filename=progress-add-auth-feature-auth.txt`, w.Header().Get("Content-Disposition"))
+		})
+	}
+
+	t.Run("unknown session", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/session/other/download", http.NoBody))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("removed progress file", func(t *testing.T) {
+		require.NoError(t, os.Remove(path))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/download", http.NoBody))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "progress file not found")
+	})
+}
+
+func TestServer_HandleDownload_MultiSession(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "progress-first.txt"), filepath.Join(dir, "progress-second.txt")
+	createProgressFile(t, first, "docs/plans/first.md", "master", "full")
+	createProgressFile(t, second, "docs/plans/second.md", "feature", "full")
+
+	sm := NewSessionManager()
+	defer sm.Close()
+	sm.Register(NewSession("", first)) // the manager derives the ID from the path
+	sm.Register(NewSession("", second))
+	srv, err := NewServerWithSessions(ServerConfig{}, sm)
+	require.NoError(t, err)
+	handler, err := srv.handler()
+	require.NoError(t, err)
+
+	firstID, secondID := sessionIDFromPath(first), sessionIDFromPath(second)
+
+	tests := []struct {
+		name     string
+		target   string
+		wantCode int
+		wantFile string
+		wantName string
+	}{
+		{name: "by path", target: "/session/" + secondID + "/download", wantCode: http.StatusOK, wantFile: second,
+			wantName: "progress-second-feature.txt"},
+		{name: "by query", target: "/download?session=" + firstID, wantCode: http.StatusOK, wantFile: first,
+			wantName: "progress-first-master.txt"},
+		{name: "api path not served", target: "/api/sessions/" + firstID + "/download", wantCode: http.StatusNotFound},
+		{name: "unknown by path", target: "/session/third/download", wantCode: http.StatusNotFound},
+		{name: "unknown by query", target: "/download?session=third", wantCode: http.StatusNotFound},
+		{name: "no session in multi-session mode", target: "/download", wantCode: http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.target, http.NoBody))
+			require.Equal(t, tc.wantCode, w.Code)
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			want, err := os.ReadFile(tc.wantFile)
+			require.NoError(t, err)
+			assert.Equal(t, want, w.Body.Bytes())
+			assert.Equal(t, "attachment; filename="+tc.wantName, w.Header().Get("Content-Disposition"))
+		})
+	}
+}
+
+func TestDownloadFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		meta SessionMetadata
+		want string
+	}{
+		{name: "plan and branch", meta: SessionMetadata{PlanPath: "docs/plans/add-auth.md", Branch: "feature/auth"},
+			want: "progress-add-auth-feature-auth.txt"},
+		{name: "plan only", meta: SessionMetadata{PlanPath: "docs/plans/add-auth.md"}, want: "progress-add-auth.txt"},
+		{name: "branch only", meta: SessionMetadata{Branch: "main"}, want: "progress-main.txt"},
+		{name: "review only placeholder", meta: SessionMetadata{PlanPath: "(no plan - review only)", Branch: "main"},
+			want: "progress-main.txt"},
+		{name: "unsafe characters", meta: SessionMetadata{PlanPath: "my plan \"v2\".md", Branch: "fix/ütf8;x"},
+			want: "progress-my-plan-v2-fix-tf8-x.txt"},
+		{name: "nothing known", want: "progress-review.txt"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, downloadFilename(tc.meta, "/tmp/.ralphex/progress/progress-review.txt"))
+		})
+	}
+}
@@ -23,6 +23,11 @@ const (
 	EventTypeTaskStart      EventType = "task_start"      // task execution started
 	EventTypeTaskEnd        EventType = "task_end"        // task execution ended
 	EventTypeIterationStart EventType = "iteration_start" // review/codex iteration started
+	EventTypePaused         EventType = "paused"          // run paused from the dashboard
+	EventTypeResumed        EventType = "resumed"         // paused run resumed
+	EventTypeStopping       EventType = "stopping"        // run stops after its current iteration (first Ctrl+C)
+	EventTypeQuestion       EventType = "question"        // plan question waiting for an answer
+	EventTypeQuestionClosed EventType = "question_closed" // plan question answered
 )
 
 // Event represents a single event to be streamed to web clients.
@@ -35,6 +40,9 @@ type Event struct {
 	Signal       string       `json:"signal,omitempty"`
 	TaskNum      int          `json:"task_num,omitempty"`      // 1-based task index from plan (matches plan.tasks[].number)
 	IterationNum int          `json:"iteration_num,omitempty"` // 1-based iteration index for review/codex phases
+	Options      []string     `json:"options,omitempty"`       // answer options of a question event
+	Default      string       `json:"default,omitempty"`       // default answer of a free-form question event
+	Multiline    bool         `json:"multiline,omitempty"`     // question event takes an answer of several lines
 }
 
 // NewOutputEvent creates an output event with current timestamp.
@@ -102,6 +110,32 @@ func NewIterationStartEvent(phase status.Phase, iterationNum int, text string) E
 	}
 }
 
+// NewPauseEvent creates a paused or resumed event.
+func NewPauseEvent(phase status.Phase, paused bool) Event {
+	e := Event{Type: EventTypeResumed, Phase: phase, Text: "resume requested", Timestamp: time.Now()}
+	if paused {
+		e.Type, e.Text = EventTypePaused, "pause requested, the run stops before its next iteration"
+	}
+	return e
+}
+
+// NewStopEvent creates an event for a stop requested after the current iteration.
+func NewStopEvent(phase status.Phase) Event {
+	return Event{Type: EventTypeStopping, Phase: phase, Text: "stop requested, the run exits after its current iteration",
+		Timestamp: time.Now()}
+}
+
+// NewQuestionEvent creates an event for a plan question that can be answered from the dashboard.
+func NewQuestionEvent(phase status.Phase, q Question) Event {
+	return Event{Type: EventTypeQuestion, Phase: phase, Text: q.Text, Options: q.Options, Default: q.Default,
+		Multiline: q.Multiline, Timestamp: time.Now()}
+}
+
+// NewQuestionClosedEvent creates an event for an answered plan question.
+func NewQuestionClosedEvent(phase status.Phase, answer string) Event {
+	return Event{Type: EventTypeQuestionClosed, Phase: phase, Text: answer, Timestamp: time.Now()}
+}
+
 // MarshalJSON implements json.Marshaler for SSE streaming.
 // this allows Event to be used directly with json.Marshal.
 func (e Event) MarshalJSON() ([]byte, error) {
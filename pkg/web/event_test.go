@@ -112,6 +112,56 @@ func TestEventType_Constants(t *testing.T) {
 	assert.Equal(t, EventTypeTaskStart, EventType("task_start"))
 	assert.Equal(t, EventTypeTaskEnd, EventType("task_end"))
 	assert.Equal(t, EventTypeIterationStart, EventType("iteration_start"))
+	assert.Equal(t, EventTypePaused, EventType("paused"))
+	assert.Equal(t, EventTypeResumed, EventType("resumed"))
+	assert.Equal(t, EventTypeStopping, EventType("stopping"))
+	assert.Equal(t, EventTypeQuestion, EventType("question"))
+	assert.Equal(t, EventTypeQuestionClosed, EventType("question_closed"))
+}
+
+func TestNewStopEvent(t *testing.T) {
+	e := NewStopEvent(status.PhaseTask)
+	assert.Equal(t, EventTypeStopping, e.Type)
+	assert.Equal(t, status.PhaseTask, e.Phase)
+	assert.Equal(t, "stop requested, the run exits after its current iteration", e.Text)
+	assert.False(t, e.Timestamp.IsZero())
+}
+
+func TestNewPauseEvent(t *testing.T) {
+	e := NewPauseEvent(status.PhaseReview, true)
+	assert.Equal(t, EventTypePaused, e.Type)
+	assert.Equal(t, status.PhaseReview, e.Phase)
+	assert.Equal(t, "pause requested, the run stops before its next iteration", e.Text)
+
+	e = NewPauseEvent(status.PhaseReview, false)
+	assert.Equal(t, EventTypeResumed, e.Type)
+	assert.Equal(t, "resume requested", e.Text)
+}
+
+func TestNewQuestionEvent(t *testing.T) {
+	e := NewQuestionEvent(status.PhasePlan, Question{Text: "which db?", Options: []string{"postgres", "sqlite"}})
+	assert.Equal(t, EventTypeQuestion, e.Type)
+	assert.Equal(t, status.PhasePlan, e.Phase)
+	assert.Equal(t, "which db?", e.Text)
+	assert.Equal(t, []string{"postgres", "sqlite"}, e.Options)
+	assert.False(t, e.Timestamp.IsZero())
+
+	data, err := json.Marshal(e)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"options":["postgres","sqlite"]`)
+	assert.NotContains(t, string(data), `"default"`)
+	assert.NotContains(t, string(data), `"multiline"`)
+
+	e = NewQuestionEvent(status.PhasePlan, Question{Text: "describe the schema", Default: "none", Multiline: true})
+	assert.Empty(t, e.Options)
+	data, err = json.Marshal(e)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"default":"none","multiline":true`)
+
+	e = NewQuestionClosedEvent(status.PhasePlan, "sqlite")
+	assert.Equal(t, EventTypeQuestionClosed, e.Type)
+	assert.Equal(t, "sqlite", e.Text)
+	assert.Empty(t, e.Options)
 }
 
 func TestNewTaskStartEvent(t *testing.T) {
@@ -0,0 +1,395 @@
+package web
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/progress"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// historyPageSize is the approximate number of bytes of a progress file read into one history page
+const historyPageSize = 1 << 20
+
+// historyTailSize is the number of bytes at the end of a progress file scanned for the footer and FAILED signal
+const historyTailSize = 64 * 1024
+
+// historyLineStartRegex matches the timestamp starting a progress line, history pages end before such a line
+var historyLineStartRegex = regexp.MustCompile(`^\[\d{2}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}\] `)
+
+// historyPhases are the phases accepted as ?phase= of a history page request
+var historyPhases = map[status.Phase]bool{
+	status.PhaseTask: true, status.PhaseReview: true, status.PhaseCodex: true,
+	status.PhaseClaudeEval: true, status.PhasePlan: true, status.PhaseFinalize: true,
+}
+
+// HistoryPage is a chunk of a progress file converted to events, read by Session.ReadHistory.
+// the next page is read from Offset with Phase, until Done.
+type HistoryPage struct {
+	Events []Event      `json:"events"`
+	Offset int64        `json:"offset"` // offset of the next page
+	Size   int64        `json:"size"`   // size of the progress file when the page was read
+	Phase  status.Phase `json:"phase"`  // phase at the end of the page, the next page starts in it
+	Done   bool         `json:"done"`   // the page reaches the end of the file
+}
+
+// HistoryEntry describes a past or running session available for replay via /api/history.
+type HistoryEntry struct {
+	ID           string        `json:"id"`
+	Status       SessionStatus `json:"status"`
+	Dir          string        `json:"dir"`
+	PlanPath     string        `json:"planPath,omitempty"`
+	Branch       string        `json:"branch,omitempty"`
+	Mode         string        `json:"mode,omitempty"`
+	StartTime    time.Time     `json:"startTime"`
+	LastModified time.Time     `json:"lastModified"`
+	Elapsed      string        `json:"elapsed,omitempty"` // run duration from the "Completed:" footer, empty if not finished
+	Tokens       string        `json:"tokens,omitempty"`  // token usage of the run so far, e.g. "1.2k in / 300 out (~$0.01)"
+	Size         int64         `json:"size"`              // progress file size in bytes
+}
+
+// progressEventReader converts progress file lines into events.
+// section headers are held until the next timestamped line so the section gets its start time,
+// plain lines get the timestamp of the last timestamped line.
+type progressEventReader struct {
+	inHeader bool
+	phase    status.Phase
+	pending  string    // section header waiting for the first timestamped event
+	lastTS   time.Time // timestamp of the last timestamped line
+}
+
+// newProgressEventReader makes a reader starting in the given phase.
+// inHeader is true when reading from the start of the file, with its header still ahead.
+func newProgressEventReader(inHeader bool, phase status.Phase) *progressEventReader {
+	if phase == "" {
+		phase = status.PhaseTask
+	}
+	return &progressEventReader{inHeader: inHeader, phase: phase}
+}
+
+// line returns events for a single progress file line, without the trailing newline.
+func (p *progressEventReader) line(line string) []Event {
+	if line == "" {
+		return nil
+	}
+	parsed, inHeader := parseProgressLine(line, p.inHeader)
+	p.inHeader = inHeader
+
+	switch parsed.Type {
+	case ParsedLineSection:
+		events := p.flush()
+		p.phase = parsed.Phase
+		p.pending = parsed.Section
+		return events
+	case ParsedLineTimestamp:
+		p.lastTS = parsed.Timestamp
+		var events []Event
+		if p.pending != "" {
+			events = sectionEvents(p.pending, p.phase, parsed.Timestamp)
+			p.pending = ""
+		}
+		return append(events, Event{
+			Type:      parsed.EventType,
+			Phase:     p.phase,
+			Text:      parsed.Text,
+			Timestamp: parsed.Timestamp,
+			Signal:    parsed.Signal,
+		})
+	case ParsedLinePlain:
+		return []Event{{Type: EventTypeOutput, Phase: p.phase, Text: parsed.Text, Timestamp: p.timestamp()}}
+	default:
+		return nil
+	}
+}
+
+// flush returns events for a pending section without a timestamped line after it.
+func (p *progressEventReader) flush() []Event {
+	if p.pending == "" {
+		return nil
+	}
+	events := sectionEvents(p.pending, p.phase, p.timestamp())
+	p.pending = ""
+	return events
+}
+
+// timestamp returns the timestamp of the last timestamped line, now if there was none yet.
+func (p *progressEventReader) timestamp() time.Time {
+	if p.lastTS.IsZero() {
+		return time.Now()
+	}
+	return p.lastTS
+}
+
+// sectionEvents returns the section event for a section header, preceded by task_start for task iteration sections.
+func sectionEvents(sectionName string, phase status.Phase, ts time.Time) []Event {
+	var events []Event
+	if matches := taskIterationRegex.FindStringSubmatch(sectionName); matches != nil {
+		taskNum, err := strconv.Atoi(matches[1])
+		if err != nil {
+			// log parse error but continue - section will still be emitted
+			log.Printf("[WARN] failed to parse task number from section %q: %v", sectionName, err)
+		} else {
+			events = append(events, Event{Type: EventTypeTaskStart, Phase: phase, TaskNum: taskNum, Text: sectionName, Timestamp: ts})
+		}
+	}
+	return append(events, Event{Type: EventTypeSection, Phase: phase, Section: sectionName, Text: sectionName, Timestamp: ts})
+}
+
+// readHistoryPage reads the progress file from offset, converting about limit bytes of whole lines into events.
+// a page ends past the header, with no section waiting for its first line, right before a timestamped line,
+// so plain lines of the next page get their timestamp from it. phase is the phase the page starts in, as returned with the previous page.
+func readHistoryPage(path string, offset int64, phase status.Phase, limit int64) (HistoryPage, error) {
+	f, err := os.Open(path) //nolint:gosec // path of a discovered session progress file
+	if err != nil {
+		return HistoryPage{}, fmt.Errorf("open progress file: %w", err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return HistoryPage{}, fmt.Errorf("stat progress file: %w", err)
+	}
+
+	page := HistoryPage{Events: []Event{}, Offset: offset, Size: fi.Size(), Phase: phase}
+	if offset >= fi.Size() {
+		page.Offset, page.Done = fi.Size(), true
+		return page, nil
+	}
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return HistoryPage{}, fmt.Errorf("seek progress file: %w", err)
+	}
+
+	reader := newProgressEventReader(offset == 0, phase)
+	br := bufio.NewReader(f)
+	for {
+		if page.Offset-offset >= limit && !reader.inHeader && reader.pending == "" {
+			if next, _ := br.Peek(len("[26-01-22 10:00:01] ")); historyLineStartRegex.Match(next) {
+				break
+			}
+		}
+		line, readErr := br.ReadBytes('\n')
+		page.Offset += int64(len(line))
+		page.Events = append(page.Events, reader.line(string(bytes.TrimRight(line, "\r\n")))...)
+		if errors.Is(readErr, io.EOF) {
+			page.Done = true
+			break
+		}
+		if readErr != nil {
+			return HistoryPage{}, fmt.Errorf("read progress file: %w", readErr)
+		}
+	}
+	if page.Done {
+		page.Events = append(page.Events, reader.flush()...)
+	}
+	page.Phase = reader.phase
+	return page, nil
+}
+
+// ReadHistory re-reads the session's progress file from offset and returns a page of its events.
+// unlike the replay buffer, which keeps the last DefaultReplayerSize events, it covers the whole log;
+// pass the Offset and Phase of the returned page to read the next one until Done.
+func (s *Session) ReadHistory(offset int64, phase status.Phase) (HistoryPage, error) {
+	return readHistoryPage(s.Path, offset, phase, historyPageSize)
+}
+
+// runTail is the end of a run found in its progress file.
+type runTail struct {
+	outcome SessionStatus // empty if the run didn't finish
+	elapsed string        // from the footer, "Completed: 2026-01-22 10:05:00 (5m0s)"
+	tokens  string        // run total of the last "tokens: ..., run total ..." line
+}
+
+// progressTail scans the end of a progress file for the run's outcome, the elapsed time of the footer
+// and the token usage of the run.
+func progressTail(path string) runTail {
+	f, err := os.Open(path) //nolint:gosec // path of a discovered session progress file
+	if err != nil {
+		return runTail{}
+	}
+	defer f.Close()
+	if fi, err := f.Stat(); err == nil && fi.Size() > historyTailSize {
+		if _, err := f.Seek(fi.Size()-historyTailSize, io.SeekStart); err != nil {
+			return runTail{}
+		}
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return runTail{}
+	}
+
+	var res runTail
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if strings.Contains(line, "<<<RALPHEX:") && extractSignalFromText(line) == "FAILED" {
+			res.outcome = SessionStatusFailed
+		}
+		if strings.Contains(line, "] tokens: ") {
+			if _, total, found := strings.Cut(line, ", run total "); found {
+				res.tokens = strings.TrimSpace(total)
+			}
+		}
+		if val, found := strings.CutPrefix(line, progressFooterPrefix); found {
+			if res.outcome == "" {
+				res.outcome = SessionStatusFinished
+			}
+			if i := strings.LastIndex(val, " ("); i >= 0 && strings.HasSuffix(val, ")") {
+				res.elapsed = val[i+2 : len(val)-1]
+			}
+		}
+	}
+	return res
+}
+
+// newHistoryEntry builds the history entry of a progress file, status is the known status of its session.
+// without one, the status is derived from the file lock and the end of the log.
+func newHistoryEntry(id, path string, meta SessionMetadata, st SessionStatus) (HistoryEntry, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return HistoryEntry{}, fmt.Errorf("stat progress file: %w", err)
+	}
+	tail := progressTail(path)
+	if st == "" {
+		st = tail.outcome
+		if active, err := IsActive(path); err == nil && active {
+			st = SessionStatusLive
+		} else if st == "" {
+			st = SessionStatusIdle
+		}
+	}
+	return HistoryEntry{
+		ID:           id,
+		Status:       st,
+		Dir:          extractProjectDir(path),
+		PlanPath:     meta.PlanPath,
+		Branch:       meta.Branch,
+		Mode:         meta.Mode,
+		StartTime:    meta.StartTime,
+		LastModified: fi.ModTime(),
+		Elapsed:      tail.elapsed,
+		Tokens:       tail.tokens,
+		Size:         fi.Size(),
+	}, nil
+}
+
+// historyFiles returns progress files in the history directory, keyed by session ID; rotated backups are skipped.
+func (s *Server) historyFiles() map[string]string {
+	files := map[string]string{}
+	if s.cfg.HistoryDir == "" {
+		return files
+	}
+	matches, err := filepath.Glob(filepath.Join(s.cfg.HistoryDir, "progress-*.txt"))
+	if err != nil {
+		return files
+	}
+	for _, path := range matches {
+		if !progress.IsBackupPath(path) {
+			files[sessionIDFromPath(path)] = path
+		}
+	}
+	return files
+}
+
+// handleHistory returns past and running sessions available for replay, most recent first.
+// in multi-session mode these are the sessions discovered under the watched directories,
+// in single-session mode the progress files of the history directory.
+func (s *Server) handleHistory(w http.ResponseWriter, _ *http.Request) {
+	entries := []HistoryEntry{}
+	if s.sm != nil {
+		now := time.Now()
+		for _, session := range s.sm.All() {
+			if session.IsRemoved() {
+				continue
+			}
+			entry, err := newHistoryEntry(session.ID, session.Path, session.GetMetadata(), session.Status(now))
+			if err != nil {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	} else {
+		for id, path := range s.historyFiles() {
+			meta, err := ParseProgressHeader(path)
+			if err != nil {
+				continue
+			}
+			entry, err := newHistoryEntry(id, path, meta, "")
+			if err != nil {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].LastModified.After(entries[j].LastModified) })
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		log.Printf("[WARN] failed to encode history: %v", err)
+		http.Error(w, "unable to encode history", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// handleHistoryPage returns a page of the full log of a history entry.
+// accepts ?offset= and ?phase= from the previous page, both empty for the first one.
+func (s *Server) handleHistoryPage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var offset int64
+	if val := query.Get("offset"); val != "" {
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid offset parameter", http.StatusBadRequest)
+			return
+		}
+		offset = n
+	}
+	phase := status.Phase(query.Get("phase"))
+	if phase != "" && !historyPhases[phase] {
+		http.Error(w, "invalid phase parameter", http.StatusBadRequest)
+		return
+	}
+
+	id := r.PathValue("id")
+	var page HistoryPage
+	var err error
+	switch session := s.sessionByID(id); {
+	case session != nil:
+		page, err = session.ReadHistory(offset, phase)
+	case s.sm == nil && s.historyFiles()[id] != "":
+		page, err = readHistoryPage(s.historyFiles()[id], offset, phase, historyPageSize)
+	default:
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			http.Error(w, "progress file not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("[WARN] failed to read history of session %s: %v", id, err)
+		http.Error(w, "unable to read progress file", http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		log.Printf("[WARN] failed to encode history page: %v", err)
+		http.Error(w, "unable to encode history page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
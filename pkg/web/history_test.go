@@ -0,0 +1,261 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+const historyLog = `# Ralphex Progress Log
+Plan: docs/plans/add-auth.md
+Branch: feature/auth
+Mode: full
+Started: 2026-01-22 10:00:00
+------------------------------------------------------------
+
+--- Task Iteration 1 ---
+[26-01-22 10:00:01] executing task
+plain continuation
+--- Claude Review ---
+[26-01-22 10:01:00] reviewing
+[26-01-22 10:02:00] <<<RALPHEX:REVIEW_DONE>>>
+------------------------------------------------------------
+Completed: 2026-01-22 10:05:00 (5m0s)
+`
+
+func TestReadHistoryPage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress-add-auth.txt")
+	require.NoError(t, os.WriteFile(path, []byte(historyLog), 0o600))
+
+	t.Run("whole file in one page", func(t *testing.T) {
+		page, err := readHistoryPage(path, 0, "", historyPageSize)
+		require.NoError(t, err)
+		assert.True(t, page.Done)
+		assert.Equal(t, int64(len(historyLog)), page.Offset)
+		assert.Equal(t, int64(len(historyLog)), page.Size)
+		assert.Equal(t, status.PhaseReview, page.Phase)
+
+		var types []string
+		for _, e := range page.Events {
+			types = append(types, string(e.Type)+":"+string(e.Phase))
+		}
+		assert.Equal(t, []string{"task_start:task", "section:task", "output:task", "output:task",
+			"section:review", "output:review", "signal:review", "output:review"}, types)
+		assert.Equal(t, 1, page.Events[0].TaskNum)
+		assert.Equal(t, page.Events[2].Timestamp, page.Events[1].Timestamp, "section starts with its first line")
+		assert.Equal(t, page.Events[2].Timestamp, page.Events[3].Timestamp, "plain line gets the last timestamp")
+	})
+
+	t.Run("small pages cover the same events", func(t *testing.T) {
+		whole, err := readHistoryPage(path, 0, "", historyPageSize)
+		require.NoError(t, err)
+
+		var events []Event
+		var offset int64
+		var phase status.Phase
+		for pages := 0; ; pages++ {
+			require.Less(t, pages, 100, "paging must make progress")
+			page, err := readHistoryPage(path, offset, phase, 10)
+			require.NoError(t, err)
+			events = append(events, page.Events...)
+			offset, phase = page.Offset, page.Phase
+			if page.Done {
+				break
+			}
+		}
+		assert.Equal(t, whole.Events, events)
+	})
+
+	t.Run("offset past the end", func(t *testing.T) {
+		page, err := readHistoryPage(path, 1<<30, status.PhaseCodex, historyPageSize)
+		require.NoError(t, err)
+		assert.True(t, page.Done)
+		assert.Empty(t, page.Events)
+		assert.Equal(t, int64(len(historyLog)), page.Offset)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := readHistoryPage(filepath.Join(t.TempDir(), "progress-missing.txt"), 0, "", historyPageSize)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestSession_ReadHistory_LargeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress-large.txt")
+	var sb strings.Builder
+	sb.WriteString("# Ralphex Progress Log\nPlan: docs/plans/large.md\n" + strings.Repeat("-", 60) + "\n\n")
+	lines := 0
+	for sb.Len() < 11<<20 {
+		if lines%1000 == 0 {
+			fmt.Fprintf(&sb, "--- Task Iteration %d ---\n", lines/1000+1)
+		}
+		fmt.Fprintf(&sb, "[26-01-22 10:00:01] line %d %s\n", lines, strings.Repeat("x", 100))
+		lines++
+	}
+	require.NoError(t, os.WriteFile(path, []byte(sb.String()), 0o600))
+
+	session := NewSession("large", path)
+	defer session.Close()
+
+	var offset int64
+	var phase status.Phase
+	pages, outputs := 0, 0
+	for {
+		page, err := session.ReadHistory(offset, phase)
+		require.NoError(t, err)
+		pages++
+		for _, e := range page.Events {
+			if e.Type == EventTypeOutput {
+				require.Equal(t, fmt.Sprintf("line %d", outputs), strings.Fields(e.Text)[0]+" "+strings.Fields(e.Text)[1])
+				outputs++
+			}
+		}
+		offset, phase = page.Offset, page.Phase
+		if page.Done {
+			break
+		}
+	}
+	assert.Equal(t, lines, outputs, "all lines read, none twice")
+	assert.Greater(t, pages, 10, "read in pages of about %d bytes", historyPageSize)
+	assert.Greater(t, outputs, DefaultReplayerSize, "more than the replay buffer keeps")
+}
+
+func TestProgressTail(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantOutcome SessionStatus
+		wantElapsed string
+		wantTokens  string
+	}{
+		{name: "finished", content: historyLog, wantOutcome: SessionStatusFinished, wantElapsed: "5m0s"},
+		{name: "failed", content: "[26-01-22 10:00:01] <<<RALPHEX:FAILED>>>\nCompleted: 2026-01-22 10:05:00 (1h2m3s)\n",
+			wantOutcome: SessionStatusFailed, wantElapsed: "1h2m3s"},
+		{name: "not finished", content: "[26-01-22 10:00:01] working\n"},
+		{name: "tokens of the last call", content: "[26-01-22 10:00:01] tokens: 1.0k in / 100 out, run total 1.0k in / 100 out\n" +
+			"[26-01-22 10:02:01] tokens: 200 in / 50 out, run total 1.2k in / 150 out (~$0.01)\n" +
+			"Completed: 2026-01-22 10:05:00 (5m0s)\n",
+			wantOutcome: SessionStatusFinished, wantElapsed: "5m0s", wantTokens: "1.2k in / 150 out (~$0.01)"},
+		{name: "tokens not reported", content: "[26-01-22 10:00:01] tokens: n/a, run total n/a\n", wantTokens: "n/a"},
+		{name: "footer beyond a long log", content: strings.Repeat("[26-01-22 10:00:01] working\n", 10000) +
+			"Completed: 2026-01-22 10:05:00 (2m0s)\n", wantOutcome: SessionStatusFinished, wantElapsed: "2m0s"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "progress-tail.txt")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+			tail := progressTail(path)
+			assert.Equal(t, tc.wantOutcome, tail.outcome)
+			assert.Equal(t, tc.wantElapsed, tail.elapsed)
+			assert.Equal(t, tc.wantTokens, tail.tokens)
+		})
+	}
+}
+
+func TestServer_HandleHistory_SingleSession(t *testing.T) {
+	dir := t.TempDir()
+	past := filepath.Join(dir, "progress-add-auth.txt")
+	require.NoError(t, os.WriteFile(past, []byte(historyLog), 0o600))
+	current := filepath.Join(dir, "progress-current.txt")
+	createProgressFile(t, current, "docs/plans/current.md", "master", "full")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "progress-old.txt.1"), []byte(historyLog), 0o600))
+
+	session := NewSession("main", current)
+	defer session.Close()
+	srv, err := NewServer(ServerConfig{HistoryDir: dir}, session)
+	require.NoError(t, err)
+	handler, err := srv.handler()
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/history", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code)
+	var entries []HistoryEntry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+	require.Len(t, entries, 2, "rotated backups are skipped")
+
+	var entry HistoryEntry
+	for _, e := range entries {
+		if e.PlanPath == "docs/plans/add-auth.md" {
+			entry = e
+		}
+	}
+	assert.Equal(t, sessionIDFromPath(past), entry.ID)
+	assert.Equal(t, "feature/auth", entry.Branch)
+	assert.Equal(t, "full", entry.Mode)
+	assert.Equal(t, "5m0s", entry.Elapsed)
+	assert.Equal(t, SessionStatusFinished, entry.Status)
+	assert.Equal(t, int64(len(historyLog)), entry.Size)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantCode   int
+		wantEvents int
+	}{
+		{name: "past run", target: "/api/history/" + entry.ID, wantCode: http.StatusOK, wantEvents: 8},
+		{name: "next page", target: fmt.Sprintf("/api/history/%s?offset=%d&phase=review", entry.ID, len(historyLog)),
+			wantCode: http.StatusOK},
+		{name: "current session", target: "/api/history/main", wantCode: http.StatusOK},
+		{name: "unknown id", target: "/api/history/other", wantCode: http.StatusNotFound},
+		{name: "invalid offset", target: "/api/history/main?offset=-1", wantCode: http.StatusBadRequest},
+		{name: "invalid phase", target: "/api/history/main?phase=bogus", wantCode: http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.target, http.NoBody))
+			require.Equal(t, tc.wantCode, w.Code)
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			var page HistoryPage
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+			assert.True(t, page.Done)
+			assert.Len(t, page.Events, tc.wantEvents)
+		})
+	}
+}
+
+func TestServer_HandleHistory_MultiSession(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "progress-add-auth.txt")
+	require.NoError(t, os.WriteFile(path, []byte(historyLog), 0o600))
+
+	sm := NewSessionManager()
+	defer sm.Close()
+	_, err := sm.Discover(dir)
+	require.NoError(t, err)
+	srv, err := NewServerWithSessions(ServerConfig{}, sm)
+	require.NoError(t, err)
+	handler, err := srv.handler()
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/history", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code)
+	var entries []HistoryEntry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entries))
+	require.Len(t, entries, 1)
+	assert.Equal(t, sessionIDFromPath(path), entries[0].ID)
+	assert.Equal(t, SessionStatusFinished, entries[0].Status)
+	assert.Equal(t, "5m0s", entries[0].Elapsed)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/history/"+entries[0].ID, http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code)
+	var page HistoryPage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.True(t, page.Done)
+	assert.Len(t, page.Events, 8)
+}
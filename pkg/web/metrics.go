@@ -0,0 +1,298 @@
+package web
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// metricsContentType is the prometheus text exposition format served on /metrics.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Metrics collects dashboard and runner counters exposed on /metrics in prometheus text format.
+// one instance is shared by a session manager, its sessions and watcher; all methods are safe for concurrent use
+// and no-ops on a nil receiver, so components work the same without metrics.
+type Metrics struct {
+	eventsBroadcast atomic.Int64
+	watcherErrors   atomic.Int64
+
+	mu            sync.Mutex
+	phaseDuration map[status.Phase]time.Duration
+	runner        map[runnerKey]*runnerStats
+}
+
+// executorCallBuckets are the upper bounds in seconds of the executor call duration histogram.
+var executorCallBuckets = []float64{10, 30, 60, 120, 300, 600, 1800, 3600}
+
+// runnerKey labels runner metrics by phase and execution mode.
+type runnerKey struct {
+	phase status.Phase
+	mode  string
+}
+
+// runnerStats holds runner counters and the executor call duration histogram for one phase and mode.
+type runnerStats struct {
+	iterations    int64
+	failures      int64
+	rateLimitHits int64
+	calls         int64
+	callSeconds   float64
+	bucketCounts  []int64 // per bucket of executorCallBuckets, not cumulative
+}
+
+// sessionGauge is the state of a watched session reported by per-session gauges.
+type sessionGauge struct {
+	id     string
+	phase  status.Phase // phase of the last event, empty if none
+	status SessionStatus
+}
+
+// NewMetrics creates an empty metrics collector.
+func NewMetrics() *Metrics {
+	return &Metrics{phaseDuration: make(map[status.Phase]time.Duration), runner: make(map[runnerKey]*runnerStats)}
+}
+
+// EventBroadcast counts an event published to session clients.
+func (m *Metrics) EventBroadcast() {
+	if m == nil {
+		return
+	}
+	m.eventsBroadcast.Add(1)
+}
+
+// WatcherError counts a file watcher error.
+func (m *Metrics) WatcherError() {
+	if m == nil {
+		return
+	}
+	m.watcherErrors.Add(1)
+}
+
+// AddPhaseDuration adds time spent in the given phase.
+func (m *Metrics) AddPhaseDuration(phase status.Phase, d time.Duration) {
+	if m == nil || phase == "" || d <= 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.phaseDuration[phase] += d
+}
+
+// Iteration counts a runner iteration started in the given phase and mode.
+func (m *Metrics) Iteration(phase status.Phase, mode string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runnerStats(phase, mode).iterations++
+}
+
+// ExecutorCall records the duration of an executor call made by the runner, and counts it as a failure if failed.
+func (m *Metrics) ExecutorCall(phase status.Phase, mode string, d time.Duration, failed bool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.runnerStats(phase, mode)
+	st.calls++
+	st.callSeconds += d.Seconds()
+	if i, _ := slices.BinarySearch(executorCallBuckets, d.Seconds()); i < len(executorCallBuckets) {
+		st.bucketCounts[i]++
+	}
+	if failed {
+		st.failures++
+	}
+}
+
+// RateLimitHit counts an executor error matching a rate limit or other configured error pattern.
+func (m *Metrics) RateLimitHit(phase status.Phase, mode string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runnerStats(phase, mode).rateLimitHits++
+}
+
+// runnerStats returns the stats for the phase and mode, creating them on first use. must be called with mu held.
+func (m *Metrics) runnerStats(phase status.Phase, mode string) *runnerStats {
+	key := runnerKey{phase: phase, mode: mode}
+	st, ok := m.runner[key]
+	if !ok {
+		st = &runnerStats{bucketCounts: make([]int64, len(executorCallBuckets))}
+		m.runner[key] = st
+	}
+	return st
+}
+
+// write renders all metrics in prometheus text format, activeSessions and sessions are sampled by the caller.
+// sessions is nil in single-session mode, where no per-session gauges are reported.
+func (m *Metrics) write(w io.Writer, activeSessions int, sessions []sessionGauge) error {
+	m.mu.Lock()
+	phases := make([]status.Phase, 0, len(m.phaseDuration))
+	for phase := range m.phaseDuration {
+		phases = append(phases, phase)
+	}
+	slices.Sort(phases)
+	durations := make([]time.Duration, len(phases))
+	for i, phase := range phases {
+		durations[i] = m.phaseDuration[phase]
+	}
+	keys := make([]runnerKey, 0, len(m.runner))
+	for key := range m.runner {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b runnerKey) int {
+		if a.phase != b.phase {
+			return strings.Compare(string(a.phase), string(b.phase))
+		}
+		return strings.Compare(a.mode, b.mode)
+	})
+	stats := make([]runnerStats, len(keys))
+	for i, key := range keys {
+		stats[i] = *m.runner[key]
+		stats[i].bucketCounts = slices.Clone(stats[i].bucketCounts)
+	}
+	m.mu.Unlock()
+
+	ew := &errWriter{w: w}
+	ew.printf("# HELP ralphex_sessions_active Number of sessions with a running ralphex process.\n")
+	ew.printf("# TYPE ralphex_sessions_active gauge\n")
+	ew.printf("ralphex_sessions_active %d\n", activeSessions)
+	ew.printf("# HELP ralphex_events_broadcast_total Events published to dashboard clients.\n")
+	ew.printf("# TYPE ralphex_events_broadcast_total counter\n")
+	ew.printf("ralphex_events_broadcast_total %d\n", m.eventsBroadcast.Load())
+	ew.printf("# HELP ralphex_phase_duration_seconds_total Time spent in each execution phase, from event timestamps.\n")
+	ew.printf("# TYPE ralphex_phase_duration_seconds_total counter\n")
+	for i, phase := range phases {
+		ew.printf("ralphex_phase_duration_seconds_total{phase=%q} %g\n", string(phase), durations[i].Seconds())
+	}
+	ew.printf("# HELP ralphex_watcher_errors_total File watcher errors.\n")
+	ew.printf("# TYPE ralphex_watcher_errors_total counter\n")
+	ew.printf("ralphex_watcher_errors_total %d\n", m.watcherErrors.Load())
+	writeRunnerMetrics(ew, keys, stats)
+	writeSessionGauges(ew, sessions)
+	return ew.err
+}
+
+// writeRunnerMetrics renders the runner counters and executor call histogram, stats are in the order of keys.
+func writeRunnerMetrics(ew *errWriter, keys []runnerKey, stats []runnerStats) {
+	if len(keys) == 0 {
+		return
+	}
+	counters := []struct {
+		name, help string
+		value      func(st runnerStats) int64
+	}{
+		{"ralphex_iterations_total", "Runner iterations started.", func(st runnerStats) int64 { return st.iterations }},
+		{"ralphex_executor_failures_total", "Executor calls that returned an error.", func(st runnerStats) int64 { return st.failures }},
+		{"ralphex_rate_limit_hits_total", "Executor errors matching a rate limit or other configured error pattern.",
+			func(st runnerStats) int64 { return st.rateLimitHits }},
+	}
+	for _, c := range counters {
+		ew.printf("# HELP %s %s\n", c.name, c.help)
+		ew.printf("# TYPE %s counter\n", c.name)
+		for i, key := range keys {
+			ew.printf("%s{phase=%q,mode=%q} %d\n", c.name, string(key.phase), key.mode, c.value(stats[i]))
+		}
+	}
+
+	ew.printf("# HELP ralphex_executor_call_duration_seconds Duration of executor calls made by the runner.\n")
+	ew.printf("# TYPE ralphex_executor_call_duration_seconds histogram\n")
+	for i, key := range keys {
+		labels := fmt.Sprintf("phase=%q,mode=%q", string(key.phase), key.mode)
+		var cumulative int64
+		for j, le := range executorCallBuckets {
+			cumulative += stats[i].bucketCounts[j]
+			ew.printf("ralphex_executor_call_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels, le, cumulative)
+		}
+		ew.printf("ralphex_executor_call_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, stats[i].calls)
+		ew.printf("ralphex_executor_call_duration_seconds_sum{%s} %g\n", labels, stats[i].callSeconds)
+		ew.printf("ralphex_executor_call_duration_seconds_count{%s} %d\n", labels, stats[i].calls)
+	}
+}
+
+// writeSessionGauges renders the last phase and the outcome of each watched session.
+func writeSessionGauges(ew *errWriter, sessions []sessionGauge) {
+	if len(sessions) == 0 {
+		return
+	}
+	ew.printf("# HELP ralphex_session_phase Phase of the last event of a watched session.\n")
+	ew.printf("# TYPE ralphex_session_phase gauge\n")
+	for _, s := range sessions {
+		if s.phase != "" {
+			ew.printf("ralphex_session_phase{session=%q,phase=%q} 1\n", s.id, string(s.phase))
+		}
+	}
+	ew.printf("# HELP ralphex_session_finished Whether a watched session stopped with the completion footer.\n")
+	ew.printf("# TYPE ralphex_session_finished gauge\n")
+	for _, s := range sessions {
+		ew.printf("ralphex_session_finished{session=%q} %d\n", s.id, boolGauge(s.status == SessionStatusFinished))
+	}
+	ew.printf("# HELP ralphex_session_failed Whether a watched session stopped with the FAILED signal.\n")
+	ew.printf("# TYPE ralphex_session_failed gauge\n")
+	for _, s := range sessions {
+		ew.printf("ralphex_session_failed{session=%q} %d\n", s.id, boolGauge(s.status == SessionStatusFailed))
+	}
+}
+
+// boolGauge returns 1 for true and 0 for false.
+func boolGauge(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// errWriter keeps the first write error so a sequence of writes can be checked once.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) printf(format string, args ...any) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = fmt.Fprintf(e.w, format, args...)
+}
+
+// handleMetrics serves collected metrics in prometheus text format.
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	active := 0
+	var sessions []sessionGauge
+	if s.sm != nil {
+		now := time.Now()
+		for _, session := range s.sm.All() {
+			if session.GetState() == SessionStateActive {
+				active++
+			}
+			gauge := sessionGauge{id: session.ID, status: session.Status(now)}
+			if last, ok := session.LastEvent(); ok {
+				gauge.phase = last.Event.Phase
+			}
+			sessions = append(sessions, gauge)
+		}
+		slices.SortFunc(sessions, func(a, b sessionGauge) int { return strings.Compare(a.id, b.id) })
+	} else if s.session != nil {
+		s.refreshSingleSession()
+		if s.session.GetState() == SessionStateActive {
+			active = 1
+		}
+	}
+
+	w.Header().Set("Content-Type", metricsContentType)
+	if err := s.metrics.write(w, active, sessions); err != nil {
+		log.Printf("[WARN] failed to write metrics: %v", err)
+	}
+}
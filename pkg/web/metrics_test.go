@@ -0,0 +1,204 @@
+package web
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/progress"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestMetrics_Write(t *testing.T) {
+	m := NewMetrics()
+	m.EventBroadcast()
+	m.EventBroadcast()
+	m.WatcherError()
+	m.AddPhaseDuration(status.PhaseTask, 90*time.Second)
+	m.AddPhaseDuration(status.PhaseTask, 30*time.Second)
+	m.AddPhaseDuration(status.PhaseReview, 1500*time.Millisecond)
+	m.AddPhaseDuration("", time.Second)                 // ignored, no phase
+	m.AddPhaseDuration(status.PhaseCodex, -time.Second) // ignored, out of order timestamps
+
+	var buf bytes.Buffer
+	require.NoError(t, m.write(&buf, 3, nil))
+	out := buf.String()
+
+	assert.Contains(t, out, "# TYPE ralphex_sessions_active gauge\nralphex_sessions_active 3\n")
+	assert.Contains(t, out, "# TYPE ralphex_events_broadcast_total counter\nralphex_events_broadcast_total 2\n")
+	assert.Contains(t, out, `ralphex_phase_duration_seconds_total{phase="review"} 1.5`+"\n")
+	assert.Contains(t, out, `ralphex_phase_duration_seconds_total{phase="task"} 120`+"\n")
+	assert.NotContains(t, out, `phase="codex"`)
+	assert.NotContains(t, out, `phase=""`)
+	assert.Contains(t, out, "ralphex_watcher_errors_total 1\n")
+}
+
+func TestMetrics_WriteRunner(t *testing.T) {
+	m := NewMetrics()
+	for range 7 {
+		m.Iteration(status.PhaseTask, "full")
+	}
+	m.Iteration(status.PhaseReview, "full")
+	m.ExecutorCall(status.PhaseTask, "full", 5*time.Second, false)
+	m.ExecutorCall(status.PhaseTask, "full", 30*time.Second, true)
+	m.ExecutorCall(status.PhaseTask, "full", 2*time.Hour, false)
+	m.RateLimitHit(status.PhaseTask, "full")
+
+	var buf bytes.Buffer
+	require.NoError(t, m.write(&buf, 1, nil))
+	out := buf.String()
+
+	assert.Contains(t, out, "# TYPE ralphex_iterations_total counter\n")
+	assert.Contains(t, out, `ralphex_iterations_total{phase="task",mode="full"} 7`+"\n")
+	assert.Contains(t, out, `ralphex_iterations_total{phase="review",mode="full"} 1`+"\n")
+	assert.Contains(t, out, `ralphex_executor_failures_total{phase="task",mode="full"} 1`+"\n")
+	assert.Contains(t, out, `ralphex_executor_failures_total{phase="review",mode="full"} 0`+"\n")
+	assert.Contains(t, out, `ralphex_rate_limit_hits_total{phase="task",mode="full"} 1`+"\n")
+	assert.Contains(t, out, "# TYPE ralphex_executor_call_duration_seconds histogram\n")
+	assert.Contains(t, out, `ralphex_executor_call_duration_seconds_bucket{phase="task",mode="full",le="10"} 1`+"\n")
+	assert.Contains(t, out, `ralphex_executor_call_duration_seconds_bucket{phase="task",mode="full",le="30"} 2`+"\n")
+	assert.Contains(t, out, `ralphex_executor_call_duration_seconds_bucket{phase="task",mode="full",le="3600"} 2`+"\n")
+	assert.Contains(t, out, `ralphex_executor_call_duration_seconds_bucket{phase="task",mode="full",le="+Inf"} 3`+"\n")
+	assert.Contains(t, out, `ralphex_executor_call_duration_seconds_sum{phase="task",mode="full"} 7235`+"\n")
+	assert.Contains(t, out, `ralphex_executor_call_duration_seconds_count{phase="task",mode="full"} 3`+"\n")
+	assert.Contains(t, out, `ralphex_executor_call_duration_seconds_count{phase="review",mode="full"} 0`+"\n")
+	assert.NotContains(t, out, "ralphex_session_", "no per-session gauges in single-session mode")
+
+	t.Run("no runner metrics", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, NewMetrics().write(&buf, 0, nil))
+		assert.NotContains(t, buf.String(), "ralphex_iterations_total")
+		assert.NotContains(t, buf.String(), "ralphex_executor_call_duration_seconds")
+	})
+}
+
+func TestMetrics_WriteSessionGauges(t *testing.T) {
+	sessions := []sessionGauge{
+		{id: "a", phase: status.PhaseTask, status: SessionStatusLive},
+		{id: "b", phase: status.PhaseReview, status: SessionStatusFinished},
+		{id: "c", status: SessionStatusFailed},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, NewMetrics().write(&buf, 1, sessions))
+	out := buf.String()
+
+	assert.Contains(t, out, "# TYPE ralphex_session_phase gauge\n")
+	assert.Contains(t, out, `ralphex_session_phase{session="a",phase="task"} 1`+"\n")
+	assert.Contains(t, out, `ralphex_session_phase{session="b",phase="review"} 1`+"\n")
+	assert.NotContains(t, out, `ralphex_session_phase{session="c"`)
+	assert.Contains(t, out, `ralphex_session_finished{session="a"} 0`+"\n")
+	assert.Contains(t, out, `ralphex_session_finished{session="b"} 1`+"\n")
+	assert.Contains(t, out, `ralphex_session_failed{session="b"} 0`+"\n")
+	assert.Contains(t, out, `ralphex_session_failed{session="c"} 1`+"\n")
+}
+
+func TestMetrics_NilReceiver(t *testing.T) {
+	var m *Metrics
+	assert.NotPanics(t, func() {
+		m.EventBroadcast()
+		m.WatcherError()
+		m.AddPhaseDuration(status.PhaseTask, time.Second)
+		m.Iteration(status.PhaseTask, "full")
+		m.ExecutorCall(status.PhaseTask, "full", time.Second, true)
+		m.RateLimitHit(status.PhaseTask, "full")
+	})
+}
+
+func TestSession_PublishMetrics(t *testing.T) {
+	session := NewSession("test", "/tmp/test.txt")
+	defer session.Close()
+	m := NewMetrics()
+	session.SetMetrics(m)
+
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Type: EventTypeOutput, Phase: status.PhaseTask, Text: "a", Timestamp: start},
+		{Type: EventTypeOutput, Phase: status.PhaseTask, Text: "b", Timestamp: start.Add(10 * time.Second)},
+		{Type: EventTypeSection, Phase: status.PhaseReview, Text: "review", Timestamp: start.Add(25 * time.Second)},
+		{Type: EventTypeOutput, Phase: status.PhaseReview, Text: "c", Timestamp: start.Add(30 * time.Second)},
+	}
+	for _, e := range events {
+		require.NoError(t, session.Publish(e))
+	}
+
+	assert.Equal(t, int64(4), m.eventsBroadcast.Load())
+	assert.Equal(t, 25*time.Second, m.phaseDuration[status.PhaseTask])
+	assert.Equal(t, 5*time.Second, m.phaseDuration[status.PhaseReview])
+}
+
+func TestServer_HandleMetrics(t *testing.T) {
+	t.Run("multi-session mode", func(t *testing.T) {
+		dir := t.TempDir()
+		logger, err := progress.NewLogger(progress.Config{PlanFile: "plan.md", Mode: "full", Branch: "main", Dir: dir},
+			testColors(), &status.PhaseHolder{})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = logger.Close() })
+
+		sm := NewSessionManager()
+		defer sm.Close()
+		_, err = sm.Discover(dir)
+		require.NoError(t, err)
+		session := sm.Get(sessionIDFromPath(logger.Path()))
+		require.NotNil(t, session)
+		require.NoError(t, session.Publish(NewOutputEvent(status.PhaseTask, "hello")))
+		sm.Metrics().WatcherError()
+
+		srv, err := NewServerWithSessions(ServerConfig{MetricsEnabled: true}, sm)
+		require.NoError(t, err)
+		handler, err := srv.handler()
+		require.NoError(t, err)
+		ts := httptest.NewServer(handler)
+		defer ts.Close()
+
+		resp, err := http.Get(ts.URL + "/metrics")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, metricsContentType, resp.Header.Get("Content-Type"))
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		id := sessionIDFromPath(logger.Path())
+		for _, name := range []string{"ralphex_sessions_active 1", "ralphex_events_broadcast_total",
+			"ralphex_phase_duration_seconds_total", "ralphex_watcher_errors_total 1",
+			`ralphex_session_phase{session="` + id + `",phase="task"} 1`, `ralphex_session_finished{session="` + id + `"} 0`} {
+			assert.Contains(t, string(body), name)
+		}
+	})
+
+	t.Run("single-session mode", func(t *testing.T) {
+		session := NewSession("main", filepath.Join(t.TempDir(), "progress-test.txt"))
+		defer session.Close()
+		srv, err := NewServer(ServerConfig{MetricsEnabled: true}, session)
+		require.NoError(t, err)
+		require.NoError(t, session.Publish(NewOutputEvent(status.PhaseTask, "hello")))
+
+		handler, err := srv.handler()
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "ralphex_sessions_active 0\n")
+		assert.Contains(t, w.Body.String(), "ralphex_events_broadcast_total 1\n")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		session := NewSession("main", "/tmp/test.txt")
+		defer session.Close()
+		srv, err := NewServer(ServerConfig{}, session)
+		require.NoError(t, err)
+
+		handler, err := srv.handler()
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,44 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// browserCommand returns the command opening the url in the default browser on the given OS.
+func browserCommand(goos, url string) (name string, args []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{url}
+	case "windows":
+		// the empty argument is the window title, start treats the first quoted argument as one
+		return "cmd", []string{"/c", "start", "", url}
+	default:
+		return "xdg-open", []string{url}
+	}
+}
+
+// startBrowser runs the opener command without waiting for the browser to exit.
+func startBrowser(name string, args ...string) error {
+	cmd := exec.CommandContext(context.Background(), name, args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	go func() { _ = cmd.Wait() }() // reap the opener, its exit status doesn't tell whether the page opened
+	return nil
+}
+
+// openBrowser opens the dashboard in the browser when requested. best-effort, a failure is a warning.
+// the url carries the auth token, the browser needs it even when the printed one hides it.
+func (d *Dashboard) openBrowser() {
+	if !d.open {
+		return
+	}
+	url := dashboardURL(d.listen, d.port, d.authToken)
+	name, args := browserCommand(d.goos, url)
+	d.colors.Info().Printf("opening web dashboard in the browser\n")
+	if err := d.startBrowser(name, args...); err != nil {
+		d.colors.Warn().Printf("warning: failed to open browser: %v\n", err)
+	}
+}
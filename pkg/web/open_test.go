@@ -0,0 +1,101 @@
+package web
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/progress"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestBrowserCommand(t *testing.T) {
+	tests := []struct {
+		goos     string
+		wantName string
+		wantArgs []string
+	}{
+		{goos: "darwin", wantName: "open", wantArgs: []string{"http://localhost:8080"}},
+		{goos: "linux", wantName: "xdg-open", wantArgs: []string{"http://localhost:8080"}},
+		{goos: "freebsd", wantName: "xdg-open", wantArgs: []string{"http://localhost:8080"}},
+		{goos: "windows", wantName: "cmd", wantArgs: []string{"/c", "start", "", "http://localhost:8080"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.goos, func(t *testing.T) {
+			name, args := browserCommand(tc.goos, "http://localhost:8080")
+			assert.Equal(t, tc.wantName, name)
+			assert.Equal(t, tc.wantArgs, args)
+		})
+	}
+}
+
+func TestDashboard_Start_OpensBrowser(t *testing.T) {
+	colors := testColors()
+	holder := &status.PhaseHolder{}
+	baseLog, err := progress.NewLogger(progress.Config{Mode: "test", Branch: "main", NoColor: true, Dir: t.TempDir()},
+		colors, holder)
+	require.NoError(t, err)
+	defer baseLog.Close()
+
+	type call struct {
+		name string
+		args []string
+	}
+	start := func(t *testing.T, cfg DashboardConfig, openErr error) []call {
+		t.Helper()
+		var calls []call
+		d := NewDashboard(cfg, holder)
+		d.goos = "linux"
+		d.startBrowser = func(name string, args ...string) error {
+			calls = append(calls, call{name: name, args: args})
+			return openErr
+		}
+		_, err := d.Start(t.Context())
+		require.NoError(t, err, "opening the browser is best-effort")
+		require.NoError(t, d.Stop())
+		return calls
+	}
+
+	t.Run("opens the dashboard url", func(t *testing.T) {
+		port := freePort(t)
+		calls := start(t, DashboardConfig{BaseLog: baseLog, Port: port, Colors: colors, Open: true}, nil)
+		require.Len(t, calls, 1)
+		assert.Equal(t, "xdg-open", calls[0].name)
+		assert.Equal(t, []string{"http://localhost:" + strconv.Itoa(port)}, calls[0].args)
+	})
+
+	t.Run("url includes the auth token", func(t *testing.T) {
+		port := freePort(t)
+		calls := start(t, DashboardConfig{BaseLog: baseLog, Port: port, Colors: colors, Open: true, AuthToken: "secret"}, nil)
+		require.Len(t, calls, 1)
+		assert.Equal(t, []string{"http://localhost:" + strconv.Itoa(port) + "/?token=secret"}, calls[0].args)
+	})
+
+	t.Run("opener failure is not fatal", func(t *testing.T) {
+		calls := start(t, DashboardConfig{BaseLog: baseLog, Port: freePort(t), Colors: colors, Open: true},
+			errors.New("xdg-open: not found"))
+		assert.Len(t, calls, 1)
+	})
+
+	t.Run("opens the fallback port", func(t *testing.T) {
+		busy, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer busy.Close()
+		port := busy.Addr().(*net.TCPAddr).Port
+
+		calls := start(t, DashboardConfig{BaseLog: baseLog, Port: port, PortAuto: true, Colors: colors, Open: true}, nil)
+		require.Len(t, calls, 1)
+		require.Len(t, calls[0].args, 1)
+		assert.NotEqual(t, "http://localhost:"+strconv.Itoa(port), calls[0].args[0])
+		assert.Contains(t, calls[0].args[0], "http://localhost:")
+	})
+
+	t.Run("not opened without the option", func(t *testing.T) {
+		calls := start(t, DashboardConfig{BaseLog: baseLog, Port: freePort(t), Colors: colors}, nil)
+		assert.Empty(t, calls)
+	})
+}
@@ -0,0 +1,147 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrNoQuestion is returned by QuestionRelay.Answer when no question waits for an answer.
+var ErrNoQuestion = errors.New("no question waiting for an answer")
+
+//go:generate moq -out mocks/collector.go -pkg mocks -skip-ensure -fmt goimports . Collector
+
+// Collector asks plan creation questions, implemented by the terminal and answers-file collectors.
+type Collector interface {
+	AskQuestion(ctx context.Context, question string, options []string) (string, error)
+	AskText(ctx context.Context, question, defaultAnswer string, multiline bool) (string, error)
+	AskDraftReview(ctx context.Context, question, planContent string) (action, feedback string, err error)
+}
+
+// Question is a plan question waiting for an answer.
+type Question struct {
+	Text      string
+	Options   []string // answer options, empty for a free-form question
+	Default   string   // answer of a free-form question left empty
+	Multiline bool     // free-form answer of several lines
+}
+
+// QuestionRelay wraps a Collector so plan questions can also be answered from the dashboard.
+// a question goes to the inner collector and waits for a dashboard answer at the same time,
+// the first answer wins and the inner collector's context is canceled. draft reviews go to the inner collector only.
+type QuestionRelay struct {
+	inner Collector
+
+	mu       sync.Mutex
+	answerCh chan string // set while a question waits for an answer
+	onAsk    []func(q Question)
+	onClose  []func(answer string)
+}
+
+// NewQuestionRelay creates a relay asking the inner collector and the dashboard.
+func NewQuestionRelay(inner Collector) *QuestionRelay {
+	return &QuestionRelay{inner: inner}
+}
+
+// OnAsk registers a callback that fires when a question starts waiting for an answer,
+// before the inner collector is asked.
+func (q *QuestionRelay) OnAsk(fn func(q Question)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onAsk = append(q.onAsk, fn)
+}
+
+// OnClose registers a callback that fires when a question is answered, the answer is empty if asking failed.
+// it fires once for each asked question, whether the terminal or the dashboard answered it.
+func (q *QuestionRelay) OnClose(fn func(answer string)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onClose = append(q.onClose, fn)
+}
+
+// AskQuestion asks the inner collector and waits for its answer or one passed to Answer, whichever comes first.
+// an error of the inner collector is returned as is.
+func (q *QuestionRelay) AskQuestion(ctx context.Context, question string, options []string) (string, error) {
+	return q.ask(ctx, Question{Text: question, Options: options}, func(innerCtx context.Context) (string, error) {
+		return q.inner.AskQuestion(innerCtx, question, options) //nolint:wrapcheck // returned as is
+	})
+}
+
+// AskText asks the inner collector for a free-form answer and waits for it or one passed to Answer,
+// whichever comes first. an error of the inner collector is returned as is.
+func (q *QuestionRelay) AskText(ctx context.Context, question, defaultAnswer string, multiline bool) (string, error) {
+	return q.ask(ctx, Question{Text: question, Default: defaultAnswer, Multiline: multiline},
+		func(innerCtx context.Context) (string, error) {
+			return q.inner.AskText(innerCtx, question, defaultAnswer, multiline) //nolint:wrapcheck // returned as is
+		})
+}
+
+// ask runs innerAsk and waits for its answer or one passed to Answer, whichever comes first.
+func (q *QuestionRelay) ask(ctx context.Context, question Question,
+	innerAsk func(ctx context.Context) (string, error)) (answer string, err error) {
+	answerCh := make(chan string, 1)
+	q.mu.Lock()
+	q.answerCh = answerCh
+	onAsk, onClose := q.onAsk, q.onClose
+	q.mu.Unlock()
+
+	for _, fn := range onAsk {
+		fn(question)
+	}
+	defer func() {
+		q.mu.Lock()
+		q.answerCh = nil
+		q.mu.Unlock()
+		for _, fn := range onClose {
+			fn(answer)
+		}
+	}()
+
+	type result struct {
+		answer string
+		err    error
+	}
+	innerCtx, cancel := context.WithCancel(ctx)
+	defer cancel() // stops the terminal prompt once answered from the dashboard
+	innerCh := make(chan result, 1)
+	go func() {
+		a, askErr := innerAsk(innerCtx)
+		innerCh <- result{answer: a, err: askErr}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", fmt.Errorf("ask question: %w", ctx.Err())
+	case answer = <-answerCh:
+		return answer, nil
+	case res := <-innerCh:
+		return res.answer, res.err
+	}
+}
+
+// AskDraftReview passes the draft review to the inner collector.
+func (q *QuestionRelay) AskDraftReview(ctx context.Context, question, planContent string) (action, feedback string, err error) {
+	return q.inner.AskDraftReview(ctx, question, planContent) //nolint:wrapcheck // pass through collector errors as-is
+}
+
+// Answer answers the waiting question, an option text or a free-text answer.
+// returns ErrNoQuestion if no question waits or it was already answered.
+func (q *QuestionRelay) Answer(answer string) error {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return errors.New("answer is empty")
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.answerCh == nil {
+		return ErrNoQuestion
+	}
+	select {
+	case q.answerCh <- answer:
+		return nil
+	default:
+		return ErrNoQuestion
+	}
+}
@@ -0,0 +1,174 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/web/mocks"
+)
+
+func TestQuestionRelay_AskQuestion(t *testing.T) {
+	t.Run("answered from the dashboard", func(t *testing.T) {
+		innerCanceled := make(chan struct{})
+		inner := &mocks.CollectorMock{
+			AskQuestionFunc: func(ctx context.Context, _ string, _ []string) (string, error) {
+				<-ctx.Done() // terminal prompt waits until canceled
+				close(innerCanceled)
+				return "", ctx.Err()
+			},
+		}
+		q := NewQuestionRelay(inner)
+		var asked []string
+		var closed []string
+		q.OnAsk(func(question Question) {
+			asked = append(asked, question.Text)
+			assert.Equal(t, []string{"postgres", "sqlite"}, question.Options)
+			go func() { assert.NoError(t, q.Answer(" sqlite ")) }()
+		})
+		q.OnClose(func(answer string) { closed = append(closed, answer) })
+
+		answer, err := q.AskQuestion(t.Context(), "which db?", []string{"postgres", "sqlite"})
+		require.NoError(t, err)
+		assert.Equal(t, "sqlite", answer)
+		assert.Equal(t, []string{"which db?"}, asked)
+		assert.Equal(t, []string{"sqlite"}, closed)
+		select {
+		case <-innerCanceled:
+		case <-time.After(time.Second):
+			t.Fatal("terminal prompt was not canceled")
+		}
+		assert.ErrorIs(t, q.Answer("postgres"), ErrNoQuestion, "question is closed")
+	})
+
+	t.Run("answered in the terminal", func(t *testing.T) {
+		inner := &mocks.CollectorMock{
+			AskQuestionFunc: func(context.Context, string, []string) (string, error) { return "postgres", nil },
+		}
+		q := NewQuestionRelay(inner)
+		var closed []string
+		q.OnClose(func(answer string) { closed = append(closed, answer) })
+
+		answer, err := q.AskQuestion(t.Context(), "which db?", []string{"postgres", "sqlite"})
+		require.NoError(t, err)
+		assert.Equal(t, "postgres", answer)
+		assert.Equal(t, []string{"postgres"}, closed)
+		assert.ErrorIs(t, q.Answer("sqlite"), ErrNoQuestion)
+	})
+
+	t.Run("terminal error", func(t *testing.T) {
+		inner := &mocks.CollectorMock{
+			AskQuestionFunc: func(context.Context, string, []string) (string, error) { return "", errors.New("selection canceled") },
+		}
+		q := NewQuestionRelay(inner)
+		var closed []string
+		q.OnClose(func(answer string) { closed = append(closed, answer) })
+
+		_, err := q.AskQuestion(t.Context(), "which db?", []string{"postgres"})
+		require.EqualError(t, err, "selection canceled")
+		assert.Equal(t, []string{""}, closed, "question closed without an answer")
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		inner := &mocks.CollectorMock{
+			AskQuestionFunc: func(ctx context.Context, _ string, _ []string) (string, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+		}
+		q := NewQuestionRelay(inner)
+		ctx, cancel := context.WithCancel(t.Context())
+		q.OnAsk(func(Question) { cancel() })
+
+		_, err := q.AskQuestion(ctx, "which db?", []string{"postgres"})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestQuestionRelay_AskText(t *testing.T) {
+	t.Run("answered from the dashboard", func(t *testing.T) {
+		inner := &mocks.CollectorMock{
+			AskTextFunc: func(ctx context.Context, _, _ string, _ bool) (string, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+		}
+		q := NewQuestionRelay(inner)
+		var asked []Question
+		q.OnAsk(func(question Question) {
+			asked = append(asked, question)
+			go func() { assert.NoError(t, q.Answer("users table\nposts table\n")) }()
+		})
+
+		answer, err := q.AskText(t.Context(), "describe the schema", "none", true)
+		require.NoError(t, err)
+		assert.Equal(t, "users table\nposts table", answer)
+		assert.Equal(t, []Question{{Text: "describe the schema", Default: "none", Multiline: true}}, asked)
+	})
+
+	t.Run("answered in the terminal", func(t *testing.T) {
+		inner := &mocks.CollectorMock{
+			AskTextFunc: func(_ context.Context, question, defaultAnswer string, multiline bool) (string, error) {
+				assert.Equal(t, "endpoint path?", question)
+				assert.Equal(t, "/api/users", defaultAnswer)
+				assert.False(t, multiline)
+				return "/api/users", nil
+			},
+		}
+		q := NewQuestionRelay(inner)
+		var closed []string
+		q.OnClose(func(answer string) { closed = append(closed, answer) })
+
+		answer, err := q.AskText(t.Context(), "endpoint path?", "/api/users", false)
+		require.NoError(t, err)
+		assert.Equal(t, "/api/users", answer)
+		assert.Equal(t, []string{"/api/users"}, closed)
+	})
+}
+
+func TestQuestionRelay_Answer(t *testing.T) {
+	q := NewQuestionRelay(&mocks.CollectorMock{})
+	require.ErrorIs(t, q.Answer("postgres"), ErrNoQuestion)
+
+	block := make(chan struct{})
+	defer close(block)
+	q = NewQuestionRelay(&mocks.CollectorMock{
+		AskQuestionFunc: func(ctx context.Context, _ string, _ []string) (string, error) {
+			select {
+			case <-ctx.Done():
+			case <-block:
+			}
+			return "", errors.New("no terminal answer")
+		},
+	})
+	results := make(chan error, 3)
+	q.OnAsk(func(Question) {
+		results <- q.Answer("  ")
+		results <- q.Answer("postgres")
+		results <- q.Answer("sqlite") // second answer to the same question
+	})
+	answer, err := q.AskQuestion(t.Context(), "which db?", []string{"postgres", "sqlite"})
+	require.NoError(t, err)
+	assert.Equal(t, "postgres", answer)
+	require.EqualError(t, <-results, "answer is empty")
+	require.NoError(t, <-results)
+	require.ErrorIs(t, <-results, ErrNoQuestion)
+}
+
+func TestQuestionRelay_AskDraftReview(t *testing.T) {
+	inner := &mocks.CollectorMock{
+		AskDraftReviewFunc: func(_ context.Context, question, planContent string) (string, string, error) {
+			assert.Equal(t, "review the plan", question)
+			assert.Equal(t, "# Plan", planContent)
+			return "revise", "add tests", nil
+		},
+	}
+	action, feedback, err := NewQuestionRelay(inner).AskDraftReview(t.Context(), "review the plan", "# Plan")
+	require.NoError(t, err)
+	assert.Equal(t, "revise", action)
+	assert.Equal(t, "add tests", feedback)
+}
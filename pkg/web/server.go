@@ -9,22 +9,40 @@ import (
 	"html/template"
 	"io/fs"
 	"log"
+	"net"
 	"net/http"
+	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
+
+	plans "github.com/umputun/ralphex/pkg/plan"
+	"github.com/umputun/ralphex/pkg/status"
 )
 
 //go:embed templates static
 var embeddedFS embed.FS
 
+// portAutoRange is the number of ports tried from the configured one with ServerConfig.PortAuto.
+const portAutoRange = 20
+
 // ServerConfig holds configuration for the web server.
 type ServerConfig struct {
+	Listen   string // address to listen on, empty for 127.0.0.1
 	Port     int    // port to listen on
+	PortAuto bool   // when Port is in use, listen on the next free one, up to portAutoRange ports
 	PlanName string // plan name to display in dashboard
 	Branch   string // git branch name
 	PlanFile string // path to plan file for /api/plan endpoint
+
+	PlanArchive plans.Archive // where a plan file missing on disk is looked up after it was moved as completed
+	HistoryDir  string        // directory with past progress files listed by /api/history in single-session mode
+
+	MetricsEnabled  bool   // serve prometheus metrics on /metrics
+	AuthToken       string // token required on all routes, empty disables auth
+	EnableWebSocket bool   // serve /ws, the dashboard streams over it and sends control commands back
 }
 
 // Server provides HTTP server for the real-time dashboard.
@@ -32,9 +50,13 @@ type Server struct {
 	cfg     ServerConfig
 	session *Session        // used for single-session mode (direct execution)
 	sm      *SessionManager // used for multi-session mode (dashboard)
-	srv     *http.Server
+	metrics *Metrics        // shared with the session (single-session) or the session manager
 	tmpl    *template.Template
 
+	srvMu sync.Mutex // guards srv and ln, Start runs in its own goroutine
+	srv   *http.Server
+	ln    net.Listener // set by Listen, nil once the server stops
+
 	// plan caching - set after first successful load (single-session mode)
 	planMu    sync.Mutex
 	planCache *Plan
@@ -48,9 +70,15 @@ func NewServer(cfg ServerConfig, session *Session) (*Server, error) {
 		return nil, fmt.Errorf("parse template: %w", err)
 	}
 
+	metrics := NewMetrics()
+	if session != nil {
+		session.SetMetrics(metrics)
+	}
+
 	return &Server{
 		cfg:     cfg,
 		session: session,
+		metrics: metrics,
 		tmpl:    tmpl,
 	}, nil
 }
@@ -64,59 +92,157 @@ func NewServerWithSessions(cfg ServerConfig, sm *SessionManager) (*Server, error
 	}
 
 	return &Server{
-		cfg:  cfg,
-		sm:   sm,
-		tmpl: tmpl,
+		cfg:     cfg,
+		sm:      sm,
+		metrics: sm.Metrics(),
+		tmpl:    tmpl,
 	}, nil
 }
 
+// Listen binds the server address without serving requests yet, so bind errors and the actual port are known
+// before Start runs in the background. with PortAuto a port in use is skipped for the next one.
+// a no-op if the server is already bound; Start calls it when it isn't.
+func (s *Server) Listen() error {
+	s.srvMu.Lock()
+	defer s.srvMu.Unlock()
+	if s.ln != nil {
+		return nil
+	}
+
+	attempts := 1
+	if s.cfg.PortAuto && s.cfg.Port != 0 {
+		attempts = portAutoRange
+	}
+	var err error
+	for i := range attempts {
+		var ln net.Listener
+		ln, err = (&net.ListenConfig{}).Listen(context.Background(), "tcp", listenAddr(s.cfg.Listen, s.cfg.Port+i))
+		if err == nil {
+			s.ln = ln
+			return nil
+		}
+		if !isAddrInUse(err) {
+			break
+		}
+	}
+	if attempts > 1 {
+		return fmt.Errorf("no free port in %d-%d: %w", s.cfg.Port, s.cfg.Port+attempts-1, err)
+	}
+	return fmt.Errorf("listen: %w", err)
+}
+
+// Addr returns the address the server is bound to, nil before Listen and after the server stops.
+func (s *Server) Addr() net.Addr {
+	s.srvMu.Lock()
+	defer s.srvMu.Unlock()
+	if s.ln == nil {
+		return nil
+	}
+	return s.ln.Addr()
+}
+
+// Port returns the port the server is bound to, the configured port if it isn't bound.
+func (s *Server) Port() int {
+	if addr, ok := s.Addr().(*net.TCPAddr); ok {
+		return addr.Port
+	}
+	return s.cfg.Port
+}
+
 // Start begins listening for HTTP requests.
 // blocks until the server is stopped or an error occurs.
 func (s *Server) Start(ctx context.Context) error {
-	mux := http.NewServeMux()
-
-	// register routes
-	mux.HandleFunc("/", s.handleIndex)
-	mux.HandleFunc("/events", s.handleEvents)
-	mux.HandleFunc("/api/plan", s.handlePlan)
-	mux.HandleFunc("/api/sessions", s.handleSessions)
-
-	// static files
-	staticFS, err := fs.Sub(embeddedFS, "static")
+	handler, err := s.handler()
 	if err != nil {
-		return fmt.Errorf("static filesystem: %w", err)
+		return err
+	}
+	if err := s.Listen(); err != nil {
+		return fmt.Errorf("http server: %w", err)
 	}
-	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))))
 
-	s.srv = &http.Server{
-		Addr:              fmt.Sprintf("127.0.0.1:%d", s.cfg.Port),
-		Handler:           mux,
+	srv := &http.Server{
+		Handler:           handler,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
+	s.srvMu.Lock()
+	s.srv = srv
+	ln := s.ln
+	s.srvMu.Unlock()
+	defer func() {
+		s.srvMu.Lock()
+		s.ln = nil
+		s.srvMu.Unlock()
+	}()
 
 	// start shutdown listener
 	go func() {
 		<-ctx.Done()
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		_ = s.srv.Shutdown(shutdownCtx)
+		_ = srv.Shutdown(shutdownCtx)
 	}()
 
-	err = s.srv.ListenAndServe()
+	err = srv.Serve(ln)
 	if errors.Is(err, http.ErrServerClosed) {
 		return nil
 	}
 	return fmt.Errorf("http server: %w", err)
 }
 
+// listenAddr returns the address to listen on, 127.0.0.1 if listen is empty.
+func listenAddr(listen string, port int) string {
+	if listen == "" {
+		listen = "127.0.0.1"
+	}
+	return net.JoinHostPort(listen, strconv.Itoa(port))
+}
+
+// handler builds the router with all dashboard routes, behind the auth token if one is set.
+// cross-origin browser requests with unsafe methods (pause/resume) are rejected with 403,
+// so a page open in the same browser can't change the live run when no auth token is set.
+func (s *Server) handler() (http.Handler, error) {
+	mux := http.NewServeMux()
+
+	// register routes
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/events", s.handleEvents)
+	mux.HandleFunc("/api/plan", s.handlePlan)
+	mux.HandleFunc("GET /download", s.handleDownload)
+	mux.HandleFunc("/api/sessions", s.handleSessions)
+	mux.HandleFunc("GET /api/sessions/{id}/events", s.handleSessionEvents)
+	mux.HandleFunc("GET /session/{id}/download", s.handleSessionDownload)
+	mux.HandleFunc("GET /session/{id}/timeline", s.handleSessionTimeline)
+	mux.HandleFunc("POST /api/sessions/{id}/pause", s.handleSessionPause)
+	mux.HandleFunc("POST /api/sessions/{id}/resume", s.handleSessionResume)
+	mux.HandleFunc("GET /api/history", s.handleHistory)
+	mux.HandleFunc("GET /api/history/{id}", s.handleHistoryPage)
+	if s.cfg.MetricsEnabled {
+		mux.HandleFunc("GET /metrics", s.handleMetrics)
+	}
+	if s.cfg.EnableWebSocket {
+		mux.HandleFunc("GET /ws", s.handleWebSocket)
+	}
+
+	// static files
+	staticFS, err := fs.Sub(embeddedFS, "static")
+	if err != nil {
+		return nil, fmt.Errorf("static filesystem: %w", err)
+	}
+	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))))
+	return s.withAuth(http.NewCrossOriginProtection().Handler(mux)), nil
+}
+
 // Stop gracefully shuts down the server.
 func (s *Server) Stop() error {
-	if s.srv == nil {
+	s.srvMu.Lock()
+	srv := s.srv
+	s.srvMu.Unlock()
+	if srv == nil {
 		return nil
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if err := s.srv.Shutdown(ctx); err != nil {
+	if err := srv.Shutdown(ctx); err != nil {
 		return fmt.Errorf("shutdown server: %w", err)
 	}
 	return nil
@@ -129,8 +255,9 @@ func (s *Server) Session() *Session {
 
 // templateData holds data for the dashboard template.
 type templateData struct {
-	PlanName string
-	Branch   string
+	PlanName  string
+	Branch    string
+	WebSocket bool // the dashboard connects to /ws instead of /events
 }
 
 // handleIndex serves the main dashboard page.
@@ -143,8 +270,9 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
 	data := templateData{
-		PlanName: s.cfg.PlanName,
-		Branch:   s.cfg.Branch,
+		PlanName:  s.cfg.PlanName,
+		Branch:    s.cfg.Branch,
+		WebSocket: s.cfg.EnableWebSocket,
 	}
 
 	if err := s.tmpl.Execute(w, data); err != nil {
@@ -219,7 +347,7 @@ func (s *Server) handleSessionPlan(w http.ResponseWriter, sessionID string) {
 		planPath = filepath.Join(sessionDir, meta.PlanPath)
 	}
 
-	plan, err := loadPlanWithFallback(planPath)
+	plan, err := loadPlanWithFallback(planPath, s.cfg.PlanArchive)
 	if err != nil {
 		log.Printf("[WARN] failed to load plan file %s: %v", meta.PlanPath, err)
 		http.Error(w, "unable to load plan", http.StatusInternalServerError)
@@ -246,7 +374,7 @@ func (s *Server) loadPlan() (*Plan, error) {
 		return s.planCache, nil
 	}
 
-	plan, err := loadPlanWithFallback(s.cfg.PlanFile)
+	plan, err := loadPlanWithFallback(s.cfg.PlanFile, s.cfg.PlanArchive)
 	if err != nil {
 		return nil, err
 	}
@@ -255,13 +383,14 @@ func (s *Server) loadPlan() (*Plan, error) {
 	return plan, nil
 }
 
-// loadPlanWithFallback loads a plan from disk with completed/ directory fallback.
+// loadPlanWithFallback loads a plan from disk with a fallback to the completed directory of the archive.
 // does not cache - each call reads from disk.
-func loadPlanWithFallback(path string) (*Plan, error) {
+func loadPlanWithFallback(path string, archive plans.Archive) (*Plan, error) {
 	plan, err := ParsePlanFile(path)
 	if err != nil && errors.Is(err, fs.ErrNotExist) {
-		completedPath := filepath.Join(filepath.Dir(path), "completed", filepath.Base(path))
-		plan, err = ParsePlanFile(completedPath)
+		if completedPath := archive.FindCompleted(path); completedPath != "" {
+			plan, err = ParsePlanFile(completedPath)
+		}
 	}
 	return plan, err
 }
@@ -317,19 +446,36 @@ func (s *Server) getSession(r *http.Request) (*Session, error) {
 type SessionInfo struct {
 	ID    string       `json:"id"`
 	State SessionState `json:"state"`
+	// Status is the liveness of the session: live, idle, finished, failed or removed.
+	Status SessionStatus `json:"status"`
 	// dir is the short display name for the project (last path segment of session directory).
 	Dir string `json:"dir"`
 	// DirPath is the full filesystem path to the session directory (used for grouping and copy-to-clipboard).
 	DirPath      string     `json:"dirPath,omitempty"`
 	PlanPath     string     `json:"planPath,omitempty"`
+	PlanName     string     `json:"planName,omitempty"`
 	Branch       string     `json:"branch,omitempty"`
 	Mode         string     `json:"mode,omitempty"`
 	StartTime    time.Time  `json:"startTime"`
 	LastModified time.Time  `json:"lastModified"`
 	DiffStats    *DiffStats `json:"diffStats,omitempty"`
+	// PhaseChanges holds the commits and files changed by each finished phase.
+	PhaseChanges []PhaseChanges `json:"phaseChanges,omitempty"`
+	// Phase is the phase of the last published event, empty if no events yet.
+	Phase         status.Phase `json:"phase,omitempty"`
+	LastEventTime time.Time    `json:"lastEventTime"`
+	// LastSeq is the sequence number of the last event, to be passed as ?since= to the events endpoint.
+	LastSeq int64 `json:"lastSeq"`
+	// ElapsedSeconds is the time since start, up to now for active sessions or to the last event otherwise.
+	ElapsedSeconds int64 `json:"elapsedSeconds"`
+	// Pausable is true for the live run, which can be paused and resumed via the API.
+	Pausable bool `json:"pausable,omitempty"`
+	Paused   bool `json:"paused,omitempty"`
 }
 
 // handleSessions returns a list of all discovered sessions.
+// in single-session mode, the list contains the current execution session.
+// accepts ?branch=, ?plan= and ?status= to return only matching sessions, see SessionFilter.
 func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.Header().Set("Allow", http.MethodGet)
@@ -337,45 +483,33 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// single-session mode - return empty list
-	if s.sm == nil {
-		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte("[]"))
+	query := r.URL.Query()
+	filter := SessionFilter{Branch: query.Get("branch"), Plan: query.Get("plan"), Status: query.Get("status")}
+	if err := filter.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	sessions := s.sm.All()
-
-	// sort by last modified (most recent first)
-	sort.Slice(sessions, func(i, j int) bool {
-		return sessions[i].GetLastModified().After(sessions[j].GetLastModified())
-	})
-
-	// convert to API response format
-	infos := make([]SessionInfo, 0, len(sessions))
-	for _, session := range sessions {
-		meta := session.GetMetadata()
-		var dirPath string
-		if absPath, err := filepath.Abs(session.Path); err == nil {
-			dirPath = filepath.Dir(absPath)
-		} else {
-			dirPath = filepath.Dir(session.Path)
-			if dirPath == "." || dirPath == ".." {
-				dirPath = ""
-			}
-		}
-		infos = append(infos, SessionInfo{
-			ID:           session.ID,
-			State:        session.GetState(),
-			Dir:          extractProjectDir(session.Path),
-			DirPath:      dirPath,
-			PlanPath:     meta.PlanPath,
-			Branch:       meta.Branch,
-			Mode:         meta.Mode,
-			StartTime:    meta.StartTime,
-			LastModified: session.GetLastModified(),
-			DiffStats:    session.GetDiffStats(),
+	infos := []SessionInfo{}
+	switch {
+	case s.sm != nil:
+		sessions := s.sm.Filter(filter)
+
+		// sort by last modified (most recent first)
+		sort.Slice(sessions, func(i, j int) bool {
+			return sessions[i].GetLastModified().After(sessions[j].GetLastModified())
 		})
+
+		// convert to API response format
+		infos = make([]SessionInfo, 0, len(sessions))
+		for _, session := range sessions {
+			infos = append(infos, newSessionInfo(session, time.Now()))
+		}
+	case s.session != nil:
+		s.refreshSingleSession()
+		if now := time.Now(); filter.Match(s.session, now) {
+			infos = []SessionInfo{newSessionInfo(s.session, now)}
+		}
 	}
 
 	data, err := json.Marshal(infos)
@@ -389,6 +523,185 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(data)
 }
 
+// refreshSingleSession updates state and metadata of the single-session mode session from its progress file.
+// in multi-session mode the session manager does this; here it is done on demand, best-effort.
+func (s *Server) refreshSingleSession() {
+	meta, err := ParseProgressHeader(s.session.Path)
+	if err != nil {
+		meta = SessionMetadata{PlanPath: s.cfg.PlanFile, Branch: s.cfg.Branch}
+	}
+	s.session.SetMetadata(meta)
+
+	if active, err := IsActive(s.session.Path); err == nil {
+		state := SessionStateCompleted
+		if active {
+			state = SessionStateActive
+		}
+		s.session.SetState(state)
+	}
+	if fi, err := os.Stat(s.session.Path); err == nil {
+		// re-read the outcome of a stopped run only when the progress file changed
+		if s.session.GetState() == SessionStateCompleted && !fi.ModTime().Equal(s.session.GetLastModified()) {
+			s.session.SetOutcome(progressOutcome(s.session.Path))
+		}
+		s.session.SetLastModified(fi.ModTime())
+	}
+}
+
+// newSessionInfo builds the API representation of a session as of now.
+func newSessionInfo(session *Session, now time.Time) SessionInfo {
+	meta := session.GetMetadata()
+	var dirPath string
+	if absPath, err := filepath.Abs(session.Path); err == nil {
+		dirPath = filepath.Dir(absPath)
+	} else {
+		dirPath = filepath.Dir(session.Path)
+		if dirPath == "." || dirPath == ".." {
+			dirPath = ""
+		}
+	}
+
+	info := SessionInfo{
+		ID:           session.ID,
+		State:        session.GetState(),
+		Status:       session.Status(now),
+		Dir:          extractProjectDir(session.Path),
+		DirPath:      dirPath,
+		PlanPath:     meta.PlanPath,
+		Branch:       meta.Branch,
+		Mode:         meta.Mode,
+		StartTime:    meta.StartTime,
+		LastModified: session.GetLastModified(),
+		DiffStats:    session.GetDiffStats(),
+		PhaseChanges: session.GetPhaseChanges(),
+	}
+	if meta.PlanPath != "" {
+		info.PlanName = filepath.Base(meta.PlanPath)
+	}
+	if pause := session.PauseHolder(); pause != nil {
+		info.Pausable = true
+		info.Paused = pause.Paused()
+	}
+	if last, ok := session.LastEvent(); ok {
+		info.Phase = last.Event.Phase
+		info.LastEventTime = last.Event.Timestamp
+		info.LastSeq = last.Seq
+	}
+
+	if !meta.StartTime.IsZero() {
+		end := info.LastEventTime
+		if info.State == SessionStateActive || end.IsZero() {
+			end = now
+		}
+		info.ElapsedSeconds = max(int64(end.Sub(meta.StartTime)/time.Second), 0)
+	}
+	return info
+}
+
+// handleSessionEvents returns buffered events of a session as a JSON array.
+// accepts ?since=N to return only events with sequence number greater than N.
+func (s *Server) handleSessionEvents(w http.ResponseWriter, r *http.Request) {
+	session := s.sessionByID(r.PathValue("id"))
+	if session == nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	since, err := parseSince(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := json.Marshal(session.EventsSince(since))
+	if err != nil {
+		log.Printf("[WARN] failed to encode events: %v", err)
+		http.Error(w, "unable to encode events", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// handleSessionTimeline returns the phase spans of a session with start, end and duration,
+// in the order the phases ran.
+func (s *Server) handleSessionTimeline(w http.ResponseWriter, r *http.Request) {
+	session := s.sessionByID(r.PathValue("id"))
+	if session == nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	data, err := json.Marshal(session.GetTimeline())
+	if err != nil {
+		log.Printf("[WARN] failed to encode timeline: %v", err)
+		http.Error(w, "unable to encode timeline", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// parseSince returns the ?since= sequence number of the request, 0 if not set.
+func parseSince(r *http.Request) (int64, error) {
+	val := r.URL.Query().Get("since")
+	if val == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil || n < 0 {
+		return 0, errors.New("invalid since parameter")
+	}
+	return n, nil
+}
+
+// handleSessionPause pauses the live run of a session before its next iteration.
+func (s *Server) handleSessionPause(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r, true)
+}
+
+// handleSessionResume resumes a paused live run.
+func (s *Server) handleSessionResume(w http.ResponseWriter, r *http.Request) {
+	s.setPaused(w, r, false)
+}
+
+// setPaused changes the paused state of a session's live run and responds with the resulting state.
+// sessions only tailed from progress files have no pause control, they get 409.
+func (s *Server) setPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	session := s.sessionByID(r.PathValue("id"))
+	if session == nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	pause := session.PauseHolder()
+	if pause == nil {
+		http.Error(w, "session is not controlled by this process", http.StatusConflict)
+		return
+	}
+
+	if paused {
+		pause.Pause()
+	} else {
+		pause.Resume()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = fmt.Fprintf(w, `{"paused":%t}`, pause.Paused())
+}
+
+// sessionByID returns the session with the given ID, nil if not found.
+func (s *Server) sessionByID(id string) *Session {
+	switch {
+	case s.sm != nil:
+		return s.sm.Get(id)
+	case s.session != nil && s.session.ID == id:
+		return s.session
+	}
+	return nil
+}
+
 // extractProjectDir extracts project directory name from session path.
 // handles edge cases where path has no meaningful parent directory.
 func extractProjectDir(path string) string {
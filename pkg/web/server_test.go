@@ -3,17 +3,23 @@ package web
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
+	"maps"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	plans "github.com/umputun/ralphex/pkg/plan"
 	"github.com/umputun/ralphex/pkg/status"
 )
 
@@ -143,6 +149,58 @@ func TestServer_StartStop(t *testing.T) {
 	}
 }
 
+func TestListenAddr(t *testing.T) {
+	assert.Equal(t, "127.0.0.1:8080", listenAddr("", 8080))
+	assert.Equal(t, "0.0.0.0:8080", listenAddr("0.0.0.0", 8080))
+	assert.Equal(t, "[::1]:8080", listenAddr("::1", 8080))
+}
+
+func TestServer_Listen_PortAuto(t *testing.T) {
+	// occupy a port, as another ralphex instance would
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer busy.Close()
+	port := busy.Addr().(*net.TCPAddr).Port
+
+	t.Run("falls back to the next free port", func(t *testing.T) {
+		session := NewSession("test", "/tmp/test.txt")
+		defer session.Close()
+		srv, err := NewServer(ServerConfig{Port: port, PortAuto: true}, session)
+		require.NoError(t, err)
+		assert.Nil(t, srv.Addr(), "not bound yet")
+		assert.Equal(t, port, srv.Port())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errCh, err := startServerAsync(ctx, srv, port)
+		require.NoError(t, err)
+
+		bound := srv.Port()
+		assert.Greater(t, bound, port)
+		assert.Less(t, bound, port+portAutoRange)
+		assert.Equal(t, "127.0.0.1:"+strconv.Itoa(bound), srv.Addr().String())
+
+		resp, err := http.Get("http://" + srv.Addr().String() + "/") //nolint:noctx // test
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		cancel()
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("fails without port auto", func(t *testing.T) {
+		session := NewSession("test", "/tmp/test.txt")
+		defer session.Close()
+		srv, err := NewServer(ServerConfig{Port: port}, session)
+		require.NoError(t, err)
+		err = srv.Listen()
+		require.Error(t, err)
+		assert.True(t, isAddrInUse(err), "expected address in use error, got %v", err)
+		assert.Nil(t, srv.Addr())
+	})
+}
+
 func TestServer_Stop(t *testing.T) {
 	t.Run("stop without start is safe", func(t *testing.T) {
 		session := NewSession("test", "/tmp/test.txt")
@@ -364,9 +422,20 @@ func TestNewServerWithSessions(t *testing.T) {
 }
 
 func TestServer_HandleSessions(t *testing.T) {
-	t.Run("returns empty list in single-session mode", func(t *testing.T) {
-		session := NewSession("test", "/tmp/test.txt")
+	t.Run("returns current session in single-session mode", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		progressPath := filepath.Join(tmpDir, "progress-test.txt")
+		started := time.Now().Add(-time.Minute).Truncate(time.Second)
+		progressContent := "# Ralphex Progress Log\nPlan: docs/plans/test-plan.md\nBranch: feature-branch\nMode: full\n" +
+			"Started: " + started.Format("2006-01-02 15:04:05") + "\n" +
+			"------------------------------------------------------------\n"
+		require.NoError(t, os.WriteFile(progressPath, []byte(progressContent), 0o600))
+
+		session := NewSession("main", progressPath)
 		defer session.Close()
+		require.NoError(t, session.Publish(NewOutputEvent(status.PhaseTask, "working")))
+		require.NoError(t, session.Publish(NewSectionEvent(status.PhaseReview, "review")))
+
 		srv, err := NewServer(ServerConfig{Port: 8080}, session)
 		require.NoError(t, err)
 
@@ -381,9 +450,59 @@ func TestServer_HandleSessions(t *testing.T) {
 		assert.Equal(t, http.StatusOK, resp.StatusCode)
 		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
 
-		body, err := io.ReadAll(resp.Body)
+		var sessions []SessionInfo
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&sessions))
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "main", sessions[0].ID)
+		assert.Equal(t, "docs/plans/test-plan.md", sessions[0].PlanPath)
+		assert.Equal(t, "test-plan.md", sessions[0].PlanName)
+		assert.Equal(t, "feature-branch", sessions[0].Branch)
+		assert.Equal(t, status.PhaseReview, sessions[0].Phase)
+		assert.Equal(t, int64(2), sessions[0].LastSeq)
+		assert.False(t, sessions[0].LastEventTime.IsZero())
+		assert.True(t, started.Equal(sessions[0].StartTime))
+		assert.GreaterOrEqual(t, sessions[0].ElapsedSeconds, int64(59))
+	})
+
+	t.Run("reports status from the progress log", func(t *testing.T) {
+		progressPath := filepath.Join(t.TempDir(), "progress-test.txt")
+		content := "# Ralphex Progress Log\nPlan: docs/plans/test-plan.md\nMode: full\n" +
+			"------------------------------------------------------------\n" +
+			"[26-01-22 10:00:01] all phases completed successfully\n\n" +
+			"------------------------------------------------------------\nCompleted: 2026-01-22 10:05:00 (5m0s)\n"
+		require.NoError(t, os.WriteFile(progressPath, []byte(content), 0o600))
+		session := NewSession("main", progressPath)
+		defer session.Close()
+		srv, err := NewServer(ServerConfig{Port: 8080}, session)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		srv.handleSessions(w, httptest.NewRequest(http.MethodGet, "/api/sessions", http.NoBody))
+
+		var sessions []SessionInfo
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessions))
+		require.Len(t, sessions, 1)
+		assert.Equal(t, SessionStateCompleted, sessions[0].State)
+		assert.Equal(t, SessionStatusFinished, sessions[0].Status)
+		assert.Contains(t, w.Body.String(), `"status":"finished"`)
+	})
+
+	t.Run("single-session mode falls back to server config without progress file", func(t *testing.T) {
+		session := NewSession("main", filepath.Join(t.TempDir(), "missing.txt"))
+		defer session.Close()
+		srv, err := NewServer(ServerConfig{Port: 8080, PlanFile: "docs/plans/x.md", Branch: "x"}, session)
 		require.NoError(t, err)
-		assert.Equal(t, "[]", string(body))
+
+		w := httptest.NewRecorder()
+		srv.handleSessions(w, httptest.NewRequest(http.MethodGet, "/api/sessions", http.NoBody))
+
+		var sessions []SessionInfo
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessions))
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "docs/plans/x.md", sessions[0].PlanPath)
+		assert.Equal(t, "x", sessions[0].Branch)
+		assert.Empty(t, sessions[0].Phase)
+		assert.Equal(t, int64(0), sessions[0].ElapsedSeconds)
 	})
 
 	t.Run("returns sessions list in multi-session mode", func(t *testing.T) {
@@ -432,6 +551,69 @@ Started: 2026-01-22 10:30:00
 		assert.Equal(t, "docs/plans/test-plan.md", sessions[0].PlanPath)
 		assert.Equal(t, "feature-branch", sessions[0].Branch)
 		assert.Equal(t, "full", sessions[0].Mode)
+		assert.Equal(t, "test-plan.md", sessions[0].PlanName)
+	})
+
+	t.Run("filters sessions by query", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		createProgressFile(t, filepath.Join(tmpDir, "progress-auth.txt"), "docs/plans/add-auth.md", "feature/auth", "full")
+		createProgressFile(t, filepath.Join(tmpDir, "progress-fix.txt"), "docs/plans/fix-tests.md", "main", "full")
+		sm := NewSessionManager()
+		defer sm.Close()
+		_, err := sm.Discover(tmpDir)
+		require.NoError(t, err)
+		sm.Get(sessionIDFromPath(filepath.Join(tmpDir, "progress-auth.txt"))).SetState(SessionStateActive)
+		srv, err := NewServerWithSessions(ServerConfig{Port: 8080}, sm)
+		require.NoError(t, err)
+
+		tests := []struct {
+			query     string
+			wantPlans []string
+		}{
+			{query: "", wantPlans: []string{"add-auth.md", "fix-tests.md"}},
+			{query: "?branch=feature&status=running", wantPlans: []string{"add-auth.md"}},
+			{query: "?plan=fix", wantPlans: []string{"fix-tests.md"}},
+			{query: "?status=completed", wantPlans: []string{"fix-tests.md"}},
+			{query: "?branch=release", wantPlans: []string{}},
+		}
+		for _, tc := range tests {
+			w := httptest.NewRecorder()
+			srv.handleSessions(w, httptest.NewRequest(http.MethodGet, "/api/sessions"+tc.query, http.NoBody))
+			require.Equal(t, http.StatusOK, w.Code, tc.query)
+			var sessions []SessionInfo
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessions), "empty result is a JSON array")
+			plans := []string{}
+			for _, s := range sessions {
+				plans = append(plans, s.PlanName)
+			}
+			sort.Strings(plans)
+			assert.Equal(t, tc.wantPlans, plans, tc.query)
+		}
+
+		w := httptest.NewRecorder()
+		srv.handleSessions(w, httptest.NewRequest(http.MethodGet, "/api/sessions?status=done", http.NoBody))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `unknown status "done"`)
+	})
+
+	t.Run("filters the single session", func(t *testing.T) {
+		progressPath := filepath.Join(t.TempDir(), "progress-test.txt")
+		createProgressFile(t, progressPath, "docs/plans/test-plan.md", "feature-branch", "full")
+		session := NewSession("main", progressPath)
+		defer session.Close()
+		srv, err := NewServer(ServerConfig{Port: 8080}, session)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		srv.handleSessions(w, httptest.NewRequest(http.MethodGet, "/api/sessions?branch=feature", http.NoBody))
+		var sessions []SessionInfo
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessions))
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "main", sessions[0].ID)
+
+		w = httptest.NewRecorder()
+		srv.handleSessions(w, httptest.NewRequest(http.MethodGet, "/api/sessions?branch=main", http.NoBody))
+		assert.Equal(t, "[]", w.Body.String())
 	})
 
 	t.Run("rejects non-GET methods", func(t *testing.T) {
@@ -455,6 +637,256 @@ Started: 2026-01-22 10:30:00
 	})
 }
 
+func TestServer_HandleSessionTimeline(t *testing.T) {
+	base := time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC)
+	session := NewSession("main", filepath.Join(t.TempDir(), "progress-main.txt"))
+	defer session.Close()
+	for i, phase := range []status.Phase{status.PhaseTask, status.PhaseTask, status.PhaseReview, status.PhaseCodex, status.PhaseCodex} {
+		e := NewOutputEvent(phase, "line")
+		e.Timestamp = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, session.Publish(e))
+	}
+	srv, err := NewServer(ServerConfig{Port: 8080}, session)
+	require.NoError(t, err)
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /session/{id}/timeline", srv.handleSessionTimeline)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, target, http.NoBody))
+		return w
+	}
+
+	w := serve(http.MethodGet, "/session/main/timeline")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var timeline []PhaseSpan
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &timeline))
+	require.Len(t, timeline, 3)
+	for i, want := range []status.Phase{status.PhaseTask, status.PhaseReview, status.PhaseCodex} {
+		assert.Equal(t, want, timeline[i].Phase)
+		assert.Positive(t, timeline[i].DurationMs)
+		assert.Equal(t, timeline[i].End.Sub(timeline[i].Start).Milliseconds(), timeline[i].DurationMs)
+		if i > 0 {
+			assert.True(t, timeline[i].Start.Equal(timeline[i-1].End), "spans are ordered and adjacent")
+		}
+	}
+	assert.Equal(t, int64(2*time.Minute/time.Millisecond), timeline[0].DurationMs)
+	assert.Contains(t, w.Body.String(), `"durationMs":120000`)
+
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/session/other/timeline").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPost, "/session/main/timeline").Code)
+
+	// the server routes the timeline only at /session/{id}/timeline
+	handler, err := srv.handler()
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/session/main/timeline", http.NoBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, w.Body.String(), rec.Body.String())
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/sessions/main/timeline", http.NoBody))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_HandleSessionEvents(t *testing.T) {
+	newSessionWithEvents := func(t *testing.T, id string, n int) *Session {
+		t.Helper()
+		session := NewSession(id, filepath.Join(t.TempDir(), "progress-"+id+".txt"))
+		t.Cleanup(session.Close)
+		for i := range n {
+			require.NoError(t, session.Publish(NewOutputEvent(status.PhaseTask, fmt.Sprintf("line %d", i+1))))
+		}
+		return session
+	}
+
+	// serve through a mux so path values and method routing are applied like in Start
+	serve := func(srv *Server, method, target string) *httptest.ResponseRecorder {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /api/sessions/{id}/events", srv.handleSessionEvents)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, target, http.NoBody))
+		return w
+	}
+
+	t.Run("single-session mode", func(t *testing.T) {
+		srv, err := NewServer(ServerConfig{Port: 8080}, newSessionWithEvents(t, "main", 3))
+		require.NoError(t, err)
+
+		tests := []struct {
+			name     string
+			target   string
+			wantCode int
+			wantSeqs []int64
+		}{
+			{name: "all events", target: "/api/sessions/main/events", wantCode: http.StatusOK, wantSeqs: []int64{1, 2, 3}},
+			{name: "since", target: "/api/sessions/main/events?since=1", wantCode: http.StatusOK, wantSeqs: []int64{2, 3}},
+			{name: "since last", target: "/api/sessions/main/events?since=3", wantCode: http.StatusOK, wantSeqs: []int64{}},
+			{name: "invalid since", target: "/api/sessions/main/events?since=abc", wantCode: http.StatusBadRequest},
+			{name: "negative since", target: "/api/sessions/main/events?since=-1", wantCode: http.StatusBadRequest},
+			{name: "unknown session", target: "/api/sessions/other/events", wantCode: http.StatusNotFound},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				w := serve(srv, http.MethodGet, tc.target)
+				require.Equal(t, tc.wantCode, w.Code)
+				if tc.wantCode != http.StatusOK {
+					return
+				}
+				assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+				var events []SessionEvent
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &events))
+				seqs := make([]int64, 0, len(events))
+				for _, e := range events {
+					seqs = append(seqs, e.Seq)
+				}
+				assert.Equal(t, tc.wantSeqs, seqs)
+			})
+		}
+	})
+
+	t.Run("multi-session mode", func(t *testing.T) {
+		sm := NewSessionManager()
+		defer sm.Close()
+		sm.Register(newSessionWithEvents(t, "one", 1))
+		two := newSessionWithEvents(t, "two", 2)
+		sm.Register(two) // register derives the id from the progress file path
+		srv, err := NewServerWithSessions(ServerConfig{Port: 8080}, sm)
+		require.NoError(t, err)
+
+		w := serve(srv, http.MethodGet, "/api/sessions/"+two.ID+"/events?since=1")
+		require.Equal(t, http.StatusOK, w.Code)
+		var events []SessionEvent
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &events))
+		require.Len(t, events, 1)
+		assert.Equal(t, int64(2), events[0].Seq)
+		assert.Equal(t, "line 2", events[0].Event.Text)
+		assert.Equal(t, status.PhaseTask, events[0].Event.Phase)
+
+		w = serve(srv, http.MethodGet, "/api/sessions/missing/events")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("rejects non-GET methods", func(t *testing.T) {
+		srv, err := NewServer(ServerConfig{Port: 8080}, newSessionWithEvents(t, "main", 1))
+		require.NoError(t, err)
+		w := serve(srv, http.MethodPost, "/api/sessions/main/events")
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
+
+func TestServer_HandleSessionPause(t *testing.T) {
+	serve := func(srv *Server, method, target string) *httptest.ResponseRecorder {
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /api/sessions/{id}/pause", srv.handleSessionPause)
+		mux.HandleFunc("POST /api/sessions/{id}/resume", srv.handleSessionResume)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, target, http.NoBody))
+		return w
+	}
+
+	t.Run("single-session mode controls the live run", func(t *testing.T) {
+		pause := &status.PauseHolder{}
+		session := NewSession("main", filepath.Join(t.TempDir(), "progress-main.txt"))
+		defer session.Close()
+		session.SetPauseHolder(pause)
+		srv, err := NewServer(ServerConfig{Port: 8080}, session)
+		require.NoError(t, err)
+
+		tests := []struct {
+			name       string
+			method     string
+			target     string
+			wantCode   int
+			wantBody   string
+			wantPaused bool
+		}{
+			{name: "pause", method: http.MethodPost, target: "/api/sessions/main/pause", wantCode: http.StatusOK,
+				wantBody: `{"paused":true}`, wantPaused: true},
+			{name: "pause again", method: http.MethodPost, target: "/api/sessions/main/pause", wantCode: http.StatusOK,
+				wantBody: `{"paused":true}`, wantPaused: true},
+			{name: "get rejected", method: http.MethodGet, target: "/api/sessions/main/resume", wantCode: http.StatusMethodNotAllowed,
+				wantPaused: true},
+			{name: "unknown session", method: http.MethodPost, target: "/api/sessions/other/resume", wantCode: http.StatusNotFound,
+				wantPaused: true},
+			{name: "resume", method: http.MethodPost, target: "/api/sessions/main/resume", wantCode: http.StatusOK,
+				wantBody: `{"paused":false}`},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				w := serve(srv, tc.method, tc.target)
+				require.Equal(t, tc.wantCode, w.Code)
+				if tc.wantBody != "" {
+					assert.JSONEq(t, tc.wantBody, w.Body.String())
+				}
+				assert.Equal(t, tc.wantPaused, pause.Paused())
+			})
+		}
+	})
+
+	t.Run("tailed sessions can't be paused", func(t *testing.T) {
+		sm := NewSessionManager()
+		defer sm.Close()
+		live := NewSession("main", filepath.Join(t.TempDir(), "progress-main.txt"))
+		live.SetPauseHolder(&status.PauseHolder{})
+		sm.Register(live)
+		tailed := NewSession("other", filepath.Join(t.TempDir(), "progress-other.txt"))
+		sm.Register(tailed)
+		srv, err := NewServerWithSessions(ServerConfig{Port: 8080}, sm)
+		require.NoError(t, err)
+
+		w := serve(srv, http.MethodPost, "/api/sessions/"+tailed.ID+"/pause")
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = serve(srv, http.MethodPost, "/api/sessions/"+live.ID+"/pause")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, live.PauseHolder().Paused())
+
+		infos := map[string]SessionInfo{}
+		for _, s := range sm.All() {
+			infos[s.ID] = newSessionInfo(s, time.Now())
+		}
+		assert.True(t, infos[live.ID].Pausable)
+		assert.True(t, infos[live.ID].Paused)
+		assert.False(t, infos[tailed.ID].Pausable)
+	})
+
+	t.Run("cross-origin requests are rejected", func(t *testing.T) {
+		pause := &status.PauseHolder{}
+		session := NewSession("main", filepath.Join(t.TempDir(), "progress-main.txt"))
+		defer session.Close()
+		session.SetPauseHolder(pause)
+		srv, err := NewServer(ServerConfig{Port: 8080}, session)
+		require.NoError(t, err)
+		h, err := srv.handler()
+		require.NoError(t, err)
+
+		tests := []struct {
+			name     string
+			header   http.Header
+			wantCode int
+		}{
+			{name: "cross-site fetch", header: http.Header{"Sec-Fetch-Site": {"cross-site"}}, wantCode: http.StatusForbidden},
+			{name: "foreign origin", header: http.Header{"Origin": {"http://evil.example"}}, wantCode: http.StatusForbidden},
+			{name: "same-origin fetch", header: http.Header{"Sec-Fetch-Site": {"same-origin"}}, wantCode: http.StatusOK},
+			{name: "non-browser client", header: http.Header{}, wantCode: http.StatusOK},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				pause.Resume()
+				req := httptest.NewRequest(http.MethodPost, "/api/sessions/main/pause", http.NoBody)
+				maps.Copy(req.Header, tc.header)
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, req)
+				assert.Equal(t, tc.wantCode, w.Code)
+				assert.Equal(t, tc.wantCode == http.StatusOK, pause.Paused())
+			})
+		}
+	})
+}
+
 func TestServer_HandleEvents_WithSession(t *testing.T) {
 	t.Run("returns 404 for unknown session", func(t *testing.T) {
 		sm := NewSessionManager()
@@ -694,7 +1126,7 @@ func TestLoadPlanWithFallback(t *testing.T) {
 `
 		require.NoError(t, os.WriteFile(planPath, []byte(planContent), 0o600))
 
-		plan, err := loadPlanWithFallback(planPath)
+		plan, err := loadPlanWithFallback(planPath, plans.Archive{})
 		require.NoError(t, err)
 		require.NotNil(t, plan)
 		assert.Equal(t, "Test Plan", plan.Title)
@@ -717,17 +1149,31 @@ func TestLoadPlanWithFallback(t *testing.T) {
 
 		// request the non-existent original path
 		originalPath := filepath.Join(tmpDir, "test-plan.md")
-		plan, err := loadPlanWithFallback(originalPath)
+		plan, err := loadPlanWithFallback(originalPath, plans.Archive{})
 		require.NoError(t, err)
 		require.NotNil(t, plan)
 		assert.Equal(t, "Completed Plan", plan.Title)
 	})
 
+	t.Run("falls back to configured archive", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		archived := filepath.Join(tmpDir, "archive", "2026-03", "test-plan.md")
+		require.NoError(t, os.MkdirAll(filepath.Dir(archived), 0o750))
+		require.NoError(t, os.WriteFile(archived, []byte("# Archived Plan\n"), 0o600))
+
+		originalPath := filepath.Join(tmpDir, "test-plan.md")
+		_, err := loadPlanWithFallback(originalPath, plans.Archive{})
+		require.Error(t, err, "not in the default completed directory")
+		plan, err := loadPlanWithFallback(originalPath, plans.Archive{Dir: "archive", DateLayout: "2006-01"})
+		require.NoError(t, err)
+		assert.Equal(t, "Archived Plan", plan.Title)
+	})
+
 	t.Run("returns error when not found in either location", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		nonexistentPath := filepath.Join(tmpDir, "nonexistent.md")
 
-		_, err := loadPlanWithFallback(nonexistentPath)
+		_, err := loadPlanWithFallback(nonexistentPath, plans.Archive{})
 		require.Error(t, err)
 	})
 }
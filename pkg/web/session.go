@@ -5,15 +5,22 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/tmaxmax/go-sse"
+	"github.com/umputun/ralphex/pkg/status"
 )
 
 // DefaultReplayerSize is the maximum number of events to keep for replay to late-joining clients.
 const DefaultReplayerSize = 10000
 
+// subscriberBuffer is the number of published events a subscriber may lag behind before it is dropped.
+const subscriberBuffer = 1024
+
 // allEventsReplayer wraps FiniteReplayer to replay ALL events when LastEventID is empty.
 // standard FiniteReplayer only replays events after a specific ID, which doesn't work
 // for first-time connections (no Last-Event-ID header).
@@ -50,6 +57,22 @@ const (
 	SessionStateCompleted SessionState = "completed" // session finished (no lock held)
 )
 
+// SessionStatus is the liveness of a session, a finer view than SessionState derived from
+// the progress file's modification time and content.
+type SessionStatus string
+
+// session status constants.
+const (
+	SessionStatusLive     SessionStatus = "live"     // running and writing to the progress file
+	SessionStatusIdle     SessionStatus = "idle"     // running without writes for IdleTimeout, or stopped without the completion footer
+	SessionStatusFinished SessionStatus = "finished" // stopped, the progress log has the completion footer
+	SessionStatusFailed   SessionStatus = "failed"   // stopped, the progress log has the FAILED signal
+	SessionStatusRemoved  SessionStatus = "removed"  // the progress file was deleted on disk
+)
+
+// IdleTimeout is the time without progress file writes after which a running session is reported as idle.
+const IdleTimeout = 10 * time.Minute
+
 // SessionMetadata holds parsed information from progress file header.
 type SessionMetadata struct {
 	PlanPath  string    // path to plan file (from "Plan:" header line)
@@ -79,11 +102,46 @@ type Session struct {
 	// diffStats holds git diff statistics when available (nil if not set)
 	diffStats *DiffStats
 
+	// phaseChanges holds the change summaries of finished phases, in the order they ran
+	phaseChanges []PhaseChanges
+
+	// timeline holds the phase spans of the session in the order they ran
+	timeline []PhaseSpan
+
 	// stopTailCh signals the tail feeder goroutine to stop
 	stopTailCh chan struct{}
 
 	// loaded tracks whether historical data has been loaded into the SSE server
 	loaded bool
+
+	// outcome is the result found in the progress log of a stopped session (finished or failed), empty if none
+	outcome SessionStatus
+
+	// removed is set when the progress file was deleted on disk
+	removed bool
+
+	// pauseHolder controls the live run of this session, nil for sessions only tailed from progress files
+	pauseHolder *status.PauseHolder
+
+	// metrics counts published events and phase durations, nil disables collection
+	metrics *Metrics
+
+	// questions relays plan questions of the live run to the dashboard, nil if it can't answer them
+	questions *QuestionRelay
+
+	// subscribers receive every published event, used by the websocket transport
+	subscribers map[chan SessionEvent]struct{}
+
+	// events buffers published events with sequence numbers for the events API,
+	// capped at DefaultReplayerSize like the SSE replayer
+	events  []SessionEvent
+	lastSeq int64
+}
+
+// SessionEvent is a published event with its per-session sequence number (starting at 1).
+type SessionEvent struct {
+	Seq   int64 `json:"seq"`
+	Event Event `json:"event"`
 }
 
 // NewSession creates a new session for the given progress file path.
@@ -162,6 +220,69 @@ func (s *Session) GetLastModified() time.Time {
 	return s.lastModified
 }
 
+// SetOutcome stores the result found in the progress log, see progressOutcome.
+func (s *Session) SetOutcome(outcome SessionStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outcome = outcome
+}
+
+// SetStatus marks the session completed with the status the watcher detected from its progress file:
+// finished or failed once the completion footer is written, idle when writes stopped for the idle window.
+// the session turns active again if a later write finds the progress file still locked.
+func (s *Session) SetStatus(st SessionStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.State = SessionStateCompleted
+	s.outcome = ""
+	if st == SessionStatusFinished || st == SessionStatusFailed {
+		s.outcome = st
+	}
+}
+
+// resumeIfIdle turns a session marked idle by SetStatus active again, used when its progress file gets new output.
+func (s *Session) resumeIfIdle() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.State == SessionStateCompleted && s.outcome == "" {
+		s.State = SessionStateActive
+	}
+}
+
+// SetRemoved marks the session's progress file as deleted on disk.
+func (s *Session) SetRemoved(removed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removed = removed
+}
+
+// IsRemoved returns true if the session's progress file was deleted on disk.
+func (s *Session) IsRemoved() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.removed
+}
+
+// Status returns the liveness of the session as of now.
+// a stopped session reports the outcome from its progress log, or idle if it has none (killed run);
+// a running session is idle after IdleTimeout without writes.
+func (s *Session) Status(now time.Time) SessionStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	switch {
+	case s.removed:
+		return SessionStatusRemoved
+	case s.State == SessionStateCompleted && s.outcome != "":
+		return s.outcome
+	case s.State == SessionStateCompleted:
+		return SessionStatusIdle
+	case !s.lastModified.IsZero() && now.Sub(s.lastModified) >= IdleTimeout:
+		return SessionStatusIdle
+	default:
+		return SessionStatusLive
+	}
+}
+
 // GetDiffStats returns a copy of the diff stats, or nil if not set.
 func (s *Session) GetDiffStats() *DiffStats {
 	s.mu.RLock()
@@ -180,6 +301,72 @@ func (s *Session) SetDiffStats(stats DiffStats) {
 	s.diffStats = &stats
 }
 
+// GetPhaseChanges returns a copy of the change summaries of finished phases.
+func (s *Session) GetPhaseChanges() []PhaseChanges {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.phaseChanges)
+}
+
+// AddPhaseChanges stores the change summary of a finished phase, replacing an earlier one of the same phase,
+// e.g. when a resumed run repeats it.
+func (s *Session) AddPhaseChanges(pc PhaseChanges) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.phaseChanges {
+		if s.phaseChanges[i].Phase == pc.Phase {
+			s.phaseChanges[i] = pc
+			return
+		}
+	}
+	s.phaseChanges = append(s.phaseChanges, pc)
+}
+
+// recordOutputStats stores diff stats and phase change summaries found in an output line.
+func (s *Session) recordOutputStats(text string) {
+	if stats, ok := parseDiffStats(text); ok {
+		s.SetDiffStats(stats)
+	}
+	if pc, ok := parsePhaseChanges(text); ok {
+		s.AddPhaseChanges(pc)
+	}
+}
+
+// SetPauseHolder attaches the pause control of the live run executing this session.
+func (s *Session) SetPauseHolder(h *status.PauseHolder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pauseHolder = h
+}
+
+// PauseHolder returns the pause control of the live run, nil if the session can't be paused.
+func (s *Session) PauseHolder() *status.PauseHolder {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pauseHolder
+}
+
+// SetQuestionRelay attaches the relay answering plan questions of the live run from the dashboard.
+func (s *Session) SetQuestionRelay(q *QuestionRelay) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.questions = q
+}
+
+// QuestionRelay returns the question relay of the live run, nil if questions can't be answered from the dashboard.
+func (s *Session) QuestionRelay() *QuestionRelay {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.questions
+}
+
+// SetMetrics sets the metrics collector updated by Publish.
+func (s *Session) SetMetrics(m *Metrics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics = m
+}
+
 // IsLoaded returns whether historical data has been loaded into the SSE server.
 func (s *Session) IsLoaded() bool {
 	s.mu.RLock()
@@ -252,9 +439,81 @@ func (s *Session) Publish(event Event) error {
 	if err := s.SSE.Publish(msg, defaultTopic); err != nil {
 		return fmt.Errorf("publish event: %w", err)
 	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics.EventBroadcast()
+	if n := len(s.events); n > 0 {
+		// time between consecutive events is attributed to the phase of the earlier one
+		prev := s.events[n-1].Event
+		s.metrics.AddPhaseDuration(prev.Phase, event.Timestamp.Sub(prev.Timestamp))
+	}
+	s.recordPhase(event.Phase, event.Timestamp)
+	s.lastSeq++
+	se := SessionEvent{Seq: s.lastSeq, Event: event}
+	s.events = append(s.events, se)
+	if len(s.events) > DefaultReplayerSize {
+		s.events = s.events[len(s.events)-DefaultReplayerSize:]
+	}
+	for ch := range s.subscribers {
+		select {
+		case ch <- se:
+		default:
+			// the subscriber can't keep up, drop it; it reconnects and catches up with EventsSince
+			close(ch)
+			delete(s.subscribers, ch)
+		}
+	}
 	return nil
 }
 
+// Subscribe returns buffered events with sequence number greater than since and a channel
+// receiving every event published afterwards, with no gap between the two.
+// the channel is closed by unsubscribe, or when the subscriber falls subscriberBuffer events behind.
+func (s *Session) Subscribe(since int64) (history []SessionEvent, events <-chan SessionEvent, unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := sort.Search(len(s.events), func(i int) bool { return s.events[i].Seq > since })
+	history = make([]SessionEvent, len(s.events)-idx)
+	copy(history, s.events[idx:])
+
+	ch := make(chan SessionEvent, subscriberBuffer)
+	if s.subscribers == nil {
+		s.subscribers = make(map[chan SessionEvent]struct{})
+	}
+	s.subscribers[ch] = struct{}{}
+	unsubscribe = func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subscribers[ch]; ok {
+			close(ch)
+			delete(s.subscribers, ch)
+		}
+	}
+	return history, ch, unsubscribe
+}
+
+// EventsSince returns buffered events with sequence number greater than seq, oldest first.
+// events evicted from the buffer are not returned.
+func (s *Session) EventsSince(seq int64) []SessionEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	idx := sort.Search(len(s.events), func(i int) bool { return s.events[i].Seq > seq })
+	res := make([]SessionEvent, len(s.events)-idx)
+	copy(res, s.events[idx:])
+	return res
+}
+
+// LastEvent returns the most recently published event, or false if nothing was published yet.
+func (s *Session) LastEvent() (SessionEvent, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.events) == 0 {
+		return SessionEvent{}, false
+	}
+	return s.events[len(s.events)-1], true
+}
+
 // feedEvents reads events from the tailer and publishes them to SSE clients.
 func (s *Session) feedEvents() {
 	s.mu.RLock()
@@ -276,20 +535,30 @@ func (s *Session) feedEvents() {
 				return
 			}
 			if event.Type == EventTypeOutput {
-				if stats, ok := parseDiffStats(event.Text); ok {
-					s.SetDiffStats(stats)
-				}
+				s.recordOutputStats(event.Text)
 			}
 			if err := s.Publish(event); err != nil {
 				log.Printf("[WARN] failed to publish tailed event: %v", err)
 			}
+			// the footer is the last thing a run writes, no need to wait for the lock release
+			if event.Type == EventTypeOutput && strings.HasPrefix(event.Text, progressFooterPrefix) {
+				s.SetStatus(progressOutcome(s.Path))
+				continue
+			}
+			s.resumeIfIdle()
 		}
 	}
 }
 
-// Close cleans up session resources including the tailer and SSE server.
+// Close cleans up session resources including the tailer, SSE server and subscriptions.
 func (s *Session) Close() {
 	s.StopTailing()
+	s.mu.Lock()
+	for ch := range s.subscribers {
+		close(ch)
+		delete(s.subscribers, ch)
+	}
+	s.mu.Unlock()
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := s.SSE.Shutdown(ctx); err != nil {
@@ -2,13 +2,14 @@ package web
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"hash/fnv"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -28,18 +29,44 @@ const MaxCompletedSessions = 100
 // and provides access to sessions by ID.
 // completed sessions are automatically evicted when MaxCompletedSessions is exceeded.
 type SessionManager struct {
-	mu       sync.RWMutex
-	sessions map[string]*Session // keyed by session ID
+	mu         sync.RWMutex
+	sessions   map[string]*Session  // keyed by session ID
+	pruneAfter time.Duration        // drop stopped sessions not modified for this long, zero disables pruning
+	idleAfter  time.Duration        // mark running sessions completed after this long without writes, zero disables
+	pruned     map[string]time.Time // pruned session ID -> progress file mtime, skipped by discovery until modified
+	metrics    *Metrics             // shared by all sessions of the manager and its watcher
 }
 
 // NewSessionManager creates a new session manager with an empty registry.
 func NewSessionManager() *SessionManager {
 	return &SessionManager{
 		sessions: make(map[string]*Session),
+		pruned:   make(map[string]time.Time),
+		metrics:  NewMetrics(),
 	}
 }
 
-// Discover scans a directory for progress files matching progress-*.txt pattern.
+// Metrics returns the metrics collector shared by the manager's sessions.
+func (m *SessionManager) Metrics() *Metrics {
+	return m.metrics
+}
+
+// SetPruneAfter sets the age after which stopped sessions are dropped by Prune, zero disables pruning.
+func (m *SessionManager) SetPruneAfter(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneAfter = d
+}
+
+// SetIdleAfter sets the time without progress file writes after which RefreshStates marks a running
+// session completed even though its file is still locked, zero disables it.
+func (m *SessionManager) SetIdleAfter(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.idleAfter = d
+}
+
+// Discover scans a directory for progress files matching progress-*.txt pattern, skipping rotated backups.
 // for each file found, it creates or updates a session in the registry.
 // returns the list of discovered session IDs.
 func (m *SessionManager) Discover(dir string) ([]string, error) {
@@ -51,6 +78,9 @@ func (m *SessionManager) Discover(dir string) ([]string, error) {
 
 	ids := make([]string, 0, len(matches))
 	for _, path := range matches {
+		if progress.IsBackupPath(path) {
+			continue
+		}
 		id := sessionIDFromPath(path)
 		ids = append(ids, id)
 
@@ -59,6 +89,15 @@ func (m *SessionManager) Discover(dir string) ([]string, error) {
 		existing := m.sessions[id]
 		m.mu.RUnlock()
 
+		// a deleted progress file showed up again (e.g. after log rotation), start over with a fresh session
+		if existing != nil && existing.IsRemoved() {
+			m.Remove(id)
+			existing = nil
+		}
+		if existing == nil && m.isPruned(id, path) {
+			continue
+		}
+
 		if existing != nil {
 			// update existing session state
 			if err := m.updateSession(existing); err != nil {
@@ -68,6 +107,7 @@ func (m *SessionManager) Discover(dir string) ([]string, error) {
 		} else {
 			// create new session
 			session := NewSession(id, path)
+			session.SetMetrics(m.metrics)
 			if err := m.updateSession(session); err != nil {
 				log.Printf("[WARN] failed to create session %s: %v", id, err)
 				continue
@@ -100,7 +140,7 @@ func (m *SessionManager) DiscoverRecursive(root string) ([]string, error) {
 		}
 
 		// skip directories that typically contain many subdirs and no progress files
-		if d.IsDir() && skipDirs[d.Name()] && path != root {
+		if d.IsDir() && isIgnored(d.Name(), defaultWatchIgnore) && path != root {
 			return filepath.SkipDir
 		}
 
@@ -153,6 +193,11 @@ func (m *SessionManager) updateSession(session *Session) error {
 	if active {
 		newState = SessionStateActive
 	}
+	// a tailed session marked completed while its file is still locked (completion footer written or idle)
+	// keeps the state, the tailer turns it active again on new output
+	if active && prevState == SessionStateCompleted && session.IsTailing() {
+		newState = prevState
+	}
 	session.SetState(newState)
 
 	// handle state transitions for tailing
@@ -169,9 +214,9 @@ func (m *SessionManager) updateSession(session *Session) error {
 	}
 
 	// for completed sessions that haven't been loaded yet, load the file content once
-	// this handles sessions discovered after they finished.
+	// this handles sessions discovered after they finished, a tailed one already has its content.
 	// MarkLoadedIfNot is atomic to prevent double-loading from concurrent goroutines.
-	if newState == SessionStateCompleted && session.MarkLoadedIfNot() {
+	if newState == SessionStateCompleted && !session.IsTailing() && session.MarkLoadedIfNot() {
 		m.loadProgressFileIntoSession(session.Path, session)
 	}
 
@@ -187,11 +232,39 @@ func (m *SessionManager) updateSession(session *Session) error {
 	if err != nil {
 		return fmt.Errorf("stat file: %w", err)
 	}
+
+	// read the outcome of a stopped session, only when the file changed since the last update
+	if newState == SessionStateCompleted && (prevState != newState || !info.ModTime().Equal(session.GetLastModified())) {
+		session.SetOutcome(progressOutcome(session.Path))
+	}
 	session.SetLastModified(info.ModTime())
 
 	return nil
 }
 
+// isIdle returns true if a progress file last modified at mtime had no writes for the idle window.
+func (m *SessionManager) isIdle(mtime, now time.Time) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.idleAfter > 0 && !mtime.IsZero() && now.Sub(mtime) >= m.idleAfter
+}
+
+// isPruned returns true if the session was pruned and its progress file hasn't changed since.
+// a modified file clears the pruned mark, so the session is discovered again.
+func (m *SessionManager) isPruned(id, path string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mtime, ok := m.pruned[id]
+	if !ok {
+		return false
+	}
+	if info, err := os.Stat(path); err == nil && info.ModTime().Equal(mtime) {
+		return true
+	}
+	delete(m.pruned, id)
+	return false
+}
+
 // Get returns a session by ID, or nil if not found.
 func (m *SessionManager) Get(id string) *Session {
 	m.mu.RLock()
@@ -211,6 +284,59 @@ func (m *SessionManager) All() []*Session {
 	return result
 }
 
+// SessionFilter selects sessions by branch, plan and status, empty fields match any session.
+type SessionFilter struct {
+	Branch string // substring of the branch name, case-insensitive
+	Plan   string // substring of the plan file name, case-insensitive
+	Status string // "running" or "completed" for the session state, otherwise a SessionStatus value
+}
+
+// sessionFilterStates maps the state names accepted by SessionFilter.Status to session states.
+var sessionFilterStates = map[string]SessionState{"running": SessionStateActive, "completed": SessionStateCompleted}
+
+// Validate checks that the status is a known state or status name.
+func (f SessionFilter) Validate() error {
+	switch SessionStatus(f.Status) {
+	case "", SessionStatusLive, SessionStatusIdle, SessionStatusFinished, SessionStatusFailed, SessionStatusRemoved:
+		return nil
+	}
+	if _, ok := sessionFilterStates[f.Status]; ok {
+		return nil
+	}
+	return fmt.Errorf("unknown status %q, expected running, completed, live, idle, finished, failed or removed", f.Status)
+}
+
+// Match reports whether the session matches the filter as of now.
+func (f SessionFilter) Match(session *Session, now time.Time) bool {
+	meta := session.GetMetadata()
+	if f.Branch != "" && !strings.Contains(strings.ToLower(meta.Branch), strings.ToLower(f.Branch)) {
+		return false
+	}
+	if f.Plan != "" && (meta.PlanPath == "" ||
+		!strings.Contains(strings.ToLower(filepath.Base(meta.PlanPath)), strings.ToLower(f.Plan))) {
+		return false
+	}
+	if f.Status == "" {
+		return true
+	}
+	if state, ok := sessionFilterStates[f.Status]; ok {
+		return session.GetState() == state
+	}
+	return session.Status(now) == SessionStatus(f.Status)
+}
+
+// Filter returns the sessions matching the filter.
+func (m *SessionManager) Filter(f SessionFilter) []*Session {
+	now := time.Now()
+	var result []*Session
+	for _, s := range m.All() {
+		if f.Match(s, now) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
 // Remove removes a session from the registry and closes its resources.
 func (m *SessionManager) Remove(id string) {
 	m.mu.Lock()
@@ -222,6 +348,51 @@ func (m *SessionManager) Remove(id string) {
 	}
 }
 
+// MarkRemoved marks a session as removed after its progress file was deleted on disk.
+// the session stays listed with its content until pruned or its file shows up again.
+func (m *SessionManager) MarkRemoved(id string) {
+	session := m.Get(id)
+	if session == nil {
+		return
+	}
+	session.StopTailing()
+	session.SetRemoved(true)
+}
+
+// Prune drops sessions that stopped (progress file not locked) or were removed and haven't been
+// modified within the prune age. running sessions are never pruned. no-op if pruning is disabled.
+// pruned sessions are not discovered again until their progress file changes.
+func (m *SessionManager) Prune(now time.Time) {
+	m.mu.RLock()
+	pruneAfter := m.pruneAfter
+	sessions := make([]*Session, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		sessions = append(sessions, s)
+	}
+	m.mu.RUnlock()
+
+	if pruneAfter <= 0 {
+		return
+	}
+
+	for _, session := range sessions {
+		mtime := session.GetLastModified()
+		if mtime.IsZero() || now.Sub(mtime) < pruneAfter {
+			continue
+		}
+		if active, err := IsActive(session.Path); err == nil && active {
+			continue
+		}
+		m.mu.Lock()
+		if m.sessions[session.ID] == session {
+			session.Close()
+			delete(m.sessions, session.ID)
+			m.pruned[session.ID] = mtime
+		}
+		m.mu.Unlock()
+	}
+}
+
 // Register adds an externally-created session to the manager.
 // This is used when a session is created for live execution (BroadcastLogger)
 // and needs to be visible in the multi-session dashboard.
@@ -229,6 +400,7 @@ func (m *SessionManager) Remove(id string) {
 func (m *SessionManager) Register(session *Session) {
 	id := sessionIDFromPath(session.Path)
 	session.ID = id // ensure ID matches what SessionManager expects
+	session.SetMetrics(m.metrics)
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -303,8 +475,10 @@ func (m *SessionManager) StartTailingActive() {
 	}
 }
 
-// RefreshStates checks all sessions for state changes (active->completed).
-// stops tailing for sessions that have completed.
+// RefreshStates checks all sessions for state changes (active->completed) and deleted progress files.
+// stops tailing for sessions that have completed and reads their outcome from the progress log.
+// a session still locked but without writes for the idle window is marked completed as idle,
+// it keeps tailing and turns active again on the next write.
 func (m *SessionManager) RefreshStates() {
 	m.mu.RLock()
 	sessions := make([]*Session, 0, len(m.sessions))
@@ -314,6 +488,18 @@ func (m *SessionManager) RefreshStates() {
 	m.mu.RUnlock()
 
 	for _, session := range sessions {
+		if session.IsRemoved() {
+			continue
+		}
+		info, err := os.Stat(session.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			m.MarkRemoved(session.ID)
+			continue
+		}
+		if err == nil {
+			session.SetLastModified(info.ModTime())
+		}
+
 		// only check sessions that are currently tailing
 		if !session.IsTailing() {
 			continue
@@ -329,6 +515,12 @@ func (m *SessionManager) RefreshStates() {
 			// session completed, update state and stop tailing
 			session.SetState(SessionStateCompleted)
 			session.StopTailing()
+			session.SetOutcome(progressOutcome(session.Path))
+			continue
+		}
+
+		if session.GetState() == SessionStateActive && m.isIdle(session.GetLastModified(), time.Now()) {
+			session.SetStatus(SessionStatusIdle)
 		}
 	}
 }
@@ -383,6 +575,36 @@ func IsActive(path string) (bool, error) {
 	return !gotLock, nil
 }
 
+// progressFooterPrefix starts the footer line written to the progress file when the run ends.
+const progressFooterPrefix = "Completed: "
+
+// progressOutcome scans a progress file for the result of a stopped run.
+// returns SessionStatusFailed if the log has the FAILED signal, SessionStatusFinished if it has
+// the "Completed:" footer written when the run ends, and empty otherwise (killed run or unreadable file).
+func progressOutcome(path string) SessionStatus {
+	f, err := os.Open(path) //nolint:gosec // path from user-controlled glob pattern, acceptable for session discovery
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, executor.MaxScannerBuffer)
+
+	var outcome SessionStatus
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.Contains(line, "<<<RALPHEX:") && extractSignalFromText(line) == "FAILED" {
+			return SessionStatusFailed
+		}
+		if strings.HasPrefix(line, progressFooterPrefix) {
+			outcome = SessionStatusFinished
+		}
+	}
+	return outcome
+}
+
 // ParseProgressHeader reads the header section of a progress file and extracts metadata.
 // the header format is:
 //
@@ -450,61 +672,20 @@ func (m *SessionManager) loadProgressFileIntoSession(path string, session *Sessi
 	// increase buffer size for large lines (matching executor)
 	buf := make([]byte, 0, 64*1024)
 	scanner.Buffer(buf, executor.MaxScannerBuffer)
-	inHeader := true
-	phase := status.PhaseTask
-	var pendingSection string // section header waiting for first timestamped event
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
-		}
-
-		parsed, newInHeader := parseProgressLine(line, inHeader)
-		inHeader = newInHeader
-
-		switch parsed.Type {
-		case ParsedLineSkip:
-			continue
-		case ParsedLineSection:
-			if pendingSection != "" {
-				m.emitPendingSection(session, pendingSection, phase, time.Now())
-			}
-			phase = parsed.Phase
-			// defer emitting section until we see a timestamped event
-			pendingSection = parsed.Section
-		case ParsedLineTimestamp:
-			// emit pending section with this event's timestamp (for accurate durations)
-			if pendingSection != "" {
-				m.emitPendingSection(session, pendingSection, phase, parsed.Timestamp)
-				pendingSection = ""
-			}
-			event := Event{
-				Type:      parsed.EventType,
-				Phase:     phase,
-				Text:      parsed.Text,
-				Timestamp: parsed.Timestamp,
-				Signal:    parsed.Signal,
-			}
+	reader := newProgressEventReader(true, status.PhaseTask)
+	publish := func(events []Event) {
+		for _, event := range events {
 			if event.Type == EventTypeOutput {
-				if stats, ok := parseDiffStats(event.Text); ok {
-					session.SetDiffStats(stats)
-				}
+				session.recordOutputStats(event.Text)
 			}
 			_ = session.Publish(event)
-		case ParsedLinePlain:
-			_ = session.Publish(Event{
-				Type:      EventTypeOutput,
-				Phase:     phase,
-				Text:      parsed.Text,
-				Timestamp: time.Now(),
-			})
 		}
 	}
 
-	if pendingSection != "" {
-		m.emitPendingSection(session, pendingSection, phase, time.Now())
+	for scanner.Scan() {
+		publish(reader.line(scanner.Text()))
 	}
+	publish(reader.flush())
 }
 
 // phaseFromSection determines the phase from a section name.
@@ -524,36 +705,3 @@ func phaseFromSection(name string) status.Phase {
 		return status.PhaseTask
 	}
 }
-
-// emitPendingSection publishes section and task_start events for a pending section.
-// task_start is emitted before section for task iteration sections.
-func (m *SessionManager) emitPendingSection(session *Session, sectionName string, phase status.Phase, ts time.Time) {
-	// emit task_start event for task iteration sections
-	if matches := taskIterationRegex.FindStringSubmatch(sectionName); matches != nil {
-		taskNum, err := strconv.Atoi(matches[1])
-		if err != nil {
-			// log parse error but continue - section will still be emitted
-			log.Printf("[WARN] failed to parse task number from section %q: %v", sectionName, err)
-		} else {
-			if err := session.Publish(Event{
-				Type:      EventTypeTaskStart,
-				Phase:     phase,
-				TaskNum:   taskNum,
-				Text:      sectionName,
-				Timestamp: ts,
-			}); err != nil {
-				log.Printf("[WARN] failed to publish task_start event: %v", err)
-			}
-		}
-	}
-
-	if err := session.Publish(Event{
-		Type:      EventTypeSection,
-		Phase:     phase,
-		Section:   sectionName,
-		Text:      sectionName,
-		Timestamp: ts,
-	}); err != nil {
-		log.Printf("[WARN] failed to publish section event: %v", err)
-	}
-}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
@@ -73,9 +74,10 @@ func TestSessionManager_Discover(t *testing.T) {
 		require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("test"), 0o600))
 		require.NoError(t, os.WriteFile(filepath.Join(dir, "progress.txt"), []byte("test"), 0o600))
 
-		// create matching file
+		// create matching file and its rotated backup
 		path := filepath.Join(dir, "progress-valid.txt")
 		createProgressFile(t, path, "plan.md", "main", "full")
+		createProgressFile(t, filepath.Join(dir, "progress-valid.1.txt"), "plan.md", "main", "full")
 
 		m := NewSessionManager()
 		ids, err := m.Discover(dir)
@@ -206,6 +208,63 @@ func TestSessionManager_All(t *testing.T) {
 	assert.Len(t, all, 2)
 }
 
+func TestSessionManager_Filter(t *testing.T) {
+	dir := t.TempDir()
+	createProgressFile(t, filepath.Join(dir, "progress-auth.txt"), "docs/plans/add-auth.md", "feature/auth", "full")
+	createProgressFile(t, filepath.Join(dir, "progress-cache.txt"), "docs/plans/add-cache.md", "feature/cache", "full")
+	createProgressFile(t, filepath.Join(dir, "progress-fix.txt"), "docs/plans/Fix-Tests.md", "main", "full")
+	createProgressFile(t, filepath.Join(dir, "progress-review.txt"), "(no plan - review only)", "Feature/Review", "review")
+
+	m := NewSessionManager()
+	defer m.Close()
+	_, err := m.Discover(dir)
+	require.NoError(t, err)
+
+	// the auth run is still going, the fix run finished, the others were killed (idle)
+	running := m.Get(sessionIDFromPath(filepath.Join(dir, "progress-auth.txt")))
+	running.SetState(SessionStateActive)
+	running.SetLastModified(time.Now())
+	m.Get(sessionIDFromPath(filepath.Join(dir, "progress-fix.txt"))).SetOutcome(SessionStatusFinished)
+
+	tests := []struct {
+		name   string
+		filter SessionFilter
+		want   []string
+	}{
+		{name: "no criteria", want: []string{"auth", "cache", "fix", "review"}},
+		{name: "branch substring", filter: SessionFilter{Branch: "feature"}, want: []string{"auth", "cache", "review"}},
+		{name: "branch case-insensitive", filter: SessionFilter{Branch: "REVIEW"}, want: []string{"review"}},
+		{name: "plan name", filter: SessionFilter{Plan: "add-"}, want: []string{"auth", "cache"}},
+		{name: "plan case-insensitive", filter: SessionFilter{Plan: "fix-tests"}, want: []string{"fix"}},
+		{name: "plan matches file name only", filter: SessionFilter{Plan: "docs"}},
+		{name: "running", filter: SessionFilter{Status: "running"}, want: []string{"auth"}},
+		{name: "completed", filter: SessionFilter{Status: "completed"}, want: []string{"cache", "fix", "review"}},
+		{name: "status value", filter: SessionFilter{Status: "finished"}, want: []string{"fix"}},
+		{name: "idle", filter: SessionFilter{Status: "idle"}, want: []string{"cache", "review"}},
+		{name: "combined", filter: SessionFilter{Branch: "feature", Status: "completed"}, want: []string{"cache", "review"}},
+		{name: "nothing matches", filter: SessionFilter{Branch: "release"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, s := range m.Filter(tc.filter) {
+				got = append(got, strings.TrimSuffix(strings.TrimPrefix(filepath.Base(s.Path), "progress-"), ".txt"))
+			}
+			sort.Strings(got)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSessionFilter_Validate(t *testing.T) {
+	for _, status := range []string{"", "running", "completed", "live", "idle", "finished", "failed", "removed"} {
+		assert.NoError(t, SessionFilter{Status: status}.Validate(), status)
+	}
+	err := SessionFilter{Status: "done"}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown status "done"`)
+}
+
 func TestSessionManager_Remove(t *testing.T) {
 	dir := t.TempDir()
 	createProgressFile(t, filepath.Join(dir, "progress-test.txt"), "plan.md", "main", "full")
@@ -825,6 +884,141 @@ func TestSessionManager_RefreshStates(t *testing.T) {
 	})
 }
 
+func TestSessionManager_RefreshStates_Idle(t *testing.T) {
+	dir := t.TempDir()
+	// a running progress logger holds the lock on its file
+	logger, err := progress.NewLogger(progress.Config{PlanFile: "live.md", Mode: "full", Branch: "main", Dir: dir},
+		testColors(), &status.PhaseHolder{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = logger.Close() })
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(logger.Path(), old, old))
+
+	m := NewSessionManager()
+	t.Cleanup(m.Close)
+	_, err = m.Discover(dir)
+	require.NoError(t, err)
+	session := m.Get(sessionIDFromPath(logger.Path()))
+	require.NotNil(t, session)
+	require.Equal(t, SessionStateActive, session.GetState())
+
+	m.RefreshStates()
+	assert.Equal(t, SessionStateActive, session.GetState(), "idle window is disabled by default")
+
+	m.SetIdleAfter(30 * time.Minute)
+	m.RefreshStates()
+	assert.Equal(t, SessionStateCompleted, session.GetState())
+	assert.Equal(t, SessionStatusIdle, session.Status(time.Now()))
+	assert.True(t, session.IsTailing(), "idle session keeps tailing")
+
+	// discovery keeps it completed although the file is still locked
+	_, err = m.Discover(dir)
+	require.NoError(t, err)
+	assert.Equal(t, SessionStateCompleted, session.GetState())
+
+	// new output turns it active again
+	logger.Print("working on task 2")
+	require.Eventually(t, func() bool { return session.GetState() == SessionStateActive }, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestSessionManager_RefreshStates_DeletedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "progress-test.txt")
+	createProgressFile(t, path, "plan.md", "main", "full")
+
+	m := NewSessionManager()
+	_, err := m.Discover(dir)
+	require.NoError(t, err)
+	session := m.Get(sessionIDFromPath(path))
+	require.NotNil(t, session)
+
+	require.NoError(t, os.Remove(path))
+	m.RefreshStates()
+
+	assert.True(t, session.IsRemoved())
+	assert.Equal(t, SessionStatusRemoved, session.Status(time.Now()))
+	assert.Same(t, session, m.Get(session.ID), "removed session stays listed")
+}
+
+func TestSessionManager_DiscoverSetsOutcome(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want SessionStatus
+	}{
+		{name: "finished", body: "[26-01-22 10:00:01] all phases completed successfully\n\n" +
+			"------------------------------------------------------------\nCompleted: 2026-01-22 10:05:00 (5m0s)\n",
+			want: SessionStatusFinished},
+		{name: "failed", body: "[26-01-22 10:00:01] <<<RALPHEX:TASK_FAILED>>>\n\n" +
+			"------------------------------------------------------------\nCompleted: 2026-01-22 10:05:00 (5m0s)\n",
+			want: SessionStatusFailed},
+		{name: "killed without footer", body: "[26-01-22 10:00:01] working on task 1\n", want: SessionStatusIdle},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "progress-test.txt")
+			createProgressFile(t, path, "plan.md", "main", "full")
+			f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // test file
+			require.NoError(t, err)
+			_, err = f.WriteString(tc.body)
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+
+			m := NewSessionManager()
+			_, err = m.Discover(dir)
+			require.NoError(t, err)
+			session := m.Get(sessionIDFromPath(path))
+			require.NotNil(t, session)
+			assert.Equal(t, tc.want, session.Status(time.Now()))
+		})
+	}
+}
+
+func TestSessionManager_Prune(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "progress-old.txt")
+	newPath := filepath.Join(dir, "progress-new.txt")
+	createProgressFile(t, oldPath, "plan.md", "main", "full")
+	createProgressFile(t, newPath, "plan.md", "main", "full")
+
+	// a running progress logger holds the lock on its file
+	logger, err := progress.NewLogger(progress.Config{PlanFile: "live.md", Mode: "full", Branch: "main", Dir: dir},
+		testColors(), &status.PhaseHolder{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = logger.Close() })
+	livePath := logger.Path()
+
+	old := time.Now().Add(-3 * time.Hour)
+	require.NoError(t, os.Chtimes(oldPath, old, old))
+	require.NoError(t, os.Chtimes(livePath, old, old))
+
+	m := NewSessionManager()
+	_, err = m.Discover(dir)
+	require.NoError(t, err)
+	require.Len(t, m.All(), 3)
+
+	m.Prune(time.Now())
+	assert.Len(t, m.All(), 3, "pruning is disabled by default")
+
+	m.SetPruneAfter(time.Hour)
+	m.Prune(time.Now())
+	assert.Nil(t, m.Get(sessionIDFromPath(oldPath)), "old stopped session is pruned")
+	assert.NotNil(t, m.Get(sessionIDFromPath(newPath)), "recent stopped session is kept")
+	assert.NotNil(t, m.Get(sessionIDFromPath(livePath)), "running session is never pruned")
+
+	// unchanged file is not discovered again, a modified one is
+	_, err = m.Discover(dir)
+	require.NoError(t, err)
+	assert.Nil(t, m.Get(sessionIDFromPath(oldPath)))
+
+	require.NoError(t, os.Chtimes(oldPath, time.Now(), time.Now()))
+	_, err = m.Discover(dir)
+	require.NoError(t, err)
+	assert.NotNil(t, m.Get(sessionIDFromPath(oldPath)))
+}
+
 func testColors() *progress.Colors {
 	return progress.NewColors(config.ColorConfig{
 		Task:       "0,255,0",
@@ -62,6 +62,25 @@ func TestSession_State(t *testing.T) {
 	assert.Equal(t, SessionStateCompleted, s.GetState())
 }
 
+func TestSession_PhaseChanges(t *testing.T) {
+	s := NewSession("test", "/tmp/test.txt")
+	assert.Empty(t, s.GetPhaseChanges())
+
+	s.recordOutputStats("task phase: 2 commits, 3 files changed (+10/-4)")
+	s.recordOutputStats("review phase: no commits, HEAD unchanged")
+	s.recordOutputStats("some other output")
+	s.recordOutputStats("task phase: 4 commits, 5 files changed (+20/-8)") // repeated phase replaces the earlier one
+
+	got := s.GetPhaseChanges()
+	assert.Equal(t, []PhaseChanges{
+		{Phase: "task", Commits: 4, Files: 5, Additions: 20, Deletions: 8},
+		{Phase: "review", HeadUnchanged: true},
+	}, got)
+
+	got[0].Commits = 100
+	assert.Equal(t, 4, s.GetPhaseChanges()[0].Commits, "returned slice is a copy")
+}
+
 func TestSession_LastModified(t *testing.T) {
 	s := NewSession("test", "/tmp/test.txt")
 
@@ -73,6 +92,65 @@ func TestSession_LastModified(t *testing.T) {
 	assert.Equal(t, now, s.GetLastModified())
 }
 
+func TestSession_Status(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name         string
+		state        SessionState
+		lastModified time.Time
+		outcome      SessionStatus
+		removed      bool
+		want         SessionStatus
+	}{
+		{name: "active with recent writes", state: SessionStateActive, lastModified: now.Add(-time.Minute), want: SessionStatusLive},
+		{name: "active without modification time", state: SessionStateActive, want: SessionStatusLive},
+		{name: "active without writes", state: SessionStateActive, lastModified: now.Add(-IdleTimeout), want: SessionStatusIdle},
+		{name: "completed finished", state: SessionStateCompleted, outcome: SessionStatusFinished, want: SessionStatusFinished},
+		{name: "completed failed", state: SessionStateCompleted, outcome: SessionStatusFailed, want: SessionStatusFailed},
+		{name: "completed without outcome", state: SessionStateCompleted, lastModified: now, want: SessionStatusIdle},
+		{name: "removed wins", state: SessionStateActive, outcome: SessionStatusFinished, removed: true, want: SessionStatusRemoved},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewSession("test", "/tmp/test.txt")
+			s.SetState(tc.state)
+			s.SetLastModified(tc.lastModified)
+			s.SetOutcome(tc.outcome)
+			s.SetRemoved(tc.removed)
+			assert.Equal(t, tc.want, s.Status(now))
+		})
+	}
+}
+
+func TestSession_SetStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      SessionStatus
+		want        SessionStatus
+		wantResumed bool
+	}{
+		{name: "finished", status: SessionStatusFinished, want: SessionStatusFinished},
+		{name: "failed", status: SessionStatusFailed, want: SessionStatusFailed},
+		{name: "idle", status: SessionStatusIdle, want: SessionStatusIdle, wantResumed: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewSession("test", "/tmp/test.txt")
+			s.SetState(SessionStateActive)
+			s.SetOutcome(SessionStatusFailed) // stale outcome of an earlier run is replaced
+			s.SetStatus(tc.status)
+			assert.Equal(t, SessionStateCompleted, s.GetState())
+			assert.Equal(t, tc.want, s.Status(time.Now()))
+
+			// new output turns only an idle session active again
+			s.resumeIfIdle()
+			assert.Equal(t, tc.wantResumed, s.GetState() == SessionStateActive)
+		})
+	}
+}
+
 func TestSession_Close(t *testing.T) {
 	s := NewSession("test", "/tmp/test.txt")
 	defer s.Close()
@@ -91,6 +169,99 @@ func TestSession_Publish(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestSession_EventsSince(t *testing.T) {
+	s := NewSession("test", "/tmp/test.txt")
+	defer s.Close()
+
+	_, ok := s.LastEvent()
+	assert.False(t, ok)
+	assert.Empty(t, s.EventsSince(0))
+
+	for _, text := range []string{"one", "two", "three"} {
+		require.NoError(t, s.Publish(NewOutputEvent("task", text)))
+	}
+
+	events := s.EventsSince(0)
+	require.Len(t, events, 3)
+	assert.Equal(t, int64(1), events[0].Seq)
+	assert.Equal(t, "one", events[0].Event.Text)
+
+	events = s.EventsSince(2)
+	require.Len(t, events, 1)
+	assert.Equal(t, int64(3), events[0].Seq)
+	assert.Equal(t, "three", events[0].Event.Text)
+
+	assert.Empty(t, s.EventsSince(3))
+
+	last, ok := s.LastEvent()
+	require.True(t, ok)
+	assert.Equal(t, int64(3), last.Seq)
+	assert.Equal(t, "three", last.Event.Text)
+}
+
+func TestSession_EventsSince_BufferLimit(t *testing.T) {
+	s := NewSession("test", "/tmp/test.txt")
+	defer s.Close()
+
+	for range DefaultReplayerSize + 5 {
+		require.NoError(t, s.Publish(NewOutputEvent("task", "line")))
+	}
+
+	// oldest events are evicted, sequence numbers keep growing
+	events := s.EventsSince(0)
+	require.Len(t, events, DefaultReplayerSize)
+	assert.Equal(t, int64(6), events[0].Seq)
+	assert.Equal(t, int64(DefaultReplayerSize+5), events[len(events)-1].Seq)
+}
+
+func TestSession_Subscribe(t *testing.T) {
+	s := NewSession("test", "/tmp/test.txt")
+	defer s.Close()
+	for _, text := range []string{"one", "two"} {
+		require.NoError(t, s.Publish(NewOutputEvent("task", text)))
+	}
+
+	history, events, unsubscribe := s.Subscribe(1)
+	require.Len(t, history, 1)
+	assert.Equal(t, "two", history[0].Event.Text)
+
+	require.NoError(t, s.Publish(NewOutputEvent("task", "three")))
+	e := <-events
+	assert.Equal(t, int64(3), e.Seq)
+	assert.Equal(t, "three", e.Event.Text)
+
+	unsubscribe()
+	_, ok := <-events
+	assert.False(t, ok, "channel closed by unsubscribe")
+	unsubscribe() // second call is a no-op
+	require.NoError(t, s.Publish(NewOutputEvent("task", "four")))
+}
+
+func TestSession_Subscribe_SlowSubscriberDropped(t *testing.T) {
+	s := NewSession("test", "/tmp/test.txt")
+	defer s.Close()
+	_, events, unsubscribe := s.Subscribe(0)
+	defer unsubscribe()
+
+	for range subscriberBuffer + 1 {
+		require.NoError(t, s.Publish(NewOutputEvent("task", "line")))
+	}
+	received := 0
+	for range events {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received, "channel closed after the buffered events")
+}
+
+func TestSession_Subscribe_ClosedWithSession(t *testing.T) {
+	s := NewSession("test", "/tmp/test.txt")
+	_, events, unsubscribe := s.Subscribe(0)
+	s.Close()
+	_, ok := <-events
+	assert.False(t, ok)
+	unsubscribe() // no-op after close
+}
+
 func TestSession_MarkLoadedIfNot(t *testing.T) {
 	t.Run("returns true on first call", func(t *testing.T) {
 		s := NewSession("test", "/tmp/test.txt")
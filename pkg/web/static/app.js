@@ -14,6 +14,16 @@
     const statusBadge = document.getElementById('status-badge');
     const elapsedTimeEl = document.getElementById('elapsed-time');
     const diffStatsEl = document.getElementById('diff-stats');
+    const tokenUsageEl = document.getElementById('token-usage');
+    const phaseChangesEl = document.getElementById('phase-changes');
+    const taskProgressEl = document.getElementById('task-progress');
+    const questionPanel = document.getElementById('question-panel');
+    const questionTextEl = document.getElementById('question-text');
+    const questionOptionsEl = document.getElementById('question-options');
+    const questionForm = document.getElementById('question-custom');
+    const questionInput = document.getElementById('question-input');
+    const questionTextarea = document.getElementById('question-textarea');
+    const questionErrorEl = document.getElementById('question-error');
     const searchInput = document.getElementById('search');
     const scrollIndicator = document.getElementById('scroll-indicator');
     const scrollToBottomBtn = document.getElementById('scroll-to-bottom');
@@ -23,11 +33,24 @@
     const planToggle = document.getElementById('plan-toggle');
     const planContent = document.getElementById('plan-content');
     const exportBtn = document.getElementById('export-btn');
+    const downloadBtn = document.getElementById('download-btn');
+    const pauseBtn = document.getElementById('pause-btn');
     const expandAllBtn = document.getElementById('expand-all');
     const collapseAllBtn = document.getElementById('collapse-all');
     const helpOverlay = document.getElementById('help-overlay');
     const helpCloseBtn = document.getElementById('help-close');
     const helpBtn = document.getElementById('help-btn');
+    const historyBtn = document.getElementById('history-btn');
+    const historyOverlay = document.getElementById('history-overlay');
+    const historyList = document.getElementById('history-list');
+    const historyCloseBtn = document.getElementById('history-close');
+    const replaySummary = document.getElementById('replay-summary');
+    const replayModeEl = document.getElementById('replay-mode');
+    const replayBranchEl = document.getElementById('replay-branch');
+    const replayElapsedEl = document.getElementById('replay-elapsed');
+    const replayTokensEl = document.getElementById('replay-tokens');
+    const replayProgressEl = document.getElementById('replay-progress');
+    const replayCloseBtn = document.getElementById('replay-close');
 
     // session sidebar elements
     const sessionSidebar = document.getElementById('session-sidebar');
@@ -45,6 +68,9 @@
     var SSE_INITIAL_RECONNECT_MS = 1000;
     var SSE_MAX_RECONNECT_MS = 30000;
 
+    // stream over /ws instead of /events, enabled by the web_websocket config
+    var USE_WEBSOCKET = document.body.dataset.websocket === 'true';
+
     // session polling interval
     var SESSION_POLL_INTERVAL_MS = 5000;
 
@@ -70,6 +96,7 @@
         currentSection: null,
         searchTerm: '',
         searchTimeout: null,
+        phaseChanges: [], // change summaries of finished phases, see updatePhaseChanges
         planCollapsed: localStorage.getItem('planCollapsed') === 'true',
         sidebarCollapsed: localStorage.getItem('sidebarCollapsed') === 'true',
         sessionViewMode: normalizeViewMode(localStorage.getItem('sessionViewMode')),
@@ -80,6 +107,9 @@
         currentSessionId: null,
         currentSession: null,
         sessionPollInterval: null,
+        paused: false, // live run paused from the dashboard
+        stopping: false, // live run stops after its current iteration (Ctrl+C in the terminal)
+        replay: null, // past run replayed from /api/history instead of the live stream: {entry, offset, phase}
 
         // timing state
         executionStartTime: null,
@@ -97,7 +127,7 @@
 
         // SSE connection state
         reconnectDelay: SSE_INITIAL_RECONNECT_MS,
-        currentEventSource: null,
+        currentEventSource: null, // EventSource, or WebSocket with USE_WEBSOCKET
         isFirstConnect: true,
         resetOnNextEvent: false,
 
@@ -272,6 +302,12 @@
     var TASK_ITERATION_PATTERN = /^task iteration \d+$/i;
     var TASK_ITERATION_NUMBER_PATTERN = /^task iteration (\d+)$/i;
     var DIFF_STATS_PATTERN = /^DIFFSTATS:\s*files=(\d+)\s+additions=(\d+)\s+deletions=(\d+)\s*$/i;
+    var TOKEN_USAGE_PATTERN = /^tokens: .*, run total (.+)$/;
+    var TASK_PROGRESS_PATTERN = /^(?:plan progress: |task completed: .* \()(\d+)\/(\d+) done\)?$/;
+    var PHASE_COMMITS_PATTERN = /^([a-z-]+) phase: (\d+) commits?, (\d+) files? changed \(\+(\d+)\/-(\d+)\)$/;
+    var PHASE_UNCHANGED_PATTERN = /^([a-z-]+) phase: no commits, HEAD unchanged$/;
+    var PHASE_REWRITTEN_PATTERN =
+        /^([a-z-]+) phase: history rewritten \(rebase or amend\), (\d+) files? with uncommitted changes \(\+(\d+)\/-(\d+)\)$/;
 
     // check if section text is a task iteration pattern
     function isTaskIteration(sectionText) {
@@ -326,6 +362,155 @@
         diffStatsEl.title = text;
     }
 
+    // show the running token total from the last "tokens: ..., run total ..." line, empty text hides it
+    function updateTokenUsage(total) {
+        if (!tokenUsageEl) return;
+        tokenUsageEl.textContent = total ? 'tokens ' + total : '';
+        tokenUsageEl.title = total ? 'token usage of this run: ' + total : '';
+    }
+
+    // describe one phase change summary the way the run printed it
+    function formatPhaseChange(pc) {
+        if (pc.headUnchanged) return pc.phase + ' phase: no commits, HEAD unchanged';
+        var lines = '(+' + (pc.additions || 0) + '/-' + (pc.deletions || 0) + ')';
+        if (pc.rewritten) {
+            return pc.phase + ' phase: history rewritten, ' + (pc.files || 0) + ' files with uncommitted changes ' + lines;
+        }
+        return pc.phase + ' phase: ' + (pc.commits || 0) + ' commits, ' + (pc.files || 0) + ' files changed ' + lines;
+    }
+
+    // show the commits and files changed by each finished phase, empty list hides it
+    function updatePhaseChanges(phases) {
+        if (!phaseChangesEl) return;
+        phaseChangesEl.textContent = '';
+        if (!phases || phases.length === 0) return;
+        phases.forEach(function(pc) {
+            var item = document.createElement('span');
+            item.className = 'phase-change';
+            item.title = formatPhaseChange(pc);
+
+            var name = document.createElement('span');
+            name.className = 'phase-change-name';
+            name.textContent = pc.phase;
+            item.appendChild(name);
+
+            var summary = document.createElement('span');
+            if (pc.headUnchanged) {
+                summary.className = 'phase-change-none';
+                summary.textContent = 'no commits';
+            } else {
+                summary.className = pc.rewritten ? 'phase-change-rewritten' : 'phase-change-counts';
+                summary.textContent = (pc.rewritten ? 'rewritten ' : pc.commits + 'c ') + pc.files + 'f +' +
+                    pc.additions + '/-' + pc.deletions;
+            }
+            item.appendChild(summary);
+            phaseChangesEl.appendChild(item);
+        });
+    }
+
+    // store a phase change summary, replacing an earlier one of the same phase as the server does
+    function addPhaseChange(pc) {
+        state.phaseChanges = state.phaseChanges.filter(function(p) { return p.phase !== pc.phase; });
+        state.phaseChanges.push(pc);
+        if (state.currentSession) {
+            state.currentSession.phaseChanges = state.phaseChanges;
+        }
+        updatePhaseChanges(state.phaseChanges);
+    }
+
+    // show plan checkbox progress as a bar with "done/total", null hides it
+    function updateTaskProgress(progress) {
+        if (!taskProgressEl) return;
+        taskProgressEl.textContent = '';
+        taskProgressEl.title = '';
+        if (!progress || progress.total === 0) return;
+
+        var bar = document.createElement('span');
+        bar.className = 'task-progress-bar';
+        var fill = document.createElement('span');
+        fill.className = 'task-progress-fill';
+        fill.style.width = Math.min(100, Math.round(progress.done * 100 / progress.total)) + '%';
+        bar.appendChild(fill);
+
+        var label = document.createElement('span');
+        label.className = 'task-progress-label';
+        label.textContent = progress.done + '/' + progress.total;
+
+        taskProgressEl.appendChild(bar);
+        taskProgressEl.appendChild(label);
+        taskProgressEl.title = 'plan tasks done: ' + progress.done + ' of ' + progress.total;
+    }
+
+    // show pause/resume for sessions this process runs, hidden for sessions only tailed from files
+    function updatePauseControl() {
+        if (!pauseBtn) return;
+        var session = getSelectedSessionFromList();
+        var pausable = !!(session && session.pausable && session.state === 'active');
+        pauseBtn.classList.toggle('is-hidden', !pausable);
+        pauseBtn.classList.toggle('paused', state.paused);
+        pauseBtn.disabled = state.stopping;
+        if (state.stopping) {
+            pauseBtn.textContent = 'Stopping';
+            pauseBtn.title = 'The run exits after its current iteration';
+            return;
+        }
+        pauseBtn.textContent = state.paused ? 'Resume' : 'Pause';
+        pauseBtn.title = state.paused ? 'Resume the paused run' : 'Pause the run before its next iteration';
+    }
+
+    function togglePause() {
+        var sessionId = state.currentSessionId || 'main';
+        var action = state.paused ? 'resume' : 'pause';
+        if (sendCommand({ type: action })) {
+            return; // the paused/resumed event updates the button
+        }
+        fetch('/api/sessions/' + encodeURIComponent(sessionId) + '/' + action, { method: 'POST' })
+            .then(function(response) {
+                if (!response.ok) {
+                    throw new Error(action + ' failed: ' + response.status);
+                }
+                return response.json();
+            })
+            .then(function(data) {
+                state.paused = !!data.paused;
+                updatePauseControl();
+            })
+            .catch(function(err) {
+                console.error(err.message);
+            });
+    }
+
+    function parseTokenUsageText(text) {
+        if (!text) return null;
+        var matches = TOKEN_USAGE_PATTERN.exec(text.trim());
+        return matches ? matches[1] : null;
+    }
+
+    function parseTaskProgressText(text) {
+        if (!text) return null;
+        var matches = TASK_PROGRESS_PATTERN.exec(text.trim());
+        if (!matches) return null;
+        return { done: parseInt(matches[1], 10), total: parseInt(matches[2], 10) };
+    }
+
+    function parsePhaseChangesText(text) {
+        if (!text) return null;
+        text = text.trim();
+        var m = PHASE_UNCHANGED_PATTERN.exec(text);
+        if (m) return { phase: m[1], commits: 0, files: 0, additions: 0, deletions: 0, headUnchanged: true };
+        m = PHASE_COMMITS_PATTERN.exec(text);
+        if (m) {
+            return { phase: m[1], commits: parseInt(m[2], 10), files: parseInt(m[3], 10),
+                additions: parseInt(m[4], 10), deletions: parseInt(m[5], 10) };
+        }
+        m = PHASE_REWRITTEN_PATTERN.exec(text);
+        if (m) {
+            return { phase: m[1], commits: 0, files: parseInt(m[2], 10), additions: parseInt(m[3], 10),
+                deletions: parseInt(m[4], 10), rewritten: true };
+        }
+        return null;
+    }
+
     function parseDiffStatsText(text) {
         if (!text) return null;
         var matches = DIFF_STATS_PATTERN.exec(text);
@@ -692,7 +877,7 @@
     }
 
     function shouldRunElapsedTimer() {
-        return !state.isTerminalState && isLiveSession();
+        return !state.replay && !state.isTerminalState && isLiveSession();
     }
 
     // start elapsed time timer - clears any existing interval to prevent memory leaks on reconnect
@@ -800,6 +985,18 @@
                 updateDiffStats(diffStats);
                 return; // metadata line, don't render
             }
+            var tokenTotal = parseTokenUsageText(event.text);
+            if (tokenTotal) {
+                updateTokenUsage(tokenTotal); // rendered as a regular line too
+            }
+            var taskProgress = parseTaskProgressText(event.text);
+            if (taskProgress) {
+                updateTaskProgress(taskProgress); // rendered as a regular line too
+            }
+            var phaseChange = parsePhaseChangesText(event.text);
+            if (phaseChange) {
+                addPhaseChange(phaseChange); // rendered as a regular line too
+            }
         }
 
         // update status badge
@@ -818,6 +1015,22 @@
             // iteration events are informational
             return;
         }
+        if (event.type === 'question') {
+            showQuestion(event); // the question itself is rendered from its QUESTION/OPTIONS lines
+            return;
+        }
+        if (event.type === 'question_closed') {
+            hideQuestion();
+            return;
+        }
+        if (event.type === 'paused' || event.type === 'resumed') {
+            state.paused = event.type === 'paused';
+            updatePauseControl(); // rendered as a regular line too
+        }
+        if (event.type === 'stopping') {
+            state.stopping = true;
+            updatePauseControl(); // rendered as a regular line too
+        }
 
         if (event.type === 'section') {
             // deduplicate sections (can happen when BroadcastLogger and Tailer both emit)
@@ -869,9 +1082,16 @@
 
     // connect to SSE stream with exponential backoff
     function connect() {
+        if (state.replay) {
+            return; // back to the live stream when the replay is closed
+        }
         if (!state.isFirstConnect) {
             state.resetOnNextEvent = true;
         }
+        if (USE_WEBSOCKET) {
+            connectWebSocket();
+            return;
+        }
 
         var url = '/events';
         if (state.currentSessionId) {
@@ -912,6 +1132,139 @@
         };
     }
 
+    // connect to the websocket stream, it carries the same events as SSE plus command errors
+    function connectWebSocket() {
+        var url = (window.location.protocol === 'https:' ? 'wss://' : 'ws://') + window.location.host + '/ws';
+        if (state.currentSessionId) {
+            url += '?session=' + encodeURIComponent(state.currentSessionId);
+        }
+
+        var socket = new WebSocket(url);
+        state.currentEventSource = socket;
+
+        socket.onopen = function() {
+            state.reconnectDelay = SSE_INITIAL_RECONNECT_MS;
+            state.isFirstConnect = false;
+        };
+
+        socket.onmessage = function(e) {
+            try {
+                var msg = JSON.parse(e.data);
+                if (msg.type === 'error') {
+                    showQuestionError(msg.error);
+                    console.error('command failed:', msg.error);
+                    return;
+                }
+                if (msg.type !== 'event' || !msg.event) return;
+                if (state.resetOnNextEvent) {
+                    resetOutputState();
+                    state.resetOnNextEvent = false;
+                }
+                state.eventQueue.push(msg.event);
+                processEventQueue();
+            } catch (err) {
+                console.error('parse error:', err);
+            }
+        };
+
+        socket.onclose = function() {
+            if (state.currentEventSource !== socket) {
+                return; // closed on purpose, e.g. switching sessions
+            }
+            state.currentEventSource = null;
+            setTimeout(connect, state.reconnectDelay);
+            state.reconnectDelay = Math.min(state.reconnectDelay * 2, SSE_MAX_RECONNECT_MS);
+        };
+    }
+
+    // send a command over the websocket, returns false without an open connection
+    function sendCommand(cmd) {
+        var socket = state.currentEventSource;
+        if (!USE_WEBSOCKET || !socket || socket.readyState !== WebSocket.OPEN) {
+            return false;
+        }
+        socket.send(JSON.stringify(cmd));
+        return true;
+    }
+
+    // show a plan question waiting for an answer, only the websocket can send the answer back
+    function showQuestion(event) {
+        if (!USE_WEBSOCKET || !questionPanel) return;
+        questionTextEl.textContent = event.text;
+        questionOptionsEl.textContent = '';
+        (event.options || []).forEach(function(option) {
+            var btn = document.createElement('button');
+            btn.type = 'button';
+            btn.className = 'question-option';
+            btn.textContent = option;
+            btn.addEventListener('click', function() {
+                answerQuestion(option);
+            });
+            questionOptionsEl.appendChild(btn);
+        });
+        // free-form questions get no options, the answer field is prefilled with the default
+        var hasOptions = (event.options || []).length > 0;
+        questionInput.placeholder = hasOptions ? 'Or type your own answer' : 'Type your answer';
+        questionInput.value = hasOptions ? '' : (event.default || '');
+        questionTextarea.value = event.default || '';
+        questionInput.classList.toggle('is-hidden', !!event.multiline);
+        questionTextarea.classList.toggle('is-hidden', !event.multiline);
+        questionErrorEl.textContent = '';
+        setQuestionDisabled(false);
+        questionPanel.classList.remove('is-hidden');
+        if (!hasOptions) {
+            answerField().focus();
+        }
+    }
+
+    // answerField returns the answer input shown for the current question
+    function answerField() {
+        return questionTextarea.classList.contains('is-hidden') ? questionInput : questionTextarea;
+    }
+
+    function hideQuestion() {
+        if (!questionPanel) return;
+        questionPanel.classList.add('is-hidden');
+        questionOptionsEl.textContent = '';
+        questionErrorEl.textContent = '';
+    }
+
+    function answerQuestion(answer) {
+        if (!answer || !answer.trim()) return;
+        if (!sendCommand({ type: 'answer', answer: answer })) {
+            showQuestionError('not connected, answer in the terminal');
+            return;
+        }
+        questionErrorEl.textContent = '';
+        setQuestionDisabled(true); // until question_closed hides the panel or an error re-enables it
+    }
+
+    function showQuestionError(text) {
+        if (!questionPanel || questionPanel.classList.contains('is-hidden')) return;
+        questionErrorEl.textContent = text;
+        setQuestionDisabled(false);
+    }
+
+    function setQuestionDisabled(disabled) {
+        questionPanel.querySelectorAll('button, input, textarea').forEach(function(el) {
+            el.disabled = disabled;
+        });
+    }
+
+    if (questionForm) {
+        questionForm.addEventListener('submit', function(e) {
+            e.preventDefault();
+            answerQuestion(answerField().value);
+        });
+        // multiline answers submit with Ctrl+Enter (Cmd+Enter on mac), plain Enter adds a line
+        questionTextarea.addEventListener('keydown', function(e) {
+            if (e.key === 'Enter' && (e.ctrlKey || e.metaKey)) {
+                e.preventDefault();
+                answerQuestion(questionTextarea.value);
+            }
+        });
+    }
+
     // phase filter functions
     function setPhaseFilter(phase) {
         state.currentPhase = phase;
@@ -1151,8 +1504,13 @@
             .then(function(sessions) {
                 state.sessions = sessions;
                 renderSessionList(sessions);
+                var selected = getSelectedSessionFromList();
+                if (selected && selected.pausable) {
+                    state.paused = !!selected.paused;
+                }
+                updatePauseControl();
                 // auto-select first session if none is currently selected
-                if (!state.currentSessionId && sessions.length > 0) {
+                if (!state.currentSessionId && !state.replay && sessions.length > 0) {
                     selectSession(sessions[0].id);
                 }
             })
@@ -1339,6 +1697,27 @@
         return dir;
     }
 
+    // map session status (live, idle, finished, failed, removed) to indicator class and tooltip.
+    // falls back to state for servers without status
+    function sessionStatusInfo(session) {
+        switch (session.status) {
+            case 'live':
+                return { className: 'active', title: 'Live session' };
+            case 'idle':
+                return { className: 'idle', title: 'Idle session, no progress for a while' };
+            case 'finished':
+                return { className: 'completed', title: 'Finished session' };
+            case 'failed':
+                return { className: 'failed', title: 'Failed session' };
+            case 'removed':
+                return { className: 'removed', title: 'Progress file removed' };
+        }
+        if (session.state === 'active') {
+            return { className: 'active', title: 'Active session' };
+        }
+        return { className: 'completed', title: 'Completed session' };
+    }
+
     // create a session item element
     // showProject: if true, show project badge (used in time-sorted view)
     function createSessionItem(session, showProject) {
@@ -1360,12 +1739,11 @@
 
         var indicator = document.createElement('span');
         indicator.className = 'session-indicator';
-        if (session.state === 'active') {
-            indicator.classList.add('active');
-            indicator.title = 'Active session';
-        } else {
-            indicator.classList.add('completed');
-            indicator.title = 'Completed session';
+        var sessionStatus = sessionStatusInfo(session);
+        indicator.classList.add(sessionStatus.className);
+        indicator.title = sessionStatus.title;
+        if (session.status === 'removed') {
+            item.classList.add('removed');
         }
 
         var name = document.createElement('div');
@@ -1420,12 +1798,15 @@
 
     // select a session and switch to it
     function selectSession(sessionId) {
-        if (sessionId === state.currentSessionId) {
+        if (sessionId === state.currentSessionId && !state.replay) {
             return; // already selected
         }
 
-        // save scroll position of current session before switching
-        saveScrollPosition(state.currentSessionId);
+        // save scroll position of current session before switching, a replay doesn't keep one
+        if (!state.replay) {
+            saveScrollPosition(state.currentSessionId);
+        }
+        stopReplay();
 
         state.currentSessionId = sessionId;
 
@@ -1470,7 +1851,10 @@
                 branchNameEl.textContent = session.branch || '';
             }
             state.currentSession = session;
+            state.paused = !!session.paused;
             updateDiffStats(session.diffStats);
+            state.phaseChanges = (session.phaseChanges || []).slice();
+            updatePhaseChanges(state.phaseChanges);
             seedExecutionStartTimeFromSession(session);
         }
 
@@ -1592,6 +1976,14 @@
         }
         elapsedTimeEl.textContent = '';
         updateDiffStats(null);
+        state.phaseChanges = [];
+        updatePhaseChanges(null);
+        updateTokenUsage(null);
+        updateTaskProgress(null);
+        hideQuestion();
+        state.paused = false;
+        state.stopping = false;
+        updatePauseControl();
         if (seedStartTime) {
             seedExecutionStartTimeFromSession({ startTime: seedStartTime });
         }
@@ -1744,6 +2136,11 @@
 
     // keyboard shortcuts
     document.addEventListener('keydown', function(e) {
+        // typing an answer to a plan question, no shortcuts
+        if (questionInput && (document.activeElement === questionInput || document.activeElement === questionTextarea)) {
+            return;
+        }
+
         // '?' shows help (unless in input)
         if (e.key === '?' && document.activeElement !== searchInput) {
             e.preventDefault();
@@ -1751,20 +2148,24 @@
             return;
         }
 
-        // Escape closes help, or clears search
+        // Escape closes help or history, or clears search
         if (e.key === 'Escape') {
             if (isHelpVisible()) {
                 hideHelp();
                 return;
             }
+            if (isHistoryVisible()) {
+                hideHistory();
+                return;
+            }
             searchInput.value = '';
             searchInput.blur();
             handleSearch();
             return;
         }
 
-        // ignore other shortcuts when help is visible
-        if (isHelpVisible()) return;
+        // ignore other shortcuts when help or history is visible
+        if (isHelpVisible() || isHistoryVisible()) return;
 
         // '/' focuses search (unless already in input)
         if (e.key === '/' && document.activeElement !== searchInput) {
@@ -1796,6 +2197,12 @@
             setSessionViewMode(VIEW_MODE.GROUPED);
         }
 
+        // 'h' opens the run history (unless in input)
+        if (e.key === 'h' && document.activeElement !== searchInput) {
+            e.preventDefault();
+            showHistory();
+        }
+
         // 'j'/'k' navigate between sections (unless in input)
         if (e.key === 'j' && document.activeElement !== searchInput) {
             e.preventDefault();
@@ -1990,6 +2397,15 @@
         URL.revokeObjectURL(url);
     }
 
+    // download the progress file of the current session, the server sends it as an attachment
+    function downloadLog() {
+        var url = '/download';
+        if (state.currentSessionId) {
+            url += '?session=' + encodeURIComponent(state.currentSessionId);
+        }
+        window.location.href = url;
+    }
+
     // export session as standalone HTML
     function exportSession() {
         // fetch current stylesheet instead of using hardcoded copy
@@ -2012,6 +2428,10 @@
     }
 
     exportBtn.addEventListener('click', exportSession);
+    downloadBtn.addEventListener('click', downloadLog);
+    if (pauseBtn) {
+        pauseBtn.addEventListener('click', togglePause);
+    }
 
     // expand/collapse all sections (user-initiated, so track preferences)
     function expandAllSections() {
@@ -2077,6 +2497,237 @@
         });
     }
 
+    // run history: full progress logs of past runs replayed page by page from /api/history,
+    // rendered with the same event handling as the live stream
+    function showHistory() {
+        if (!historyOverlay) return;
+        historyOverlay.classList.add('visible');
+        fetchHistory();
+    }
+    function hideHistory() { if (historyOverlay) historyOverlay.classList.remove('visible'); }
+    function isHistoryVisible() { return historyOverlay && historyOverlay.classList.contains('visible'); }
+
+    function createHistoryMessage(text) {
+        var div = document.createElement('div');
+        div.className = 'session-loading';
+        div.textContent = text;
+        return div;
+    }
+
+    function fetchHistory() {
+        clearElement(historyList);
+        historyList.appendChild(createHistoryMessage('Loading runs...'));
+        fetch('/api/history')
+            .then(function(response) {
+                if (!response.ok) {
+                    throw new Error('History not available');
+                }
+                return response.json();
+            })
+            .then(renderHistoryList)
+            .catch(function(err) {
+                clearElement(historyList);
+                historyList.appendChild(createHistoryMessage('History not available'));
+                console.log('History fetch:', err.message);
+            });
+    }
+
+    // format a byte count for display
+    function formatBytes(n) {
+        if (!n) return '0 B';
+        if (n < 1024) return n + ' B';
+        if (n < 1024 * 1024) return (n / 1024).toFixed(1) + ' KB';
+        return (n / (1024 * 1024)).toFixed(1) + ' MB';
+    }
+
+    /**
+     * Render history entries to the history overlay.
+     * XSS-safe: uses textContent for all server-provided text.
+     * @param {Array} entries - Array of history entries from API
+     */
+    function renderHistoryList(entries) {
+        clearElement(historyList);
+        if (!entries || entries.length === 0) {
+            historyList.appendChild(createHistoryMessage('No runs found'));
+            return;
+        }
+        entries.forEach(function(entry) {
+            var item = document.createElement('div');
+            item.className = 'session-item history-item';
+
+            var info = document.createElement('div');
+            info.className = 'session-info';
+
+            var topRow = document.createElement('div');
+            topRow.className = 'session-row session-row-top';
+            var indicator = document.createElement('span');
+            indicator.className = 'session-indicator';
+            var entryStatus = sessionStatusInfo(entry);
+            indicator.classList.add(entryStatus.className);
+            indicator.title = entryStatus.title;
+            var name = document.createElement('div');
+            name.className = 'session-name';
+            name.textContent = extractPlanName(entry.planPath);
+            var timeSpan = document.createElement('span');
+            timeSpan.className = 'session-time session-time-top';
+            timeSpan.textContent = formatRelativeTime(entry.lastModified);
+            topRow.appendChild(indicator);
+            topRow.appendChild(name);
+            topRow.appendChild(timeSpan);
+
+            var metaRow = document.createElement('div');
+            metaRow.className = 'session-row session-row-meta';
+            var meta = document.createElement('span');
+            meta.className = 'session-project';
+            meta.textContent = [entry.dir, entry.branch, entry.mode, entry.elapsed,
+                entry.tokens ? 'tokens: ' + entry.tokens : '', formatBytes(entry.size)]
+                .filter(Boolean).join(' · ');
+            metaRow.appendChild(meta);
+
+            info.appendChild(topRow);
+            info.appendChild(metaRow);
+            item.appendChild(info);
+            item.addEventListener('click', function() {
+                hideHistory();
+                startReplay(entry);
+            });
+            historyList.appendChild(item);
+        });
+    }
+
+    // replace the live stream with the replay of a history entry
+    function startReplay(entry) {
+        if (state.currentEventSource) {
+            state.currentEventSource.close();
+            state.currentEventSource = null;
+        }
+        saveScrollPosition(state.currentSessionId);
+
+        var replay = { entry: entry, offset: 0, phase: '' };
+        state.replay = replay;
+        resetOutputState({ seedStartTime: entry.startTime });
+        state.pendingScrollRestore = false;
+
+        if (planNameEl) planNameEl.textContent = extractPlanName(entry.planPath);
+        if (branchNameEl) branchNameEl.textContent = entry.branch || '';
+        replayModeEl.textContent = entry.mode ? 'mode: ' + entry.mode : '';
+        replayBranchEl.textContent = entry.branch ? 'branch: ' + entry.branch : '';
+        replayElapsedEl.textContent = entry.elapsed ? 'elapsed: ' + entry.elapsed : '';
+        replayTokensEl.textContent = entry.tokens ? 'tokens: ' + entry.tokens : '';
+        replayProgressEl.textContent = '';
+        replaySummary.classList.remove('is-hidden');
+
+        // the plan is known for sessions only, not for progress files listed from the history directory
+        var isSession = state.sessions.some(function(s) { return s.id === entry.id; });
+        if (isSession) {
+            fetchPlanForSession(entry.id);
+        } else {
+            state.planData = null;
+            clearElement(planContent);
+            planContent.appendChild(createPlanMessage('Plan not available'));
+        }
+
+        loadReplayPage(replay);
+    }
+
+    // fetch the next page of the replayed log, the page after it is fetched once this one is rendered
+    function loadReplayPage(replay) {
+        var url = '/api/history/' + encodeURIComponent(replay.entry.id) + '?offset=' + replay.offset;
+        if (replay.phase) {
+            url += '&phase=' + encodeURIComponent(replay.phase);
+        }
+        fetch(url)
+            .then(function(response) {
+                if (!response.ok) {
+                    throw new Error('Run log not available');
+                }
+                return response.json();
+            })
+            .then(function(page) {
+                if (state.replay !== replay) return; // closed or replaced meanwhile
+                replay.offset = page.offset;
+                replay.phase = page.phase;
+                replayProgressEl.textContent = page.done ? formatBytes(page.size) :
+                    'loading ' + formatBytes(page.offset) + ' of ' + formatBytes(page.size);
+                for (var i = 0; i < page.events.length; i++) {
+                    state.eventQueue.push(page.events[i]);
+                }
+                processEventQueue();
+                whenQueueDrained(replay, function() {
+                    if (page.done) {
+                        finishReplay(replay);
+                    } else {
+                        loadReplayPage(replay);
+                    }
+                });
+            })
+            .catch(function(err) {
+                if (state.replay !== replay) return;
+                replayProgressEl.textContent = 'failed to load the log';
+                console.log('History page fetch:', err.message);
+            });
+    }
+
+    // call fn once all queued events are rendered, unless the replay was closed
+    function whenQueueDrained(replay, fn) {
+        if (state.replay !== replay) return;
+        if (state.eventQueue.length > 0 || state.isProcessingQueue) {
+            setTimeout(function() { whenQueueDrained(replay, fn); }, 50);
+            return;
+        }
+        fn();
+    }
+
+    // show the outcome and duration of the fully rendered replay
+    function finishReplay(replay) {
+        var entry = replay.entry;
+        if (!state.isTerminalState && (entry.status === 'finished' || entry.status === 'failed')) {
+            updateStatusBadge({ type: 'signal', signal: entry.status === 'failed' ? 'FAILED' : 'COMPLETED' });
+        }
+        runTerminalCleanupOnce();
+        var elapsed = entry.elapsed;
+        if (!elapsed && state.executionStartTime && state.lastEventTimestamp) {
+            elapsed = formatDuration(state.lastEventTimestamp - state.executionStartTime);
+        }
+        elapsedTimeEl.textContent = elapsed || '';
+        replayElapsedEl.textContent = elapsed ? 'elapsed: ' + elapsed : '';
+    }
+
+    // drop the replay state and hide its summary, without reconnecting
+    function stopReplay() {
+        state.replay = null;
+        if (replaySummary) replaySummary.classList.add('is-hidden');
+    }
+
+    // close the replay and go back to the live stream of the selected session
+    function closeReplay() {
+        if (!state.replay) return;
+        if (state.currentSessionId) {
+            selectSession(state.currentSessionId); // reconnects, the selected session is the same
+            return;
+        }
+        stopReplay();
+        reconnectToSession(null);
+        fetchPlan();
+    }
+
+    if (historyBtn) {
+        historyBtn.addEventListener('click', showHistory);
+    }
+    if (historyCloseBtn) {
+        historyCloseBtn.addEventListener('click', hideHistory);
+    }
+    if (historyOverlay) {
+        historyOverlay.addEventListener('click', function(e) {
+            if (e.target === historyOverlay) {
+                hideHistory();
+            }
+        });
+    }
+    if (replayCloseBtn) {
+        replayCloseBtn.addEventListener('click', closeReplay);
+    }
+
 
 
 
@@ -199,6 +199,11 @@ func (t *Tailer) readNewLines() {
 				if line != "" {
 					_, _ = t.file.Seek(t.offset, io.SeekStart)
 					t.reader.Reset(t.file)
+					return
+				}
+				// the whole file is read, continue in the new file if the logger rotated it
+				if t.followRotation() {
+					continue
 				}
 				return
 			}
@@ -233,6 +238,47 @@ func (t *Tailer) readNewLines() {
 	}
 }
 
+// followRotation switches to the new file at the tailed path after the progress logger rotated it,
+// or rewinds if the file was truncated in place. returns true if reading should continue.
+// the new file starts with the same header, it is skipped like the header of the original file.
+// must be called with the mutex held, after the current file was read to the end.
+func (t *Tailer) followRotation() bool {
+	pathInfo, err := os.Stat(t.path)
+	if err != nil {
+		return false // between rename and create, check again on the next poll
+	}
+	fileInfo, err := t.file.Stat()
+	if err != nil {
+		return false
+	}
+
+	if os.SameFile(pathInfo, fileInfo) {
+		if pathInfo.Size() >= t.offset {
+			return false
+		}
+		// truncated in place
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return false
+		}
+	} else {
+		// lines written to the old file right before the rotation are read first
+		if _, err := t.reader.Peek(1); err == nil {
+			return true
+		}
+		f, err := os.Open(t.path)
+		if err != nil {
+			return false
+		}
+		t.file.Close()
+		t.file = f
+	}
+
+	t.offset = 0
+	t.inHeader = true
+	t.reader.Reset(t.file)
+	return true
+}
+
 // sendEvent tries to enqueue an event; when the queue is full, it prefers
 // keeping high-priority events (sections, task boundaries, signals) by dropping
 // older events to make space.
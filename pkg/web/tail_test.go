@@ -1,14 +1,17 @@
 package web
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/umputun/ralphex/pkg/progress"
 	"github.com/umputun/ralphex/pkg/status"
 )
 
@@ -324,6 +327,43 @@ Started: 2026-01-22 10:30:00
 	})
 }
 
+func TestTailer_FollowsRotation(t *testing.T) {
+	l, err := progress.NewLogger(progress.Config{PlanFile: "docs/plans/feature.md", Mode: "full", Branch: "feature",
+		Dir: t.TempDir(), MaxSizeBytes: 600, MaxBackups: 1}, testColors(), &status.PhaseHolder{})
+	require.NoError(t, err)
+	defer l.Close()
+
+	tailer := NewTailer(l.Path(), TailerConfig{PollInterval: 10 * time.Millisecond, InitialPhase: status.PhaseTask})
+	require.NoError(t, tailer.Start(true))
+	defer tailer.Stop()
+
+	// about 70 bytes per line, the file is rotated every few lines
+	const lines = 30
+	var got []string
+	for i := range lines {
+		l.Print("line %02d %s", i, strings.Repeat("x", 40))
+		if i%5 == 0 {
+			time.Sleep(20 * time.Millisecond) // let the tailer catch up between some rotations
+		}
+	}
+	require.FileExists(t, progress.BackupPath(l.Path(), 1))
+
+	timeout := time.After(2 * time.Second)
+	for len(got) < lines {
+		select {
+		case event := <-tailer.Events():
+			if strings.HasPrefix(event.Text, "line ") {
+				got = append(got, event.Text[:7])
+			}
+		case <-timeout:
+			t.Fatalf("received %d of %d lines: %v", len(got), lines, got)
+		}
+	}
+	for i, text := range got {
+		assert.Equal(t, fmt.Sprintf("line %02d", i), text, "lines are received in order, header lines are skipped")
+	}
+}
+
 func TestTailer_Stop(t *testing.T) {
 	t.Run("stop before start is safe", func(t *testing.T) {
 		tailer := NewTailer("/nonexistent", DefaultTailerConfig())
@@ -0,0 +1,58 @@
+package web
+
+import (
+	"time"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// PhaseSpan is a stretch of a session spent in one phase, from the transition into it
+// to the transition out of it, or to the last event of the phase while it is current.
+type PhaseSpan struct {
+	Phase      status.Phase `json:"phase"`
+	Start      time.Time    `json:"start"`
+	End        time.Time    `json:"end"`
+	DurationMs int64        `json:"durationMs"`
+}
+
+// recordPhase extends the timeline with activity in the given phase at t: the current span
+// grows up to t, or a new span starts if the phase changed. must be called with mu held.
+func (s *Session) recordPhase(phase status.Phase, t time.Time) {
+	if phase == "" || t.IsZero() {
+		return
+	}
+	if n := len(s.timeline); n > 0 {
+		last := &s.timeline[n-1]
+		if last.Phase == phase {
+			if t.After(last.End) {
+				last.End = t
+			}
+			return
+		}
+		if t.Before(last.End) {
+			t = last.End // keep spans ordered if the transition is reported before the last event's time
+		}
+		last.End = t
+	}
+	s.timeline = append(s.timeline, PhaseSpan{Phase: phase, Start: t, End: t})
+}
+
+// StartPhase records the transition into the given phase at t.
+// the live run calls it from the phase holder's OnChange callback, events published later extend the span.
+func (s *Session) StartPhase(phase status.Phase, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordPhase(phase, t)
+}
+
+// GetTimeline returns the phase spans of the session in the order they ran, with durations filled in.
+func (s *Session) GetTimeline() []PhaseSpan {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make([]PhaseSpan, len(s.timeline))
+	for i, span := range s.timeline {
+		span.DurationMs = span.End.Sub(span.Start).Milliseconds()
+		res[i] = span
+	}
+	return res
+}
@@ -0,0 +1,62 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestSession_GetTimeline(t *testing.T) {
+	base := time.Date(2026, 3, 5, 10, 0, 0, 0, time.UTC)
+	at := func(sec int) time.Time { return base.Add(time.Duration(sec) * time.Second) }
+
+	t.Run("empty", func(t *testing.T) {
+		session := NewSession("test", "/tmp/test.txt")
+		defer session.Close()
+		assert.Empty(t, session.GetTimeline())
+	})
+
+	t.Run("events and transitions", func(t *testing.T) {
+		session := NewSession("test", "/tmp/test.txt")
+		defer session.Close()
+
+		publish := func(phase status.Phase, sec int) {
+			e := NewOutputEvent(phase, "line")
+			e.Timestamp = at(sec)
+			assert.NoError(t, session.Publish(e))
+		}
+		publish(status.PhaseTask, 0)
+		publish(status.PhaseTask, 30)
+		session.StartPhase(status.PhaseReview, at(40))
+		publish(status.PhaseReview, 100)
+		publish("", 110) // events without phase don't touch the timeline
+		publish(status.PhaseCodex, 130)
+		session.StartPhase(status.PhaseCodex, at(120)) // late report of the current phase is ignored
+		publish(status.PhaseCodex, 190)
+
+		want := []PhaseSpan{
+			{Phase: status.PhaseTask, Start: at(0), End: at(40), DurationMs: 40000},
+			{Phase: status.PhaseReview, Start: at(40), End: at(130), DurationMs: 90000},
+			{Phase: status.PhaseCodex, Start: at(130), End: at(190), DurationMs: 60000},
+		}
+		assert.Equal(t, want, session.GetTimeline())
+	})
+
+	t.Run("transition before the last event keeps spans ordered", func(t *testing.T) {
+		session := NewSession("test", "/tmp/test.txt")
+		defer session.Close()
+
+		e := NewOutputEvent(status.PhaseTask, "line")
+		e.Timestamp = at(10)
+		assert.NoError(t, session.Publish(e))
+		session.StartPhase(status.PhaseReview, at(5))
+
+		timeline := session.GetTimeline()
+		assert.Len(t, timeline, 2)
+		assert.Equal(t, at(10), timeline[0].End)
+		assert.Equal(t, at(10), timeline[1].Start)
+	})
+}
@@ -0,0 +1,139 @@
+package web
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stateDir is the directory of ralphex state in a project, levels in it don't count toward the watch depth.
+const stateDir = ".ralphex"
+
+// watchPattern is a watch directory, possibly with glob elements, split into a static base and glob segments.
+// each segment matches one directory name per filepath.Match, "**" matches any number of directories.
+type watchPattern struct {
+	base     string   // absolute directory before the first glob element
+	segments []string // remaining path elements, empty for a plain directory
+}
+
+// walkState tracks how far a directory got in matching a pattern.
+type walkState struct {
+	seg     int  // index of the next segment to match, len(segments) when the pattern matched
+	depth   int  // directory levels counted toward the watch depth so far
+	inState bool // inside the .ralphex directory of a matched project, its levels are free
+}
+
+// parseWatchPattern splits an absolute, cleaned path into a watchPattern.
+func parseWatchPattern(path string) watchPattern {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for i, part := range parts {
+		if hasGlobMeta(part) {
+			return watchPattern{base: filepath.Clean(filepath.FromSlash(strings.Join(parts[:i], "/") + "/")), segments: parts[i:]}
+		}
+	}
+	return watchPattern{base: path}
+}
+
+// hasGlobMeta reports whether a path element has glob meta characters.
+func hasGlobMeta(s string) bool {
+	return strings.ContainsAny(s, `*?[`)
+}
+
+// start returns the states of the pattern base.
+func (p watchPattern) start() []walkState {
+	return p.closure([]walkState{{}})
+}
+
+// closure adds the states reached by "**" matching no directories.
+func (p watchPattern) closure(states []walkState) []walkState {
+	for i := 0; i < len(states); i++ {
+		st := states[i]
+		if st.seg < len(p.segments) && p.segments[st.seg] == "**" {
+			states = appendState(states, walkState{seg: st.seg + 1, depth: st.depth})
+		}
+	}
+	return states
+}
+
+// advance returns the states of a subdirectory called name of a directory in states.
+// maxDepth limits the counted levels, zero means unlimited. ignored names are skipped unless
+// the pattern names them explicitly.
+func (p watchPattern) advance(states []walkState, name string, maxDepth int, ignore []string) []walkState {
+	var next []walkState
+	for _, st := range states {
+		switch {
+		case st.inState:
+			next = appendState(next, st)
+		case st.seg == len(p.segments):
+			if name == stateDir {
+				next = appendState(next, walkState{seg: st.seg, depth: st.depth, inState: true})
+				continue
+			}
+			if !isIgnored(name, ignore) && (maxDepth == 0 || st.depth < maxDepth) {
+				next = appendState(next, walkState{seg: st.seg, depth: st.depth + 1})
+			}
+		case p.segments[st.seg] == "**":
+			if !isIgnored(name, ignore) && (maxDepth == 0 || st.depth < maxDepth) {
+				next = appendState(next, walkState{seg: st.seg, depth: st.depth + 1})
+			}
+		case !hasGlobMeta(p.segments[st.seg]):
+			if name == p.segments[st.seg] {
+				next = appendState(next, walkState{seg: st.seg + 1, depth: st.depth})
+			}
+		default:
+			if ok, err := filepath.Match(p.segments[st.seg], name); err == nil && ok && !isIgnored(name, ignore) {
+				next = appendState(next, walkState{seg: st.seg + 1, depth: st.depth})
+			}
+		}
+	}
+	return p.closure(next)
+}
+
+// statesOf returns the states of path, nil if path is outside the pattern or beyond the watch depth.
+func (p watchPattern) statesOf(path string, maxDepth int, ignore []string) []walkState {
+	rel, err := filepath.Rel(p.base, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return nil
+	}
+	states := p.start()
+	if rel == "." {
+		return states
+	}
+	for name := range strings.SplitSeq(rel, string(os.PathSeparator)) {
+		if states = p.advance(states, name, maxDepth, ignore); len(states) == 0 {
+			return nil
+		}
+	}
+	return states
+}
+
+// matched reports whether any of states completed the pattern, progress files there are sessions.
+func (p watchPattern) matched(states []walkState) bool {
+	for _, st := range states {
+		if st.seg == len(p.segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendState appends st unless an equal or shallower state for the same position is already in states.
+func appendState(states []walkState, st walkState) []walkState {
+	for i, s := range states {
+		if s.seg == st.seg && s.inState == st.inState {
+			states[i].depth = min(s.depth, st.depth)
+			return states
+		}
+	}
+	return append(states, st)
+}
+
+// isIgnored reports whether a directory name matches one of the ignore entries, names or glob patterns.
+func isIgnored(name string, ignore []string) bool {
+	for _, pattern := range ignore {
+		if ok, err := filepath.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWatchPattern(t *testing.T) {
+	tests := []struct {
+		path     string
+		base     string
+		segments []string
+	}{
+		{path: "/home/u/work", base: "/home/u/work"},
+		{path: "/home/u/work/*", base: "/home/u/work", segments: []string{"*"}},
+		{path: "/home/u/work/**", base: "/home/u/work", segments: []string{"**"}},
+		{path: "/home/u/work/*/src", base: "/home/u/work", segments: []string{"*", "src"}},
+		{path: "/home/u/proj-[ab]/docs", base: "/home/u", segments: []string{"proj-[ab]", "docs"}},
+		{path: "/*", base: "/", segments: []string{"*"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			p := parseWatchPattern(filepath.FromSlash(tc.path))
+			assert.Equal(t, filepath.FromSlash(tc.base), p.base)
+			assert.Equal(t, tc.segments, p.segments)
+		})
+	}
+}
+
+func TestWatchPattern_StatesOf(t *testing.T) {
+	ignore := []string{"node_modules", "*.bak"}
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		depth   int
+		watched bool // the directory is watched, on the way to or within a match
+		matched bool // progress files in the directory are sessions
+	}{
+		{name: "plain base", pattern: "/w", path: "/w", depth: 2, watched: true, matched: true},
+		{name: "plain within depth", pattern: "/w", path: "/w/a/b", depth: 2, watched: true, matched: true},
+		{name: "plain beyond depth", pattern: "/w", path: "/w/a/b/c", depth: 2},
+		{name: "plain unlimited depth", pattern: "/w", path: "/w/a/b/c/d", depth: 0, watched: true, matched: true},
+		{name: "state dir is free", pattern: "/w", path: "/w/a/b/.ralphex/progress", depth: 2, watched: true, matched: true},
+		{name: "outside", pattern: "/w", path: "/x/a", depth: 2},
+		{name: "parent", pattern: "/w/a", path: "/w", depth: 2},
+		{name: "ignored", pattern: "/w", path: "/w/a/node_modules", depth: 2},
+		{name: "ignored by pattern", pattern: "/w", path: "/w/old.bak", depth: 2},
+		{name: "glob base not matched", pattern: "/w/*", path: "/w", depth: 2, watched: true},
+		{name: "glob match", pattern: "/w/*", path: "/w/repo", depth: 2, watched: true, matched: true},
+		{name: "glob below match", pattern: "/w/*", path: "/w/repo/a/b", depth: 2, watched: true, matched: true},
+		{name: "glob below match beyond depth", pattern: "/w/*", path: "/w/repo/a/b/c", depth: 2},
+		{name: "glob ignored name", pattern: "/w/*", path: "/w/node_modules", depth: 2},
+		{name: "glob then literal", pattern: "/w/*/src", path: "/w/repo/src", depth: 1, watched: true, matched: true},
+		{name: "glob then literal on the way", pattern: "/w/*/src", path: "/w/repo", depth: 1, watched: true},
+		{name: "glob then literal mismatch", pattern: "/w/*/src", path: "/w/repo/docs", depth: 1},
+		{name: "char class", pattern: "/w/proj-[ab]", path: "/w/proj-b", depth: 1, watched: true, matched: true},
+		{name: "char class mismatch", pattern: "/w/proj-[ab]", path: "/w/proj-c", depth: 1},
+		{name: "double star base", pattern: "/w/**", path: "/w", depth: 2, watched: true, matched: true},
+		{name: "double star within depth", pattern: "/w/**", path: "/w/a/b", depth: 2, watched: true, matched: true},
+		{name: "double star beyond depth", pattern: "/w/**", path: "/w/a/b/c", depth: 2},
+		{name: "double star then literal", pattern: "/w/**/svc", path: "/w/a/svc", depth: 2, watched: true, matched: true},
+		{name: "double star then literal on the way", pattern: "/w/**/svc", path: "/w/a/b", depth: 2, watched: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := parseWatchPattern(filepath.FromSlash(tc.pattern))
+			states := p.statesOf(filepath.FromSlash(tc.path), tc.depth, ignore)
+			assert.Equal(t, tc.watched, len(states) > 0, "watched")
+			assert.Equal(t, tc.matched, p.matched(states), "matched")
+		})
+	}
+}
+
+func TestIsIgnored(t *testing.T) {
+	ignore := []string{".git", "node_modules", "*.bak", "[", ""}
+	assert.True(t, isIgnored(".git", ignore))
+	assert.True(t, isIgnored("node_modules", ignore))
+	assert.True(t, isIgnored("old.bak", ignore))
+	assert.False(t, isIgnored("src", ignore))
+	assert.False(t, isIgnored(".github", ignore))
+	assert.False(t, isIgnored("src", nil))
+}
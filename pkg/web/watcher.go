@@ -6,57 +6,73 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+
+	"github.com/umputun/ralphex/pkg/progress"
 )
 
-// skipDirs is the set of directory names to skip during recursive watching.
+// defaultWatchIgnore is the list of directory names to skip during recursive watching.
 // these are known high-volume or non-relevant directories that won't contain progress files.
-var skipDirs = map[string]bool{
-	".git":         true,
-	".idea":        true,
-	".vscode":      true,
-	".cache":       true,
-	".npm":         true,
-	".yarn":        true,
-	"node_modules": true,
-	"vendor":       true,
-	"__pycache__":  true,
-	"target":       true,
-	"build":        true,
-	"dist":         true,
+var defaultWatchIgnore = []string{".git", ".idea", ".vscode", ".cache", ".npm", ".yarn",
+	"node_modules", "vendor", "__pycache__", "target", "build", "dist"}
+
+// WatchOptions controls how far watched directories are searched for progress files.
+type WatchOptions struct {
+	Depth  int      // directory levels searched below a watched directory, .ralphex not counted; zero is unlimited
+	Ignore []string // directory names or name glob patterns to skip, the built-in list if empty
 }
 
 // Watcher monitors directories for progress file changes.
 // it uses fsnotify for efficient file system event detection
 // and notifies the SessionManager when new progress files appear.
+// watched entries may be glob patterns, directories created later that match them are picked up.
 type Watcher struct {
-	dirs    []string
-	sm      *SessionManager
-	watcher *fsnotify.Watcher
+	dirs     []string
+	patterns []watchPattern
+	depth    int
+	ignore   []string
+	sm       *SessionManager
+	watcher  *fsnotify.Watcher
 
 	mu      sync.Mutex
 	started bool
 }
 
-// NewWatcher creates a watcher for the specified directories.
-// directories are watched recursively for progress-*.txt files.
+// NewWatcher creates a watcher for the specified directories or glob patterns, see ResolveWatchDirs.
+// directories are watched recursively for progress-*.txt files, without a depth limit until SetOptions.
 func NewWatcher(dirs []string, sm *SessionManager) (*Watcher, error) {
 	w, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, fmt.Errorf("create fsnotify watcher: %w", err)
 	}
 
+	patterns := make([]watchPattern, 0, len(dirs))
+	for _, dir := range dirs {
+		patterns = append(patterns, parseWatchPattern(dir))
+	}
 	return &Watcher{
-		dirs:    dirs,
-		sm:      sm,
-		watcher: w,
+		dirs:     dirs,
+		patterns: patterns,
+		ignore:   defaultWatchIgnore,
+		sm:       sm,
+		watcher:  w,
 	}, nil
 }
 
+// SetOptions sets the search depth and the ignored directory names, call before Start.
+func (w *Watcher) SetOptions(opts WatchOptions) {
+	w.depth = max(opts.Depth, 0)
+	w.ignore = defaultWatchIgnore
+	if len(opts.Ignore) > 0 {
+		w.ignore = opts.Ignore
+	}
+}
+
 // Start begins watching directories for progress file changes.
 // runs until the context is canceled.
 // performs initial discovery before starting the watch loop.
@@ -69,18 +85,16 @@ func (w *Watcher) Start(ctx context.Context) error {
 	w.started = true
 	w.mu.Unlock()
 
-	// add all directories to watcher (including subdirectories)
-	for _, dir := range w.dirs {
-		if err := w.addRecursive(dir); err != nil {
-			return err
-		}
-	}
-
-	// initial discovery (recursive to find existing progress files in subdirectories)
-	for _, dir := range w.dirs {
-		if _, err := w.sm.DiscoverRecursive(dir); err != nil {
-			log.Printf("[WARN] initial discovery failed for %s: %v", dir, err)
+	// add matching directories to watcher and discover existing progress files in them.
+	// a directory matched by several patterns is discovered once
+	discovered := make(map[string]bool)
+	for _, p := range w.patterns {
+		if _, err := os.Stat(p.base); err != nil {
+			log.Printf("[WARN] watch directory %s: %v", p.base, err)
+			w.sm.Metrics().WatcherError()
+			continue
 		}
+		w.addRecursive(p, p.base, p.start(), discovered)
 	}
 
 	// start tailing for active sessions
@@ -93,34 +107,53 @@ func (w *Watcher) Start(ctx context.Context) error {
 	return w.run(ctx)
 }
 
-// addRecursive adds a directory and all its subdirectories to the watcher.
-func (w *Watcher) addRecursive(dir string) error {
-	walkErr := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			// skip directories that can't be accessed
-			if d != nil && d.IsDir() {
-				return filepath.SkipDir
+// addRecursive adds dir and its subdirectories still on the way to or within a match of the pattern to the watcher.
+// progress files of directories matching the pattern are discovered, once per directory in discovered.
+// returns the discovered session IDs.
+func (w *Watcher) addRecursive(p watchPattern, dir string, states []walkState, discovered map[string]bool) []string {
+	// best-effort: continue walking even if we can't watch a specific directory
+	if err := w.watcher.Add(dir); err != nil {
+		log.Printf("[WARN] failed to watch directory %s: %v", dir, err)
+		w.sm.Metrics().WatcherError()
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil // skip directories that can't be accessed
+	}
+
+	var ids []string
+	if p.matched(states) && !discovered[dir] {
+		discovered[dir] = true
+		if slices.ContainsFunc(entries, func(e os.DirEntry) bool { return !e.IsDir() && isProgressFile(e.Name()) }) {
+			found, discoverErr := w.sm.Discover(dir)
+			if discoverErr != nil {
+				log.Printf("[WARN] discovery failed for %s: %v", dir, discoverErr)
+				w.sm.Metrics().WatcherError()
 			}
-			return err
+			ids = append(ids, found...)
+		}
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
 		}
+		if next := p.advance(states, e.Name(), w.depth, w.ignore); len(next) > 0 {
+			ids = append(ids, w.addRecursive(p, filepath.Join(dir, e.Name()), next, discovered)...)
+		}
+	}
+	return ids
+}
 
-		if d.IsDir() {
-			name := d.Name()
-			// skip directories that typically contain many subdirs and no progress files
-			if skipDirs[name] && path != dir {
-				return filepath.SkipDir
-			}
-			// best-effort: continue walking even if we can't watch a specific directory
-			if err := w.watcher.Add(path); err != nil {
-				log.Printf("[WARN] failed to watch directory %s: %v", path, err)
-			}
+// searched reports whether progress files in dir belong to a watched directory.
+func (w *Watcher) searched(dir string) bool {
+	for _, p := range w.patterns {
+		if p.matched(p.statesOf(dir, w.depth, w.ignore)) {
+			return true
 		}
-		return nil
-	})
-	if walkErr != nil {
-		return fmt.Errorf("walk directory %s: %w", dir, walkErr)
 	}
-	return nil
+	return false
 }
 
 // run is the main watch loop processing fsnotify events.
@@ -142,6 +175,7 @@ func (w *Watcher) run(ctx context.Context) error {
 			}
 			// log error but continue watching
 			log.Printf("[WARN] fsnotify error: %v", err)
+			w.sm.Metrics().WatcherError()
 		}
 	}
 }
@@ -154,19 +188,21 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 		return
 	}
 
-	// handle create or write events
-	if event.Has(fsnotify.Create) || event.Has(fsnotify.Write) {
+	// handle create or write events, progress files outside of matched directories are not sessions
+	if (event.Has(fsnotify.Create) || event.Has(fsnotify.Write)) && w.searched(filepath.Dir(event.Name)) {
 		w.handleProgressFileChange(event.Name)
 	}
 
-	// handle remove events
+	// handle remove events, the session stays listed as removed instead of showing stale content as live
 	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
 		id := sessionIDFromPath(event.Name)
-		w.sm.Remove(id)
+		w.sm.MarkRemoved(id)
 	}
 }
 
 // handleNonProgressEvent handles events for non-progress files (e.g., new directories).
+// a new directory matching a watched pattern, e.g. a freshly cloned repository, is watched
+// and its existing progress files are discovered.
 func (w *Watcher) handleNonProgressEvent(event fsnotify.Event) {
 	if !event.Has(fsnotify.Create) {
 		return
@@ -175,8 +211,15 @@ func (w *Watcher) handleNonProgressEvent(event fsnotify.Event) {
 	if err != nil || !info.IsDir() {
 		return
 	}
-	if err := w.addRecursive(event.Name); err != nil {
-		log.Printf("[WARN] failed to watch new directory %s: %v", event.Name, err)
+	discovered := make(map[string]bool)
+	for _, p := range w.patterns {
+		states := p.statesOf(event.Name, w.depth, w.ignore)
+		if len(states) == 0 {
+			continue
+		}
+		for _, id := range w.addRecursive(p, event.Name, states, discovered) {
+			w.startTailingIfNeeded(id)
+		}
 	}
 }
 
@@ -186,6 +229,7 @@ func (w *Watcher) handleProgressFileChange(path string) {
 	ids, err := w.sm.Discover(dir)
 	if err != nil {
 		log.Printf("[WARN] discovery failed for %s: %v", dir, err)
+		w.sm.Metrics().WatcherError()
 		return
 	}
 
@@ -209,8 +253,8 @@ func (w *Watcher) startTailingIfNeeded(id string) {
 	}
 }
 
-// refreshLoop periodically checks for session state changes (active->completed).
-// runs until context is canceled.
+// refreshLoop periodically checks for session state changes (active->completed)
+// and prunes old stopped sessions. runs until context is canceled.
 func (w *Watcher) refreshLoop(ctx context.Context) {
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
@@ -221,6 +265,7 @@ func (w *Watcher) refreshLoop(ctx context.Context) {
 			return
 		case <-ticker.C:
 			w.sm.RefreshStates()
+			w.sm.Prune(time.Now())
 		}
 	}
 }
@@ -234,13 +279,16 @@ func (w *Watcher) Close() error {
 }
 
 // isProgressFile returns true if the path matches progress-*.txt pattern.
+// rotated backups (progress-*.N.txt) are not progress files of their own.
 func isProgressFile(path string) bool {
 	name := filepath.Base(path)
-	return strings.HasPrefix(name, "progress-") && strings.HasSuffix(name, ".txt")
+	return strings.HasPrefix(name, "progress-") && strings.HasSuffix(name, ".txt") && !progress.IsBackupPath(name)
 }
 
 // ResolveWatchDirs determines the directories to watch based on precedence:
 // CLI flags > config file > current directory (default).
+// entries may start with ~ and contain glob patterns per path element, e.g. ~/work/*/ or ~/work/**,
+// where ** matches any number of directories.
 // returns at least one directory (current directory if nothing else specified).
 func ResolveWatchDirs(cliDirs, configDirs []string) []string {
 	// CLI flags take highest precedence
@@ -261,7 +309,8 @@ func ResolveWatchDirs(cliDirs, configDirs []string) []string {
 	return []string{cwd}
 }
 
-// normalizeDirs converts relative paths to absolute and removes duplicates.
+// normalizeDirs expands ~, converts relative paths to absolute and removes duplicates.
+// glob patterns keep their glob elements, the directory before them must exist.
 // logs warnings for invalid directories to help users debug configuration issues.
 func normalizeDirs(dirs []string) []string {
 	seen := make(map[string]bool)
@@ -269,16 +318,18 @@ func normalizeDirs(dirs []string) []string {
 
 	for _, dir := range dirs {
 		// convert to absolute path
-		abs, err := filepath.Abs(dir)
+		abs, err := filepath.Abs(expandHome(dir))
 		if err != nil {
 			log.Printf("[WARN] failed to resolve path %q: %v", dir, err)
 			abs = dir
 		}
+		p := parseWatchPattern(abs)
 
 		// resolve symlinks for consistent deduplication (macOS has /var -> /private/var)
-		if resolved, evalErr := filepath.EvalSymlinks(abs); evalErr == nil {
-			abs = resolved
+		if resolved, evalErr := filepath.EvalSymlinks(p.base); evalErr == nil {
+			p.base = resolved
 		}
+		abs = filepath.Join(append([]string{p.base}, p.segments...)...)
 
 		// skip duplicates
 		if seen[abs] {
@@ -287,13 +338,13 @@ func normalizeDirs(dirs []string) []string {
 		seen[abs] = true
 
 		// verify directory exists
-		info, err := os.Stat(abs)
+		info, err := os.Stat(p.base)
 		if err != nil {
-			log.Printf("[WARN] watch directory %q does not exist: %v", abs, err)
+			log.Printf("[WARN] watch directory %q does not exist: %v", p.base, err)
 			continue
 		}
 		if !info.IsDir() {
-			log.Printf("[WARN] watch path %q is not a directory", abs)
+			log.Printf("[WARN] watch path %q is not a directory", p.base)
 			continue
 		}
 		result = append(result, abs)
@@ -311,3 +362,15 @@ func normalizeDirs(dirs []string) []string {
 
 	return result
 }
+
+// expandHome replaces a leading ~ with the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
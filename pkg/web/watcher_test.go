@@ -9,6 +9,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/progress"
+	"github.com/umputun/ralphex/pkg/status"
 )
 
 // resolveSymlinks resolves symlinks in the given path for test comparison.
@@ -34,6 +37,7 @@ func TestIsProgressFile(t *testing.T) {
 		{"progress-test.log", false},
 		{"my-progress-test.txt", false},
 		{".progress-test.txt", false},
+		{"progress-test-2026-01-22T10-30-00.1.txt", false}, // rotated backup
 		{"", false},
 	}
 
@@ -94,6 +98,18 @@ func TestResolveWatchDirs_DeduplicatesAndNormalizes(t *testing.T) {
 	assert.Equal(t, resolveSymlinks(t, testDir), result[0])
 }
 
+func TestResolveWatchDirs_GlobAndTilde(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	work := filepath.Join(home, "work")
+	require.NoError(t, os.Mkdir(work, 0o750))
+
+	result := ResolveWatchDirs([]string{"~/work/*/", "~/work", filepath.Join(work, "*"), "~/missing/*"}, nil)
+	root := resolveSymlinks(t, work)
+	assert.Equal(t, []string{filepath.Join(root, "*"), root}, result)
+}
+
 func TestResolveWatchDirs_InvalidDirsIgnored(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -286,6 +302,45 @@ Started: 2026-01-22 10:00:00
 	require.NotNil(t, session, "session in subdirectory should be discovered")
 }
 
+func TestWatcher_DetectsCompletedSession(t *testing.T) {
+	tmpDir := t.TempDir()
+	sm := NewSessionManager()
+	t.Cleanup(sm.Close)
+
+	// a running progress logger holds the lock on its file
+	logger, err := progress.NewLogger(progress.Config{PlanFile: "plan.md", Mode: "full", Branch: "main", Dir: tmpDir},
+		testColors(), &status.PhaseHolder{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = logger.Close() })
+	sessionID := sessionIDFromPath(logger.Path())
+
+	w, err := NewWatcher([]string{tmpDir}, sm)
+	require.NoError(t, err)
+	go func() { _ = w.Start(t.Context()) }()
+
+	require.Eventually(t, func() bool {
+		s := sm.Get(sessionID)
+		return s != nil && s.GetState() == SessionStateActive && s.IsTailing()
+	}, 2*time.Second, 20*time.Millisecond)
+
+	// the completion footer marks the session completed while the file is still locked
+	f, err := os.OpenFile(logger.Path(), os.O_APPEND|os.O_WRONLY, 0o600) //nolint:gosec // test file
+	require.NoError(t, err)
+	_, err = f.WriteString("\n------------------------------------------------------------\nCompleted: 2026-01-22 10:05:00 (5m0s)\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	session := sm.Get(sessionID)
+	require.Eventually(t, func() bool { return session.GetState() == SessionStateCompleted }, 2*time.Second, 20*time.Millisecond)
+	assert.Equal(t, SessionStatusFinished, session.Status(time.Now()))
+
+	// a later write event doesn't turn it active again
+	require.NoError(t, os.Chtimes(logger.Path(), time.Now(), time.Now()))
+	_, err = sm.Discover(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, SessionStateCompleted, session.GetState())
+}
+
 func TestWatcher_HandlesDeletedProgressFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	sm := NewSessionManager()
@@ -327,9 +382,18 @@ Started: 2026-01-22 10:00:00
 	// give watcher time to process
 	time.Sleep(200 * time.Millisecond)
 
-	// verify session was removed
+	// verify session stays listed, marked as removed
 	session = sm.Get(sessionID)
-	assert.Nil(t, session, "session should be removed after file deletion")
+	require.NotNil(t, session, "session should stay listed after file deletion")
+	assert.True(t, session.IsRemoved())
+	assert.Equal(t, SessionStatusRemoved, session.Status(time.Now()))
+
+	// the file showing up again replaces the removed session with a fresh one
+	require.NoError(t, os.WriteFile(progressFile, []byte(header), 0o600))
+	require.Eventually(t, func() bool {
+		s := sm.Get(sessionID)
+		return s != nil && !s.IsRemoved()
+	}, time.Second, 20*time.Millisecond)
 }
 
 func TestWatcher_SkipsKnownDirectories(t *testing.T) {
@@ -388,6 +452,95 @@ Started: 2026-01-22 10:00:00
 	}
 }
 
+func TestWatcher_DepthLimit(t *testing.T) {
+	root := t.TempDir()
+	paths := map[string]string{
+		"level1":    filepath.Join(root, "a", "progress-level1.txt"),
+		"level2":    filepath.Join(root, "a", "b", "progress-level2.txt"),
+		"level3":    filepath.Join(root, "a", "b", "c", "progress-level3.txt"),
+		"state dir": filepath.Join(root, "a", "b", ".ralphex", "progress", "progress-state.txt"),
+		"ignored":   filepath.Join(root, "skip", "progress-ignored.txt"),
+	}
+	for _, path := range paths {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		createProgressFile(t, path, "plan.md", "main", "full")
+	}
+
+	sm := NewSessionManager()
+	w, err := NewWatcher([]string{root}, sm)
+	require.NoError(t, err)
+	w.SetOptions(WatchOptions{Depth: 2, Ignore: []string{"sk*"}})
+	go func() { _ = w.Start(t.Context()) }()
+
+	require.Eventually(t, func() bool { return sm.Get(sessionIDFromPath(paths["state dir"])) != nil },
+		2*time.Second, 20*time.Millisecond)
+	assert.NotNil(t, sm.Get(sessionIDFromPath(paths["level1"])))
+	assert.NotNil(t, sm.Get(sessionIDFromPath(paths["level2"])))
+	assert.Nil(t, sm.Get(sessionIDFromPath(paths["level3"])), "beyond depth")
+	assert.Nil(t, sm.Get(sessionIDFromPath(paths["ignored"])), "ignored directory")
+
+	// a new progress file beyond the depth is not picked up either
+	late := filepath.Join(root, "a", "b", "c", "progress-late.txt")
+	createProgressFile(t, late, "plan.md", "main", "full")
+	time.Sleep(200 * time.Millisecond)
+	assert.Nil(t, sm.Get(sessionIDFromPath(late)))
+}
+
+func TestWatcher_GlobPicksUpLateDirectories(t *testing.T) {
+	root := resolveSymlinks(t, t.TempDir())
+	existing := filepath.Join(root, "existing", "progress-existing.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(existing), 0o750))
+	createProgressFile(t, existing, "plan.md", "main", "full")
+	outside := filepath.Join(root, "progress-outside.txt") // in the glob base, not matched by it
+	createProgressFile(t, outside, "plan.md", "main", "full")
+
+	sm := NewSessionManager()
+	w, err := NewWatcher([]string{filepath.Join(root, "*")}, sm)
+	require.NoError(t, err)
+	w.SetOptions(WatchOptions{Depth: 2})
+	go func() { _ = w.Start(t.Context()) }()
+
+	require.Eventually(t, func() bool { return sm.Get(sessionIDFromPath(existing)) != nil },
+		2*time.Second, 20*time.Millisecond)
+	assert.Nil(t, sm.Get(sessionIDFromPath(outside)))
+
+	// a repository created after startup is watched
+	newRepo := filepath.Join(root, "new-repo")
+	require.NoError(t, os.Mkdir(newRepo, 0o750))
+	time.Sleep(100 * time.Millisecond)
+	created := filepath.Join(newRepo, "progress-created.txt")
+	createProgressFile(t, created, "plan.md", "main", "full")
+	require.Eventually(t, func() bool { return sm.Get(sessionIDFromPath(created)) != nil },
+		2*time.Second, 20*time.Millisecond)
+
+	// a repository moved in with progress files already in it is discovered
+	staging := t.TempDir()
+	moved := filepath.Join(staging, "moved-repo", ".ralphex", "progress", "progress-moved.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(moved), 0o750))
+	createProgressFile(t, moved, "plan.md", "main", "full")
+	require.NoError(t, os.Rename(filepath.Join(staging, "moved-repo"), filepath.Join(root, "moved-repo")))
+	movedID := sessionIDFromPath(filepath.Join(root, "moved-repo", ".ralphex", "progress", "progress-moved.txt"))
+	require.Eventually(t, func() bool { return sm.Get(movedID) != nil }, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestWatcher_OverlappingPatternsShareSessions(t *testing.T) {
+	root := resolveSymlinks(t, t.TempDir())
+	path := filepath.Join(root, "repo", "progress-shared.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	createProgressFile(t, path, "plan.md", "main", "full")
+
+	sm := NewSessionManager()
+	w, err := NewWatcher([]string{root, filepath.Join(root, "*"), filepath.Join(root, "repo")}, sm)
+	require.NoError(t, err)
+	go func() { _ = w.Start(t.Context()) }()
+
+	require.Eventually(t, func() bool { return sm.Get(sessionIDFromPath(path)) != nil },
+		2*time.Second, 20*time.Millisecond)
+	createProgressFile(t, path, "plan.md", "main", "full")
+	time.Sleep(200 * time.Millisecond)
+	assert.Len(t, sm.All(), 1)
+}
+
 func TestWatcher_WatchesUnknownHiddenDirectories(t *testing.T) {
 	tmpDir := t.TempDir()
 	dotDir := filepath.Join(tmpDir, ".myconfig")
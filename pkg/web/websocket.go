@@ -0,0 +1,190 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteTimeout   = 10 * time.Second
+	wsPingInterval   = 30 * time.Second
+	wsPongTimeout    = wsPingInterval + 10*time.Second
+	wsMaxMessageSize = 64 << 10
+)
+
+// websocket message types, "event" and "error" are sent by the server, the rest are browser commands.
+const (
+	wsTypeEvent  = "event"
+	wsTypeError  = "error"
+	wsTypePause  = "pause"
+	wsTypeResume = "resume"
+	wsTypeAnswer = "answer"
+)
+
+// wsMessage is a message of the websocket protocol in both directions.
+type wsMessage struct {
+	Type   string `json:"type"`
+	Seq    int64  `json:"seq,omitempty"`    // sequence number of the event, as in the events API
+	Event  *Event `json:"event,omitempty"`  // published event, for "event"
+	Answer string `json:"answer,omitempty"` // answer to the waiting plan question, for "answer"
+	Error  string `json:"error,omitempty"`  // why a command failed, for "error"
+}
+
+// wsUpgrader keeps the default origin check, browsers on other sites can't open the socket.
+var wsUpgrader = websocket.Upgrader{ReadBufferSize: 4096, WriteBufferSize: 4096}
+
+// wsConn serializes writes to a websocket, the event stream and command replies share it.
+type wsConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+// send writes a JSON message.
+func (c *wsConn) send(msg wsMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return fmt.Errorf("set write deadline: %w", err)
+	}
+	if err := c.conn.WriteJSON(msg); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+	return nil
+}
+
+// ping writes a ping control frame.
+func (c *wsConn) ping() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+		return fmt.Errorf("write ping: %w", err)
+	}
+	return nil
+}
+
+// handleWebSocket streams session events over a websocket and accepts control commands from the browser:
+// pause/resume of the live run and answers to its plan questions.
+// accepts ?session=<id> like /events and ?since=N to skip events the client already has.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	session, err := s.getSession(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	since, err := parseSince(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("[WS] upgrade failed: %v", err) // the upgrader already replied with an error
+		return
+	}
+	defer conn.Close()
+	log.Printf("[WS] connection opened: session=%s", session.ID)
+
+	history, events, unsubscribe := session.Subscribe(since)
+	defer unsubscribe()
+
+	c := &wsConn{conn: conn}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		readCommands(c, session)
+	}()
+	streamEvents(c, history, events, done)
+	log.Printf("[WS] connection closed: session=%s", session.ID)
+}
+
+// streamEvents sends history and then live events until the client goes away or the subscription ends.
+// a dropped subscription (slow client) closes the connection, the client reconnects with ?since=.
+func streamEvents(c *wsConn, history []SessionEvent, events <-chan SessionEvent, done <-chan struct{}) {
+	for _, e := range history {
+		if err := c.send(wsMessage{Type: wsTypeEvent, Seq: e.Seq, Event: &e.Event}); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := c.send(wsMessage{Type: wsTypeEvent, Seq: e.Seq, Event: &e.Event}); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := c.ping(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readCommands reads browser commands until the connection fails, failed commands get an "error" reply.
+func readCommands(c *wsConn, session *Session) {
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("[WS] read failed: %v", err)
+			}
+			return
+		}
+		var msg wsMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			err = fmt.Errorf("invalid message: %w", err)
+			_ = c.send(wsMessage{Type: wsTypeError, Error: err.Error()})
+			continue
+		}
+		if err := handleCommand(session, msg); err != nil {
+			_ = c.send(wsMessage{Type: wsTypeError, Error: err.Error()})
+		}
+	}
+}
+
+// handleCommand applies a browser command to the session's live run.
+// the result reaches the client as a regular event: paused/resumed, question_closed.
+func handleCommand(session *Session, msg wsMessage) error {
+	switch msg.Type {
+	case wsTypePause, wsTypeResume:
+		pause := session.PauseHolder()
+		if pause == nil {
+			return errors.New("session is not controlled by this process")
+		}
+		if msg.Type == wsTypePause {
+			pause.Pause()
+			return nil
+		}
+		pause.Resume()
+		return nil
+	case wsTypeAnswer:
+		questions := session.QuestionRelay()
+		if questions == nil {
+			return ErrNoQuestion
+		}
+		return questions.Answer(msg.Answer)
+	default:
+		return fmt.Errorf("unknown command %q", msg.Type)
+	}
+}
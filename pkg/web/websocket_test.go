@@ -0,0 +1,220 @@
+package web
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/status"
+	"github.com/umputun/ralphex/pkg/web/mocks"
+)
+
+// startWebSocketServer serves the single-session dashboard with websocket enabled.
+func startWebSocketServer(t *testing.T, session *Session) *httptest.Server {
+	t.Helper()
+	srv, err := NewServer(ServerConfig{EnableWebSocket: true}, session)
+	require.NoError(t, err)
+	handler, err := srv.handler()
+	require.NoError(t, err)
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// dialWebSocket opens a websocket to the test server path.
+func dialWebSocket(t *testing.T, ts *httptest.Server, path string) *websocket.Conn {
+	t.Helper()
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+path, nil)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// readWSMessage reads the next message, failing the test after a second.
+func readWSMessage(t *testing.T, conn *websocket.Conn) wsMessage {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	var msg wsMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+func TestServer_WebSocket_StreamsEvents(t *testing.T) {
+	session := NewSession("main", "/tmp/test.txt")
+	defer session.Close()
+	require.NoError(t, session.Publish(NewOutputEvent(status.PhaseTask, "first")))
+	require.NoError(t, session.Publish(NewOutputEvent(status.PhaseTask, "second")))
+	ts := startWebSocketServer(t, session)
+
+	conn := dialWebSocket(t, ts, "/ws")
+	msg := readWSMessage(t, conn)
+	assert.Equal(t, wsTypeEvent, msg.Type)
+	assert.Equal(t, int64(1), msg.Seq)
+	require.NotNil(t, msg.Event)
+	assert.Equal(t, "first", msg.Event.Text)
+	assert.Equal(t, "second", readWSMessage(t, conn).Event.Text)
+
+	// live event after the history
+	require.NoError(t, session.Publish(NewOutputEvent(status.PhaseReview, "live")))
+	msg = readWSMessage(t, conn)
+	assert.Equal(t, int64(3), msg.Seq)
+	assert.Equal(t, "live", msg.Event.Text)
+	assert.Equal(t, status.PhaseReview, msg.Event.Phase)
+
+	t.Run("since skips received events", func(t *testing.T) {
+		conn := dialWebSocket(t, ts, "/ws?since=2")
+		msg := readWSMessage(t, conn)
+		assert.Equal(t, int64(3), msg.Seq)
+		assert.Equal(t, "live", msg.Event.Text)
+	})
+}
+
+func TestServer_WebSocket_Commands(t *testing.T) {
+	t.Run("pause and resume", func(t *testing.T) {
+		session := NewSession("main", "/tmp/test.txt")
+		defer session.Close()
+		pause := &status.PauseHolder{}
+		pause.OnChange(func(paused bool) { _ = session.Publish(NewPauseEvent(status.PhaseTask, paused)) })
+		session.SetPauseHolder(pause)
+		conn := dialWebSocket(t, startWebSocketServer(t, session), "/ws")
+
+		require.NoError(t, conn.WriteJSON(wsMessage{Type: wsTypePause}))
+		msg := readWSMessage(t, conn)
+		require.NotNil(t, msg.Event)
+		assert.Equal(t, EventTypePaused, msg.Event.Type)
+		assert.True(t, pause.Paused())
+
+		require.NoError(t, conn.WriteJSON(wsMessage{Type: wsTypeResume}))
+		assert.Equal(t, EventTypeResumed, readWSMessage(t, conn).Event.Type)
+		assert.False(t, pause.Paused())
+	})
+
+	t.Run("answer question", func(t *testing.T) {
+		session := NewSession("main", "/tmp/test.txt")
+		defer session.Close()
+		inner := &mocks.CollectorMock{
+			AskQuestionFunc: func(ctx context.Context, _ string, _ []string) (string, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+		}
+		questions := NewQuestionRelay(inner)
+		asked := make(chan struct{})
+		questions.OnAsk(func(Question) { close(asked) })
+		session.SetQuestionRelay(questions)
+		conn := dialWebSocket(t, startWebSocketServer(t, session), "/ws")
+
+		answerCh := make(chan string, 1)
+		go func() {
+			answer, err := questions.AskQuestion(t.Context(), "which db?", []string{"postgres", "sqlite"})
+			assert.NoError(t, err)
+			answerCh <- answer
+		}()
+		<-asked
+		require.NoError(t, conn.WriteJSON(wsMessage{Type: wsTypeAnswer, Answer: "sqlite"}))
+		select {
+		case answer := <-answerCh:
+			assert.Equal(t, "sqlite", answer)
+		case <-time.After(time.Second):
+			t.Fatal("question not answered")
+		}
+
+		// no question waiting anymore
+		require.NoError(t, conn.WriteJSON(wsMessage{Type: wsTypeAnswer, Answer: "postgres"}))
+		msg := readWSMessage(t, conn)
+		assert.Equal(t, wsTypeError, msg.Type)
+		assert.Equal(t, ErrNoQuestion.Error(), msg.Error)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		session := NewSession("main", "/tmp/test.txt")
+		defer session.Close()
+		conn := dialWebSocket(t, startWebSocketServer(t, session), "/ws")
+
+		tests := []struct {
+			name    string
+			message string
+			wantErr string
+		}{
+			{name: "pause without live run", message: `{"type":"pause"}`, wantErr: "session is not controlled by this process"},
+			{name: "answer without questions", message: `{"type":"answer","answer":"yes"}`, wantErr: ErrNoQuestion.Error()},
+			{name: "unknown command", message: `{"type":"stop"}`, wantErr: `unknown command "stop"`},
+			{name: "invalid json", message: `{type`, wantErr: "invalid message"},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(tc.message)))
+				msg := readWSMessage(t, conn)
+				assert.Equal(t, wsTypeError, msg.Type)
+				assert.Contains(t, msg.Error, tc.wantErr)
+			})
+		}
+	})
+}
+
+func TestServer_WebSocket_Rejected(t *testing.T) {
+	session := NewSession("main", "/tmp/test.txt")
+	defer session.Close()
+	ts := startWebSocketServer(t, session)
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	t.Run("cross-origin", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(wsURL+"/ws", http.Header{"Origin": []string{"http://evil.example"}})
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("invalid since", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(wsURL+"/ws?since=-1", nil)
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		srv, err := NewServer(ServerConfig{}, session)
+		require.NoError(t, err)
+		handler, err := srv.handler()
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ws", http.NoBody))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+		assert.Contains(t, w.Body.String(), `<body data-websocket="false">`, "dashboard stays on SSE")
+	})
+
+	t.Run("dashboard uses websocket when enabled", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `<body data-websocket="true">`)
+	})
+}
+
+func TestServer_WebSocket_SessionClosed(t *testing.T) {
+	session := NewSession("main", "/tmp/test.txt")
+	conn := dialWebSocket(t, startWebSocketServer(t, session), "/ws")
+	require.NoError(t, session.Publish(NewOutputEvent(status.PhaseTask, "hello")))
+	assert.Equal(t, "hello", readWSMessage(t, conn).Event.Text)
+
+	session.Close()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	_, _, err := conn.ReadMessage()
+	require.Error(t, err, "connection is closed with the session")
+}